@@ -0,0 +1,46 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/secret"
+	"log"
+	"net/http"
+)
+
+//authorized checks control plane access configured in the service config, it writes error response if request is denied
+func authorized(writer http.ResponseWriter, request *http.Request, endpoint string) bool {
+	access, err := loadAccess(request.Context())
+	if err != nil {
+		log.Print(err)
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if access == nil {
+		return true
+	}
+	return access.Check(writer, request, endpoint)
+}
+
+func loadAccess(ctx context.Context) (*auth.Access, error) {
+	cfg, err := base.NewConfigFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil || cfg.Access == nil {
+		return nil, err
+	}
+	if cfg.Access.APIKeys != nil {
+		data, err := secret.New(cfg.SourceScheme, afs.New()).Decrypt(ctx, cfg.Access.APIKeys)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decrypt access API keys")
+		}
+		keys := map[string]string{}
+		if err = json.Unmarshal(data, &keys); err != nil {
+			return nil, errors.Wrap(err, "failed to decode access API keys")
+		}
+		cfg.Access.Init(keys)
+	}
+	return cfg.Access, nil
+}
@@ -0,0 +1,125 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/viant/afs/file"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/job"
+	"time"
+)
+
+//alertActions returns rule post actions for transfer outcome: failure notify actions are suppressed for the same rule and error code
+//within alerting window, escalation actions are added after threshold and recovered actions are added to the first success after alert
+func (s *service) alertActions(ctx context.Context, rule *config.Rule, response *contract.Response, err error) *job.Actions {
+	alerting := rule.Alerting
+	if alerting == nil {
+		return &rule.Actions
+	}
+	name := responseRule(response)
+	alertURL := alerting.AlertURL(name)
+	var alerts = make(map[string]*config.Alert)
+	if data, e := s.fs.DownloadWithURL(ctx, alertURL); e == nil {
+		_ = json.Unmarshal(data, &alerts)
+	}
+	result := rule.Actions
+	if err == nil {
+		if len(alerts) == 0 {
+			return &result
+		}
+		if e := s.fs.Delete(ctx, alertURL); e != nil {
+			response.LogError = e.Error()
+		}
+		onRecovered := alerting.OnRecovered
+		if len(onRecovered) == 0 {
+			onRecovered = alertNotifyActions(rule.OnFailure, fmt.Sprintf("%v recovered", name), "$SourceURL was mirrored after failure")
+		}
+		result.OnSuccess = append(append([]*job.Action{}, rule.OnSuccess...), onRecovered...)
+		response.Alert = config.AlertRecovered
+		return &result
+	}
+	now := time.Now()
+	code := base.ErrorCode(err)
+	alert := alerts[code]
+	if alerting.IsSuppressed(alert, now) {
+		alert.Count++
+		alert.Suppressed++
+		result.OnFailure = withoutNotifyActions(rule.OnFailure)
+		response.Alert = config.AlertSuppressed
+	} else {
+		alert = &config.Alert{Alerted: now, Count: 1}
+		alerts[code] = alert
+		response.Alert = config.AlertSent
+	}
+	alert.LastError = err.Error()
+	alert.Updated = now
+	if alerting.IsEscalated(alert) {
+		alert.Escalated = true
+		onEscalate := alerting.OnEscalate
+		if len(onEscalate) == 0 {
+			onEscalate = alertNotifyActions(rule.OnFailure, fmt.Sprintf("%v escalated: %v failures", name, alert.Count), "")
+		}
+		result.OnFailure = append(append([]*job.Action{}, result.OnFailure...), onEscalate...)
+		response.Alert = config.AlertEscalated
+	}
+	data, e := json.Marshal(alerts)
+	if e == nil {
+		e = s.fs.Upload(ctx, alertURL, file.DefaultFileOsMode, bytes.NewReader(data))
+	}
+	if e != nil {
+		response.LogError = e.Error()
+	}
+	return &result
+}
+
+//alertNotifyActions returns copies of notify actions with supplied title and message, empty message keeps action message
+func alertNotifyActions(actions []*job.Action, title, message string) []*job.Action {
+	var result = make([]*job.Action, 0)
+	for _, action := range actions {
+		if action.Action != job.ActionNotify {
+			continue
+		}
+		notify := *action
+		notify.Title = title
+		if message != "" {
+			notify.Message = message
+			notify.Body = nil
+		}
+		notify.Name = ""
+		notify.DependsOn = nil
+		result = append(result, &notify)
+	}
+	return result
+}
+
+//withoutNotifyActions returns actions without notify actions, dependencies on removed actions are dropped
+func withoutNotifyActions(actions []*job.Action) []*job.Action {
+	var removed = make(map[string]bool)
+	for _, action := range actions {
+		if action.Action == job.ActionNotify && action.Name != "" {
+			removed[action.Name] = true
+		}
+	}
+	var result = make([]*job.Action, 0)
+	for _, action := range actions {
+		if action.Action == job.ActionNotify {
+			continue
+		}
+		if len(removed) > 0 && len(action.DependsOn) > 0 {
+			clone := *action
+			clone.DependsOn = make([]string, 0)
+			for _, dependency := range action.DependsOn {
+				if !removed[dependency] {
+					clone.DependsOn = append(clone.DependsOn, dependency)
+				}
+			}
+			action = &clone
+		}
+		result = append(result, action)
+	}
+	return result
+}
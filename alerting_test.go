@@ -0,0 +1,69 @@
+package smirror
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/job"
+	"testing"
+)
+
+func TestService_AlertActions(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/alerting")
+	rule := &config.Rule{
+		Info: base.Info{Workflow: "partner"},
+		Actions: job.Actions{
+			OnSuccess: []*job.Action{{Action: job.ActionDelete}},
+			OnFailure: []*job.Action{
+				{Action: job.ActionNotify, Name: "alert", Title: "partner failed", Message: "$Error"},
+				{Action: job.ActionMove, URL: "mem://localhost/alerting/error", DependsOn: []string{"alert"}},
+			},
+		},
+		Alerting: &config.Alerting{StateURL: "mem://localhost/alerting/state", EscalateAfter: 3},
+	}
+	srv := &service{fs: fs}
+	transferErr := errors.New("test error")
+	var useCases = []struct {
+		description  string
+		err          error
+		expectAlert  string
+		expectTitles []string
+		expectFailed int
+	}{
+		{description: "first failure sent", err: transferErr, expectAlert: config.AlertSent, expectTitles: []string{"partner failed"}, expectFailed: 2},
+		{description: "duplicate suppressed", err: transferErr, expectAlert: config.AlertSuppressed, expectFailed: 1},
+		{description: "escalated", err: transferErr, expectAlert: config.AlertEscalated, expectTitles: []string{"partner escalated: 3 failures"}, expectFailed: 2},
+		{description: "escalated once", err: transferErr, expectAlert: config.AlertSuppressed, expectFailed: 1},
+		{description: "recovered", expectAlert: config.AlertRecovered, expectTitles: []string{"partner recovered"}},
+		{description: "success", expectAlert: ""},
+	}
+	for _, useCase := range useCases {
+		response := contract.NewResponse("mem://localhost/alerting/data/f1.csv")
+		response.Rule = rule
+		actions := srv.alertActions(ctx, rule, response, useCase.err)
+		assert.Equal(t, useCase.expectAlert, response.Alert, useCase.description)
+		var titles = make([]string, 0)
+		candidates := actions.OnSuccess
+		if useCase.err != nil {
+			candidates = actions.OnFailure
+			assert.Equal(t, useCase.expectFailed, len(actions.OnFailure), useCase.description)
+			assert.Nil(t, actions.Validate(), useCase.description)
+		}
+		for _, action := range candidates {
+			if action.Action == job.ActionNotify {
+				titles = append(titles, action.Title)
+			}
+		}
+		if len(useCase.expectTitles) == 0 {
+			assert.Empty(t, titles, useCase.description)
+			continue
+		}
+		assert.Equal(t, useCase.expectTitles, titles, useCase.description)
+	}
+}
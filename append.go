@@ -0,0 +1,258 @@
+package smirror
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/afsc/gs"
+	as3 "github.com/viant/afsc/s3"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/native"
+	"github.com/viant/smirror/contract"
+	"google.golang.org/api/googleapi"
+	gstorage "google.golang.org/api/storage/v1"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//maxAppendAttempts max number of conditional append attempts when rolling object or state is modified concurrently
+const maxAppendAttempts = 5
+
+//appendTransfer appends transfer content to the current rolling dest object, a new rolling object is started once rotation is due;
+//appends to the same rolling object are serialized within the instance, and across instances rolling object and state writes
+//are guarded by gs generation or s3 ETag precondition
+func (s *service) appendTransfer(ctx context.Context, transfer *Transfer, reader io.Reader, options []storage.Option, response *contract.Response) error {
+	layout := transfer.Resource.Append
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if layout.Delimiter != "" && len(data) > 0 && !bytes.HasSuffix(data, []byte(layout.Delimiter)) {
+		data = append(data, layout.Delimiter...)
+	}
+	if transfer.Dest.CompressionCodec() == config.GZipCodec { //concatenated gzip members are a valid gzip stream
+		buffer := new(bytes.Buffer)
+		gzipWriter := gzip.NewWriter(buffer)
+		if _, err = gzipWriter.Write(data); err == nil {
+			err = gzipWriter.Close()
+		}
+		if err != nil {
+			return err
+		}
+		data = buffer.Bytes()
+	}
+	stateURL := layout.StateURL(transfer.Dest.URL)
+	unlock := s.lockAppend(stateURL)
+	defer unlock()
+	state, stateVersion, err := s.loadAppendState(ctx, stateURL, options)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if layout.ShallRotate(state, len(data), now) {
+		state = layout.NewState(transfer.Dest.URL, now)
+	}
+	appendURL := state.URL
+	if state.Parts == 0 {
+		err = s.putIfMatch(ctx, appendURL, data, "", options)
+		if isPreconditionFailed(err) { //rolling object was created by another instance
+			err = s.appendObject(ctx, transfer.Resource, appendURL, data, options)
+		}
+	} else {
+		err = s.appendObject(ctx, transfer.Resource, appendURL, data, options)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to append to %v", appendURL)
+	}
+	for attempt := 1; ; attempt++ {
+		if state.URL == appendURL { //state rotated by another instance already points past appended object
+			state.Size += int64(len(data))
+			state.Parts++
+		}
+		content, err := json.Marshal(state)
+		if err == nil {
+			err = s.putIfMatch(ctx, stateURL, content, stateVersion, options)
+		}
+		if isPreconditionFailed(err) && attempt < maxAppendAttempts {
+			if state, stateVersion, err = s.loadAppendState(ctx, stateURL, options); err != nil {
+				return err
+			}
+			if state.URL == "" {
+				state = &config.AppendState{URL: appendURL, Created: now}
+			}
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to update append state %v", stateURL)
+		}
+		break
+	}
+	response.AddURL(appendURL)
+	return nil
+}
+
+//loadAppendState loads rolling object state with its version
+func (s *service) loadAppendState(ctx context.Context, stateURL string, options []storage.Option) (*config.AppendState, string, error) {
+	state := &config.AppendState{}
+	content, version, err := s.getVersioned(ctx, stateURL, options)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to load append state %v", stateURL)
+	}
+	if len(content) > 0 {
+		_ = json.Unmarshal(content, state)
+	}
+	return state, version, nil
+}
+
+//lockAppend locks rolling object state, it returns unlock function
+func (s *service) lockAppend(stateURL string) func() {
+	s.mux.Lock()
+	if s.appendLocks == nil {
+		s.appendLocks = make(map[string]*sync.Mutex)
+	}
+	lock, ok := s.appendLocks[stateURL]
+	if !ok {
+		lock = &sync.Mutex{}
+		s.appendLocks[stateURL] = lock
+	}
+	s.mux.Unlock()
+	lock.Lock()
+	return lock.Unlock
+}
+
+//appendObject appends data to existing rolling object
+func (s *service) appendObject(ctx context.Context, resource *config.Resource, URL string, data []byte, options []storage.Option) error {
+	if s.canCompose(resource, URL) {
+		return s.composeAppend(ctx, URL, data, options)
+	}
+	return s.concatenateAppend(ctx, URL, data, options)
+}
+
+//canCompose returns true if gs compose API can be used, compose does not support customer supplied key
+func (s *service) canCompose(resource *config.Resource, URL string) bool {
+	return url.Scheme(URL, "") == gs.Scheme && resource.CustomKey == nil
+}
+
+//concatenateAppend rewrites rolling object with appended data, rewrite is retried if rolling object was modified concurrently
+func (s *service) concatenateAppend(ctx context.Context, URL string, data []byte, options []storage.Option) error {
+	for attempt := 1; ; attempt++ {
+		existing, version, err := s.getVersioned(ctx, URL, options)
+		if err != nil {
+			return err
+		}
+		err = s.putIfMatch(ctx, URL, append(existing, data...), version, options)
+		if isPreconditionFailed(err) && attempt < maxAppendAttempts {
+			continue
+		}
+		return err
+	}
+}
+
+//getVersioned downloads object with its version: gs generation or s3 ETag, missing object returns empty version
+func (s *service) getVersioned(ctx context.Context, URL string, options []storage.Option) ([]byte, string, error) {
+	object, _ := s.fs.Object(ctx, URL, options...)
+	if object == nil {
+		return nil, "", nil
+	}
+	version := ""
+	switch sys := object.Sys().(type) {
+	case *gstorage.Object:
+		version = strconv.FormatInt(sys.Generation, 10)
+	case *s3.Object:
+		version = aws.StringValue(sys.ETag)
+	}
+	data, err := s.fs.Download(ctx, object, options...)
+	return data, version, err
+}
+
+//putIfMatch uploads object if gs and s3 object version still matches, empty version requires object not to exist,
+//other storage writes are only serialized within the instance
+func (s *service) putIfMatch(ctx context.Context, URL string, data []byte, version string, options []storage.Option) error {
+	switch url.Scheme(URL, "") {
+	case gs.Scheme:
+		generation, _ := strconv.ParseInt(version, 10, 64)
+		options = append(options, option.NewGeneration(true, generation))
+	case as3.Scheme:
+		options = append(options, &etagPrecondition{ETag: version})
+	default:
+		return s.fs.Upload(ctx, URL, file.DefaultFileOsMode, bytes.NewReader(data), options...)
+	}
+	upload, err := newNativeUploader(ctx, URL, options)
+	if err != nil {
+		return err
+	}
+	return upload(ctx, bytes.NewReader(data))
+}
+
+//isPreconditionFailed returns true if conditional write failed since object was modified concurrently
+func isPreconditionFailed(err error) bool {
+	switch actual := errors.Cause(err).(type) {
+	case *googleapi.Error:
+		return actual.Code == http.StatusPreconditionFailed
+	case awserr.RequestFailure:
+		return actual.StatusCode() == http.StatusPreconditionFailed
+	}
+	return false
+}
+
+//composeAppend uploads data as a part object and composes rolling object with the part, concurrent compose is retried
+func (s *service) composeAppend(ctx context.Context, URL string, data []byte, options []storage.Option) error {
+	partURL := URL + ".part-" + uuid.New().String()
+	if err := s.fs.Upload(ctx, partURL, file.DefaultFileOsMode, bytes.NewReader(data), options...); err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.fs.Delete(ctx, partURL, options...)
+	}()
+	service, err := native.GSService(ctx, URL, options)
+	if err != nil {
+		return errors.Wrap(err, "failed to create storage service")
+	}
+	var requesterPays *config.RequesterPays
+	option.Assign(options, &requesterPays)
+	userProject, err := native.UserProject(requesterPays)
+	if err != nil {
+		return err
+	}
+	bucket := url.Host(URL)
+	name := strings.Trim(url.Path(URL), "/")
+	partName := strings.Trim(url.Path(partURL), "/")
+	for attempt := 1; ; attempt++ {
+		getCall := service.Objects.Get(bucket, name).Context(ctx)
+		if userProject != "" {
+			getCall.UserProject(userProject)
+		}
+		current, err := getCall.Do()
+		if err != nil {
+			return err
+		}
+		request := &gstorage.ComposeRequest{
+			SourceObjects: []*gstorage.ComposeRequestSourceObjects{{Name: name, Generation: current.Generation}, {Name: partName}},
+			Destination:   &gstorage.Object{ContentType: current.ContentType, ContentEncoding: current.ContentEncoding, Metadata: current.Metadata, KmsKeyName: strings.Split(current.KmsKeyName, "/cryptoKeyVersions/")[0]},
+		}
+		composeCall := service.Objects.Compose(bucket, name, request).IfGenerationMatch(current.Generation).Context(ctx)
+		if userProject != "" {
+			composeCall.UserProject(userProject)
+		}
+		_, err = composeCall.Do()
+		if isPreconditionFailed(err) && attempt < maxAppendAttempts {
+			continue
+		}
+		return err
+	}
+}
@@ -0,0 +1,77 @@
+package smirror
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"google.golang.org/api/googleapi"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestService_Append(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/append")
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/append/data"}},
+				Dest: &config.Resource{
+					URL:    "mem://localhost/append/dest",
+					Append: &config.Append{Prefix: "feed", MaxParts: 2, Delimiter: "\n"},
+				},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var rollingURLs []string
+	for _, item := range []struct{ name, content string }{{"f1.log", "a1\na2"}, {"f2.log", "b1\n"}, {"f3.log", "c1"}} {
+		sourceURL := "mem://localhost/append/data/" + item.name
+		_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader(item.content))
+		response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+		if !assert.Equal(t, base.StatusOK, response.Status, response.Error) || !assert.Equal(t, 1, len(response.DestURLs)) {
+			return
+		}
+		rollingURLs = append(rollingURLs, response.DestURLs[0])
+	}
+	assert.Equal(t, rollingURLs[0], rollingURLs[1], "second file is appended")
+	assert.NotEqual(t, rollingURLs[1], rollingURLs[2], "rolling object is rotated after MaxParts")
+	assert.True(t, strings.HasPrefix(rollingURLs[0], "mem://localhost/append/dest/append/data/feed-"), rollingURLs[0])
+
+	content, err := fs.DownloadWithURL(ctx, rollingURLs[0])
+	assert.Nil(t, err)
+	assert.Equal(t, "a1\na2\nb1\n", string(content))
+	content, err = fs.DownloadWithURL(ctx, rollingURLs[2])
+	assert.Nil(t, err)
+	assert.Equal(t, "c1\n", string(content))
+	exists, _ := fs.Exists(ctx, "mem://localhost/append/dest/append/data/f1.log")
+	assert.False(t, exists, "appended file is not mirrored as separate object")
+}
+
+func TestIsPreconditionFailed(t *testing.T) {
+	var useCases = []struct {
+		description string
+		err         error
+		expect      bool
+	}{
+		{description: "gs precondition", err: errors.Wrap(&googleapi.Error{Code: http.StatusPreconditionFailed}, "failed to upload"), expect: true},
+		{description: "s3 precondition", err: awserr.NewRequestFailure(awserr.New("PreconditionFailed", "At least one of the pre-conditions you specified did not hold", nil), http.StatusPreconditionFailed, "1"), expect: true},
+		{description: "not found", err: &googleapi.Error{Code: http.StatusNotFound}},
+		{description: "other error", err: errors.New("412 in message only")},
+		{description: "no error"},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expect, isPreconditionFailed(useCase.err), useCase.description)
+	}
+}
@@ -0,0 +1,203 @@
+package smirror
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/as2"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+//StorageMirrorAS2 cloud function entry point, received AS2 payload is written to landing resource and mirrored right away,
+//synchronous MDN is returned if requested
+func StorageMirrorAS2(w http.ResponseWriter, r *http.Request) {
+	response, err := handleAS2(r)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if data, _ := json.Marshal(response); len(data) > 0 {
+		log.Print(string(data))
+	}
+	if len(response.MDN) > 0 {
+		for key, values := range response.MDNHeader {
+			w.Header()[key] = values
+		}
+		_, _ = w.Write(response.MDN)
+		return
+	}
+	if response.Status != base.StatusOK && response.Disposition == "" {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		log.Print(err)
+	}
+}
+
+func handleAS2(httpRequest *http.Request) (response *contract.AS2Response, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	defer func() {
+		_ = httpRequest.Body.Close()
+	}()
+	request := &contract.AS2Request{Tenant: httpRequest.URL.Query().Get("tenant"), Header: httpRequest.Header, Body: httpRequest.Body}
+	ctx := context.Background()
+	service, err := NewFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		return nil, err
+	}
+	return service.ReceiveAS2(ctx, request), nil
+}
+
+//ReceiveAS2 verifies AS2 message, writes payload to landing resource, mirrors it and builds MDN
+func (s *service) ReceiveAS2(ctx context.Context, request *contract.AS2Request) *contract.AS2Response {
+	response := contract.NewAS2Response()
+	message, modifier, err := s.receiveAS2(ctx, request, response)
+	if err != nil {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+	}
+	if message == nil || !message.Receipt {
+		return response
+	}
+	if message.AsyncReceiptURL != "" {
+		log.Printf("as2 message %v requested asynchronous MDN, synchronous MDN is returned", message.ID)
+	}
+	text := fmt.Sprintf("message %v was received", message.ID)
+	if err != nil {
+		text = fmt.Sprintf("message %v processing failed", message.ID)
+	}
+	mdn := as2.NewMDN(s.config.AS2.ID, message, modifier, text)
+	response.Disposition = mdn.Disposition()
+	signer, signerErr := s.as2Signer(ctx)
+	if signerErr != nil {
+		log.Print(signerErr)
+	}
+	if response.MDNHeader, response.MDN, err = mdn.Encode(signer); err != nil {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+	}
+	return response
+}
+
+//receiveAS2 returns read message with disposition error modifier on failure
+func (s *service) receiveAS2(ctx context.Context, request *contract.AS2Request, response *contract.AS2Response) (*as2.Message, string, error) {
+	receiver := s.config.AS2
+	if receiver == nil {
+		return nil, "", errors.New("as2 receiver was not configured")
+	}
+	message, err := as2.ReadMessage(request.Header, request.Body, int64(receiver.MaxPayloadMb)*1024*1024)
+	if message == nil {
+		return nil, "", err
+	}
+	defer func() {
+		_ = message.Close()
+	}()
+	response.Partner = message.From
+	response.MessageID = message.ID
+	if err != nil {
+		return message, as2.ErrorUnexpected, err
+	}
+	partner := receiver.Partner(message.From)
+	if partner == nil || message.To != receiver.ID {
+		return message, as2.ErrorAuthentication, errors.Errorf("unknown as2 partner: %v -> %v", message.From, message.To)
+	}
+	var certificates []*x509.Certificate
+	if partner.CertificateURL != "" {
+		if certificates, err = s.as2Certificates(ctx, partner); err != nil {
+			return message, as2.ErrorUnexpected, err
+		}
+	}
+	identity, err := s.as2Signer(ctx)
+	if err != nil {
+		return message, as2.ErrorUnexpected, err
+	}
+	if err = message.Open(identity, certificates); err != nil {
+		return message, as2.Modifier(err), errors.Wrapf(err, "failed to open as2 message %v", message.ID)
+	}
+	response.Signed = message.Signed
+	if !partner.AllowUnsigned && !message.Signed {
+		return message, as2.ErrorInsufficientSecurity, errors.Errorf("as2 partner %v requires signed message", partner.ID)
+	}
+	name := path.Base(message.Filename)
+	if name == "." || name == ".." || name == "/" || name == "" {
+		name = fmt.Sprintf("%v.dat", time.Now().UnixNano())
+	}
+	response.URL = url.Join(receiver.Landing.URL, strings.Trim(partner.Prefix, "/"), name)
+	options, err := s.secret.StorageOpts(ctx, &receiver.Landing)
+	if err != nil {
+		return message, as2.ErrorUnexpected, err
+	}
+	if err = s.fs.Upload(ctx, response.URL, 0644, message.Payload(), options...); err != nil {
+		return message, as2.ErrorUnexpected, errors.Wrapf(err, "failed to land as2 payload: %v", response.URL)
+	}
+	//payload was landed, so MDN reports processed even if mirror rule fails, failure is reported with mirror response
+	response.Mirror = s.Mirror(ctx, contract.NewRequest(response.URL))
+	if response.Mirror.Status == base.StatusError {
+		response.Status = base.StatusError
+		response.Error = response.Mirror.Error
+	}
+	return message, "", nil
+}
+
+//as2Certificates returns partner certificates, they are loaded once per instance
+func (s *service) as2Certificates(ctx context.Context, partner *config.AS2Partner) ([]*x509.Certificate, error) {
+	s.mux.Lock()
+	certificates, ok := s.as2PartnerCertificates[partner.ID]
+	s.mux.Unlock()
+	if ok {
+		return certificates, nil
+	}
+	data, err := s.fs.DownloadWithURL(ctx, partner.CertificateURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load as2 partner %v certificate", partner.ID)
+	}
+	if certificates, err = as2.ParseCertificates(data); err != nil {
+		return nil, errors.Wrapf(err, "invalid as2 partner %v certificate", partner.ID)
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.as2PartnerCertificates == nil {
+		s.as2PartnerCertificates = make(map[string][]*x509.Certificate)
+	}
+	s.as2PartnerCertificates[partner.ID] = certificates
+	return certificates, nil
+}
+
+//as2Signer returns local identity used to sign MDN and decrypt messages, it is nil without local certificate
+func (s *service) as2Signer(ctx context.Context) (*as2.Signer, error) {
+	if s.config.AS2.Certificate == nil {
+		return nil, nil
+	}
+	s.mux.Lock()
+	signer := s.as2MDNSigner
+	s.mux.Unlock()
+	if signer != nil {
+		return signer, nil
+	}
+	data, err := s.secret.Decrypt(ctx, s.config.AS2.Certificate)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt as2 certificate")
+	}
+	if signer, err = as2.NewSigner(data); err != nil {
+		return nil, err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.as2MDNSigner = signer
+	return signer, nil
+}
@@ -0,0 +1,108 @@
+package as2
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/base64"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	//ErrorAuthentication unknown partner disposition modifier
+	ErrorAuthentication = "authentication-failed"
+	//ErrorDecryption encrypted payload disposition modifier
+	ErrorDecryption = "decryption-failed"
+	//ErrorDecompression compressed payload disposition modifier
+	ErrorDecompression = "decompression-failed"
+	//ErrorInsufficientSecurity unsigned payload from partner requiring signature disposition modifier
+	ErrorInsufficientSecurity = "insufficient-message-security"
+	//ErrorIntegrity invalid signature disposition modifier
+	ErrorIntegrity = "integrity-check-failed"
+	//ErrorUnexpected processing error disposition modifier
+	ErrorUnexpected = "unexpected-processing-error"
+
+	reportingUA = "smirror"
+	lineLength  = 76
+)
+
+//MDN represents message disposition notification (receipt)
+type MDN struct {
+	//From local AS2 identifier
+	From    string
+	Message *Message
+	//Error disposition error modifier, empty for processed message
+	Error string
+	Text  string
+}
+
+//Disposition returns MDN disposition
+func (m *MDN) Disposition() string {
+	result := "automatic-action/MDN-sent-automatically; processed"
+	if m.Error != "" {
+		result += "/error: " + m.Error
+	}
+	return result
+}
+
+//Encode returns MDN HTTP header and body, MDN is signed if signed receipt was requested and signer is not nil
+func (m *MDN) Encode(signer *Signer) (http.Header, []byte, error) {
+	boundary := newBoundary()
+	body := new(bytes.Buffer)
+	fmt.Fprintf(body, "--%v\r\nContent-Type: text/plain\r\n\r\n%v\r\n", boundary, m.Text)
+	fmt.Fprintf(body, "--%v\r\nContent-Type: message/disposition-notification\r\n\r\n", boundary)
+	fmt.Fprintf(body, "Reporting-UA: %v\r\n", reportingUA)
+	fmt.Fprintf(body, "Original-Recipient: rfc822; %v\r\n", m.Message.To)
+	fmt.Fprintf(body, "Final-Recipient: rfc822; %v\r\n", m.Message.To)
+	fmt.Fprintf(body, "Original-Message-ID: %v\r\n", m.Message.ID)
+	if m.Message.MIC != "" && m.Error == "" {
+		fmt.Fprintf(body, "Received-Content-MIC: %v\r\n", m.Message.MIC)
+	}
+	fmt.Fprintf(body, "Disposition: %v\r\n\r\n--%v--\r\n", m.Disposition(), boundary)
+	contentType := fmt.Sprintf(`multipart/report; report-type=disposition-notification; boundary="%v"`, boundary)
+
+	header := http.Header{}
+	header.Set("AS2-Version", "1.2")
+	header.Set(HeaderFrom, m.From)
+	header.Set(HeaderTo, m.Message.From)
+	header.Set(HeaderMessageID, fmt.Sprintf("<%v@%v>", uuid.New().String(), reportingUA))
+	header.Set("MIME-Version", "1.0")
+	if signer == nil || !m.Message.SignedReceipt {
+		header.Set("Content-Type", contentType)
+		return header, body.Bytes(), nil
+	}
+	hash := m.Message.micHash
+	if hash == 0 {
+		hash = crypto.SHA256
+	}
+	entity := append([]byte("Content-Type: "+contentType+"\r\n\r\n"), body.Bytes()...)
+	signature, err := signer.Sign(entity, hash)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to sign MDN")
+	}
+	signedBoundary := newBoundary()
+	signed := new(bytes.Buffer)
+	fmt.Fprintf(signed, "--%v\r\n", signedBoundary)
+	signed.Write(entity)
+	fmt.Fprintf(signed, "\r\n--%v\r\nContent-Type: %v; name=smime.p7s\r\nContent-Transfer-Encoding: base64\r\nContent-Disposition: attachment; filename=smime.p7s\r\n\r\n", signedBoundary, contentTypeSignature)
+	encoded := base64.StdEncoding.EncodeToString(signature)
+	for len(encoded) > lineLength {
+		signed.WriteString(encoded[:lineLength] + "\r\n")
+		encoded = encoded[lineLength:]
+	}
+	fmt.Fprintf(signed, "%v\r\n--%v--\r\n", encoded, signedBoundary)
+	header.Set("Content-Type", fmt.Sprintf(`%v; protocol="%v"; micalg=%v; boundary="%v"`, contentTypeSigned, contentTypeSignature, micName(hash), signedBoundary))
+	return header, signed.Bytes(), nil
+}
+
+//NewMDN creates MDN for received message, modifier is a disposition error modifier, empty for processed message
+func NewMDN(from string, message *Message, modifier string, text string) *MDN {
+	return &MDN{From: from, Message: message, Error: modifier, Text: text}
+}
+
+func newBoundary() string {
+	return "smirror-" + strings.ReplaceAll(uuid.New().String(), "-", "")
+}
@@ -0,0 +1,473 @@
+package as2
+
+import (
+	"bufio"
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/quotedprintable"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path"
+	"strings"
+)
+
+const (
+	//HeaderFrom sender AS2 identifier header
+	HeaderFrom = "AS2-From"
+	//HeaderTo receiver AS2 identifier header
+	HeaderTo = "AS2-To"
+	//HeaderMessageID message id header
+	HeaderMessageID = "Message-Id"
+	//HeaderDispositionTo MDN request header
+	HeaderDispositionTo = "Disposition-Notification-To"
+	//HeaderDispositionOptions signed MDN options header
+	HeaderDispositionOptions = "Disposition-Notification-Options"
+	//HeaderReceiptDelivery asynchronous MDN URL header
+	HeaderReceiptDelivery = "Receipt-Delivery-Option"
+
+	contentTypeSigned     = "multipart/signed"
+	contentTypeSignature  = "application/pkcs7-signature"
+	contentTypeXSignature = "application/x-pkcs7-signature"
+	contentTypeSMIME      = "application/pkcs7-mime"
+	contentTypeXSMIME     = "application/x-pkcs7-mime"
+)
+
+//maxHeaderSize max MIME entity header size
+const maxHeaderSize = 64 * 1024
+
+//maxLayers max nested S/MIME layers (encryption, compression, signature)
+const maxLayers = 4
+
+//DispositionError represents message processing error reported with MDN disposition modifier
+type DispositionError struct {
+	Modifier string
+	Err      error
+}
+
+//Error returns error message
+func (e *DispositionError) Error() string {
+	return e.Err.Error()
+}
+
+//Cause returns underlying error
+func (e *DispositionError) Cause() error {
+	return e.Err
+}
+
+//Modifier returns disposition modifier for message processing error
+func Modifier(err error) string {
+	if dispositionError, ok := err.(*DispositionError); ok {
+		return dispositionError.Modifier
+	}
+	return ErrorUnexpected
+}
+
+//Message represents inbound AS2 message, HTTP body is spooled to a temp file, so message has to be closed
+type Message struct {
+	ID          string
+	From        string
+	To          string
+	Filename    string
+	ContentType string
+	//Signed true if signature was verified
+	Signed bool
+	//Encrypted true if payload was decrypted
+	Encrypted bool
+	//Compressed true if payload was decompressed
+	Compressed bool
+	//MIC base64 received content digest with algorithm, reported back in MDN
+	MIC string
+	//Receipt true if MDN was requested
+	Receipt bool
+	//SignedReceipt true if signed MDN was requested
+	SignedReceipt bool
+	//AsyncReceiptURL asynchronous MDN URL, only synchronous MDN is supported
+	AsyncReceiptURL string
+	entity          *entity
+	payload         *entity
+	micHash         crypto.Hash
+	maxSize         int64
+	spools          []*os.File
+}
+
+//entity represents MIME entity, body is a section of spooled or decrypted data
+type entity struct {
+	header textproto.MIMEHeader
+	raw    *io.SectionReader
+	body   *io.SectionReader
+}
+
+//Payload returns payload reader with content transfer encoding removed
+func (m *Message) Payload() io.Reader {
+	if m.payload == nil {
+		return bytes.NewReader(nil)
+	}
+	return m.payload.decode()
+}
+
+//Close removes spooled message data
+func (m *Message) Close() error {
+	for _, spool := range m.spools {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}
+	m.spools = nil
+	return nil
+}
+
+//Open unwraps message layers, enveloped data is decrypted with local identity, compressed data is decompressed and
+//signature is verified with partner certificates, signed message without partner certificates fails integrity check
+func (m *Message) Open(identity *Signer, certificates []*x509.Certificate) error {
+	current := m.entity
+	var micContent *io.SectionReader
+	var signatureHash crypto.Hash
+	for layer := 0; ; layer++ {
+		if layer > maxLayers {
+			return &DispositionError{Modifier: ErrorUnexpected, Err: errors.New("too many S/MIME layers")}
+		}
+		mediaType, params, err := mime.ParseMediaType(current.header.Get("Content-Type"))
+		if err != nil && current.header.Get("Content-Type") != "" {
+			return &DispositionError{Modifier: ErrorUnexpected, Err: errors.Wrapf(err, "invalid content type: %v", current.header.Get("Content-Type"))}
+		}
+		switch mediaType {
+		case contentTypeSMIME, contentTypeXSMIME:
+			data, err := ioutil.ReadAll(io.LimitReader(current.decode(), m.maxSize+1))
+			if err != nil {
+				return &DispositionError{Modifier: ErrorUnexpected, Err: errors.Wrap(err, "failed to read S/MIME entity")}
+			}
+			switch strings.ToLower(params["smime-type"]) {
+			case "enveloped-data":
+				if identity == nil {
+					return &DispositionError{Modifier: ErrorDecryption, Err: errors.New("local certificate was not configured, unable to decrypt message")}
+				}
+				decrypted, err := identity.Decrypt(data)
+				if err != nil {
+					return &DispositionError{Modifier: ErrorDecryption, Err: err}
+				}
+				m.Encrypted = true
+				micContent = io.NewSectionReader(bytes.NewReader(decrypted), 0, int64(len(decrypted)))
+				if current, err = readEntity(micContent); err != nil {
+					return &DispositionError{Modifier: ErrorUnexpected, Err: err}
+				}
+			case "compressed-data":
+				if micContent, err = m.decompress(data); err != nil {
+					return &DispositionError{Modifier: ErrorDecompression, Err: err}
+				}
+				m.Compressed = true
+				if current, err = readEntity(micContent); err != nil {
+					return &DispositionError{Modifier: ErrorUnexpected, Err: err}
+				}
+			default:
+				return &DispositionError{Modifier: ErrorUnexpected, Err: errors.Errorf("unsupported smime-type: %v", params["smime-type"])}
+			}
+		case contentTypeSigned:
+			if m.Signed {
+				return &DispositionError{Modifier: ErrorUnexpected, Err: errors.New("nested signature is not supported")}
+			}
+			parts, err := splitMultipart(current.body, params["boundary"])
+			if err != nil {
+				return &DispositionError{Modifier: ErrorUnexpected, Err: err}
+			}
+			if len(parts) != 2 {
+				return &DispositionError{Modifier: ErrorUnexpected, Err: errors.Errorf("expected 2 signed message parts, but had %v", len(parts))}
+			}
+			signaturePart, err := readEntity(parts[1])
+			if err != nil {
+				return &DispositionError{Modifier: ErrorUnexpected, Err: err}
+			}
+			if signatureType, _, _ := mime.ParseMediaType(signaturePart.header.Get("Content-Type")); signatureType != contentTypeSignature && signatureType != contentTypeXSignature {
+				return &DispositionError{Modifier: ErrorUnexpected, Err: errors.Errorf("unsupported signature type: %v", signatureType)}
+			}
+			signature, err := ioutil.ReadAll(io.LimitReader(signaturePart.decode(), maxHeaderSize*16))
+			if err != nil {
+				return &DispositionError{Modifier: ErrorUnexpected, Err: errors.Wrap(err, "failed to read signature")}
+			}
+			if signatureHash, err = Verify(signature, parts[0], parts[0].Size(), certificates); err != nil {
+				return &DispositionError{Modifier: ErrorIntegrity, Err: err}
+			}
+			m.Signed = true
+			micContent = parts[0]
+			if current, err = readEntity(parts[0]); err != nil {
+				return &DispositionError{Modifier: ErrorUnexpected, Err: err}
+			}
+		default:
+			m.payload = current
+			if current != m.entity {
+				m.ContentType = current.header.Get("Content-Type")
+				if name := filename(current.header.Get("Content-Disposition")); name != "" {
+					m.Filename = name
+				}
+			}
+			if micContent == nil {
+				micContent = current.body
+			}
+			if m.micHash == 0 {
+				m.micHash = signatureHash
+			}
+			if m.micHash == 0 {
+				m.micHash = crypto.SHA256
+			}
+			if m.MIC, err = mic(micContent, m.micHash); err != nil {
+				return &DispositionError{Modifier: ErrorUnexpected, Err: err}
+			}
+			return nil
+		}
+	}
+}
+
+//decompress decompresses data to a spool file, decompressed size is limited by max message size
+func (m *Message) decompress(data []byte) (*io.SectionReader, error) {
+	reader, err := Decompress(data)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = reader.Close() }()
+	return m.spool(reader)
+}
+
+//spool writes reader to a temp file, it returns spooled data section
+func (m *Message) spool(reader io.Reader) (*io.SectionReader, error) {
+	file, err := ioutil.TempFile("", "as2")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create as2 spool file")
+	}
+	m.spools = append(m.spools, file)
+	size, err := io.Copy(file, io.LimitReader(reader, m.maxSize+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to spool as2 message")
+	}
+	if size > m.maxSize {
+		return nil, errors.Errorf("as2 message exceeded %v bytes", m.maxSize)
+	}
+	return io.NewSectionReader(file, 0, size), nil
+}
+
+//ReadMessage reads AS2 message headers and spools body, up to maxSize bytes, message layers are unwrapped with Open,
+//once AS2 headers are read, message is returned with an error so that failure MDN can be sent
+func ReadMessage(header http.Header, body io.Reader, maxSize int64) (*Message, error) {
+	result := &Message{
+		ID:              strings.TrimSpace(header.Get(HeaderMessageID)),
+		From:            unquote(header.Get(HeaderFrom)),
+		To:              unquote(header.Get(HeaderTo)),
+		ContentType:     header.Get("Content-Type"),
+		Filename:        filename(header.Get("Content-Disposition")),
+		Receipt:         header.Get(HeaderDispositionTo) != "",
+		AsyncReceiptURL: header.Get(HeaderReceiptDelivery),
+		maxSize:         maxSize,
+	}
+	if result.From == "" || result.To == "" {
+		return nil, errors.Errorf("%v and %v headers are required", HeaderFrom, HeaderTo)
+	}
+	result.SignedReceipt, result.micHash = receiptOptions(header.Get(HeaderDispositionOptions))
+	if result.Filename == "" {
+		result.Filename = unquote(strings.Trim(result.ID, "<>"))
+	}
+	spooled, err := result.spool(body)
+	if err != nil {
+		return result, err
+	}
+	result.entity = &entity{header: textproto.MIMEHeader{}, raw: spooled, body: spooled}
+	for _, key := range []string{"Content-Type", "Content-Disposition", "Content-Transfer-Encoding"} {
+		if value := header.Get(key); value != "" {
+			result.entity.header.Set(key, value)
+		}
+	}
+	return result, nil
+}
+
+//receiptOptions parses disposition notification options, i.e. signed-receipt-protocol=optional, pkcs7-signature; signed-receipt-micalg=optional, sha-256
+func receiptOptions(options string) (bool, crypto.Hash) {
+	var signed bool
+	var hash crypto.Hash
+	for _, option := range strings.Split(options, ";") {
+		pair := strings.SplitN(option, "=", 2)
+		if len(pair) != 2 {
+			continue
+		}
+		values := strings.Split(pair[1], ",")
+		switch strings.ToLower(strings.TrimSpace(pair[0])) {
+		case "signed-receipt-protocol":
+			for _, value := range values[1:] {
+				if strings.TrimSpace(strings.ToLower(value)) == "pkcs7-signature" {
+					signed = true
+				}
+			}
+		case "signed-receipt-micalg":
+			for _, value := range values[1:] {
+				if candidate := micHash(value); candidate != 0 && hash == 0 {
+					hash = candidate
+				}
+			}
+		}
+	}
+	return signed, hash
+}
+
+func micHash(name string) crypto.Hash {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "sha-256", "sha256":
+		return crypto.SHA256
+	case "sha-1", "sha1":
+		return crypto.SHA1
+	}
+	return 0
+}
+
+func micName(hash crypto.Hash) string {
+	if hash == crypto.SHA1 {
+		return "sha1"
+	}
+	return "sha-256"
+}
+
+//mic returns received content MIC
+func mic(content *io.SectionReader, hash crypto.Hash) (string, error) {
+	digest := hash.New()
+	if _, err := io.Copy(digest, io.NewSectionReader(content, 0, content.Size())); err != nil {
+		return "", errors.Wrap(err, "failed to compute MIC")
+	}
+	return base64.StdEncoding.EncodeToString(digest.Sum(nil)) + ", " + micName(hash), nil
+}
+
+//splitMultipart returns raw multipart entity sections, line break preceding delimiter belongs to delimiter
+func splitMultipart(body *io.SectionReader, boundary string) ([]*io.SectionReader, error) {
+	if boundary == "" {
+		return nil, errors.New("multipart boundary was empty")
+	}
+	delimiter := []byte("--" + boundary)
+	reader := bufio.NewReader(io.NewSectionReader(body, 0, body.Size()))
+	var result []*io.SectionReader
+	var offset, lineBreak int64
+	partStart := int64(-1)
+	lineStart := true
+	for {
+		line, err := reader.ReadSlice('\n')
+		lineOffset := offset
+		offset += int64(len(line))
+		if lineStart && bytes.HasPrefix(line, delimiter) {
+			rest := line[len(delimiter):]
+			if bytes.HasPrefix(rest, []byte("--")) {
+				if partStart >= 0 {
+					result = append(result, io.NewSectionReader(body, partStart, lineOffset-lineBreak-partStart))
+				}
+				return result, nil
+			}
+			if len(bytes.TrimSpace(rest)) == 0 {
+				if partStart >= 0 {
+					result = append(result, io.NewSectionReader(body, partStart, lineOffset-lineBreak-partStart))
+				}
+				partStart = offset
+			}
+		}
+		lineStart = bytes.HasSuffix(line, []byte("\n"))
+		lineBreak = 0
+		if lineStart {
+			lineBreak = 1
+			if bytes.HasSuffix(line, []byte("\r\n")) {
+				lineBreak = 2
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF {
+			return nil, errors.New("multipart closing delimiter was missing")
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read multipart")
+		}
+	}
+}
+
+//readEntity reads MIME entity header, entity without header has only body
+func readEntity(raw *io.SectionReader) (*entity, error) {
+	reader := bufio.NewReader(io.NewSectionReader(raw, 0, raw.Size()))
+	result := &entity{header: textproto.MIMEHeader{}, raw: raw, body: raw}
+	var lines []string
+	var offset int64
+	for offset <= maxHeaderSize {
+		line, err := reader.ReadString('\n')
+		offset += int64(len(line))
+		if err != nil {
+			if err == io.EOF {
+				return result, nil
+			}
+			return nil, errors.Wrap(err, "failed to read entity header")
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += " " + strings.TrimSpace(line)
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if offset > maxHeaderSize {
+		return result, nil
+	}
+	for _, line := range lines {
+		if pair := strings.SplitN(line, ":", 2); len(pair) == 2 {
+			result.header.Add(strings.TrimSpace(pair[0]), strings.TrimSpace(pair[1]))
+		}
+	}
+	result.body = io.NewSectionReader(raw, offset, raw.Size()-offset)
+	return result, nil
+}
+
+//decode returns entity body reader with content transfer encoding removed
+func (e *entity) decode() io.Reader {
+	body := io.NewSectionReader(e.body, 0, e.body.Size())
+	switch strings.ToLower(strings.TrimSpace(e.header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, &spaceFilter{reader: body})
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+	return body
+}
+
+//spaceFilter removes white spaces from base64 encoded content
+type spaceFilter struct {
+	reader io.Reader
+}
+
+func (f *spaceFilter) Read(data []byte) (int, error) {
+	for {
+		n, err := f.reader.Read(data)
+		count := 0
+		for _, octet := range data[:n] {
+			if octet != ' ' && octet != '\t' && octet != '\r' && octet != '\n' {
+				data[count] = octet
+				count++
+			}
+		}
+		if count > 0 || err != nil {
+			return count, err
+		}
+	}
+}
+
+//filename returns content disposition file name without path
+func filename(disposition string) string {
+	if disposition == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(disposition)
+	if err != nil || params["filename"] == "" {
+		return ""
+	}
+	return path.Base(strings.ReplaceAll(params["filename"], "\\", "/"))
+}
+
+func unquote(value string) string {
+	return strings.Trim(strings.TrimSpace(value), `"`)
+}
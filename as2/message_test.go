@@ -0,0 +1,231 @@
+package as2
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go.mozilla.org/pkcs7"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+//signedEntity returns multipart/signed MIME entity
+func signedEntity(t *testing.T, signer *Signer, entity []byte) []byte {
+	signature, err := signer.Sign(entity, crypto.SHA256)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	body := new(bytes.Buffer)
+	fmt.Fprintf(body, "Content-Type: multipart/signed; protocol=\"application/pkcs7-signature\"; micalg=sha-256; boundary=\"b1\"\r\n\r\n")
+	fmt.Fprintf(body, "--b1\r\n%s\r\n--b1\r\nContent-Type: application/pkcs7-signature\r\nContent-Transfer-Encoding: base64\r\n\r\n%v\r\n--b1--\r\n", entity, base64.StdEncoding.EncodeToString(signature))
+	return body.Bytes()
+}
+
+//smimeEntity returns application/pkcs7-mime MIME entity
+func smimeEntity(smimeType string, data []byte) []byte {
+	return []byte(fmt.Sprintf("Content-Type: application/pkcs7-mime; smime-type=%v\r\nContent-Transfer-Encoding: base64\r\n\r\n%v", smimeType, base64.StdEncoding.EncodeToString(data)))
+}
+
+//encryptedEntity returns enveloped MIME entity for recipient
+func encryptedEntity(t *testing.T, recipient *Signer, entity []byte) []byte {
+	data, err := pkcs7.Encrypt(entity, []*x509.Certificate{recipient.Certificate})
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	return smimeEntity("enveloped-data", data)
+}
+
+//entityRequest splits MIME entity into HTTP header and body
+func entityRequest(entity []byte) (http.Header, []byte) {
+	index := bytes.Index(entity, []byte("\r\n\r\n"))
+	header := http.Header{}
+	for _, line := range bytes.Split(entity[:index], []byte("\r\n")) {
+		pair := bytes.SplitN(line, []byte(":"), 2)
+		header.Set(string(pair[0]), string(bytes.TrimSpace(pair[1])))
+	}
+	return header, entity[index+4:]
+}
+
+func TestMessage_Open(t *testing.T) {
+	partner := newTestSigner(t, "partner")
+	local := newTestSigner(t, "local")
+	entity := []byte("Content-Type: application/edi-x12\r\nContent-Disposition: attachment; filename=\"orders/850.edi\"\r\n\r\nISA*00*~")
+	signed := signedEntity(t, partner, entity)
+
+	var useCases = []struct {
+		description      string
+		entity           []byte
+		identity         *Signer
+		certificates     []*x509.Certificate
+		maxSize          int64
+		expectPayload    string
+		expectFile       string
+		expectSigned     bool
+		expectEncrypted  bool
+		expectCompressed bool
+		expectReadError  bool
+		expectModifier   string
+	}{
+		{
+			description:   "plain payload",
+			entity:        []byte("Content-Type: application/octet-stream\r\nContent-Disposition: attachment; filename=\"data.csv\"\r\n\r\nid,name"),
+			expectPayload: "id,name",
+			expectFile:    "data.csv",
+		},
+		{
+			description:   "signed payload",
+			entity:        signed,
+			certificates:  []*x509.Certificate{partner.Certificate},
+			expectPayload: "ISA*00*~",
+			expectFile:    "850.edi",
+			expectSigned:  true,
+		},
+		{
+			description:    "tampered signed payload",
+			entity:         bytes.Replace(signed, []byte("ISA*00"), []byte("ISA*01"), 1),
+			certificates:   []*x509.Certificate{partner.Certificate},
+			expectModifier: ErrorIntegrity,
+		},
+		{
+			description:    "unknown signer",
+			entity:         signed,
+			certificates:   []*x509.Certificate{local.Certificate},
+			expectModifier: ErrorIntegrity,
+		},
+		{
+			description:    "signed payload without partner certificate",
+			entity:         signed,
+			expectModifier: ErrorIntegrity,
+		},
+		{
+			description:     "encrypted signed payload",
+			entity:          encryptedEntity(t, local, signed),
+			identity:        local,
+			certificates:    []*x509.Certificate{partner.Certificate},
+			expectPayload:   "ISA*00*~",
+			expectFile:      "850.edi",
+			expectSigned:    true,
+			expectEncrypted: true,
+		},
+		{
+			description:    "encrypted payload without local certificate",
+			entity:         encryptedEntity(t, local, signed),
+			expectModifier: ErrorDecryption,
+		},
+		{
+			description:    "encrypted payload for other recipient",
+			entity:         encryptedEntity(t, partner, signed),
+			identity:       local,
+			expectModifier: ErrorDecryption,
+		},
+		{
+			description:      "signed compressed payload",
+			entity:           signedEntity(t, partner, smimeEntity("compressed-data", compressedData(t, entity))),
+			certificates:     []*x509.Certificate{partner.Certificate},
+			expectPayload:    "ISA*00*~",
+			expectFile:       "850.edi",
+			expectSigned:     true,
+			expectCompressed: true,
+		},
+		{
+			description:    "invalid compressed payload",
+			entity:         smimeEntity("compressed-data", []byte("compressed")),
+			expectModifier: ErrorDecompression,
+		},
+		{
+			description:     "payload exceeding max size",
+			entity:          []byte("Content-Type: application/octet-stream\r\n\r\nid,name"),
+			maxSize:         3,
+			expectReadError: true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		header, body := entityRequest(useCase.entity)
+		header.Set(HeaderFrom, "partner")
+		header.Set(HeaderTo, "local")
+		header.Set(HeaderMessageID, "<msg-1@partner>")
+		maxSize := useCase.maxSize
+		if maxSize == 0 {
+			maxSize = 1024 * 1024
+		}
+		message, err := ReadMessage(header, bytes.NewReader(body), maxSize)
+		if !assert.NotNil(t, message, useCase.description) {
+			continue
+		}
+		if useCase.expectReadError {
+			assert.NotNil(t, err, useCase.description)
+			assert.Nil(t, message.Close(), useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		err = message.Open(useCase.identity, useCase.certificates)
+		if useCase.expectModifier != "" {
+			assert.NotNil(t, err, useCase.description)
+			assert.EqualValues(t, useCase.expectModifier, Modifier(err), useCase.description)
+			assert.Nil(t, message.Close(), useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		payload, err := ioutil.ReadAll(message.Payload())
+		assert.Nil(t, err, useCase.description)
+		assert.EqualValues(t, useCase.expectPayload, string(payload), useCase.description)
+		assert.EqualValues(t, useCase.expectFile, message.Filename, useCase.description)
+		assert.EqualValues(t, useCase.expectSigned, message.Signed, useCase.description)
+		assert.EqualValues(t, useCase.expectEncrypted, message.Encrypted, useCase.description)
+		assert.EqualValues(t, useCase.expectCompressed, message.Compressed, useCase.description)
+		assert.NotEmpty(t, message.MIC, useCase.description)
+		assert.Nil(t, message.Close(), useCase.description)
+	}
+}
+
+func TestMDN_Encode(t *testing.T) {
+	local := newTestSigner(t, "local")
+	header := http.Header{}
+	header.Set(HeaderFrom, "partner")
+	header.Set(HeaderTo, "local")
+	header.Set(HeaderMessageID, "<msg-1@partner>")
+	header.Set(HeaderDispositionTo, "mdn@partner")
+	header.Set(HeaderDispositionOptions, "signed-receipt-protocol=optional, pkcs7-signature; signed-receipt-micalg=optional, sha-256")
+	header.Set("Content-Type", "application/octet-stream")
+	message, err := ReadMessage(header, bytes.NewReader([]byte("id,name")), 1024)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer func() { _ = message.Close() }()
+	if !assert.Nil(t, message.Open(nil, nil)) {
+		return
+	}
+	assert.True(t, message.SignedReceipt)
+
+	mdnHeader, body, err := NewMDN("local", message, "", "received").Encode(local)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.EqualValues(t, "partner", mdnHeader.Get(HeaderTo))
+	//signed MDN is read back as signed message from local
+	receipt, err := ReadMessage(mdnHeader, bytes.NewReader(body), 1024*1024)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer func() { _ = receipt.Close() }()
+	assert.Nil(t, receipt.Open(nil, []*x509.Certificate{local.Certificate}))
+	payload, _ := ioutil.ReadAll(receipt.Payload())
+	assert.Contains(t, string(payload), "Disposition: automatic-action/MDN-sent-automatically; processed")
+	assert.Contains(t, string(payload), "Received-Content-MIC: "+message.MIC)
+
+	mdnHeader, body, err = NewMDN("local", message, ErrorIntegrity, "invalid signature").Encode(nil)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Contains(t, mdnHeader.Get("Content-Type"), "multipart/report")
+	assert.Contains(t, string(body), "processed/error: integrity-check-failed")
+}
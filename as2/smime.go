@@ -0,0 +1,437 @@
+package as2
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"github.com/pkg/errors"
+	"go.mozilla.org/pkcs7"
+	"io"
+)
+
+var (
+	oidCompressedData = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 9}
+	oidZlib           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 3, 8}
+)
+
+//Signer represents local certificate and private key used to sign MDN and decrypt enveloped messages
+type Signer struct {
+	Certificate *x509.Certificate
+	Key         crypto.PrivateKey
+}
+
+//Sign returns detached PKCS#7 signature of content
+func (s *Signer) Sign(content []byte, hash crypto.Hash) ([]byte, error) {
+	digestAlgorithm, err := digestAlgorithm(hash)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := pkcs7.NewSignedData(content)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create signed data")
+	}
+	signed.SetDigestAlgorithm(digestAlgorithm)
+	if err = signed.AddSigner(s.Certificate, s.Key, pkcs7.SignerInfoConfig{}); err != nil {
+		return nil, errors.Wrap(err, "failed to sign content")
+	}
+	signed.Detach()
+	return signed.Finish()
+}
+
+//Decrypt decrypts PKCS#7 enveloped data
+func (s *Signer) Decrypt(enveloped []byte) ([]byte, error) {
+	envelope, err := pkcs7.Parse(enveloped)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode enveloped data")
+	}
+	data, err := envelope.Decrypt(s.Certificate, s.Key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt enveloped data")
+	}
+	return data, nil
+}
+
+//Verify verifies detached PKCS#7 signature of content with partner certificates, all signers have to chain to one of partner certificates,
+//content is streamed to compute digest, so signature has to carry signed attributes. It returns the first signer digest hash
+func Verify(signature []byte, content io.ReaderAt, size int64, certificates []*x509.Certificate) (crypto.Hash, error) {
+	if len(certificates) == 0 {
+		return 0, errors.New("partner certificate was not configured")
+	}
+	signed, err := pkcs7.Parse(signature)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to decode signature")
+	}
+	if len(signed.Signers) == 0 {
+		return 0, errors.New("signature has no signer")
+	}
+	roots := x509.NewCertPool()
+	for _, certificate := range certificates {
+		roots.AddCert(certificate)
+	}
+	intermediates := x509.NewCertPool()
+	for _, certificate := range signed.Certificates {
+		intermediates.AddCert(certificate)
+	}
+	candidates := append(append([]*x509.Certificate{}, signed.Certificates...), certificates...)
+	digests := make(map[crypto.Hash][]byte)
+	var result crypto.Hash
+	for i, signer := range signed.Signers {
+		hash, err := digestHash(signer.DigestAlgorithm.Algorithm)
+		if err != nil {
+			return 0, err
+		}
+		if i == 0 {
+			result = hash
+		}
+		var certificate *x509.Certificate
+		for _, candidate := range candidates {
+			if candidate.SerialNumber.Cmp(signer.IssuerAndSerialNumber.SerialNumber) == 0 && bytes.Equal(candidate.RawIssuer, signer.IssuerAndSerialNumber.IssuerName.FullBytes) {
+				certificate = candidate
+				break
+			}
+		}
+		if certificate == nil {
+			return 0, errors.New("no certificate for signer")
+		}
+		if _, err = certificate.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+			return 0, errors.Wrap(err, "signer certificate is not trusted")
+		}
+		if len(signer.AuthenticatedAttributes) == 0 {
+			return 0, errors.New("signature without signed attributes is not supported")
+		}
+		var messageDigest []byte
+		attributes := make([]signedAttribute, len(signer.AuthenticatedAttributes))
+		for j, attribute := range signer.AuthenticatedAttributes {
+			attributes[j] = signedAttribute{Type: attribute.Type, Value: attribute.Value}
+			if attribute.Type.Equal(pkcs7.OIDAttributeMessageDigest) {
+				if _, err = asn1.Unmarshal(attribute.Value.Bytes, &messageDigest); err != nil {
+					return 0, errors.Wrap(err, "failed to decode message digest")
+				}
+			}
+		}
+		if _, ok := digests[hash]; !ok {
+			digest := hash.New()
+			if _, err = io.Copy(digest, io.NewSectionReader(content, 0, size)); err != nil {
+				return 0, errors.Wrap(err, "failed to digest content")
+			}
+			digests[hash] = digest.Sum(nil)
+		}
+		if subtle.ConstantTimeCompare(messageDigest, digests[hash]) != 1 {
+			return 0, errors.New("content digest does not match signed digest")
+		}
+		signatureAlgorithm, err := signatureAlgorithm(certificate, hash)
+		if err != nil {
+			return 0, err
+		}
+		signedContent, err := marshalAttributes(attributes)
+		if err != nil {
+			return 0, err
+		}
+		if err = certificate.CheckSignature(signatureAlgorithm, signedContent, signer.EncryptedDigest); err != nil {
+			return 0, errors.Wrap(err, "invalid signature")
+		}
+	}
+	return result, nil
+}
+
+//signedAttribute represents PKCS#7 signed attribute with its original encoding
+type signedAttribute struct {
+	Type  asn1.ObjectIdentifier
+	Value asn1.RawValue `asn1:"set"`
+}
+
+//marshalAttributes returns DER SET OF signed attributes, signature is computed over this encoding
+func marshalAttributes(attributes []signedAttribute) ([]byte, error) {
+	data, err := asn1.Marshal(struct {
+		A []signedAttribute `asn1:"set"`
+	}{A: attributes})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode signed attributes")
+	}
+	var set asn1.RawValue
+	if _, err = asn1.Unmarshal(data, &set); err != nil {
+		return nil, errors.Wrap(err, "failed to encode signed attributes")
+	}
+	return set.Bytes, nil
+}
+
+func signatureAlgorithm(certificate *x509.Certificate, hash crypto.Hash) (x509.SignatureAlgorithm, error) {
+	switch certificate.PublicKey.(type) {
+	case *rsa.PublicKey:
+		switch hash {
+		case crypto.SHA1:
+			return x509.SHA1WithRSA, nil
+		case crypto.SHA256:
+			return x509.SHA256WithRSA, nil
+		case crypto.SHA384:
+			return x509.SHA384WithRSA, nil
+		case crypto.SHA512:
+			return x509.SHA512WithRSA, nil
+		}
+	case *ecdsa.PublicKey:
+		switch hash {
+		case crypto.SHA1:
+			return x509.ECDSAWithSHA1, nil
+		case crypto.SHA256:
+			return x509.ECDSAWithSHA256, nil
+		case crypto.SHA384:
+			return x509.ECDSAWithSHA384, nil
+		case crypto.SHA512:
+			return x509.ECDSAWithSHA512, nil
+		}
+	}
+	return 0, errors.Errorf("unsupported signature: %T with %v", certificate.PublicKey, hash)
+}
+
+func digestAlgorithm(hash crypto.Hash) (asn1.ObjectIdentifier, error) {
+	switch hash {
+	case crypto.SHA1:
+		return pkcs7.OIDDigestAlgorithmSHA1, nil
+	case crypto.SHA256:
+		return pkcs7.OIDDigestAlgorithmSHA256, nil
+	case crypto.SHA384:
+		return pkcs7.OIDDigestAlgorithmSHA384, nil
+	case crypto.SHA512:
+		return pkcs7.OIDDigestAlgorithmSHA512, nil
+	}
+	return nil, errors.Errorf("unsupported digest: %v", hash)
+}
+
+func digestHash(oid asn1.ObjectIdentifier) (crypto.Hash, error) {
+	switch {
+	case oid.Equal(pkcs7.OIDDigestAlgorithmSHA1), oid.Equal(pkcs7.OIDEncryptionAlgorithmRSASHA1):
+		return crypto.SHA1, nil
+	case oid.Equal(pkcs7.OIDDigestAlgorithmSHA256), oid.Equal(pkcs7.OIDEncryptionAlgorithmRSASHA256):
+		return crypto.SHA256, nil
+	case oid.Equal(pkcs7.OIDDigestAlgorithmSHA384), oid.Equal(pkcs7.OIDEncryptionAlgorithmRSASHA384):
+		return crypto.SHA384, nil
+	case oid.Equal(pkcs7.OIDDigestAlgorithmSHA512), oid.Equal(pkcs7.OIDEncryptionAlgorithmRSASHA512):
+		return crypto.SHA512, nil
+	}
+	return 0, errors.Errorf("unsupported digest algorithm: %v", oid)
+}
+
+//Decompress returns zlib reader of RFC 3274 compressed data, BER (indefinite length, constructed octets) is accepted
+func Decompress(compressed []byte) (io.ReadCloser, error) {
+	info, _, err := readBER(compressed)
+	if err != nil {
+		return nil, err
+	}
+	content, err := info.children(2)
+	if err != nil {
+		return nil, err
+	}
+	if !content[0].isOID(oidCompressedData) {
+		return nil, errors.New("content is not compressed data")
+	}
+	if content, err = content[1].children(1); err != nil {
+		return nil, err
+	}
+	compressedData, err := content[0].children(3)
+	if err != nil {
+		return nil, err
+	}
+	algorithm, err := compressedData[1].children(1)
+	if err != nil {
+		return nil, err
+	}
+	if !algorithm[0].isOID(oidZlib) {
+		return nil, errors.New("unsupported compression algorithm")
+	}
+	encapsulated, err := compressedData[2].children(2)
+	if err != nil {
+		return nil, err
+	}
+	if content, err = encapsulated[1].children(1); err != nil {
+		return nil, err
+	}
+	data, err := content[0].octets()
+	if err != nil {
+		return nil, err
+	}
+	reader, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decompress content")
+	}
+	return reader, nil
+}
+
+//berValue represents BER encoded value, content of indefinite length value excludes end of contents octets
+type berValue struct {
+	tag         int
+	constructed bool
+	content     []byte
+}
+
+//readBER reads one BER value, it returns value and remaining data
+func readBER(data []byte) (*berValue, []byte, error) {
+	if len(data) < 2 {
+		return nil, nil, errors.New("truncated BER value")
+	}
+	result := &berValue{tag: int(data[0] & 0x1f), constructed: data[0]&0x20 != 0}
+	offset := 1
+	if result.tag == 0x1f {
+		result.tag = 0
+		for {
+			if offset >= len(data) || offset > 4 {
+				return nil, nil, errors.New("invalid BER tag")
+			}
+			octet := data[offset]
+			offset++
+			result.tag = result.tag<<7 | int(octet&0x7f)
+			if octet&0x80 == 0 {
+				break
+			}
+		}
+	}
+	if offset >= len(data) {
+		return nil, nil, errors.New("truncated BER length")
+	}
+	length := int(data[offset])
+	offset++
+	if length == 0x80 {
+		if !result.constructed {
+			return nil, nil, errors.New("indefinite length primitive BER value")
+		}
+		rest := data[offset:]
+		for {
+			if len(rest) >= 2 && rest[0] == 0 && rest[1] == 0 {
+				result.content = data[offset : len(data)-len(rest)]
+				return result, rest[2:], nil
+			}
+			var err error
+			if _, rest, err = readBER(rest); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	if length > 0x80 {
+		count := length & 0x7f
+		if count > 4 || offset+count > len(data) {
+			return nil, nil, errors.New("invalid BER length")
+		}
+		length = 0
+		for _, octet := range data[offset : offset+count] {
+			length = length<<8 | int(octet)
+		}
+		offset += count
+	}
+	if length < 0 || offset+length > len(data) {
+		return nil, nil, errors.New("truncated BER value")
+	}
+	result.content = data[offset : offset+length]
+	return result, data[offset+length:], nil
+}
+
+//children returns constructed value elements, at least min elements are expected
+func (v *berValue) children(min int) ([]*berValue, error) {
+	if !v.constructed {
+		return nil, errors.Errorf("expected constructed BER value, but had tag %v", v.tag)
+	}
+	var result []*berValue
+	for rest := v.content; len(rest) > 0; {
+		child, next, err := readBER(rest)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, child)
+		rest = next
+	}
+	if len(result) < min {
+		return nil, errors.Errorf("expected %v BER elements, but had %v", min, len(result))
+	}
+	return result, nil
+}
+
+//octets returns primitive or constructed octet string content
+func (v *berValue) octets() ([]byte, error) {
+	if !v.constructed {
+		return v.content, nil
+	}
+	children, err := v.children(0)
+	if err != nil {
+		return nil, err
+	}
+	var result []byte
+	for _, child := range children {
+		data, err := child.octets()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, data...)
+	}
+	return result, nil
+}
+
+func (v *berValue) isOID(oid asn1.ObjectIdentifier) bool {
+	if v.constructed || v.tag != asn1.TagOID {
+		return false
+	}
+	data, err := asn1.Marshal(oid)
+	return err == nil && bytes.Equal(data[2:], v.content)
+}
+
+//ParseCertificates parses PEM (one or more CERTIFICATE blocks) or DER certificate
+func ParseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var result []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to parse certificate")
+		}
+		result = append(result, certificate)
+	}
+	if len(result) > 0 {
+		return result, nil
+	}
+	certificate, err := x509.ParseCertificate(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse certificate")
+	}
+	return []*x509.Certificate{certificate}, nil
+}
+
+//NewSigner creates a signer from PEM with certificate and private key (PKCS#1, SEC 1 or PKCS#8)
+func NewSigner(data []byte) (*Signer, error) {
+	result := &Signer{}
+	var err error
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		switch block.Type {
+		case "CERTIFICATE":
+			if result.Certificate != nil {
+				continue
+			}
+			if result.Certificate, err = x509.ParseCertificate(block.Bytes); err != nil {
+				return nil, errors.Wrap(err, "failed to parse certificate")
+			}
+		case "RSA PRIVATE KEY":
+			if result.Key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+				return nil, errors.Wrap(err, "failed to parse private key")
+			}
+		case "EC PRIVATE KEY":
+			if result.Key, err = x509.ParseECPrivateKey(block.Bytes); err != nil {
+				return nil, errors.Wrap(err, "failed to parse private key")
+			}
+		case "PRIVATE KEY":
+			if result.Key, err = x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+				return nil, errors.Wrap(err, "failed to parse private key")
+			}
+		}
+	}
+	if result.Certificate == nil || result.Key == nil {
+		return nil, errors.New("signer PEM requires CERTIFICATE and PRIVATE KEY blocks")
+	}
+	return result, nil
+}
@@ -0,0 +1,235 @@
+package as2
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func newTestSigner(t *testing.T, name string) *Signer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	return newTestIdentity(t, name, key, &key.PublicKey, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+func newTestECSigner(t *testing.T, name string) *Signer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	return newTestIdentity(t, name, key, &key.PublicKey, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func newTestIdentity(t *testing.T, name string, key crypto.Signer, publicKey crypto.PublicKey, keyPEM []byte) *Signer {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, publicKey, key)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	data := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), keyPEM...)
+	signer, err := NewSigner(data)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	return signer
+}
+
+func TestVerify(t *testing.T) {
+	signer := newTestSigner(t, "partner")
+	ecSigner := newTestECSigner(t, "ec-partner")
+	other := newTestSigner(t, "other")
+	content := []byte("Content-Type: text/csv\r\n\r\nid,name\r\n1,abc\r\n")
+	var useCases = []struct {
+		description  string
+		signer       *Signer
+		hash         crypto.Hash
+		content      []byte
+		certificates []*x509.Certificate
+		expectError  bool
+	}{
+		{
+			description:  "sha256 signature",
+			signer:       signer,
+			hash:         crypto.SHA256,
+			content:      content,
+			certificates: []*x509.Certificate{signer.Certificate},
+		},
+		{
+			description:  "sha1 signature",
+			signer:       signer,
+			hash:         crypto.SHA1,
+			content:      content,
+			certificates: []*x509.Certificate{signer.Certificate},
+		},
+		{
+			description:  "ecdsa signature",
+			signer:       ecSigner,
+			hash:         crypto.SHA384,
+			content:      content,
+			certificates: []*x509.Certificate{ecSigner.Certificate},
+		},
+		{
+			description:  "modified content",
+			signer:       signer,
+			hash:         crypto.SHA256,
+			content:      append(append([]byte{}, content...), '2'),
+			certificates: []*x509.Certificate{signer.Certificate},
+			expectError:  true,
+		},
+		{
+			description:  "untrusted certificate",
+			signer:       signer,
+			hash:         crypto.SHA256,
+			content:      content,
+			certificates: []*x509.Certificate{other.Certificate},
+			expectError:  true,
+		},
+		{
+			description: "no partner certificate",
+			signer:      signer,
+			hash:        crypto.SHA256,
+			content:     content,
+			expectError: true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		signature, err := useCase.signer.Sign(content, useCase.hash)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		hash, err := Verify(signature, bytes.NewReader(useCase.content), int64(len(useCase.content)), useCase.certificates)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+		assert.EqualValues(t, useCase.hash, hash, useCase.description)
+	}
+}
+
+//compressedData returns DER RFC 3274 compressed data
+func compressedData(t *testing.T, content []byte) []byte {
+	type encapsulated struct {
+		ContentType asn1.ObjectIdentifier
+		Content     []byte `asn1:"explicit,tag:0"`
+	}
+	type compressed struct {
+		Version   int
+		Algorithm pkix.AlgorithmIdentifier
+		Content   encapsulated
+	}
+	type contentInfo struct {
+		ContentType asn1.ObjectIdentifier
+		Content     compressed `asn1:"explicit,tag:0"`
+	}
+	data, err := asn1.Marshal(contentInfo{
+		ContentType: oidCompressedData,
+		Content: compressed{
+			Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidZlib},
+			Content:   encapsulated{ContentType: asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}, Content: zlibData(t, content)},
+		},
+	})
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	return data
+}
+
+//berCompressedData returns indefinite length RFC 3274 compressed data with chunked content octets
+func berCompressedData(t *testing.T, content []byte) []byte {
+	marshal := func(value interface{}) []byte {
+		data, err := asn1.Marshal(value)
+		if !assert.Nil(t, err) {
+			t.FailNow()
+		}
+		return data
+	}
+	indefinite := func(tag byte, values ...[]byte) []byte {
+		return append(append([]byte{tag, 0x80}, bytes.Join(values, nil)...), 0, 0)
+	}
+	compressed := zlibData(t, content)
+	half := len(compressed) / 2
+	return indefinite(0x30,
+		marshal(oidCompressedData),
+		indefinite(0xa0, indefinite(0x30,
+			marshal(0),
+			marshal(pkix.AlgorithmIdentifier{Algorithm: oidZlib}),
+			indefinite(0x30,
+				marshal(asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}),
+				indefinite(0xa0, indefinite(0x24, marshal(compressed[:half]), marshal(compressed[half:]))),
+			),
+		)),
+	)
+}
+
+func zlibData(t *testing.T, content []byte) []byte {
+	buffer := new(bytes.Buffer)
+	writer := zlib.NewWriter(buffer)
+	_, err := writer.Write(content)
+	if !assert.Nil(t, err) || !assert.Nil(t, writer.Close()) {
+		t.FailNow()
+	}
+	return buffer.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	content := []byte("Content-Type: text/csv\r\n\r\nid,name\r\n1,abc\r\n")
+	var useCases = []struct {
+		description string
+		data        []byte
+		expectError bool
+	}{
+		{
+			description: "DER compressed data",
+			data:        compressedData(t, content),
+		},
+		{
+			description: "BER compressed data",
+			data:        berCompressedData(t, content),
+		},
+		{
+			description: "truncated data",
+			data:        compressedData(t, content)[:20],
+			expectError: true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		reader, err := Decompress(useCase.data)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		actual, err := ioutil.ReadAll(reader)
+		assert.Nil(t, err, useCase.description)
+		assert.EqualValues(t, content, actual, useCase.description)
+	}
+}
@@ -0,0 +1,168 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/as2"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"math/big"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+//newAS2Signer returns partner signer with self signed certificate
+func newAS2Signer(t *testing.T) (*as2.Signer, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "secure"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	signer, err := as2.NewSigner(append(certificate, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})...))
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	return signer, certificate
+}
+
+func TestService_ReceiveAS2(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/as2")
+	cfg := &Config{
+		AS2: &config.AS2{
+			ID:      "smirror",
+			Landing: config.Resource{URL: "mem://localhost/as2/landing"},
+			Partners: []*config.AS2Partner{
+				{ID: "acme", AllowUnsigned: true},
+				{ID: "secure", CertificateURL: "mem://localhost/as2/certs/secure.pem"},
+			},
+		},
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/as2/landing/acme", Suffix: ".csv"}},
+				Dest:   &config.Resource{URL: "mem://localhost/as2/dest"},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	signer, certificate := newAS2Signer(t)
+	if !assert.Nil(t, fs.Upload(ctx, "mem://localhost/as2/certs/secure.pem", 0644, bytes.NewReader(certificate))) {
+		return
+	}
+	entity := []byte("Content-Type: text/csv\r\nContent-Disposition: attachment; filename=\"orders.csv\"\r\n\r\n1,2,3")
+	signature, err := signer.Sign(entity, crypto.SHA256)
+	if !assert.Nil(t, err) {
+		return
+	}
+	signed := fmt.Sprintf("--b1\r\n%s\r\n--b1\r\nContent-Type: application/pkcs7-signature\r\nContent-Transfer-Encoding: base64\r\n\r\n%v\r\n--b1--\r\n", entity, base64.StdEncoding.EncodeToString(signature))
+
+	var useCases = []struct {
+		description       string
+		from              string
+		contentType       string
+		body              string
+		expectURL         string
+		expectDisposition string
+		expectStatus      string
+	}{
+		{
+			description:       "landed and mirrored",
+			from:              "acme",
+			expectURL:         "mem://localhost/as2/landing/acme/orders.csv",
+			expectDisposition: "automatic-action/MDN-sent-automatically; processed",
+			expectStatus:      base.StatusOK,
+		},
+		{
+			description:       "unknown partner",
+			from:              "unknown",
+			expectDisposition: "automatic-action/MDN-sent-automatically; processed/error: " + as2.ErrorAuthentication,
+			expectStatus:      base.StatusError,
+		},
+		{
+			description:       "unsigned message",
+			from:              "secure",
+			expectDisposition: "automatic-action/MDN-sent-automatically; processed/error: " + as2.ErrorInsufficientSecurity,
+			expectStatus:      base.StatusError,
+		},
+		{
+			description:       "signed message",
+			from:              "secure",
+			contentType:       `multipart/signed; protocol="application/pkcs7-signature"; micalg=sha-256; boundary="b1"`,
+			body:              signed,
+			expectURL:         "mem://localhost/as2/landing/secure/orders.csv",
+			expectDisposition: "automatic-action/MDN-sent-automatically; processed",
+			expectStatus:      base.StatusOK,
+		},
+		{
+			description:       "tampered signed message",
+			from:              "secure",
+			contentType:       `multipart/signed; protocol="application/pkcs7-signature"; micalg=sha-256; boundary="b1"`,
+			body:              strings.Replace(signed, "1,2,3", "1,2,4", 1),
+			expectDisposition: "automatic-action/MDN-sent-automatically; processed/error: " + as2.ErrorIntegrity,
+			expectStatus:      base.StatusError,
+		},
+	}
+
+	for _, useCase := range useCases {
+		header := http.Header{}
+		header.Set(as2.HeaderFrom, useCase.from)
+		header.Set(as2.HeaderTo, "smirror")
+		header.Set(as2.HeaderMessageID, "<"+useCase.from+"-1@partner>")
+		header.Set(as2.HeaderDispositionTo, "mdn@partner")
+		header.Set("Content-Type", "application/octet-stream")
+		header.Set("Content-Disposition", `attachment; filename="orders.csv"`)
+		body := "1,2,3"
+		if useCase.body != "" {
+			header.Set("Content-Type", useCase.contentType)
+			body = useCase.body
+		}
+		response := service.ReceiveAS2(ctx, &contract.AS2Request{Header: header, Body: strings.NewReader(body)})
+		assert.EqualValues(t, useCase.expectStatus, response.Status, useCase.description+" "+response.Error)
+		assert.EqualValues(t, useCase.expectDisposition, response.Disposition, useCase.description)
+		assert.EqualValues(t, useCase.expectURL, response.URL, useCase.description)
+		assert.EqualValues(t, useCase.from, response.MDNHeader.Get(as2.HeaderTo), useCase.description)
+		assert.Contains(t, string(response.MDN), response.Disposition, useCase.description)
+		if useCase.expectURL == "" {
+			continue
+		}
+		landed, err := fs.DownloadWithURL(ctx, useCase.expectURL)
+		assert.Nil(t, err, useCase.description)
+		assert.EqualValues(t, "1,2,3", string(landed), useCase.description)
+		if useCase.from != "acme" {
+			continue
+		}
+		if assert.NotNil(t, response.Mirror, useCase.description) {
+			assert.EqualValues(t, base.StatusOK, response.Mirror.Status, useCase.description)
+		}
+		exists, _ := fs.Exists(ctx, "mem://localhost/as2/dest/as2/landing/acme/orders.csv")
+		assert.True(t, exists, useCase.description)
+	}
+}
@@ -0,0 +1,282 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+	"fmt"
+	"github.com/pkg/errors"
+	"google.golang.org/api/idtoken"
+	"net/http"
+	"strings"
+)
+
+const (
+	//RoleReader read-only control plane access
+	RoleReader = "reader"
+	//RoleAdmin admin control plane access, includes reader access
+	RoleAdmin = "admin"
+
+	//AnyPrincipal matches any authenticated principal
+	AnyPrincipal = "*"
+
+	//IAPHeader Google IAP signed JWT assertion header
+	IAPHeader = "X-Goog-Iap-Jwt-Assertion"
+	//APIKeyHeader API key header
+	APIKeyHeader = "X-Api-Key"
+
+	//EndpointMonitor monitor endpoint
+	EndpointMonitor = "monitor"
+	//EndpointConfig config export endpoint
+	EndpointConfig = "config"
+	//EndpointReplay replay endpoint
+	EndpointReplay = "replay"
+	//EndpointMigrate migrate endpoint
+	EndpointMigrate = "migrate"
+	//EndpointActivate staged rules activation endpoint
+	EndpointActivate = "activate"
+	//EndpointRules rule files status endpoint
+	EndpointRules = "rules"
+	//EndpointPause ingestion pause and resume endpoint
+	EndpointPause = "pause"
+	//EndpointCron cron tick endpoint
+	EndpointCron = "cron"
+	//EndpointEvents event log cursor endpoint
+	EndpointEvents = "events"
+	//EndpointDashboard Grafana dashboard data endpoint
+	EndpointDashboard = "dashboard"
+	//EndpointMirror mirror request submit endpoint
+	EndpointMirror = "mirror"
+	//EndpointOpenAPI OpenAPI document endpoint
+	EndpointOpenAPI = "openapi"
+	//EndpointBatch batch mirror request submit endpoint
+	EndpointBatch = "batch"
+	//EndpointHistory rule history evaluation endpoint
+	EndpointHistory = "history"
+)
+
+var defaultEndpointRoles = map[string]string{
+	EndpointMonitor:   RoleReader,
+	EndpointConfig:    RoleReader,
+	EndpointReplay:    RoleAdmin,
+	EndpointMigrate:   RoleAdmin,
+	EndpointActivate:  RoleAdmin,
+	EndpointRules:     RoleReader,
+	EndpointPause:     RoleAdmin,
+	EndpointCron:      RoleAdmin,
+	EndpointEvents:    RoleReader,
+	EndpointDashboard: RoleReader,
+	EndpointMirror:    RoleAdmin,
+	EndpointOpenAPI:   RoleReader,
+	EndpointBatch:     RoleAdmin,
+	EndpointHistory:   RoleReader,
+}
+
+var validateToken = idtoken.Validate
+
+//Access represents control plane authentication and per endpoint authorization
+type Access struct {
+	//JWT Google IAM (Authorization bearer ID token) or IAP (signed header assertion) JWT validation
+	JWT *JWT `json:",omitempty"`
+	//APIKeys secret with JSON object mapping API key to role
+	APIKeys *Secret `json:",omitempty"`
+	//MTLS verified client certificate validation
+	MTLS *MTLS `json:",omitempty"`
+	//Endpoints endpoint required role, unlisted endpoints use reader for monitor/config and admin otherwise
+	Endpoints map[string]string `json:",omitempty"`
+	keys      map[string]string
+}
+
+//JWT represents Google IAM/IAP JWT validation
+type JWT struct {
+	//Audience expected token audience, i.e. function URL or /projects/PROJECT_NUMBER/global/backendServices/SERVICE_ID for IAP
+	Audience string
+	//Principals maps token email to role
+	Principals map[string]string
+}
+
+//MTLS represents client certificate validation, TLS has to be terminated by the process with client certificate verification
+type MTLS struct {
+	//Principals maps certificate common name, DNS, email or URI SAN to role
+	Principals map[string]string
+}
+
+//Denied represents authentication or authorization failure
+type Denied struct {
+	Code   int
+	Reason string
+}
+
+//Error returns error message
+func (d *Denied) Error() string {
+	return d.Reason
+}
+
+//IsValidRole returns true if role is known
+func IsValidRole(role string) bool {
+	return role == RoleReader || role == RoleAdmin
+}
+
+//Grants returns true if granted role satisfies required role
+func Grants(granted, required string) bool {
+	switch granted {
+	case RoleAdmin:
+		return true
+	case RoleReader:
+		return required == RoleReader
+	}
+	return false
+}
+
+//Init sets decrypted API keys
+func (a *Access) Init(keys map[string]string) {
+	a.keys = keys
+}
+
+//Validate checks if access settings are valid
+func (a *Access) Validate() error {
+	if a.JWT == nil && a.APIKeys == nil && a.MTLS == nil {
+		return errors.New("access requires at least one of JWT, APIKeys, MTLS")
+	}
+	if a.JWT != nil {
+		if a.JWT.Audience == "" {
+			return errors.New("access.JWT.Audience was empty")
+		}
+		if err := validatePrincipals("JWT", a.JWT.Principals); err != nil {
+			return err
+		}
+	}
+	if a.MTLS != nil {
+		if err := validatePrincipals("MTLS", a.MTLS.Principals); err != nil {
+			return err
+		}
+	}
+	for endpoint, role := range a.Endpoints {
+		if !IsValidRole(role) {
+			return errors.Errorf("invalid access.Endpoints.%v role: %v", endpoint, role)
+		}
+	}
+	return nil
+}
+
+func validatePrincipals(kind string, principals map[string]string) error {
+	if len(principals) == 0 {
+		return errors.Errorf("access.%v.Principals was empty", kind)
+	}
+	for principal, role := range principals {
+		if !IsValidRole(role) {
+			return errors.Errorf("invalid access.%v.Principals.%v role: %v", kind, principal, role)
+		}
+	}
+	return nil
+}
+
+//Role returns role required by endpoint
+func (a *Access) Role(endpoint string) string {
+	if role, ok := a.Endpoints[endpoint]; ok {
+		return role
+	}
+	if role, ok := defaultEndpointRoles[endpoint]; ok {
+		return role
+	}
+	return RoleAdmin
+}
+
+//Authorize authenticates request and checks if principal role satisfies endpoint role, it returns principal
+func (a *Access) Authorize(ctx context.Context, request *http.Request, endpoint string) (string, error) {
+	principal, role, err := a.authenticate(ctx, request)
+	if err != nil {
+		return "", err
+	}
+	if required := a.Role(endpoint); !Grants(role, required) {
+		return principal, &Denied{Code: http.StatusForbidden, Reason: fmt.Sprintf("%v (%v) is not allowed to access %v, required role: %v", principal, role, endpoint, required)}
+	}
+	return principal, nil
+}
+
+//Handler returns handler authorizing requests before delegating to handler
+func (a *Access) Handler(endpoint string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if a.Check(writer, request, endpoint) {
+			handler(writer, request)
+		}
+	}
+}
+
+//Check authorizes request, it writes error response and returns false if request is denied
+func (a *Access) Check(writer http.ResponseWriter, request *http.Request, endpoint string) bool {
+	if _, err := a.Authorize(request.Context(), request, endpoint); err != nil {
+		code := http.StatusUnauthorized
+		if denied, ok := err.(*Denied); ok {
+			code = denied.Code
+		}
+		http.Error(writer, err.Error(), code)
+		return false
+	}
+	return true
+}
+
+func (a *Access) authenticate(ctx context.Context, request *http.Request) (string, string, error) {
+	if a.MTLS != nil && request.TLS != nil && len(request.TLS.VerifiedChains) > 0 {
+		if principal, role, ok := a.MTLS.match(request.TLS.VerifiedChains[0][0]); ok {
+			return principal, role, nil
+		}
+	}
+	if key := request.Header.Get(APIKeyHeader); key != "" && a.APIKeys != nil {
+		for candidate, role := range a.keys {
+			if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+				return "api-key", role, nil
+			}
+		}
+		return "", "", &Denied{Code: http.StatusUnauthorized, Reason: "invalid API key"}
+	}
+	if a.JWT != nil {
+		if token := bearerToken(request); token != "" {
+			return a.JWT.authenticate(ctx, token)
+		}
+	}
+	return "", "", &Denied{Code: http.StatusUnauthorized, Reason: "missing credentials"}
+}
+
+func (j *JWT) authenticate(ctx context.Context, token string) (string, string, error) {
+	payload, err := validateToken(ctx, token, j.Audience)
+	if err != nil {
+		return "", "", &Denied{Code: http.StatusUnauthorized, Reason: fmt.Sprintf("invalid token: %v", err)}
+	}
+	email, _ := payload.Claims["email"].(string)
+	if role, ok := j.Principals[email]; ok && email != "" {
+		return email, role, nil
+	}
+	if role, ok := j.Principals[AnyPrincipal]; ok {
+		return email, role, nil
+	}
+	return "", "", &Denied{Code: http.StatusForbidden, Reason: fmt.Sprintf("unknown principal: %v", email)}
+}
+
+func (m *MTLS) match(certificate *x509.Certificate) (string, string, bool) {
+	candidates := append([]string{certificate.Subject.CommonName}, certificate.DNSNames...)
+	candidates = append(candidates, certificate.EmailAddresses...)
+	for _, URI := range certificate.URIs {
+		candidates = append(candidates, URI.String())
+	}
+	for _, candidate := range candidates {
+		if role, ok := m.Principals[candidate]; ok && candidate != "" {
+			return candidate, role, true
+		}
+	}
+	if role, ok := m.Principals[AnyPrincipal]; ok {
+		return certificate.Subject.CommonName, role, true
+	}
+	return "", "", false
+}
+
+func bearerToken(request *http.Request) string {
+	if assertion := request.Header.Get(IAPHeader); assertion != "" {
+		return assertion
+	}
+	authorization := request.Header.Get("Authorization")
+	if len(authorization) > 7 && strings.EqualFold(authorization[:7], "bearer ") {
+		return strings.TrimSpace(authorization[7:])
+	}
+	return ""
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/idtoken"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccess_Authorize(t *testing.T) {
+	validateToken = func(ctx context.Context, token string, audience string) (*idtoken.Payload, error) {
+		if token != "valid" {
+			return nil, errors.New("invalid signature")
+		}
+		return &idtoken.Payload{Audience: audience, Claims: map[string]interface{}{"email": "ops@example.com"}}, nil
+	}
+	defer func() { validateToken = idtoken.Validate }()
+	access := &Access{
+		JWT:     &JWT{Audience: "https://example.com", Principals: map[string]string{"ops@example.com": RoleReader}},
+		APIKeys: &Secret{URL: "mem://localhost/keys.json"},
+		MTLS:    &MTLS{Principals: map[string]string{"deployer": RoleAdmin}},
+	}
+	if !assert.Nil(t, access.Validate()) {
+		return
+	}
+	access.Init(map[string]string{"k1": RoleAdmin})
+
+	var useCases = []struct {
+		description string
+		header      map[string]string
+		commonName  string
+		endpoint    string
+		expectCode  int
+	}{
+		{description: "missing credentials", endpoint: EndpointMonitor, expectCode: http.StatusUnauthorized},
+		{description: "api key admin", header: map[string]string{APIKeyHeader: "k1"}, endpoint: EndpointReplay, expectCode: http.StatusOK},
+		{description: "invalid api key", header: map[string]string{APIKeyHeader: "k2"}, endpoint: EndpointMonitor, expectCode: http.StatusUnauthorized},
+		{description: "bearer reader", header: map[string]string{"Authorization": "Bearer valid"}, endpoint: EndpointMonitor, expectCode: http.StatusOK},
+		{description: "iap reader on admin endpoint", header: map[string]string{IAPHeader: "valid"}, endpoint: EndpointMigrate, expectCode: http.StatusForbidden},
+		{description: "invalid token", header: map[string]string{"Authorization": "Bearer forged"}, endpoint: EndpointMonitor, expectCode: http.StatusUnauthorized},
+		{description: "client certificate admin", commonName: "deployer", endpoint: EndpointActivate, expectCode: http.StatusOK},
+		{description: "unknown client certificate", commonName: "other", endpoint: EndpointConfig, expectCode: http.StatusUnauthorized},
+	}
+
+	for _, useCase := range useCases {
+		request := httptest.NewRequest(http.MethodPost, "/", nil)
+		for k, v := range useCase.header {
+			request.Header.Set(k, v)
+		}
+		if useCase.commonName != "" {
+			certificate := &x509.Certificate{Subject: pkix.Name{CommonName: useCase.commonName}}
+			request.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{certificate}}}
+		}
+		recorder := httptest.NewRecorder()
+		access.Handler(useCase.endpoint, func(writer http.ResponseWriter, request *http.Request) {})(recorder, request)
+		assert.Equal(t, useCase.expectCode, recorder.Code, useCase.description)
+	}
+}
@@ -111,12 +111,15 @@ func (s *Service) handleMessage(ctx context.Context, msg *sqs.Message) (bool, er
 		fmt.Printf("%s\n", *msg.Body)
 	}
 	response := service.Mirror(ctx, contract.NewRequest(s3Event.URL()))
+	if response.IsLogSuppressed() {
+		return !response.IsRetryable(), nil
+	}
 	output, err := json.Marshal(response)
 	if err != nil {
 		fmt.Printf("failed marshal reported %v\n", response)
 	}
 	fmt.Printf("%s\n", output)
-	return true, nil
+	return !response.IsRetryable(), nil
 }
 
 func (s *Service) getQueueURL() (string, error) {
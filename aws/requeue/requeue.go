@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/pkg/errors"
+	_ "github.com/viant/afsc/gs"
+	_ "github.com/viant/afsc/s3"
+	"github.com/viant/smirror"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/event"
+	"github.com/viant/smirror/job"
+)
+
+func main() {
+	lambda.Start(handleMessages)
+}
+
+//handleMessages mirrors S3 events republished to SQS by requeue action, message attempt attribute is passed to mirror request
+func handleMessages(ctx context.Context, sqsEvent events.SQSEvent) error {
+	if len(sqsEvent.Records) == 0 {
+		return nil
+	}
+	service, err := smirror.NewFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		return err
+	}
+	for _, record := range sqsEvent.Records {
+		s3Event, err := event.NewS3EventFromJSON([]byte(record.Body))
+		if err != nil {
+			return errors.Wrapf(err, "unable unmarshal s3 event from %s", record.Body)
+		}
+		attempt := job.SQSAttempt(record)
+		err = s3Event.Each(func(URL string) error {
+			request := contract.NewRequest(URL)
+			request.Requeue = attempt
+			response := service.Mirror(ctx, request)
+			if response.IsLogSuppressed() {
+				return nil
+			}
+			if data, err := json.Marshal(response); err == nil {
+				fmt.Printf("%s\n", string(data))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -36,6 +36,9 @@ func handleRequest(ctx context.Context, s3Event events.S3Event) error {
 	for _, resource := range s3Event.Records {
 		URL := resourceURL(resource)
 		response := service.Mirror(ctx, contract.NewRequest(URL))
+		if response.IsLogSuppressed() {
+			continue
+		}
 		if data, err := json.Marshal(response); err == nil {
 			fmt.Printf("%s\n", string(data))
 		}
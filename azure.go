@@ -0,0 +1,76 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/azure"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"sync"
+)
+
+//azurePublishers publishers keyed by endpoint and credentials, to reuse AAD tokens
+var azurePublishers = sync.Map{}
+
+func azurePublisher(resource *config.Resource, endpoint string) (*azure.Publisher, error) {
+	if resource.Credentials == nil || len(resource.Credentials.Auth) == 0 {
+		return nil, errors.New("azure credentials were not decrypted")
+	}
+	key := endpoint + "/" + string(resource.Credentials.Auth)
+	if publisher, ok := azurePublishers.Load(key); ok {
+		return publisher.(*azure.Publisher), nil
+	}
+	credentials, err := azure.NewCredentials(resource.Credentials.Auth)
+	if err != nil {
+		return nil, err
+	}
+	publisher, err := azure.New(resource.Vendor, endpoint, resource.Azure.EventType, credentials, nil)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := azurePublishers.LoadOrStore(key, publisher)
+	return actual.(*azure.Publisher), nil
+}
+
+//publishAzure publishes transfer data to Event Grid topic or Service Bus queue/topic, split output records are published in batches
+func (s *service) publishAzure(ctx context.Context, transfer *Transfer, data []byte, response *contract.Response) error {
+	settings := transfer.Resource.Azure
+	dest := transfer.MessageDest()
+	publisher, err := azurePublisher(transfer.Resource, settings.Endpoint(transfer.Resource.Vendor, dest))
+	if err != nil {
+		return base.NewCodedError(base.ErrorCodeAuth, err)
+	}
+	records := [][]byte{data}
+	if transfer.splitCounter > 0 {
+		records = bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	}
+	properties := map[string]string{base.SourceAttribute: response.TriggeredBy, base.TransferIDKey: response.TransferID}
+	messages := make([]*azure.Message, 0, len(records))
+	for i, record := range records {
+		if len(record) == 0 {
+			continue
+		}
+		messages = append(messages, &azure.Message{
+			ID:            fmt.Sprintf("%v-%v-%v", response.TransferID, transfer.splitCounter, i),
+			Subject:       response.TriggeredBy,
+			CorrelationID: response.CorrelationID,
+			Data:          settings.Payload(record, response.TriggeredBy, dest, response.TransferID, transfer.partition),
+			Properties:    properties,
+		})
+	}
+	waited, err := s.limiter.Wait(ctx, dest)
+	response.AddThrottleTime(waited)
+	if err != nil {
+		return err
+	}
+	IDs, err := publisher.Publish(ctx, messages, settings.BatchSize)
+	response.AddMessageIDs(IDs...)
+	if err != nil {
+		s.limiter.Report(dest, err)
+		return errors.Wrapf(err, "failed to publish to %v %v", transfer.Resource.Vendor, dest)
+	}
+	return nil
+}
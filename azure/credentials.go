@@ -0,0 +1,47 @@
+package azure
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/pkg/errors"
+)
+
+//Credentials represents decrypted Azure credentials, either shared access key or AAD client credentials
+type Credentials struct {
+	//SASKeyName Service Bus shared access policy name, i.e. RootManageSharedAccessKey
+	SASKeyName string `json:",omitempty"`
+	//SASKey Event Grid topic access key or Service Bus shared access policy key
+	SASKey string `json:",omitempty"`
+	//TenantID AAD tenant ID
+	TenantID string `json:",omitempty"`
+	//ClientID AAD application (service principal) client ID
+	ClientID string `json:",omitempty"`
+	//ClientSecret AAD application client secret
+	ClientSecret string `json:",omitempty"`
+	//AuthorityHost AAD authority host, default https://login.microsoftonline.com
+	AuthorityHost string `json:",omitempty"`
+}
+
+//IsSAS returns true if credentials use shared access key
+func (c *Credentials) IsSAS() bool {
+	return c.SASKey != ""
+}
+
+//NewCredentials creates credentials from decrypted secret JSON
+func NewCredentials(secret []byte) (*Credentials, error) {
+	secret = bytes.TrimSpace(secret)
+	if len(secret) == 0 {
+		return nil, errors.New("azure credentials were empty")
+	}
+	credentials := &Credentials{}
+	if err := json.Unmarshal(secret, credentials); err != nil {
+		return nil, errors.Wrap(err, "failed to decode azure credentials")
+	}
+	if credentials.SASKey == "" && (credentials.TenantID == "" || credentials.ClientID == "" || credentials.ClientSecret == "") {
+		return nil, errors.New("azure credentials require SASKey or TenantID, ClientID and ClientSecret")
+	}
+	if credentials.AuthorityHost == "" {
+		credentials.AuthorityHost = "https://login.microsoftonline.com"
+	}
+	return credentials, nil
+}
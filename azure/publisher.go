@@ -0,0 +1,261 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	//KindEventGrid Event Grid topic publisher
+	KindEventGrid = "eventgrid"
+	//KindServiceBus Service Bus queue or topic publisher
+	KindServiceBus = "servicebus"
+
+	//MaxEventGridBatchBytes Event Grid max publish request size
+	MaxEventGridBatchBytes = 1024 * 1024
+	//MaxServiceBusBatchBytes Service Bus (standard tier) max batch size
+	MaxServiceBusBatchBytes = 256 * 1024
+
+	eventGridAPIVersion  = "2018-01-01"
+	eventGridScope       = "https://eventgrid.azure.net/.default"
+	serviceBusScope      = "https://servicebus.azure.net/.default"
+	serviceBusBatchType  = "application/vnd.microsoft.servicebus.json"
+	sasTokenTTL          = time.Hour
+	defaultEventType     = "smirror.transfer"
+	eventGridDataVersion = "1.0"
+)
+
+//Message represents a message
+type Message struct {
+	ID            string
+	Subject       string
+	CorrelationID string
+	Data          []byte
+	Properties    map[string]string
+}
+
+//Publisher represents Event Grid topic or Service Bus queue/topic REST publisher
+type Publisher struct {
+	kind        string
+	endpoint    string
+	eventType   string
+	credentials *Credentials
+	http        *http.Client
+	mux         sync.Mutex
+	token       string
+	expiry      time.Time
+}
+
+type event struct {
+	ID          string      `json:"id"`
+	EventType   string      `json:"eventType"`
+	Subject     string      `json:"subject"`
+	EventTime   string      `json:"eventTime"`
+	Data        interface{} `json:"data"`
+	DataVersion string      `json:"dataVersion"`
+}
+
+type serviceBusMessage struct {
+	Body             string
+	BrokerProperties map[string]interface{}
+	UserProperties   map[string]string `json:",omitempty"`
+}
+
+//Publish publishes messages in batches of up to batchSize messages, it returns published message IDs
+func (p *Publisher) Publish(ctx context.Context, messages []*Message, batchSize int) ([]string, error) {
+	if batchSize <= 0 {
+		batchSize = 1
+	}
+	maxBytes := MaxServiceBusBatchBytes
+	if p.kind == KindEventGrid {
+		maxBytes = MaxEventGridBatchBytes
+	}
+	var IDs = make([]string, 0, len(messages))
+	var batch = make([]json.RawMessage, 0, batchSize)
+	var pending = make([]string, 0, batchSize)
+	batchBytes := 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := p.send(ctx, batch); err != nil {
+			return err
+		}
+		IDs = append(IDs, pending...)
+		batch, pending, batchBytes = batch[:0], pending[:0], 0
+		return nil
+	}
+	for _, message := range messages {
+		encoded, err := p.encode(message)
+		if err != nil {
+			return IDs, err
+		}
+		if len(batch) > 0 && (len(batch) >= batchSize || batchBytes+len(encoded)+1 > maxBytes) {
+			if err = flush(); err != nil {
+				return IDs, err
+			}
+		}
+		batch = append(batch, encoded)
+		pending = append(pending, message.ID)
+		batchBytes += len(encoded) + 1
+	}
+	return IDs, flush()
+}
+
+func (p *Publisher) encode(message *Message) (json.RawMessage, error) {
+	if p.kind == KindEventGrid {
+		var data interface{} = string(message.Data)
+		if json.Valid(message.Data) {
+			data = json.RawMessage(message.Data)
+		}
+		return json.Marshal(&event{
+			ID:          message.ID,
+			EventType:   p.eventType,
+			Subject:     message.Subject,
+			EventTime:   time.Now().UTC().Format(time.RFC3339Nano),
+			Data:        data,
+			DataVersion: eventGridDataVersion,
+		})
+	}
+	brokerProperties := map[string]interface{}{"MessageId": message.ID}
+	if message.CorrelationID != "" {
+		brokerProperties["CorrelationId"] = message.CorrelationID
+	}
+	if message.Subject != "" {
+		brokerProperties["Label"] = message.Subject
+	}
+	return json.Marshal(&serviceBusMessage{
+		Body:             string(message.Data),
+		BrokerProperties: brokerProperties,
+		UserProperties:   message.Properties,
+	})
+}
+
+func (p *Publisher) send(ctx context.Context, batch []json.RawMessage) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	URL := p.endpoint
+	contentType := serviceBusBatchType
+	if p.kind == KindEventGrid {
+		URL += "?api-version=" + eventGridAPIVersion
+		contentType = "application/json"
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", contentType)
+	if err = p.authorize(ctx, request); err != nil {
+		return err
+	}
+	response, err := p.http.Do(request)
+	if err != nil {
+		return errors.Wrapf(err, "failed to publish to %v", p.endpoint)
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		data, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("failed to publish %v message(s) to %v: %v %s", len(batch), p.endpoint, response.Status, data)
+	}
+	return nil
+}
+
+func (p *Publisher) authorize(ctx context.Context, request *http.Request) error {
+	if p.credentials.IsSAS() {
+		if p.kind == KindEventGrid {
+			request.Header.Set("aeg-sas-key", p.credentials.SASKey)
+			return nil
+		}
+		request.Header.Set("Authorization", p.sasToken(time.Now().Add(sasTokenTTL)))
+		return nil
+	}
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+//sasToken returns Service Bus shared access signature token for publisher entity
+func (p *Publisher) sasToken(expiry time.Time) string {
+	resource := url.QueryEscape(strings.ToLower(strings.TrimSuffix(p.endpoint, "/messages")))
+	expires := fmt.Sprintf("%d", expiry.Unix())
+	mac := hmac.New(sha256.New, []byte(p.credentials.SASKey))
+	mac.Write([]byte(resource + "\n" + expires))
+	signature := url.QueryEscape(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return fmt.Sprintf("SharedAccessSignature sr=%s&sig=%s&se=%s&skn=%s", resource, signature, expires, p.credentials.SASKeyName)
+}
+
+//accessToken returns cached AAD client credentials access token
+func (p *Publisher) accessToken(ctx context.Context) (string, error) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if p.token != "" && time.Now().Before(p.expiry) {
+		return p.token, nil
+	}
+	scope := serviceBusScope
+	if p.kind == KindEventGrid {
+		scope = eventGridScope
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {p.credentials.ClientID},
+		"client_secret": {p.credentials.ClientSecret},
+		"scope":         {scope},
+	}
+	tokenURL := strings.TrimRight(p.credentials.AuthorityHost, "/") + "/" + url.PathEscape(p.credentials.TenantID) + "/oauth2/v2.0/token"
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	response, err := p.http.Do(request)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get AAD token")
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		data, _ := ioutil.ReadAll(response.Body)
+		return "", fmt.Errorf("failed to get AAD token: %v %s", response.Status, data)
+	}
+	token := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}{}
+	if err = json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", errors.Wrap(err, "failed to decode AAD token")
+	}
+	p.token = token.AccessToken
+	//refresh a minute before expiry
+	p.expiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return p.token, nil
+}
+
+//New creates a publisher, endpoint is Event Grid topic endpoint or Service Bus entity messages URL
+func New(kind, endpoint, eventType string, credentials *Credentials, httpClient *http.Client) (*Publisher, error) {
+	if kind != KindEventGrid && kind != KindServiceBus {
+		return nil, errors.Errorf("unsupported azure publisher kind: %v", kind)
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: time.Minute}
+	}
+	if eventType == "" {
+		eventType = defaultEventType
+	}
+	return &Publisher{kind: kind, endpoint: endpoint, eventType: eventType, credentials: credentials, http: httpClient}, nil
+}
@@ -0,0 +1,76 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPublisher_Publish(t *testing.T) {
+	var batches [][]map[string]interface{}
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		if strings.HasSuffix(request.URL.Path, "/oauth2/v2.0/token") {
+			assert.Equal(t, "/tenant/oauth2/v2.0/token", request.URL.Path)
+			assert.Contains(t, string(body), "scope=https%3A%2F%2Fservicebus.azure.net%2F.default")
+			_, _ = writer.Write([]byte(`{"access_token":"aad-token","expires_in":3600}`))
+			return
+		}
+		batch := []map[string]interface{}{}
+		assert.Nil(t, json.Unmarshal(body, &batch))
+		batches = append(batches, batch)
+		headers = append(headers, request.Header.Get("aeg-sas-key")+request.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	var messages []*Message
+	for i := 0; i < 5; i++ {
+		messages = append(messages, &Message{ID: fmt.Sprintf("id-%v", i), Subject: "a.csv", Data: []byte(fmt.Sprintf(`{"id":%v}`, i))})
+	}
+
+	credentials, err := NewCredentials([]byte(`{"SASKey":"key"}`))
+	assert.Nil(t, err)
+	publisher, err := New(KindEventGrid, server.URL+"/api/events", "", credentials, nil)
+	assert.Nil(t, err)
+	IDs, err := publisher.Publish(ctx, messages, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id-0", "id-1", "id-2", "id-3", "id-4"}, IDs)
+	assert.Equal(t, 3, len(batches))
+	assert.Equal(t, 2, len(batches[0]))
+	assert.Equal(t, map[string]interface{}{"id": float64(0)}, batches[0][0]["data"])
+	assert.Equal(t, "smirror.transfer", batches[0][0]["eventType"])
+	assert.Equal(t, []string{"key", "key", "key"}, headers)
+
+	batches, headers = nil, nil
+	credentials, err = NewCredentials([]byte(`{"TenantID":"tenant","ClientID":"id","ClientSecret":"secret","AuthorityHost":"` + server.URL + `"}`))
+	assert.Nil(t, err)
+	publisher, err = New(KindServiceBus, server.URL+"/orders/messages", "", credentials, nil)
+	assert.Nil(t, err)
+	_, err = publisher.Publish(ctx, messages[:2], 10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(batches))
+	assert.Equal(t, `{"id":1}`, batches[0][1]["Body"])
+	assert.Equal(t, "id-1", batches[0][1]["BrokerProperties"].(map[string]interface{})["MessageId"])
+	assert.Equal(t, []string{"Bearer aad-token"}, headers)
+
+	_, err = NewCredentials([]byte(`{"ClientID":"id"}`))
+	assert.NotNil(t, err)
+}
+
+func TestPublisher_sasToken(t *testing.T) {
+	credentials, err := NewCredentials([]byte(`{"SASKeyName":"send","SASKey":"key"}`))
+	assert.Nil(t, err)
+	publisher, err := New(KindServiceBus, "https://ns.servicebus.windows.net/orders/messages", "", credentials, nil)
+	assert.Nil(t, err)
+	token := publisher.sasToken(time.Unix(1700000000, 0))
+	assert.True(t, strings.HasPrefix(token, "SharedAccessSignature sr=https%3A%2F%2Fns.servicebus.windows.net%2Forders&sig="))
+	assert.True(t, strings.HasSuffix(token, "&se=1700000000&skn=send"))
+}
@@ -0,0 +1,66 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/shared"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestService_Azure(t *testing.T) {
+	var bodies []string
+	mux := sync.Mutex{}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/orders/messages", request.URL.Path)
+		assert.True(t, strings.HasPrefix(request.Header.Get("Authorization"), "SharedAccessSignature "))
+		batch := []map[string]interface{}{}
+		data, _ := ioutil.ReadAll(request.Body)
+		assert.Nil(t, json.Unmarshal(data, &batch))
+		mux.Lock()
+		defer mux.Unlock()
+		for _, message := range batch {
+			bodies = append(bodies, message["Body"].(string))
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/azure/data"}},
+				Dest: &config.Resource{
+					Queue:       "orders",
+					Vendor:      shared.VendorServiceBus,
+					Credentials: &auth.Credentials{Auth: []byte(`{"SASKeyName":"send","SASKey":"key"}`)},
+					Azure:       &config.Azure{Namespace: server.URL, Template: `{"source":"$SourceURL","record":$Data}`, BatchSize: 2},
+				},
+				Split: &config.Split{MaxLines: 3},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sourceURL := "mem://localhost/azure/data/a.json"
+	_ = afs.New().Upload(ctx, sourceURL, 0644, strings.NewReader("{\"id\":1}\n{\"id\":2}\n{\"id\":3}\n{\"id\":4}\n"))
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	if !assert.Equal(t, base.StatusOK, response.Status, response.Error) {
+		return
+	}
+	assert.Equal(t, 4, len(response.MessageIDs))
+	assert.Equal(t, 4, len(bodies))
+	assert.Contains(t, bodies, `{"source":"mem://localhost/azure/data/a.json","record":{"id":4}}`)
+}
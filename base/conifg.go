@@ -3,10 +3,15 @@ package base
 import (
 	"cloud.google.com/go/compute/metadata"
 	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/auth"
 	"github.com/viant/afsc/gs"
 	"github.com/viant/afsc/s3"
 	"golang.org/x/oauth2/google"
 	"os"
+	"strings"
 )
 
 //Config represents a base config
@@ -15,6 +20,10 @@ type Config struct {
 	Region       string
 	ProjectID    string
 	SourceScheme string
+	//ResponseLog persists every response JSON partitioned by date, rule and status
+	ResponseLog *ResponseLog `json:",omitempty"`
+	//Access control plane endpoints authentication and authorization
+	Access *auth.Access `json:",omitempty"`
 }
 
 func (c *Config) Init() {
@@ -38,3 +47,29 @@ func (c *Config) Init() {
 		}
 	}
 }
+
+//NewConfigFromEnv returns base config from env JSON or URL, empty config if env is not set
+func NewConfigFromEnv(ctx context.Context, key string) (*Config, error) {
+	cfg := &Config{}
+	JSONOrURL := strings.TrimSpace(os.Getenv(key))
+	if JSONOrURL == "" {
+		return cfg, nil
+	}
+	data := []byte(JSONOrURL)
+	if !strings.HasPrefix(JSONOrURL, "{") {
+		var err error
+		if data, err = afs.New().DownloadWithURL(ctx, JSONOrURL); err != nil {
+			return nil, errors.Wrapf(err, "failed to download: %v", JSONOrURL)
+		}
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode: %v", key)
+	}
+	if cfg.Access != nil {
+		if err := cfg.Access.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	cfg.Init()
+	return cfg, nil
+}
@@ -23,11 +23,54 @@ const (
 	//StatusPending status pending
 	StatusPending = "pending"
 
+	//StatusPoisoned status for source object moved to dead letter location after repeated failures
+	StatusPoisoned = "poisoned"
+
+	//StatusEmpty status for skipped zero-byte or placeholder object
+	StatusEmpty = "empty"
+
+	//StatusCompletion status for zero-byte object treated as folder completion signal
+	StatusCompletion = "completion"
+
+	//StatusSidecar status for checksum sidecar object that is not mirrored
+	StatusSidecar = "sidecar"
+
+	//StatusDuplicate status for skipped or quarantined duplicate content
+	StatusDuplicate = "duplicate"
+
+	//StatusAnomaly status for rule arrival anomaly
+	StatusAnomaly = "anomaly"
+
+	//StatusPaused status for event deferred while ingestion is paused
+	StatusPaused = "paused"
+
+	//StatusMaintenance status for event deferred while dest maintenance window is open
+	StatusMaintenance = "maintenance"
+
+	//StatusDebounced status for event superseded by a later event of the same source object
+	StatusDebounced = "debounced"
+
 	//StatusUnProcess status for unprocessed file
 	StatusUnProcess = "unprocessed"
 
 	//SourceAttribute dest attribute
 	SourceAttribute = "Source"
+	//TransferIDKey destination object metadata and message attribute with mirror transfer ID
+	TransferIDKey = "smirror-transfer-id"
+	//CorrelationIDKey trigger or source object metadata and message attribute with upstream correlation ID
+	CorrelationIDKey = "correlation-id"
+	//SourceURLKey destination object provenance metadata with source URL
+	SourceURLKey = "smirror-source-url"
+	//SourceGenerationKey destination object provenance metadata with source generation or modification time and size version
+	SourceGenerationKey = "smirror-source-generation"
+	//RuleKey destination object provenance metadata with rule name
+	RuleKey = "smirror-rule"
+	//ConfigVersionKey destination object provenance metadata with rule file content digest
+	ConfigVersionKey = "smirror-config-version"
+	//PipelineVersionKey destination object provenance metadata with pipeline build version
+	PipelineVersionKey = "smirror-pipeline-version"
+	//PipelineVersionEnvKey pipeline build version env key
+	PipelineVersionEnvKey = "PIPELINE_VERSION"
 
 	//UnclassifiedStatus
 	UnclassifiedStatus = "unclassified"
@@ -38,12 +81,17 @@ const (
 	//DestEnvKey destination env key
 	DestEnvKey = "DEST"
 
+	//ChaosEnvKey env key that has to be true for chaos fault injection config to take effect
+	ChaosEnvKey = "CHAOS_MODE"
+
 
 	//LambdaScheme represents lambda schem
 	LambdaScheme = "lambda"
 
 	//YAMLExt yaml extension
 	YAMLExt = ".yaml"
+	//JSONExt json extension
+	JSONExt = ".json"
 
 	//CloudFunctionScheme represents clud function scheme
 	CloudFunctionScheme = "cloudfunction"
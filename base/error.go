@@ -1,6 +1,13 @@
 package base
 
-
+import (
+	"errors"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"google.golang.org/api/googleapi"
+	"regexp"
+	"strconv"
+	"strings"
+)
 
 //SchemaError represent schema validation error
 type SchemaError struct {
@@ -24,4 +31,172 @@ func IsSchemaError(err error) bool {
 	}
 	_, ok := err.(*SchemaError)
 	return ok
-}
\ No newline at end of file
+}
+
+const (
+	//ErrorCodeAuth authentication, authorization or secret error
+	ErrorCodeAuth = "auth"
+	//ErrorCodeNotFound source or destination not found error
+	ErrorCodeNotFound = "notFound"
+	//ErrorCodeSchema data schema validation error
+	ErrorCodeSchema = "schema"
+	//ErrorCodeQuota provider quota or rate limit error
+	ErrorCodeQuota = "quota"
+	//ErrorCodeTransient provider backend or network error
+	ErrorCodeTransient = "transient"
+	//ErrorCodeConfig rule or resource configuration error
+	ErrorCodeConfig = "config"
+	//ErrorCodeGenerationGone trigger event object generation no longer exists
+	ErrorCodeGenerationGone = "generationGone"
+	//ErrorCodeChecksum source content does not match sidecar checksum
+	ErrorCodeChecksum = "checksum"
+	//ErrorCodeImmutable operation would violate destination retention or legal hold
+	ErrorCodeImmutable = "immutable"
+	//ErrorCodePolicy data classification policy violation
+	ErrorCodePolicy = "policy"
+	//ErrorCodePaused ingestion paused without defer settings, the event is redelivered by platform retries
+	ErrorCodePaused = "paused"
+	//ErrorCodeUnreachable dest preflight connect failed, network or firewall (IP allowlist) error, the event is redelivered by platform retries
+	ErrorCodeUnreachable = "unreachable"
+	//ErrorCodeUnknown unclassified error
+	ErrorCodeUnknown = "unknown"
+
+	//ErrorClassRetryable error that may succeed on retry
+	ErrorClassRetryable = "retryable"
+	//ErrorClassTerminal error that would fail again on retry
+	ErrorClassTerminal = "terminal"
+)
+
+var errorCodeFragments = []struct {
+	code      string
+	fragments []string
+}{
+	{ErrorCodeImmutable, []string{"retentionPolicyNotMet", "objectUnderActiveHold"}},
+	{ErrorCodeQuota, []string{"Too Many Requests", "TooManyRequests", "SlowDown", "rateLimitExceeded", "RequestLimitExceeded", "quotaExceeded", "RESOURCE_EXHAUSTED"}},
+	{ErrorCodeAuth, []string{"Unauthorized", "Forbidden", "AccessDenied", "permission denied", "PERMISSION_DENIED", "invalid_grant", "InvalidAccessKeyId", "SignatureDoesNotMatch", "ExpiredToken"}},
+	{ErrorCodeNotFound, []string{"not found", "no such file or directory", "NoSuchKey", "NoSuchBucket"}},
+	{ErrorCodeTransient, []string{"backendError", "connection reset by peer", "Internal Server Error", "Bad Gateway", "Service Unavailable", "Gateway Timeout", "deadline exceeded", "i/o timeout"}},
+}
+
+//statusCodeExpr matches provider status code formats: googleapi "Error 503:", aws "status code: 503" and "StatusCode: 503"
+var statusCodeExpr = regexp.MustCompile(`(?:Error |status code: |StatusCode: )(\d{3})\b`)
+
+//statusErrorCode returns error code for provider HTTP status code
+func statusErrorCode(status int) string {
+	switch status {
+	case 401, 403:
+		return ErrorCodeAuth
+	case 404:
+		return ErrorCodeNotFound
+	case 429:
+		return ErrorCodeQuota
+	case 500, 502, 503, 504:
+		return ErrorCodeTransient
+	}
+	return ""
+}
+
+//errorStatus returns provider HTTP status code of typed googleapi or aws error
+func errorStatus(err error) int {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	var requestErr awserr.RequestFailure
+	if errors.As(err, &requestErr) {
+		return requestErr.StatusCode()
+	}
+	return 0
+}
+
+//CodedError represents an error with taxonomy code
+type CodedError struct {
+	Code string
+	err  error
+}
+
+func (e *CodedError) Error() string {
+	return e.err.Error()
+}
+
+//Unwrap returns underlying error
+func (e *CodedError) Unwrap() error {
+	return e.err
+}
+
+//NewCodedError creates an error with taxonomy code
+func NewCodedError(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CodedError{Code: code, err: err}
+}
+
+//ErrorCode returns error code, coded and schema errors take precedence over error message classification
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	var schemaErr *SchemaError
+	if errors.As(err, &schemaErr) {
+		return ErrorCodeSchema
+	}
+	code := ClassifyError(err.Error())
+	if code == ErrorCodeImmutable || code == ErrorCodeQuota {
+		return code
+	}
+	if statusCode := statusErrorCode(errorStatus(err)); statusCode != "" {
+		return statusCode
+	}
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		if awsCode := ClassifyError(awsErr.Code()); awsCode != ErrorCodeUnknown {
+			return awsCode
+		}
+	}
+	return code
+}
+
+//ClassifyError returns error code for error message, status codes are only matched in provider error format,
+//so digits in object URLs do not affect classification
+func ClassifyError(message string) string {
+	if message == "" {
+		return ""
+	}
+	for _, candidate := range errorCodeFragments[:2] {
+		for _, fragment := range candidate.fragments {
+			if strings.Contains(message, fragment) {
+				return candidate.code
+			}
+		}
+	}
+	for _, match := range statusCodeExpr.FindAllStringSubmatch(message, -1) {
+		status, _ := strconv.Atoi(match[1])
+		if code := statusErrorCode(status); code != "" {
+			return code
+		}
+	}
+	for _, candidate := range errorCodeFragments[2:] {
+		for _, fragment := range candidate.fragments {
+			if strings.Contains(message, fragment) {
+				return candidate.code
+			}
+		}
+	}
+	return ErrorCodeUnknown
+}
+
+//ErrorClass returns error class for error code, unknown errors are retryable
+func ErrorClass(code string) string {
+	switch code {
+	case "":
+		return ""
+	case ErrorCodeAuth, ErrorCodeNotFound, ErrorCodeSchema, ErrorCodeConfig, ErrorCodeGenerationGone, ErrorCodeChecksum, ErrorCodeImmutable, ErrorCodePolicy:
+		return ErrorClassTerminal
+	}
+	return ErrorClassRetryable
+}
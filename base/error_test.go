@@ -0,0 +1,43 @@
+package base
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/googleapi"
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	var useCases = []struct {
+		description string
+		err         error
+		code        string
+		class       string
+	}{
+		{description: "nil error", err: nil, code: "", class: ""},
+		{description: "coded error", err: errors.Wrap(NewCodedError(ErrorCodeAuth, fmt.Errorf("failed to decrypt")), "failed to init"), code: ErrorCodeAuth, class: ErrorClassTerminal},
+		{description: "schema error", err: errors.Wrap(NewSchemaError(fmt.Errorf("invalid field")), "failed to transfer"), code: ErrorCodeSchema, class: ErrorClassTerminal},
+		{description: "not found", err: fmt.Errorf("gs://bucket/file.csv: not found"), code: ErrorCodeNotFound, class: ErrorClassTerminal},
+		{description: "throttled", err: fmt.Errorf("googleapi: Error 429: rateLimitExceeded"), code: ErrorCodeQuota, class: ErrorClassRetryable},
+		{description: "permission", err: fmt.Errorf("googleapi: Error 403: Forbidden"), code: ErrorCodeAuth, class: ErrorClassTerminal},
+		{description: "backend", err: fmt.Errorf("googleapi: Error 503: backendError"), code: ErrorCodeTransient, class: ErrorClassRetryable},
+		{description: "connection", err: fmt.Errorf("read: connection reset by peer"), code: ErrorCodeTransient, class: ErrorClassRetryable},
+		{description: "generation gone", err: NewCodedError(ErrorCodeGenerationGone, fmt.Errorf("generation 1 of gs://bucket/file.csv is no longer available")), code: ErrorCodeGenerationGone, class: ErrorClassTerminal},
+		{description: "checksum", err: NewCodedError(ErrorCodeChecksum, fmt.Errorf("checksum mismatch")), code: ErrorCodeChecksum, class: ErrorClassTerminal},
+		{description: "backend on object name with status digits", err: fmt.Errorf("failed to download gs://bucket/part-00403.csv: googleapi: Error 503: backendError"), code: ErrorCodeTransient, class: ErrorClassRetryable},
+		{description: "connection reset on object name with status digits", err: fmt.Errorf("failed to read s3://bucket/part-00404-401.csv: read: connection reset by peer"), code: ErrorCodeTransient, class: ErrorClassRetryable},
+		{description: "unknown on object name with status digits", err: fmt.Errorf("failed to decode gs://bucket/part-00403.csv: gzip: invalid header"), code: ErrorCodeUnknown, class: ErrorClassRetryable},
+		{description: "aws status code", err: fmt.Errorf("failed to upload s3://bucket/part-00503.csv: Forbidden\n\tstatus code: 403, request id: 1"), code: ErrorCodeAuth, class: ErrorClassTerminal},
+		{description: "typed googleapi error", err: errors.Wrap(&googleapi.Error{Code: 503, Message: "gs://bucket/part-00403.csv"}, "failed to download"), code: ErrorCodeTransient, class: ErrorClassRetryable},
+		{description: "typed aws error", err: errors.Wrap(awserr.NewRequestFailure(awserr.New("InternalError", "s3://bucket/part-00404.csv", nil), 500, "1"), "failed to download"), code: ErrorCodeTransient, class: ErrorClassRetryable},
+		{description: "typed aws not found", err: errors.Wrap(awserr.New("NoSuchKey", "s3://bucket/part-00503.csv", nil), "failed to download"), code: ErrorCodeNotFound, class: ErrorClassTerminal},
+		{description: "unknown", err: fmt.Errorf("gzip: invalid header"), code: ErrorCodeUnknown, class: ErrorClassRetryable},
+	}
+	for _, useCase := range useCases {
+		code := ErrorCode(useCase.err)
+		assert.Equal(t, useCase.code, code, useCase.description)
+		assert.Equal(t, useCase.class, ErrorClass(code), useCase.description)
+	}
+}
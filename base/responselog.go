@@ -0,0 +1,57 @@
+package base
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"strings"
+	"time"
+)
+
+const (
+	//UnmatchedPartition response log partition for responses without matched rule
+	UnmatchedPartition = "unmatched"
+	responseLogDateLayout = "2006-01-02"
+)
+
+//ResponseLog represents response persistence settings
+type ResponseLog struct {
+	//URL base URL, each response is stored as <URL>/<yyyy-MM-dd>/<rule>/<status>/<ID>.json
+	URL string
+}
+
+//ObjectURL returns response object URL
+func (l *ResponseLog) ObjectURL(at time.Time, partition, status, ID string) string {
+	if partition == "" {
+		partition = UnmatchedPartition
+	}
+	if ID == "" {
+		ID = uuid.New().String()
+	}
+	return url.Join(l.PartitionURL(at, partition), status, ID+JSONExt)
+}
+
+//PartitionURL returns response log date and rule partition URL
+func (l *ResponseLog) PartitionURL(at time.Time, partition string) string {
+	partition = strings.Trim(strings.Replace(partition, " ", "_", -1), "/")
+	return url.Join(l.URL, at.UTC().Format(responseLogDateLayout), partition)
+}
+
+//IsLogged returns true if URL is within response log location, to avoid event cycle
+func (l *ResponseLog) IsLogged(URL string) bool {
+	return l.URL != "" && strings.HasPrefix(URL, strings.TrimRight(l.URL, "/")+"/")
+}
+
+//Persist uploads response JSON
+func (l *ResponseLog) Persist(ctx context.Context, fs afs.Service, partition, status, ID string, response interface{}) (string, error) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return "", err
+	}
+	URL := l.ObjectURL(time.Now(), partition, status, ID)
+	return URL, fs.Upload(ctx, URL, file.DefaultFileOsMode, bytes.NewReader(data))
+}
@@ -0,0 +1,160 @@
+package base
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/viant/toolbox"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//sizeExpr size with optional unit, i.e. 500MB, 1.5GiB, 64k
+var sizeExpr = regexp.MustCompile(`(?i)^([0-9]+(?:\.[0-9]+)?)\s*([kmgt]?)(?:i?b)?$`)
+
+var sizeUnits = map[string]float64{
+	"":  1,
+	"k": 1 << 10,
+	"m": 1 << 20,
+	"g": 1 << 30,
+	"t": 1 << 40,
+}
+
+//Milliseconds duration in milliseconds, config value is a number or duration text (i.e. "1.5s", "15m")
+type Milliseconds int
+
+//UnmarshalJSON decodes number or duration text
+func (m *Milliseconds) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalDuration(data, time.Millisecond)
+	*m = Milliseconds(value)
+	return err
+}
+
+//Duration returns time duration
+func (m Milliseconds) Duration() time.Duration {
+	return time.Duration(m) * time.Millisecond
+}
+
+//Seconds duration in seconds, config value is a number or duration text (i.e. "90s", "15m", "1d")
+type Seconds int
+
+//UnmarshalJSON decodes number or duration text
+func (s *Seconds) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalDuration(data, time.Second)
+	*s = Seconds(value)
+	return err
+}
+
+//Duration returns time duration
+func (s Seconds) Duration() time.Duration {
+	return time.Duration(s) * time.Second
+}
+
+//Bytes size in bytes, config value is a number or size text (i.e. "64KB", "500MB", "1GiB"), units are binary multiples
+type Bytes int
+
+//UnmarshalJSON decodes number or size text
+func (b *Bytes) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalSize(data, 1)
+	*b = Bytes(value)
+	return err
+}
+
+//Megabytes size in megabytes, config value is a number or size text (i.e. "16MB", "1GB")
+type Megabytes int
+
+//UnmarshalJSON decodes number or size text
+func (m *Megabytes) UnmarshalJSON(data []byte) error {
+	value, err := unmarshalSize(data, 1<<20)
+	*m = Megabytes(value)
+	return err
+}
+
+//ParseDuration parses duration text, supported units are Go duration units and d (days), number without unit uses supplied unit
+func ParseDuration(text string, unit time.Duration) (time.Duration, error) {
+	text = strings.TrimSpace(text)
+	if number, err := strconv.ParseFloat(text, 64); err == nil {
+		return time.Duration(number * float64(unit)), nil
+	}
+	if strings.HasSuffix(text, "d") {
+		if days, err := strconv.ParseFloat(strings.TrimSuffix(text, "d"), 64); err == nil {
+			return time.Duration(days * float64(24*time.Hour)), nil
+		}
+	}
+	result, err := time.ParseDuration(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration: %q, expected number or duration i.e. 500ms, 15m, 1h30m, 1d", text)
+	}
+	return result, nil
+}
+
+//ParseSize parses size text in bytes, units (K, M, G, T with optional B or iB suffix) are binary multiples, number without unit uses supplied unit
+func ParseSize(text string, unit int64) (int64, error) {
+	text = strings.TrimSpace(text)
+	if number, err := strconv.ParseFloat(text, 64); err == nil {
+		return int64(number * float64(unit)), nil
+	}
+	matched := sizeExpr.FindStringSubmatch(text)
+	if len(matched) == 0 {
+		return 0, fmt.Errorf("invalid size: %q, expected number or size i.e. 64KB, 500MB, 1GiB", text)
+	}
+	number, _ := strconv.ParseFloat(matched[1], 64)
+	return int64(math.Round(number * sizeUnits[strings.ToLower(matched[2])])), nil
+}
+
+//unmarshalDuration decodes JSON number in unit or duration text, it returns value in unit
+func unmarshalDuration(data []byte, unit time.Duration) (int, error) {
+	text, err := unmarshalText(data)
+	if err != nil || text == "" {
+		return 0, err
+	}
+	duration, err := ParseDuration(text, unit)
+	if err != nil {
+		return 0, err
+	}
+	return int(duration / unit), nil
+}
+
+//unmarshalSize decodes JSON number in unit or size text, it returns value in unit
+func unmarshalSize(data []byte, unit int64) (int, error) {
+	text, err := unmarshalText(data)
+	if err != nil || text == "" {
+		return 0, err
+	}
+	size, err := ParseSize(text, unit)
+	if err != nil {
+		return 0, err
+	}
+	return int(size / unit), nil
+}
+
+func unmarshalText(data []byte) (string, error) {
+	if string(data) == "null" {
+		return "", nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var text string
+		err := json.Unmarshal(data, &text)
+		return text, err
+	}
+	return string(data), nil
+}
+
+//unmarshalConverter converts text (i.e. YAML rule value) with target JSON unmarshaler
+func unmarshalConverter(target, source interface{}) error {
+	data, err := json.Marshal(source)
+	if err != nil {
+		return err
+	}
+	return target.(json.Unmarshaler).UnmarshalJSON(data)
+}
+
+func init() {
+	textType := reflect.TypeOf("")
+	for _, target := range []interface{}{(*Milliseconds)(nil), (*Seconds)(nil), (*Bytes)(nil), (*Megabytes)(nil)} {
+		toolbox.RegisterConverter(reflect.TypeOf(target), textType, unmarshalConverter)
+	}
+}
@@ -0,0 +1,52 @@
+package base
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/toolbox"
+	"testing"
+)
+
+func TestUnits_UnmarshalJSON(t *testing.T) {
+	type units struct {
+		CheckInMs   Milliseconds
+		IntervalSec Seconds
+		MaxSize     Bytes
+		PartSizeMb  Megabytes
+	}
+	var useCases = []struct {
+		description string
+		JSON        string
+		expect      units
+		expectError bool
+	}{
+		{description: "numeric", JSON: `{"CheckInMs":60000,"IntervalSec":30,"MaxSize":1048576,"PartSizeMb":64}`, expect: units{CheckInMs: 60000, IntervalSec: 30, MaxSize: 1048576, PartSizeMb: 64}},
+		{description: "numeric text", JSON: `{"CheckInMs":"1500","IntervalSec":"30"}`, expect: units{CheckInMs: 1500, IntervalSec: 30}},
+		{description: "duration and size", JSON: `{"CheckInMs":"15m","IntervalSec":"1h30m","MaxSize":"500MB","PartSizeMb":"1GiB"}`, expect: units{CheckInMs: 900000, IntervalSec: 5400, MaxSize: 524288000, PartSizeMb: 1024}},
+		{description: "fractional", JSON: `{"CheckInMs":"1.5s","MaxSize":"1.5k"}`, expect: units{CheckInMs: 1500, MaxSize: 1536}},
+		{description: "days", JSON: `{"IntervalSec":"1d"}`, expect: units{IntervalSec: 86400}},
+		{description: "invalid duration", JSON: `{"IntervalSec":"soon"}`, expectError: true},
+		{description: "invalid size", JSON: `{"MaxSize":"5 parsecs"}`, expectError: true},
+	}
+	for _, useCase := range useCases {
+		actual := units{}
+		err := json.Unmarshal([]byte(useCase.JSON), &actual)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+}
+
+func TestUnits_Converter(t *testing.T) {
+	actual := &struct {
+		MaxSize     Bytes
+		IntervalSec Seconds
+	}{}
+	err := toolbox.DefaultConverter.AssignConverted(&actual, map[string]interface{}{"MaxSize": "64KB", "IntervalSec": "2m"})
+	assert.Nil(t, err)
+	assert.EqualValues(t, 65536, actual.MaxSize)
+	assert.EqualValues(t, 120, actual.IntervalSec)
+}
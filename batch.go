@@ -0,0 +1,118 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/shared"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	//defaultBatchParallelism default max concurrently mirrored batch objects
+	defaultBatchParallelism = 8
+	//maxBatchParallelism max concurrently mirrored batch objects
+	maxBatchParallelism = 64
+	//maxBatchURLs max number of batch source objects
+	maxBatchURLs = 1000
+)
+
+//StorageMirrorBatch cloud function entry point, mirrors source objects supplied with JSON batch request concurrently
+//and returns combined response
+func StorageMirrorBatch(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, auth.EndpointBatch) {
+		return
+	}
+	if !validRequest(w, r, pathBatch) {
+		return
+	}
+	err := submitBatch(w, r)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func submitBatch(writer http.ResponseWriter, httpRequest *http.Request) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	defer func() {
+		_ = httpRequest.Body.Close()
+	}()
+	request := &contract.BatchRequest{}
+	if err = json.NewDecoder(httpRequest.Body).Decode(&request); err != nil {
+		return errors.Wrapf(err, "failed to decode %T", request)
+	}
+	ctx := context.Background()
+	service, err := NewFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		return err
+	}
+	response := service.MirrorBatch(ctx, request)
+	for _, mirrored := range response.Responses {
+		if !mirrored.IsLogSuppressed() {
+			shared.LogLn(mirrored)
+		}
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(writer).Encode(response)
+}
+
+//MirrorBatch mirrors source objects concurrently
+func (s *service) MirrorBatch(ctx context.Context, request *contract.BatchRequest) *contract.BatchResponse {
+	return mirrorBatch(ctx, s.Mirror, request)
+}
+
+//mirrorBatch mirrors batch source objects with mirror function, at most request parallelism objects at a time
+func mirrorBatch(ctx context.Context, mirror func(ctx context.Context, request *contract.Request) *contract.Response, request *contract.BatchRequest) *contract.BatchResponse {
+	response := contract.NewBatchResponse()
+	startTime := time.Now()
+	if len(request.URLs) == 0 {
+		response.Status = base.StatusError
+		response.Error = "batch URLs were empty"
+		return response
+	}
+	if len(request.URLs) > maxBatchURLs {
+		response.Status = base.StatusError
+		response.Error = fmt.Sprintf("batch exceeded %v URLs: %v", maxBatchURLs, len(request.URLs))
+		return response
+	}
+	parallelism := request.Parallelism
+	if parallelism <= 0 {
+		parallelism = defaultBatchParallelism
+	}
+	if parallelism > maxBatchParallelism {
+		parallelism = maxBatchParallelism
+	}
+	response.Responses = make([]*contract.Response, len(request.URLs))
+	waitGroup := &sync.WaitGroup{}
+	limiter := make(chan bool, parallelism)
+	for i := range request.URLs {
+		waitGroup.Add(1)
+		limiter <- true
+		go func(i int) {
+			defer func() {
+				<-limiter
+				waitGroup.Done()
+			}()
+			mirrorRequest := contract.NewRequest(request.URLs[i])
+			mirrorRequest.CorrelationID = request.CorrelationID
+			mirrorRequest.SkipActions = request.SkipActions
+			response.Responses[i] = mirror(ctx, mirrorRequest)
+		}(i)
+	}
+	waitGroup.Wait()
+	response.TimeTakenMs = int(time.Since(startTime) / time.Millisecond)
+	response.Init()
+	return response
+}
@@ -0,0 +1,156 @@
+package smirror
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMirrorBatch(t *testing.T) {
+	var useCases = []struct {
+		description     string
+		request         *contract.BatchRequest
+		expectStatus    string
+		expectSucceeded int
+		expectFailed    int
+		maxRunning      int32
+	}{
+		{description: "all succeeded", request: &contract.BatchRequest{URLs: []string{"mem://localhost/batch/f1.csv", "mem://localhost/batch/f2.csv", "mem://localhost/batch/f3.csv"}, Parallelism: 2}, expectStatus: base.StatusOK, expectSucceeded: 3, maxRunning: 2},
+		{description: "partially failed", request: &contract.BatchRequest{URLs: []string{"mem://localhost/batch/f1.csv", "mem://localhost/batch/error.csv"}}, expectStatus: base.StatusError, expectSucceeded: 1, expectFailed: 1, maxRunning: defaultBatchParallelism},
+		{description: "empty", request: &contract.BatchRequest{}, expectStatus: base.StatusError},
+	}
+	for _, useCase := range useCases {
+		var running, maxRunning int32
+		mirror := func(ctx context.Context, request *contract.Request) *contract.Response {
+			current := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				observed := atomic.LoadInt32(&maxRunning)
+				if current <= observed || atomic.CompareAndSwapInt32(&maxRunning, observed, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			response := contract.NewResponse(request.URL)
+			if strings.Contains(request.URL, "error") {
+				response.Status = base.StatusError
+				response.Error = "test error"
+			}
+			return response
+		}
+		response := mirrorBatch(context.Background(), mirror, useCase.request)
+		assert.Equal(t, useCase.expectStatus, response.Status, useCase.description)
+		assert.Equal(t, useCase.expectSucceeded, response.Succeeded, useCase.description)
+		assert.Equal(t, useCase.expectFailed, response.Failed, useCase.description)
+		assert.True(t, maxRunning <= useCase.maxRunning, useCase.description)
+		for i, URL := range useCase.request.URLs {
+			assert.Equal(t, URL, response.Responses[i].TriggeredBy, useCase.description)
+		}
+	}
+}
+
+func TestService_MirrorBatch(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/batchrace")
+	var URLs []string
+	for i := 0; i < 8; i++ {
+		URL := fmt.Sprintf("mem://localhost/batchrace/incoming/partner%v/data/%v.csv", i%2, i)
+		if !assert.Nil(t, fs.Upload(ctx, URL, 0644, strings.NewReader("1,2,3"))) {
+			return
+		}
+		URLs = append(URLs, URL)
+	}
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Filter: `^/batchrace/incoming/(?P<partner>[^/]+)/`}, Glob: "batchrace/incoming/**/*.csv"},
+				Dest:   &config.Resource{URL: "mem://localhost/batchrace/dest/$partner"},
+				Labels: map[string]string{"partner": "$partner"},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	//run with -race, batch mirrors objects concurrently with shared rules
+	response := service.MirrorBatch(ctx, &contract.BatchRequest{URLs: URLs, Parallelism: 4})
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+	assert.Equal(t, len(URLs), response.Succeeded)
+	for i, URL := range URLs {
+		if !assert.Equal(t, base.StatusOK, response.Responses[i].Status, response.Responses[i].Error) {
+			continue
+		}
+		destURL := fmt.Sprintf("mem://localhost/batchrace/dest/partner%v/batchrace/incoming/partner%v/data/%v.csv", i%2, i%2, i)
+		exists, _ := fs.Exists(ctx, destURL)
+		assert.True(t, exists, URL)
+	}
+}
+
+func TestService_MirrorReload(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/reloadrace/rules"
+	_ = fs.Delete(ctx, "mem://localhost/reloadrace")
+	upload := func(version int) {
+		rule := fmt.Sprintf(`{"Source":{"Prefix":"/reloadrace/incoming"},"Dest":{"URL":"mem://localhost/reloadrace/dest"},"Labels":{"version":"%v"}}`, version)
+		assert.Nil(t, fs.Upload(ctx, baseURL+"/rule.json", 0644, strings.NewReader(rule)))
+	}
+	upload(0)
+	var URLs []string
+	for i := 0; i < 16; i++ {
+		URL := fmt.Sprintf("mem://localhost/reloadrace/incoming/%v.csv", i)
+		if !assert.Nil(t, fs.Upload(ctx, URL, 0644, strings.NewReader("1,2,3"))) {
+			return
+		}
+		URLs = append(URLs, URL)
+	}
+	cfg := &Config{
+		Mirrors: config.Ruleset{BaseURL: baseURL, CheckInMs: 1, Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/reloadrace/other"}},
+				Dest:   &config.Resource{URL: "mem://localhost/reloadrace/other"},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	//run with -race, rule file changes are reloaded while events are matched against published rules
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		for i := 1; i < 10; i++ {
+			time.Sleep(2 * time.Millisecond)
+			upload(i)
+		}
+	}()
+	responses := make([]*contract.Response, len(URLs))
+	var waitGroup sync.WaitGroup
+	for i := range URLs {
+		waitGroup.Add(1)
+		go func(i int) {
+			defer waitGroup.Done()
+			responses[i] = service.Mirror(ctx, contract.NewRequest(URLs[i]))
+		}(i)
+	}
+	waitGroup.Wait()
+	<-done
+	for i, response := range responses {
+		assert.Equal(t, base.StatusOK, response.Status, URLs[i]+" "+response.Error)
+		assert.Equal(t, 2, response.TotalRules, URLs[i])
+	}
+	assert.Equal(t, 1, len(cfg.Mirrors.Rules), "initial rules are not modified by reload")
+}
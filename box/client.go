@@ -0,0 +1,136 @@
+package box
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxRetries = 3
+
+var (
+	apiURL    = "https://api.box.com/2.0"
+	uploadURL = "https://upload.box.com/api/2.0"
+	tokenURL  = "https://api.box.com/oauth2/token"
+)
+
+//Error represents Box API error
+type Error struct {
+	StatusCode  int             `json:"status"`
+	Code        string          `json:"code"`
+	Message     string          `json:"message"`
+	ContextInfo json.RawMessage `json:"context_info"`
+}
+
+//Error returns error message
+func (e *Error) Error() string {
+	return fmt.Sprintf("box API error: %v %v %v", e.StatusCode, e.Code, e.Message)
+}
+
+//conflictID returns ID of conflicting item
+func (e *Error) conflictID() string {
+	info := struct {
+		Conflicts json.RawMessage `json:"conflicts"`
+	}{}
+	if json.Unmarshal(e.ContextInfo, &info) != nil {
+		return ""
+	}
+	var conflicts []*item
+	if json.Unmarshal(info.Conflicts, &conflicts) != nil {
+		conflict := &item{}
+		if json.Unmarshal(info.Conflicts, conflict) != nil {
+			return ""
+		}
+		conflicts = []*item{conflict}
+	}
+	if len(conflicts) == 0 {
+		return ""
+	}
+	return conflicts[0].ID
+}
+
+//IsNotFound returns true if error is Box not found error
+func IsNotFound(err error) bool {
+	apiError, ok := errors.Cause(err).(*Error)
+	return ok && apiError.StatusCode == http.StatusNotFound
+}
+
+type client struct {
+	credentials *Credentials
+	http        *http.Client
+}
+
+//call sends JSON request and decodes JSON response
+func (c *client) call(ctx context.Context, method, URL string, arg, result interface{}) error {
+	var body []byte
+	if arg != nil {
+		var err error
+		if body, err = json.Marshal(arg); err != nil {
+			return err
+		}
+	}
+	response, err := c.do(ctx, method, URL, map[string]string{"Content-Type": "application/json"}, body)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+//do sends request, rate limited requests are retried after server advised delay
+func (c *client) do(ctx context.Context, method, URL string, headers map[string]string, body []byte) (*http.Response, error) {
+	token, err := c.credentials.TokenSource().Token()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get box access token")
+	}
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		request, err := http.NewRequestWithContext(ctx, method, URL, reader)
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Authorization", "Bearer "+token.AccessToken)
+		for k, v := range headers {
+			request.Header.Set(k, v)
+		}
+		response, err := c.http.Do(request)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to call %v %v", method, URL)
+		}
+		if response.StatusCode/100 == 2 && response.StatusCode != http.StatusAccepted {
+			return response, nil
+		}
+		data, _ := ioutil.ReadAll(response.Body)
+		_ = response.Body.Close()
+		retry := response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusAccepted || response.StatusCode == http.StatusServiceUnavailable
+		if retry && attempt < maxRetries {
+			delay, _ := strconv.Atoi(response.Header.Get("Retry-After"))
+			select {
+			case <-time.After(time.Duration(delay+1) * time.Second):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		apiError := &Error{}
+		if json.Unmarshal(data, apiError) != nil || apiError.Code == "" {
+			apiError.Message = strings.TrimSpace(string(data))
+		}
+		apiError.StatusCode = response.StatusCode
+		return nil, apiError
+	}
+}
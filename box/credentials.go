@@ -0,0 +1,122 @@
+package box
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/shared"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"net/url"
+	"sync"
+)
+
+//tokenSources OAuth token sources keyed by client and grant, shared by managers
+var tokenSources = sync.Map{}
+
+//Persister stores rotated credentials secret JSON
+type Persister func(ctx context.Context, secret []byte) error
+
+//Credentials represents decrypted Box app credentials, either OAuth refresh token or client credentials grant subject
+type Credentials struct {
+	ClientID     string
+	ClientSecret string
+	//RefreshToken OAuth refresh token, Box rotates refresh token on use, rotated token is stored with Persist
+	RefreshToken string `json:",omitempty"`
+	//SubjectType client credentials grant subject: enterprise or user
+	SubjectType string `json:",omitempty"`
+	//SubjectID enterprise or user ID
+	SubjectID string `json:",omitempty"`
+	//Persist stores credentials with rotated refresh token, without it rotated token is only kept in memory
+	Persist Persister `json:"-"`
+}
+
+//rotatingTokenSource persists credentials once refresh token is rotated, persisting is retried with subsequent token calls till it succeeds
+type rotatingTokenSource struct {
+	source      oauth2.TokenSource
+	credentials Credentials
+	persisted   string
+	mux         sync.Mutex
+}
+
+//Token returns token, rotated refresh token is persisted
+func (s *rotatingTokenSource) Token() (*oauth2.Token, error) {
+	token, err := s.source.Token()
+	if err != nil {
+		return nil, err
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if token.RefreshToken == "" || token.RefreshToken == s.persisted {
+		return token, nil
+	}
+	credentials := s.credentials
+	credentials.RefreshToken = token.RefreshToken
+	secret, err := json.Marshal(&credentials)
+	if err != nil {
+		return nil, err
+	}
+	shared.RegisterSecret([]byte(token.RefreshToken))
+	if err = s.credentials.Persist(context.Background(), secret); err != nil {
+		shared.LogF("failed to persist rotated box refresh token: %v\n", err)
+		return token, nil
+	}
+	s.persisted = token.RefreshToken
+	return token, nil
+}
+
+//TokenSource returns cached token source
+func (c *Credentials) TokenSource() oauth2.TokenSource {
+	key := c.ClientID + "/" + c.RefreshToken + "/" + c.SubjectType + "/" + c.SubjectID
+	if source, ok := tokenSources.Load(key); ok {
+		return source.(oauth2.TokenSource)
+	}
+	var source oauth2.TokenSource
+	if c.RefreshToken != "" {
+		config := &oauth2.Config{
+			ClientID:     c.ClientID,
+			ClientSecret: c.ClientSecret,
+			Endpoint:     oauth2.Endpoint{TokenURL: tokenURL, AuthStyle: oauth2.AuthStyleInParams},
+		}
+		source = config.TokenSource(context.Background(), &oauth2.Token{RefreshToken: c.RefreshToken})
+		if c.Persist != nil {
+			source = &rotatingTokenSource{source: source, credentials: *c, persisted: c.RefreshToken}
+		}
+	} else {
+		config := &clientcredentials.Config{
+			ClientID:       c.ClientID,
+			ClientSecret:   c.ClientSecret,
+			TokenURL:       tokenURL,
+			AuthStyle:      oauth2.AuthStyleInParams,
+			EndpointParams: url.Values{"box_subject_type": {c.SubjectType}, "box_subject_id": {c.SubjectID}},
+		}
+		source = config.TokenSource(context.Background())
+	}
+	actual, _ := tokenSources.LoadOrStore(key, source)
+	return actual.(oauth2.TokenSource)
+}
+
+//NewCredentials creates credentials from decrypted secret JSON
+func NewCredentials(secret []byte) (*Credentials, error) {
+	secret = bytes.TrimSpace(secret)
+	if len(secret) == 0 {
+		return nil, errors.New("box credentials were empty")
+	}
+	credentials := &Credentials{}
+	if err := json.Unmarshal(secret, credentials); err != nil {
+		return nil, errors.Wrap(err, "failed to decode box credentials")
+	}
+	if credentials.ClientID == "" || credentials.ClientSecret == "" {
+		return nil, errors.New("box credentials require ClientID and ClientSecret")
+	}
+	if credentials.RefreshToken == "" {
+		if credentials.SubjectType != "enterprise" && credentials.SubjectType != "user" {
+			return nil, errors.New("box credentials require RefreshToken or SubjectType (enterprise or user) with SubjectID")
+		}
+		if credentials.SubjectID == "" {
+			return nil, errors.New("box credentials SubjectID was empty")
+		}
+	}
+	return credentials, nil
+}
@@ -0,0 +1,68 @@
+package box
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCredentials_TokenSource(t *testing.T) {
+	var rotations int
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_ = request.ParseForm()
+		rotations++
+		writer.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(writer).Encode(map[string]interface{}{
+			"access_token":  fmt.Sprintf("access%v", rotations),
+			"token_type":    "bearer",
+			"expires_in":    1,
+			"refresh_token": fmt.Sprintf("%v-rotated", request.Form.Get("refresh_token")),
+		})
+	}))
+	defer server.Close()
+	tokenURL = server.URL + "/oauth2/token"
+
+	var useCases = []struct {
+		description  string
+		refreshToken string
+		persistErr   error
+		expect       []string
+	}{
+		{description: "rotated token persisted", refreshToken: "r1", expect: []string{"r1-rotated", "r1-rotated-rotated"}},
+		{description: "persist failure is retried", refreshToken: "r2", persistErr: errors.New("denied")},
+	}
+	for _, useCase := range useCases {
+		credentials, err := NewCredentials([]byte(fmt.Sprintf(`{"ClientID":"app","ClientSecret":"secret","RefreshToken":%q}`, useCase.refreshToken)))
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		var persisted []string
+		var attempts int
+		credentials.Persist = func(ctx context.Context, secret []byte) error {
+			attempts++
+			if useCase.persistErr != nil {
+				return useCase.persistErr
+			}
+			stored, err := NewCredentials(secret)
+			if !assert.Nil(t, err, useCase.description) {
+				return err
+			}
+			assert.Equal(t, "secret", stored.ClientSecret, useCase.description)
+			persisted = append(persisted, stored.RefreshToken)
+			return nil
+		}
+		source := credentials.TokenSource()
+		for i := 0; i < 2; i++ {
+			//token expires within expiry delta, so each call refreshes it
+			_, err := source.Token()
+			assert.Nil(t, err, useCase.description)
+		}
+		assert.EqualValues(t, useCase.expect, persisted, useCase.description)
+		assert.Equal(t, 2, attempts, useCase.description)
+	}
+}
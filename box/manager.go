@@ -0,0 +1,154 @@
+package box
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/afs/base"
+	"github.com/viant/afs/object"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"net/http"
+	"path"
+	"strings"
+)
+
+//Scheme box URL scheme, URL host is an account alias, i.e. box://partners/inbound/file.csv
+const Scheme = "box"
+
+//changeEvents event types reporting new file content
+var changeEvents = map[string]bool{"ITEM_CREATE": true, "ITEM_UPLOAD": true, "ITEM_MOVE": true, "ITEM_COPY": true, "ITEM_UNDELETE_VIA_TRASH": true}
+
+type manager struct {
+	*base.Manager
+}
+
+func (m *manager) provider(ctx context.Context, baseURL string, options ...storage.Option) (storage.Storager, error) {
+	credentials := &Credentials{}
+	if _, ok := option.Assign(options, &credentials); !ok {
+		return nil, errors.Errorf("box credentials were empty: %v", baseURL)
+	}
+	return newStorager(credentials, options...), nil
+}
+
+//Changes returns files changed since cursor (event stream position) with next cursor, empty cursor lists all files under URL recursively
+func Changes(ctx context.Context, URL, cursor string, options ...storage.Option) ([]storage.Object, string, error) {
+	credentials := &Credentials{}
+	if _, ok := option.Assign(options, &credentials); !ok {
+		return nil, "", errors.Errorf("box credentials were empty: %v", URL)
+	}
+	client := newStorager(credentials, options...)
+	baseURL, location := url.Base(URL, Scheme)
+	location = boxPath(location)
+	if cursor == "" {
+		//stream position is taken before listing, so changes made while listing are not lost
+		position, _, _, err := client.events(ctx, "now")
+		if err != nil {
+			return nil, "", err
+		}
+		var result = make([]storage.Object, 0)
+		err = client.walk(ctx, location, func(filePath string, entry *item) {
+			result = append(result, object.New(url.Join(baseURL, filePath), entry.info(), nil))
+		})
+		return result, position, err
+	}
+	var changed = make(map[string]*item)
+	var order []string
+	for {
+		position, entries, more, err := client.events(ctx, cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, entry := range entries {
+			filePath := entry.path()
+			if location != "/" && !strings.HasPrefix(filePath, location+"/") {
+				continue
+			}
+			if _, ok := changed[filePath]; !ok {
+				order = append(order, filePath)
+			}
+			changed[filePath] = entry
+		}
+		cursor = position
+		if !more {
+			break
+		}
+	}
+	var result = make([]storage.Object, 0, len(order))
+	for _, filePath := range order {
+		result = append(result, object.New(url.Join(baseURL, filePath), changed[filePath].info(), nil))
+	}
+	return result, cursor, nil
+}
+
+//events returns next stream position, changed files since stream position and true if stream had any events
+func (s *storager) events(ctx context.Context, position string) (string, []*item, bool, error) {
+	page := &struct {
+		NextStreamPosition json.Number `json:"next_stream_position"`
+		Entries            []*struct {
+			EventType string `json:"event_type"`
+			Source    *item  `json:"source"`
+		} `json:"entries"`
+	}{}
+	URL := fmt.Sprintf("%v/events?stream_type=changes&limit=500&stream_position=%v", apiURL, position)
+	if err := s.call(ctx, http.MethodGet, URL, nil, page); err != nil {
+		return "", nil, false, errors.Wrap(err, "failed to read box events")
+	}
+	var result []*item
+	for _, entry := range page.Entries {
+		if entry.Source != nil && entry.Source.Type == "file" && changeEvents[entry.EventType] {
+			result = append(result, entry.Source)
+		}
+	}
+	return page.NextStreamPosition.String(), result, len(page.Entries) > 0, nil
+}
+
+//walk visits files under location recursively
+func (s *storager) walk(ctx context.Context, location string, handler func(filePath string, entry *item)) error {
+	folder, err := s.resolve(ctx, location)
+	if err != nil {
+		return err
+	}
+	if !folder.isFolder() {
+		handler(location, folder)
+		return nil
+	}
+	var folders []string
+	err = s.listFolder(ctx, folder.ID, func(child *item) bool {
+		childPath := path.Join(location, child.Name)
+		s.items.Store(childPath, child)
+		if child.isFolder() {
+			folders = append(folders, childPath)
+		} else {
+			handler(childPath, child)
+		}
+		return true
+	})
+	for i := 0; i < len(folders) && err == nil; i++ {
+		err = s.walk(ctx, folders[i], handler)
+	}
+	return err
+}
+
+func newManager(options ...storage.Option) *manager {
+	result := &manager{}
+	result.Manager = base.New(result, Scheme, result.provider, options)
+	return result
+}
+
+//New creates Box manager
+func New(options ...storage.Option) storage.Manager {
+	return newManager(options...)
+}
+
+//Provider returns Box manager
+func Provider(options ...storage.Option) (storage.Manager, error) {
+	return New(options...), nil
+}
+
+func init() {
+	afs.GetRegistry().Register(Scheme, Provider)
+}
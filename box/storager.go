@@ -0,0 +1,433 @@
+package box
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	ahttp "github.com/viant/afs/http"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	rootFolderID = "0"
+	itemFields   = "type,id,name,size,modified_at,path_collection"
+	pageLimit    = 1000
+)
+
+//ChunkedUploadThreshold content size from which chunked upload session is used, Box requires at least 20MB
+var ChunkedUploadThreshold = 20 * 1024 * 1024
+
+type item struct {
+	Type           string    `json:"type"`
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Size           int64     `json:"size"`
+	ModifiedAt     time.Time `json:"modified_at"`
+	PathCollection *struct {
+		Entries []*item `json:"entries"`
+	} `json:"path_collection,omitempty"`
+}
+
+func (i *item) isFolder() bool {
+	return i.Type == "folder"
+}
+
+func (i *item) info() os.FileInfo {
+	if i.isFolder() {
+		return file.NewInfo(i.Name, 0, file.DefaultDirOsMode, i.ModifiedAt, true)
+	}
+	return file.NewInfo(i.Name, i.Size, file.DefaultFileOsMode, i.ModifiedAt, false)
+}
+
+//path returns item path from path collection
+func (i *item) path() string {
+	var elements = []string{"/"}
+	if i.PathCollection != nil {
+		for _, parent := range i.PathCollection.Entries {
+			if parent.ID != rootFolderID {
+				elements = append(elements, parent.Name)
+			}
+		}
+	}
+	return path.Join(append(elements, i.Name)...)
+}
+
+type session struct {
+	ID               string `json:"id"`
+	PartSize         int64  `json:"part_size"`
+	SessionEndpoints struct {
+		UploadPart string `json:"upload_part"`
+		Commit     string `json:"commit"`
+	} `json:"session_endpoints"`
+}
+
+type part struct {
+	PartID string `json:"part_id"`
+	Offset int64  `json:"offset"`
+	Size   int64  `json:"size"`
+	SHA1   string `json:"sha1"`
+}
+
+type storager struct {
+	client
+	//items resolved items keyed by path
+	items sync.Map
+}
+
+func boxPath(location string) string {
+	return path.Clean("/" + location)
+}
+
+//resolve returns item for location, Box API addresses items by ID
+func (s *storager) resolve(ctx context.Context, location string) (*item, error) {
+	location = boxPath(location)
+	if location == "/" {
+		return &item{Type: "folder", ID: rootFolderID}, nil
+	}
+	if cached, ok := s.items.Load(location); ok {
+		return cached.(*item), nil
+	}
+	parentPath, name := path.Split(location)
+	parent, err := s.resolve(ctx, parentPath)
+	if err != nil {
+		return nil, err
+	}
+	if !parent.isFolder() {
+		return nil, &Error{StatusCode: http.StatusNotFound, Code: "not_found", Message: location}
+	}
+	var result *item
+	err = s.listFolder(ctx, parent.ID, func(child *item) bool {
+		if strings.EqualFold(child.Name, name) {
+			result = child
+		}
+		return result == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, &Error{StatusCode: http.StatusNotFound, Code: "not_found", Message: location}
+	}
+	s.items.Store(location, result)
+	return result, nil
+}
+
+//listFolder iterates folder items until handler returns false
+func (s *storager) listFolder(ctx context.Context, folderID string, handler func(child *item) bool) error {
+	marker := ""
+	for {
+		URL := fmt.Sprintf("%v/folders/%v/items?fields=%v&limit=%v&usemarker=true", apiURL, folderID, itemFields, pageLimit)
+		if marker != "" {
+			URL += "&marker=" + marker
+		}
+		page := &struct {
+			Entries    []*item `json:"entries"`
+			NextMarker string  `json:"next_marker"`
+		}{}
+		if err := s.call(ctx, http.MethodGet, URL, nil, page); err != nil {
+			if IsNotFound(err) {
+				s.forget("")
+			}
+			return err
+		}
+		for _, entry := range page.Entries {
+			if !handler(entry) {
+				return nil
+			}
+		}
+		if marker = page.NextMarker; marker == "" {
+			return nil
+		}
+	}
+}
+
+//forget removes cached items under location
+func (s *storager) forget(location string) {
+	s.items.Range(func(key, value interface{}) bool {
+		if candidate := key.(string); location == "" || candidate == location || strings.HasPrefix(candidate, location+"/") {
+			s.items.Delete(key)
+		}
+		return true
+	})
+}
+
+//Exists returns true if location exists
+func (s *storager) Exists(ctx context.Context, location string, options ...storage.Option) (bool, error) {
+	_, err := s.resolve(ctx, location)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+//Get returns file info for supplied location
+func (s *storager) Get(ctx context.Context, location string, options ...storage.Option) (os.FileInfo, error) {
+	entry, err := s.resolve(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	return entry.info(), nil
+}
+
+//List lists location, folder itself is returned as the first element
+func (s *storager) List(ctx context.Context, location string, options ...storage.Option) ([]os.FileInfo, error) {
+	entry, err := s.resolve(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	var result = []os.FileInfo{entry.info()}
+	if !entry.isFolder() {
+		return result, nil
+	}
+	err = s.listFolder(ctx, entry.ID, func(child *item) bool {
+		s.items.Store(path.Join(boxPath(location), child.Name), child)
+		result = append(result, child.info())
+		return true
+	})
+	return result, err
+}
+
+//Open returns file content reader
+func (s *storager) Open(ctx context.Context, location string, options ...storage.Option) (io.ReadCloser, error) {
+	entry, err := s.resolve(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	response, err := s.do(ctx, http.MethodGet, fmt.Sprintf("%v/files/%v/content", apiURL, entry.ID), nil, nil)
+	if err != nil {
+		if IsNotFound(err) {
+			s.forget(boxPath(location))
+		}
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+//Upload uploads content as new file or new file version, content from chunked upload threshold is uploaded with upload session
+func (s *storager) Upload(ctx context.Context, destination string, mode os.FileMode, reader io.Reader, options ...storage.Option) error {
+	destination = boxPath(destination)
+	parentPath, name := path.Split(destination)
+	parent, err := s.resolve(ctx, parentPath)
+	if err != nil {
+		return err
+	}
+	existing, err := s.resolve(ctx, destination)
+	if err != nil && !IsNotFound(err) {
+		return err
+	}
+	buffer := make([]byte, ChunkedUploadThreshold)
+	read, err := io.ReadFull(reader, buffer)
+	var uploaded *item
+	switch err {
+	case io.EOF, io.ErrUnexpectedEOF:
+		uploaded, err = s.uploadFile(ctx, parent.ID, name, existing, buffer[:read])
+	case nil:
+		uploaded, err = s.uploadSession(ctx, parent.ID, name, existing, io.MultiReader(bytes.NewReader(buffer), reader))
+	}
+	if err != nil {
+		//cached parent or file may have been removed outside
+		s.forget(parentPath)
+		return errors.Wrapf(err, "failed to upload %v", destination)
+	}
+	s.items.Store(destination, uploaded)
+	return nil
+}
+
+//uploadFile uploads content with single multipart request
+func (s *storager) uploadFile(ctx context.Context, folderID, name string, existing *item, data []byte) (*item, error) {
+	URL := uploadURL + "/files/content"
+	attributes := map[string]interface{}{"name": name, "parent": map[string]string{"id": folderID}}
+	if existing != nil {
+		URL = fmt.Sprintf("%v/files/%v/content", uploadURL, existing.ID)
+		attributes = map[string]interface{}{"name": name}
+	}
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	encoded, _ := json.Marshal(attributes)
+	_ = writer.WriteField("attributes", string(encoded))
+	fileWriter, err := writer.CreateFormFile("file", name)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = fileWriter.Write(data)
+	if err = writer.Close(); err != nil {
+		return nil, err
+	}
+	response, err := s.do(ctx, http.MethodPost, URL, map[string]string{"Content-Type": writer.FormDataContentType()}, body.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return decodeUploaded(response)
+}
+
+//uploadSession spools content to temp file, since upload session requires file size upfront, then uploads it in parts
+func (s *storager) uploadSession(ctx context.Context, folderID, name string, existing *item, reader io.Reader) (*item, error) {
+	spool, err := ioutil.TempFile("", "smirror-box-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+	digest := sha1.New()
+	size, err := io.Copy(io.MultiWriter(spool, digest), reader)
+	if err != nil {
+		return nil, err
+	}
+	URL := uploadURL + "/files/upload_sessions"
+	arg := map[string]interface{}{"folder_id": folderID, "file_size": size, "file_name": name}
+	if existing != nil {
+		URL = fmt.Sprintf("%v/files/%v/upload_sessions", uploadURL, existing.ID)
+		arg = map[string]interface{}{"file_size": size, "file_name": name}
+	}
+	uploadSession := &session{}
+	if err = s.call(ctx, http.MethodPost, URL, arg, uploadSession); err != nil {
+		return nil, errors.Wrap(err, "failed to create upload session")
+	}
+	var parts []*part
+	for offset := int64(0); offset < size; offset += uploadSession.PartSize {
+		partSize := uploadSession.PartSize
+		if offset+partSize > size {
+			partSize = size - offset
+		}
+		data := make([]byte, partSize)
+		if _, err = spool.ReadAt(data, offset); err != nil {
+			return nil, err
+		}
+		partDigest := sha1.Sum(data)
+		headers := map[string]string{
+			"Content-Type":  "application/octet-stream",
+			"Content-Range": fmt.Sprintf("bytes %v-%v/%v", offset, offset+partSize-1, size),
+			"Digest":        "sha=" + base64.StdEncoding.EncodeToString(partDigest[:]),
+		}
+		response, err := s.do(ctx, http.MethodPut, uploadSession.SessionEndpoints.UploadPart, headers, data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to upload part at %v", offset)
+		}
+		uploaded := &struct {
+			Part *part `json:"part"`
+		}{}
+		err = json.NewDecoder(response.Body).Decode(uploaded)
+		_ = response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, uploaded.Part)
+	}
+	body, _ := json.Marshal(map[string]interface{}{"parts": parts})
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"Digest":       "sha=" + base64.StdEncoding.EncodeToString(digest.Sum(nil)),
+	}
+	response, err := s.do(ctx, http.MethodPost, uploadSession.SessionEndpoints.Commit, headers, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to commit upload session")
+	}
+	return decodeUploaded(response)
+}
+
+func decodeUploaded(response *http.Response) (*item, error) {
+	defer response.Body.Close()
+	uploaded := &struct {
+		Entries []*item `json:"entries"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(uploaded); err != nil {
+		return nil, err
+	}
+	if len(uploaded.Entries) == 0 {
+		return nil, errors.New("upload response entries were empty")
+	}
+	return uploaded.Entries[0], nil
+}
+
+//Create creates folder, including missing parents, or file
+func (s *storager) Create(ctx context.Context, destination string, mode os.FileMode, reader io.Reader, isDir bool, options ...storage.Option) error {
+	if !isDir {
+		if reader == nil {
+			reader = bytes.NewReader(nil)
+		}
+		return s.Upload(ctx, destination, mode, reader, options...)
+	}
+	_, err := s.createFolder(ctx, boxPath(destination))
+	return err
+}
+
+func (s *storager) createFolder(ctx context.Context, location string) (*item, error) {
+	folder, err := s.resolve(ctx, location)
+	if err == nil || !IsNotFound(err) {
+		return folder, err
+	}
+	parentPath, name := path.Split(location)
+	parent, err := s.createFolder(ctx, boxPath(parentPath))
+	if err != nil {
+		return nil, err
+	}
+	folder = &item{}
+	err = s.call(ctx, http.MethodPost, apiURL+"/folders", map[string]interface{}{"name": name, "parent": map[string]string{"id": parent.ID}}, folder)
+	if apiError, ok := errors.Cause(err).(*Error); ok && apiError.StatusCode == http.StatusConflict {
+		//created concurrently
+		if ID := apiError.conflictID(); ID != "" {
+			folder, err = &item{Type: "folder", ID: ID, Name: name}, nil
+		}
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create folder %v", location)
+	}
+	s.items.Store(location, folder)
+	return folder, nil
+}
+
+//Delete deletes location
+func (s *storager) Delete(ctx context.Context, location string, options ...storage.Option) error {
+	location = boxPath(location)
+	entry, err := s.resolve(ctx, location)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	URL := fmt.Sprintf("%v/files/%v", apiURL, entry.ID)
+	if entry.isFolder() {
+		URL = fmt.Sprintf("%v/folders/%v?recursive=true", apiURL, entry.ID)
+	}
+	err = s.call(ctx, http.MethodDelete, URL, nil, nil)
+	s.forget(location)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+//Close closes storager
+func (s *storager) Close() error {
+	return nil
+}
+
+//newStorager creates storager, http client provider option supplies resource proxy and TLS transport
+func newStorager(credentials *Credentials, options ...storage.Option) *storager {
+	httpClient := &http.Client{Timeout: 30 * time.Minute}
+	var provider ahttp.ClientProvider
+	if _, ok := option.Assign(options, &provider); ok {
+		if provided, err := provider(Scheme, options...); err == nil && provided != nil {
+			httpClient.Transport = provided.Transport
+		}
+	}
+	return &storager{client: client{credentials: credentials, http: httpClient}}
+}
@@ -0,0 +1,187 @@
+package box
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type fakeItem struct {
+	item
+	parent  string
+	content string
+}
+
+//fakeBox emulates subset of Box API used by storager
+type fakeBox struct {
+	mux      sync.Mutex
+	server   *httptest.Server
+	items    map[string]*fakeItem
+	sessions map[string]*bytes.Buffer
+	events   []string
+	sequence int
+}
+
+func (f *fakeBox) add(parent, kind, name, content string) *fakeItem {
+	f.sequence++
+	result := &fakeItem{item: item{Type: kind, ID: fmt.Sprint(f.sequence), Name: name, Size: int64(len(content))}, parent: parent, content: content}
+	f.items[result.ID] = result
+	f.events = append(f.events, result.ID)
+	return result
+}
+
+func (f *fakeBox) pathCollection(parent string) []*item {
+	var result []*item
+	for parent != rootFolderID {
+		folder := f.items[parent]
+		result = append([]*item{{Type: "folder", ID: folder.ID, Name: folder.Name}}, result...)
+		parent = folder.parent
+	}
+	return append([]*item{{Type: "folder", ID: rootFolderID, Name: "All Files"}}, result...)
+}
+
+func (f *fakeBox) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	reply := func(status int, value interface{}) {
+		writer.WriteHeader(status)
+		_ = json.NewEncoder(writer).Encode(value)
+	}
+	if request.URL.Path == "/oauth2/token" {
+		_ = request.ParseForm()
+		if request.Form.Get("grant_type") != "client_credentials" || request.Form.Get("box_subject_id") != "42" {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		reply(http.StatusOK, map[string]interface{}{"access_token": "access", "token_type": "bearer", "expires_in": 3600})
+		return
+	}
+	if request.Header.Get("Authorization") != "Bearer access" {
+		writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	elements := strings.Split(strings.Trim(request.URL.Path, "/"), "/")
+	switch {
+	case request.Method == http.MethodGet && len(elements) == 4 && elements[1] == "folders":
+		var entries []*item
+		for _, candidate := range f.items {
+			if candidate.parent == elements[2] {
+				entries = append(entries, &candidate.item)
+			}
+		}
+		reply(http.StatusOK, map[string]interface{}{"entries": entries})
+	case request.Method == http.MethodGet && elements[1] == "files":
+		_, _ = writer.Write([]byte(f.items[elements[2]].content))
+	case request.Method == http.MethodPost && request.URL.Path == "/2.0/folders":
+		arg := struct {
+			Name   string
+			Parent struct{ ID string }
+		}{}
+		_ = json.NewDecoder(request.Body).Decode(&arg)
+		reply(http.StatusCreated, f.add(arg.Parent.ID, "folder", arg.Name, "").item)
+	case request.Method == http.MethodPost && request.URL.Path == "/api/2.0/files/content":
+		_ = request.ParseMultipartForm(1024)
+		arg := struct {
+			Name   string
+			Parent struct{ ID string }
+		}{}
+		_ = json.Unmarshal([]byte(request.FormValue("attributes")), &arg)
+		file, _, _ := request.FormFile("file")
+		data, _ := ioutil.ReadAll(file)
+		reply(http.StatusCreated, map[string]interface{}{"entries": []interface{}{f.add(arg.Parent.ID, "file", arg.Name, string(data)).item}})
+	case request.Method == http.MethodPost && request.URL.Path == "/api/2.0/files/upload_sessions":
+		ID := fmt.Sprint(len(f.sessions))
+		f.sessions[ID] = new(bytes.Buffer)
+		reply(http.StatusCreated, map[string]interface{}{"id": ID, "part_size": 4, "session_endpoints": map[string]string{
+			"upload_part": f.server.URL + "/api/2.0/files/upload_sessions/" + ID,
+			"commit":      f.server.URL + "/api/2.0/files/upload_sessions/" + ID + "/commit",
+		}})
+	case request.Method == http.MethodPut && elements[3] == "upload_sessions":
+		data, _ := ioutil.ReadAll(request.Body)
+		digest := sha1.Sum(data)
+		if request.Header.Get("Digest") != "sha="+base64.StdEncoding.EncodeToString(digest[:]) {
+			reply(http.StatusPreconditionFailed, map[string]string{"code": "invalid_digest"})
+			return
+		}
+		f.sessions[elements[4]].Write(data)
+		reply(http.StatusOK, map[string]interface{}{"part": map[string]interface{}{"part_id": fmt.Sprint(len(data)), "size": len(data)}})
+	case request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/commit"):
+		content := f.sessions[elements[4]].String()
+		reply(http.StatusCreated, map[string]interface{}{"entries": []interface{}{f.add("1", "file", "large.csv", content).item}})
+	case request.URL.Path == "/2.0/events":
+		position := request.URL.Query().Get("stream_position")
+		if position == "now" {
+			reply(http.StatusOK, map[string]interface{}{"next_stream_position": len(f.events)})
+			return
+		}
+		var offset int
+		_, _ = fmt.Sscan(position, &offset)
+		var entries []interface{}
+		for _, ID := range f.events[offset:] {
+			source := f.items[ID].item
+			source.PathCollection = &struct {
+				Entries []*item `json:"entries"`
+			}{Entries: f.pathCollection(f.items[ID].parent)}
+			entries = append(entries, map[string]interface{}{"event_type": "ITEM_UPLOAD", "source": source})
+		}
+		reply(http.StatusOK, map[string]interface{}{"next_stream_position": len(f.events), "entries": entries})
+	default:
+		reply(http.StatusNotFound, map[string]string{"code": "not_found"})
+	}
+}
+
+func TestStorager(t *testing.T) {
+	box := &fakeBox{items: map[string]*fakeItem{}, sessions: map[string]*bytes.Buffer{}}
+	box.server = httptest.NewServer(box)
+	defer box.server.Close()
+	apiURL, uploadURL, tokenURL = box.server.URL+"/2.0", box.server.URL+"/api/2.0", box.server.URL+"/oauth2/token"
+	ChunkedUploadThreshold = 8
+	inbound := box.add(rootFolderID, "folder", "inbound", "")
+	box.add(inbound.ID, "file", "a.csv", "a")
+	ctx := context.Background()
+	credentials, err := NewCredentials([]byte(`{"ClientID":"app","ClientSecret":"secret","SubjectType":"enterprise","SubjectID":"42"}`))
+	if !assert.Nil(t, err) {
+		return
+	}
+	fs := afs.New()
+
+	objects, err := fs.List(ctx, "box://partners/inbound", credentials)
+	if assert.Nil(t, err) && assert.Equal(t, 2, len(objects)) {
+		assert.Equal(t, "box://partners/inbound/a.csv", objects[1].URL())
+	}
+	data, err := fs.DownloadWithURL(ctx, "box://partners/inbound/a.csv", credentials)
+	assert.Nil(t, err)
+	assert.Equal(t, "a", string(data))
+
+	_, cursor, err := Changes(ctx, "box://partners/inbound", "", credentials)
+	assert.Nil(t, err)
+
+	err = fs.Upload(ctx, "box://partners/inbound/large.csv", 0644, strings.NewReader("0123456789"), credentials)
+	assert.Nil(t, err)
+	err = fs.Upload(ctx, "box://partners/outbound/small.csv", 0644, strings.NewReader("abc"), credentials)
+	assert.Nil(t, err)
+	var uploaded = map[string]string{}
+	for _, candidate := range box.items {
+		uploaded[candidate.Name] = candidate.content
+	}
+	assert.Equal(t, "0123456789", uploaded["large.csv"])
+	assert.Equal(t, "abc", uploaded["small.csv"])
+
+	changed, _, err := Changes(ctx, "box://partners/inbound", cursor, credentials)
+	if assert.Nil(t, err) && assert.Equal(t, 1, len(changed)) {
+		assert.Equal(t, "box://partners/inbound/large.csv", changed[0].URL())
+		assert.EqualValues(t, 10, changed[0].Size())
+	}
+}
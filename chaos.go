@@ -0,0 +1,83 @@
+package smirror
+
+import (
+	"fmt"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/shared"
+	"io"
+	"math/rand"
+	"time"
+)
+
+const (
+	chaosUpload = "upload"
+	chaosSecret = "secret"
+)
+
+//chaosFault returns injected upload or secret resolution error with configured probability
+func (s *service) chaosFault(fault, URL string) error {
+	chaos := s.config.Chaos
+	if chaos == nil {
+		return nil
+	}
+	probability, code := chaos.UploadFailure, base.ErrorCodeTransient
+	if fault == chaosSecret {
+		probability, code = chaos.SecretFailure, base.ErrorCodeAuth
+	}
+	if !chaosInjected(probability) {
+		return nil
+	}
+	shared.LogF("chaos: injected %v fault: %v\n", fault, URL)
+	return base.NewCodedError(code, fmt.Errorf("chaos: injected %v failure: %v", fault, URL))
+}
+
+//chaosReader returns source reader slowed down or truncated with configured probability
+func (s *service) chaosReader(reader io.Reader, URL string) io.Reader {
+	chaos := s.config.Chaos
+	if chaos == nil {
+		return reader
+	}
+	result := &faultyReader{Reader: reader, limit: -1}
+	if chaosInjected(chaos.SlowRead) {
+		shared.LogF("chaos: injected slow read: %v\n", URL)
+		result.delay = chaos.ReadDelayDuration()
+	}
+	if chaosInjected(chaos.Truncate) {
+		shared.LogF("chaos: injected truncated stream: %v\n", URL)
+		result.limit = chaos.TruncateAtBytes()
+	}
+	if result.delay == 0 && result.limit == -1 {
+		return reader
+	}
+	return result
+}
+
+func chaosInjected(probability float64) bool {
+	return probability > 0 && rand.Float64() < probability
+}
+
+//faultyReader represents reader delaying each read, failing after limit bytes if limit is not negative
+type faultyReader struct {
+	io.Reader
+	delay time.Duration
+	limit int64
+	read  int64
+}
+
+func (r *faultyReader) Read(data []byte) (int, error) {
+	if r.delay > 0 {
+		time.Sleep(r.delay)
+	}
+	if r.limit >= 0 {
+		remaining := r.limit - r.read
+		if remaining <= 0 {
+			return 0, base.NewCodedError(base.ErrorCodeTransient, fmt.Errorf("chaos: truncated stream after %v bytes: %w", r.read, io.ErrUnexpectedEOF))
+		}
+		if int64(len(data)) > remaining {
+			data = data[:remaining]
+		}
+	}
+	n, err := r.Reader.Read(data)
+	r.read += int64(n)
+	return n, err
+}
@@ -0,0 +1,72 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFaultyReader_Read(t *testing.T) {
+	var useCases = []struct {
+		description string
+		limit       int64
+		expect      string
+		expectCode  string
+	}{
+		{description: "truncated", limit: 4, expect: "1,2,", expectCode: base.ErrorCodeTransient},
+		{description: "within limit", limit: 100, expect: "1,2,3"},
+		{description: "no limit", limit: -1, expect: "1,2,3"},
+	}
+	for _, useCase := range useCases {
+		reader := &faultyReader{Reader: strings.NewReader("1,2,3"), limit: useCase.limit}
+		data, err := ioutil.ReadAll(reader)
+		assert.Equal(t, useCase.expect, string(data), useCase.description)
+		assert.Equal(t, useCase.expectCode, base.ErrorCode(err), useCase.description)
+	}
+}
+
+func TestService_MirrorChaos(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/chaos")
+	_ = fs.Upload(ctx, "mem://localhost/chaos/data/f1.csv", 0644, strings.NewReader(strings.Repeat("1,2,3\n", 100)))
+	_ = os.Setenv(base.ChaosEnvKey, "true")
+	defer func() {
+		_ = os.Unsetenv(base.ChaosEnvKey)
+	}()
+	var useCases = []struct {
+		description string
+		chaos       *config.Chaos
+		expectCode  string
+	}{
+		{description: "upload failure", chaos: &config.Chaos{UploadFailure: 1}, expectCode: base.ErrorCodeTransient},
+		{description: "secret failure", chaos: &config.Chaos{SecretFailure: 1}, expectCode: base.ErrorCodeAuth},
+		{description: "truncated stream", chaos: &config.Chaos{Truncate: 1, TruncateAt: 10}, expectCode: base.ErrorCodeTransient},
+		{description: "no fault", chaos: &config.Chaos{}},
+	}
+	for _, useCase := range useCases {
+		cfg := &Config{
+			Chaos: useCase.chaos,
+			Mirrors: config.Ruleset{Rules: []*config.Rule{
+				{
+					Source: &config.Resource{Basic: matcher.Basic{Prefix: "/chaos/data"}},
+					Dest:   &config.Resource{URL: "mem://localhost/chaos/dest"},
+				},
+			}},
+		}
+		service, err := New(ctx, cfg)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		response := service.Mirror(ctx, contract.NewRequest("mem://localhost/chaos/data/f1.csv"))
+		assert.Equal(t, useCase.expectCode, response.ErrorCode, useCase.description+" "+response.Error)
+	}
+}
@@ -0,0 +1,44 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/digest"
+)
+
+//claimCheck returns message payload, data exceeding dest claim check max bytes is written to claim-check object
+//and replaced with claim-check reference
+func (s *service) claimCheck(ctx context.Context, transfer *Transfer, data []byte, response *contract.Response) ([]byte, error) {
+	settings := transfer.Resource.ClaimCheck
+	if settings == nil || !settings.IsOffloaded(len(data)) {
+		return data, nil
+	}
+	return s.offload(ctx, transfer, data, response)
+}
+
+//offload writes data to claim-check object and returns claim-check reference
+func (s *service) offload(ctx context.Context, transfer *Transfer, data []byte, response *contract.Response) ([]byte, error) {
+	settings := transfer.Resource.ClaimCheck
+	algorithm := settings.DigestAlgorithm()
+	dataDigest, err := digest.Compute(algorithm, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	objectURL := settings.ObjectURL(response.TransferID, transfer.splitCounter, transfer.Dest.URL)
+	if err = s.fs.Upload(ctx, objectURL, file.DefaultFileOsMode, bytes.NewReader(data)); err != nil {
+		return nil, errors.Wrapf(err, "failed to upload claim-check: %v", objectURL)
+	}
+	response.AddClaimCheckURLs(objectURL)
+	return json.Marshal(&contract.ClaimCheck{
+		SourceURL:  response.TriggeredBy,
+		TransferID: response.TransferID,
+		URL:        objectURL,
+		Algorithm:  algorithm,
+		Digest:     dataDigest,
+		Size:       len(data),
+	})
+}
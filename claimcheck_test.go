@@ -0,0 +1,59 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/digest"
+	"strings"
+	"testing"
+)
+
+func TestService_ClaimCheck(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/claimcheck")
+	srv := &service{fs: fs}
+	var useCases = []struct {
+		description   string
+		claimCheck    *config.ClaimCheck
+		data          string
+		expectOffload bool
+	}{
+		{description: "no claim check", data: strings.Repeat("x", 100)},
+		{description: "within limit", claimCheck: &config.ClaimCheck{URL: "mem://localhost/claimcheck", MaxBytes: base.Bytes(100)}, data: strings.Repeat("x", 100)},
+		{description: "offloaded", claimCheck: &config.ClaimCheck{URL: "mem://localhost/claimcheck", MaxBytes: base.Bytes(10)}, data: strings.Repeat("x", 100), expectOffload: true},
+	}
+	for _, useCase := range useCases {
+		transfer := &Transfer{
+			Resource:     &config.Resource{Topic: "topic", ClaimCheck: useCase.claimCheck},
+			Dest:         NewDatafile("topic/data/f1_00002.csv", nil),
+			splitCounter: 2,
+		}
+		response := contract.NewResponse("mem://localhost/data/f1.csv")
+		response.TransferID = "t1"
+		payload, err := srv.claimCheck(ctx, transfer, []byte(useCase.data), response)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		if !useCase.expectOffload {
+			assert.Equal(t, useCase.data, string(payload), useCase.description)
+			assert.Empty(t, response.ClaimCheckURLs, useCase.description)
+			continue
+		}
+		claimCheck := &contract.ClaimCheck{}
+		assert.Nil(t, json.Unmarshal(payload, claimCheck), useCase.description)
+		assert.Equal(t, "mem://localhost/claimcheck/t1/00002_f1_00002.csv", claimCheck.URL, useCase.description)
+		assert.Equal(t, len(useCase.data), claimCheck.Size, useCase.description)
+		assert.Equal(t, []string{claimCheck.URL}, response.ClaimCheckURLs, useCase.description)
+		stored, err := fs.DownloadWithURL(ctx, claimCheck.URL)
+		assert.Nil(t, err, useCase.description)
+		expectDigest, _ := digest.Compute(config.DigestSHA256, strings.NewReader(string(stored)))
+		assert.Equal(t, expectDigest, claimCheck.Digest, useCase.description)
+		assert.Equal(t, useCase.data, string(stored), useCase.description)
+	}
+}
@@ -0,0 +1,60 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"testing"
+)
+
+func TestService_Classification(t *testing.T) {
+	var useCases = []struct {
+		description       string
+		maxClassification string
+		expectStatus      string
+	}{
+		{
+			description:       "pii dest accepts pii",
+			maxClassification: config.ClassificationPII,
+			expectStatus:      base.StatusOK,
+		},
+		{
+			description:  "undeclared dest refuses pii",
+			expectStatus: base.StatusError,
+		},
+	}
+
+	ctx := context.Background()
+	fs := afs.New()
+	for _, useCase := range useCases {
+		destURL := "mem://localhost/classification/dest"
+		cfg := &Config{
+			Mirrors: config.Ruleset{Rules: []*config.Rule{
+				{
+					Classification: config.ClassificationPII,
+					Source:         &config.Resource{Basic: matcher.Basic{Prefix: "/classification/data"}},
+					Dest:           &config.Resource{URL: destURL, MaxClassification: useCase.maxClassification},
+				},
+			}},
+		}
+		service, err := New(ctx, cfg)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		sourceURL := "mem://localhost/classification/data/customers.csv"
+		_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("id,email\n1,a@b.c\n"))
+		response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+		assert.Equal(t, useCase.expectStatus, response.Status, useCase.description+" "+response.Error)
+		if useCase.expectStatus == base.StatusOK {
+			assert.Equal(t, 1, len(response.DestURLs), useCase.description)
+			continue
+		}
+		assert.Equal(t, base.ErrorCodePolicy, response.ErrorCode, useCase.description)
+		assert.Equal(t, 0, len(response.DestURLs), useCase.description)
+	}
+}
@@ -0,0 +1,43 @@
+package smirror
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/clickhouse"
+	"github.com/viant/smirror/contract"
+)
+
+//insertClickHouse streams transfer into ClickHouse table with HTTP interface INSERT
+func (s *service) insertClickHouse(ctx context.Context, transfer *Transfer, response *contract.Response) error {
+	settings := transfer.Resource.ClickHouse
+	var credentials *clickhouse.Credentials
+	if transfer.Resource.Credentials != nil {
+		var err error
+		if credentials, err = clickhouse.NewCredentials(transfer.Resource.Credentials.Auth); err != nil {
+			return base.NewCodedError(base.ErrorCodeAuth, err)
+		}
+	}
+	httpClient, err := s.httpClient(ctx, transfer.Resource)
+	if err != nil {
+		return err
+	}
+	client := clickhouse.New(transfer.Resource.URL, credentials, httpClient)
+	reader, err := transfer.GetReader()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get reader for: %v", transfer.Resource.URL)
+	}
+	waited, err := s.limiter.Wait(ctx, transfer.Resource.URL)
+	response.AddThrottleTime(waited)
+	if err != nil {
+		return err
+	}
+	format := settings.InsertFormat(response.TriggeredBy)
+	summary, err := client.Insert(ctx, settings.Table, format, settings.QuerySettings(format), reader)
+	if err != nil {
+		s.limiter.Report(transfer.Resource.URL, err)
+		return err
+	}
+	response.IncrementValue(contract.ValueLoadedRows, int(summary.WrittenRows))
+	return nil
+}
@@ -0,0 +1,118 @@
+package clickhouse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//SummaryHeader HTTP interface query summary header
+const SummaryHeader = "X-ClickHouse-Summary"
+
+//Credentials represents decrypted ClickHouse credentials
+type Credentials struct {
+	Username string `json:",omitempty"`
+	Password string `json:",omitempty"`
+}
+
+//Summary represents insert summary
+type Summary struct {
+	WrittenRows  int64
+	WrittenBytes int64
+}
+
+//Client represents ClickHouse HTTP interface client
+type Client struct {
+	URL         string
+	credentials *Credentials
+	http        *http.Client
+}
+
+//Insert streams data into table with INSERT ... FORMAT query, settings are passed as query parameters
+func (c *Client) Insert(ctx context.Context, table, format string, settings map[string]string, data io.Reader) (*Summary, error) {
+	endpoint, err := url.Parse(strings.TrimRight(c.URL, "/") + "/")
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid clickhouse URL: %v", c.URL)
+	}
+	query := endpoint.Query()
+	query.Set("query", fmt.Sprintf("INSERT INTO %v FORMAT %v", QuoteTable(table), format))
+	for key, value := range settings {
+		query.Set(key, value)
+	}
+	endpoint.RawQuery = query.Encode()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), data)
+	if err != nil {
+		return nil, err
+	}
+	if c.credentials != nil && c.credentials.Username != "" {
+		request.Header.Set("X-ClickHouse-User", c.credentials.Username)
+		request.Header.Set("X-ClickHouse-Key", c.credentials.Password)
+	}
+	response, err := c.http.Do(request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to insert into %v", table)
+	}
+	defer response.Body.Close()
+	body, _ := ioutil.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("failed to insert into %v: %v %s", table, response.Status, bytes.TrimSpace(body))
+	}
+	return decodeSummary(response.Header.Get(SummaryHeader)), nil
+}
+
+//decodeSummary decodes summary header, counters are encoded as JSON strings
+func decodeSummary(header string) *Summary {
+	summary := &Summary{}
+	if header == "" {
+		return summary
+	}
+	counters := map[string]string{}
+	if err := json.Unmarshal([]byte(header), &counters); err != nil {
+		return summary
+	}
+	summary.WrittenRows, _ = strconv.ParseInt(counters["written_rows"], 10, 64)
+	summary.WrittenBytes, _ = strconv.ParseInt(counters["written_bytes"], 10, 64)
+	return summary
+}
+
+//QuoteTable returns backtick quoted, optionally database qualified table
+func QuoteTable(table string) string {
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		parts[i] = "`" + part + "`"
+	}
+	return strings.Join(parts, ".")
+}
+
+//NewCredentials creates credentials from decrypted secret JSON
+func NewCredentials(secret []byte) (*Credentials, error) {
+	secret = bytes.TrimSpace(secret)
+	if len(secret) == 0 {
+		return nil, errors.New("clickhouse credentials were empty")
+	}
+	credentials := &Credentials{}
+	if err := json.Unmarshal(secret, credentials); err != nil {
+		return nil, errors.Wrap(err, "failed to decode clickhouse credentials")
+	}
+	if credentials.Username == "" {
+		return nil, errors.New("clickhouse credentials Username was empty")
+	}
+	return credentials, nil
+}
+
+//New creates a client
+func New(URL string, credentials *Credentials, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Minute}
+	}
+	return &Client{URL: URL, credentials: credentials, http: httpClient}
+}
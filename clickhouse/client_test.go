@@ -0,0 +1,65 @@
+package clickhouse
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_Insert(t *testing.T) {
+	var useCases = []struct {
+		description string
+		status      int
+		summary     string
+		expectRows  int64
+		expectError bool
+	}{
+		{
+			description: "inserted rows",
+			status:      http.StatusOK,
+			summary:     `{"read_rows":"0","read_bytes":"0","written_rows":"2","written_bytes":"24"}`,
+			expectRows:  2,
+		},
+		{
+			description: "async insert without summary",
+			status:      http.StatusOK,
+		},
+		{
+			description: "server error",
+			status:      http.StatusInternalServerError,
+			expectError: true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		var query, user, body string
+		server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			query = request.URL.Query().Get("query")
+			user = request.Header.Get("X-ClickHouse-User")
+			data, _ := ioutil.ReadAll(request.Body)
+			body = string(data)
+			if useCase.summary != "" {
+				writer.Header().Set(SummaryHeader, useCase.summary)
+			}
+			writer.WriteHeader(useCase.status)
+		}))
+		client := New(server.URL, &Credentials{Username: "loader", Password: "secret"}, nil)
+		summary, err := client.Insert(context.Background(), "db.events", "CSVWithNames", map[string]string{"async_insert": "1"}, strings.NewReader("id\n1\n2\n"))
+		server.Close()
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.expectRows, summary.WrittenRows, useCase.description)
+		assert.Equal(t, "INSERT INTO `db`.`events` FORMAT CSVWithNames", query, useCase.description)
+		assert.Equal(t, "loader", user, useCase.description)
+		assert.Equal(t, "id\n1\n2\n", body, useCase.description)
+	}
+}
@@ -0,0 +1,53 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestService_ClickHouse(t *testing.T) {
+	var query, body string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		query = request.URL.RawQuery
+		data, _ := ioutil.ReadAll(request.Body)
+		body = string(data)
+		writer.Header().Set("X-ClickHouse-Summary", `{"written_rows":"2"}`)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/clickhouse/data"}},
+				Dest: &config.Resource{
+					URL:        server.URL,
+					ClickHouse: &config.ClickHouse{Table: "analytics.events", AsyncInsert: true},
+				},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sourceURL := "mem://localhost/clickhouse/data/events.json"
+	_ = afs.New().Upload(ctx, sourceURL, 0644, strings.NewReader("{\"id\":1}\n{\"id\":2}\n"))
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	if !assert.Equal(t, base.StatusOK, response.Status, response.Error) {
+		return
+	}
+	assert.Equal(t, "{\"id\":1}\n{\"id\":2}\n", body)
+	assert.Contains(t, query, "async_insert=1")
+	assert.Contains(t, query, "JSONEachRow")
+	assert.EqualValues(t, 2, response.Values[contract.ValueLoadedRows])
+}
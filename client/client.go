@@ -0,0 +1,222 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/eventlog"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultPollInterval = 10 * time.Second
+
+//entryPoints default HTTP entry point function names by endpoint
+var entryPoints = map[string]string{
+	auth.EndpointMirror:   "StorageMirrorSubmit",
+	auth.EndpointBatch:    "StorageMirrorBatch",
+	auth.EndpointRules:    "StorageMirrorRuleStatus",
+	auth.EndpointHistory:  "StorageMirrorRuleHistory",
+	auth.EndpointActivate: "StorageMirrorActivate",
+	auth.EndpointPause:    "StorageMirrorPause",
+	auth.EndpointEvents:   "StorageMirrorEvents",
+}
+
+//Config represents deployed smirror HTTP endpoints
+type Config struct {
+	//BaseURL entry points base URL, i.e. https://us-central1-myproject.cloudfunctions.net
+	BaseURL string
+	//Endpoints endpoint (mirror, batch, rules, history, activate, pause, events) to entry point URL or path relative to BaseURL, default entry point function name
+	Endpoints map[string]string `json:",omitempty"`
+	//APIKey API key sent with X-Api-Key header
+	APIKey string `json:",omitempty"`
+	//Tenant default tenant name for multi tenant config
+	Tenant string `json:",omitempty"`
+}
+
+//EventHandler handles streamed event log events, returned error stops streaming
+type EventHandler func(event *eventlog.Event) error
+
+//Client represents smirror HTTP client, responses with error status are returned as is, error is returned for transport and HTTP failures
+type Client struct {
+	config     *Config
+	httpClient *http.Client
+}
+
+//Mirror submits mirror request, source object is mirrored synchronously
+func (c *Client) Mirror(ctx context.Context, request *contract.Request) (*contract.Response, error) {
+	response := contract.NewResponse(request.URL)
+	return response, c.post(ctx, auth.EndpointMirror, request, response)
+}
+
+//MirrorBatch submits batch mirror request, source objects are mirrored concurrently within one invocation
+func (c *Client) MirrorBatch(ctx context.Context, request *contract.BatchRequest) (*contract.BatchResponse, error) {
+	response := contract.NewBatchResponse()
+	return response, c.post(ctx, auth.EndpointBatch, request, response)
+}
+
+//RuleStatus returns rule files load status
+func (c *Client) RuleStatus(ctx context.Context, request *contract.RuleStatusRequest) (*contract.RuleStatusResponse, error) {
+	if request.Tenant == "" {
+		request.Tenant = c.config.Tenant
+	}
+	response := &contract.RuleStatusResponse{}
+	return response, c.post(ctx, auth.EndpointRules, request, response)
+}
+
+//RuleHistory evaluates source URL against rules active at request time and now
+func (c *Client) RuleHistory(ctx context.Context, request *contract.RuleHistoryRequest) (*contract.RuleHistoryResponse, error) {
+	if request.Tenant == "" {
+		request.Tenant = c.config.Tenant
+	}
+	response := &contract.RuleHistoryResponse{}
+	return response, c.post(ctx, auth.EndpointHistory, request, response)
+}
+
+//Activate validates pending rules and promotes them to active rules
+func (c *Client) Activate(ctx context.Context, request *contract.ActivationRequest) (*contract.ActivationResponse, error) {
+	if request.Tenant == "" {
+		request.Tenant = c.config.Tenant
+	}
+	response := &contract.ActivationResponse{}
+	return response, c.post(ctx, auth.EndpointActivate, request, response)
+}
+
+//Pause pauses or resumes ingestion for all rules or a rule, empty action returns active pause markers
+func (c *Client) Pause(ctx context.Context, request *contract.PauseRequest) (*contract.PauseResponse, error) {
+	if request.Tenant == "" {
+		request.Tenant = c.config.Tenant
+	}
+	response := &contract.PauseResponse{}
+	return response, c.post(ctx, auth.EndpointPause, request, response)
+}
+
+//Events returns event log events appended after request cursor
+func (c *Client) Events(ctx context.Context, request *contract.EventsRequest) (*contract.EventsResponse, error) {
+	query := url.Values{}
+	if request.Cursor != "" {
+		query.Set("cursor", request.Cursor)
+	}
+	if request.Limit > 0 {
+		query.Set("limit", strconv.Itoa(request.Limit))
+	}
+	tenant := request.Tenant
+	if tenant == "" {
+		tenant = c.config.Tenant
+	}
+	if tenant != "" {
+		query.Set("tenant", tenant)
+	}
+	response := &contract.EventsResponse{}
+	return response, c.do(ctx, http.MethodGet, auth.EndpointEvents, query, nil, response)
+}
+
+//StreamEvents reads event log from request cursor, polling for new events with interval (default 10s) until context is done
+//or handler returns error, it returns the last read cursor, so streaming can be resumed
+func (c *Client) StreamEvents(ctx context.Context, request *contract.EventsRequest, interval time.Duration, handler EventHandler) (string, error) {
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	cursor := request.Cursor
+	for {
+		page := *request
+		page.Cursor = cursor
+		response, err := c.Events(ctx, &page)
+		if err != nil {
+			return cursor, err
+		}
+		if response.Error != "" {
+			return cursor, errors.New(response.Error)
+		}
+		for _, event := range response.Events {
+			if err = handler(event); err != nil {
+				return cursor, err
+			}
+		}
+		cursor = response.Cursor
+		if len(response.Events) > 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return cursor, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+//URL returns endpoint URL
+func (c *Client) URL(endpoint string) string {
+	location, ok := c.config.Endpoints[endpoint]
+	if !ok {
+		location = entryPoints[endpoint]
+	}
+	if strings.Contains(location, "://") {
+		return location
+	}
+	return strings.TrimRight(c.config.BaseURL, "/") + "/" + strings.TrimLeft(location, "/")
+}
+
+func (c *Client) post(ctx context.Context, endpoint string, request, response interface{}) error {
+	return c.do(ctx, http.MethodPost, endpoint, nil, request, response)
+}
+
+func (c *Client) do(ctx context.Context, method, endpoint string, query url.Values, request, response interface{}) error {
+	URL := c.URL(endpoint)
+	if len(query) > 0 {
+		URL += "?" + query.Encode()
+	}
+	var body io.Reader
+	if request != nil {
+		data, err := json.Marshal(request)
+		if err != nil {
+			return errors.Wrapf(err, "failed to encode %T", request)
+		}
+		body = bytes.NewReader(data)
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, method, URL, body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %v request", endpoint)
+	}
+	if request != nil {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+	if c.config.APIKey != "" {
+		httpRequest.Header.Set(auth.APIKeyHeader, c.config.APIKey)
+	}
+	httpResponse, err := c.httpClient.Do(httpRequest)
+	if err != nil {
+		return errors.Wrapf(err, "failed to call %v", URL)
+	}
+	defer func() {
+		_ = httpResponse.Body.Close()
+	}()
+	data, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %v response", endpoint)
+	}
+	if httpResponse.StatusCode/100 != 2 {
+		return errors.Errorf("%v endpoint returned %v: %s", endpoint, httpResponse.StatusCode, bytes.TrimSpace(data))
+	}
+	if err = json.Unmarshal(data, response); err != nil {
+		return errors.Wrapf(err, "failed to decode %T", response)
+	}
+	return nil
+}
+
+//New creates smirror client, nil httpClient uses http.DefaultClient, use Google ID token client (google.golang.org/api/idtoken)
+//for IAM protected entry points
+func New(config *Config, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{config: config, httpClient: httpClient}
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/eventlog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_Mirror(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get(auth.APIKeyHeader) != "k1" {
+			http.Error(writer, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch request.URL.Path {
+		case "/StorageMirrorSubmit":
+			mirrorRequest := &contract.Request{}
+			_ = json.NewDecoder(request.Body).Decode(mirrorRequest)
+			response := contract.NewResponse(mirrorRequest.URL)
+			response.DestURLs = []string{"gs://dest/" + mirrorRequest.URL[len("gs://source/"):]}
+			_ = json.NewEncoder(writer).Encode(response)
+		case "/rules":
+			statusRequest := &contract.RuleStatusRequest{}
+			_ = json.NewDecoder(request.Body).Decode(statusRequest)
+			response := contract.NewRuleStatusResponse()
+			response.Error = statusRequest.Tenant
+			_ = json.NewEncoder(writer).Encode(response)
+		default:
+			http.NotFound(writer, request)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	client := New(&Config{BaseURL: server.URL, APIKey: "k1", Tenant: "team1", Endpoints: map[string]string{auth.EndpointRules: "/rules"}}, nil)
+	response, err := client.Mirror(ctx, contract.NewRequest("gs://source/data/f1.csv"))
+	if assert.Nil(t, err) {
+		assert.Equal(t, base.StatusOK, response.Status)
+		assert.Equal(t, []string{"gs://dest/data/f1.csv"}, response.DestURLs)
+	}
+	status, err := client.RuleStatus(ctx, &contract.RuleStatusRequest{})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "team1", status.Error)
+	}
+	_, err = client.Pause(ctx, &contract.PauseRequest{})
+	assert.NotNil(t, err)
+
+	client = New(&Config{BaseURL: server.URL}, nil)
+	_, err = client.Mirror(ctx, contract.NewRequest("gs://source/data/f1.csv"))
+	assert.NotNil(t, err)
+}
+
+func TestClient_StreamEvents(t *testing.T) {
+	pages := map[string]*contract.EventsResponse{
+		"":   {Status: base.StatusOK, Cursor: "c1", Events: []*eventlog.Event{{Type: eventlog.TypeReceived}, {Type: eventlog.TypeMatched}}},
+		"c1": {Status: base.StatusOK, Cursor: "c2", Events: []*eventlog.Event{{Type: eventlog.TypeUploaded}}},
+		"c2": {Status: base.StatusOK, Cursor: "c2", Events: []*eventlog.Event{}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_ = json.NewEncoder(writer).Encode(pages[request.URL.Query().Get("cursor")])
+	}))
+	defer server.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	client := New(&Config{BaseURL: server.URL}, nil)
+	var types = make([]string, 0)
+	cursor, err := client.StreamEvents(ctx, &contract.EventsRequest{}, 10*time.Millisecond, func(event *eventlog.Event) error {
+		types = append(types, event.Type)
+		return nil
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, "c2", cursor)
+	assert.Equal(t, []string{eventlog.TypeReceived, eventlog.TypeMatched, eventlog.TypeUploaded}, types)
+}
@@ -0,0 +1,204 @@
+package smirror
+
+import (
+	"context"
+	"github.com/viant/afs"
+	ahttp "github.com/viant/afs/http"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/secret"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+//transportOnce shared transport is tuned once per process
+var transportOnce sync.Once
+
+//connectionStats shared transport new connection counters
+var connectionStats = struct {
+	dials    uint64
+	tlsDials uint64
+}{}
+
+//tuneTransport applies idle connection settings to default HTTP transport and counts new connections,
+//storage clients clone or use default transport, so tuned settings apply to gs and s3 clients created afterwards
+func tuneTransport(connections *config.Connections) {
+	transportOnce.Do(func() {
+		transport, ok := http.DefaultTransport.(*http.Transport)
+		if !ok {
+			return
+		}
+		transport.MaxIdleConns = connections.MaxIdleConns
+		transport.MaxIdleConnsPerHost = connections.MaxIdleConnsPerHost
+		transport.IdleConnTimeout = connections.IdleConnTimeout.Duration()
+		dialContext := transport.DialContext
+		if dialContext == nil {
+			dialContext = (&net.Dialer{}).DialContext
+		}
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			atomic.AddUint64(&connectionStats.dials, 1)
+			if strings.HasSuffix(addr, ":443") {
+				atomic.AddUint64(&connectionStats.tlsDials, 1)
+			}
+			return dialContext(ctx, network, addr)
+		}
+	})
+}
+
+//clients represents thread safe storage service cache keyed by scheme and credential identity,
+//each identity uses dedicated afs service, so managers (and their HTTP clients) are not rebuilt when a bucket is accessed with different credentials
+type clients struct {
+	afs.Service
+	services sync.Map
+	count    int32
+	hits     uint64
+	misses   uint64
+}
+
+//service returns storage service for options credential identity
+func (c *clients) service(options []storage.Option) afs.Service {
+	var identity secret.Identity
+	if _, ok := option.Assign(options, &identity); !ok || identity == "" {
+		return c.Service
+	}
+	if service, ok := c.services.Load(identity); ok {
+		atomic.AddUint64(&c.hits, 1)
+		return service.(afs.Service)
+	}
+	service, loaded := c.services.LoadOrStore(identity, afs.New())
+	if loaded {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+		atomic.AddInt32(&c.count, 1)
+	}
+	return service.(afs.Service)
+}
+
+//Stats returns client cache and connection stats
+func (c *clients) Stats() *contract.Clients {
+	return &contract.Clients{
+		Identities: int(atomic.LoadInt32(&c.count)),
+		Hits:       atomic.LoadUint64(&c.hits),
+		Misses:     atomic.LoadUint64(&c.misses),
+		Dials:      atomic.LoadUint64(&connectionStats.dials),
+		TLSDials:   atomic.LoadUint64(&connectionStats.tlsDials),
+	}
+}
+
+//List lists resources with identity service
+func (c *clients) List(ctx context.Context, URL string, options ...storage.Option) ([]storage.Object, error) {
+	return c.service(options).List(ctx, URL, options...)
+}
+
+//Object returns an object with identity service
+func (c *clients) Object(ctx context.Context, URL string, options ...storage.Option) (storage.Object, error) {
+	return c.service(options).Object(ctx, URL, options...)
+}
+
+//Open opens an object with identity service
+func (c *clients) Open(ctx context.Context, object storage.Object, options ...storage.Option) (io.ReadCloser, error) {
+	return c.service(options).Open(ctx, object, options...)
+}
+
+//OpenURL opens URL with identity service
+func (c *clients) OpenURL(ctx context.Context, URL string, options ...storage.Option) (io.ReadCloser, error) {
+	return c.service(options).OpenURL(ctx, URL, options...)
+}
+
+//Upload uploads content with identity service
+func (c *clients) Upload(ctx context.Context, URL string, mode os.FileMode, reader io.Reader, options ...storage.Option) error {
+	return c.service(options).Upload(ctx, URL, mode, reader, options...)
+}
+
+//Uploader returns batch uploader with identity service
+func (c *clients) Uploader(ctx context.Context, URL string, options ...storage.Option) (storage.Upload, io.Closer, error) {
+	return c.service(options).Uploader(ctx, URL, options...)
+}
+
+//Delete deletes resource with identity service
+func (c *clients) Delete(ctx context.Context, URL string, options ...storage.Option) error {
+	return c.service(options).Delete(ctx, URL, options...)
+}
+
+//Create creates resource with identity service
+func (c *clients) Create(ctx context.Context, URL string, mode os.FileMode, isDir bool, options ...storage.Option) error {
+	return c.service(options).Create(ctx, URL, mode, isDir, options...)
+}
+
+//Walk walks resources with identity service
+func (c *clients) Walk(ctx context.Context, URL string, handler storage.OnVisit, options ...storage.Option) error {
+	return c.service(options).Walk(ctx, URL, handler, options...)
+}
+
+//Exists checks resource with identity service
+func (c *clients) Exists(ctx context.Context, URL string, options ...storage.Option) (bool, error) {
+	return c.service(options).Exists(ctx, URL, options...)
+}
+
+//Download downloads object with identity service
+func (c *clients) Download(ctx context.Context, object storage.Object, options ...storage.Option) ([]byte, error) {
+	return c.service(options).Download(ctx, object, options...)
+}
+
+//DownloadWithURL downloads URL with identity service
+func (c *clients) DownloadWithURL(ctx context.Context, URL string, options ...storage.Option) ([]byte, error) {
+	return c.service(options).DownloadWithURL(ctx, URL, options...)
+}
+
+//Copy copies resource with identity service
+func (c *clients) Copy(ctx context.Context, sourceURL, destURL string, options ...storage.Option) error {
+	return c.service(options).Copy(ctx, sourceURL, destURL, options...)
+}
+
+//Move moves resource with identity service
+func (c *clients) Move(ctx context.Context, sourceURL, destURL string, options ...storage.Option) error {
+	return c.service(options).Move(ctx, sourceURL, destURL, options...)
+}
+
+//Init initialises manager with identity service
+func (c *clients) Init(ctx context.Context, baseURL string, options ...storage.Option) error {
+	return c.service(options).Init(ctx, baseURL, options...)
+}
+
+//NewWriter creates an upload writer with identity service
+func (c *clients) NewWriter(ctx context.Context, URL string, mode os.FileMode, options ...storage.Option) (io.WriteCloser, error) {
+	return c.service(options).NewWriter(ctx, URL, mode, options...)
+}
+
+//CloseAll closes all identity services managers
+func (c *clients) CloseAll() error {
+	c.services.Range(func(key, value interface{}) bool {
+		_ = value.(afs.Service).CloseAll()
+		return true
+	})
+	return c.Service.CloseAll()
+}
+
+//newClients creates storage client cache, default credentials use shared default service
+func newClients() *clients {
+	return &clients{Service: afs.New()}
+}
+
+//httpClient returns http client with resource proxy and TLS transport, nil client means package default client
+func (s *service) httpClient(ctx context.Context, resource *config.Resource) (*http.Client, error) {
+	if resource.Proxy == nil && resource.TLS == nil {
+		return nil, nil
+	}
+	options, err := s.secret.StorageOpts(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+	var provider ahttp.ClientProvider
+	if _, ok := option.Assign(options, &provider); !ok {
+		return nil, nil
+	}
+	return provider(resource.URL, options...)
+}
@@ -0,0 +1,51 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs/storage"
+	"github.com/viant/smirror/secret"
+	"strings"
+	"testing"
+)
+
+func TestClients_Service(t *testing.T) {
+	ctx := context.Background()
+	fs := newClients()
+	baseURL := "mem://localhost/clients"
+	_ = fs.Delete(ctx, baseURL)
+
+	var useCases = []struct {
+		description string
+		options     []storage.Option
+		expectNew   bool
+	}{
+		{description: "default credentials"},
+		{description: "first identity", options: []storage.Option{secret.Identity("gs://auth:1")}, expectNew: true},
+		{description: "same identity reused", options: []storage.Option{secret.Identity("gs://auth:1")}},
+		{description: "other identity", options: []storage.Option{secret.Identity("gs://auth:2")}, expectNew: true},
+	}
+	for _, useCase := range useCases {
+		before := fs.Stats()
+		service := fs.service(useCase.options)
+		after := fs.Stats()
+		if len(useCase.options) == 0 {
+			assert.True(t, service == fs.Service, useCase.description)
+			assert.EqualValues(t, before.Hits+before.Misses, after.Hits+after.Misses, useCase.description)
+			continue
+		}
+		assert.False(t, service == fs.Service, useCase.description)
+		if useCase.expectNew {
+			assert.EqualValues(t, before.Misses+1, after.Misses, useCase.description)
+			assert.EqualValues(t, before.Identities+1, after.Identities, useCase.description)
+		} else {
+			assert.EqualValues(t, before.Hits+1, after.Hits, useCase.description)
+			assert.EqualValues(t, before.Identities, after.Identities, useCase.description)
+		}
+		err := fs.Upload(ctx, baseURL+"/f.txt", 0644, strings.NewReader("abc"), useCase.options...)
+		assert.Nil(t, err, useCase.description)
+	}
+	data, err := fs.DownloadWithURL(ctx, baseURL+"/f.txt")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "abc", string(data))
+}
@@ -4,8 +4,14 @@ import (
 	"context"
 	"github.com/jessevdk/go-flags"
 	"github.com/viant/smirror/cmd/build"
+	"github.com/viant/smirror/cmd/export"
+	"github.com/viant/smirror/cmd/list"
+	"github.com/viant/smirror/cmd/loadtest"
+	"github.com/viant/smirror/cmd/migrate"
 	"github.com/viant/smirror/cmd/mirror"
 	"github.com/viant/smirror/cmd/option"
+	"github.com/viant/smirror/cmd/replay"
+	"github.com/viant/smirror/cmd/rules"
 	"github.com/viant/smirror/cmd/validate"
 	"github.com/viant/smirror/shared"
 	"log"
@@ -28,7 +34,10 @@ func RunClient(Version string, args []string) {
 	}
 	canBuildRule :=  options.DestinationURL != ""
 	canMirror := options.SourceURL != ""
-	if !(canMirror || options.Validate || canBuildRule) && len(args) == 1 {
+	if options.Command.Name == commandValidate {
+		options.Validate = true
+	}
+	if !(canMirror || options.Validate || canBuildRule) && options.Command.Name == "" && len(args) == 1 {
 		os.Exit(1)
 	}
 
@@ -37,6 +46,9 @@ func RunClient(Version string, args []string) {
 		log.Fatal(err)
 	}
 	ctx := context.Background()
+	if runCommand(ctx, srv, options) {
+		os.Exit(0)
+	}
 	if options.RuleURL == "" || canBuildRule {
 		err = srv.Build(ctx, &build.Request{Options: options})
 		if err != nil {
@@ -51,7 +63,7 @@ func RunClient(Version string, args []string) {
 		os.Exit(0)
 	}
 
-	response, err := srv.Mirror(ctx, &mirror.Request{options})
+	response, err := srv.Mirror(ctx, &mirror.Request{Options: options})
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -63,6 +75,58 @@ func RunClient(Version string, args []string) {
 }
 
 
+//runCommand runs command other than rule based mirror/validate, it returns true if command was handled
+func runCommand(ctx context.Context, srv Service, options *option.Options) bool {
+	var response interface{}
+	var err error
+	switch options.Command.Name {
+	case "", commandMirror, commandValidate:
+		if options.ConfigURL == "" || options.Validate {
+			return false
+		}
+		mirrorResponse, e := srv.MirrorURL(ctx, &mirror.Request{Options: options})
+		if err = e; mirrorResponse != nil {
+			response = mirrorResponse
+		}
+	case commandRules:
+		err = srv.Rules(ctx, &rules.Request{Options: options})
+	case commandReplay:
+		replayResponse, e := srv.Replay(ctx, &replay.Request{Options: options})
+		if err = e; replayResponse != nil {
+			response = replayResponse
+		}
+	case commandList:
+		listResponse, e := srv.List(ctx, &list.Request{Options: options})
+		if err = e; listResponse != nil {
+			response = listResponse
+		}
+	case commandExport:
+		exportResponse, e := srv.Export(ctx, &export.Request{Options: options})
+		if err = e; exportResponse != nil {
+			response = exportResponse
+		}
+	case commandMigrate:
+		migrateResponse, e := srv.Migrate(ctx, &migrate.Request{Options: options})
+		if err = e; migrateResponse != nil {
+			response = migrateResponse
+		}
+	case commandLoadTest:
+		loadTestResponse, e := srv.LoadTest(ctx, &loadtest.Request{Options: options})
+		if err = e; loadTestResponse != nil {
+			response = loadTestResponse
+		}
+	default:
+		log.Fatalf("unsupported command: %v, supported: %v|%v|%v|%v|%v|%v|%v|%v", options.Command.Name, commandMirror, commandValidate, commandReplay, commandList, commandRules, commandExport, commandMigrate, commandLoadTest)
+	}
+	if response != nil {
+		shared.LogLn(response)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	return true
+}
+
 func isHelOption(args []string) bool {
 	for _, arg := range args {
 		if arg == "-h" {
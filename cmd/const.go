@@ -3,3 +3,14 @@ package cmd
 const (
 	processingRoutines = 32
 )
+
+const (
+	commandMirror   = "mirror"
+	commandValidate = "validate"
+	commandReplay   = "replay"
+	commandList     = "ls"
+	commandRules    = "rules"
+	commandExport   = "export"
+	commandMigrate  = "migrate"
+	commandLoadTest = "loadtest"
+)
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"context"
+	"github.com/viant/smirror"
+	"github.com/viant/smirror/cmd/export"
+)
+
+//Export renders effective config as canonical JSON with stable hash
+func (s *service) Export(ctx context.Context, request *export.Request) (*smirror.ConfigExport, error) {
+	request.Init(s.config)
+	cfg, err := s.loadConfig(ctx, request.Options)
+	if err != nil {
+		return nil, err
+	}
+	return smirror.ExportConfig(cfg)
+}
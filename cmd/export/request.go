@@ -0,0 +1,8 @@
+package export
+
+import "github.com/viant/smirror/cmd/option"
+
+//Request represents config export request
+type Request struct {
+	*option.Options
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/cmd/list"
+	"github.com/viant/smirror/cron"
+)
+
+//List lists pending cron candidates for cron config
+func (s *service) List(ctx context.Context, request *list.Request) (*cron.Response, error) {
+	request.Init(s.config)
+	if request.ConfigURL == "" {
+		return nil, errors.New("cron configURL was empty")
+	}
+	cfg, err := cron.NewConfigFromURL(ctx, request.ConfigURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load cron config: %v", request.ConfigURL)
+	}
+	cronService, err := cron.New(ctx, cfg, s.fs)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cron service")
+	}
+	response := cronService.Pending(ctx)
+	if response.Error != "" {
+		return response, errors.New(response.Error)
+	}
+	return response, nil
+}
@@ -0,0 +1,8 @@
+package list
+
+import "github.com/viant/smirror/cmd/option"
+
+//Request represents list pending cron candidates request
+type Request struct {
+	*option.Options
+}
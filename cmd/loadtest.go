@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/cmd/loadtest"
+	sloadtest "github.com/viant/smirror/loadtest"
+)
+
+//LoadTest generates synthetic files into source URL and drives mirror pipeline at target rate
+func (s *service) LoadTest(ctx context.Context, request *loadtest.Request) (*sloadtest.Response, error) {
+	request.Init(s.config)
+	if request.SourceURL == "" {
+		return nil, errors.New("source URL was empty")
+	}
+	files := &sloadtest.Files{Count: request.FileCount}
+	if request.FileSize != "" {
+		size, err := base.ParseSize(request.FileSize, 1)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid size: %v", request.FileSize)
+		}
+		files.Size = base.Bytes(size)
+	}
+	cfg, err := s.loadConfig(ctx, request.Options)
+	if err != nil {
+		return nil, err
+	}
+	mirrorService, err := smirror.New(ctx, cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create mirror service")
+	}
+	response := sloadtest.New(mirrorService).Run(ctx, &sloadtest.Request{
+		SourceURL:   request.SourceURL,
+		Files:       []*sloadtest.Files{files},
+		Rate:        request.Rate,
+		Parallelism: request.Parallelism,
+		Cleanup:     request.Cleanup,
+		ReportURL:   request.ReportURL,
+	})
+	if response.Error != "" {
+		return response, errors.New(response.Error)
+	}
+	return response, nil
+}
@@ -0,0 +1,8 @@
+package loadtest
+
+import "github.com/viant/smirror/cmd/option"
+
+//Request represents load test request
+type Request struct {
+	*option.Options
+}
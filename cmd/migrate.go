@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror"
+	"github.com/viant/smirror/cmd/migrate"
+	smigrate "github.com/viant/smirror/migrate"
+)
+
+//Migrate performs bulk initial copy of source prefix with config rules
+func (s *service) Migrate(ctx context.Context, request *migrate.Request) (*smigrate.Response, error) {
+	request.Init(s.config)
+	if request.SourceURL == "" {
+		return nil, errors.New("source URL was empty")
+	}
+	cfg, err := s.loadConfig(ctx, request.Options)
+	if err != nil {
+		return nil, err
+	}
+	mirrorService, err := smirror.New(ctx, cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create mirror service")
+	}
+	response := smigrate.New(mirrorService).Migrate(ctx, &smigrate.Request{
+		SourceURL:     request.SourceURL,
+		CheckpointURL: request.CheckpointURL,
+		Parallelism:   request.Parallelism,
+		BytesPerSec:   request.BytesPerSec,
+		ReportURL:     request.ReportURL,
+	})
+	if response.Error != "" {
+		return response, errors.New(response.Error)
+	}
+	return response, nil
+}
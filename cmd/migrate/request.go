@@ -0,0 +1,8 @@
+package migrate
+
+import "github.com/viant/smirror/cmd/option"
+
+//Request represents migrate request
+type Request struct {
+	*option.Options
+}
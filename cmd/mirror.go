@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/pkg/errors"
 	"github.com/viant/afs/storage"
+	"github.com/viant/smirror"
 	"github.com/viant/smirror/base"
 	"github.com/viant/smirror/cmd/history"
 	"github.com/viant/smirror/cmd/mirror"
@@ -54,6 +55,27 @@ func (s *service) Mirror(ctx context.Context, request *mirror.Request) (*mirror.
 	return response, err
 }
 
+//MirrorURL runs one-off mirror of source URL against rules loaded from config URL
+func (s *service) MirrorURL(ctx context.Context, request *mirror.Request) (*contract.Response, error) {
+	request.Init(s.config)
+	if request.SourceURL == "" {
+		return nil, errors.New("sourceURL was empty")
+	}
+	cfg, err := smirror.NewConfigFromURL(ctx, request.ConfigURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load config: %v", request.ConfigURL)
+	}
+	mirrorService, err := smirror.New(ctx, cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create mirror service")
+	}
+	response := mirrorService.Mirror(ctx, contract.NewRequest(request.SourceURL))
+	if response.Error != "" {
+		return response, errors.New(response.Error)
+	}
+	return response, nil
+}
+
 func (s *service) loadDatafiles(waitGroup *sync.WaitGroup, ctx context.Context, object storage.Object, rule *config.Rule, request *mirror.Request, response *mirror.Response) {
 	waitGroup.Add(1)
 	go s.scanFiles(ctx, waitGroup, object, request, response)
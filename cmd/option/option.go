@@ -44,6 +44,29 @@ type Options struct {
 	OverflowMb    int64  `short:"x" long:"overflowSize" description:"overflow size in Mb"`
 	OverflowDest  string `short:"O" long:"overflowDest" description:"overflow destination"`
 	OverflowQueue string `short:"Q" long:"overflowQueue" description:"overflow queue"`
+
+	ConfigURL string `short:"c" long:"config" description:"smirror config URL, or cron config URL for ls command"`
+
+	UnprocessedAge string `short:"a" long:"age" description:"replay unprocessed file age i.e. 1hour"`
+
+	ResponseURL string `long:"responses" description:"replay source files of failed responses stored under response log URL"`
+
+	CheckpointURL string `long:"checkpoint" description:"migrate resumable checkpoint URL"`
+
+	Parallelism int `long:"parallel" description:"migrate or loadtest concurrent transfers"`
+
+	BytesPerSec int64 `long:"bandwidth" description:"migrate max source bytes per second"`
+
+	ReportURL string `long:"report" description:"migrate reconciliation report URL, or loadtest report URL"`
+
+	FileCount int     `long:"files" description:"loadtest number of synthetic files"`
+	FileSize  string  `long:"size" description:"loadtest synthetic file size i.e. 64KB"`
+	Rate      float64 `long:"rate" description:"loadtest target mirror calls per second"`
+	Cleanup   bool    `long:"cleanup" description:"loadtest removes synthetic files once completed"`
+
+	Command struct {
+		Name string `positional-arg-name:"command" description:"mirror|validate|replay|ls|rules|export|migrate|loadtest"`
+	} `positional-args:"yes"`
 }
 
 //HistoryPathURL return history URL
@@ -68,6 +91,18 @@ func (r *Options) Init(config *smirror.Config) {
 	if r.HistoryURL != "" {
 		r.HistoryURL = normalizeLocation(r.HistoryURL)
 	}
+	if r.ConfigURL != "" {
+		r.ConfigURL = normalizeLocation(r.ConfigURL)
+	}
+	if r.CheckpointURL != "" {
+		r.CheckpointURL = normalizeLocation(r.CheckpointURL)
+	}
+	if r.ReportURL != "" {
+		r.ReportURL = normalizeLocation(r.ReportURL)
+	}
+	if r.ResponseURL != "" {
+		r.ResponseURL = normalizeLocation(r.ResponseURL)
+	}
 	r.initHistoryURL()
 }
 
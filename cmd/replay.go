@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/cmd/replay"
+	sreplay "github.com/viant/smirror/replay"
+)
+
+//Replay replays unprocessed trigger files older than specified age, or source files of failed responses
+func (s *service) Replay(ctx context.Context, request *replay.Request) (*sreplay.Response, error) {
+	request.Init(s.config)
+	if request.SourceURL == "" && request.ResponseURL == "" {
+		return nil, errors.New("trigger (source) URL and responses URL were empty")
+	}
+	response := sreplay.New().Replay(ctx, &sreplay.Request{
+		TriggerURL:          request.SourceURL,
+		UnprocessedDuration: request.UnprocessedAge,
+		ResponseURL:         request.ResponseURL,
+	})
+	if response.Error != "" {
+		return response, errors.New(response.Error)
+	}
+	return response, nil
+}
@@ -0,0 +1,8 @@
+package replay
+
+import "github.com/viant/smirror/cmd/option"
+
+//Request represents replay request
+type Request struct {
+	*option.Options
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror"
+	"github.com/viant/smirror/cmd/option"
+	"github.com/viant/smirror/cmd/rules"
+	"github.com/viant/smirror/shared"
+)
+
+//Rules prints effective rules loaded from config or rule URL location
+func (s *service) Rules(ctx context.Context, request *rules.Request) error {
+	request.Init(s.config)
+	cfg, err := s.loadConfig(ctx, request.Options)
+	if err != nil {
+		return err
+	}
+	for _, rule := range cfg.Mirrors.Snapshot() {
+		s.reportRule(rule)
+	}
+	shared.LogF("Effective rules: %v\n", len(cfg.Mirrors.Snapshot()))
+	return nil
+}
+
+//loadConfig loads effective config from config or rule URL location
+func (s *service) loadConfig(ctx context.Context, options *option.Options) (*smirror.Config, error) {
+	var cfg *smirror.Config
+	var err error
+	switch {
+	case options.ConfigURL != "":
+		if cfg, err = smirror.NewConfigFromURL(ctx, options.ConfigURL); err != nil {
+			return nil, errors.Wrapf(err, "failed to load config: %v", options.ConfigURL)
+		}
+	case options.RuleURL != "":
+		if cfg, err = newConfig(ctx, s.config.ProjectID); err != nil {
+			return nil, errors.Wrap(err, "failed to create config for rules")
+		}
+		cfg.Mirrors.BaseURL, _ = url.Split(options.RuleURL, file.Scheme)
+		if err = cfg.Init(ctx, s.fs); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("both configURL and ruleURL were empty")
+	}
+	return cfg, nil
+}
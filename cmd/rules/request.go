@@ -0,0 +1,8 @@
+package rules
+
+import "github.com/viant/smirror/cmd/option"
+
+//Request represents rules request
+type Request struct {
+	*option.Options
+}
@@ -5,9 +5,19 @@ import (
 	"github.com/pkg/errors"
 	"github.com/viant/smirror"
 	"github.com/viant/smirror/cmd/build"
+	"github.com/viant/smirror/cmd/export"
+	"github.com/viant/smirror/cmd/list"
+	"github.com/viant/smirror/cmd/loadtest"
+	"github.com/viant/smirror/cmd/migrate"
 	"github.com/viant/smirror/cmd/mirror"
+	"github.com/viant/smirror/cmd/replay"
+	"github.com/viant/smirror/cmd/rules"
 	"github.com/viant/smirror/cmd/validate"
 	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/cron"
+	sloadtest "github.com/viant/smirror/loadtest"
+	smigrate "github.com/viant/smirror/migrate"
+	sreplay "github.com/viant/smirror/replay"
 	"github.com/viant/afs"
 	"sync/atomic"
 )
@@ -20,6 +30,20 @@ type Service interface {
 	Validate(ctx context.Context, request *validate.Request) error
 	//Load start load process for specified source and rule
 	Mirror(ctx context.Context, request *mirror.Request) (*mirror.Response, error)
+	//MirrorURL mirrors source URL against rules loaded from config URL
+	MirrorURL(ctx context.Context, request *mirror.Request) (*contract.Response, error)
+	//Rules prints effective rules
+	Rules(ctx context.Context, request *rules.Request) error
+	//Replay replays unprocessed trigger files
+	Replay(ctx context.Context, request *replay.Request) (*sreplay.Response, error)
+	//List lists pending cron candidates
+	List(ctx context.Context, request *list.Request) (*cron.Response, error)
+	//Export renders effective config with stable hash
+	Export(ctx context.Context, request *export.Request) (*smirror.ConfigExport, error)
+	//Migrate performs bulk initial copy of source prefix
+	Migrate(ctx context.Context, request *migrate.Request) (*smigrate.Response, error)
+	//LoadTest generates synthetic source files and reports mirror pipeline throughput and latency percentiles
+	LoadTest(ctx context.Context, request *loadtest.Request) (*sloadtest.Response, error)
 	//Stop stop service
 	Stop()
 }
@@ -23,8 +23,8 @@ func (s *service) Validate(ctx context.Context, request *validate.Request) error
 	}
 	cfg.Mirrors.BaseURL = parent
 	err = cfg.Init(ctx, s.fs)
-	if err == nil && len(cfg.Mirrors.Rules) > 0 {
-		s.reportRule(cfg.Mirrors.Rules[0])
+	if rules := cfg.Mirrors.Snapshot(); err == nil && len(rules) > 0 {
+		s.reportRule(rules[0])
 		shared.LogLn("Rule is VALID\n")
 	}
 
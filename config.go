@@ -7,9 +7,11 @@ import (
 	"github.com/viant/smirror/auth"
 	"github.com/viant/smirror/base"
 	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/throttle"
 	"github.com/viant/afs"
 	"github.com/viant/afs/cache"
 	"github.com/viant/toolbox"
+	"log"
 	"os"
 	"strings"
 )
@@ -26,6 +28,44 @@ type Config struct {
 	Mirrors          config.Ruleset
 	Streaming        config.Streaming
 	ResponseURL      string
+	//RateLimit destination provider API rate limit
+	RateLimit throttle.Config
+	//EvaluationTrace records per rule match evaluation in response
+	EvaluationTrace bool
+	//Tenants independent team config roots routed by event bucket/prefix
+	Tenants []*Tenant `json:",omitempty"`
+	//SecretScopes secret URL or parameter prefixes rules can use
+	SecretScopes []string `json:",omitempty"`
+	//ImpersonationScopes service accounts and federation AWS role ARNs rules can impersonate
+	ImpersonationScopes []string `json:",omitempty"`
+	//Labels global labels, rule labels take precedence
+	Labels map[string]string `json:",omitempty"`
+	//Poison dead-letters source objects that repeatedly fail
+	Poison *config.Poison `json:",omitempty"`
+	//Lanes priority lanes concurrent transfer budgets
+	Lanes *config.Lanes `json:",omitempty"`
+	//Policy dest allow/deny guardrails, checked when rules are loaded and before each transfer
+	Policy *config.Policy `json:",omitempty"`
+	//Pause global and per rule ingestion pause control
+	Pause *config.Pause `json:",omitempty"`
+	//Perf records per invocation runtime memory and allocation telemetry in response
+	Perf *config.Perf `json:",omitempty"`
+	//Connections shared HTTP transport idle connection tuning
+	Connections config.Connections
+	//Webhooks partner push notification triggers
+	Webhooks []*config.Webhook `json:",omitempty"`
+	//AS2 AS2 receiver
+	AS2 *config.AS2 `json:",omitempty"`
+	//PipelineVersion build version stamped on dest objects, default PIPELINE_VERSION env variable
+	PipelineVersion string `json:",omitempty"`
+	//EventLog append-only transfer lifecycle event log
+	EventLog *config.EventLog `json:",omitempty"`
+	//Metrics push based StatsD/DogStatsD metrics emitter
+	Metrics *config.Metrics `json:",omitempty"`
+	//Monitoring Cloud Monitoring custom metrics export
+	Monitoring *config.Monitoring `json:",omitempty"`
+	//Chaos test only fault injection, requires CHAOS_MODE=true env variable
+	Chaos *config.Chaos `json:",omitempty"`
 }
 
 //Load initialises routes
@@ -39,16 +79,116 @@ func (c *Config) Init(ctx context.Context, fs afs.Service) (err error) {
 		c.MaxRetries = maxRetries
 	}
 	c.Streaming.Init()
+	c.RateLimit.Init()
+	c.Connections.Init()
+	if c.PipelineVersion == "" {
+		c.PipelineVersion = os.Getenv(base.PipelineVersionEnvKey)
+	}
+	for _, webhook := range c.Webhooks {
+		webhook.Init()
+		if err = webhook.Validate(); err != nil {
+			return err
+		}
+		webhook.Source.Init(c.ProjectID)
+	}
+	if c.EventLog != nil {
+		if err = c.EventLog.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Metrics != nil {
+		c.Metrics.Init()
+		if err = c.Metrics.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Monitoring != nil {
+		c.Monitoring.Init(c.ProjectID, c.Region)
+		if err = c.Monitoring.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Chaos != nil {
+		if err = c.Chaos.Validate(); err != nil {
+			return err
+		}
+		if !c.Chaos.Enabled() {
+			log.Printf("chaos config was ignored, %v env variable was not true", base.ChaosEnvKey)
+			c.Chaos = nil
+		}
+	}
+	if c.AS2 != nil {
+		c.AS2.Init(c.ProjectID)
+		if err = c.AS2.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Poison != nil {
+		c.Poison.Init()
+		if err = c.Poison.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Pause != nil {
+		c.Pause.Init()
+		if err = c.Pause.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Perf != nil {
+		c.Perf.Init()
+	}
+	if c.Lanes != nil {
+		if err = c.Lanes.Validate(); err != nil {
+			return err
+		}
+	}
 	if err = c.Mirrors.Init(ctx, fs); err != nil {
 		return err
 	}
-	return c.Mirrors.Validate()
+	if err = c.validateTenants(); err != nil {
+		return err
+	}
+	if err = c.Mirrors.Validate(); err != nil {
+		return err
+	}
+	return c.checkPolicy()
+}
+
+//checkPolicy checks if rules dest are permitted by global policy
+func (c *Config) checkPolicy() error {
+	if c.Policy == nil {
+		return nil
+	}
+	if err := c.Policy.Init(); err != nil {
+		return errors.Wrap(err, "invalid policy")
+	}
+	for _, rule := range c.Mirrors.Snapshot() {
+		if err := c.Policy.CheckRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateTenants() error {
+	names := map[string]bool{}
+	for _, tenant := range c.Tenants {
+		if err := tenant.Validate(); err != nil {
+			return err
+		}
+		if names[tenant.Name] {
+			return errors.Errorf("duplicate tenant: %v", tenant.Name)
+		}
+		names[tenant.Name] = true
+	}
+	return nil
 }
 
 //UseMessageDest returns true if any routes uses message bus
 func (c *Config) UseMessageDest() bool {
-	for _, resource := range c.Mirrors.Rules {
-		if resource.Dest.Topic != "" || resource.Dest.Queue != "" {
+	for _, resource := range c.Mirrors.Snapshot() {
+		if resource.Dest.Azure == nil && (resource.Dest.Topic != "" || resource.Dest.Queue != "") {
 			return true
 		}
 	}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/job"
+	"time"
+)
+
+const (
+	//AlertSent failure alert was sent
+	AlertSent = "sent"
+	//AlertSuppressed duplicate failure alert was suppressed
+	AlertSuppressed = "suppressed"
+	//AlertEscalated escalation threshold was reached
+	AlertEscalated = "escalated"
+	//AlertRecovered transfer succeeded after failure alert
+	AlertRecovered = "recovered"
+
+	defaultAlertWindow = time.Hour
+)
+
+//Alerting represents rule failure notification noise control: duplicate notify actions for the same rule and error code
+//are suppressed within window, escalation actions run after threshold and recovered actions run with the next successful transfer
+type Alerting struct {
+	//StateURL base URL storing per rule alert state
+	StateURL string
+	//Window duplicate alert suppression window, number of seconds or duration text (i.e. "30m"), default 1h
+	Window base.Seconds `json:",omitempty"`
+	//EscalateAfter number of failures with the same error code within window after which OnEscalate actions run once
+	EscalateAfter int `json:",omitempty"`
+	//OnEscalate escalation actions, default failure notify actions with escalated title
+	OnEscalate []*job.Action `json:",omitempty"`
+	//OnRecovered actions run with the first successful transfer after alert, default failure notify actions with recovered title
+	OnRecovered []*job.Action `json:",omitempty"`
+}
+
+//Alert represents rule error code alert state
+type Alert struct {
+	//Alerted time of the last not suppressed alert
+	Alerted    time.Time
+	Count      int
+	Suppressed int  `json:",omitempty"`
+	Escalated  bool `json:",omitempty"`
+	LastError  string
+	Updated    time.Time
+}
+
+//Validate checks if alerting settings are valid
+func (a *Alerting) Validate() error {
+	if a.StateURL == "" {
+		return fmt.Errorf("StateURL was empty")
+	}
+	if a.Window < 0 || a.EscalateAfter < 0 {
+		return fmt.Errorf("Window and EscalateAfter can not be negative")
+	}
+	return nil
+}
+
+//WindowDuration returns duplicate alert suppression window
+func (a *Alerting) WindowDuration() time.Duration {
+	if a.Window > 0 {
+		return a.Window.Duration()
+	}
+	return defaultAlertWindow
+}
+
+//AlertURL returns rule alert state URL
+func (a *Alerting) AlertURL(rule string) string {
+	return url.Join(a.StateURL, rule+".json")
+}
+
+//IsSuppressed returns true if alert was sent within window
+func (a *Alerting) IsSuppressed(alert *Alert, now time.Time) bool {
+	return alert != nil && now.Sub(alert.Alerted) < a.WindowDuration()
+}
+
+//IsEscalated returns true if alert failure count reached escalation threshold for the first time
+func (a *Alerting) IsEscalated(alert *Alert) bool {
+	return a.EscalateAfter > 0 && !alert.Escalated && alert.Count >= a.EscalateAfter
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAppendPrefix    = "append"
+	defaultAppendMaxSizeMb = 128
+	defaultAppendMaxAge    = 3600
+	//defaultAppendMaxParts stays below GCS composite object 1024 components limit
+	defaultAppendMaxParts = 1000
+	appendTimeLayout      = "20060102-150405.000000"
+)
+
+//Append represents append dest mode, incoming files are appended to a rolling dest object rotated by size, age or parts
+type Append struct {
+	//Prefix rolling object name prefix, default append
+	Prefix string `json:",omitempty"`
+	//MaxSizeMb rolling object rotation size, default 128
+	MaxSizeMb base.Megabytes `json:",omitempty"`
+	//MaxAge rolling object rotation age, number of seconds or duration text, default 1h
+	MaxAge base.Seconds `json:",omitempty"`
+	//MaxParts max number of appended files per rolling object, default 1000
+	MaxParts int `json:",omitempty"`
+	//Delimiter appended after file content that does not end with it, i.e. "\n"
+	Delimiter string `json:",omitempty"`
+}
+
+//AppendState represents current rolling object state, stored next to rolling objects
+type AppendState struct {
+	URL     string
+	Created time.Time
+	Size    int64
+	Parts   int
+}
+
+//Init initialises append settings
+func (a *Append) Init() {
+	if a.Prefix == "" {
+		a.Prefix = defaultAppendPrefix
+	}
+	if a.MaxSizeMb == 0 {
+		a.MaxSizeMb = defaultAppendMaxSizeMb
+	}
+	if a.MaxAge == 0 {
+		a.MaxAge = defaultAppendMaxAge
+	}
+	if a.MaxParts == 0 {
+		a.MaxParts = defaultAppendMaxParts
+	}
+}
+
+//Validate initialises and checks if append settings are valid for supplied dest
+func (a *Append) Validate(resource *Resource) error {
+	a.Init()
+	if a.MaxSizeMb < 0 || a.MaxAge < 0 || a.MaxParts < 0 {
+		return fmt.Errorf("invalid append rotation: MaxSizeMb: %v, MaxAge: %v, MaxParts: %v", a.MaxSizeMb, a.MaxAge, a.MaxParts)
+	}
+	if strings.Contains(a.Prefix, "/") {
+		return fmt.Errorf("invalid append.Prefix: %v", a.Prefix)
+	}
+	if !resource.IsStorage() || resource.Topic != "" || resource.Queue != "" {
+		return fmt.Errorf("append is only supported with storage dest, but had: %v", resource.URL)
+	}
+	if resource.Retention != nil || resource.ContentAddressed != nil {
+		return fmt.Errorf("append can not be used with retention or contentAddressed")
+	}
+	return nil
+}
+
+//StateURL returns rolling object state URL for supplied dest URL, rolling objects are written to dest URL parent
+func (a *Append) StateURL(destURL string) string {
+	parent, name := url.Split(destURL, file.Scheme)
+	return url.Join(parent, "_"+a.Prefix+appendExt(name)+".json")
+}
+
+//ShallRotate returns true if data can not be appended to current rolling object
+func (a *Append) ShallRotate(state *AppendState, size int, now time.Time) bool {
+	if state == nil || state.URL == "" {
+		return true
+	}
+	if state.Parts >= a.MaxParts || now.Sub(state.Created) >= a.MaxAge.Duration() {
+		return true
+	}
+	return state.Size > 0 && state.Size+int64(size) > int64(a.MaxSizeMb)*1024*1024
+}
+
+//NewState returns new rolling object state for supplied dest URL
+func (a *Append) NewState(destURL string, now time.Time) *AppendState {
+	parent, name := url.Split(destURL, file.Scheme)
+	return &AppendState{
+		URL:     url.Join(parent, a.Prefix+"-"+now.UTC().Format(appendTimeLayout)+appendExt(name)),
+		Created: now,
+	}
+}
+
+//appendExt returns dest name extension including compression, i.e. .log.gz
+func appendExt(name string) string {
+	ext := path.Ext(name)
+	if ext == ".gz" {
+		ext = path.Ext(strings.TrimSuffix(name, ext)) + ext
+	}
+	return ext
+}
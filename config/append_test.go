@@ -0,0 +1,33 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAppend_ShallRotate(t *testing.T) {
+	now := time.Now()
+	var useCases = []struct {
+		description string
+		state       *AppendState
+		size        int
+		expect      bool
+	}{
+		{description: "no rolling object", expect: true},
+		{description: "append", state: &AppendState{URL: "mem://localhost/a.log", Created: now, Size: 10, Parts: 1}, size: 10},
+		{description: "max parts", state: &AppendState{URL: "mem://localhost/a.log", Created: now, Parts: 3}, expect: true},
+		{description: "max age", state: &AppendState{URL: "mem://localhost/a.log", Created: now.Add(-2 * time.Hour), Parts: 1}, expect: true},
+		{description: "max size", state: &AppendState{URL: "mem://localhost/a.log", Created: now, Size: 1024 * 1024, Parts: 1}, size: 1, expect: true},
+		{description: "oversized first part", state: &AppendState{URL: "mem://localhost/a.log", Created: now}, size: 2 * 1024 * 1024},
+	}
+	for _, useCase := range useCases {
+		layout := &Append{MaxSizeMb: 1, MaxParts: 3}
+		assert.Nil(t, layout.Validate(&Resource{URL: "mem://localhost/dest"}), useCase.description)
+		assert.Equal(t, useCase.expect, layout.ShallRotate(useCase.state, useCase.size, now), useCase.description)
+	}
+	layout := &Append{}
+	layout.Init()
+	assert.Equal(t, "gs://logs/app/_append.log.gz.json", layout.StateURL("gs://logs/app/f1.log.gz"))
+	assert.Equal(t, "gs://logs/app/append-20261015-101500.000000.log.gz", layout.NewState("gs://logs/app/f1.log.gz", time.Date(2026, 10, 15, 10, 15, 0, 0, time.UTC)).URL)
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/smirror/auth"
+)
+
+const defaultAS2MaxPayloadMb = 256
+
+//AS2 represents AS2 receiver, received payloads are written to landing resource and mirrored with matching rule
+type AS2 struct {
+	//ID local AS2 identifier, inbound message AS2-To has to match it
+	ID string
+	//Certificate encrypted PEM with local certificate and RSA private key used to sign MDN, without it MDN is unsigned
+	Certificate *auth.Secret `json:",omitempty"`
+	//Landing payload landing base URL and credentials, payload is written to Landing.URL/partner prefix/file name
+	Landing Resource
+	//MaxPayloadMb max inbound message size, default 256
+	MaxPayloadMb int `json:",omitempty"`
+	Partners     []*AS2Partner
+}
+
+//AS2Partner represents trading partner
+type AS2Partner struct {
+	//ID partner AS2 identifier (AS2-From)
+	ID string
+	//CertificateURL partner public certificate (PEM or DER) used to verify signed messages, required unless AllowUnsigned
+	CertificateURL string `json:",omitempty"`
+	//AllowUnsigned accepts unsigned messages, by default they are rejected with insufficient-message-security MDN
+	AllowUnsigned bool `json:",omitempty"`
+	//Prefix landing sub path, default partner ID
+	Prefix string `json:",omitempty"`
+}
+
+//Init initialises AS2 defaults
+func (a *AS2) Init(projectID string) {
+	if a.MaxPayloadMb == 0 {
+		a.MaxPayloadMb = defaultAS2MaxPayloadMb
+	}
+	a.Landing.Init(projectID)
+	for _, partner := range a.Partners {
+		if partner.Prefix == "" {
+			partner.Prefix = partner.ID
+		}
+	}
+}
+
+//Validate checks if AS2 receiver is valid
+func (a *AS2) Validate() error {
+	if a.ID == "" {
+		return fmt.Errorf("as2.ID was empty")
+	}
+	if a.Landing.URL == "" {
+		return fmt.Errorf("as2.Landing.URL was empty")
+	}
+	for _, partner := range a.Partners {
+		if partner.ID == "" {
+			return fmt.Errorf("as2.Partners.ID was empty")
+		}
+		if !partner.AllowUnsigned && partner.CertificateURL == "" {
+			return fmt.Errorf("as2 partner %v CertificateURL was empty, required unless AllowUnsigned", partner.ID)
+		}
+	}
+	return nil
+}
+
+//Partner returns partner for AS2 identifier
+func (a *AS2) Partner(ID string) *AS2Partner {
+	for _, candidate := range a.Partners {
+		if candidate.ID == ID {
+			return candidate
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/shared"
+	"strings"
+)
+
+//Azure represents Azure Event Grid topic or Service Bus queue/topic destination, resource Vendor selects eventgrid or servicebus
+type Azure struct {
+	//Namespace Service Bus namespace host, i.e. mynamespace.servicebus.windows.net, or base URL, i.e. emulator http://localhost:5300
+	Namespace string `json:",omitempty"`
+	//EventType Event Grid event type, default smirror.transfer
+	EventType string `json:",omitempty"`
+	//Template optional payload template, $Data, $SourceURL, $DestURL, $TransferID and $Partition are expanded
+	Template string `json:",omitempty"`
+	//BatchSize max messages per publish request, split output records are published as individual messages, default 100
+	BatchSize int `json:",omitempty"`
+}
+
+//Init initialises defaults
+func (a *Azure) Init() {
+	if a.BatchSize == 0 {
+		a.BatchSize = 100
+	}
+}
+
+//Validate checks if azure destination is valid
+func (a *Azure) Validate(resource *Resource) error {
+	if resource.Credentials == nil {
+		return errors.New("azure credentials were empty")
+	}
+	switch resource.Vendor {
+	case shared.VendorEventGrid:
+		if !strings.Contains(resource.Topic, "://") {
+			return errors.Errorf("invalid event grid topic endpoint: %v, expected https://<topic>.<region>.eventgrid.azure.net/api/events", resource.Topic)
+		}
+	case shared.VendorServiceBus:
+		if a.Namespace == "" {
+			return errors.New("azure.Namespace was empty")
+		}
+		if resource.Topic == "" && resource.Queue == "" {
+			return errors.New("service bus Topic or Queue was empty")
+		}
+	default:
+		return errors.Errorf("unsupported azure vendor: '%v', expected %v or %v", resource.Vendor, shared.VendorEventGrid, shared.VendorServiceBus)
+	}
+	if a.BatchSize < 0 {
+		return errors.Errorf("invalid azure.BatchSize: %v", a.BatchSize)
+	}
+	return nil
+}
+
+//Endpoint returns Event Grid topic endpoint or Service Bus entity messages URL
+func (a *Azure) Endpoint(vendor, dest string) string {
+	if vendor == shared.VendorEventGrid {
+		return dest
+	}
+	baseURL := strings.TrimRight(a.Namespace, "/")
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "https://" + baseURL
+	}
+	return baseURL + "/" + strings.Trim(dest, "/") + "/messages"
+}
+
+//Payload returns templated payload
+func (a *Azure) Payload(data []byte, sourceURL, destURL, transferID, partition string) []byte {
+	if a.Template == "" {
+		return data
+	}
+	replacer := strings.NewReplacer("$Data", string(data), "$SourceURL", sourceURL, "$DestURL", destURL, "$TransferID", transferID, "$Partition", partition)
+	return []byte(replacer.Replace(a.Template))
+}
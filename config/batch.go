@@ -0,0 +1,41 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultBatchSize        = 100
+	defaultBatchParallelism = 16
+	//maxBatchSize s3 DeleteObjects key limit
+	maxBatchSize = 1000
+)
+
+//Batch represents bulk move settings, objects are copied server side in parallel, then sources are deleted in batches
+type Batch struct {
+	//Size number of sources deleted per batch, s3 uses single DeleteObjects request per batch, default 100, max 1000
+	Size int `json:",omitempty"`
+	//Parallelism max concurrent server side copies and deletes, default 16
+	Parallelism int `json:",omitempty"`
+}
+
+//Init initialises defaults
+func (b *Batch) Init() {
+	if b.Size == 0 {
+		b.Size = defaultBatchSize
+	}
+	if b.Parallelism == 0 {
+		b.Parallelism = defaultBatchParallelism
+	}
+}
+
+//Validate checks if batch is valid
+func (b *Batch) Validate() error {
+	if b.Size < 0 || b.Size > maxBatchSize {
+		return errors.Errorf("invalid batch.Size: %v, expected 1-%v", b.Size, maxBatchSize)
+	}
+	if b.Parallelism < 0 {
+		return errors.Errorf("invalid batch.Parallelism: %v", b.Parallelism)
+	}
+	return nil
+}
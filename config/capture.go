@@ -0,0 +1,69 @@
+package config
+
+import (
+	"github.com/viant/afs/url"
+	"github.com/viant/toolbox/data"
+	"strings"
+)
+
+//capturesKey captures state key, i.e. ${Captures.partner}
+const capturesKey = "Captures"
+
+//Captures returns source filter named groups matched with URL path, i.e. partner for (?P<partner>[^/]+), nil if filter has no named group,
+//filter is compiled by Validate
+func (r *Resource) Captures(URL string) map[string]string {
+	if r.Filter == "" {
+		return nil
+	}
+	expr := compiled(r.filterExpr, r.Filter)
+	if expr == nil {
+		return nil
+	}
+	names := expr.SubexpNames()
+	matched := expr.FindStringSubmatch(url.Path(URL))
+	var result map[string]string
+	for i := 1; i < len(matched); i++ {
+		if names[i] == "" {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string)
+		}
+		result[names[i]] = matched[i]
+	}
+	return result
+}
+
+//CaptureLabels returns rule labels with expanded source captures, i.e. {"partner": "$partner"}
+func (r *Rule) CaptureLabels(captures map[string]string) map[string]string {
+	if len(r.Labels) == 0 || len(captures) == 0 {
+		return r.Labels
+	}
+	var result = make(map[string]string, len(r.Labels))
+	for key, value := range r.Labels {
+		result[key] = ExpandCaptures(value, captures)
+	}
+	return result
+}
+
+//ExpandCaptures expands $name and ${Captures.name} source captures in text
+func ExpandCaptures(text string, captures map[string]string) string {
+	if len(captures) == 0 || !strings.Contains(text, "$") {
+		return text
+	}
+	state := data.NewMap()
+	putCaptures(state, captures)
+	return state.ExpandAsText(text)
+}
+
+func putCaptures(state data.Map, captures map[string]string) {
+	if len(captures) == 0 {
+		return
+	}
+	var values = make(map[string]interface{}, len(captures))
+	for key, value := range captures {
+		state.Put(key, value)
+		values[key] = value
+	}
+	state.Put(capturesKey, values)
+}
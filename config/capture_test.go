@@ -0,0 +1,70 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs/matcher"
+	"sync"
+	"testing"
+)
+
+func TestResource_Captures(t *testing.T) {
+	var useCases = []struct {
+		description string
+		filter      string
+		URL         string
+		expect      map[string]string
+	}{
+		{
+			description: "named groups",
+			filter:      `^/incoming/(?P<partner>[^/]+)/(?P<date>\d{8})/`,
+			URL:         "gs://bucket/incoming/acme/20240105/data.csv",
+			expect:      map[string]string{"partner": "acme", "date": "20240105"},
+		},
+		{
+			description: "unnamed groups",
+			filter:      `^/incoming/([^/]+)/`,
+			URL:         "gs://bucket/incoming/acme/data.csv",
+		},
+		{
+			description: "no filter",
+			URL:         "gs://bucket/incoming/acme/data.csv",
+		},
+		{
+			description: "no match",
+			filter:      `^/incoming/(?P<partner>[^/]+)/`,
+			URL:         "gs://bucket/outgoing/acme/data.csv",
+		},
+	}
+	for _, useCase := range useCases {
+		resource := &Resource{Basic: matcher.Basic{Filter: useCase.filter}}
+		assert.EqualValues(t, useCase.expect, resource.Captures(useCase.URL), useCase.description)
+		assert.Nil(t, resource.filterExpr, useCase.description)
+		if !assert.Nil(t, resource.Validate(), useCase.description) {
+			continue
+		}
+		//validated resource is matched concurrently
+		waitGroup := sync.WaitGroup{}
+		for i := 0; i < 4; i++ {
+			waitGroup.Add(1)
+			go func() {
+				defer waitGroup.Done()
+				assert.EqualValues(t, useCase.expect, resource.Captures(useCase.URL), useCase.description)
+			}()
+		}
+		waitGroup.Wait()
+	}
+}
+
+func TestResource_ExpandURLWithCaptures(t *testing.T) {
+	rule := &Rule{
+		Source: &Resource{Basic: matcher.Basic{Filter: `^/incoming/(?P<partner>[^/]+)/`}},
+		Dest:   &Resource{URL: "s3://dest/partners/$partner/"},
+		Labels: map[string]string{"team": "ingestion", "partner": "${Captures.partner}"},
+	}
+	captures := rule.Source.Captures("gs://bucket/incoming/acme/data.csv")
+	destURL, err := rule.Dest.ExpandURL("gs://bucket/incoming/acme/data.csv", captures)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "s3://dest/partners/acme/", destURL)
+	assert.EqualValues(t, map[string]string{"team": "ingestion", "partner": "acme"}, rule.CaptureLabels(captures))
+	assert.EqualValues(t, "${Captures.partner}", rule.Labels["partner"], "rule labels are not modified")
+}
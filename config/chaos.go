@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/smirror/base"
+	"os"
+	"time"
+)
+
+const (
+	defaultChaosReadDelay  = 100 * time.Millisecond
+	defaultChaosTruncateAt = 1024
+)
+
+//Chaos represents test only fault injection settings, used to verify retries, dead-lettering and alerting,
+//it takes effect only with CHAOS_MODE=true env variable, fault probabilities are in 0..1 range
+type Chaos struct {
+	//UploadFailure dest upload failure probability, injected error is transient
+	UploadFailure float64 `json:",omitempty"`
+	//SecretFailure secret resolution failure probability, injected error is auth
+	SecretFailure float64 `json:",omitempty"`
+	//SlowRead source read slowdown probability per transfer
+	SlowRead float64 `json:",omitempty"`
+	//ReadDelay delay added to each slowed down source read, default 100ms
+	ReadDelay base.Milliseconds `json:",omitempty"`
+	//Truncate source stream truncation probability per transfer, injected error is transient
+	Truncate float64 `json:",omitempty"`
+	//TruncateAt number of bytes read before truncated stream fails, default 1024
+	TruncateAt base.Bytes `json:",omitempty"`
+}
+
+//Enabled returns true if chaos env variable is set
+func (c *Chaos) Enabled() bool {
+	return os.Getenv(base.ChaosEnvKey) == "true"
+}
+
+//Validate checks if chaos settings are valid
+func (c *Chaos) Validate() error {
+	for name, probability := range map[string]float64{"UploadFailure": c.UploadFailure, "SecretFailure": c.SecretFailure, "SlowRead": c.SlowRead, "Truncate": c.Truncate} {
+		if probability < 0 || probability > 1 {
+			return fmt.Errorf("invalid chaos.%v: %v, expected 0..1", name, probability)
+		}
+	}
+	if c.ReadDelay < 0 || c.TruncateAt < 0 {
+		return fmt.Errorf("invalid chaos: ReadDelay and TruncateAt can not be negative")
+	}
+	return nil
+}
+
+//ReadDelayDuration returns slowed down source read delay
+func (c *Chaos) ReadDelayDuration() time.Duration {
+	if c.ReadDelay > 0 {
+		return c.ReadDelay.Duration()
+	}
+	return defaultChaosReadDelay
+}
+
+//TruncateAtBytes returns number of bytes read before truncated stream fails
+func (c *Chaos) TruncateAtBytes() int64 {
+	if c.TruncateAt > 0 {
+		return int64(c.TruncateAt)
+	}
+	return defaultChaosTruncateAt
+}
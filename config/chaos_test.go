@@ -0,0 +1,30 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestChaos_Validate(t *testing.T) {
+	var useCases = []struct {
+		description string
+		chaos       *Chaos
+		hasError    bool
+		expectDelay time.Duration
+	}{
+		{description: "defaults", chaos: &Chaos{UploadFailure: 0.1}, expectDelay: defaultChaosReadDelay},
+		{description: "custom delay", chaos: &Chaos{SlowRead: 1, ReadDelay: 20}, expectDelay: 20 * time.Millisecond},
+		{description: "invalid probability", chaos: &Chaos{Truncate: 1.5}, hasError: true},
+		{description: "negative probability", chaos: &Chaos{SecretFailure: -0.1}, hasError: true},
+	}
+	for _, useCase := range useCases {
+		err := useCase.chaos.Validate()
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+		assert.Equal(t, useCase.expectDelay, useCase.chaos.ReadDelayDuration(), useCase.description)
+	}
+}
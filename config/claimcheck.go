@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/digest"
+	"path"
+)
+
+const (
+	//defaultClaimCheckMaxBytes stays below SQS 256KB message limit
+	defaultClaimCheckMaxBytes = 192 * 1024
+)
+
+//ClaimCheck represents message payload offloading, payload larger than MaxBytes is written to claim-check object
+//and the message carries the object URL and digest instead
+type ClaimCheck struct {
+	//URL claim-check objects base URL
+	URL string
+	//MaxBytes max inlined payload size, number of bytes or size text, default 192KB
+	MaxBytes base.Bytes `json:",omitempty"`
+	//Algorithm payload digest algorithm: sha256 (default), sha512, sha1 or blake3
+	Algorithm string `json:",omitempty"`
+}
+
+//Validate checks if claim check settings are valid for supplied dest
+func (c *ClaimCheck) Validate(resource *Resource) error {
+	if c.URL == "" {
+		return fmt.Errorf("invalid ClaimCheck: URL was empty")
+	}
+	if resource.Topic == "" && resource.Queue == "" && resource.NATS == nil {
+		return fmt.Errorf("invalid ClaimCheck: dest Topic, Queue or NATS was empty")
+	}
+	if c.MaxBytes < 0 {
+		return fmt.Errorf("invalid ClaimCheck: MaxBytes can not be negative")
+	}
+	if c.Algorithm != "" && !digest.IsSupported(c.Algorithm) {
+		return fmt.Errorf("invalid ClaimCheck: unsupported Algorithm: %v", c.Algorithm)
+	}
+	return nil
+}
+
+//IsOffloaded returns true if payload of supplied size is written to claim-check object
+func (c *ClaimCheck) IsOffloaded(size int) bool {
+	maxBytes := int(c.MaxBytes)
+	if maxBytes == 0 {
+		maxBytes = defaultClaimCheckMaxBytes
+	}
+	return size > maxBytes
+}
+
+//DigestAlgorithm returns payload digest algorithm
+func (c *ClaimCheck) DigestAlgorithm() string {
+	if c.Algorithm == "" {
+		return DigestSHA256
+	}
+	return c.Algorithm
+}
+
+//ObjectURL returns claim-check object URL for transfer split part
+func (c *ClaimCheck) ObjectURL(transferID string, split int32, name string) string {
+	return url.Join(c.URL, transferID, fmt.Sprintf("%05d_%v", split, path.Base(name)))
+}
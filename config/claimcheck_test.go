@@ -0,0 +1,31 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/smirror/base"
+	"testing"
+)
+
+func TestClaimCheck_Validate(t *testing.T) {
+	var useCases = []struct {
+		description string
+		resource    *Resource
+		hasError    bool
+	}{
+		{description: "topic", resource: &Resource{Topic: "topic", ClaimCheck: &ClaimCheck{URL: "gs://bucket/claims"}}},
+		{description: "nats", resource: &Resource{NATS: &NATS{}, ClaimCheck: &ClaimCheck{URL: "gs://bucket/claims", Algorithm: DigestSHA512}}},
+		{description: "empty URL", resource: &Resource{Queue: "queue", ClaimCheck: &ClaimCheck{}}, hasError: true},
+		{description: "storage dest", resource: &Resource{URL: "gs://bucket/data", ClaimCheck: &ClaimCheck{URL: "gs://bucket/claims"}}, hasError: true},
+		{description: "unsupported algorithm", resource: &Resource{Topic: "topic", ClaimCheck: &ClaimCheck{URL: "gs://bucket/claims", Algorithm: "crc"}}, hasError: true},
+	}
+	for _, useCase := range useCases {
+		err := useCase.resource.ClaimCheck.Validate(useCase.resource)
+		assert.Equal(t, useCase.hasError, err != nil, useCase.description)
+	}
+}
+
+func TestClaimCheck_IsOffloaded(t *testing.T) {
+	assert.False(t, (&ClaimCheck{}).IsOffloaded(defaultClaimCheckMaxBytes))
+	assert.True(t, (&ClaimCheck{}).IsOffloaded(defaultClaimCheckMaxBytes+1))
+	assert.True(t, (&ClaimCheck{MaxBytes: base.Bytes(10)}).IsOffloaded(11))
+}
@@ -0,0 +1,53 @@
+package config
+
+import "fmt"
+
+const (
+	//ClassificationPublic data that can be shared publicly
+	ClassificationPublic = "public"
+	//ClassificationInternal data restricted to the organization
+	ClassificationInternal = "internal"
+	//ClassificationPII personally identifiable information
+	ClassificationPII = "pii"
+)
+
+var classificationLevels = map[string]int{
+	ClassificationPublic:   1,
+	ClassificationInternal: 2,
+	ClassificationPII:      3,
+}
+
+//IsValidClassification returns true if classification is empty or a known data classification
+func IsValidClassification(classification string) bool {
+	if classification == "" {
+		return true
+	}
+	_, ok := classificationLevels[classification]
+	return ok
+}
+
+//CheckClassification returns policy violation error if dest max classification does not accept rule classification,
+//dest without MaxClassification accepts public data only, unclassified rule is not checked
+func (r *Rule) CheckClassification() error {
+	if r.Classification == "" || r.Dest == nil {
+		return nil
+	}
+	maxClassification := r.Dest.MaxClassification
+	if maxClassification == "" {
+		maxClassification = ClassificationPublic
+	}
+	if classificationLevels[r.Classification] > classificationLevels[maxClassification] {
+		return fmt.Errorf("%v data can not be mirrored to %v dest: %v", r.Classification, maxClassification, destName(r.Dest))
+	}
+	return nil
+}
+
+func destName(resource *Resource) string {
+	switch {
+	case resource.URL != "":
+		return resource.URL
+	case resource.Topic != "":
+		return resource.Topic
+	}
+	return resource.Queue
+}
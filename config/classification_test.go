@@ -0,0 +1,52 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRule_CheckClassification(t *testing.T) {
+	var useCases = []struct {
+		description       string
+		classification    string
+		maxClassification string
+		expectError       bool
+	}{
+		{
+			description: "unclassified rule",
+		},
+		{
+			description:    "public data to undeclared dest",
+			classification: ClassificationPublic,
+		},
+		{
+			description:    "internal data to undeclared dest",
+			classification: ClassificationInternal,
+			expectError:    true,
+		},
+		{
+			description:       "pii data to pii dest",
+			classification:    ClassificationPII,
+			maxClassification: ClassificationPII,
+		},
+		{
+			description:       "pii data to internal dest",
+			classification:    ClassificationPII,
+			maxClassification: ClassificationInternal,
+			expectError:       true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		rule := &Rule{
+			Classification: useCase.classification,
+			Dest:           &Resource{URL: "gs://bucket/data", MaxClassification: useCase.maxClassification},
+		}
+		err := rule.CheckClassification()
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+	}
+}
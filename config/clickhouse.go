@@ -0,0 +1,100 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"github.com/viant/afs/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	//ClickHouseFormatCSV CSV with header line format
+	ClickHouseFormatCSV = "CSVWithNames"
+	//ClickHouseFormatJSON newline delimited JSON format
+	ClickHouseFormatJSON = "JSONEachRow"
+)
+
+//ClickHouse represents ClickHouse table destination loaded with HTTP interface INSERT, resource URL is HTTP interface URL
+type ClickHouse struct {
+	//Table destination table, optionally database qualified
+	Table string
+	//Format CSVWithNames or JSONEachRow, source extension is used by default
+	Format string `json:",omitempty"`
+	//Delimiter CSV delimiter, comma by default
+	Delimiter string `json:",omitempty"`
+	//MaxInsertBlockSize max_insert_block_size setting, server default is used otherwise
+	MaxInsertBlockSize int `json:",omitempty"`
+	//AsyncInsert enables server side async_insert buffering
+	AsyncInsert bool `json:",omitempty"`
+	//AsyncInsertNoWait returns before async insert buffer is flushed (wait_for_async_insert=0)
+	AsyncInsertNoWait bool `json:",omitempty"`
+	//Settings additional query settings
+	Settings map[string]string `json:",omitempty"`
+}
+
+//Init initialises defaults
+func (c *ClickHouse) Init() {
+	if c.Delimiter == "" {
+		c.Delimiter = ","
+	}
+}
+
+//Validate checks if clickhouse destination is valid
+func (c *ClickHouse) Validate(resource *Resource) error {
+	if !identifier.MatchString(c.Table) {
+		return errors.Errorf("invalid clickhouse.Table: '%v'", c.Table)
+	}
+	if scheme := url.Scheme(resource.URL, ""); scheme != "http" && scheme != "https" {
+		return errors.Errorf("unsupported clickhouse URL: %v, expected http:// or https:// scheme", resource.URL)
+	}
+	switch c.Format {
+	case "", ClickHouseFormatCSV, ClickHouseFormatJSON:
+	default:
+		return errors.Errorf("unsupported clickhouse.Format: %v, expected %v or %v", c.Format, ClickHouseFormatCSV, ClickHouseFormatJSON)
+	}
+	if len([]rune(c.Delimiter)) > 1 {
+		return errors.Errorf("invalid clickhouse.Delimiter: '%v'", c.Delimiter)
+	}
+	if c.MaxInsertBlockSize < 0 {
+		return errors.New("clickhouse.MaxInsertBlockSize can not be negative")
+	}
+	if c.AsyncInsertNoWait && !c.AsyncInsert {
+		return errors.New("clickhouse.AsyncInsertNoWait requires AsyncInsert")
+	}
+	return nil
+}
+
+//InsertFormat returns insert format for source URL
+func (c *ClickHouse) InsertFormat(sourceURL string) string {
+	if c.Format != "" {
+		return c.Format
+	}
+	ext := strings.ToLower(path.Ext(strings.TrimSuffix(strings.TrimSuffix(sourceURL, ".gz"), ".zip")))
+	if ext == ".json" || ext == ".ndjson" || ext == ".jsonl" {
+		return ClickHouseFormatJSON
+	}
+	return ClickHouseFormatCSV
+}
+
+//QuerySettings returns insert query settings
+func (c *ClickHouse) QuerySettings(format string) map[string]string {
+	var result = make(map[string]string)
+	for k, v := range c.Settings {
+		result[k] = v
+	}
+	if format == ClickHouseFormatCSV && c.Delimiter != "," {
+		result["format_csv_delimiter"] = c.Delimiter
+	}
+	if c.MaxInsertBlockSize > 0 {
+		result["max_insert_block_size"] = strconv.Itoa(c.MaxInsertBlockSize)
+	}
+	if c.AsyncInsert {
+		result["async_insert"] = "1"
+		result["wait_for_async_insert"] = "1"
+		if c.AsyncInsertNoWait {
+			result["wait_for_async_insert"] = "0"
+		}
+	}
+	return result
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestClickHouse_QuerySettings(t *testing.T) {
+	var useCases = []struct {
+		description  string
+		ClickHouse
+		sourceURL    string
+		expectFormat string
+		expect       map[string]string
+	}{
+		{
+			description:  "csv defaults",
+			ClickHouse:   ClickHouse{Table: "events", Delimiter: ","},
+			sourceURL:    "gs://bucket/data/events.csv.gz",
+			expectFormat: ClickHouseFormatCSV,
+			expect:       map[string]string{},
+		},
+		{
+			description:  "json with block size and async insert",
+			ClickHouse:   ClickHouse{Table: "events", Delimiter: ",", MaxInsertBlockSize: 100000, AsyncInsert: true, AsyncInsertNoWait: true},
+			sourceURL:    "gs://bucket/data/events.json",
+			expectFormat: ClickHouseFormatJSON,
+			expect:       map[string]string{"max_insert_block_size": "100000", "async_insert": "1", "wait_for_async_insert": "0"},
+		},
+		{
+			description:  "tab delimited",
+			ClickHouse:   ClickHouse{Table: "events", Delimiter: "\t", Settings: map[string]string{"input_format_skip_unknown_fields": "1"}},
+			sourceURL:    "gs://bucket/data/events.tsv",
+			expectFormat: ClickHouseFormatCSV,
+			expect:       map[string]string{"format_csv_delimiter": "\t", "input_format_skip_unknown_fields": "1"},
+		},
+	}
+
+	for _, useCase := range useCases {
+		format := useCase.InsertFormat(useCase.sourceURL)
+		assert.Equal(t, useCase.expectFormat, format, useCase.description)
+		assert.Equal(t, useCase.expect, useCase.QuerySettings(format), useCase.description)
+	}
+}
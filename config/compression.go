@@ -1,6 +1,9 @@
 package config
 
-import "strings"
+import (
+	"bytes"
+	"strings"
+)
 
 const (
 	//GZipCodec gzip code
@@ -22,10 +25,17 @@ const (
 	TarExtension = ".tar"
 )
 
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte{'P', 'K', 0x03, 0x04}
+)
+
 //Compression represents conversion strategy
 type Compression struct {
 	Codec      string `json:",omitempty"`
 	Uncompress bool   `json:",omitempty"`
+	//Detect detects source compression with content magic bytes instead of URL extension
+	Detect bool `json:",omitempty"`
 }
 
 //Equals returns true if compression is the same
@@ -49,3 +59,19 @@ func NewCompressionForURL(URL string) *Compression {
 	}
 	return nil
 }
+
+//DetectCodec returns codec for content header magic bytes or empty string
+func DetectCodec(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return GZipCodec
+	case bytes.HasPrefix(header, zipMagic):
+		return ZipCodec
+	}
+	return ""
+}
+
+//MagicSize returns content header size needed to detect codec
+func MagicSize() int {
+	return len(zipMagic)
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"github.com/viant/smirror/base"
+)
+
+const (
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 32
+	defaultIdleConnTimeout     = 90
+)
+
+//Connections represents shared HTTP transport idle connection settings, connections are reused across warm invocations
+type Connections struct {
+	//MaxIdleConns max idle connections across all hosts, default 100
+	MaxIdleConns int `json:",omitempty"`
+	//MaxIdleConnsPerHost max idle connections per host, default 32 (go default is 2, which forces new TLS handshakes for parallel transfers)
+	MaxIdleConnsPerHost int `json:",omitempty"`
+	//IdleConnTimeout idle connection timeout, default 90s
+	IdleConnTimeout base.Seconds `json:",omitempty"`
+	//Stats records client cache and connection stats in response
+	Stats bool `json:",omitempty"`
+}
+
+//Init initialises connections settings
+func (c *Connections) Init() {
+	if c.MaxIdleConns == 0 {
+		c.MaxIdleConns = defaultMaxIdleConns
+	}
+	if c.MaxIdleConnsPerHost == 0 {
+		c.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	}
+	if c.MaxIdleConns < c.MaxIdleConnsPerHost {
+		c.MaxIdleConns = c.MaxIdleConnsPerHost
+	}
+	if c.IdleConnTimeout == 0 {
+		c.IdleConnTimeout = defaultIdleConnTimeout
+	}
+}
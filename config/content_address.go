@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/digest"
+	"hash"
+	"time"
+)
+
+const (
+	//DigestSHA256 SHA-256 content digest
+	DigestSHA256 = digest.SHA256
+	//DigestSHA512 SHA-512 content digest
+	DigestSHA512 = digest.SHA512
+	//DigestSHA1 SHA-1 content digest
+	DigestSHA1 = digest.SHA1
+	//DigestBLAKE3 BLAKE3 content digest
+	DigestBLAKE3 = digest.BLAKE3
+
+	defaultContentDepth          = 2
+	maxContentDepth              = 4
+	defaultContentManifestSuffix = ".ref.json"
+	contentTempFolder            = "_tmp"
+)
+
+//ContentAddressed represents content-addressed dest layout, uploaded object is named by its content digest
+//(BaseURL/algorithm/ab/cd/digest) and a manifest with the original name points to the content
+type ContentAddressed struct {
+	//BaseURL content store base URL, default dest URL
+	BaseURL string `json:",omitempty"`
+	//Algorithm content digest algorithm: sha256 (default), sha512, sha1 or blake3
+	Algorithm string `json:",omitempty"`
+	//Depth number of two character digest prefix folders, default 2
+	Depth int `json:",omitempty"`
+	//ManifestSuffix suffix appended to the original dest URL for manifest, default .ref.json
+	ManifestSuffix string `json:",omitempty"`
+}
+
+//ContentManifest represents content-addressed object pointer written under the original dest name
+type ContentManifest struct {
+	Name       string
+	SourceURL  string
+	URL        string
+	Algorithm  string
+	Digest     string
+	Size       int64
+	TransferID string `json:",omitempty"`
+	Created    time.Time
+}
+
+//Validate checks if content-addressed layout is valid for supplied dest
+func (c *ContentAddressed) Validate(resource *Resource) error {
+	switch c.Algorithm {
+	case "", DigestSHA256, DigestSHA512, DigestSHA1, DigestBLAKE3:
+	default:
+		return fmt.Errorf("unsupported contentAddressed.Algorithm: %v, expected %v, %v, %v or %v", c.Algorithm, DigestSHA256, DigestSHA512, DigestSHA1, DigestBLAKE3)
+	}
+	if c.Depth < 0 || c.Depth > maxContentDepth {
+		return fmt.Errorf("invalid contentAddressed.Depth: %v, expected 1..%v", c.Depth, maxContentDepth)
+	}
+	if !resource.IsStorage() || resource.Topic != "" || resource.Queue != "" {
+		return fmt.Errorf("contentAddressed is only supported with storage dest, but had: %v", resource.URL)
+	}
+	if resource.Retention != nil {
+		return fmt.Errorf("contentAddressed can not be used with retention, uploaded content is moved to its digest location")
+	}
+	return nil
+}
+
+//DigestAlgorithm returns content digest algorithm
+func (c *ContentAddressed) DigestAlgorithm() string {
+	if c.Algorithm == "" {
+		return DigestSHA256
+	}
+	return c.Algorithm
+}
+
+//NewHash returns content digest hash
+func (c *ContentAddressed) NewHash() hash.Hash {
+	return digest.New(c.DigestAlgorithm())
+}
+
+//baseURL returns content store base URL
+func (c *ContentAddressed) baseURL(resource *Resource) string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	return resource.URL
+}
+
+//ContentURL returns content URL for supplied hex digest, i.e. gs://lake/sha256/ab/cd/abcd...
+func (c *ContentAddressed) ContentURL(resource *Resource, digest string) string {
+	depth := c.Depth
+	if depth == 0 {
+		depth = defaultContentDepth
+	}
+	elements := []string{c.DigestAlgorithm()}
+	for i := 0; i < depth && len(digest) >= 2*(i+1); i++ {
+		elements = append(elements, digest[2*i:2*(i+1)])
+	}
+	elements = append(elements, digest)
+	return url.Join(c.baseURL(resource), elements...)
+}
+
+//TempURL returns temporary upload URL used until content digest is known
+func (c *ContentAddressed) TempURL(resource *Resource, name string) string {
+	return url.Join(c.baseURL(resource), c.DigestAlgorithm(), contentTempFolder, name)
+}
+
+//ManifestURL returns manifest URL for the original dest URL
+func (c *ContentAddressed) ManifestURL(destURL string) string {
+	if c.ManifestSuffix == "" {
+		return destURL + defaultContentManifestSuffix
+	}
+	return destURL + c.ManifestSuffix
+}
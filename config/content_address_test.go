@@ -0,0 +1,52 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestContentAddressed_ContentURL(t *testing.T) {
+	var useCases = []struct {
+		description string
+		ContentAddressed
+		URL         string
+		Topic       string
+		expect      string
+		expectError bool
+	}{
+		{
+			description: "default layout",
+			URL:         "gs://lake",
+			expect:      "gs://lake/sha256/ab/cd/abcdef",
+		},
+		{
+			description: "custom base and depth",
+			ContentAddressed: ContentAddressed{BaseURL: "s3://cas", Algorithm: DigestSHA1, Depth: 1},
+			URL:              "s3://bucket/data",
+			expect:           "s3://cas/sha1/ab/abcdef",
+		},
+		{
+			description: "unsupported algorithm",
+			ContentAddressed: ContentAddressed{Algorithm: "md5"},
+			URL:              "gs://lake",
+			expectError:      true,
+		},
+		{
+			description: "message dest",
+			URL:         "gs://lake",
+			Topic:       "events",
+			expectError: true,
+		},
+	}
+	for _, useCase := range useCases {
+		resource := &Resource{URL: useCase.URL, Topic: useCase.Topic}
+		err := useCase.ContentAddressed.Validate(resource)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if assert.Nil(t, err, useCase.description) {
+			assert.Equal(t, useCase.expect, useCase.ContentAddressed.ContentURL(resource, "abcdef"), useCase.description)
+		}
+	}
+}
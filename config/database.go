@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	//DriverPostgres PostgreSQL driver, CSV is loaded with COPY FROM STDIN
+	DriverPostgres = "postgres"
+	//DriverMySQL MySQL driver, CSV is loaded with LOAD DATA LOCAL INFILE
+	DriverMySQL = "mysql"
+)
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*(\.[A-Za-z_][A-Za-z0-9_$]*)?$`)
+
+//bindVariable matches source URL and transfer ID variables, optionally single quoted
+var bindVariable = regexp.MustCompile(`'\$(SourceURL|TransferID)'|\$(SourceURL|TransferID)`)
+
+//Database represents relational table destination, each file (or split part) is loaded in its own transaction
+type Database struct {
+	//Driver postgres or mysql
+	Driver string
+	//Table destination table, optionally schema qualified
+	Table string
+	//Columns table columns in CSV order, CSV header is used otherwise
+	Columns []string `json:",omitempty"`
+	//Delimiter CSV delimiter, comma by default
+	Delimiter string `json:",omitempty"`
+	//Header first CSV line holds column names
+	Header bool `json:",omitempty"`
+	//PreSQL statements executed in file transaction before load, $Table is expanded, $SourceURL and $TransferID are bind parameters
+	PreSQL []string `json:",omitempty"`
+	//PostSQL statements executed in file transaction after load, $Table is expanded, $SourceURL and $TransferID are bind parameters
+	PostSQL []string `json:",omitempty"`
+}
+
+//Init initialises defaults
+func (d *Database) Init() {
+	if d.Delimiter == "" {
+		d.Delimiter = ","
+	}
+}
+
+//Validate checks if database destination is valid
+func (d *Database) Validate(resource *Resource) error {
+	if d.Driver != DriverPostgres && d.Driver != DriverMySQL {
+		return errors.Errorf("unsupported database.Driver: '%v', expected %v or %v", d.Driver, DriverPostgres, DriverMySQL)
+	}
+	if resource.Credentials == nil {
+		return errors.New("database credentials (DSN) were empty")
+	}
+	if !identifier.MatchString(d.Table) {
+		return errors.Errorf("invalid database.Table: '%v'", d.Table)
+	}
+	for _, column := range d.Columns {
+		if !identifier.MatchString(column) || strings.Contains(column, ".") {
+			return errors.Errorf("invalid database.Columns column: '%v'", column)
+		}
+	}
+	if d.Driver == DriverPostgres && len(d.Columns) == 0 && !d.Header {
+		return errors.New("postgres database destination requires Columns or Header")
+	}
+	if len([]rune(d.Delimiter)) > 1 {
+		return errors.Errorf("invalid database.Delimiter: '%v'", d.Delimiter)
+	}
+	return nil
+}
+
+//BindSQL returns statement with expanded validated table and driver placeholders replacing source URL and transfer ID
+//(quoted or not), with matching bind arguments; object keys are never pasted into SQL text
+func (d *Database) BindSQL(statement, sourceURL, transferID string) (string, []interface{}) {
+	var args []interface{}
+	SQL := bindVariable.ReplaceAllStringFunc(strings.Replace(statement, "$Table", d.Table, -1), func(variable string) string {
+		value := sourceURL
+		if strings.Contains(variable, "TransferID") {
+			value = transferID
+		}
+		args = append(args, value)
+		if d.Driver == DriverPostgres {
+			return fmt.Sprintf("$%v", len(args))
+		}
+		return "?"
+	})
+	return SQL, args
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"strings"
+)
+
+//Databricks represents Databricks Unity Catalog volume or DBFS destination, resource URL is a volume (/Volumes/catalog/schema/volume/path) or DBFS (dbfs:/path) path
+type Databricks struct {
+	//Host workspace URL, i.e. https://adb-1234567890123456.7.azuredatabricks.net
+	Host string
+	//SkipOverwrite fails delivery if destination file exists
+	SkipOverwrite bool `json:",omitempty"`
+	//JobID optional job triggered with run now after delivery
+	JobID int64 `json:",omitempty"`
+	//JobParameters job parameters, $SourceURL and $DestURL are expanded
+	JobParameters map[string]string `json:",omitempty"`
+}
+
+//Validate checks if databricks destination is valid
+func (d *Databricks) Validate(resource *Resource) error {
+	if d.Host == "" {
+		return errors.New("databricks.Host was empty")
+	}
+	if resource.Credentials == nil {
+		return errors.New("databricks credentials were empty")
+	}
+	if !strings.HasPrefix(resource.URL, "/Volumes/") && !strings.HasPrefix(resource.URL, "dbfs:/") {
+		return errors.Errorf("unsupported databricks path: %v, expected /Volumes/ or dbfs:/ prefix", resource.URL)
+	}
+	return nil
+}
+
+//ExpandJobParameters returns job parameters with expanded source and dest URL
+func (d *Databricks) ExpandJobParameters(sourceURL, destURL string) map[string]string {
+	var result = make(map[string]string)
+	replacer := strings.NewReplacer("$SourceURL", sourceURL, "$DestURL", destURL)
+	for key, value := range d.JobParameters {
+		result[key] = replacer.Replace(value)
+	}
+	return result
+}
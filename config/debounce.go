@@ -0,0 +1,77 @@
+package config
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"time"
+)
+
+const (
+	defaultDebounceQuietPeriod = 30
+	//maxDebounceQuietPeriod keeps debounce wait within cloud function timeout
+	maxDebounceQuietPeriod = 300
+)
+
+//Debounce represents source event debouncing for rapidly rewritten objects, an event waits until source object is quiet
+//for QuietPeriod and is only mirrored if no later event for the same object was recorded in the meanwhile
+type Debounce struct {
+	//StateURL base URL storing the latest event per source object
+	StateURL string
+	//QuietPeriod time without source object modification, number of seconds or duration text, default 30 sec, max 5 min
+	QuietPeriod base.Seconds `json:",omitempty"`
+}
+
+//DebounceEntry represents the latest source object event
+type DebounceEntry struct {
+	SourceURL string
+	//EventID unique ID of the latest event
+	EventID string
+	//Version source object version: modification time and size
+	Version  string
+	Recorded time.Time
+}
+
+//Init initialises debounce settings
+func (d *Debounce) Init() {
+	if d.QuietPeriod == 0 {
+		d.QuietPeriod = defaultDebounceQuietPeriod
+	}
+}
+
+//Validate checks if debounce settings are valid
+func (d *Debounce) Validate() error {
+	if d.StateURL == "" {
+		return errors.New("debounce.StateURL was empty")
+	}
+	if d.QuietPeriod < 0 || d.QuietPeriod > maxDebounceQuietPeriod {
+		return errors.Errorf("invalid debounce.QuietPeriod: %v, max: %v", d.QuietPeriod, maxDebounceQuietPeriod)
+	}
+	return nil
+}
+
+//EntryURL returns the latest source object event entry URL
+func (d *Debounce) EntryURL(sourceURL string) string {
+	key := md5.Sum([]byte(sourceURL))
+	return url.Join(d.StateURL, hex.EncodeToString(key[:])+".json")
+}
+
+//Wait returns time to wait until source object modified at supplied time is quiet
+func (d *Debounce) Wait(modified, now time.Time) time.Duration {
+	quiet := d.QuietPeriod.Duration()
+	if d.QuietPeriod == 0 {
+		quiet = defaultDebounceQuietPeriod * time.Second
+	}
+	if wait := modified.Add(quiet).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+//ObjectVersion returns source object version
+func ObjectVersion(modified time.Time, size int64) string {
+	return fmt.Sprintf("%v:%v", modified.UnixNano(), size)
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/digest"
+	"time"
+)
+
+const (
+	//DuplicateFlag reports duplicate and mirrors it (default)
+	DuplicateFlag = "flag"
+	//DuplicateSkip reports duplicate without mirroring it
+	DuplicateSkip = "skip"
+	//DuplicateQuarantine moves duplicate to quarantine location
+	DuplicateQuarantine = "quarantine"
+
+	defaultDedupWindowHours = 48
+)
+
+//Dedup represents duplicate content detection settings
+type Dedup struct {
+	//IndexURL base URL storing mirrored content digests
+	IndexURL string
+	//WindowHours digest retention window, default 48
+	WindowHours int `json:",omitempty"`
+	//OnDuplicate flag (default), skip or quarantine
+	OnDuplicate string `json:",omitempty"`
+	//QuarantineURL base URL where quarantined duplicates are moved
+	QuarantineURL string `json:",omitempty"`
+	//Algorithm digest computed when source provider does not expose checksum: md5 (default), crc32c, xxhash64, blake3 ...
+	Algorithm string `json:",omitempty"`
+}
+
+//Digest represents mirrored content digest index entry
+type Digest struct {
+	Digest     string
+	SourceURL  string
+	TransferID string
+	Mirrored   time.Time
+}
+
+//Init initialises dedup settings
+func (d *Dedup) Init() {
+	if d.WindowHours == 0 {
+		d.WindowHours = defaultDedupWindowHours
+	}
+	if d.OnDuplicate == "" {
+		d.OnDuplicate = DuplicateFlag
+	}
+	if d.Algorithm == "" {
+		d.Algorithm = digest.MD5
+	}
+}
+
+//Validate checks if dedup settings are valid
+func (d *Dedup) Validate() error {
+	if d.IndexURL == "" {
+		return errors.New("dedup.IndexURL was empty")
+	}
+	switch d.OnDuplicate {
+	case DuplicateFlag, DuplicateSkip:
+	case DuplicateQuarantine:
+		if d.QuarantineURL == "" {
+			return errors.New("dedup.QuarantineURL was empty")
+		}
+	default:
+		return errors.Errorf("invalid dedup.OnDuplicate: %v", d.OnDuplicate)
+	}
+	if d.Algorithm != "" && !digest.IsSupported(d.Algorithm) {
+		return errors.Errorf("unsupported dedup.Algorithm: %v", d.Algorithm)
+	}
+	return nil
+}
+
+//Window returns digest retention window
+func (d *Dedup) Window() time.Duration {
+	return time.Duration(d.WindowHours) * time.Hour
+}
+
+//EntryURL returns content digest index entry URL
+func (d *Dedup) EntryURL(digest string) string {
+	key := md5.Sum([]byte(digest))
+	return url.Join(d.IndexURL, hex.EncodeToString(key[:])+".json")
+}
+
+//QuarantineDestURL returns source object quarantine URL
+func (d *Dedup) QuarantineDestURL(sourceURL string) string {
+	return url.Join(d.QuarantineURL, url.Host(sourceURL), url.Path(sourceURL))
+}
+
+//IsDuplicate returns true if digest entry was mirrored from other source within window
+func (d *Dedup) IsDuplicate(entry *Digest, sourceURL string, now time.Time) bool {
+	return entry != nil && entry.SourceURL != sourceURL && now.Sub(entry.Mirrored) <= d.Window()
+}
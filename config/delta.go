@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/digest"
+)
+
+const (
+	defaultDeltaBlockSizeMb = 8
+	//minDeltaBlockSizeMb S3 multipart upload min part size
+	minDeltaBlockSizeMb = 5
+	defaultDeltaSuffix  = ".delta.json"
+)
+
+//Delta represents delta transfer, dest object is reassembled server-side from unchanged blocks of existing dest object
+//and changed blocks of source, block signatures of the last transfer are stored next to dest object
+type Delta struct {
+	//BlockSizeMb signature block size, default 8, min 5
+	BlockSizeMb base.Megabytes `json:",omitempty"`
+	//MinSizeMb min source size to use delta transfer, smaller sources are uploaded as whole without signatures
+	MinSizeMb base.Megabytes `json:",omitempty"`
+	//SignatureSuffix block signature suffix appended to dest URL, default .delta.json
+	SignatureSuffix string `json:",omitempty"`
+	//Algorithm block digest algorithm: sha256 (default), blake3 or xxhash64
+	Algorithm string `json:",omitempty"`
+}
+
+//DeltaSignature represents dest object block signatures
+type DeltaSignature struct {
+	//Version dest object version: provider checksum, or modification time and size
+	Version   string
+	Size      int64
+	BlockSize int64
+	//Algorithm block digest algorithm, sha256 if empty
+	Algorithm string `json:",omitempty"`
+	//Blocks hex encoded block digests
+	Blocks []string
+}
+
+//Validate checks if delta transfer is valid for supplied dest
+func (d *Delta) Validate(resource *Resource) error {
+	if d.BlockSizeMb != 0 && d.BlockSizeMb < minDeltaBlockSizeMb {
+		return fmt.Errorf("invalid delta.BlockSizeMb: %v, min: %v", d.BlockSizeMb, minDeltaBlockSizeMb)
+	}
+	if d.MinSizeMb < 0 {
+		return fmt.Errorf("invalid delta.MinSizeMb: %v", d.MinSizeMb)
+	}
+	switch d.Algorithm {
+	case "", digest.SHA256, digest.BLAKE3, digest.XXHash64:
+	default:
+		return fmt.Errorf("unsupported delta.Algorithm: %v, expected %v, %v or %v", d.Algorithm, digest.SHA256, digest.BLAKE3, digest.XXHash64)
+	}
+	if !resource.IsStorage() || resource.Topic != "" || resource.Queue != "" {
+		return fmt.Errorf("delta is only supported with storage dest, but had: %v", resource.URL)
+	}
+	if resource.Retention != nil || resource.ContentAddressed != nil || resource.Append != nil {
+		return fmt.Errorf("delta can not be used with retention, contentAddressed or append")
+	}
+	return nil
+}
+
+//BlockSize returns signature block size in bytes
+func (d *Delta) BlockSize() int64 {
+	if d.BlockSizeMb == 0 {
+		return defaultDeltaBlockSizeMb * 1024 * 1024
+	}
+	return int64(d.BlockSizeMb) * 1024 * 1024
+}
+
+//DigestAlgorithm returns block digest algorithm
+func (d *Delta) DigestAlgorithm() string {
+	if d.Algorithm == "" {
+		return digest.SHA256
+	}
+	return d.Algorithm
+}
+
+//MinSize returns min source size in bytes
+func (d *Delta) MinSize() int64 {
+	return int64(d.MinSizeMb) * 1024 * 1024
+}
+
+//SignatureURL returns block signature URL for supplied dest URL
+func (d *Delta) SignatureURL(destURL string) string {
+	if d.SignatureSuffix == "" {
+		return destURL + defaultDeltaSuffix
+	}
+	return destURL + d.SignatureSuffix
+}
+
+//DigestAlgorithm returns block digest algorithm
+func (s *DeltaSignature) DigestAlgorithm() string {
+	if s.Algorithm == "" {
+		return digest.SHA256
+	}
+	return s.Algorithm
+}
+
+//Reusable returns true if block at supplied index with supplied digest and size can be copied from dest object
+func (s *DeltaSignature) Reusable(index int, digest string, size int64) bool {
+	if s == nil || index >= len(s.Blocks) || s.Blocks[index] != digest {
+		return false
+	}
+	offset := int64(index) * s.BlockSize
+	return offset+size <= s.Size && (size == s.BlockSize || offset+size == s.Size)
+}
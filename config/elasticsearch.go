@@ -0,0 +1,146 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"github.com/viant/afs/url"
+	"github.com/viant/toolbox"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var indexDateMath = regexp.MustCompile(`\{now(([+-])(\d+)([yMwdhHm]))?(/([yMwdhHm]))?(\{([^{}]+)\})?\}`)
+
+var dateMathFormats = map[string]string{"y": "yyyy", "M": "yyyy.MM", "w": "yyyy.MM.dd", "d": "yyyy.MM.dd", "h": "yyyy.MM.dd.HH", "H": "yyyy.MM.dd.HH", "m": "yyyy.MM.dd.HH.mm"}
+
+//Elasticsearch represents Elasticsearch or OpenSearch bulk index destination, resource URL is cluster URL
+type Elasticsearch struct {
+	//Index index name template, $partition and $name (source file name without extension) are expanded, date math, i.e. <logs-{now/d}> or <logs-{now-1d/M{yyyy-MM}}> is resolved in UTC
+	Index string
+	//Format json (newline delimited) or csv, source extension is used by default
+	Format string `json:",omitempty"`
+	//Delimiter CSV delimiter, comma by default, first CSV line holds field names
+	Delimiter string `json:",omitempty"`
+	//IDField optional document field used as document ID
+	IDField string `json:",omitempty"`
+	//BatchSize documents per bulk request, default 1000
+	BatchSize int `json:",omitempty"`
+	//MaxRetries max retries of rejected (429) requests or documents, default 5
+	MaxRetries int `json:",omitempty"`
+	//BackoffMs initial retry backoff doubled with each retry, default 500
+	BackoffMs int `json:",omitempty"`
+	//MaxFailedDocs max failed documents per file before transfer fails, default 0
+	MaxFailedDocs int `json:",omitempty"`
+}
+
+//Init initialises defaults
+func (e *Elasticsearch) Init() {
+	if e.Delimiter == "" {
+		e.Delimiter = ","
+	}
+	if e.BatchSize == 0 {
+		e.BatchSize = 1000
+	}
+	if e.MaxRetries == 0 {
+		e.MaxRetries = 5
+	}
+	if e.BackoffMs == 0 {
+		e.BackoffMs = 500
+	}
+}
+
+//Validate checks if elasticsearch destination is valid
+func (e *Elasticsearch) Validate(resource *Resource) error {
+	if e.Index == "" {
+		return errors.New("elasticsearch.Index was empty")
+	}
+	if scheme := url.Scheme(resource.URL, ""); scheme != "http" && scheme != "https" {
+		return errors.Errorf("unsupported elasticsearch URL: %v, expected http:// or https:// scheme", resource.URL)
+	}
+	switch strings.ToLower(e.Format) {
+	case "", "json", "csv":
+	default:
+		return errors.Errorf("unsupported elasticsearch.Format: %v", e.Format)
+	}
+	if e.BatchSize < 0 || e.MaxRetries < 0 || e.MaxFailedDocs < 0 {
+		return errors.New("elasticsearch BatchSize, MaxRetries and MaxFailedDocs can not be negative")
+	}
+	return nil
+}
+
+//IsCSV returns true if source is CSV
+func (e *Elasticsearch) IsCSV(sourceURL string) bool {
+	if e.Format != "" {
+		return strings.ToLower(e.Format) == "csv"
+	}
+	ext := strings.ToLower(path.Ext(strings.TrimSuffix(strings.TrimSuffix(sourceURL, ".gz"), ".zip")))
+	return ext == ".csv" || ext == ".tsv"
+}
+
+//ExpandIndex returns index name for source URL, split partition and time
+func (e *Elasticsearch) ExpandIndex(sourceURL, partition string, now time.Time) string {
+	name := path.Base(sourceURL)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	index := strings.NewReplacer("$partition", partition, "$name", name).Replace(e.Index)
+	if !strings.HasPrefix(index, "<") || !strings.HasSuffix(index, ">") {
+		return strings.ToLower(index)
+	}
+	index = index[1 : len(index)-1]
+	index = indexDateMath.ReplaceAllStringFunc(index, func(expression string) string {
+		matched := indexDateMath.FindStringSubmatch(expression)
+		at := now.UTC()
+		if matched[1] != "" {
+			amount, _ := strconv.Atoi(matched[3])
+			if matched[2] == "-" {
+				amount = -amount
+			}
+			at = addDateUnit(at, matched[4], amount)
+		}
+		unit := matched[6]
+		if unit == "" {
+			unit = "d"
+		}
+		at = roundDate(at, unit)
+		format := matched[8]
+		if format == "" {
+			format = dateMathFormats[unit]
+		}
+		return at.Format(toolbox.DateFormatToLayout(format))
+	})
+	return strings.ToLower(index)
+}
+
+func addDateUnit(at time.Time, unit string, amount int) time.Time {
+	switch unit {
+	case "y":
+		return at.AddDate(amount, 0, 0)
+	case "M":
+		return at.AddDate(0, amount, 0)
+	case "w":
+		return at.AddDate(0, 0, 7*amount)
+	case "d":
+		return at.AddDate(0, 0, amount)
+	case "h", "H":
+		return at.Add(time.Duration(amount) * time.Hour)
+	}
+	return at.Add(time.Duration(amount) * time.Minute)
+}
+
+func roundDate(at time.Time, unit string) time.Time {
+	switch unit {
+	case "y":
+		return time.Date(at.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	case "M":
+		return time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case "w":
+		day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case "d":
+		return time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	case "h", "H":
+		return at.Truncate(time.Hour)
+	}
+	return at.Truncate(time.Minute)
+}
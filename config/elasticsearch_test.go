@@ -0,0 +1,26 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestElasticsearch_ExpandIndex(t *testing.T) {
+	now := time.Date(2024, 3, 14, 15, 9, 26, 0, time.UTC)
+	var useCases = []struct {
+		description string
+		index       string
+		expect      string
+	}{
+		{description: "plain", index: "Events-$name", expect: "events-orders_1"},
+		{description: "default day", index: "<logs-{now/d}>", expect: "logs-2024.03.14"},
+		{description: "offset and format", index: "<logs-{now-1M/M{yyyy-MM}}>", expect: "logs-2024-02"},
+		{description: "week", index: "<logs-{now/w}>", expect: "logs-2024.03.11"},
+		{description: "partition", index: "<$partition-{now/y}>", expect: "eu-2024"},
+	}
+	for _, useCase := range useCases {
+		settings := &Elasticsearch{Index: useCase.index}
+		assert.Equal(t, useCase.expect, settings.ExpandIndex("gs://bucket/data/orders_1.json", "eu", now), useCase.description)
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+import "strings"
+
+const (
+	//EmptySkip skips zero-byte or placeholder object
+	EmptySkip = "skip"
+	//EmptyMirror mirrors zero-byte object as any other object
+	EmptyMirror = "mirror"
+	//EmptyComplete treats zero-byte object as completion signal, all other parent folder objects are replayed
+	EmptyComplete = "complete"
+
+	folderPlaceholderSuffix = "_$folder$"
+)
+
+//IsValidEmptyAction returns true if action is empty or a known zero-byte object handling
+func IsValidEmptyAction(action string) bool {
+	switch action {
+	case "", EmptySkip, EmptyMirror, EmptyComplete:
+		return true
+	}
+	return false
+}
+
+//IsPlaceholder returns true if object is zero-byte object or folder marker
+func IsPlaceholder(URL string, size int64) bool {
+	return size == 0 || strings.HasSuffix(URL, "/") || strings.HasSuffix(URL, folderPlaceholderSuffix)
+}
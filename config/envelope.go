@@ -0,0 +1,390 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/base"
+	"gopkg.in/yaml.v2"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	sopsMetadataKey = "sops"
+	sopsValuePrefix = "ENC[AES256_GCM,"
+	//sopsRulesKey wraps rules array, SOPS documents are objects
+	sopsRulesKey    = "Rules"
+)
+
+//Decrypter decrypts KMS ciphertext with supplied key (GCP KMS key name or AWS KMS key ARN/alias)
+type Decrypter func(ctx context.Context, key string, ciphertext []byte) ([]byte, error)
+
+//DecryptRuleFile returns decrypted rule file content for SOPS or KMS encrypted file, otherwise data as is;
+//SOPS data key is decrypted with key listed in sops metadata, whole file KMS ciphertext is decrypted with supplied key
+func DecryptRuleFile(ctx context.Context, data []byte, ext, key string, decrypter Decrypter) ([]byte, error) {
+	if ciphertext, ok := kmsCiphertext(data); ok {
+		if key == "" {
+			return nil, errors.New("KMS encrypted rule file requires EncryptionKey")
+		}
+		if decrypter == nil {
+			return nil, errors.New("decrypter was empty")
+		}
+		plaintext, err := decrypter(ctx, key, ciphertext)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decrypt rule file with %v", key)
+		}
+		return plaintext, nil
+	}
+	document, ok := sopsDocument(data, ext)
+	if !ok {
+		return data, nil
+	}
+	if decrypter == nil {
+		return nil, errors.New("decrypter was empty")
+	}
+	dataKey, err := sopsDataKey(ctx, document[sopsMetadataKey], decrypter)
+	if err != nil {
+		return nil, err
+	}
+	if err = sopsVerifyMAC(data, ext, document[sopsMetadataKey], dataKey); err != nil {
+		return nil, err
+	}
+	delete(document, sopsMetadataKey)
+	decrypted, err := sopsDecrypt(document, nil, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if rules, ok := document[sopsRulesKey].([]interface{}); ok && len(document) == 1 {
+		return json.Marshal(rules)
+	}
+	return json.Marshal(decrypted)
+}
+
+//kmsCiphertext returns whole file KMS ciphertext, binary or base64 encoded, rule JSON or YAML is never valid base64
+func kmsCiphertext(data []byte) ([]byte, bool) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, false
+	}
+	if !utf8.Valid(trimmed) {
+		return trimmed, true
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(string(trimmed))
+	if err != nil || len(ciphertext) == 0 {
+		return nil, false
+	}
+	return ciphertext, true
+}
+
+//sopsDocument returns decoded rule document if it has sops metadata
+func sopsDocument(data []byte, ext string) (map[string]interface{}, bool) {
+	if !bytes.Contains(data, []byte(sopsValuePrefix)) {
+		return nil, false
+	}
+	var document interface{}
+	var err error
+	if ext == base.YAMLExt {
+		err = yaml.Unmarshal(data, &document)
+		document = normalizeYAML(document)
+	} else {
+		err = json.Unmarshal(data, &document)
+	}
+	aMap, ok := document.(map[string]interface{})
+	if err != nil || !ok {
+		return nil, false
+	}
+	if _, ok = aMap[sopsMetadataKey].(map[string]interface{}); !ok {
+		return nil, false
+	}
+	return aMap, true
+}
+
+//normalizeYAML converts YAML maps into JSON compatible maps
+func normalizeYAML(value interface{}) interface{} {
+	switch actual := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(actual))
+		for k, v := range actual {
+			result[fmt.Sprint(k)] = normalizeYAML(v)
+		}
+		return result
+	case []interface{}:
+		for i := range actual {
+			actual[i] = normalizeYAML(actual[i])
+		}
+	}
+	return value
+}
+
+//sopsDataKey decrypts SOPS data key with the first usable GCP or AWS KMS master key
+func sopsDataKey(ctx context.Context, metadata interface{}, decrypter Decrypter) ([]byte, error) {
+	aMap, _ := metadata.(map[string]interface{})
+	var lastErr error
+	for _, provider := range []struct{ group, keyField string }{{"gcp_kms", "resource_id"}, {"kms", "arn"}} {
+		entries, _ := aMap[provider.group].([]interface{})
+		for _, entry := range entries {
+			masterKey, _ := entry.(map[string]interface{})
+			key, _ := masterKey[provider.keyField].(string)
+			enc, _ := masterKey["enc"].(string)
+			if key == "" || enc == "" {
+				continue
+			}
+			ciphertext, err := base64.StdEncoding.DecodeString(enc)
+			if err != nil {
+				lastErr = errors.Wrapf(err, "invalid sops data key: %v", key)
+				continue
+			}
+			dataKey, err := decrypter(ctx, key, ciphertext)
+			if err != nil {
+				lastErr = errors.Wrapf(err, "failed to decrypt sops data key with %v", key)
+				continue
+			}
+			return dataKey, nil
+		}
+	}
+	if lastErr == nil {
+		lastErr = errors.New("sops metadata has no gcp_kms or kms master key")
+	}
+	return nil, lastErr
+}
+
+//sopsDecrypt decrypts SOPS values, value additional data is its key path joined with ':'
+func sopsDecrypt(value interface{}, path []string, dataKey []byte) (interface{}, error) {
+	switch actual := value.(type) {
+	case map[string]interface{}:
+		for k, v := range actual {
+			decrypted, err := sopsDecrypt(v, append(path[:len(path):len(path)], k), dataKey)
+			if err != nil {
+				return nil, err
+			}
+			actual[k] = decrypted
+		}
+	case []interface{}:
+		for i, v := range actual {
+			decrypted, err := sopsDecrypt(v, path, dataKey)
+			if err != nil {
+				return nil, err
+			}
+			actual[i] = decrypted
+		}
+	case string:
+		if strings.HasPrefix(actual, sopsValuePrefix) {
+			return sopsDecryptValue(actual, strings.Join(path, ":")+":", dataKey)
+		}
+	}
+	return value, nil
+}
+
+//sopsDecryptValue decrypts ENC[AES256_GCM,data:...,iv:...,tag:...,type:...] value
+func sopsDecryptValue(value, additionalData string, dataKey []byte) (interface{}, error) {
+	plaintext, valueType, err := sopsOpen(value, additionalData, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	text := string(plaintext)
+	switch valueType {
+	case "int":
+		return strconv.Atoi(text)
+	case "float":
+		return strconv.ParseFloat(text, 64)
+	case "bool":
+		return strconv.ParseBool(text)
+	}
+	return text, nil
+}
+
+//sopsOpen returns ENC[AES256_GCM,...] value plaintext and type
+func sopsOpen(value, additionalData string, dataKey []byte) ([]byte, string, error) {
+	fields := map[string]string{}
+	for _, field := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(value, sopsValuePrefix), "]"), ",") {
+		if index := strings.Index(field, ":"); index != -1 {
+			fields[field[:index]] = field[index+1:]
+		}
+	}
+	encrypted, err := base64.StdEncoding.DecodeString(fields["data"])
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "invalid sops value data: %v", additionalData)
+	}
+	iv, err := base64.StdEncoding.DecodeString(fields["iv"])
+	if err != nil || len(iv) == 0 {
+		return nil, "", errors.Errorf("invalid sops value iv: %v", additionalData)
+	}
+	tag, err := base64.StdEncoding.DecodeString(fields["tag"])
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "invalid sops value tag: %v", additionalData)
+	}
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "invalid sops data key")
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(encrypted, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to decrypt sops value: %v", additionalData)
+	}
+	return plaintext, fields["type"], nil
+}
+
+//sopsLeaf represents SOPS document leaf value with its key path
+type sopsLeaf struct {
+	path  []string
+	value interface{}
+}
+
+//sopsVerifyMAC checks sops.mac against SHA512 of document cleartext values in document order,
+//so that added or modified unencrypted values are rejected
+func sopsVerifyMAC(data []byte, ext string, metadata interface{}, dataKey []byte) error {
+	aMap, _ := metadata.(map[string]interface{})
+	mac, _ := aMap["mac"].(string)
+	if !strings.HasPrefix(mac, sopsValuePrefix) {
+		return errors.New("sops metadata has no mac")
+	}
+	expected, _, err := sopsOpen(mac, fmt.Sprint(aMap["lastmodified"]), dataKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to decrypt sops mac")
+	}
+	leaves, err := sopsLeaves(data, ext)
+	if err != nil {
+		return err
+	}
+	macOnlyEncrypted, _ := aMap["mac_only_encrypted"].(bool)
+	hash := sha512.New()
+	for _, leaf := range leaves {
+		var value []byte
+		text, encrypted := leaf.value.(string)
+		encrypted = encrypted && strings.HasPrefix(text, sopsValuePrefix)
+		if encrypted {
+			if value, _, err = sopsOpen(text, strings.Join(leaf.path, ":")+":", dataKey); err != nil {
+				return err
+			}
+		} else if macOnlyEncrypted {
+			continue
+		} else if value, err = sopsBytes(leaf.value); err != nil {
+			return errors.Wrapf(err, "invalid sops value: %v", strings.Join(leaf.path, ":"))
+		}
+		hash.Write(value)
+	}
+	actual := fmt.Sprintf("%X", hash.Sum(nil))
+	if subtle.ConstantTimeCompare([]byte(actual), []byte(strings.ToUpper(string(expected)))) != 1 {
+		return errors.New("sops mac mismatch, rule file was modified")
+	}
+	return nil
+}
+
+//sopsBytes returns cleartext value bytes as SOPS hashes them
+func sopsBytes(value interface{}) ([]byte, error) {
+	switch actual := value.(type) {
+	case nil:
+		return []byte{}, nil
+	case string:
+		return []byte(actual), nil
+	case bool:
+		if actual {
+			return []byte("True"), nil
+		}
+		return []byte("False"), nil
+	case int:
+		return []byte(strconv.Itoa(actual)), nil
+	case float64:
+		return []byte(strconv.FormatFloat(actual, 'f', -1, 64)), nil
+	case json.Number:
+		if intValue, err := strconv.Atoi(string(actual)); err == nil {
+			return []byte(strconv.Itoa(intValue)), nil
+		}
+		floatValue, err := actual.Float64()
+		if err != nil {
+			return nil, err
+		}
+		return []byte(strconv.FormatFloat(floatValue, 'f', -1, 64)), nil
+	}
+	return nil, errors.Errorf("unsupported type: %T", value)
+}
+
+//sopsLeaves returns document leaf values in document order, sops metadata is skipped
+func sopsLeaves(data []byte, ext string) ([]sopsLeaf, error) {
+	var leaves []sopsLeaf
+	if ext == base.YAMLExt {
+		document := yaml.MapSlice{}
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, err
+		}
+		yamlLeaves(document, nil, &leaves)
+		return leaves, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return leaves, jsonLeaves(decoder, nil, &leaves)
+}
+
+//yamlLeaves appends YAML value leaves in document order
+func yamlLeaves(value interface{}, path []string, leaves *[]sopsLeaf) {
+	switch actual := value.(type) {
+	case yaml.MapSlice:
+		for _, item := range actual {
+			key := fmt.Sprint(item.Key)
+			if len(path) == 0 && key == sopsMetadataKey {
+				continue
+			}
+			yamlLeaves(item.Value, append(path[:len(path):len(path)], key), leaves)
+		}
+	case []interface{}:
+		for _, item := range actual {
+			yamlLeaves(item, path, leaves)
+		}
+	default:
+		*leaves = append(*leaves, sopsLeaf{path: path, value: value})
+	}
+}
+
+//jsonLeaves appends JSON value leaves in document order
+func jsonLeaves(decoder *json.Decoder, path []string, leaves *[]sopsLeaf) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		*leaves = append(*leaves, sopsLeaf{path: path, value: token})
+		return nil
+	}
+	switch delim {
+	case '{':
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			key, _ := keyToken.(string)
+			if len(path) == 0 && key == sopsMetadataKey {
+				var skipped json.RawMessage
+				if err = decoder.Decode(&skipped); err != nil {
+					return err
+				}
+				continue
+			}
+			if err = jsonLeaves(decoder, append(path[:len(path):len(path)], key), leaves); err != nil {
+				return err
+			}
+		}
+	case '[':
+		for decoder.More() {
+			if err = jsonLeaves(decoder, path, leaves); err != nil {
+				return err
+			}
+		}
+	}
+	_, err = decoder.Token()
+	return err
+}
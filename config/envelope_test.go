@@ -0,0 +1,141 @@
+package config
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestDecryptRuleFile(t *testing.T) {
+	dataKey := []byte("0123456789abcdef0123456789abcdef")
+	masterKey := "projects/p/locations/global/keyRings/r/cryptoKeys/rules"
+	decrypter := func(ctx context.Context, key string, ciphertext []byte) ([]byte, error) {
+		if key != masterKey {
+			return nil, errors.Errorf("unknown key: %v", key)
+		}
+		return xorBytes(ciphertext), nil
+	}
+	encKey := base64.StdEncoding.EncodeToString(xorBytes(dataKey))
+	lastModified := "2026-10-15T10:00:00Z"
+	sopsJSON := fmt.Sprintf(`[{"Info":{"Workflow":"secret"},"Dest":{"URL":"%v","Tokens":["%v"],"Port":"%v"}}]`,
+		sopsEncrypt(t, dataKey, "gs://secret/data", "Rules:Dest:URL:", "str"),
+		sopsEncrypt(t, dataKey, "abc", "Rules:Dest:Tokens:", "str"),
+		sopsEncrypt(t, dataKey, "8080", "Rules:Dest:Port:", "int"))
+	jsonMAC := sopsMAC(t, dataKey, lastModified, "secret", "gs://secret/data", "abc", "8080")
+	sopsMetadata := func(mac string) string {
+		return fmt.Sprintf(`{"gcp_kms":[{"resource_id":"%v","enc":"%v"}],"lastmodified":"%v","mac":"%v"}`, masterKey, encKey, lastModified, mac)
+	}
+
+	var useCases = []struct {
+		description string
+		data        string
+		ext         string
+		key         string
+		expect      string
+		expectError bool
+	}{
+		{
+			description: "plain json",
+			data:        `[{"Dest":{"URL":"gs://bucket/data"}}]`,
+			ext:         ".json",
+			expect:      `[{"Dest":{"URL":"gs://bucket/data"}}]`,
+		},
+		{
+			description: "kms envelope",
+			data:        base64.StdEncoding.EncodeToString(xorBytes([]byte(`[{"Dest":{"URL":"gs://bucket/data"}}]`))),
+			ext:         ".json",
+			key:         masterKey,
+			expect:      `[{"Dest":{"URL":"gs://bucket/data"}}]`,
+		},
+		{
+			description: "kms envelope without key",
+			data:        base64.StdEncoding.EncodeToString([]byte(`[]`)),
+			ext:         ".json",
+			expectError: true,
+		},
+		{
+			description: "sops json",
+			data:        fmt.Sprintf(`{"Rules":%v,"sops":%v}`, sopsJSON, sopsMetadata(jsonMAC)),
+			ext:         ".json",
+			expect:      `[{"Dest":{"Port":8080,"Tokens":["abc"],"URL":"gs://secret/data"},"Info":{"Workflow":"secret"}}]`,
+		},
+		{
+			description: "sops yaml",
+			data: fmt.Sprintf("Dest:\n  URL: %v\n  Retries: 3\nsops:\n  kms:\n  - arn: %v\n    enc: %v\n  lastmodified: \"%v\"\n  mac: %v\n",
+				sopsEncrypt(t, dataKey, "s3://secret/data", "Dest:URL:", "str"), masterKey, encKey, lastModified,
+				sopsMAC(t, dataKey, lastModified, "s3://secret/data", "3")),
+			ext:    ".yaml",
+			expect: `{"Dest":{"Retries":3,"URL":"s3://secret/data"}}`,
+		},
+		{
+			description: "sops tampered path",
+			data: fmt.Sprintf(`{"Source":{"URL":"%v"},"sops":%v}`,
+				sopsEncrypt(t, dataKey, "gs://secret/data", "Dest:URL:", "str"), sopsMetadata(sopsMAC(t, dataKey, lastModified, "gs://secret/data"))),
+			ext:         ".json",
+			expectError: true,
+		},
+		{
+			description: "sops added unencrypted value",
+			data:        fmt.Sprintf(`{"Rules":%v,"sops":%v}`, strings.Replace(sopsJSON, `"Dest":{`, `"Dest":{"Bucket":"gs://attacker",`, 1), sopsMetadata(jsonMAC)),
+			ext:         ".json",
+			expectError: true,
+		},
+		{
+			description: "sops modified unencrypted value",
+			data:        fmt.Sprintf(`{"Rules":%v,"sops":%v}`, strings.Replace(sopsJSON, `"Workflow":"secret"`, `"Workflow":"attacker"`, 1), sopsMetadata(jsonMAC)),
+			ext:         ".json",
+			expectError: true,
+		},
+		{
+			description: "sops without mac",
+			data:        fmt.Sprintf(`{"Rules":%v,"sops":{"gcp_kms":[{"resource_id":"%v","enc":"%v"}]}}`, sopsJSON, masterKey, encKey),
+			ext:         ".json",
+			expectError: true,
+		},
+	}
+	for _, useCase := range useCases {
+		actual, err := DecryptRuleFile(context.Background(), []byte(useCase.data), useCase.ext, useCase.key, decrypter)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if assert.Nil(t, err, useCase.description) {
+			assert.EqualValues(t, useCase.expect, string(actual), useCase.description)
+		}
+	}
+}
+
+func xorBytes(data []byte) []byte {
+	result := make([]byte, len(data))
+	for i := range data {
+		result[i] = data[i] ^ 0x5a
+	}
+	return result
+}
+
+func sopsEncrypt(t *testing.T, dataKey []byte, value, additionalData, valueType string) string {
+	block, err := aes.NewCipher(dataKey)
+	assert.Nil(t, err)
+	iv := []byte("0123456789abcdef0123456789abcdef")
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	assert.Nil(t, err)
+	sealed := gcm.Seal(nil, iv, []byte(value), []byte(additionalData))
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	return fmt.Sprintf("ENC[AES256_GCM,data:%v,iv:%v,tag:%v,type:%v]",
+		base64.StdEncoding.EncodeToString(data), base64.StdEncoding.EncodeToString(iv), base64.StdEncoding.EncodeToString(tag), valueType)
+}
+
+func sopsMAC(t *testing.T, dataKey []byte, lastModified string, values ...string) string {
+	hash := sha512.New()
+	for _, value := range values {
+		hash.Write([]byte(value))
+	}
+	return sopsEncrypt(t, dataKey, fmt.Sprintf("%X", hash.Sum(nil)), lastModified, "str")
+}
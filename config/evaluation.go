@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/afs/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	//ReasonMatched rule matched
+	ReasonMatched = "matched"
+	//ReasonDisabled rule matched but is disabled
+	ReasonDisabled = "disabled"
+	//ReasonDoneMarker rule matched done marker
+	ReasonDoneMarker = "doneMarker"
+	//ReasonBucketMismatch source bucket did not match
+	ReasonBucketMismatch = "bucketMismatch"
+	//ReasonPrefixMismatch source prefix did not match
+	ReasonPrefixMismatch = "prefixMismatch"
+	//ReasonSuffixMismatch source suffix did not match
+	ReasonSuffixMismatch = "suffixMismatch"
+	//ReasonExclusion source exclusion expression matched
+	ReasonExclusion = "exclusion"
+	//ReasonFilterMismatch source filter expression did not match
+	ReasonFilterMismatch = "filterMismatch"
+	//ReasonGlobMismatch source glob did not match
+	ReasonGlobMismatch = "globMismatch"
+	//ReasonPlaceholder rule matched but zero-byte or placeholder object is skipped or treated as completion with OnEmpty
+	ReasonPlaceholder = "placeholder"
+	//ReasonOverflow rule matched but object exceeds source overflow size
+	ReasonOverflow = "overflow"
+	//ReasonNoMatch unclassified no match
+	ReasonNoMatch = "noMatch"
+)
+
+//Evaluation represents rule match evaluation trace
+type Evaluation struct {
+	RuleURL  string `json:",omitempty"`
+	Workflow string `json:",omitempty"`
+	Matched  bool
+	Reason   string
+	Detail   string `json:",omitempty"`
+}
+
+//Evaluate evaluates rule against URL recording why rule did or did not match
+func (r *Rule) Evaluate(URL string) *Evaluation {
+	result := &Evaluation{RuleURL: r.Info.URL, Workflow: r.Info.Workflow}
+	if r.HasMatch(URL) {
+		result.Matched = true
+		result.Reason = ReasonMatched
+		location := url.Path(URL)
+		if _, name := path.Split(location); r.DoneMarker != "" && name == r.DoneMarker {
+			result.Reason = ReasonDoneMarker
+		}
+		if r.Disabled {
+			result.Reason = ReasonDisabled
+		}
+		return result
+	}
+	result.Reason, result.Detail = r.noMatchReason(URL)
+	return result
+}
+
+//EvaluateObject evaluates rule against object URL and size, matched rule reports placeholder or overflow object handling
+func (r *Rule) EvaluateObject(URL string, size int64) *Evaluation {
+	result := r.Evaluate(URL)
+	if result.Reason != ReasonMatched {
+		return result
+	}
+	if r.OnEmpty != "" && r.OnEmpty != EmptyMirror && IsPlaceholder(URL, size) {
+		result.Reason = ReasonPlaceholder
+		result.Detail = "onEmpty " + r.OnEmpty
+	} else if overflow := r.Source.Overflow; overflow != nil && overflow.Size() < size {
+		result.Reason = ReasonOverflow
+		result.Detail = fmt.Sprintf("size %v exceeds %vMB", size, overflow.SizeMB)
+	}
+	return result
+}
+
+func (r *Rule) noMatchReason(URL string) (string, string) {
+	if r.Source.Bucket != "" {
+		if bucket := url.Host(URL); bucket != r.Source.Bucket {
+			return ReasonBucketMismatch, "expected " + r.Source.Bucket + ", but had " + bucket
+		}
+	}
+	location := url.Path(URL)
+	_, name := path.Split(location)
+	if prefix := r.Source.Prefix; prefix != "" && !strings.HasPrefix(strings.Trim(location, "/"), strings.Trim(prefix, "/")) {
+		return ReasonPrefixMismatch, "expected " + prefix
+	}
+	if suffix := r.Source.Suffix; suffix != "" && !strings.HasSuffix(name, suffix) {
+		return ReasonSuffixMismatch, "expected " + suffix
+	}
+	if exclusion := r.Source.Exclusion; exclusion != "" {
+		expr, err := regexp.Compile(exclusion)
+		if err != nil {
+			return ReasonExclusion, err.Error()
+		}
+		if expr.MatchString(location) {
+			return ReasonExclusion, "excluded by " + exclusion
+		}
+	}
+	if filter := r.Source.Filter; filter != "" {
+		expr, err := regexp.Compile(filter)
+		if err != nil {
+			return ReasonFilterMismatch, err.Error()
+		}
+		if !expr.MatchString(location) {
+			return ReasonFilterMismatch, "expected " + filter
+		}
+	}
+	if glob := r.Source.Glob; glob != "" {
+		expr, err := CompileGlob(glob)
+		if err != nil {
+			return ReasonGlobMismatch, err.Error()
+		}
+		if !expr.MatchString(strings.TrimPrefix(location, "/")) {
+			return ReasonGlobMismatch, "expected " + glob
+		}
+	}
+	return ReasonNoMatch, ""
+}
+
+//Evaluate evaluates all rules against URL
+func (r *Ruleset) Evaluate(URL string) []*Evaluation {
+	rules := r.Snapshot()
+	var result = make([]*Evaluation, 0, len(rules))
+	for i := range rules {
+		result = append(result, rules[i].Evaluate(URL))
+	}
+	return result
+}
+
+//EvaluateObject evaluates all rules against object URL and size
+func (r *Ruleset) EvaluateObject(URL string, size int64) []*Evaluation {
+	rules := r.Snapshot()
+	var result = make([]*Evaluation, 0, len(rules))
+	for i := range rules {
+		result = append(result, rules[i].EvaluateObject(URL, size))
+	}
+	return result
+}
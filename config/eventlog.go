@@ -0,0 +1,81 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/eventlog"
+	"strings"
+	"time"
+)
+
+const defaultEventLogSettle = 30 * time.Second
+
+//EventLog represents append-only transfer lifecycle event log, exactly one of URL, Topic or Stream is required
+type EventLog struct {
+	//URL log structured bucket prefix, consumers read it with events cursor API
+	URL string `json:",omitempty"`
+	//Topic Pub/Sub topic
+	Topic string `json:",omitempty"`
+	//Stream Kinesis stream name
+	Stream string `json:",omitempty"`
+	//Region Kinesis stream region
+	Region string `json:",omitempty"`
+	//Events logged event types, all types by default
+	Events []string `json:",omitempty"`
+	//Settle bucket segments younger than settle duration are not read, default 30s
+	Settle base.Seconds `json:",omitempty"`
+}
+
+//Validate checks if event log is valid
+func (l *EventLog) Validate() error {
+	count := 0
+	for _, candidate := range []string{l.URL, l.Topic, l.Stream} {
+		if candidate != "" {
+			count++
+		}
+	}
+	if count != 1 {
+		return fmt.Errorf("invalid EventLog: exactly one of URL, Topic or Stream is required")
+	}
+	for _, eventType := range l.Events {
+		if !isEventType(eventType) {
+			return fmt.Errorf("invalid EventLog.Events: %v, supported: %v", eventType, strings.Join(eventlog.Types, ", "))
+		}
+	}
+	return nil
+}
+
+//Accepts returns true if event type is logged
+func (l *EventLog) Accepts(eventType string) bool {
+	if len(l.Events) == 0 {
+		return true
+	}
+	for _, candidate := range l.Events {
+		if candidate == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+//IsLogged returns true if URL is within bucket event log location, to avoid event cycle
+func (l *EventLog) IsLogged(URL string) bool {
+	return l.URL != "" && strings.HasPrefix(URL, strings.TrimRight(l.URL, "/")+"/")
+}
+
+//SettleDuration returns bucket segment settle duration
+func (l *EventLog) SettleDuration() time.Duration {
+	if l.Settle > 0 {
+		return l.Settle.Duration()
+	}
+	return defaultEventLogSettle
+}
+
+func isEventType(eventType string) bool {
+	for _, candidate := range eventlog.Types {
+		if candidate == eventType {
+			return true
+		}
+	}
+	return false
+}
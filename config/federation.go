@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/afs/url"
+	"strings"
+)
+
+const (
+	//gcpAudiencePrefix GCP workload identity pool provider audience prefix
+	gcpAudiencePrefix = "//iam.googleapis.com/"
+	//awsRoleARNPrefix AWS IAM role ARN prefix
+	awsRoleARNPrefix = "arn:aws:iam::"
+)
+
+//Federation represents workload identity federation, runtime cloud identity is exchanged for short-lived credentials of the other cloud
+type Federation struct {
+	//Audience gs: workload identity pool provider (//iam.googleapis.com/projects/{number}/locations/global/workloadIdentityPools/{pool}/providers/{provider}),
+	//s3: Google ID token audience trusted by AWS role, default sts.amazonaws.com
+	Audience string `json:",omitempty"`
+	//ServiceAccount optional GCP service account impersonated with federated token (gs)
+	ServiceAccount string `json:",omitempty"`
+	//RoleARN AWS role assumed with Google ID token (s3)
+	RoleARN string `json:",omitempty"`
+	//SessionName optional AWS role session name (s3)
+	SessionName string `json:",omitempty"`
+}
+
+//Validate checks if federation is valid for supplied resource, RoleARN selects AWS credentials, otherwise GCP credentials are used
+func (f *Federation) Validate(resource *Resource) error {
+	if resource.Credentials != nil || resource.ImpersonateServiceAccount != "" {
+		return fmt.Errorf("federation, Credentials and ImpersonateServiceAccount are mutually exclusive")
+	}
+	scheme := "gs"
+	if f.RoleARN != "" {
+		scheme = "s3"
+		if !strings.HasPrefix(f.RoleARN, awsRoleARNPrefix) {
+			return fmt.Errorf("invalid federation RoleARN: %v, expected %v{account}:role/{name}", f.RoleARN, awsRoleARNPrefix)
+		}
+		if f.ServiceAccount != "" {
+			return fmt.Errorf("federation ServiceAccount is only supported with gs:// resource")
+		}
+	} else if !strings.HasPrefix(f.Audience, gcpAudiencePrefix) {
+		return fmt.Errorf("invalid federation audience: %v, expected %v...workloadIdentityPools/{pool}/providers/{provider}", f.Audience, gcpAudiencePrefix)
+	}
+	if resource.URL != "" && url.Scheme(resource.URL, "") != scheme {
+		return fmt.Errorf("federation expected %v:// resource, but had: %v", scheme, resource.URL)
+	}
+	return nil
+}
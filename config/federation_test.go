@@ -0,0 +1,60 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/smirror/auth"
+	"testing"
+)
+
+func TestFederation_Validate(t *testing.T) {
+	audience := "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/aws/providers/mirror"
+	var useCases = []struct {
+		description string
+		Federation
+		resource    *Resource
+		expectError bool
+	}{
+		{
+			description: "gs with pool audience",
+			Federation:  Federation{Audience: audience},
+			resource:    &Resource{URL: "gs://bucket/data"},
+		},
+		{
+			description: "s3 with role",
+			Federation:  Federation{RoleARN: "arn:aws:iam::123456789012:role/mirror"},
+			resource:    &Resource{URL: "s3://bucket/data"},
+		},
+		{
+			description: "source without URL",
+			Federation:  Federation{RoleARN: "arn:aws:iam::123456789012:role/mirror"},
+			resource:    &Resource{},
+		},
+		{
+			description: "role with gs resource",
+			Federation:  Federation{RoleARN: "arn:aws:iam::123456789012:role/mirror"},
+			resource:    &Resource{URL: "gs://bucket/data"},
+			expectError: true,
+		},
+		{
+			description: "invalid audience",
+			Federation:  Federation{Audience: "sts.amazonaws.com"},
+			resource:    &Resource{URL: "gs://bucket/data"},
+			expectError: true,
+		},
+		{
+			description: "with credentials",
+			Federation:  Federation{Audience: audience},
+			resource:    &Resource{URL: "gs://bucket/data", Credentials: &auth.Credentials{}},
+			expectError: true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		err := useCase.Validate(useCase.resource)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/afs/url"
+	"strings"
+)
+
+//KMSKeyName represents GCS customer-managed encryption key (CMEK) storage option applied to uploaded objects
+type KMSKeyName struct {
+	Name string
+}
+
+//NewKMSKeyName creates CMEK key option
+func NewKMSKeyName(name string) *KMSKeyName {
+	return &KMSKeyName{Name: name}
+}
+
+//validateKMSKeyName checks if key name uses projects/{project}/locations/{location}/keyRings/{ring}/cryptoKeys/{key} format
+func validateKMSKeyName(name string) error {
+	elements := strings.Split(name, "/")
+	if len(elements) != 8 || elements[0] != "projects" || elements[2] != "locations" || elements[4] != "keyRings" || elements[6] != "cryptoKeys" {
+		return fmt.Errorf("invalid KMSKeyName: %v, expected projects/{project}/locations/{location}/keyRings/{ring}/cryptoKeys/{key}", name)
+	}
+	return nil
+}
+
+//validateImpersonation checks if impersonated service account is used with gs resource without JSON key credentials
+func (r *Resource) validateImpersonation() error {
+	if !strings.Contains(r.ImpersonateServiceAccount, "@") || !strings.HasSuffix(r.ImpersonateServiceAccount, ".gserviceaccount.com") {
+		return fmt.Errorf("invalid ImpersonateServiceAccount: %v, expected service account email", r.ImpersonateServiceAccount)
+	}
+	if r.Credentials != nil {
+		return fmt.Errorf("ImpersonateServiceAccount and Credentials are mutually exclusive")
+	}
+	if r.URL != "" && url.Scheme(r.URL, "") != "gs" {
+		return fmt.Errorf("ImpersonateServiceAccount is only supported with gs:// resource, but had: %v", r.URL)
+	}
+	return nil
+}
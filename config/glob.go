@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+//CompileGlob compiles source glob into regular expression matching location path without leading slash,
+//supported: ** (any number of folders), * and ? (within folder), [abc], [a-z], [!abc] character classes and {csv,tsv} alternatives
+func CompileGlob(glob string) (*regexp.Regexp, error) {
+	glob = strings.TrimPrefix(glob, "/")
+	expr := new(strings.Builder)
+	expr.WriteString("^")
+	braces := 0
+	for i := 0; i < len(glob); i++ {
+		switch char := glob[i]; char {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					expr.WriteString("(?:.*/)?")
+					continue
+				}
+				expr.WriteString(".*")
+				continue
+			}
+			expr.WriteString("[^/]*")
+		case '?':
+			expr.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == 0 { //] as the first class character
+				end = strings.IndexByte(glob[i+2:], ']') + 1
+			}
+			if end <= 0 {
+				return nil, fmt.Errorf("invalid glob: %v, unclosed character class", glob)
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '{':
+			braces++
+			expr.WriteString("(?:")
+		case '}':
+			if braces == 0 {
+				return nil, fmt.Errorf("invalid glob: %v, unexpected }", glob)
+			}
+			braces--
+			expr.WriteString(")")
+		case ',':
+			if braces > 0 {
+				expr.WriteString("|")
+				continue
+			}
+			expr.WriteString(",")
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				char = glob[i]
+			}
+			expr.WriteString(regexp.QuoteMeta(string(char)))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+	if braces > 0 {
+		return nil, fmt.Errorf("invalid glob: %v, unclosed {", glob)
+	}
+	expr.WriteString("$")
+	compiled, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob: %v, %w", glob, err)
+	}
+	return compiled, nil
+}
+
+//Match returns true if location matches resource prefix, suffix, filter, exclusion and glob, expressions are compiled by Validate,
+//so Match is read only and rules can be matched concurrently (embedded matcher.Basic compiles expressions lazily)
+func (r *Resource) Match(parent string, info os.FileInfo) bool {
+	if r.Directory != nil && *r.Directory != info.IsDir() {
+		return false
+	}
+	location := path.Join(parent, info.Name())
+	if r.Filter != "" {
+		if expr := compiled(r.filterExpr, r.Filter); expr != nil && !expr.MatchString(location) {
+			return false
+		}
+	}
+	if r.Prefix != "" && !strings.HasPrefix(location, r.Prefix) {
+		return false
+	}
+	if r.Suffix != "" && !strings.HasSuffix(location, r.Suffix) {
+		return false
+	}
+	if r.Exclusion != "" {
+		if expr := compiled(r.exclusionExpr, r.Exclusion); expr != nil && expr.MatchString(location) {
+			return false
+		}
+	}
+	if r.Glob == "" {
+		return true
+	}
+	expr := r.compiledGlob
+	if expr == nil { //resource was not validated, glob is compiled without caching
+		if expr, _ = CompileGlob(r.Glob); expr == nil {
+			return false
+		}
+	}
+	return expr.MatchString(strings.TrimPrefix(location, "/"))
+}
+
+//compiled returns compiled expression, for not validated resource pattern is compiled without caching
+func compiled(expr *regexp.Regexp, pattern string) *regexp.Regexp {
+	if expr != nil {
+		return expr
+	}
+	expr, _ = regexp.Compile(pattern)
+	return expr
+}
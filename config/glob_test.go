@@ -0,0 +1,64 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs/file"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCompileGlob(t *testing.T) {
+	var useCases = []struct {
+		description string
+		glob        string
+		location    string
+		expect      bool
+		hasError    bool
+	}{
+		{description: "any folder depth", glob: "incoming/**/{csv,tsv}/*.gz", location: "incoming/2024/01/csv/data.gz", expect: true},
+		{description: "zero folders", glob: "incoming/**/{csv,tsv}/*.gz", location: "incoming/tsv/data.gz", expect: true},
+		{description: "alternative mismatch", glob: "incoming/**/{csv,tsv}/*.gz", location: "incoming/json/data.gz"},
+		{description: "star within folder", glob: "incoming/*.gz", location: "incoming/sub/data.gz"},
+		{description: "leading slash", glob: "/incoming/*.gz", location: "incoming/data.gz", expect: true},
+		{description: "character class", glob: "logs/app[0-9]/?.log", location: "logs/app3/a.log", expect: true},
+		{description: "negated class", glob: "logs/app[!0-9]/*.log", location: "logs/app3/a.log"},
+		{description: "escaped meta", glob: `data/\*.csv`, location: "data/*.csv", expect: true},
+		{description: "dot is literal", glob: "data/*.csv", location: "data/abcxcsv"},
+		{description: "unclosed class", glob: "data/[a-z", hasError: true},
+		{description: "unclosed brace", glob: "data/{a,b", hasError: true},
+	}
+	for _, useCase := range useCases {
+		expr, err := CompileGlob(useCase.glob)
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.EqualValues(t, useCase.expect, expr.MatchString(useCase.location), useCase.description)
+	}
+}
+
+func TestResource_Match(t *testing.T) {
+	resource := &Resource{Glob: "incoming/**/*.gz"}
+	if !assert.Nil(t, resource.Validate()) {
+		return
+	}
+	assert.NotNil(t, resource.compiledGlob)
+	waitGroup := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			assert.True(t, resource.Match("/incoming/2024", file.NewInfo("data.gz", 0, 0644, time.Now(), false)))
+			assert.False(t, resource.Match("/outgoing", file.NewInfo("data.gz", 0, 0644, time.Now(), false)))
+		}()
+	}
+	waitGroup.Wait()
+
+	unvalidated := &Resource{Glob: "incoming/*.gz"}
+	assert.True(t, unvalidated.Match("/incoming", file.NewInfo("data.gz", 0, 0644, time.Now(), false)))
+	assert.Nil(t, unvalidated.compiledGlob)
+}
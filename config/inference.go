@@ -0,0 +1,80 @@
+package config
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/viant/afs/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	defaultInferenceSampleSize = 100
+	defaultInferenceSuffix     = ".schema.json"
+)
+
+var digitsExpr = regexp.MustCompile(`[0-9]+`)
+
+//Inference represents top of file sampling and schema inference report settings
+type Inference struct {
+	//SampleSize number of records sampled from the top of file, default 100
+	SampleSize int `json:",omitempty"`
+	//Format csv or json, rule schema format or source extension by default
+	Format string `json:",omitempty"`
+	//Delimiter CSV delimiter, rule schema delimiter or comma by default
+	Delimiter string `json:",omitempty"`
+	//Header first CSV record holds column names, otherwise columns are named col_<index>
+	Header bool `json:",omitempty"`
+	//ReportSuffix report object suffix appended to destination URL, default .schema.json
+	ReportSuffix string `json:",omitempty"`
+	//StateURL inferred file patterns location, each file pattern is inferred once, empty - every file is inferred
+	StateURL string `json:",omitempty"`
+}
+
+//Init initialises inference with rule schema settings
+func (i *Inference) Init(schema *Schema) {
+	if schema != nil {
+		if i.Format == "" {
+			i.Format = schema.Format
+		}
+		if i.Delimiter == "" {
+			i.Delimiter = schema.Delimiter
+		}
+	}
+	i.Format = strings.ToLower(i.Format)
+	if i.SampleSize == 0 {
+		i.SampleSize = defaultInferenceSampleSize
+	}
+	if i.ReportSuffix == "" {
+		i.ReportSuffix = defaultInferenceSuffix
+	}
+}
+
+//SourceFormat returns sampled records format for source URL
+func (i *Inference) SourceFormat(URL string) string {
+	if i.Format != "" {
+		return i.Format
+	}
+	name := strings.ToLower(URL)
+	for _, ext := range []string{".gz", ".zip"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	switch path.Ext(name) {
+	case ".json", ".jsonl", ".ndjson":
+		return SortJSON
+	}
+	return SortCSV
+}
+
+//FilePattern returns file name pattern with digit runs replaced, i.e. sales_20200101.csv -> sales_{n}.csv
+func FilePattern(URL string) string {
+	_, name := url.Split(URL, "")
+	return digitsExpr.ReplaceAllString(name, "{n}")
+}
+
+//PatternURL returns inferred file pattern state URL
+func (i *Inference) PatternURL(pattern string) string {
+	key := md5.Sum([]byte(pattern))
+	return url.Join(i.StateURL, hex.EncodeToString(key[:]))
+}
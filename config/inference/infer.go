@@ -0,0 +1,260 @@
+package inference
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	//TypeNull no non empty value was sampled
+	TypeNull = "null"
+	//TypeBoolean boolean type
+	TypeBoolean = "boolean"
+	//TypeInteger integer type
+	TypeInteger = "integer"
+	//TypeFloat floating point type
+	TypeFloat = "float"
+	//TypeDate date type
+	TypeDate = "date"
+	//TypeTimestamp timestamp type
+	TypeTimestamp = "timestamp"
+	//TypeRecord nested JSON object
+	TypeRecord = "record"
+	//TypeArray JSON array
+	TypeArray = "array"
+	//TypeString string type
+	TypeString = "string"
+
+	maxExamples = 3
+)
+
+var dateLayouts = []string{"2006-01-02", "2006/01/02", "01/02/2006"}
+
+var timestampLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02 15:04:05.000", "2006-01-02 15:04:05 MST"}
+
+//Report represents schema inference report
+type Report struct {
+	Pattern   string
+	SourceURL string
+	Format    string
+	Records   int
+	Columns   []*Column
+	Inferred  time.Time
+}
+
+//Column represents inferred column
+type Column struct {
+	Name     string
+	Type     string
+	Nullable bool     `json:",omitempty"`
+	Examples []string `json:",omitempty"`
+	types    map[string]int
+}
+
+func (c *Column) observe(value interface{}) {
+	kind := valueType(value)
+	if kind == TypeNull {
+		c.Nullable = true
+		return
+	}
+	c.types[kind]++
+	if len(c.Examples) < maxExamples {
+		example := fmt.Sprintf("%v", value)
+		if kind == TypeRecord || kind == TypeArray {
+			data, _ := json.Marshal(value)
+			example = string(data)
+		}
+		c.Examples = append(c.Examples, example)
+	}
+}
+
+//resolve returns the narrowest type covering all sampled values
+func (c *Column) resolve() {
+	switch len(c.types) {
+	case 0:
+		c.Type = TypeNull
+		return
+	case 1:
+		for kind := range c.types {
+			c.Type = kind
+		}
+		return
+	}
+	if len(c.types) == 2 && c.types[TypeInteger] > 0 && c.types[TypeFloat] > 0 {
+		c.Type = TypeFloat
+		return
+	}
+	if len(c.types) == 2 && c.types[TypeDate] > 0 && c.types[TypeTimestamp] > 0 {
+		c.Type = TypeTimestamp
+		return
+	}
+	c.Type = TypeString
+}
+
+func valueType(value interface{}) string {
+	switch actual := value.(type) {
+	case nil:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case json.Number:
+		if _, err := actual.Int64(); err == nil {
+			return TypeInteger
+		}
+		return TypeFloat
+	case map[string]interface{}:
+		return TypeRecord
+	case []interface{}:
+		return TypeArray
+	case string:
+		return textType(actual)
+	}
+	return TypeString
+}
+
+func textType(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return TypeNull
+	}
+	if _, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return TypeInteger
+	}
+	if _, err := strconv.ParseFloat(text, 64); err == nil {
+		return TypeFloat
+	}
+	switch strings.ToLower(text) {
+	case "true", "false":
+		return TypeBoolean
+	}
+	for _, layout := range dateLayouts {
+		if _, err := time.Parse(layout, text); err == nil {
+			return TypeDate
+		}
+	}
+	for _, layout := range timestampLayouts {
+		if _, err := time.Parse(layout, text); err == nil {
+			return TypeTimestamp
+		}
+	}
+	return TypeString
+}
+
+type builder struct {
+	report  *Report
+	columns map[string]*Column
+}
+
+func (b *builder) column(name string) *Column {
+	column, ok := b.columns[name]
+	if !ok {
+		column = &Column{Name: name, types: make(map[string]int)}
+		b.columns[name] = column
+		b.report.Columns = append(b.report.Columns, column)
+	}
+	return column
+}
+
+func (b *builder) build() *Report {
+	for _, column := range b.report.Columns {
+		column.resolve()
+		//columns missing in some records are nullable
+		if column.types != nil && sum(column.types) < b.report.Records {
+			column.Nullable = true
+		}
+	}
+	return b.report
+}
+
+func sum(counts map[string]int) int {
+	result := 0
+	for _, count := range counts {
+		result += count
+	}
+	return result
+}
+
+func (b *builder) readCSV(reader io.Reader, delimiter string, header bool, sampleSize int) error {
+	csvReader := csv.NewReader(reader)
+	if delimiter != "" {
+		csvReader.Comma = rune(delimiter[0])
+	}
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	var names []string
+	for b.report.Records < sampleSize {
+		fields, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read CSV record")
+		}
+		if header && names == nil {
+			names = fields
+			for _, name := range names {
+				b.column(strings.TrimSpace(name))
+			}
+			continue
+		}
+		b.report.Records++
+		for i, field := range fields {
+			name := fmt.Sprintf("col_%v", i)
+			if i < len(names) {
+				name = strings.TrimSpace(names[i])
+			}
+			b.column(name).observe(field)
+		}
+	}
+	return nil
+}
+
+func (b *builder) readJSON(reader io.Reader, sampleSize int) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for b.report.Records < sampleSize && scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		decoder := json.NewDecoder(strings.NewReader(line))
+		decoder.UseNumber()
+		record := map[string]interface{}{}
+		if err := decoder.Decode(&record); err != nil {
+			return errors.Wrapf(err, "failed to decode JSON record: %v", line)
+		}
+		b.report.Records++
+		names := make([]string, 0, len(record))
+		for name := range record {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			b.column(name).observe(record[name])
+		}
+	}
+	return scanner.Err()
+}
+
+//Infer samples up to sampleSize top records and infers column names and types
+func Infer(reader io.Reader, format, delimiter string, header bool, sampleSize int) (*Report, error) {
+	b := &builder{report: &Report{Format: format, Inferred: time.Now().UTC()}, columns: make(map[string]*Column)}
+	var err error
+	if strings.ToLower(format) == "json" {
+		err = b.readJSON(reader, sampleSize)
+	} else {
+		err = b.readCSV(reader, delimiter, header, sampleSize)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return b.build(), nil
+}
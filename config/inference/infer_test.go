@@ -0,0 +1,61 @@
+package inference
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestInfer(t *testing.T) {
+	var useCases = []struct {
+		description string
+		format      string
+		header      bool
+		input       string
+		sampleSize  int
+		expect      map[string]string
+		nullable    []string
+	}{
+		{
+			description: "csv with header",
+			format:      "csv",
+			header:      true,
+			input:       "id,amount,created,active,name\n1,2.5,2020-01-01,true,a\n2,3,2020-01-02 10:00:00,false,\n3,x,2020-01-03,true,c",
+			sampleSize:  2,
+			expect:      map[string]string{"id": TypeInteger, "amount": TypeFloat, "created": TypeTimestamp, "active": TypeBoolean, "name": TypeString},
+			nullable:    []string{"name"},
+		},
+		{
+			description: "csv without header",
+			format:      "csv",
+			input:       "1,a\n2,b",
+			sampleSize:  10,
+			expect:      map[string]string{"col_0": TypeInteger, "col_1": TypeString},
+		},
+		{
+			description: "json",
+			format:      "json",
+			input:       "{\"id\":1,\"tags\":[\"a\"],\"meta\":{\"k\":1},\"ts\":\"2020-01-01T10:00:00Z\"}\n{\"id\":2.5,\"ts\":null}",
+			sampleSize:  10,
+			expect:      map[string]string{"id": TypeFloat, "tags": TypeArray, "meta": TypeRecord, "ts": TypeTimestamp},
+			nullable:    []string{"meta", "tags", "ts"},
+		},
+	}
+
+	for _, useCase := range useCases {
+		report, err := Infer(strings.NewReader(useCase.input), useCase.format, "", useCase.header, useCase.sampleSize)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		actual := map[string]string{}
+		var nullable []string
+		for _, column := range report.Columns {
+			actual[column.Name] = column.Type
+			if column.Nullable {
+				nullable = append(nullable, column.Name)
+			}
+		}
+		assert.Equal(t, useCase.expect, actual, useCase.description)
+		assert.Equal(t, useCase.nullable, nullable, useCase.description)
+	}
+}
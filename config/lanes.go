@@ -0,0 +1,53 @@
+package config
+
+import "github.com/pkg/errors"
+
+const (
+	//PriorityHigh high priority lane, i.e. manifests, done markers
+	PriorityHigh = "high"
+	//PriorityNormal default lane
+	PriorityNormal = "normal"
+	//PriorityLow low priority lane, i.e. backfills
+	PriorityLow = "low"
+)
+
+//Lanes represents priority lanes, each lane has own concurrent transfer budget
+type Lanes struct {
+	//MaxConcurrent max concurrent transfers per lane (high, normal, low), 0 or missing - no limit
+	MaxConcurrent map[string]int `json:",omitempty"`
+	//SmallFileBytes objects smaller than this size use high lane regardless of rule priority, 0 - disabled
+	SmallFileBytes int64 `json:",omitempty"`
+}
+
+//IsValidPriority returns true if priority is empty or a known lane
+func IsValidPriority(priority string) bool {
+	switch priority {
+	case "", PriorityHigh, PriorityNormal, PriorityLow:
+		return true
+	}
+	return false
+}
+
+//Validate checks if lanes are valid
+func (l *Lanes) Validate() error {
+	for priority, max := range l.MaxConcurrent {
+		if priority == "" || !IsValidPriority(priority) {
+			return errors.Errorf("invalid lane priority: %v", priority)
+		}
+		if max < 0 {
+			return errors.Errorf("invalid %v lane max concurrent: %v", priority, max)
+		}
+	}
+	return nil
+}
+
+//Lane returns lane for rule priority and object size
+func (l *Lanes) Lane(priority string, size int64) string {
+	if l.SmallFileBytes > 0 && size < l.SmallFileBytes {
+		return PriorityHigh
+	}
+	if priority == "" {
+		return PriorityNormal
+	}
+	return priority
+}
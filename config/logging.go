@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/smirror/shared"
+)
+
+//Logging represents rule response logging verbosity and sampling, overriding LOGGING env variable level
+type Logging struct {
+	//Level off, error (failed responses only), info or debug (transfer steps, no sampling), default LOGGING env variable level
+	Level string `json:",omitempty"`
+	//SampleRate fraction of successful responses logged at info level, i.e. 0.01, default 1
+	SampleRate float64 `json:",omitempty"`
+}
+
+//Validate checks if logging settings are valid
+func (l *Logging) Validate() error {
+	switch l.Level {
+	case "", shared.LoggingLevelOff, shared.LoggingLevelError, shared.LoggingLevelInfo, shared.LoggingLevelDebug:
+	default:
+		return fmt.Errorf("invalid level: %v, supported: off, error, info, debug", l.Level)
+	}
+	if l.SampleRate < 0 || l.SampleRate > 1 {
+		return fmt.Errorf("invalid sampleRate: %v, expected 0..1", l.SampleRate)
+	}
+	return nil
+}
+
+//LogLevel returns rule log level
+func (l *Logging) LogLevel() string {
+	if l.Level == "" {
+		return shared.LoggingLevel()
+	}
+	return l.Level
+}
+
+//IsDebug returns true if rule transfer steps are logged
+func (l *Logging) IsDebug() bool {
+	return l.LogLevel() == shared.LoggingLevelDebug
+}
+
+//Accepts returns true if response is logged, sample is a random number in [0, 1)
+func (l *Logging) Accepts(failed bool, sample float64) bool {
+	switch l.LogLevel() {
+	case shared.LoggingLevelOff:
+		return false
+	case shared.LoggingLevelError:
+		return failed
+	case shared.LoggingLevelDebug:
+		return true
+	}
+	return failed || l.SampleRate == 0 || sample < l.SampleRate
+}
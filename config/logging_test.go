@@ -0,0 +1,38 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/smirror/shared"
+	"os"
+	"testing"
+)
+
+func TestLogging_Accepts(t *testing.T) {
+	var useCases = []struct {
+		description string
+		env         string
+		logging     Logging
+		failed      bool
+		sample      float64
+		expect      bool
+	}{
+		{description: "default info", expect: true},
+		{description: "env off", env: "false", failed: true, expect: false},
+		{description: "rule overrides env", env: "off", logging: Logging{Level: shared.LoggingLevelInfo}, expect: true},
+		{description: "error level skips success", logging: Logging{Level: shared.LoggingLevelError}, expect: false},
+		{description: "error level logs failure", logging: Logging{Level: shared.LoggingLevelError}, failed: true, expect: true},
+		{description: "sampled in", logging: Logging{SampleRate: 0.1}, sample: 0.05, expect: true},
+		{description: "sampled out", logging: Logging{SampleRate: 0.1}, sample: 0.5, expect: false},
+		{description: "failure is not sampled", logging: Logging{SampleRate: 0.1}, sample: 0.5, failed: true, expect: true},
+		{description: "debug is not sampled", logging: Logging{Level: shared.LoggingLevelDebug, SampleRate: 0.1}, sample: 0.5, expect: true},
+		{description: "rule off", logging: Logging{Level: shared.LoggingLevelOff}, failed: true, expect: false},
+	}
+	defer os.Unsetenv(shared.LoggingEnvKey)
+	for _, useCase := range useCases {
+		_ = os.Setenv(shared.LoggingEnvKey, useCase.env)
+		assert.Nil(t, useCase.logging.Validate(), useCase.description)
+		assert.Equal(t, useCase.expect, useCase.logging.Accepts(useCase.failed, useCase.sample), useCase.description)
+	}
+	assert.NotNil(t, (&Logging{Level: "verbose"}).Validate())
+	assert.NotNil(t, (&Logging{SampleRate: 2}).Validate())
+}
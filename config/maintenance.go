@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/smirror/base"
+	"time"
+)
+
+const (
+	maxMaintenanceDuration  = 7 * 24 * time.Hour
+	maintenanceMarkerPrefix = "maintenance"
+)
+
+//Maintenance represents recurring dest maintenance window, transfers to dest are deferred while the window is open
+type Maintenance struct {
+	//Cron window start cron expression (minute hour day-of-month month day-of-week), i.e. 0 2 * * 0
+	Cron string
+	//Duration window duration, number of seconds or duration text, i.e. 2h
+	Duration base.Seconds
+	//TimeZone cron expression time zone, default UTC
+	TimeZone string `json:",omitempty"`
+	//Reason optional window description
+	Reason   string `json:",omitempty"`
+	schedule *Schedule
+	location *time.Location
+}
+
+//Validate checks if maintenance window is valid
+func (m *Maintenance) Validate() error {
+	if m.Cron == "" {
+		return fmt.Errorf("maintenance.Cron was empty")
+	}
+	if m.Duration <= 0 || m.Duration.Duration() > maxMaintenanceDuration {
+		return fmt.Errorf("invalid maintenance.Duration: %v, expected up to %v", m.Duration.Duration(), maxMaintenanceDuration)
+	}
+	return m.init()
+}
+
+func (m *Maintenance) init() (err error) {
+	if m.schedule != nil {
+		return nil
+	}
+	m.location = time.UTC
+	if m.TimeZone != "" {
+		if m.location, err = time.LoadLocation(m.TimeZone); err != nil {
+			return fmt.Errorf("invalid maintenance.TimeZone: %v, %v", m.TimeZone, err)
+		}
+	}
+	m.schedule, err = NewSchedule(m.Cron)
+	return err
+}
+
+//End returns end of the window open at supplied time, ok is false if window is closed
+func (m *Maintenance) End(now time.Time) (end time.Time, ok bool) {
+	if m.init() != nil {
+		return end, false
+	}
+	now = now.In(m.location)
+	duration := m.Duration.Duration()
+	//the latest matching start closes the window last
+	for start := now.Truncate(time.Minute); now.Sub(start) < duration; start = start.Add(-time.Minute) {
+		if m.schedule.Match(start) {
+			return start.Add(duration), true
+		}
+	}
+	return end, false
+}
+
+//MaintenanceWindow returns the open maintenance window with the latest end, or nil if dest is not in maintenance
+func (r *Resource) MaintenanceWindow(now time.Time) (*Maintenance, time.Time) {
+	var result *Maintenance
+	var resultEnd time.Time
+	for _, window := range r.Maintenance {
+		if end, ok := window.End(now); ok && end.After(resultEnd) {
+			result, resultEnd = window, end
+		}
+	}
+	return result, resultEnd
+}
+
+//MaintenanceMarker returns defer marker name for rule dest maintenance
+func MaintenanceMarker(workflow string) string {
+	if workflow == "" {
+		return maintenanceMarkerPrefix
+	}
+	return maintenanceMarkerPrefix + "-" + workflow
+}
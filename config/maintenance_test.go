@@ -0,0 +1,86 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSchedule_Match(t *testing.T) {
+	var useCases = []struct {
+		description string
+		expr        string
+		time        string
+		expect      bool
+		expectError bool
+	}{
+		{description: "every minute", expr: "* * * * *", time: "2026-10-15T10:17:00Z", expect: true},
+		{description: "daily match", expr: "30 2 * * *", time: "2026-10-15T02:30:00Z", expect: true},
+		{description: "daily mismatch", expr: "30 2 * * *", time: "2026-10-15T02:31:00Z"},
+		{description: "step", expr: "*/15 * * * *", time: "2026-10-15T02:45:00Z", expect: true},
+		{description: "list and range", expr: "0 1,3-5 * * *", time: "2026-10-15T04:00:00Z", expect: true},
+		{description: "sunday as 7", expr: "0 2 * * 7", time: "2026-10-18T02:00:00Z", expect: true},
+		{description: "weekday range", expr: "0 2 * * 1-5", time: "2026-10-18T02:00:00Z"},
+		{description: "day of month or week", expr: "0 0 1 * 4", time: "2026-10-15T00:00:00Z", expect: true},
+		{description: "macro", expr: "@daily", time: "2026-10-15T00:00:00Z", expect: true},
+		{description: "invalid fields", expr: "0 2 * *", expectError: true},
+		{description: "out of range", expr: "60 * * * *", expectError: true},
+	}
+	for _, useCase := range useCases {
+		schedule, err := NewSchedule(useCase.expr)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		at, _ := time.Parse(time.RFC3339, useCase.time)
+		assert.Equal(t, useCase.expect, schedule.Match(at), useCase.description)
+	}
+}
+
+func TestMaintenance_End(t *testing.T) {
+	var useCases = []struct {
+		description string
+		Maintenance
+		time        string
+		expectEnd   string
+	}{
+		{
+			description: "open window",
+			Maintenance: Maintenance{Cron: "0 2 * * *", Duration: 7200},
+			time:        "2026-10-15T03:10:00Z",
+			expectEnd:   "2026-10-15T04:00:00Z",
+		},
+		{
+			description: "closed window",
+			Maintenance: Maintenance{Cron: "0 2 * * *", Duration: 7200},
+			time:        "2026-10-15T04:00:00Z",
+		},
+		{
+			description: "window spanning midnight",
+			Maintenance: Maintenance{Cron: "0 23 * * *", Duration: 3 * 3600},
+			time:        "2026-10-16T01:00:00Z",
+			expectEnd:   "2026-10-16T02:00:00Z",
+		},
+		{
+			description: "time zone",
+			Maintenance: Maintenance{Cron: "0 2 * * *", Duration: 3600, TimeZone: "America/New_York"},
+			time:        "2026-10-15T06:30:00Z",
+			expectEnd:   "2026-10-15T07:00:00Z",
+		},
+	}
+	for _, useCase := range useCases {
+		window := useCase.Maintenance
+		if !assert.Nil(t, window.Validate(), useCase.description) {
+			continue
+		}
+		at, _ := time.Parse(time.RFC3339, useCase.time)
+		end, ok := window.End(at)
+		assert.Equal(t, useCase.expectEnd != "", ok, useCase.description)
+		if ok {
+			assert.Equal(t, useCase.expectEnd, end.UTC().Format(time.RFC3339), useCase.description)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/smirror/base"
+	"time"
+)
+
+const (
+	defaultBatchMaxParts = 100
+	defaultBatchMaxAge   = 5 * time.Second
+	//defaultBatchMaxBytes stays below Pub/Sub 10MB message limit
+	defaultBatchMaxBytes = 8 * 1024 * 1024
+	//defaultQueueBatchMaxBytes stays below SQS 256KB message limit
+	defaultQueueBatchMaxBytes = 192 * 1024
+)
+
+//MessageBatch represents topic or queue dest batching, split parts published to the same message destination within a transfer
+//are coalesced into manifest messages
+type MessageBatch struct {
+	//MaxParts max number of parts per manifest message, default 100
+	MaxParts int `json:",omitempty"`
+	//MaxBytes max encoded manifest message size, number of bytes or size text, default 8MB for topic, 192KB for queue
+	MaxBytes base.Bytes `json:",omitempty"`
+	//MaxAge max time parts wait for manifest message, number of milliseconds or duration text, default 5s
+	MaxAge base.Milliseconds `json:",omitempty"`
+}
+
+//Validate checks if message batch settings are valid for supplied dest
+func (b *MessageBatch) Validate(resource *Resource) error {
+	if resource.Topic == "" && resource.Queue == "" {
+		return fmt.Errorf("invalid MessageBatch: dest Topic or Queue was empty")
+	}
+	if resource.Azure != nil {
+		return fmt.Errorf("invalid MessageBatch: Azure dest is not supported")
+	}
+	if b.MaxParts < 0 || b.MaxBytes < 0 || b.MaxAge < 0 {
+		return fmt.Errorf("invalid MessageBatch: MaxParts, MaxBytes and MaxAge can not be negative")
+	}
+	return nil
+}
+
+//MaxPartsCount returns max number of parts per manifest message
+func (b *MessageBatch) MaxPartsCount() int {
+	if b.MaxParts > 0 {
+		return b.MaxParts
+	}
+	return defaultBatchMaxParts
+}
+
+//MaxBytesSize returns max encoded manifest message size
+func (b *MessageBatch) MaxBytesSize(resource *Resource) int {
+	if b.MaxBytes > 0 {
+		return int(b.MaxBytes)
+	}
+	if resource.Topic == "" {
+		return defaultQueueBatchMaxBytes
+	}
+	return defaultBatchMaxBytes
+}
+
+//MaxAgeDuration returns max time parts wait for manifest message
+func (b *MessageBatch) MaxAgeDuration() time.Duration {
+	if b.MaxAge > 0 {
+		return b.MaxAge.Duration()
+	}
+	return defaultBatchMaxAge
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/smirror/base"
+	"testing"
+	"time"
+)
+
+func TestMessageBatch_Validate(t *testing.T) {
+	var useCases = []struct {
+		description string
+		resource    *Resource
+		hasError    bool
+	}{
+		{description: "topic", resource: &Resource{Topic: "topic", MessageBatch: &MessageBatch{}}},
+		{description: "queue", resource: &Resource{Queue: "queue", MessageBatch: &MessageBatch{MaxParts: 10}}},
+		{description: "storage dest", resource: &Resource{URL: "gs://bucket/data", MessageBatch: &MessageBatch{}}, hasError: true},
+		{description: "negative max parts", resource: &Resource{Topic: "topic", MessageBatch: &MessageBatch{MaxParts: -1}}, hasError: true},
+	}
+	for _, useCase := range useCases {
+		err := useCase.resource.MessageBatch.Validate(useCase.resource)
+		assert.Equal(t, useCase.hasError, err != nil, useCase.description)
+	}
+}
+
+func TestMessageBatch_Defaults(t *testing.T) {
+	batch := &MessageBatch{}
+	assert.Equal(t, defaultBatchMaxParts, batch.MaxPartsCount())
+	assert.Equal(t, defaultBatchMaxAge, batch.MaxAgeDuration())
+	assert.Equal(t, defaultBatchMaxBytes, batch.MaxBytesSize(&Resource{Topic: "topic"}))
+	assert.Equal(t, defaultQueueBatchMaxBytes, batch.MaxBytesSize(&Resource{Queue: "queue"}))
+	batch = &MessageBatch{MaxBytes: base.Bytes(1024), MaxAge: base.Milliseconds(200)}
+	assert.Equal(t, 1024, batch.MaxBytesSize(&Resource{Topic: "topic"}))
+	assert.Equal(t, 200*time.Millisecond, batch.MaxAgeDuration())
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+)
+
+const (
+	//EmitterStatsD plain StatsD emitter, rule is a metric name segment
+	EmitterStatsD = "statsd"
+	//EmitterDogStatsD DogStatsD emitter, rule, status and labels are metric tags
+	EmitterDogStatsD = "dogstatsd"
+
+	defaultMetricsPrefix = "smirror"
+	defaultStatsDHost    = "127.0.0.1"
+	defaultStatsDPort    = "8125"
+)
+
+//Metrics represents push based metrics emitter
+type Metrics struct {
+	//Emitter statsd or dogstatsd
+	Emitter string
+	//Address agent host:port, default DD_AGENT_HOST:DD_DOGSTATSD_PORT env variables or 127.0.0.1:8125
+	Address string `json:",omitempty"`
+	//Prefix metric name prefix, default smirror
+	Prefix string `json:",omitempty"`
+}
+
+//Init initialises metrics defaults
+func (m *Metrics) Init() {
+	if m.Prefix == "" {
+		m.Prefix = defaultMetricsPrefix
+	}
+	if m.Address != "" {
+		return
+	}
+	host, port := os.Getenv("DD_AGENT_HOST"), os.Getenv("DD_DOGSTATSD_PORT")
+	if host == "" {
+		host = defaultStatsDHost
+	}
+	if port == "" {
+		port = defaultStatsDPort
+	}
+	m.Address = net.JoinHostPort(host, port)
+}
+
+//Validate checks if metrics emitter is valid
+func (m *Metrics) Validate() error {
+	if m.Emitter != EmitterStatsD && m.Emitter != EmitterDogStatsD {
+		return fmt.Errorf("invalid Metrics.Emitter: %v, supported: %v, %v", m.Emitter, EmitterStatsD, EmitterDogStatsD)
+	}
+	if _, _, err := net.SplitHostPort(m.Address); err != nil {
+		return fmt.Errorf("invalid Metrics.Address: %v, %w", m.Address, err)
+	}
+	return nil
+}
+
+//IsTagged returns true if emitter supports tags
+func (m *Metrics) IsTagged() bool {
+	return m.Emitter == EmitterDogStatsD
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/toolbox"
+)
+
+//CurrentRuleVersion current rule file layout version, rules without Version use version 1 layout
+const CurrentRuleVersion = 2
+
+//ruleMigration upgrades decoded rule layout to version, it returns deprecation warnings
+type ruleMigration struct {
+	version int
+	migrate func(rule interface{}) []string
+}
+
+//ruleMigrations ordered rule layout migrations
+var ruleMigrations = []*ruleMigration{
+	{version: 2, migrate: migrateRuleV2},
+}
+
+//migrateRule upgrades decoded rule (JSON or YAML map) to CurrentRuleVersion layout, it returns deprecation warnings
+func migrateRule(rule interface{}) ([]string, error) {
+	version := 1
+	if value, ok := mapValue(rule, "Version"); ok {
+		version = toolbox.AsInt(value)
+	}
+	if version < 1 || version > CurrentRuleVersion {
+		return nil, fmt.Errorf("unsupported rule version: %v, supported: 1..%v", version, CurrentRuleVersion)
+	}
+	if version == CurrentRuleVersion {
+		return nil, nil
+	}
+	var warnings []string
+	for _, migration := range ruleMigrations {
+		if version >= migration.version {
+			continue
+		}
+		warnings = append(warnings, migration.migrate(rule)...)
+		version = migration.version
+	}
+	setMapValue(rule, "Version", CurrentRuleVersion)
+	return warnings, nil
+}
+
+//migrateRules upgrades decoded rules, warnings are prefixed with rule index
+func migrateRules(rules []interface{}) ([]string, error) {
+	var warnings []string
+	for i, rule := range rules {
+		ruleWarnings, err := migrateRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rule[%v]: %w", i, err)
+		}
+		for _, warning := range ruleWarnings {
+			warnings = append(warnings, fmt.Sprintf("rule[%v]: %v", i, warning))
+		}
+	}
+	return warnings, nil
+}
+
+//migrateRuleV2 renames resource Params to Parameters and flattens nested Compression into rule Codec and Uncompress
+func migrateRuleV2(rule interface{}) []string {
+	var warnings []string
+	for _, name := range []string{"Source", "Dest"} {
+		resource, ok := mapValue(rule, name)
+		if !ok {
+			continue
+		}
+		if renameMapKey(resource, "Params", "Parameters") {
+			warnings = append(warnings, fmt.Sprintf("%v.Params is deprecated, use %v.Parameters", name, name))
+		}
+	}
+	if compression, ok := mapValue(rule, "Compression"); ok {
+		for _, key := range []string{"Codec", "Uncompress"} {
+			if value, has := mapValue(compression, key); has {
+				if _, exists := mapValue(rule, key); !exists {
+					setMapValue(rule, key, value)
+				}
+			}
+		}
+		deleteMapKey(rule, "Compression")
+		warnings = append(warnings, "Compression is deprecated, use rule Codec and Uncompress")
+	}
+	return warnings
+}
+
+//renameMapKey moves from key value to to key unless it is already set, it returns true if from key was present
+func renameMapKey(source interface{}, from, to string) bool {
+	value, ok := mapValue(source, from)
+	if !ok {
+		return false
+	}
+	if _, exists := mapValue(source, to); !exists {
+		setMapValue(source, to, value)
+	}
+	deleteMapKey(source, from)
+	return true
+}
+
+//mapValue returns JSON (string keyed) or YAML (interface keyed) map value
+func mapValue(source interface{}, key string) (interface{}, bool) {
+	switch actual := source.(type) {
+	case map[string]interface{}:
+		value, ok := actual[key]
+		return value, ok
+	case map[interface{}]interface{}:
+		value, ok := actual[key]
+		return value, ok
+	}
+	return nil, false
+}
+
+func setMapValue(source interface{}, key string, value interface{}) {
+	switch actual := source.(type) {
+	case map[string]interface{}:
+		actual[key] = value
+	case map[interface{}]interface{}:
+		actual[key] = value
+	}
+}
+
+func deleteMapKey(source interface{}, key string) {
+	switch actual := source.(type) {
+	case map[string]interface{}:
+		delete(actual, key)
+	case map[interface{}]interface{}:
+		delete(actual, key)
+	}
+}
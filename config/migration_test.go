@@ -0,0 +1,70 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLoadRules_Migration(t *testing.T) {
+	var useCases = []struct {
+		description    string
+		ext            string
+		data           string
+		expectCodec    string
+		expectParams   int
+		expectWarnings []string
+		expectError    bool
+	}{
+		{
+			description:    "JSON legacy layout",
+			ext:            ".json",
+			data:           `{"Source":{"Prefix":"/data"},"Dest":{"URL":"gs://bucket/$x","Pattern":"(.+)","Params":[{"Name":"x","Expression":"$1"}]},"Compression":{"Codec":"gzip"}}`,
+			expectCodec:    "gzip",
+			expectParams:   1,
+			expectWarnings: []string{"Dest.Params is deprecated, use Dest.Parameters", "Compression is deprecated, use rule Codec and Uncompress"},
+		},
+		{
+			description:    "JSON rules legacy layout",
+			ext:            ".json",
+			data:           `[{"Source":{"Prefix":"/data"},"Dest":{"URL":"gs://bucket"}},{"Source":{"Prefix":"/data"},"Dest":{"URL":"gs://bucket"},"Compression":{"Codec":"gzip"}}]`,
+			expectWarnings: []string{"rule[1]: Compression is deprecated, use rule Codec and Uncompress"},
+		},
+		{
+			description:    "YAML legacy layout",
+			ext:            ".yaml",
+			data:           "Source:\n  Prefix: /data\nDest:\n  URL: gs://bucket\n  Params:\n    - Name: x\n      Expression: $1\n",
+			expectParams:   1,
+			expectWarnings: []string{"Dest.Params is deprecated, use Dest.Parameters"},
+		},
+		{
+			description: "current layout",
+			ext:         ".json",
+			data:        `{"Version":2,"Source":{"Prefix":"/data"},"Dest":{"URL":"gs://bucket"},"Codec":"gzip"}`,
+			expectCodec: "gzip",
+		},
+		{
+			description: "unsupported version",
+			ext:         ".json",
+			data:        `{"Version":3,"Source":{"Prefix":"/data"},"Dest":{"URL":"gs://bucket"}}`,
+			expectError: true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		rules, warnings, err := loadRules([]byte(useCase.data), useCase.ext)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.EqualValues(t, useCase.expectWarnings, warnings, useCase.description)
+		rule := rules[len(rules)-1]
+		assert.EqualValues(t, CurrentRuleVersion, rule.Version, useCase.description)
+		if useCase.expectCodec != "" && assert.NotNil(t, rule.Compression, useCase.description) {
+			assert.EqualValues(t, useCase.expectCodec, rule.Codec, useCase.description)
+		}
+		assert.EqualValues(t, useCase.expectParams, len(rule.Dest.Parameters), useCase.description)
+	}
+}
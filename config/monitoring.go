@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/smirror/base"
+	"os"
+	"time"
+)
+
+const (
+	defaultMonitoringPrefix   = "custom.googleapis.com/smirror"
+	defaultMonitoringLocation = "global"
+	defaultMonitoringFlush    = time.Minute
+	//minMonitoringFlush Cloud Monitoring accepts one point per time series every 5 seconds
+	minMonitoringFlush = 10 * time.Second
+)
+
+//Monitoring represents Cloud Monitoring (Stackdriver) custom metrics export
+type Monitoring struct {
+	//ProjectID metrics project, default config project
+	ProjectID string `json:",omitempty"`
+	//Prefix metric type prefix, default custom.googleapis.com/smirror
+	Prefix string `json:",omitempty"`
+	//Function job resource label, default K_SERVICE, FUNCTION_NAME or FUNCTION_TARGET env variable
+	Function string `json:",omitempty"`
+	//Region location resource label, default config region or global
+	Region string `json:",omitempty"`
+	//FlushSec min interval between metrics writes, default 60, min 10
+	FlushSec base.Seconds `json:",omitempty"`
+}
+
+//Init initialises monitoring defaults
+func (m *Monitoring) Init(projectID, region string) {
+	if m.ProjectID == "" {
+		m.ProjectID = projectID
+	}
+	if m.Prefix == "" {
+		m.Prefix = defaultMonitoringPrefix
+	}
+	if m.Region == "" {
+		m.Region = region
+	}
+	if m.Region == "" {
+		m.Region = defaultMonitoringLocation
+	}
+	for _, key := range []string{"K_SERVICE", "FUNCTION_NAME", "FUNCTION_TARGET"} {
+		if m.Function != "" {
+			break
+		}
+		m.Function = os.Getenv(key)
+	}
+}
+
+//Validate checks if monitoring settings are valid
+func (m *Monitoring) Validate() error {
+	if m.ProjectID == "" {
+		return fmt.Errorf("invalid Monitoring: ProjectID was empty")
+	}
+	if m.FlushSec < 0 {
+		return fmt.Errorf("invalid Monitoring.FlushSec: %v", m.FlushSec)
+	}
+	return nil
+}
+
+//FlushInterval returns min interval between metrics writes
+func (m *Monitoring) FlushInterval() time.Duration {
+	if m.FlushSec <= 0 {
+		return defaultMonitoringFlush
+	}
+	if interval := m.FlushSec.Duration(); interval > minMonitoringFlush {
+		return interval
+	}
+	return minMonitoringFlush
+}
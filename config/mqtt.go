@@ -0,0 +1,55 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"github.com/viant/afs/url"
+	"path"
+	"strings"
+)
+
+//MQTT represents MQTT destination, resource URL is broker URL, i.e. mqtt://host:1883 or mqtts://host:8883 for TLS
+type MQTT struct {
+	//Topic topic template, $partition and $name (source file name without extension) are expanded
+	Topic string
+	//QoS quality of service: 0 - at most once, 1 - at least once, 2 - exactly once
+	QoS byte `json:",omitempty"`
+	//Retain flags messages as retained
+	Retain bool `json:",omitempty"`
+	//DataMaxBytes files (or split parts) up to this size are published as payload, larger ones as file arrival events, 0 - always events
+	DataMaxBytes int64 `json:",omitempty"`
+	//ClientID optional client ID, random smirror-<id> is used otherwise
+	ClientID string `json:",omitempty"`
+}
+
+//Validate checks if mqtt destination is valid
+func (m *MQTT) Validate(resource *Resource) error {
+	if m.Topic == "" {
+		return errors.New("mqtt.Topic was empty")
+	}
+	if strings.ContainsAny(m.Topic, "+#") {
+		return errors.Errorf("invalid mqtt.Topic: %v, wildcards are not allowed", m.Topic)
+	}
+	if m.QoS > 2 {
+		return errors.Errorf("invalid mqtt.QoS: %v", m.QoS)
+	}
+	switch url.Scheme(resource.URL, "") {
+	case "mqtt", "tcp", "mqtts", "ssl", "tls":
+	default:
+		return errors.Errorf("unsupported mqtt URL: %v, expected mqtt:// or mqtts:// scheme", resource.URL)
+	}
+	return nil
+}
+
+//ExpandTopic returns topic for source URL and split partition
+func (m *MQTT) ExpandTopic(sourceURL, partition string) string {
+	name := path.Base(sourceURL)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	sanitizer := strings.NewReplacer("/", "_", "+", "_", "#", "_")
+	replacer := strings.NewReplacer("$partition", sanitizer.Replace(partition), "$name", sanitizer.Replace(name))
+	return replacer.Replace(m.Topic)
+}
+
+//IsDataMode returns true if data of supplied size is published as payload
+func (m *MQTT) IsDataMode(size int) bool {
+	return m.DataMaxBytes > 0 && int64(size) <= m.DataMaxBytes
+}
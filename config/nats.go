@@ -0,0 +1,39 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"github.com/viant/afs/url"
+	"path"
+	"strings"
+)
+
+//NATS represents NATS JetStream destination, resource URL is NATS server URL, i.e. nats://host:4222 or tls://host:4222
+type NATS struct {
+	//Subject subject template, $partition and $name (source file name without extension) are expanded
+	Subject string
+	//Stream optional expected stream name, publish fails if subject is bound to other stream
+	Stream string `json:",omitempty"`
+}
+
+//Validate checks if nats destination is valid
+func (n *NATS) Validate(resource *Resource) error {
+	if n.Subject == "" {
+		return errors.New("nats.Subject was empty")
+	}
+	if strings.ContainsAny(n.Subject, " \t\r\n") {
+		return errors.Errorf("invalid nats.Subject: %q", n.Subject)
+	}
+	if scheme := url.Scheme(resource.URL, ""); scheme != "nats" && scheme != "tls" {
+		return errors.Errorf("unsupported nats URL: %v, expected nats:// or tls:// scheme", resource.URL)
+	}
+	return nil
+}
+
+//ExpandSubject returns subject for source URL and split partition, dots in expanded values are replaced as dots separate subject tokens
+func (n *NATS) ExpandSubject(sourceURL, partition string) string {
+	name := path.Base(sourceURL)
+	name = strings.TrimSuffix(name, path.Ext(name))
+	sanitizer := strings.NewReplacer(".", "_", " ", "_", "*", "_", ">", "_")
+	replacer := strings.NewReplacer("$partition", sanitizer.Replace(partition), "$name", sanitizer.Replace(name))
+	return replacer.Replace(n.Subject)
+}
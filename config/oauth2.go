@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/afs/url"
+)
+
+const (
+	//OAuth2AuthStyleHeader client id and secret are sent with HTTP basic auth
+	OAuth2AuthStyleHeader = "header"
+	//OAuth2AuthStyleParams client id and secret are sent as token request form params
+	OAuth2AuthStyleParams = "params"
+)
+
+//OAuth2 represents OAuth2 client credentials grant authorizing HTTP destination requests with bearer token,
+//client id and secret are read from decrypted resource Credentials JSON: {"ClientID":"...","ClientSecret":"..."}
+type OAuth2 struct {
+	//TokenURL identity provider token endpoint
+	TokenURL string
+	Scopes   []string `json:",omitempty"`
+	//Audience optional audience token request param
+	Audience string `json:",omitempty"`
+	//AuthStyle client authentication: header (default) or params
+	AuthStyle string `json:",omitempty"`
+}
+
+//Validate checks if OAuth2 settings are valid for supplied resource
+func (o *OAuth2) Validate(resource *Resource) error {
+	if o.TokenURL == "" {
+		return fmt.Errorf("oauth2.TokenURL was empty")
+	}
+	if !isHTTPScheme(o.TokenURL) {
+		return fmt.Errorf("invalid oauth2.TokenURL: %v", o.TokenURL)
+	}
+	if resource.URL != "" && !isHTTPScheme(resource.URL) {
+		return fmt.Errorf("oauth2 expected http(s) resource, but had: %v", resource.URL)
+	}
+	if resource.Credentials == nil {
+		return fmt.Errorf("oauth2 requires Credentials with ClientID and ClientSecret")
+	}
+	switch o.AuthStyle {
+	case "", OAuth2AuthStyleHeader, OAuth2AuthStyleParams:
+	default:
+		return fmt.Errorf("unsupported oauth2.AuthStyle: %v, supported: %v, %v", o.AuthStyle, OAuth2AuthStyleHeader, OAuth2AuthStyleParams)
+	}
+	return nil
+}
+
+func isHTTPScheme(URL string) bool {
+	scheme := url.Scheme(URL, "")
+	return scheme == "http" || scheme == "https"
+}
@@ -0,0 +1,117 @@
+package config
+
+import (
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"time"
+)
+
+const (
+	//PartSizeStatic configured part size, no dest throughput was observed yet
+	PartSizeStatic = "static"
+	//PartSizeThroughput part size uploads within target part time at observed throughput
+	PartSizeThroughput = "throughput"
+	//PartSizeDeadline part size reduced so that failed part retry fits remaining deadline
+	PartSizeDeadline = "deadline"
+	//PartSizeMaxParts part size increased to fit provider max number of parts
+	PartSizeMaxParts = "maxParts"
+
+	defaultMaxPartSizeMb  = 512
+	defaultTargetPartTime = 10
+	partSizeAlignment     = megaBytes
+)
+
+//PartSizeLimit represents provider multipart upload constraints
+type PartSizeLimit struct {
+	MinMb    int
+	MaxMb    int
+	MaxParts int
+}
+
+//partSizeLimits provider multipart upload constraints by dest scheme
+var partSizeLimits = map[string]*PartSizeLimit{
+	"s3": {MinMb: 5, MaxMb: 5 * 1024, MaxParts: 10000},
+	"gs": {MinMb: 1, MaxMb: 5 * 1024},
+}
+
+var defaultPartSizeLimit = &PartSizeLimit{MinMb: 1, MaxMb: 5 * 1024}
+
+//AdaptivePartSize represents multipart upload part size tuning based on observed dest throughput and remaining deadline
+type AdaptivePartSize struct {
+	//MinPartSizeMb min part size, provider min by default (s3: 5, others: 1)
+	MinPartSizeMb base.Megabytes `json:",omitempty"`
+	//MaxPartSizeMb max part size, parts are buffered in memory, default 512
+	MaxPartSizeMb base.Megabytes `json:",omitempty"`
+	//TargetPartTime target part upload time, number of seconds or duration text, so that failed part retry cost stays bounded, default 10 sec
+	TargetPartTime base.Seconds `json:",omitempty"`
+}
+
+//Init initialises adaptive part size
+func (a *AdaptivePartSize) Init() {
+	if a.MaxPartSizeMb == 0 {
+		a.MaxPartSizeMb = defaultMaxPartSizeMb
+	}
+	if a.TargetPartTime == 0 {
+		a.TargetPartTime = defaultTargetPartTime
+	}
+}
+
+//Limit returns provider multipart upload constraints for dest URL
+func (a *AdaptivePartSize) Limit(destURL string) *PartSizeLimit {
+	if limit, ok := partSizeLimits[url.Scheme(destURL, "")]; ok {
+		return limit
+	}
+	return defaultPartSizeLimit
+}
+
+//PartSize returns part size with the reason it was chosen for supplied file size, observed dest throughput (bytes/sec, 0 if unknown)
+//and remaining deadline (0 if none), static part size is used until dest throughput is observed
+func (a *AdaptivePartSize) PartSize(destURL string, fileSize int64, static int, throughput float64, remaining time.Duration) (int, string) {
+	size, reason := float64(static), PartSizeStatic
+	if throughput > 0 {
+		size, reason = throughput*a.TargetPartTime.Duration().Seconds(), PartSizeThroughput
+		if remaining > 0 {
+			//slack left after the whole transfer at observed throughput, failed part retry has to fit within half of it
+			slack := remaining.Seconds() - float64(fileSize)/throughput
+			if limit := throughput * slack / 2; size > limit {
+				size, reason = limit, PartSizeDeadline
+			}
+		}
+	}
+	limit := a.Limit(destURL)
+	minSize := float64(maxInt(int(a.MinPartSizeMb), limit.MinMb) * megaBytes)
+	maxSize := float64(minInt(int(a.MaxPartSizeMb), limit.MaxMb) * megaBytes)
+	if maxSize < minSize {
+		maxSize = minSize
+	}
+	if size < minSize {
+		size = minSize
+	}
+	if size > maxSize {
+		size = maxSize
+	}
+	if limit.MaxParts > 0 {
+		if partsSize := float64(fileSize) / float64(limit.MaxParts); size < partsSize {
+			size, reason = partsSize, PartSizeMaxParts
+		}
+	}
+	if size > float64(limit.MaxMb*megaBytes) {
+		size = float64(limit.MaxMb * megaBytes)
+	}
+	aligned := (int(size) + partSizeAlignment - 1) / partSizeAlignment * partSizeAlignment
+	return aligned, reason
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAdaptivePartSize_PartSize(t *testing.T) {
+	const mb = 1024 * 1024
+	var useCases = []struct {
+		description string
+		adaptive    *AdaptivePartSize
+		destURL     string
+		fileSize    int64
+		throughput  float64
+		remaining   time.Duration
+		expectSize  int
+		expectWhy   string
+	}{
+		{description: "no observation", adaptive: &AdaptivePartSize{}, destURL: "gs://bucket/data.csv", fileSize: 1024 * mb, expectSize: 64 * mb, expectWhy: PartSizeStatic},
+		{description: "throughput", adaptive: &AdaptivePartSize{}, destURL: "gs://bucket/data.csv", fileSize: 1024 * mb, throughput: 10 * mb, expectSize: 100 * mb, expectWhy: PartSizeThroughput},
+		{description: "max part size", adaptive: &AdaptivePartSize{}, destURL: "gs://bucket/data.csv", fileSize: 1024 * mb, throughput: 100 * mb, expectSize: 512 * mb, expectWhy: PartSizeThroughput},
+		{description: "provider min", adaptive: &AdaptivePartSize{}, destURL: "s3://bucket/data.csv", fileSize: 1024 * mb, throughput: 100 * 1024, expectSize: 5 * mb, expectWhy: PartSizeThroughput},
+		{description: "deadline", adaptive: &AdaptivePartSize{}, destURL: "gs://bucket/data.csv", fileSize: 1024 * mb, throughput: 10 * mb, remaining: 110 * time.Second, expectSize: 38 * mb, expectWhy: PartSizeDeadline},
+		{description: "deadline exceeded", adaptive: &AdaptivePartSize{MinPartSizeMb: 8}, destURL: "gs://bucket/data.csv", fileSize: 1024 * mb, throughput: 10 * mb, remaining: 50 * time.Second, expectSize: 8 * mb, expectWhy: PartSizeDeadline},
+		{description: "max parts", adaptive: &AdaptivePartSize{}, destURL: "s3://bucket/data.csv", fileSize: 200000 * mb, throughput: 1 * mb, expectSize: 20 * mb, expectWhy: PartSizeMaxParts},
+	}
+	for _, useCase := range useCases {
+		useCase.adaptive.Init()
+		size, reason := useCase.adaptive.PartSize(useCase.destURL, useCase.fileSize, 64*mb, useCase.throughput, useCase.remaining)
+		assert.Equal(t, useCase.expectSize, size, useCase.description)
+		assert.Equal(t, useCase.expectWhy, reason, useCase.description)
+	}
+}
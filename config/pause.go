@@ -0,0 +1,72 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/job"
+	"time"
+)
+
+const (
+	defaultPauseCheckInMs = 10000
+	//PauseAll pause marker name pausing all rules
+	PauseAll = "all"
+)
+
+//Pause represents ingestion pause control, a marker object under ControlURL pauses all rules ("all") or a rule (rule workflow name),
+//events of paused rule are deferred instead of processed
+type Pause struct {
+	//ControlURL base URL with pause marker objects
+	ControlURL string
+	//CheckInMs pause markers check frequency, default 10 sec
+	CheckInMs base.Milliseconds `json:",omitempty"`
+	//Requeue republishes paused source event with delay, MaxAttempts and DelaySec should cover maintenance window
+	Requeue *job.Requeue `json:",omitempty"`
+	//DeferURL base URL where paused source object is moved, deferred objects are moved back to source location on resume
+	DeferURL string `json:",omitempty"`
+}
+
+//PauseMarker represents pause marker object content
+type PauseMarker struct {
+	//Name marker name: all or rule workflow name
+	Name   string
+	Reason string `json:",omitempty"`
+	Paused time.Time
+}
+
+//Init initialises pause settings
+func (p *Pause) Init() {
+	if p.CheckInMs == 0 {
+		p.CheckInMs = defaultPauseCheckInMs
+	}
+}
+
+//Validate checks if pause settings are valid
+func (p *Pause) Validate() error {
+	if p.ControlURL == "" {
+		return errors.New("pause.ControlURL was empty")
+	}
+	if p.Requeue != nil && p.DeferURL != "" {
+		return errors.New("pause supports only one of Requeue or DeferURL")
+	}
+	if p.Requeue != nil {
+		return p.Requeue.Validate()
+	}
+	return nil
+}
+
+//MarkerURL returns pause marker URL
+func (p *Pause) MarkerURL(name string) string {
+	return url.Join(p.ControlURL, name)
+}
+
+//DeferDestURL returns paused source object defer URL
+func (p *Pause) DeferDestURL(marker, sourceURL string) string {
+	return url.Join(p.DeferURL, marker, url.Host(sourceURL), url.Path(sourceURL))
+}
+
+//DeferBaseURL returns deferred objects base URL for supplied marker
+func (p *Pause) DeferBaseURL(marker string) string {
+	return url.Join(p.DeferURL, marker)
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"github.com/viant/smirror/base"
+	"time"
+)
+
+const (
+	defaultPerfSampleMs = 100
+	//minPerfSampleMs runtime memory stats read stops the world, too frequent sampling slows down transfer
+	minPerfSampleMs = 10
+)
+
+//Perf represents per invocation runtime telemetry settings, runtime stats are sampled while event is processed
+type Perf struct {
+	//SampleMs runtime stats sampling interval, default 100 ms, min 10 ms
+	SampleMs base.Milliseconds `json:",omitempty"`
+}
+
+//Init initialises perf settings
+func (p *Perf) Init() {
+	if p.SampleMs == 0 {
+		p.SampleMs = defaultPerfSampleMs
+	}
+	if p.SampleMs < minPerfSampleMs {
+		p.SampleMs = minPerfSampleMs
+	}
+}
+
+//SampleInterval returns runtime stats sampling interval
+func (p *Perf) SampleInterval() time.Duration {
+	if p.SampleMs == 0 {
+		return defaultPerfSampleMs * time.Millisecond
+	}
+	return p.SampleMs.Duration()
+}
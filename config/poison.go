@@ -0,0 +1,60 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/job"
+	"time"
+)
+
+const defaultMaxFailures = 3
+
+//Poison represents poison file detection settings
+type Poison struct {
+	//FailureURL base URL storing per source object failure counters
+	FailureURL string
+	//MaxFailures number of failures after which source object is dead-lettered, default 3
+	MaxFailures int `json:",omitempty"`
+	//DeadLetterURL base URL where poisoned source object is moved
+	DeadLetterURL string
+	//OnPoison alert actions, $Error holds the last failure
+	OnPoison []*job.Action `json:",omitempty"`
+}
+
+//Failure represents source object failure counter
+type Failure struct {
+	URL string
+	//Modified source object modification time, counter is reset when object changes
+	Modified  time.Time
+	Count     int
+	LastError string
+	Updated   time.Time
+}
+
+//Init initialises poison settings
+func (p *Poison) Init() {
+	if p.MaxFailures == 0 {
+		p.MaxFailures = defaultMaxFailures
+	}
+}
+
+//Validate checks if poison settings are valid
+func (p *Poison) Validate() error {
+	if p.FailureURL == "" {
+		return errors.New("poison.FailureURL was empty")
+	}
+	if p.DeadLetterURL == "" {
+		return errors.New("poison.DeadLetterURL was empty")
+	}
+	return nil
+}
+
+//CounterURL returns source object failure counter URL
+func (p *Poison) CounterURL(sourceURL string) string {
+	return url.Join(p.FailureURL, url.Host(sourceURL), url.Path(sourceURL)) + ".json"
+}
+
+//DeadLetterDestURL returns source object dead letter URL
+func (p *Poison) DeadLetterDestURL(sourceURL string) string {
+	return url.Join(p.DeadLetterURL, url.Host(sourceURL), url.Path(sourceURL))
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/shared"
+	"regexp"
+	"strings"
+)
+
+//Policy represents global dest guardrails, deny takes precedence over allow, empty allow list allows any
+type Policy struct {
+	//AllowURLs dest URL patterns rules can write to, * matches within path segment, ** across segments
+	AllowURLs []string `json:",omitempty"`
+	//DenyURLs dest URL patterns rules can not write to
+	DenyURLs []string `json:",omitempty"`
+	//AllowProviders dest providers rules can use, i.e. gs, s3, pubsub, sqs, redis, database
+	AllowProviders []string `json:",omitempty"`
+	//DenyProviders dest providers rules can not use
+	DenyProviders []string `json:",omitempty"`
+	allowURLs      []*regexp.Regexp
+	denyURLs       []*regexp.Regexp
+}
+
+//Init compiles policy URL patterns
+func (p *Policy) Init() error {
+	var err error
+	if p.allowURLs, err = compileURLPatterns(p.AllowURLs); err != nil {
+		return fmt.Errorf("invalid AllowURLs: %w", err)
+	}
+	if p.denyURLs, err = compileURLPatterns(p.DenyURLs); err != nil {
+		return fmt.Errorf("invalid DenyURLs: %w", err)
+	}
+	return nil
+}
+
+//CheckRule checks if rule dest is permitted
+func (p *Policy) CheckRule(rule *Rule) error {
+	if rule.Dest == nil {
+		return nil
+	}
+	if err := p.Check(rule.Dest, rule.Dest.URL); err != nil {
+		return fmt.Errorf("rule %v: %w", rule.Info.URL, err)
+	}
+	return nil
+}
+
+//Check checks if dest provider and resolved dest URL are permitted
+func (p *Policy) Check(dest *Resource, URL string) error {
+	provider := DestProvider(dest)
+	if containsFold(p.DenyProviders, provider) {
+		return fmt.Errorf("dest provider %v is denied by policy", provider)
+	}
+	if len(p.AllowProviders) > 0 && !containsFold(p.AllowProviders, provider) {
+		return fmt.Errorf("dest provider %v is not allowed by policy %v", provider, p.AllowProviders)
+	}
+	if URL == "" || dest.Topic != "" || dest.Queue != "" {
+		return nil
+	}
+	if matchAny(p.denyURLs, URL) {
+		return fmt.Errorf("dest %v is denied by policy", URL)
+	}
+	if len(p.allowURLs) > 0 && !matchAny(p.allowURLs, URL) {
+		return fmt.Errorf("dest %v is not allowed by policy %v", URL, p.AllowURLs)
+	}
+	return nil
+}
+
+//DestProvider returns dest provider name
+func DestProvider(dest *Resource) string {
+	switch {
+	case dest.Topic != "" || dest.Queue != "":
+		if dest.Vendor != "" {
+			return dest.Vendor
+		}
+		if dest.Topic != "" {
+			return shared.VendorPubsub
+		}
+		return shared.VendorSQS
+	case dest.Databricks != nil:
+		return "databricks"
+	case dest.Azure != nil:
+		return "azure"
+	case dest.Redis != nil:
+		return "redis"
+	case dest.NATS != nil:
+		return "nats"
+	case dest.MQTT != nil:
+		return "mqtt"
+	case dest.Database != nil:
+		return "database"
+	case dest.Elasticsearch != nil:
+		return "elasticsearch"
+	case dest.ClickHouse != nil:
+		return "clickhouse"
+	case dest.Sheets != nil:
+		return "sheets"
+	}
+	return url.Scheme(dest.URL, "")
+}
+
+//compileURLPatterns compiles URL patterns, a pattern matches URL itself or any URL under it
+func compileURLPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var result []*regexp.Regexp
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return nil, fmt.Errorf("pattern was empty")
+		}
+		expr := regexp.QuoteMeta(strings.TrimRight(pattern, "/"))
+		expr = strings.Replace(expr, `\*\*`, `.*`, -1)
+		expr = strings.Replace(expr, `\*`, `[^/]*`, -1)
+		compiled, err := regexp.Compile("^" + expr + "(/.*)?$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %v: %w", pattern, err)
+		}
+		result = append(result, compiled)
+	}
+	return result, nil
+}
+
+func matchAny(patterns []*regexp.Regexp, URL string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(URL) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(candidates []string, value string) bool {
+	for _, candidate := range candidates {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
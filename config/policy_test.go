@@ -0,0 +1,71 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	policy := &Policy{
+		AllowURLs:     []string{"gs://myorg-*", "s3://myorg-archive/**/exports"},
+		DenyURLs:      []string{"gs://myorg-public"},
+		DenyProviders: []string{"sheets"},
+	}
+	if !assert.Nil(t, policy.Init()) {
+		return
+	}
+	var useCases = []struct {
+		description string
+		dest        *Resource
+		URL         string
+		expectError bool
+	}{
+		{
+			description: "allowed bucket",
+			dest:        &Resource{URL: "gs://myorg-data"},
+			URL:         "gs://myorg-data/2024/01/events.csv",
+		},
+		{
+			description: "typo bucket",
+			dest:        &Resource{URL: "gs://myrog-data"},
+			URL:         "gs://myrog-data/events.csv",
+			expectError: true,
+		},
+		{
+			description: "denied bucket",
+			dest:        &Resource{URL: "gs://myorg-public"},
+			URL:         "gs://myorg-public/events.csv",
+			expectError: true,
+		},
+		{
+			description: "multi segment wildcard",
+			dest:        &Resource{URL: "s3://myorg-archive"},
+			URL:         "s3://myorg-archive/team/a/exports/events.csv",
+		},
+		{
+			description: "single segment wildcard",
+			dest:        &Resource{URL: "s3://myorg-archive"},
+			URL:         "s3://myorg-archive/team/events.csv",
+			expectError: true,
+		},
+		{
+			description: "denied provider",
+			dest:        &Resource{Sheets: &Sheets{SpreadsheetID: "sheet-1"}},
+			expectError: true,
+		},
+		{
+			description: "message dest",
+			dest:        &Resource{Topic: "events"},
+			URL:         "events",
+		},
+	}
+
+	for _, useCase := range useCases {
+		err := policy.Check(useCase.dest, useCase.URL)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+	}
+}
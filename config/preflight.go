@@ -0,0 +1,119 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPreflightTimeout = 10 * time.Second
+	defaultPreflightTTL     = 5 * time.Minute
+)
+
+//preflightPorts default dest port by URL scheme
+var preflightPorts = map[string]string{
+	"http":    "80",
+	"https":   "443",
+	"webdav":  "80",
+	"webdavs": "443",
+	"gs":      "443",
+	"s3":      "443",
+	"scp":     "22",
+	"sftp":    "22",
+	"ftp":     "21",
+}
+
+//Preflight represents dest reachability check (TCP/TLS connect and optional HEAD request) run before large transfers,
+//failed check is reported with unreachable error code, so network or firewall issues are distinguishable from data errors
+type Preflight struct {
+	//MinSize min transfer size to run preflight, number of bytes or size text (i.e. "500MB"), default all transfers
+	MinSize base.Bytes `json:",omitempty"`
+	//Timeout connect and HEAD request timeout, default 10s
+	Timeout base.Milliseconds `json:",omitempty"`
+	//HEAD sends HEAD request to http(s)/webdav(s) dest after connect, any HTTP status proves reachability
+	HEAD bool `json:",omitempty"`
+	//TTL successful check is reused for dest address, default 5m
+	TTL base.Seconds `json:",omitempty"`
+}
+
+//Validate checks if preflight settings are valid for supplied dest
+func (p *Preflight) Validate(resource *Resource) error {
+	if p.MinSize < 0 || p.Timeout < 0 || p.TTL < 0 {
+		return fmt.Errorf("invalid Preflight: MinSize, Timeout and TTL can not be negative")
+	}
+	if _, err := p.Address(resource); err != nil {
+		return err
+	}
+	if p.HEAD && p.HeadURL(resource.URL) == "" {
+		return fmt.Errorf("invalid Preflight.HEAD: unsupported dest scheme: %v", url.Scheme(resource.URL, ""))
+	}
+	return nil
+}
+
+//Address returns dest host:port, cloud storage URL is checked against storage API endpoint
+func (p *Preflight) Address(resource *Resource) (string, error) {
+	scheme := url.Scheme(resource.URL, "")
+	host := url.Host(resource.URL)
+	switch scheme {
+	case "gs":
+		host = "storage.googleapis.com"
+	case "s3":
+		host = "s3.amazonaws.com"
+		if resource.Region != "" {
+			host = "s3." + resource.Region + ".amazonaws.com"
+		}
+	}
+	if host == "" || strings.Contains(host, "$") {
+		return "", fmt.Errorf("invalid Preflight: dest URL host was empty or templated: %v", resource.URL)
+	}
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host, nil
+	}
+	port, ok := preflightPorts[scheme]
+	if !ok {
+		return "", fmt.Errorf("invalid Preflight: unsupported dest scheme: %v", scheme)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+//IsTLS returns true if dest scheme uses TLS
+func (p *Preflight) IsTLS(URL string) bool {
+	switch url.Scheme(URL, "") {
+	case "https", "webdavs", "gs", "s3":
+		return true
+	}
+	return false
+}
+
+//HeadURL returns dest HEAD request URL or empty string for non HTTP dest
+func (p *Preflight) HeadURL(URL string) string {
+	switch url.Scheme(URL, "") {
+	case "http", "https":
+		return URL
+	case "webdav":
+		return "http" + strings.TrimPrefix(URL, "webdav")
+	case "webdavs":
+		return "https" + strings.TrimPrefix(URL, "webdavs")
+	}
+	return ""
+}
+
+//TimeoutDuration returns connect and HEAD request timeout
+func (p *Preflight) TimeoutDuration() time.Duration {
+	if p.Timeout > 0 {
+		return p.Timeout.Duration()
+	}
+	return defaultPreflightTimeout
+}
+
+//TTLDuration returns successful check reuse duration
+func (p *Preflight) TTLDuration() time.Duration {
+	if p.TTL > 0 {
+		return p.TTL.Duration()
+	}
+	return defaultPreflightTTL
+}
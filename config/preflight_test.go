@@ -0,0 +1,32 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPreflight_Address(t *testing.T) {
+	var useCases = []struct {
+		description string
+		resource    *Resource
+		expect      string
+		expectError bool
+	}{
+		{description: "https default port", resource: &Resource{URL: "https://partner.com/inbound"}, expect: "partner.com:443"},
+		{description: "explicit port", resource: &Resource{URL: "webdavs://dav.partner.com:8443/inbound"}, expect: "dav.partner.com:8443"},
+		{description: "regional s3 endpoint", resource: &Resource{URL: "s3://bucket/data", Region: "us-west-2"}, expect: "s3.us-west-2.amazonaws.com:443"},
+		{description: "gs endpoint", resource: &Resource{URL: "gs://bucket/data"}, expect: "storage.googleapis.com:443"},
+		{description: "templated host", resource: &Resource{URL: "https://${partner}.com/inbound"}, expectError: true},
+		{description: "unsupported scheme", resource: &Resource{URL: "mem://localhost/data"}, expectError: true},
+	}
+	for _, useCase := range useCases {
+		preflight := &Preflight{}
+		actual, err := preflight.Address(useCase.resource)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+		assert.Equal(t, useCase.expect, actual, useCase.description)
+	}
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/viant/afs/option"
+	"github.com/viant/smirror/auth"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var proxySchemes = map[string]bool{"http": true, "https": true, "socks5": true, "socks5h": true}
+
+//Proxy represents resource forward proxy, without URL proxy is taken from HTTPS_PROXY/HTTP_PROXY env variables
+type Proxy struct {
+	option.Proxy
+	//Credentials encrypted proxy credentials: user:password or JSON with Username and Password
+	Credentials *auth.Secret `json:",omitempty"`
+	//NoProxy hosts reached directly: host name, .domain suffix, IP, CIDR or * for all
+	NoProxy []string `json:",omitempty"`
+	//Auth decrypted proxy credentials
+	Auth []byte `json:"-"`
+}
+
+//Validate checks if proxy settings are valid
+func (p *Proxy) Validate() error {
+	if p.URL == "" {
+		return nil
+	}
+	proxyURL, err := url.Parse(p.URL)
+	if err != nil {
+		return fmt.Errorf("invalid Proxy.URL: %v, %w", p.URL, err)
+	}
+	if !proxySchemes[proxyURL.Scheme] || proxyURL.Host == "" {
+		return fmt.Errorf("unsupported Proxy.URL: %v, expected http, https, socks5 or socks5h proxy", p.URL)
+	}
+	return nil
+}
+
+//ProxyURL returns proxy URL with credentials, it is nil if URL was not configured
+func (p *Proxy) ProxyURL() (*url.URL, error) {
+	if p.URL == "" {
+		return nil, nil
+	}
+	result, err := url.Parse(p.URL)
+	if err != nil {
+		return nil, err
+	}
+	return p.withCredentials(result)
+}
+
+//Bypass returns true if host matches NoProxy list
+func (p *Proxy) Bypass(host string) bool {
+	if hostname, _, err := net.SplitHostPort(host); err == nil {
+		host = hostname
+	}
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+	for _, candidate := range p.NoProxy {
+		candidate = strings.ToLower(strings.TrimSpace(candidate))
+		switch {
+		case candidate == "":
+		case candidate == "*":
+			return true
+		case strings.Contains(candidate, "/"):
+			if _, network, err := net.ParseCIDR(candidate); err == nil && ip != nil && network.Contains(ip) {
+				return true
+			}
+		case host == strings.TrimPrefix(candidate, "."):
+			return true
+		case strings.HasSuffix(host, "."+strings.TrimPrefix(candidate, ".")):
+			return true
+		}
+	}
+	return false
+}
+
+//Func returns http transport proxy function
+func (p *Proxy) Func() func(*http.Request) (*url.URL, error) {
+	return func(request *http.Request) (*url.URL, error) {
+		if p.Bypass(request.URL.Host) {
+			return nil, nil
+		}
+		if p.URL == "" {
+			result, err := http.ProxyFromEnvironment(request)
+			if result == nil || err != nil {
+				return result, err
+			}
+			return p.withCredentials(result)
+		}
+		return p.ProxyURL()
+	}
+}
+
+//Option returns storage proxy option with credentials for supplied host, it is nil if host bypasses proxy
+func (p *Proxy) Option(host string) (*option.Proxy, error) {
+	if p.URL == "" || p.Bypass(host) {
+		return nil, nil
+	}
+	proxyURL, err := p.ProxyURL()
+	if err != nil {
+		return nil, err
+	}
+	result := p.Proxy
+	result.URL = proxyURL.String()
+	return &result, nil
+}
+
+//withCredentials sets decrypted credentials as proxy URL user info
+func (p *Proxy) withCredentials(proxyURL *url.URL) (*url.URL, error) {
+	if len(p.Auth) == 0 {
+		return proxyURL, nil
+	}
+	username, password, err := p.credentials()
+	if err != nil {
+		return nil, err
+	}
+	result := *proxyURL
+	result.User = url.UserPassword(username, password)
+	return &result, nil
+}
+
+func (p *Proxy) credentials() (string, string, error) {
+	if json.Valid(p.Auth) {
+		credentials := struct {
+			Username string
+			Password string
+		}{}
+		if err := json.Unmarshal(p.Auth, &credentials); err != nil {
+			return "", "", fmt.Errorf("invalid proxy credentials: %w", err)
+		}
+		return credentials.Username, credentials.Password, nil
+	}
+	pair := strings.SplitN(strings.TrimSpace(string(p.Auth)), ":", 2)
+	if len(pair) != 2 {
+		return "", "", fmt.Errorf("invalid proxy credentials format, expected user:password")
+	}
+	return pair[0], pair[1], nil
+}
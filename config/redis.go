@@ -0,0 +1,43 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"github.com/viant/afs/url"
+	"strings"
+)
+
+//Redis represents Redis Stream destination, resource URL is Redis server URL, i.e. redis://host:6379/0 or rediss://host:6380 for TLS
+type Redis struct {
+	//Stream stream key, $partition is expanded with split partition
+	Stream string
+	//MaxLen stream max length trimming, 0 - no trimming
+	MaxLen int64 `json:",omitempty"`
+	//ExactTrim uses exact MAXLEN trimming, by default approximate (~) trimming is used
+	ExactTrim bool `json:",omitempty"`
+	//RecordMaxBytes files (or split parts) up to this size are added with one entry per record, 0 - one entry per file
+	RecordMaxBytes int64 `json:",omitempty"`
+}
+
+//Validate checks if redis destination is valid
+func (r *Redis) Validate(resource *Resource) error {
+	if r.Stream == "" {
+		return errors.New("redis.Stream was empty")
+	}
+	if scheme := url.Scheme(resource.URL, ""); scheme != "redis" && scheme != "rediss" {
+		return errors.Errorf("unsupported redis URL: %v, expected redis:// or rediss:// scheme", resource.URL)
+	}
+	if r.MaxLen < 0 {
+		return errors.Errorf("invalid redis.MaxLen: %v", r.MaxLen)
+	}
+	return nil
+}
+
+//StreamKey returns stream key for split partition
+func (r *Redis) StreamKey(partition string) string {
+	return strings.Replace(r.Stream, "$partition", partition, 1)
+}
+
+//IsRecordMode returns true if data of supplied size is added with one entry per record
+func (r *Redis) IsRecordMode(size int) bool {
+	return r.RecordMaxBytes > 0 && int64(size) <= r.RecordMaxBytes
+}
@@ -6,11 +6,10 @@ import (
 	"github.com/viant/afs/matcher"
 	"github.com/viant/afs/option"
 	"github.com/viant/afs/url"
-	"github.com/viant/toolbox/data"
-	"github.com/viant/toolbox/data/udf"
 	"regexp"
 	"github.com/viant/smirror/auth"
 	"github.com/viant/smirror/config/pattern"
+	"github.com/viant/smirror/udf"
 	"strings"
 )
 
@@ -25,35 +24,100 @@ type Resource struct {
 	Grant       *option.Grant     `json:",omitempty"`
 	ACL         *option.ACL       `json:",omitempty"`
 	ServerSideEncryption *option.ServerSideEncryption `json:",omitempty"`
+	//RequesterPays flags requester-pays bucket, gs requests are billed to ProjectID
+	RequesterPays bool `json:",omitempty"`
+	//KMSKeyARN SSE-KMS key ARN (or alias) for KMS encrypted bucket reads and writes
+	KMSKeyARN   string            `json:",omitempty"`
+	//KMSKeyName GCS CMEK key name applied to uploaded objects
+	KMSKeyName  string            `json:",omitempty"`
+	//MaxClassification max data classification dest accepts: public (default), internal or pii
+	MaxClassification string `json:",omitempty"`
+	//Retention WORM retention and legal hold applied to uploaded objects, overwrites are refused
+	Retention   *Retention        `json:",omitempty"`
+	//ContentAddressed names uploaded objects by content digest with a manifest under the original name
+	ContentAddressed *ContentAddressed `json:",omitempty"`
+	//Append appends incoming files to rolling dest object instead of creating an object per file
+	Append *Append `json:",omitempty"`
+	//Delta transfers only changed blocks of large frequently updated objects
+	Delta *Delta `json:",omitempty"`
+	//Maintenance recurring dest maintenance windows, transfers are deferred while a window is open
+	Maintenance []*Maintenance    `json:",omitempty"`
 	Credentials *auth.Credentials `json:",omitempty"`
-	Proxy       *option.Proxy
+	//ImpersonateServiceAccount GCP service account email impersonated for gs access instead of JSON key credentials
+	ImpersonateServiceAccount string `json:",omitempty"`
+	//Federation workload identity federation for cross-cloud access without long-lived keys
+	Federation  *Federation `json:",omitempty"`
+	//OAuth2 client credentials bearer token for http(s) destination requests
+	OAuth2      *OAuth2 `json:",omitempty"`
+	//TLS private CA, mTLS client certificate and min version for http(s) resource
+	TLS         *TLS `json:",omitempty"`
+	//Proxy forward proxy with credentials and NoProxy list for HTTP based clients
+	Proxy       *Proxy `json:",omitempty"`
+	//Preflight dest reachability check run before large transfers
+	Preflight   *Preflight `json:",omitempty"`
 	Topic       string `json:",omitempty"`
 	Queue       string `json:",omitempty"`
+	//MessageBatch coalesces split parts published to the same topic or queue into manifest messages
+	MessageBatch *MessageBatch `json:",omitempty"`
+	//ClaimCheck offloads large message payloads to claim-check objects
+	ClaimCheck *ClaimCheck `json:",omitempty"`
+	//Databricks Unity Catalog volume or DBFS destination
+	Databricks *Databricks `json:",omitempty"`
+	//Azure Event Grid topic or Service Bus queue/topic destination
+	Azure       *Azure `json:",omitempty"`
+	//Redis Redis Stream destination
+	Redis       *Redis `json:",omitempty"`
+	//NATS NATS JetStream destination
+	NATS        *NATS `json:",omitempty"`
+	//MQTT MQTT broker destination
+	MQTT        *MQTT `json:",omitempty"`
+	//Database PostgreSQL or MySQL table destination
+	Database    *Database `json:",omitempty"`
+	//Elasticsearch Elasticsearch or OpenSearch bulk index destination
+	Elasticsearch *Elasticsearch `json:",omitempty"`
+	//ClickHouse ClickHouse table destination
+	ClickHouse  *ClickHouse `json:",omitempty"`
+	//Sheets Google Sheet destination
+	Sheets      *Sheets `json:",omitempty"`
 	Vendor      string `json:",omitempty"`
 	//Optional pubsub project ID, otherwise it uses default one.
 	ProjectID  string `json:",omitempty"`
 	Pattern    string `json:",omitempty"`
 	compiled   *regexp.Regexp
+	//Glob source location glob, i.e. incoming/**/{csv,tsv}/*.gz, it is matched in addition to prefix, suffix and filter
+	Glob          string `json:",omitempty"`
+	compiledGlob  *regexp.Regexp
+	filterExpr    *regexp.Regexp
+	exclusionExpr *regexp.Regexp
 	Parameters []*pattern.Param `json:",omitempty"`
 }
 
-func (r *Resource) ExpandURL(sourceURL string) (string, error) {
+//ExpandURL expands URL with pattern parameters and source captures, parameters take precedence
+func (r *Resource) ExpandURL(sourceURL string, captures map[string]string) (string, error) {
 	var err error
 	if r.Pattern != "" && len(r.Parameters) > 0 {
-		if r.compiled == nil {
-			r.compiled, err = regexp.Compile(r.Pattern)
-			if err != nil {
+		expr := r.compiled
+		if expr == nil { //resource was not validated, pattern is compiled without caching
+			if expr, err = regexp.Compile(r.Pattern); err != nil {
 				return "", err
 			}
 		}
 		var params = make(map[string]interface{})
-		udfs := data.NewMap()
-		udf.Register(udfs)
+		udfs := udf.NewMap()
 		for _, param := range r.Parameters {
-			paramValue := expandWithPattern(r.compiled, sourceURL, param.Expression)
+			paramValue := expandWithPattern(expr, sourceURL, param.Expression)
 			params[param.Name] = udfs.ExpandAsText(paramValue)
 		}
-		expander := data.Map(params)
+		expander := udf.NewMap()
+		putCaptures(expander, captures)
+		for key, value := range params {
+			expander.Put(key, value)
+		}
+		return expander.ExpandAsText(r.URL), nil
+	}
+	if strings.Contains(r.URL, "$") {
+		expander := udf.NewMap()
+		putCaptures(expander, captures)
 		return expander.ExpandAsText(r.URL), nil
 	}
 	return r.URL, nil
@@ -72,23 +136,210 @@ func expandWithPattern(expr *regexp.Regexp, sourceURL string, expression string)
 	return expression
 }
 
+//HasEncryptionKey returns true if resource uses KMS or customer supplied key
+func (r *Resource) HasEncryptionKey() bool {
+	return r.KMSKeyARN != "" || r.KMSKeyName != "" || r.CustomKey != nil
+}
+
+//IsStorage returns true if resource is a storage destination
+func (r *Resource) IsStorage() bool {
+	return r.URL != "" && r.Databricks == nil && r.Redis == nil && r.NATS == nil && r.MQTT == nil && r.Elasticsearch == nil && r.ClickHouse == nil && !r.IsWebDAV()
+}
+
+//IsWebDAV returns true if resource URL uses webdav:// or webdavs:// scheme
+func (r *Resource) IsWebDAV() bool {
+	scheme := url.Scheme(r.URL, "")
+	return scheme == "webdav" || scheme == "webdavs"
+}
+
 //CloneWithURL clone resource with URL
 func (r Resource) CloneWithURL(URL string) *Resource {
 	return &Resource{
 		Basic:       r.Basic,
+		Glob:        r.Glob,
+		compiledGlob:  r.compiledGlob,
+		filterExpr:    r.filterExpr,
+		exclusionExpr: r.exclusionExpr,
 		URL:         URL,
 		Region:      r.Region,
 		CustomKey:   r.CustomKey,
 		Proxy:       r.Proxy,
 		Grant:       r.Grant,
+		ACL:         r.ACL,
 		Credentials: r.Credentials,
+		ImpersonateServiceAccount: r.ImpersonateServiceAccount,
+		Federation:                r.Federation,
+		OAuth2:                    r.OAuth2,
+		TLS:                       r.TLS,
+		ServerSideEncryption: r.ServerSideEncryption,
+		RequesterPays:        r.RequesterPays,
+		KMSKeyARN:            r.KMSKeyARN,
+		KMSKeyName:           r.KMSKeyName,
+		Retention:            r.Retention,
 		Topic:       r.Topic,
 		Queue:       r.Queue,
 		ProjectID:   r.ProjectID,
 	}
 }
 
+//Validate checks if resource is valid
+func (r *Resource) Validate() error {
+	if r.Databricks != nil {
+		if err := r.Databricks.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.Azure != nil {
+		if err := r.Azure.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.Redis != nil {
+		if err := r.Redis.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.NATS != nil {
+		if err := r.NATS.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.MQTT != nil {
+		if err := r.MQTT.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.Database != nil {
+		if err := r.Database.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.Elasticsearch != nil {
+		if err := r.Elasticsearch.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.ClickHouse != nil {
+		if err := r.ClickHouse.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.Sheets != nil {
+		if err := r.Sheets.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.ImpersonateServiceAccount != "" {
+		if err := r.validateImpersonation(); err != nil {
+			return err
+		}
+	}
+	if r.Federation != nil {
+		if err := r.Federation.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.OAuth2 != nil {
+		if err := r.OAuth2.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.TLS != nil {
+		if err := r.TLS.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.Proxy != nil {
+		if err := r.Proxy.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.Preflight != nil {
+		if err := r.Preflight.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.MessageBatch != nil {
+		if err := r.MessageBatch.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.ClaimCheck != nil {
+		if err := r.ClaimCheck.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.Glob != "" {
+		var err error
+		if r.compiledGlob, err = CompileGlob(r.Glob); err != nil {
+			return err
+		}
+	}
+	if r.Filter != "" {
+		var err error
+		if r.filterExpr, err = regexp.Compile(r.Filter); err != nil {
+			return fmt.Errorf("invalid filter: %v, %w", r.Filter, err)
+		}
+	}
+	if r.Exclusion != "" {
+		var err error
+		if r.exclusionExpr, err = regexp.Compile(r.Exclusion); err != nil {
+			return fmt.Errorf("invalid exclusion: %v, %w", r.Exclusion, err)
+		}
+	}
+	if r.Pattern != "" {
+		var err error
+		if r.compiled, err = regexp.Compile(r.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %v, %w", r.Pattern, err)
+		}
+	}
+	if !IsValidClassification(r.MaxClassification) {
+		return fmt.Errorf("invalid MaxClassification: %v", r.MaxClassification)
+	}
+	if r.Retention != nil {
+		if err := r.Retention.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.ContentAddressed != nil {
+		if err := r.ContentAddressed.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.Append != nil {
+		if err := r.Append.Validate(r); err != nil {
+			return err
+		}
+	}
+	if r.Delta != nil {
+		if err := r.Delta.Validate(r); err != nil {
+			return err
+		}
+	}
+	for _, window := range r.Maintenance {
+		if err := window.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.KMSKeyARN != "" {
+		if err := validateKMSKeyARN(r.KMSKeyARN); err != nil {
+			return err
+		}
+	}
+	if r.KMSKeyName != "" {
+		if r.CustomKey != nil {
+			return fmt.Errorf("KMSKeyName and CustomKey are mutually exclusive")
+		}
+		return validateKMSKeyName(r.KMSKeyName)
+	}
+	return nil
+}
+
 func (r *Resource) Init(projectID string) {
+	if r.Azure != nil {
+		r.Azure.Init()
+		return
+	}
 	if r.Topic == "" {
 		return
 	}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/afs/url"
+	"strings"
+	"time"
+)
+
+const (
+	//RetentionCompliance S3 Object Lock compliance mode, retention can not be shortened or removed by any user
+	RetentionCompliance = "COMPLIANCE"
+	//RetentionGovernance S3 Object Lock governance mode, users with bypass permission can alter retention
+	RetentionGovernance = "GOVERNANCE"
+
+	maxRetentionDays = 100 * 365
+)
+
+//Retention represents WORM destination settings for bucket lock (gs) or Object Lock (s3) enabled buckets
+type Retention struct {
+	//Mode S3 Object Lock mode: COMPLIANCE (default) or GOVERNANCE, gs applies bucket retention policy
+	Mode string `json:",omitempty"`
+	//Days retention period of uploaded objects
+	Days int `json:",omitempty"`
+	//LegalHold places legal hold (gs temporary hold) on uploaded objects
+	LegalHold bool `json:",omitempty"`
+}
+
+//ObjectRetention represents object retention storage option applied to uploaded objects
+type ObjectRetention struct {
+	Mode        string
+	RetainUntil time.Time
+	LegalHold   bool
+}
+
+//Init initialises retention
+func (r *Retention) Init() {
+	r.Mode = strings.ToUpper(r.Mode)
+	if r.Mode == "" {
+		r.Mode = RetentionCompliance
+	}
+}
+
+//Validate checks if retention is valid for supplied dest
+func (r *Retention) Validate(resource *Resource) error {
+	switch strings.ToUpper(r.Mode) {
+	case "", RetentionCompliance, RetentionGovernance:
+	default:
+		return fmt.Errorf("unsupported retention mode: %v, expected %v or %v", r.Mode, RetentionCompliance, RetentionGovernance)
+	}
+	if r.Days < 0 || r.Days > maxRetentionDays {
+		return fmt.Errorf("invalid retention days: %v, expected 0..%v", r.Days, maxRetentionDays)
+	}
+	if r.Days == 0 && !r.LegalHold {
+		return fmt.Errorf("retention requires Days or LegalHold")
+	}
+	if scheme := url.Scheme(resource.URL, ""); scheme != "gs" && scheme != "s3" {
+		return fmt.Errorf("retention is only supported with gs:// or s3:// dest, but had: %v", resource.URL)
+	}
+	return nil
+}
+
+//Option returns object retention storage option for objects uploaded at supplied time
+func (r *Retention) Option(now time.Time) *ObjectRetention {
+	result := &ObjectRetention{Mode: r.Mode, LegalHold: r.LegalHold}
+	if r.Days > 0 {
+		result.RetainUntil = now.UTC().AddDate(0, 0, r.Days)
+	}
+	return result
+}
+
+//String returns retention description
+func (r *Retention) String() string {
+	var elements []string
+	if r.Days > 0 {
+		elements = append(elements, fmt.Sprintf("%v %v days", r.Mode, r.Days))
+	}
+	if r.LegalHold {
+		elements = append(elements, "legal hold")
+	}
+	return strings.Join(elements, ", ")
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRetention_Validate(t *testing.T) {
+	var useCases = []struct {
+		description string
+		Retention
+		URL         string
+		expectError bool
+	}{
+		{
+			description: "s3 object lock",
+			Retention:   Retention{Mode: "governance", Days: 365},
+			URL:         "s3://bucket/data",
+		},
+		{
+			description: "gs legal hold only",
+			Retention:   Retention{LegalHold: true},
+			URL:         "gs://bucket/data",
+		},
+		{
+			description: "missing period and hold",
+			Retention:   Retention{Mode: RetentionCompliance},
+			URL:         "s3://bucket/data",
+			expectError: true,
+		},
+		{
+			description: "invalid mode",
+			Retention:   Retention{Mode: "strict", Days: 1},
+			URL:         "s3://bucket/data",
+			expectError: true,
+		},
+		{
+			description: "unsupported dest",
+			Retention:   Retention{Days: 1},
+			URL:         "mem://localhost/data",
+			expectError: true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		err := useCase.Validate(&Resource{URL: useCase.URL})
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+	}
+}
+
+func TestRetention_Option(t *testing.T) {
+	retention := &Retention{Days: 30, LegalHold: true}
+	retention.Init()
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	option := retention.Option(now)
+	assert.Equal(t, RetentionCompliance, option.Mode)
+	assert.Equal(t, time.Date(2024, 2, 14, 10, 0, 0, 0, time.UTC), option.RetainUntil)
+	assert.True(t, option.LegalHold)
+	assert.Equal(t, "COMPLIANCE 30 days, legal hold", retention.String())
+}
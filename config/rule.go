@@ -16,7 +16,15 @@ import (
 
 //Rule represent matching resource route rule
 type Rule struct {
+	//Version rule file layout version, older layouts are upgraded at load time
+	Version    int `json:",omitempty"`
 	Info       base.Info
+	//ConfigVersion rule file content digest set at load time, stamped on dest objects
+	ConfigVersion string `json:"-"`
+	//Labels rule metadata (i.e. team, partner, data-domain, cost-center) propagated to response, metrics and notifications
+	Labels     map[string]string `json:",omitempty"`
+	//Classification source data classification: public, internal or pii, checked against dest MaxClassification
+	Classification string `json:",omitempty"`
 	Disabled   bool `json:",omitempty"`
 	Dest       *Resource
 	Source     *Resource
@@ -25,7 +33,19 @@ type Rule struct {
 	Transcoder *Transcoding `json:",omitempty"`
 	Streaming  *Streaming   `json:",omitempty"`
 	Split      *Split       `json:",omitempty"`
+	//Sort sorts records by key columns before writing destination
+	Sort       *Sort        `json:",omitempty"`
+	//Inference samples top records and writes schema inference report next to the destination
+	Inference  *Inference   `json:",omitempty"`
 	AllowEmpty bool         `json:",omitempty"`
+	//Sidecar checksum sidecar manifest (i.e. file.csv.md5) verification
+	Sidecar *Sidecar `json:",omitempty"`
+	//Dedup duplicate content detection across different file names
+	Dedup *Dedup `json:",omitempty"`
+	//Debounce mirrors only the final version of rapidly rewritten source object
+	Debounce *Debounce `json:",omitempty"`
+	//OnEmpty zero-byte or placeholder object handling: skip, mirror (default) or complete
+	OnEmpty string `json:",omitempty"`
 	job.Actions
 	*Compression
 	//PreserveDepth  - preserves specified folder depth in dest URL
@@ -36,6 +56,15 @@ type Rule struct {
 
 	//Name of the file that is done flag, in that case all file will be replayed
 	DoneMarker string `json:",omitempty"`
+
+	//Priority processing lane: high, normal (default) or low
+	Priority string `json:",omitempty"`
+
+	//Logging rule response log level and sampling
+	Logging *Logging `json:",omitempty"`
+
+	//Alerting failure notification suppression, escalation and recovery
+	Alerting *Alerting `json:",omitempty"`
 }
 
 //NewReplacer create a replaced for the rule
@@ -51,9 +80,14 @@ func (r *Rule) NewReplacer() *strings.Replacer {
 	return strings.NewReplacer(pairs...)
 }
 
-//HasTransformer returns true if rule has recover or replace option
+//HasTransformer returns true if rule has recover, replace or sort option
 func (r *Rule) HasTransformer() bool {
-	return r.Schema != nil || len(r.Replace) > 0
+	return r.Schema != nil || len(r.Replace) > 0 || r.Sort != nil
+}
+
+//GzipPassthrough returns true if gzip source can be copied as is to gzip dest: no record transformation, split or transcoding
+func (r *Rule) GzipPassthrough() bool {
+	return r.Compression != nil && r.Compression.Codec == GZipCodec && !r.HasTransformer() && r.Split == nil && r.Transcoder == nil
 }
 
 //HasSplit returns true if rule has split defined
@@ -92,12 +126,69 @@ func (r *Rule) ArchiveWalkURL(URL string) string {
 
 //Validate checks if route is valid
 func (r *Rule) Validate() error {
+	if r.Version > CurrentRuleVersion {
+		return fmt.Errorf("unsupported rule version: %v, supported: 1..%v", r.Version, CurrentRuleVersion)
+	}
 	if r.Source == nil {
 		return fmt.Errorf("source was empty")
 	}
 	if r.Dest == nil {
 		return fmt.Errorf("dest was empty")
 	}
+	if err := r.Source.Validate(); err != nil {
+		return fmt.Errorf("invalid source: %w", err)
+	}
+	if err := r.Dest.Validate(); err != nil {
+		return fmt.Errorf("invalid dest: %w", err)
+	}
+	if r.Source.Retention != nil {
+		return fmt.Errorf("invalid source: retention is only supported with dest")
+	}
+	if r.Dest.Delta != nil && r.Compression != nil && r.Compression.Codec != "" {
+		return fmt.Errorf("invalid dest: delta can not be used with %v compression, compressed blocks are not stable", r.Compression.Codec)
+	}
+	if !IsValidClassification(r.Classification) {
+		return fmt.Errorf("invalid classification: %v", r.Classification)
+	}
+	if !IsValidEmptyAction(r.OnEmpty) {
+		return fmt.Errorf("invalid OnEmpty: %v", r.OnEmpty)
+	}
+	if r.Sidecar != nil {
+		if err := r.Sidecar.Validate(); err != nil {
+			return fmt.Errorf("invalid sidecar: %w", err)
+		}
+	}
+	if r.Dedup != nil {
+		if err := r.Dedup.Validate(); err != nil {
+			return fmt.Errorf("invalid dedup: %w", err)
+		}
+	}
+	if r.Debounce != nil {
+		if err := r.Debounce.Validate(); err != nil {
+			return fmt.Errorf("invalid debounce: %w", err)
+		}
+	}
+	if r.Sort != nil {
+		if err := r.Sort.Validate(); err != nil {
+			return fmt.Errorf("invalid sort: %w", err)
+		}
+	}
+	if !IsValidPriority(r.Priority) {
+		return fmt.Errorf("invalid priority: %v", r.Priority)
+	}
+	if r.Logging != nil {
+		if err := r.Logging.Validate(); err != nil {
+			return fmt.Errorf("invalid logging: %w", err)
+		}
+	}
+	if r.Alerting != nil {
+		if err := r.Alerting.Validate(); err != nil {
+			return fmt.Errorf("invalid alerting: %w", err)
+		}
+	}
+	if err := r.Actions.Validate(); err != nil {
+		return fmt.Errorf("invalid actions: %w", err)
+	}
 	//if r.Transcoder != nil {
 	//	return r.Transcoder.Validate()
 	//}
@@ -115,6 +206,39 @@ func (r *Rule) Init(ctx context.Context, fs afs.Service) error {
 	if r.Streaming != nil {
 		r.Streaming.Init()
 	}
+	if r.Sidecar != nil {
+		r.Sidecar.Init()
+	}
+	if r.Dedup != nil {
+		r.Dedup.Init()
+	}
+	if r.Debounce != nil {
+		r.Debounce.Init()
+	}
+	if r.Sort != nil {
+		r.Sort.Init(r.Schema)
+	}
+	if r.Inference != nil {
+		r.Inference.Init(r.Schema)
+	}
+	if r.Dest != nil && r.Dest.Azure != nil {
+		r.Dest.Azure.Init()
+	}
+	if r.Dest != nil && r.Dest.Database != nil {
+		r.Dest.Database.Init()
+	}
+	if r.Dest != nil && r.Dest.Elasticsearch != nil {
+		r.Dest.Elasticsearch.Init()
+	}
+	if r.Dest != nil && r.Dest.ClickHouse != nil {
+		r.Dest.ClickHouse.Init()
+	}
+	if r.Dest != nil && r.Dest.Sheets != nil {
+		r.Dest.Sheets.Init()
+	}
+	if r.Dest != nil && r.Dest.Retention != nil {
+		r.Dest.Retention.Init()
+	}
 	if r.Schema != nil && len(r.Schema.Fields) > 0 {
 		for i := range r.Schema.Fields {
 			r.Schema.Fields[i].Init()
@@ -182,10 +306,10 @@ func (r *Rule) HasMatch(URL string) bool {
 //Resources returns rule resource
 func (r *Rule) Resources() []*Resource {
 	var result = make([]*Resource, 0)
-	if r.Source.Credentials != nil || r.Source.CustomKey != nil {
+	if r.Source.Credentials != nil || r.Source.CustomKey != nil || r.Source.TLS != nil || r.Source.Proxy != nil {
 		result = append(result, r.Source)
 	}
-	if r.Dest.Credentials != nil || r.Dest.CustomKey != nil {
+	if r.Dest.Credentials != nil || r.Dest.CustomKey != nil || r.Dest.TLS != nil || r.Dest.Proxy != nil {
 		result = append(result, r.Dest)
 	}
 	return result
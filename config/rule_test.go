@@ -198,3 +198,138 @@ func TestRoute_Name(t *testing.T) {
 	}
 
 }
+
+func TestRule_Evaluate(t *testing.T) {
+
+	var useCases = []struct {
+		description string
+		Rule
+		URL          string
+		size         int64
+		expectMatch  bool
+		expectReason string
+	}{
+		{
+			description: "prefix match",
+			Rule: Rule{
+				Source: &Resource{Basic: matcher.Basic{Prefix: "/folder/"}},
+			},
+			URL:          "gs://bucket/folder/abc.csv",
+			expectMatch:  true,
+			expectReason: ReasonMatched,
+		},
+		{
+			description: "disabled",
+			Rule: Rule{
+				Disabled: true,
+				Source:   &Resource{Basic: matcher.Basic{Prefix: "/folder/"}},
+			},
+			URL:          "gs://bucket/folder/abc.csv",
+			expectMatch:  true,
+			expectReason: ReasonDisabled,
+		},
+		{
+			description: "bucket mismatch",
+			Rule: Rule{
+				Source: &Resource{Bucket: "other", Basic: matcher.Basic{Prefix: "/folder/"}},
+			},
+			URL:          "gs://bucket/folder/abc.csv",
+			expectReason: ReasonBucketMismatch,
+		},
+		{
+			description: "prefix mismatch",
+			Rule: Rule{
+				Source: &Resource{Basic: matcher.Basic{Prefix: "/data/"}},
+			},
+			URL:          "gs://bucket/folder/abc.csv",
+			expectReason: ReasonPrefixMismatch,
+		},
+		{
+			description: "suffix mismatch",
+			Rule: Rule{
+				Source: &Resource{Basic: matcher.Basic{Prefix: "/folder/", Suffix: ".tsv"}},
+			},
+			URL:          "gs://bucket/folder/abc.csv",
+			expectReason: ReasonSuffixMismatch,
+		},
+		{
+			description: "exclusion",
+			Rule: Rule{
+				Source: &Resource{Basic: matcher.Basic{Prefix: "/folder/", Exclusion: `\.tmp$`, Filter: `^/data/`}},
+			},
+			URL:          "gs://bucket/folder/abc.tmp",
+			expectReason: ReasonExclusion,
+		},
+		{
+			description: "filter mismatch",
+			Rule: Rule{
+				Source: &Resource{Basic: matcher.Basic{Filter: `^\/[a-z]+/data/\d+/`}},
+			},
+			URL:          "gs://bucket/folder/abc.csv",
+			expectReason: ReasonFilterMismatch,
+		},
+		{
+			description: "glob mismatch",
+			Rule: Rule{
+				Source: &Resource{Basic: matcher.Basic{Prefix: "/folder/"}, Glob: "folder/**/*.gz"},
+			},
+			URL:          "gs://bucket/folder/abc.csv",
+			expectReason: ReasonGlobMismatch,
+		},
+		{
+			description: "glob match",
+			Rule: Rule{
+				Source: &Resource{Glob: "folder/**/*.csv"},
+			},
+			URL:          "gs://bucket/folder/abc.csv",
+			expectMatch:  true,
+			expectReason: ReasonMatched,
+		},
+		{
+			description: "placeholder skip",
+			Rule: Rule{
+				OnEmpty: EmptySkip,
+				Source:  &Resource{Basic: matcher.Basic{Prefix: "/folder/"}},
+			},
+			URL:          "gs://bucket/folder/abc.csv",
+			expectMatch:  true,
+			expectReason: ReasonPlaceholder,
+		},
+		{
+			description: "placeholder mirrored",
+			Rule: Rule{
+				OnEmpty: EmptyMirror,
+				Source:  &Resource{Basic: matcher.Basic{Prefix: "/folder/"}},
+			},
+			URL:          "gs://bucket/folder/abc.csv",
+			expectMatch:  true,
+			expectReason: ReasonMatched,
+		},
+		{
+			description: "overflow",
+			Rule: Rule{
+				Source: &Resource{Basic: matcher.Basic{Prefix: "/folder/"}, Overflow: &Overflow{SizeMB: 1}},
+			},
+			URL:          "gs://bucket/folder/abc.csv",
+			size:         2 * 1024 * 1024,
+			expectMatch:  true,
+			expectReason: ReasonOverflow,
+		},
+		{
+			description: "below overflow size",
+			Rule: Rule{
+				Source: &Resource{Basic: matcher.Basic{Prefix: "/folder/"}, Overflow: &Overflow{SizeMB: 1}},
+			},
+			URL:          "gs://bucket/folder/abc.csv",
+			size:         1024,
+			expectMatch:  true,
+			expectReason: ReasonMatched,
+		},
+	}
+
+	for _, useCase := range useCases {
+		actual := useCase.EvaluateObject(useCase.URL, useCase.size)
+		assert.EqualValues(t, useCase.expectMatch, actual.Matched, useCase.description)
+		assert.EqualValues(t, useCase.expectReason, actual.Reason, useCase.description)
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/shared"
+	"sort"
+	"sync"
+	"time"
+)
+
+//RuleFileStatus represents rule file load status
+type RuleFileStatus struct {
+	URL     string
+	ModTime time.Time
+	Status  string
+	Error   string `json:",omitempty"`
+	//Rules number of active rules loaded from the file
+	Rules int
+	//Stale previous good version is kept active, since the current version failed to load
+	Stale bool `json:",omitempty"`
+	//LoadedAt time active version was loaded
+	LoadedAt time.Time `json:",omitempty"`
+	//Warnings rule layout deprecation warnings, older layouts are upgraded at load time
+	Warnings []string `json:",omitempty"`
+}
+
+//ruleFiles tracks rule files status and last good version rules
+type ruleFiles struct {
+	mux      sync.RWMutex
+	statuses map[string]*RuleFileStatus
+	good     map[string][]*Rule
+	onReload func(statuses []*RuleFileStatus)
+}
+
+//loaded records successfully loaded rule file
+func (f *ruleFiles) loaded(URL string, modTime time.Time, rules []*Rule, warnings []string) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	f.good[URL] = rules
+	f.statuses[URL] = &RuleFileStatus{URL: URL, ModTime: modTime, Status: base.StatusOK, Rules: len(rules), LoadedAt: time.Now(), Warnings: warnings}
+}
+
+//failed records rule file load error, it returns previous good version rules to keep them active
+func (f *ruleFiles) failed(URL string, modTime time.Time, err error) []*Rule {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	status := &RuleFileStatus{URL: URL, ModTime: modTime, Status: base.StatusError, Error: err.Error()}
+	previous, ok := f.good[URL]
+	if ok {
+		status.Stale = true
+		status.Rules = len(previous)
+		if prev, has := f.statuses[URL]; has {
+			status.LoadedAt = prev.LoadedAt
+		}
+	}
+	f.statuses[URL] = status
+	return previous
+}
+
+//retain removes status of rule files that no longer exist, it returns removed rule file URLs
+func (f *ruleFiles) retain(URLs map[string]bool) []string {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	var removed []string
+	for URL := range f.statuses {
+		if !URLs[URL] {
+			delete(f.statuses, URL)
+			delete(f.good, URL)
+			removed = append(removed, URL)
+		}
+	}
+	return removed
+}
+
+//list returns rule files status copies sorted by URL
+func (f *ruleFiles) list() []*RuleFileStatus {
+	f.mux.RLock()
+	defer f.mux.RUnlock()
+	var result = make([]*RuleFileStatus, 0, len(f.statuses))
+	for _, status := range f.statuses {
+		clone := *status
+		result = append(result, &clone)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].URL < result[j].URL })
+	return result
+}
+
+func newRuleFiles() *ruleFiles {
+	return &ruleFiles{statuses: map[string]*RuleFileStatus{}, good: map[string][]*Rule{}}
+}
+
+//LogRuleFiles logs rule files failed to load and deprecation warnings, it is used as OnReload handler
+func LogRuleFiles(statuses []*RuleFileStatus) {
+	for _, status := range statuses {
+		for _, warning := range status.Warnings {
+			shared.LogF("rule file %v: %v\n", status.URL, warning)
+		}
+		if status.Error == "" {
+			continue
+		}
+		if status.Stale {
+			shared.LogF("rule file %v failed to load, previous version (%v rules) is kept active: %v\n", status.URL, status.Rules, status.Error)
+			continue
+		}
+		shared.LogF("rule file %v failed to load: %v\n", status.URL, status.Error)
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/shared"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	//snapshotTimeLayout fixed width, lexicographically sortable snapshot time layout
+	snapshotTimeLayout = "20060102T150405.000000000Z"
+	//snapshotDeleted rule file removal snapshot version
+	snapshotDeleted = "deleted"
+)
+
+//RuleHistory represents rule files history, each loaded rule file version is kept as a snapshot, so the rules active
+//at a given time can be restored; snapshots are stored as URL/<rule file path>/<time>_<config version>.<ext>
+type RuleHistory struct {
+	//URL snapshots base URL (outside Mirrors.BaseURL)
+	URL string
+}
+
+//ruleSnapshot represents rule file snapshot
+type ruleSnapshot struct {
+	URL      string
+	RulePath string
+	Time     time.Time
+	Version  string
+}
+
+//Validate checks if rule history settings are valid
+func (h *RuleHistory) Validate(baseURL string) error {
+	if h.URL == "" {
+		return fmt.Errorf("invalid History: URL was empty")
+	}
+	if baseURL != "" && strings.HasPrefix(strings.TrimRight(h.URL, "/")+"/", strings.TrimRight(baseURL, "/")+"/") {
+		return fmt.Errorf("invalid History: URL %v can not be under rules BaseURL %v", h.URL, baseURL)
+	}
+	return nil
+}
+
+//record stores rule file version snapshot unless it already exists
+func (h *RuleHistory) record(ctx context.Context, fs afs.Service, baseURL, ruleURL string, modTime time.Time, version string, data []byte) error {
+	snapshotURL := h.snapshotURL(baseURL, ruleURL, modTime, version+path.Ext(ruleURL))
+	if exists, _ := fs.Exists(ctx, snapshotURL); exists {
+		return nil
+	}
+	return fs.Upload(ctx, snapshotURL, file.DefaultFileOsMode, bytes.NewReader(data))
+}
+
+//remove stores rule file removal snapshot
+func (h *RuleHistory) remove(ctx context.Context, fs afs.Service, baseURL, ruleURL string, removed time.Time) error {
+	return fs.Upload(ctx, h.snapshotURL(baseURL, ruleURL, removed, snapshotDeleted), file.DefaultFileOsMode, bytes.NewReader([]byte{}))
+}
+
+func (h *RuleHistory) snapshotURL(baseURL, ruleURL string, at time.Time, suffix string) string {
+	return url.Join(h.URL, rulePath(baseURL, ruleURL), at.UTC().Format(snapshotTimeLayout)+"_"+suffix)
+}
+
+//snapshots returns the latest snapshot of each rule file recorded at or before supplied time, removed rule files are skipped
+func (h *RuleHistory) snapshots(ctx context.Context, fs afs.Service, at time.Time) ([]*ruleSnapshot, error) {
+	exists, err := fs.Exists(ctx, h.URL)
+	if err != nil || !exists {
+		return nil, err
+	}
+	objects, err := fs.List(ctx, h.URL, option.NewRecursive(true))
+	if err != nil {
+		return nil, err
+	}
+	var latest = make(map[string]*ruleSnapshot)
+	for _, object := range objects {
+		if object.IsDir() {
+			continue
+		}
+		snapshot := h.snapshot(object.URL())
+		if snapshot == nil || snapshot.Time.After(at) {
+			continue
+		}
+		if prev, ok := latest[snapshot.RulePath]; ok && !snapshot.Time.After(prev.Time) {
+			continue
+		}
+		latest[snapshot.RulePath] = snapshot
+	}
+	var result = make([]*ruleSnapshot, 0, len(latest))
+	for _, snapshot := range latest {
+		if snapshot.Version != snapshotDeleted {
+			result = append(result, snapshot)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].RulePath < result[j].RulePath })
+	return result, nil
+}
+
+//snapshot parses snapshot URL, it returns nil for unrecognized objects
+func (h *RuleHistory) snapshot(URL string) *ruleSnapshot {
+	parent, name := url.Split(URL, file.Scheme)
+	index := strings.Index(name, "_")
+	if index == -1 {
+		return nil
+	}
+	at, err := time.Parse(snapshotTimeLayout, name[:index])
+	if err != nil {
+		return nil
+	}
+	version := name[index+1:]
+	if ext := path.Ext(version); ext != "" {
+		version = version[:len(version)-len(ext)]
+	}
+	return &ruleSnapshot{URL: URL, RulePath: rulePath(h.URL, parent), Time: at, Version: version}
+}
+
+//rulePath returns URL path relative to base URL
+func rulePath(baseURL, URL string) string {
+	return strings.Trim(strings.TrimPrefix(URL, strings.TrimRight(baseURL, "/")), "/")
+}
+
+//recordRemoved stores removal snapshots of rule files that no longer exist
+func (h *RuleHistory) recordRemoved(ctx context.Context, fs afs.Service, baseURL string, URLs []string) {
+	now := time.Now()
+	for _, URL := range URLs {
+		if err := h.remove(ctx, fs, baseURL, URL, now); err != nil {
+			shared.LogF("failed to record rule file %v removal: %v\n", URL, err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRuleHistory_Validate(t *testing.T) {
+	var useCases = []struct {
+		description string
+		history     *RuleHistory
+		hasError    bool
+	}{
+		{description: "valid", history: &RuleHistory{URL: "gs://bucket/history"}},
+		{description: "empty URL", history: &RuleHistory{}, hasError: true},
+		{description: "under base URL", history: &RuleHistory{URL: "gs://bucket/rules/history"}, hasError: true},
+		{description: "base URL prefix", history: &RuleHistory{URL: "gs://bucket/rules-history"}},
+	}
+	for _, useCase := range useCases {
+		err := useCase.history.Validate("gs://bucket/rules")
+		assert.Equal(t, useCase.hasError, err != nil, useCase.description)
+	}
+}
+
+func TestRuleset_AsOf(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/rulehistory/rules"
+	historyURL := "mem://localhost/rulehistory/history"
+	_ = fs.Delete(ctx, "mem://localhost/rulehistory")
+	upload := func(name, content string) {
+		assert.Nil(t, fs.Upload(ctx, baseURL+"/"+name, 0644, strings.NewReader(content)))
+	}
+	upload("team/rule1.json", `{"Source":{"Prefix":"/data/a"},"Dest":{"URL":"mem://localhost/dest/a"}}`)
+	upload("rule2.json", `{"Source":{"Prefix":"/data/b"},"Dest":{"URL":"mem://localhost/dest/b"}}`)
+	ruleset := &Ruleset{BaseURL: baseURL, History: &RuleHistory{URL: historyURL}}
+	if !assert.Nil(t, ruleset.Load(ctx, fs)) {
+		return
+	}
+	before := time.Now()
+	time.Sleep(5 * time.Millisecond)
+
+	upload("team/rule1.json", `{"Source":{"Prefix":"/data/a"},"Dest":{"URL":"mem://localhost/dest/a2"}}`)
+	assert.Nil(t, fs.Delete(ctx, baseURL+"/rule2.json"))
+	if !assert.Nil(t, ruleset.Reload(ctx, fs)) {
+		return
+	}
+	assert.EqualValues(t, 1, len(ruleset.Snapshot()))
+
+	then, err := ruleset.AsOf(ctx, fs, before)
+	if !assert.Nil(t, err) {
+		return
+	}
+	if assert.EqualValues(t, 2, len(then.Rules), "removed rule file was active then") {
+		assert.EqualValues(t, baseURL+"/rule2.json", then.Rules[0].Info.URL)
+		assert.EqualValues(t, baseURL+"/team/rule1.json", then.Rules[1].Info.URL)
+		assert.EqualValues(t, "mem://localhost/dest/a", then.Rules[1].Dest.URL)
+	}
+	now, err := ruleset.AsOf(ctx, fs, time.Now())
+	if !assert.Nil(t, err) {
+		return
+	}
+	if assert.EqualValues(t, 1, len(now.Rules)) {
+		assert.EqualValues(t, "mem://localhost/dest/a2", now.Rules[0].Dest.URL)
+		assert.EqualValues(t, ruleset.Snapshot()[0].ConfigVersion, now.Rules[0].ConfigVersion)
+	}
+	none, err := ruleset.AsOf(ctx, fs, before.Add(-time.Hour))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(none.Rules), "no rules before history")
+
+	_, err = (&Ruleset{BaseURL: baseURL}).AsOf(ctx, fs, before)
+	assert.NotNil(t, err, "history was not configured")
+}
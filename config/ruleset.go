@@ -1,39 +1,91 @@
 package config
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/viant/afs"
 	"github.com/viant/afs/option"
 	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/shared"
 	"github.com/viant/toolbox"
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
 	"path"
 	"github.com/viant/smirror/base"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
-//Ruleset represents route slice
+//Ruleset represents route slice, loaded rules are published as immutable snapshot,
+//so a reload never changes rules concurrently matched events iterate
 type Ruleset struct {
 	BaseURL      string
-	CheckInMs    int
+	CheckInMs    base.Milliseconds
+	//Rules initial rules, loaded rules are available with Snapshot
 	Rules        []*Rule
+	//Staging optional staged activation of pending rules
+	Staging      Staging
+	//EncryptionKey KMS key decrypting KMS encrypted rule files, SOPS files carry their own key
+	EncryptionKey string `json:",omitempty"`
+	//History optional rule files history, keeps loaded rule file versions as snapshots
+	History *RuleHistory `json:",omitempty"`
+	decrypter    Decrypter
 	meta         *base.Meta
 	initialRules []*Rule
 	inited       int32
+	files        *ruleFiles
+	snapshot     atomic.Value
+	mux          *sync.Mutex
 }
 
+//Snapshot returns current rules snapshot, returned rules must not be modified
+func (r *Ruleset) Snapshot() []*Rule {
+	if rules, ok := r.snapshot.Load().([]*Rule); ok {
+		return rules
+	}
+	return r.Rules
+}
+
+//Status returns rule files load status
+func (r *Ruleset) Status() []*RuleFileStatus {
+	if r.files == nil {
+		return []*RuleFileStatus{}
+	}
+	return r.files.list()
+}
+
+//OnReload sets handler notified with rule files status after rules are (re)loaded
+func (r *Ruleset) OnReload(handler func(statuses []*RuleFileStatus)) {
+	if r.files == nil {
+		r.files = newRuleFiles()
+	}
+	r.files.mux.Lock()
+	defer r.files.mux.Unlock()
+	r.files.onReload = handler
+}
+
+//OnDecrypt sets decrypter used for SOPS or KMS encrypted rule files
+func (r *Ruleset) OnDecrypt(decrypter Decrypter) {
+	r.decrypter = decrypter
+	r.Staging.decrypt = func(ctx context.Context, data []byte, ext string) ([]byte, error) {
+		return DecryptRuleFile(ctx, data, ext, r.EncryptionKey, decrypter)
+	}
+}
 
 //Match returns the first match route
-func (r Ruleset) Rule(URL string) *Rule {
-	for i := range r.Rules {
-		if r.Rules[i].Info.URL == URL {
-			return r.Rules[i]
+func (r *Ruleset) Rule(URL string) *Rule {
+	rules := r.Snapshot()
+	for i := range rules {
+		if rules[i].Info.URL == URL {
+			return rules[i]
 		}
 	}
 	return nil
@@ -41,15 +93,16 @@ func (r Ruleset) Rule(URL string) *Rule {
 
 
 //Match returns the first match route
-func (r Ruleset) Match(URL string) (matched []*Rule) {
+func (r *Ruleset) Match(URL string) (matched []*Rule) {
+	rules := r.Snapshot()
 	ruleURL := "."
-	for i := range r.Rules {
-		if r.Rules[i].HasMatch(URL) {
-			if ruleURL == r.Rules[i].Info.URL {
+	for i := range rules {
+		if rules[i].HasMatch(URL) {
+			if ruleURL == rules[i].Info.URL {
 				continue
 			}
-			ruleURL = r.Rules[i].Info.URL
-			matched = append(matched, r.Rules[i])
+			ruleURL = rules[i].Info.URL
+			matched = append(matched, rules[i])
 		}
 	}
 	return matched
@@ -57,7 +110,15 @@ func (r Ruleset) Match(URL string) (matched []*Rule) {
 
 
 
-func (r Ruleset) Validate() error {
+func (r *Ruleset) Validate() error {
+	if err := r.Staging.Validate(r.BaseURL); err != nil {
+		return err
+	}
+	if r.History != nil {
+		if err := r.History.Validate(r.BaseURL); err != nil {
+			return err
+		}
+	}
 	if len(r.Rules) == 0 {
 		return nil
 	}
@@ -69,7 +130,7 @@ func (r Ruleset) Validate() error {
 	return nil
 }
 
-func (r Ruleset) Init(ctx context.Context, fs afs.Service) error {
+func (r *Ruleset) Init(ctx context.Context, fs afs.Service) error {
 	if len(r.Rules) == 0 {
 		return nil
 	}
@@ -86,18 +147,45 @@ func (r *Ruleset) Load(ctx context.Context, fs afs.Service) error {
 	if err := r.initRules(); err != nil {
 		return err
 	}
+	r.Staging.Init()
+	if r.mux == nil {
+		r.mux = &sync.Mutex{}
+	}
+	if r.files == nil {
+		r.files = newRuleFiles()
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
 	r.meta = base.NewMeta(r.BaseURL, time.Duration(r.CheckInMs)*time.Millisecond)
 	return r.load(ctx, fs)
 }
 
+//Reload forces rules reload
+func (r *Ruleset) Reload(ctx context.Context, fs afs.Service) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.meta = base.NewMeta(r.BaseURL, time.Duration(r.CheckInMs)*time.Millisecond)
+	_, err := r.reloadIfNeeded(ctx, fs)
+	return err
+}
+
 func (r *Ruleset) load(ctx context.Context, fs afs.Service) (err error) {
-	if err = r.loadAllResources(ctx, fs); err != nil {
+	rules, err := r.loadAllResources(ctx, fs)
+	if err != nil {
 		return err
 	}
+	r.snapshot.Store(rules)
 	return nil
 }
 
+//ReloadIfNeeded reloads and publishes new rules snapshot if rules changed, concurrent reloads are serialized
 func (r *Ruleset) ReloadIfNeeded(ctx context.Context, fs afs.Service) (bool, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.reloadIfNeeded(ctx, fs)
+}
+
+func (r *Ruleset) reloadIfNeeded(ctx context.Context, fs afs.Service) (bool, error) {
 	changed, err := r.meta.HasChanged(ctx, fs)
 	if err != nil || !changed {
 		return changed, err
@@ -105,37 +193,54 @@ func (r *Ruleset) ReloadIfNeeded(ctx context.Context, fs afs.Service) (bool, err
 	return true, r.load(ctx, fs)
 }
 
-func (c *Ruleset) loadAllResources(ctx context.Context, fs afs.Service) error {
+//loadAllResources returns initial rules followed by rules loaded from BaseURL as a new slice
+func (c *Ruleset) loadAllResources(ctx context.Context, fs afs.Service) ([]*Rule, error) {
+	rules := append(make([]*Rule, 0, len(c.initialRules)), c.initialRules...)
 	if c.BaseURL == "" {
-		return nil
+		return rules, nil
 	}
-	c.Rules = c.initialRules
 	exists, err := fs.Exists(ctx, c.BaseURL)
 	if err != nil || !exists {
-		return err
+		return rules, err
 	}
 	fs.Delete(ctx,"s3://viant-dataflow-config/StorageMirror/_.cache")
 	routesObject, err := fs.List(ctx, c.BaseURL, option.NewRecursive(true))
 	if err != nil {
-		return err
+		return nil, err
 	}
+	var URLs = make(map[string]bool)
 	for _, object := range routesObject {
 		if object.IsDir()  || ! (path.Ext(object.Name()) == ".json" || path.Ext(object.Name()) == ".yaml") {
 			continue
 		}
-
-		if err = c.loadResources(ctx, fs, object); err != nil {
-			//Report error, let the other rules work fine
+		URLs[object.URL()] = true
+		loaded, warnings, err := c.loadResources(ctx, fs, object)
+		if err != nil {
+			//Report error, keep previous good version, let the other rules work fine
 			fmt.Println(err)
+			loaded = c.files.failed(object.URL(), object.ModTime(), err)
+		} else {
+			c.files.loaded(object.URL(), object.ModTime(), loaded, warnings)
 		}
+		rules = append(rules, loaded...)
 	}
-	return nil
+	removed := c.files.retain(URLs)
+	if c.History != nil && len(removed) > 0 {
+		c.History.recordRemoved(ctx, fs, c.BaseURL, removed)
+	}
+	c.files.mux.RLock()
+	onReload := c.files.onReload
+	c.files.mux.RUnlock()
+	if onReload != nil {
+		onReload(c.files.list())
+	}
+	return rules, nil
 }
 
-func (c *Ruleset) loadResources(ctx context.Context, fs afs.Service, object storage.Object) error {
+func (c *Ruleset) loadResources(ctx context.Context, fs afs.Service, object storage.Object) ([]*Rule, []string, error) {
 	reader, err := fs.Open(ctx, object)
 	if err != nil {
-		return fmt.Errorf("failed to open: %v, %w", object.URL(), err)
+		return nil, nil, fmt.Errorf("failed to open: %v, %w", object.URL(), err)
 	}
 	defer func() {
 		if reader == nil {
@@ -146,32 +251,79 @@ func (c *Ruleset) loadResources(ctx context.Context, fs afs.Service, object stor
 
 	data, err := ioutil.ReadAll(reader)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	rules, warnings, err := c.decodeRules(ctx, fs, object.URL(), data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.History != nil {
+		if err := c.History.record(ctx, fs, c.BaseURL, object.URL(), object.ModTime(), rules[0].ConfigVersion, data); err != nil {
+			shared.LogF("failed to record rule file %v snapshot: %v\n", object.URL(), err)
+		}
+	}
+	return rules, warnings, nil
+}
+
+//decodeRules decrypts, decodes and validates rule file data
+func (c *Ruleset) decodeRules(ctx context.Context, fs afs.Service, URL string, data []byte) ([]*Rule, []string, error) {
+	ext := path.Ext(URL)
+	decrypted, err := DecryptRuleFile(ctx, data, ext, c.EncryptionKey, c.decrypter)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to decrypt rules: %v", URL)
 	}
-	rules, err := loadRules(data, path.Ext(object.Name()))
+	rules, warnings, err := loadRules(decrypted, ext)
 	if err != nil {
-		return errors.Wrapf(err, "failed to load rules: %v", object.URL())
+		return nil, nil, errors.Wrapf(err, "failed to load rules: %v", URL)
+	}
+	if len(rules) == 0 {
+		return nil, nil, errors.Errorf("no rules found: %v", URL)
 	}
 	transientRoutes := Ruleset{Rules: rules}
-	transientRoutes.Rules[0].Info.URL = object.URL()
+	transientRoutes.Rules[0].Info.URL = URL
 	if err := transientRoutes.Init(ctx, fs); err != nil {
-		return errors.Wrapf(err, "invalid rule: %v", object.URL())
+		return nil, nil, errors.Wrapf(err, "invalid rule: %v", URL)
 	}
 	if err := transientRoutes.Validate(); err != nil {
-		return errors.Wrapf(err, "invalid rule: %v", object.URL())
+		return nil, nil, errors.Wrapf(err, "invalid rule: %v", URL)
 	}
+	configVersion := md5.Sum(decrypted)
 	for i := range rules {
-		rules[i].Info.URL = object.URL()
+		rules[i].Info.URL = URL
+		rules[i].ConfigVersion = hex.EncodeToString(configVersion[:])
 		if rules[i].Info.Workflow == "" {
-			name := object.Name()
+			name := path.Base(URL)
 			if strings.HasSuffix(name, ".json") {
 				name = string(name[:len(name)-5])
 			}
 			rules[i].Info.Workflow = name
 		}
-		c.Rules = append(c.Rules, rules[i])
 	}
-	return nil
+	return rules, warnings, nil
+}
+
+//AsOf returns rules active at supplied time restored from rule files history
+func (c *Ruleset) AsOf(ctx context.Context, fs afs.Service, at time.Time) (*Ruleset, error) {
+	if c.History == nil {
+		return nil, errors.New("rule files history was not configured")
+	}
+	snapshots, err := c.History.snapshots(ctx, fs, at)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list rule files history: %v", c.History.URL)
+	}
+	result := &Ruleset{BaseURL: c.BaseURL, Rules: append([]*Rule{}, c.initialRules...)}
+	for _, snapshot := range snapshots {
+		data, err := fs.DownloadWithURL(ctx, snapshot.URL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to download rule file snapshot: %v", snapshot.URL)
+		}
+		rules, _, err := c.decodeRules(ctx, fs, url.Join(c.BaseURL, snapshot.RulePath), data)
+		if err != nil {
+			return nil, err
+		}
+		result.Rules = append(result.Rules, rules...)
+	}
+	return result, nil
 }
 
 func (r *Ruleset) initRules() error {
@@ -188,34 +340,72 @@ func (r *Ruleset) initRules() error {
 	return nil
 }
 
-func loadRules(data []byte, ext string) ([]*Rule, error) {
+//loadRules decodes rule file and upgrades older rule layouts, it returns deprecation warnings
+func loadRules(data []byte, ext string) ([]*Rule, []string, error) {
 	if ext == "" {
-		return nil, nil
+		return nil, nil, nil
 	}
 	var rules = make([]*Rule, 0)
 	switch ext {
 	case base.YAMLExt:
 		ruleMap := map[string]interface{}{}
 		if err := yaml.Unmarshal(data, &ruleMap); err != nil {
-			rulesMap := []map[string]interface{}{}
+			rulesMap := []interface{}{}
 			err = json.Unmarshal(data, &rulesMap)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
+			}
+			warnings, err := migrateRules(rulesMap)
+			if err != nil {
+				return nil, nil, err
 			}
 			err = toolbox.DefaultConverter.AssignConverted(&rules, rulesMap)
-			return rules, err
+			return rules, warnings, err
+		}
+		warnings, err := migrateRule(ruleMap)
+		if err != nil {
+			return nil, nil, err
 		}
 		rule := &Rule{}
-		err := toolbox.DefaultConverter.AssignConverted(&rule, ruleMap)
+		err = toolbox.DefaultConverter.AssignConverted(&rule, ruleMap)
 		rules = append(rules, rule)
-		return rules, err
+		return rules, warnings, err
 	default:
+		warnings, migrated, err := migrateJSONRules(data)
+		if err != nil {
+			return nil, nil, err
+		}
 		rule := &Rule{}
-		if err := json.Unmarshal(data, rule); err != nil {
-			err = json.Unmarshal(data, &rules)
-			return rules, err
+		if err := json.Unmarshal(migrated, rule); err != nil {
+			err = json.Unmarshal(migrated, &rules)
+			return rules, warnings, err
 		}
 		rules = append(rules, rule)
+		return rules, warnings, nil
 	}
-	return rules, nil
+}
+
+//migrateJSONRules upgrades JSON rule or rules layout, it returns deprecation warnings and upgraded JSON
+func migrateJSONRules(data []byte) ([]string, []byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, nil, err
+	}
+	var warnings []string
+	var err error
+	switch actual := decoded.(type) {
+	case map[string]interface{}:
+		warnings, err = migrateRule(actual)
+	case []interface{}:
+		warnings, err = migrateRules(actual)
+	default:
+		return nil, data, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	migrated, err := json.Marshal(decoded)
+	return warnings, migrated, err
 }
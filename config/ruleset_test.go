@@ -1,8 +1,12 @@
 package config
 
 import (
+	"context"
 	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
 	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"strings"
 	"testing"
 )
 
@@ -93,3 +97,52 @@ func TestRoutes_HasMatch(t *testing.T) {
 		assert.Equal(t, useCase.expectURL, actual.Dest.URL, useCase.description)
 	}
 }
+
+func TestRuleset_Load(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/ruleset/rules"
+	_ = fs.Delete(ctx, baseURL)
+	upload := func(name, content string) {
+		assert.Nil(t, fs.Upload(ctx, baseURL+"/"+name, 0644, strings.NewReader(content)))
+	}
+	upload("rule1.json", `{"Source":{"Prefix":"/data/a"},"Dest":{"URL":"mem://localhost/dest/a"}}`)
+	upload("rule2.json", `{"Source":{"Prefix":"/data/b"},"Dest":{"URL":"mem://localhost/dest/b"}}`)
+	upload("rule3.json", `{"Source":{"Prefix":"/data/c"},`)
+
+	var notified []*RuleFileStatus
+	ruleset := &Ruleset{BaseURL: baseURL}
+	ruleset.OnReload(func(statuses []*RuleFileStatus) {
+		notified = statuses
+	})
+	if !assert.Nil(t, ruleset.Load(ctx, fs)) {
+		return
+	}
+	assert.EqualValues(t, 2, len(ruleset.Snapshot()), "broken file does not abort loading")
+	assert.Len(t, ruleset.Snapshot()[0].ConfigVersion, 32, "rule file content digest")
+	statuses := ruleset.Status()
+	if !assert.EqualValues(t, 3, len(statuses)) {
+		return
+	}
+	assert.EqualValues(t, base.StatusOK, statuses[0].Status)
+	assert.EqualValues(t, base.StatusError, statuses[2].Status)
+	assert.NotEmpty(t, statuses[2].Error)
+	assert.False(t, statuses[2].Stale, "no previous version")
+	assert.EqualValues(t, statuses, notified)
+
+	upload("rule2.json", `{"Source":{"Prefix":"/data/b"`)
+	if !assert.Nil(t, ruleset.Reload(ctx, fs)) {
+		return
+	}
+	assert.EqualValues(t, 2, len(ruleset.Snapshot()), "previous good version is kept active")
+	assert.EqualValues(t, 1, len(ruleset.Match("mem://localhost/data/b/1.csv")))
+	statuses = ruleset.Status()
+	assert.EqualValues(t, base.StatusError, statuses[1].Status)
+	assert.True(t, statuses[1].Stale)
+	assert.EqualValues(t, 1, statuses[1].Rules)
+	assert.False(t, statuses[1].LoadedAt.IsZero())
+
+	assert.Nil(t, fs.Delete(ctx, baseURL+"/rule3.json"))
+	assert.Nil(t, ruleset.Reload(ctx, fs))
+	assert.EqualValues(t, 2, len(ruleset.Status()), "removed file status is dropped")
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	//kmsARNPrefix AWS KMS key ARN prefix
+	kmsARNPrefix = "arn:aws:kms:"
+	//kmsAliasPrefix AWS KMS alias prefix
+	kmsAliasPrefix = "alias/"
+)
+
+//RequesterPays represents requester-pays storage option, s3 requests are sent with requester payer, gs requests are billed to UserProject
+type RequesterPays struct {
+	Enabled     bool
+	UserProject string
+}
+
+//KMSKey represents S3 SSE-KMS storage option, used to read from and write to KMS encrypted bucket
+type KMSKey struct {
+	ARN string
+}
+
+//NewRequesterPays creates requester pays option
+func NewRequesterPays(enabled bool, userProject string) *RequesterPays {
+	return &RequesterPays{Enabled: enabled, UserProject: userProject}
+}
+
+//NewKMSKey creates SSE-KMS key option
+func NewKMSKey(ARN string) *KMSKey {
+	return &KMSKey{ARN: ARN}
+}
+
+//validateKMSKeyARN checks if key is either KMS key ARN or alias
+func validateKMSKeyARN(key string) error {
+	if strings.HasPrefix(key, kmsARNPrefix) || strings.HasPrefix(key, kmsAliasPrefix) {
+		return nil
+	}
+	return fmt.Errorf("invalid KMSKeyARN: %v, expected %v... or %v...", key, kmsARNPrefix, kmsAliasPrefix)
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var scheduleFieldBounds = [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+
+var scheduleMacros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+//Schedule represents standard 5 field cron expression: minute hour day-of-month month day-of-week
+type Schedule struct {
+	fields [5]uint64
+	//anyDayOf... flags unrestricted day field, cron matches either day field when both are restricted
+	anyDayOfMonth bool
+	anyDayOfWeek  bool
+}
+
+//Match returns true if supplied time minute matches schedule
+func (s *Schedule) Match(t time.Time) bool {
+	if !s.has(0, t.Minute()) || !s.has(1, t.Hour()) || !s.has(3, int(t.Month())) {
+		return false
+	}
+	dayOfMonth := s.has(2, t.Day())
+	dayOfWeek := s.has(4, int(t.Weekday()))
+	if s.anyDayOfMonth || s.anyDayOfWeek {
+		return dayOfMonth && dayOfWeek
+	}
+	return dayOfMonth || dayOfWeek
+}
+
+func (s *Schedule) has(field, value int) bool {
+	return s.fields[field]&(1<<uint(value)) != 0
+}
+
+//NewSchedule parses cron expression, supports *, lists, ranges, steps and @hourly, @daily, @weekly, @monthly, @yearly macros
+func NewSchedule(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := scheduleMacros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("invalid cron expression: %q, expected 5 fields", expr)
+	}
+	result := &Schedule{}
+	for i, part := range parts {
+		bits, err := parseScheduleField(part, scheduleFieldBounds[i][0], scheduleFieldBounds[i][1], i == 4)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression: %q, %v", expr, err)
+		}
+		result.fields[i] = bits
+	}
+	result.anyDayOfMonth = parts[2] == "*" || parts[2] == "?"
+	result.anyDayOfWeek = parts[4] == "*" || parts[4] == "?"
+	return result, nil
+}
+
+func parseScheduleField(field string, min, max int, dayOfWeek bool) (uint64, error) {
+	var result uint64
+	for _, item := range strings.Split(field, ",") {
+		step := 1
+		if index := strings.Index(item, "/"); index != -1 {
+			value, err := strconv.Atoi(item[index+1:])
+			if err != nil || value <= 0 {
+				return 0, fmt.Errorf("invalid step: %v", item)
+			}
+			step = value
+			item = item[:index]
+		}
+		lower, upper := min, max
+		switch {
+		case item == "*" || item == "?":
+		case strings.Contains(item, "-"):
+			bounds := strings.SplitN(item, "-", 2)
+			var err error
+			if lower, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("invalid range: %v", item)
+			}
+			if upper, err = strconv.Atoi(bounds[1]); err != nil {
+				return 0, fmt.Errorf("invalid range: %v", item)
+			}
+		default:
+			value, err := strconv.Atoi(item)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value: %v", item)
+			}
+			lower = value
+			if step == 1 {
+				upper = value
+			}
+		}
+		if dayOfWeek && upper == 7 { //Sunday as 7
+			if lower == 7 {
+				lower, upper = 0, 0
+			} else {
+				upper = 6
+				result |= 1
+			}
+		}
+		if lower < min || upper > max || lower > upper {
+			return 0, fmt.Errorf("value out of range %v-%v: %v", min, max, item)
+		}
+		for i := lower; i <= upper; i += step {
+			result |= 1 << uint(i)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"strings"
+)
+
+const (
+	//SheetsModeAppend appends CSV rows after last sheet row
+	SheetsModeAppend = "append"
+	//SheetsModeReplace clears sheet before writing CSV rows
+	SheetsModeReplace = "replace"
+
+	defaultSheetsMaxBytes = 1024 * 1024
+	maxSheetsMaxBytes     = 10 * 1024 * 1024
+)
+
+//Sheets represents Google Sheet destination for small CSV files
+type Sheets struct {
+	//SpreadsheetID spreadsheet ID from sheet URL
+	SpreadsheetID string
+	//Sheet sheet (tab) name, Sheet1 by default
+	Sheet string `json:",omitempty"`
+	//Mode append (default) or replace
+	Mode string `json:",omitempty"`
+	//Delimiter CSV delimiter, comma by default
+	Delimiter string `json:",omitempty"`
+	//Header first CSV line holds column names, it is skipped in append mode
+	Header bool `json:",omitempty"`
+	//RawValues writes values as is, otherwise values are parsed as if typed by user (numbers, dates, formulas)
+	RawValues bool `json:",omitempty"`
+	//MaxBytes max CSV size, 1MB by default, 10MB at most
+	MaxBytes int `json:",omitempty"`
+}
+
+//Init initialises defaults
+func (s *Sheets) Init() {
+	if s.Sheet == "" {
+		s.Sheet = "Sheet1"
+	}
+	if s.Mode == "" {
+		s.Mode = SheetsModeAppend
+	}
+	if s.Delimiter == "" {
+		s.Delimiter = ","
+	}
+	if s.MaxBytes == 0 {
+		s.MaxBytes = defaultSheetsMaxBytes
+	}
+}
+
+//Validate checks if sheets destination is valid
+func (s *Sheets) Validate() error {
+	if s.SpreadsheetID == "" {
+		return errors.New("sheets.SpreadsheetID was empty")
+	}
+	if s.Mode != SheetsModeAppend && s.Mode != SheetsModeReplace {
+		return errors.Errorf("unsupported sheets.Mode: '%v', expected %v or %v", s.Mode, SheetsModeAppend, SheetsModeReplace)
+	}
+	if len([]rune(s.Delimiter)) > 1 {
+		return errors.Errorf("invalid sheets.Delimiter: '%v'", s.Delimiter)
+	}
+	if s.MaxBytes < 0 || s.MaxBytes > maxSheetsMaxBytes {
+		return errors.Errorf("invalid sheets.MaxBytes: %v, expected up to %v", s.MaxBytes, maxSheetsMaxBytes)
+	}
+	return nil
+}
+
+//Range returns A1 notation range for the whole sheet
+func (s *Sheets) Range() string {
+	return "'" + strings.ReplaceAll(s.Sheet, "'", "''") + "'"
+}
+
+//ValueInputOption returns sheets API value input option
+func (s *Sheets) ValueInputOption() string {
+	if s.RawValues {
+		return "RAW"
+	}
+	return "USER_ENTERED"
+}
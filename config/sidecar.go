@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/smirror/digest"
+	"hash"
+	"path"
+	"strings"
+)
+
+const (
+	//SidecarMD5 md5 checksum sidecar extension
+	SidecarMD5 = ".md5"
+	//SidecarSHA256 sha256 checksum sidecar extension
+	SidecarSHA256 = ".sha256"
+	//SidecarSFV simple file verification (crc32) sidecar extension
+	SidecarSFV = ".sfv"
+	//SidecarCRC32C crc32c checksum sidecar extension
+	SidecarCRC32C = ".crc32c"
+	//SidecarXXH64 xxHash64 checksum sidecar extension
+	SidecarXXH64 = ".xxh64"
+	//SidecarBLAKE3 blake3 checksum sidecar extension
+	SidecarBLAKE3 = ".b3"
+)
+
+//sidecarAlgorithms digest algorithms by sidecar extension
+var sidecarAlgorithms = map[string]string{
+	SidecarMD5:    digest.MD5,
+	SidecarSHA256: digest.SHA256,
+	SidecarSFV:    digest.CRC32,
+	SidecarCRC32C: digest.CRC32C,
+	SidecarXXH64:  digest.XXHash64,
+	SidecarBLAKE3: digest.BLAKE3,
+}
+
+//Sidecar represents checksum sidecar manifest (i.e. file.csv.md5) settings
+type Sidecar struct {
+	//Extensions sidecar extensions, default .md5, .sha256, .sfv
+	Extensions []string `json:",omitempty"`
+	//Required delays transfer until sidecar is present, the data file is re-triggered once sidecar arrives
+	Required bool `json:",omitempty"`
+	//SkipSidecar skips mirroring sidecar itself
+	SkipSidecar bool `json:",omitempty"`
+}
+
+//Init initialises sidecar settings
+func (s *Sidecar) Init() {
+	if len(s.Extensions) == 0 {
+		s.Extensions = []string{SidecarMD5, SidecarSHA256, SidecarSFV}
+	}
+}
+
+//Validate checks if sidecar settings are valid
+func (s *Sidecar) Validate() error {
+	for _, ext := range s.Extensions {
+		if NewSidecarHash(ext) == nil {
+			return fmt.Errorf("unsupported sidecar extension: %v", ext)
+		}
+	}
+	return nil
+}
+
+//DataURL returns data file URL if URL is a sidecar
+func (s *Sidecar) DataURL(URL string) (string, bool) {
+	for _, ext := range s.Extensions {
+		if strings.HasSuffix(URL, ext) {
+			return URL[:len(URL)-len(ext)], true
+		}
+	}
+	return "", false
+}
+
+//SidecarURLs returns candidate sidecar URLs for data file URL
+func (s *Sidecar) SidecarURLs(URL string) []string {
+	var result = make([]string, 0, len(s.Extensions))
+	for _, ext := range s.Extensions {
+		result = append(result, URL+ext)
+	}
+	return result
+}
+
+//NewSidecarHash returns hash for sidecar extension
+func NewSidecarHash(ext string) hash.Hash {
+	return digest.New(SidecarAlgorithm(ext))
+}
+
+//SidecarAlgorithm returns digest algorithm for sidecar extension
+func SidecarAlgorithm(ext string) string {
+	return sidecarAlgorithms[ext]
+}
+
+//ExpectedChecksum returns hex checksum from sidecar content for data file URL
+func ExpectedChecksum(ext string, content []byte, dataURL string) (string, error) {
+	_, name := path.Split(dataURL)
+	var candidates = make([]string, 0)
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if index := strings.Index(line, ") = "); index != -1 { //BSD style: MD5 (file.csv) = hash
+			if strings.Contains(line[:index], name) {
+				return strings.ToLower(strings.TrimSpace(line[index+4:])), nil
+			}
+			candidates = append(candidates, line[index+4:])
+			continue
+		}
+		fields := strings.Fields(line)
+		checksum, fileName := fields[0], ""
+		if ext == SidecarSFV { //SFV: file.csv crc32
+			checksum = fields[len(fields)-1]
+			fileName = strings.Join(fields[:len(fields)-1], " ")
+		} else if len(fields) > 1 { //GNU style: hash  file.csv
+			fileName = strings.TrimPrefix(fields[1], "*")
+		}
+		if fileName != "" && path.Base(fileName) == name {
+			return strings.ToLower(checksum), nil
+		}
+		candidates = append(candidates, checksum)
+	}
+	if len(candidates) != 1 {
+		return "", fmt.Errorf("failed to find %v checksum in sidecar", name)
+	}
+	return strings.ToLower(strings.TrimSpace(candidates[0])), nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExpectedChecksum(t *testing.T) {
+	var useCases = []struct {
+		description string
+		ext         string
+		content     string
+		expect      string
+		hasError    bool
+	}{
+		{description: "hash only", ext: SidecarMD5, content: "D41D8CD98F00B204E9800998ECF8427E\n", expect: "d41d8cd98f00b204e9800998ecf8427e"},
+		{description: "GNU style", ext: SidecarSHA256, content: "abc  other.csv\ndef *file.csv\n", expect: "def"},
+		{description: "BSD style", ext: SidecarMD5, content: "MD5 (file.csv) = 0cc175b9c0f1b6a831c399e269772661", expect: "0cc175b9c0f1b6a831c399e269772661"},
+		{description: "SFV", ext: SidecarSFV, content: "; generated\nother.csv 11111111\nfile.csv 8BD69E52\n", expect: "8bd69e52"},
+		{description: "ambiguous", ext: SidecarMD5, content: "abc  a.csv\ndef  b.csv\n", hasError: true},
+	}
+	for _, useCase := range useCases {
+		actual, err := ExpectedChecksum(useCase.ext, []byte(useCase.content), "gs://bucket/data/file.csv")
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+		assert.Equal(t, useCase.expect, actual, useCase.description)
+	}
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"os"
+	"strings"
+)
+
+const (
+	//SortCSV CSV records format
+	SortCSV = "csv"
+	//SortJSON newline delimited JSON records format
+	SortJSON = "json"
+
+	defaultSortMaxMemoryMB = 64
+)
+
+//Sort represents sort-on-mirror transformer, records are sorted by key columns with temp file spill and external merge
+type Sort struct {
+	//Keys sort key columns: JSON field names, CSV header names (with Header) or 0 based column indexes
+	Keys []string
+	//Descending reverses sort order
+	Descending bool `json:",omitempty"`
+	//Numeric compares key values as numbers when both values are numeric
+	Numeric bool `json:",omitempty"`
+	//Format csv or json, rule schema format or csv by default
+	Format string `json:",omitempty"`
+	//Delimiter CSV delimiter, rule schema delimiter or comma by default
+	Delimiter string `json:",omitempty"`
+	//Header first CSV record is a header, it is kept as first record
+	Header bool `json:",omitempty"`
+	//MaxMemoryMB in-memory records budget before spilling sorted run to temp file, default 64
+	MaxMemoryMB int `json:",omitempty"`
+	//TempDir spill location, os temp dir by default
+	TempDir string `json:",omitempty"`
+}
+
+//Init initialises sort with rule schema settings
+func (s *Sort) Init(schema *Schema) {
+	if schema != nil {
+		if s.Format == "" {
+			s.Format = strings.ToLower(schema.Format)
+		}
+		if s.Delimiter == "" {
+			s.Delimiter = schema.Delimiter
+		}
+	}
+	if s.Format == "" {
+		s.Format = SortCSV
+	}
+	s.Format = strings.ToLower(s.Format)
+	if s.MaxMemoryMB == 0 {
+		s.MaxMemoryMB = defaultSortMaxMemoryMB
+	}
+	if s.TempDir == "" {
+		s.TempDir = os.TempDir()
+	}
+}
+
+//Validate checks if sort is valid
+func (s *Sort) Validate() error {
+	if len(s.Keys) == 0 {
+		return errors.New("sort.Keys were empty")
+	}
+	if s.Format != SortCSV && s.Format != SortJSON {
+		return errors.Errorf("unsupported sort.Format: %v", s.Format)
+	}
+	return nil
+}
+
+//MaxMemory returns in-memory records budget in bytes
+func (s *Sort) MaxMemory() int {
+	return s.MaxMemoryMB * 1024 * 1024
+}
+
+//IsJSON returns true for JSON records
+func (s *Sort) IsJSON() bool {
+	return s.Format == SortJSON
+}
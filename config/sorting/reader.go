@@ -0,0 +1,369 @@
+package sorting
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/csv"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const maxLineSize = 64 * 1024 * 1024
+
+var lineBreak = []byte{'\n'}
+
+//record represents sortable record
+type record struct {
+	Keys []string
+	Data []byte
+}
+
+type sorter struct {
+	sort      *config.Sort
+	response  *contract.Response
+	maxMemory int
+	header    []byte
+	indexes   []int
+	runs      []string
+	records   []*record
+	size      int
+}
+
+func (s *sorter) less(a, b *record) bool {
+	for i := range a.Keys {
+		cmp := s.compare(a.Keys[i], b.Keys[i])
+		if cmp == 0 {
+			continue
+		}
+		if s.sort.Descending {
+			return cmp > 0
+		}
+		return cmp < 0
+	}
+	return false
+}
+
+func (s *sorter) compare(a, b string) int {
+	if s.sort.Numeric {
+		x, errX := strconv.ParseFloat(a, 64)
+		y, errY := strconv.ParseFloat(b, 64)
+		if errX == nil && errY == nil {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+//add appends record, sorted records are spilled to temp file once memory budget is exceeded
+func (s *sorter) add(aRecord *record) error {
+	s.records = append(s.records, aRecord)
+	s.size += len(aRecord.Data)
+	for _, key := range aRecord.Keys {
+		s.size += len(key)
+	}
+	if s.size < s.maxMemory {
+		return nil
+	}
+	return s.spill()
+}
+
+func (s *sorter) sortRecords() {
+	sort.SliceStable(s.records, func(i, j int) bool { return s.less(s.records[i], s.records[j]) })
+}
+
+func (s *sorter) spill() error {
+	s.sortRecords()
+	run, err := ioutil.TempFile(s.sort.TempDir, "smirror-sort-*.run")
+	if err != nil {
+		return errors.Wrap(err, "failed to create sort spill file")
+	}
+	s.runs = append(s.runs, run.Name())
+	writer := bufio.NewWriter(run)
+	encoder := gob.NewEncoder(writer)
+	for _, aRecord := range s.records {
+		if err = encoder.Encode(aRecord); err != nil {
+			_ = run.Close()
+			return errors.Wrapf(err, "failed to spill record to %v", run.Name())
+		}
+	}
+	if err = writer.Flush(); err == nil {
+		err = run.Close()
+	}
+	s.records = nil
+	s.size = 0
+	return err
+}
+
+func (s *sorter) cleanup() {
+	for _, run := range s.runs {
+		_ = os.Remove(run)
+	}
+}
+
+//keyIndexes resolves CSV key columns to indexes
+func (s *sorter) keyIndexes(header []string) error {
+	for _, key := range s.sort.Keys {
+		index := -1
+		for i, name := range header {
+			if strings.TrimSpace(name) == key {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			var err error
+			if index, err = strconv.Atoi(key); err != nil {
+				return errors.Errorf("unknown sort key column: %v", key)
+			}
+		}
+		s.indexes = append(s.indexes, index)
+	}
+	return nil
+}
+
+func (s *sorter) readCSV(reader io.Reader) error {
+	csvReader := csv.NewReader(reader)
+	if s.sort.Delimiter != "" {
+		csvReader.Comma = rune(s.sort.Delimiter[0])
+	}
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+	buffer := new(bytes.Buffer)
+	csvWriter := csv.NewWriter(buffer)
+	csvWriter.Comma = csvReader.Comma
+	encode := func(fields []string) ([]byte, error) {
+		buffer.Reset()
+		if err := csvWriter.Write(fields); err != nil {
+			return nil, err
+		}
+		csvWriter.Flush()
+		return append([]byte{}, bytes.TrimRight(buffer.Bytes(), "\r\n")...), csvWriter.Error()
+	}
+	for i := 0; ; i++ {
+		fields, err := csvReader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to read CSV record")
+		}
+		if i == 0 {
+			var header []string
+			if s.sort.Header {
+				header = fields
+				if s.header, err = encode(fields); err != nil {
+					return err
+				}
+			}
+			if err = s.keyIndexes(header); err != nil {
+				return err
+			}
+			if s.sort.Header {
+				continue
+			}
+		}
+		aRecord := &record{Keys: make([]string, len(s.indexes))}
+		for j, index := range s.indexes {
+			if index < len(fields) {
+				aRecord.Keys[j] = fields[index]
+			}
+		}
+		data, err := encode(fields)
+		if err != nil {
+			return err
+		}
+		aRecord.Data = data
+		if err = s.add(aRecord); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *sorter) readJSON(reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 1024*1024), maxLineSize)
+	for scanner.Scan() {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		values := map[string]interface{}{}
+		if err := json.Unmarshal(data, &values); err != nil {
+			return errors.Wrapf(err, "failed to decode JSON record: %s", data)
+		}
+		aRecord := &record{Keys: make([]string, len(s.sort.Keys)), Data: append([]byte{}, data...)}
+		for i, key := range s.sort.Keys {
+			if value, ok := values[key]; ok && value != nil {
+				aRecord.Keys[i] = fmt.Sprintf("%v", value)
+			}
+		}
+		if err := s.add(aRecord); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+//write writes header and sorted records merging spilled runs with in-memory records
+func (s *sorter) write(writer io.Writer) error {
+	s.sortRecords()
+	bufWriter := bufio.NewWriter(writer)
+	count := 0
+	emit := func(data []byte) error {
+		if count > 0 {
+			if _, err := bufWriter.Write(lineBreak); err != nil {
+				return err
+			}
+		}
+		count++
+		_, err := bufWriter.Write(data)
+		return err
+	}
+	if s.header != nil {
+		if err := emit(s.header); err != nil {
+			return err
+		}
+	}
+	queue := &mergeQueue{sorter: s}
+	for i, run := range s.runs {
+		file, err := os.Open(run)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open sort spill file: %v", run)
+		}
+		defer file.Close()
+		source := &runSource{index: i, decoder: gob.NewDecoder(bufio.NewReader(file))}
+		if err = queue.push(source); err != nil {
+			return err
+		}
+	}
+	if err := queue.push(&runSource{index: len(s.runs), records: s.records}); err != nil {
+		return err
+	}
+	for queue.Len() > 0 {
+		source := queue.sources[0]
+		if err := emit(source.current.Data); err != nil {
+			return err
+		}
+		if err := source.next(); err != nil {
+			return err
+		}
+		if source.current == nil {
+			heap.Pop(queue)
+		} else {
+			heap.Fix(queue, 0)
+		}
+	}
+	return bufWriter.Flush()
+}
+
+//runSource represents sorted run, spilled with decoder or in-memory records
+type runSource struct {
+	index   int
+	decoder *gob.Decoder
+	records []*record
+	current *record
+}
+
+func (r *runSource) next() error {
+	r.current = nil
+	if r.decoder == nil {
+		if len(r.records) > 0 {
+			r.current = r.records[0]
+			r.records = r.records[1:]
+		}
+		return nil
+	}
+	aRecord := &record{}
+	if err := r.decoder.Decode(aRecord); err != nil {
+		if err == io.EOF {
+			return nil
+		}
+		return errors.Wrap(err, "failed to read sort spill file")
+	}
+	r.current = aRecord
+	return nil
+}
+
+type mergeQueue struct {
+	sorter  *sorter
+	sources []*runSource
+}
+
+func (q *mergeQueue) push(source *runSource) error {
+	if err := source.next(); err != nil || source.current == nil {
+		return err
+	}
+	heap.Push(q, source)
+	return nil
+}
+
+func (q *mergeQueue) Len() int { return len(q.sources) }
+
+func (q *mergeQueue) Less(i, j int) bool {
+	a, b := q.sources[i], q.sources[j]
+	if q.sorter.less(a.current, b.current) {
+		return true
+	}
+	if q.sorter.less(b.current, a.current) {
+		return false
+	}
+	//earlier runs first to keep sort stable
+	return a.index < b.index
+}
+
+func (q *mergeQueue) Swap(i, j int) { q.sources[i], q.sources[j] = q.sources[j], q.sources[i] }
+
+func (q *mergeQueue) Push(x interface{}) { q.sources = append(q.sources, x.(*runSource)) }
+
+func (q *mergeQueue) Pop() interface{} {
+	last := q.sources[len(q.sources)-1]
+	q.sources = q.sources[:len(q.sources)-1]
+	return last
+}
+
+//NewReader returns reader with records sorted by sort keys
+func NewReader(reader io.Reader, sortRule *config.Sort, response *contract.Response) (io.Reader, error) {
+	return newReader(reader, sortRule, response, sortRule.MaxMemory())
+}
+
+func newReader(reader io.Reader, sortRule *config.Sort, response *contract.Response, maxMemory int) (io.Reader, error) {
+	if maxMemory == 0 {
+		maxMemory = sortRule.MaxMemory()
+	}
+	pipeReader, pipeWriter := io.Pipe()
+	s := &sorter{sort: sortRule, response: response, maxMemory: maxMemory}
+	go func() {
+		defer s.cleanup()
+		var err error
+		if sortRule.IsJSON() {
+			err = s.readJSON(reader)
+		} else {
+			err = s.readCSV(reader)
+		}
+		if err == nil {
+			if len(s.runs) > 0 {
+				response.SetValue(contract.ValueSortSpillCount, len(s.runs))
+			}
+			err = s.write(pipeWriter)
+		}
+		_ = pipeWriter.CloseWithError(err)
+	}()
+	return pipeReader, nil
+}
@@ -0,0 +1,63 @@
+package sorting
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestNewReader(t *testing.T) {
+	var useCases = []struct {
+		description string
+		sort        *config.Sort
+		input       string
+		maxMemory   int
+		expect      string
+		expectSpill bool
+	}{
+		{
+			description: "csv header with numeric key",
+			sort:        &config.Sort{Keys: []string{"id"}, Header: true, Numeric: true},
+			input:       "name,id\nc,10\na,2\nb,1",
+			expect:      "name,id\nb,1\na,2\nc,10",
+		},
+		{
+			description: "csv column index descending",
+			sort:        &config.Sort{Keys: []string{"0"}, Descending: true},
+			input:       "a,1\nc,2\nb,3\n",
+			expect:      "c,2\nb,3\na,1",
+		},
+		{
+			description: "json multi key stable",
+			sort:        &config.Sort{Keys: []string{"k1", "k2"}, Format: "json"},
+			input:       "{\"k1\":\"b\",\"k2\":1,\"v\":1}\n{\"k1\":\"a\",\"k2\":2,\"v\":2}\n{\"k1\":\"a\",\"k2\":1,\"v\":3}\n{\"k1\":\"a\",\"k2\":1,\"v\":4}",
+			expect:      "{\"k1\":\"a\",\"k2\":1,\"v\":3}\n{\"k1\":\"a\",\"k2\":1,\"v\":4}\n{\"k1\":\"a\",\"k2\":2,\"v\":2}\n{\"k1\":\"b\",\"k2\":1,\"v\":1}",
+		},
+		{
+			description: "spill with external merge",
+			sort:        &config.Sort{Keys: []string{"0"}, Numeric: true},
+			input:       "5,e\n3,c\n1,a\n4,d\n2,b\n1,z",
+			maxMemory:   1,
+			expect:      "1,a\n1,z\n2,b\n3,c\n4,d\n5,e",
+			expectSpill: true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		useCase.sort.Init(nil)
+		response := contract.NewResponse("")
+		reader, err := newReader(strings.NewReader(useCase.input), useCase.sort, response, useCase.maxMemory)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		actual, err := ioutil.ReadAll(reader)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.expect, string(actual), useCase.description)
+		assert.Equal(t, useCase.expectSpill, response.Values[contract.ValueSortSpillCount] != nil, useCase.description)
+	}
+}
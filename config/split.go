@@ -1,6 +1,7 @@
 package config
 
 import (
+	"github.com/viant/smirror/base"
 	"crypto/md5"
 	"encoding/json"
 	"fmt"
@@ -30,7 +31,7 @@ type Split struct {
 	SchemaURL string
 
 	//MaxSize max size, if file larger then splits
-	MaxSize int
+	MaxSize base.Bytes
 }
 
 //Partition represent partition split
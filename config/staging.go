@@ -0,0 +1,163 @@
+package config
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/viant/afs"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultActivationMarker = "_activate.json"
+	defaultReportName       = "_report.json"
+)
+
+//Staging represents staged rules activation settings
+type Staging struct {
+	//PendingURL pending rules location, new or changed rules land here before activation
+	PendingURL string
+	//ActivationMarker marker object name in PendingURL with signed activation request
+	ActivationMarker string
+	//SigningKey KMS encrypted HMAC key used to verify activation request signature
+	SigningKey *auth.Secret
+	//decrypt decrypts SOPS or KMS encrypted pending rule file, pending data is promoted encrypted
+	decrypt func(ctx context.Context, data []byte, ext string) ([]byte, error)
+}
+
+//PendingRule represents pending rule file validation result
+type PendingRule struct {
+	URL   string
+	Rules int
+	Error string `json:",omitempty"`
+	//Warnings rule layout deprecation warnings
+	Warnings []string `json:",omitempty"`
+	data     []byte
+}
+
+//Data returns pending rule file content
+func (r *PendingRule) Data() []byte {
+	return r.data
+}
+
+//Init initialises staging
+func (s *Staging) Init() {
+	if s.ActivationMarker == "" {
+		s.ActivationMarker = defaultActivationMarker
+	}
+}
+
+//Enabled returns true if staging is enabled
+func (s *Staging) Enabled() bool {
+	return s.PendingURL != ""
+}
+
+//MarkerURL returns activation marker URL
+func (s *Staging) MarkerURL() string {
+	return url.Join(s.PendingURL, s.ActivationMarker)
+}
+
+//ReportURL returns pending rules validation report URL
+func (s *Staging) ReportURL() string {
+	return url.Join(s.PendingURL, defaultReportName)
+}
+
+//Validate checks if staging is valid
+func (s *Staging) Validate(baseURL string) error {
+	if !s.Enabled() {
+		return nil
+	}
+	if baseURL == "" {
+		return fmt.Errorf("mirrors baseURL was empty, required by staging")
+	}
+	pendingURL := strings.TrimRight(url.Normalize(s.PendingURL, ""), "/") + "/"
+	if strings.HasPrefix(pendingURL, strings.TrimRight(url.Normalize(baseURL, ""), "/")+"/") {
+		return fmt.Errorf("pending URL %v can not be within active rules %v", s.PendingURL, baseURL)
+	}
+	return nil
+}
+
+//Pending loads and validates pending rules, it returns validation results with pending rules hash
+func (s *Staging) Pending(ctx context.Context, fs afs.Service) ([]*PendingRule, string, error) {
+	var result = make([]*PendingRule, 0)
+	exists, err := fs.Exists(ctx, s.PendingURL)
+	if err != nil || !exists {
+		return result, "", err
+	}
+	objects, err := fs.List(ctx, s.PendingURL, option.NewRecursive(true))
+	if err != nil {
+		return nil, "", err
+	}
+	for _, object := range objects {
+		ext := path.Ext(object.Name())
+		if object.IsDir() || strings.HasPrefix(object.Name(), "_") || !(ext == base.JSONExt || ext == base.YAMLExt) {
+			continue
+		}
+		pending := &PendingRule{URL: object.URL()}
+		result = append(result, pending)
+		if pending.data, err = fs.Download(ctx, object); err != nil {
+			pending.Error = err.Error()
+			continue
+		}
+		data := pending.data
+		if s.decrypt != nil {
+			data, err = s.decrypt(ctx, data, ext)
+		}
+		var rules []*Rule
+		var warnings []string
+		if err == nil {
+			rules, warnings, err = loadRules(data, ext)
+		}
+		if err == nil {
+			pending.Rules = len(rules)
+			pending.Warnings = warnings
+			transient := Ruleset{Rules: rules}
+			if err = transient.Init(ctx, fs); err == nil {
+				err = transient.Validate()
+			}
+		}
+		if err != nil {
+			pending.Error = err.Error()
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].URL < result[j].URL
+	})
+	return result, s.hash(result), nil
+}
+
+//RelativePath returns pending rule path relative to pending URL
+func (s *Staging) RelativePath(URL string) string {
+	pendingPath := strings.Trim(url.Path(s.PendingURL), "/")
+	rulePath := strings.Trim(url.Path(URL), "/")
+	return strings.Trim(strings.Replace(rulePath, pendingPath, "", 1), "/")
+}
+
+func (s *Staging) hash(rules []*PendingRule) string {
+	hash := sha256.New()
+	for _, rule := range rules {
+		hash.Write([]byte(s.RelativePath(rule.URL)))
+		hash.Write(rule.data)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+//Sign returns pending rules hash signature
+func Sign(hash string, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(hash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+//VerifySignature returns true if signature matches pending rules hash
+func VerifySignature(hash, signature string, key []byte) bool {
+	return hmac.Equal([]byte(Sign(hash, key)), []byte(strings.ToLower(signature)))
+}
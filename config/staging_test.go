@@ -0,0 +1,46 @@
+package config
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"strings"
+	"testing"
+)
+
+func TestStaging_Pending(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	staging := &Staging{PendingURL: "mem://localhost/staging/pending"}
+	staging.Init()
+	_ = fs.Upload(ctx, "mem://localhost/staging/pending/team/rule.json", 0644, strings.NewReader(`[{"Source":{"Prefix":"/data/"},"Dest":{"URL":"mem://localhost/dest"}}]`))
+	_ = fs.Upload(ctx, "mem://localhost/staging/pending/_activate.json", 0644, strings.NewReader(`{}`))
+
+	rules, hash, err := staging.Pending(ctx, fs)
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, 1, len(rules))
+	assert.Equal(t, "", rules[0].Error)
+	assert.Equal(t, "team/rule.json", staging.RelativePath(rules[0].URL))
+	assert.NotEqual(t, "", hash)
+
+	signature := Sign(hash, []byte("secret"))
+	assert.True(t, VerifySignature(hash, signature, []byte("secret")))
+	assert.False(t, VerifySignature(hash, signature, []byte("other")))
+
+	_ = fs.Upload(ctx, "mem://localhost/staging/pending/team/bad.yaml", 0644, strings.NewReader("Source:\n  Filter: '['\n"))
+	rules, changedHash, err := staging.Pending(ctx, fs)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(rules))
+	assert.NotEqual(t, hash, changedHash)
+	assert.NotEqual(t, "", rules[0].Error)
+}
+
+func TestStaging_Validate(t *testing.T) {
+	staging := &Staging{PendingURL: "gs://bucket/rules/pending"}
+	assert.NotNil(t, staging.Validate(""))
+	assert.NotNil(t, staging.Validate("gs://bucket/rules"))
+	assert.Nil(t, staging.Validate("gs://bucket/rule"))
+	assert.Nil(t, staging.Validate("gs://bucket/active"))
+}
@@ -1,5 +1,7 @@
 package config
 
+import "github.com/viant/smirror/base"
+
 const (
 	megaBytes              = 1024 * 1024
 	defaultStreamThreshold = 512
@@ -8,12 +10,14 @@ const (
 
 //Streaming represents streaming option
 type Streaming struct {
-	ThresholdMb             int
+	ThresholdMb             base.Megabytes
 	threshold               int
 	partSize                int
-	PartSizeMb              int
-	ChecksumSkipThresholdMb int
+	PartSizeMb              base.Megabytes
+	ChecksumSkipThresholdMb base.Megabytes
 	checksumSkipThreshold   int
+	//Adaptive multipart upload part size tuning, PartSizeMb is used until dest throughput is observed
+	Adaptive *AdaptivePartSize `json:",omitempty"`
 }
 
 //Threshold returns download/upload streaming
@@ -37,7 +41,7 @@ func (c *Streaming) Init() {
 		c.ThresholdMb = defaultStreamThreshold
 	}
 	if c.threshold == 0 {
-		c.threshold = c.ThresholdMb * megaBytes
+		c.threshold = int(c.ThresholdMb) * megaBytes
 	}
 
 	if c.PartSizeMb == 0 {
@@ -45,13 +49,16 @@ func (c *Streaming) Init() {
 	}
 
 	if c.partSize == 0 {
-		c.partSize = c.PartSizeMb * megaBytes
+		c.partSize = int(c.PartSizeMb) * megaBytes
 	}
 
 	if c.ChecksumSkipThresholdMb == 0 {
 		c.ChecksumSkipThresholdMb = defaultStreamThreshold
 	}
 	if c.checksumSkipThreshold == 0 {
-		c.checksumSkipThreshold = c.ChecksumSkipThresholdMb * megaBytes
+		c.checksumSkipThreshold = int(c.ChecksumSkipThresholdMb) * megaBytes
+	}
+	if c.Adaptive != nil {
+		c.Adaptive.Init()
 	}
 }
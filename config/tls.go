@@ -0,0 +1,47 @@
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"github.com/viant/smirror/auth"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+//TLS represents http(s) resource transport TLS settings: private CA bundle, mTLS client certificate and min version
+type TLS struct {
+	//CAURL PEM CA bundle URL, CA certificates are trusted in addition to system roots
+	CAURL string `json:",omitempty"`
+	//Certificate encrypted PEM with client certificate and private key used for mTLS
+	Certificate *auth.Secret `json:",omitempty"`
+	//MinVersion min TLS version: 1.0, 1.1, 1.2 (default) or 1.3
+	MinVersion string `json:",omitempty"`
+	//InsecureSkipVerify skips server certificate verification, for development only
+	InsecureSkipVerify bool `json:",omitempty"`
+	//ClientCertificate decrypted client certificate PEM
+	ClientCertificate []byte `json:"-"`
+}
+
+//Version returns min TLS version
+func (t *TLS) Version() uint16 {
+	if version, ok := tlsVersions[t.MinVersion]; ok {
+		return version
+	}
+	return tls.VersionTLS12
+}
+
+//Validate checks if TLS settings are valid for supplied resource
+func (t *TLS) Validate(resource *Resource) error {
+	if resource.URL != "" && !isHTTPScheme(resource.URL) {
+		return fmt.Errorf("tls expected http(s) resource, but had: %v", resource.URL)
+	}
+	if _, ok := tlsVersions[t.MinVersion]; t.MinVersion != "" && !ok {
+		return fmt.Errorf("unsupported tls.MinVersion: %v, supported: 1.0, 1.1, 1.2, 1.3", t.MinVersion)
+	}
+	return nil
+}
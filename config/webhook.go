@@ -0,0 +1,145 @@
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"hash"
+	"strings"
+	"time"
+)
+
+const (
+	//WebhookSHA256 HMAC SHA-256 webhook signature
+	WebhookSHA256 = "sha256"
+	//WebhookSHA1 HMAC SHA-1 webhook signature
+	WebhookSHA1 = "sha1"
+
+	defaultWebhookSignatureHeader = "X-Signature"
+	defaultWebhookPathField       = "path"
+	defaultWebhookToleranceSec    = 300
+	defaultWebhookMaxFiles        = 1000
+)
+
+//Webhook represents partner push notification trigger, payload is verified with HMAC signature and mapped to source URL or prefix
+type Webhook struct {
+	//Partner partner name, supplied with partner query parameter or X-Partner header
+	Partner string
+	//Secret encrypted HMAC shared secret, decrypted with secret service
+	Secret *auth.Secret
+	//Algorithm HMAC algorithm: sha256 (default) or sha1
+	Algorithm string `json:",omitempty"`
+	//SignatureHeader header with hex HMAC signature, optionally prefixed with algorithm (i.e. sha256=...), default X-Signature
+	SignatureHeader string `json:",omitempty"`
+	//TimestampHeader optional header with unix timestamp, with timestamp signed content is timestamp.payload
+	TimestampHeader string `json:",omitempty"`
+	//ToleranceSec max timestamp age, it protects against replayed notifications, default 300
+	ToleranceSec base.Seconds `json:",omitempty"`
+	//Source partner source base URL and credentials used to list notified prefix
+	Source Resource
+	//PathField payload JSON field (dot separated for nested field) with uploaded object path or prefix (ending with /), default path
+	PathField string `json:",omitempty"`
+	//MaxFiles max files triggered for notified prefix, default 1000
+	MaxFiles int `json:",omitempty"`
+}
+
+//Init initialises webhook defaults
+func (w *Webhook) Init() {
+	if w.Algorithm == "" {
+		w.Algorithm = WebhookSHA256
+	}
+	if w.SignatureHeader == "" {
+		w.SignatureHeader = defaultWebhookSignatureHeader
+	}
+	if w.ToleranceSec == 0 {
+		w.ToleranceSec = defaultWebhookToleranceSec
+	}
+	if w.PathField == "" {
+		w.PathField = defaultWebhookPathField
+	}
+	if w.MaxFiles == 0 {
+		w.MaxFiles = defaultWebhookMaxFiles
+	}
+}
+
+//Validate checks if webhook is valid
+func (w *Webhook) Validate() error {
+	if w.Partner == "" {
+		return fmt.Errorf("webhook.Partner was empty")
+	}
+	if w.Secret == nil {
+		return fmt.Errorf("webhook %v Secret was empty", w.Partner)
+	}
+	if w.Source.URL == "" {
+		return fmt.Errorf("webhook %v Source.URL was empty", w.Partner)
+	}
+	if w.hash() == nil {
+		return fmt.Errorf("unsupported webhook %v algorithm: %v, supported: %v, %v", w.Partner, w.Algorithm, WebhookSHA256, WebhookSHA1)
+	}
+	return nil
+}
+
+func (w *Webhook) hash() func() hash.Hash {
+	switch w.Algorithm {
+	case WebhookSHA256, "":
+		return sha256.New
+	case WebhookSHA1:
+		return sha1.New
+	}
+	return nil
+}
+
+//Sign returns hex HMAC signature of payload, with timestamp signed content is timestamp.payload
+func (w *Webhook) Sign(secret []byte, timestamp string, payload []byte) string {
+	mac := hmac.New(w.hash(), secret)
+	if timestamp != "" {
+		mac.Write([]byte(timestamp + "."))
+	}
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+//Verify checks payload signature and timestamp tolerance
+func (w *Webhook) Verify(secret []byte, signature, timestamp string, payload []byte, now time.Time) error {
+	if w.TimestampHeader != "" {
+		var unix int64
+		if _, err := fmt.Sscanf(timestamp, "%d", &unix); err != nil {
+			return fmt.Errorf("invalid webhook timestamp: '%v'", timestamp)
+		}
+		if age := now.Sub(time.Unix(unix, 0)); age > w.ToleranceSec.Duration() || -age > w.ToleranceSec.Duration() {
+			return fmt.Errorf("webhook timestamp %v is outside of %v tolerance", timestamp, w.ToleranceSec.Duration())
+		}
+	} else {
+		timestamp = ""
+	}
+	signature = strings.TrimPrefix(signature, w.Algorithm+"=")
+	expected := w.Sign(secret, timestamp, payload)
+	if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected)) {
+		return fmt.Errorf("invalid webhook %v signature", w.Partner)
+	}
+	return nil
+}
+
+//Path returns notified path from decoded payload
+func (w *Webhook) Path(payload map[string]interface{}) (string, error) {
+	var value interface{} = payload
+	for _, field := range strings.Split(w.PathField, ".") {
+		aMap, ok := value.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("webhook payload field %v was missing", w.PathField)
+		}
+		value = aMap[field]
+	}
+	result, ok := value.(string)
+	if !ok || result == "" {
+		return "", fmt.Errorf("webhook payload field %v was empty", w.PathField)
+	}
+	if strings.Contains(result, "..") {
+		return "", fmt.Errorf("invalid webhook path: %v", result)
+	}
+	return result, nil
+}
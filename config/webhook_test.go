@@ -0,0 +1,123 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWebhook_Verify(t *testing.T) {
+	secret := []byte("shared secret")
+	payload := []byte(`{"path":"data/asset.csv"}`)
+	now := time.Unix(1700000000, 0)
+	var useCases = []struct {
+		description string
+		Webhook
+		signature   func(webhook *Webhook) string
+		timestamp   string
+		expectError bool
+	}{
+		{
+			description: "sha256 signature",
+			Webhook:     Webhook{Partner: "p1"},
+			signature: func(webhook *Webhook) string {
+				return webhook.Sign(secret, "", payload)
+			},
+		},
+		{
+			description: "prefixed sha1 signature",
+			Webhook:     Webhook{Partner: "p1", Algorithm: WebhookSHA1},
+			signature: func(webhook *Webhook) string {
+				return "sha1=" + webhook.Sign(secret, "", payload)
+			},
+		},
+		{
+			description: "invalid signature",
+			Webhook:     Webhook{Partner: "p1"},
+			signature: func(webhook *Webhook) string {
+				return webhook.Sign([]byte("other"), "", payload)
+			},
+			expectError: true,
+		},
+		{
+			description: "signed timestamp",
+			Webhook:     Webhook{Partner: "p1", TimestampHeader: "X-Timestamp"},
+			timestamp:   "1699999900",
+			signature: func(webhook *Webhook) string {
+				return webhook.Sign(secret, "1699999900", payload)
+			},
+		},
+		{
+			description: "replayed timestamp",
+			Webhook:     Webhook{Partner: "p1", TimestampHeader: "X-Timestamp"},
+			timestamp:   "1699999000",
+			signature: func(webhook *Webhook) string {
+				return webhook.Sign(secret, "1699999000", payload)
+			},
+			expectError: true,
+		},
+		{
+			description: "unsigned timestamp",
+			Webhook:     Webhook{Partner: "p1", TimestampHeader: "X-Timestamp"},
+			timestamp:   "1699999900",
+			signature: func(webhook *Webhook) string {
+				return webhook.Sign(secret, "", payload)
+			},
+			expectError: true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		useCase.Init()
+		err := useCase.Verify(secret, useCase.signature(&useCase.Webhook), useCase.timestamp, payload, now)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+	}
+}
+
+func TestWebhook_Path(t *testing.T) {
+	var useCases = []struct {
+		description string
+		pathField   string
+		payload     map[string]interface{}
+		expect      string
+		expectError bool
+	}{
+		{
+			description: "default field",
+			payload:     map[string]interface{}{"path": "data/asset.csv"},
+			expect:      "data/asset.csv",
+		},
+		{
+			description: "nested field",
+			pathField:   "object.name",
+			payload:     map[string]interface{}{"object": map[string]interface{}{"name": "data/"}},
+			expect:      "data/",
+		},
+		{
+			description: "missing field",
+			payload:     map[string]interface{}{"name": "data/asset.csv"},
+			expectError: true,
+		},
+		{
+			description: "parent path",
+			payload:     map[string]interface{}{"path": "../secret/asset.csv"},
+			expectError: true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		webhook := &Webhook{PathField: useCase.pathField}
+		webhook.Init()
+		actual, err := webhook.Path(useCase.payload)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+}
@@ -0,0 +1,76 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"hash"
+	"io"
+	"time"
+)
+
+//digestWriter computes digest and size of written content
+type digestWriter struct {
+	io.WriteCloser
+	hash hash.Hash
+	size int64
+}
+
+func (w *digestWriter) Write(p []byte) (int, error) {
+	n, err := w.WriteCloser.Write(p)
+	w.hash.Write(p[:n])
+	w.size += int64(n)
+	return n, err
+}
+
+//storeContent moves uploaded temp object to its content-addressed location, unless the content is already stored,
+//and writes manifest pointing to the content under the original dest name, dest storage options are used for all calls
+func (s *service) storeContent(ctx context.Context, transfer *Transfer, tempURL string, writer *digestWriter, options []storage.Option, response *contract.Response) error {
+	layout := transfer.Resource.ContentAddressed
+	digest := hex.EncodeToString(writer.hash.Sum(nil))
+	contentURL := layout.ContentURL(transfer.Resource, digest)
+	if exists, _ := s.fs.Exists(ctx, contentURL, options...); exists {
+		if err := s.fs.Delete(ctx, tempURL, options...); err != nil {
+			return errors.Wrapf(err, "failed to delete %v", tempURL)
+		}
+	} else if err := s.fs.Move(ctx, tempURL, contentURL, options...); err != nil {
+		return errors.Wrapf(err, "failed to move %v to %v", tempURL, contentURL)
+	}
+	_, name := url.Split(transfer.Dest.URL, file.Scheme)
+	manifest := &config.ContentManifest{
+		Name:       name,
+		SourceURL:  response.TriggeredBy,
+		URL:        contentURL,
+		Algorithm:  layout.DigestAlgorithm(),
+		Digest:     digest,
+		Size:       writer.size,
+		TransferID: response.TransferID,
+		Created:    time.Now(),
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	manifestURL := layout.ManifestURL(transfer.Dest.URL)
+	if usesNativeUpload(transfer.Resource) {
+		var upload nativeUploader
+		if upload, err = newNativeUploader(ctx, manifestURL, options); err == nil {
+			err = upload(ctx, bytes.NewReader(data))
+		}
+	} else {
+		err = s.fs.Upload(ctx, manifestURL, file.DefaultFileOsMode, bytes.NewReader(data), options...)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to upload manifest %v", manifestURL)
+	}
+	response.AddURL(contentURL)
+	response.AddURL(manifestURL)
+	return nil
+}
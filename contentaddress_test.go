@@ -0,0 +1,128 @@
+package smirror
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestService_ContentAddressed(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/cas")
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/cas/data"}},
+				Dest: &config.Resource{
+					URL:              "mem://localhost/cas/dest",
+					ContentAddressed: &config.ContentAddressed{BaseURL: "mem://localhost/cas/lake"},
+				},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sum := sha256.Sum256([]byte("1,2,3"))
+	digest := hex.EncodeToString(sum[:])
+	contentURL := "mem://localhost/cas/lake/sha256/" + digest[:2] + "/" + digest[2:4] + "/" + digest
+
+	for _, name := range []string{"a.csv", "b.csv"} {
+		sourceURL := "mem://localhost/cas/data/" + name
+		_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("1,2,3"))
+		response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+		if !assert.Equal(t, base.StatusOK, response.Status, response.Error) {
+			return
+		}
+		manifestURL := "mem://localhost/cas/dest/cas/data/" + name + ".ref.json"
+		assert.EqualValues(t, []string{contentURL, manifestURL}, response.DestURLs, name)
+		data, err := fs.DownloadWithURL(ctx, manifestURL)
+		if !assert.Nil(t, err, name) {
+			continue
+		}
+		manifest := &config.ContentManifest{}
+		assert.Nil(t, json.Unmarshal(data, manifest), name)
+		assert.Equal(t, name, manifest.Name)
+		assert.Equal(t, sourceURL, manifest.SourceURL)
+		assert.Equal(t, contentURL, manifest.URL)
+		assert.Equal(t, digest, manifest.Digest)
+		assert.EqualValues(t, 5, manifest.Size)
+	}
+	content, err := fs.DownloadWithURL(ctx, contentURL)
+	assert.Nil(t, err)
+	assert.Equal(t, "1,2,3", string(content))
+	exists, _ := fs.Exists(ctx, "mem://localhost/cas/dest/cas/data/a.csv")
+	assert.False(t, exists, "content is only stored under its digest")
+	temp, _ := fs.List(ctx, "mem://localhost/cas/lake/sha256/_tmp")
+	for _, object := range temp {
+		assert.True(t, object.IsDir(), "temp uploads are moved or removed: %v", object.URL())
+	}
+}
+
+//optionRecorder records storage options passed to storage calls
+type optionRecorder struct {
+	afs.Service
+	calls map[string][]storage.Option
+}
+
+func (r *optionRecorder) Exists(ctx context.Context, URL string, options ...storage.Option) (bool, error) {
+	r.calls["Exists"] = options
+	return r.Service.Exists(ctx, URL, options...)
+}
+
+func (r *optionRecorder) Move(ctx context.Context, sourceURL, destURL string, options ...storage.Option) error {
+	r.calls["Move"] = options
+	return r.Service.Move(ctx, sourceURL, destURL, options...)
+}
+
+func (r *optionRecorder) Delete(ctx context.Context, URL string, options ...storage.Option) error {
+	r.calls["Delete"] = options
+	return r.Service.Delete(ctx, URL, options...)
+}
+
+func (r *optionRecorder) Upload(ctx context.Context, URL string, mode os.FileMode, reader io.Reader, options ...storage.Option) error {
+	r.calls["Upload"] = options
+	return r.Service.Upload(ctx, URL, mode, reader, options...)
+}
+
+func TestService_StoreContent_Options(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/casopt")
+	recorder := &optionRecorder{Service: fs, calls: map[string][]storage.Option{}}
+	srv := &service{fs: recorder}
+	region := &option.Region{Name: "us-west-2"}
+	transfer := &Transfer{
+		Resource: &config.Resource{URL: "mem://localhost/casopt/dest", ContentAddressed: &config.ContentAddressed{BaseURL: "mem://localhost/casopt/lake"}},
+		Dest:     NewDatafile("mem://localhost/casopt/dest/a.csv", nil),
+	}
+	for _, expectCalls := range [][]string{{"Exists", "Move", "Upload"}, {"Exists", "Delete", "Upload"}} {
+		recorder.calls = map[string][]storage.Option{}
+		tempURL := "mem://localhost/casopt/lake/_tmp/a"
+		_ = fs.Upload(ctx, tempURL, 0644, strings.NewReader("1,2,3"))
+		writer := &digestWriter{hash: sha256.New(), size: 5}
+		writer.hash.Write([]byte("1,2,3"))
+		err := srv.storeContent(ctx, transfer, tempURL, writer, []storage.Option{region}, contract.NewResponse("mem://localhost/casopt/data/a.csv"))
+		if !assert.Nil(t, err) {
+			return
+		}
+		for _, call := range expectCalls {
+			assert.Contains(t, recorder.calls[call], region, call)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package contract
+
+import (
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+)
+
+//ActivationRequest represents pending rules activation request
+type ActivationRequest struct {
+	//Tenant optional tenant name for multi tenant config
+	Tenant string
+	//Hash pending rules hash as reported by validation
+	Hash string
+	//Signature HMAC-SHA256 hex signature of hash
+	Signature string
+	//DryRun only validates pending rules
+	DryRun bool
+}
+
+//ActivationResponse represents pending rules activation response
+type ActivationResponse struct {
+	Hash      string
+	Rules     []*config.PendingRule `json:",omitempty"`
+	Activated []string              `json:",omitempty"`
+	Status    string
+	Error     string `json:",omitempty"`
+}
+
+//NewActivationResponse creates activation response
+func NewActivationResponse() *ActivationResponse {
+	return &ActivationResponse{Status: base.StatusOK}
+}
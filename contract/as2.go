@@ -0,0 +1,40 @@
+package contract
+
+import (
+	"github.com/viant/smirror/base"
+	"io"
+	"net/http"
+)
+
+//AS2Request represents inbound AS2 message
+type AS2Request struct {
+	//Tenant optional tenant name for multi tenant config
+	Tenant string
+	Header http.Header `json:"-"`
+	//Body raw message body, it is read up to AS2 MaxPayloadMb
+	Body io.Reader `json:"-"`
+}
+
+//AS2Response represents AS2 receiver response
+type AS2Response struct {
+	Partner   string `json:",omitempty"`
+	MessageID string `json:",omitempty"`
+	//URL landed payload URL
+	URL    string `json:",omitempty"`
+	Signed bool   `json:",omitempty"`
+	//Disposition MDN disposition
+	Disposition string `json:",omitempty"`
+	//Mirror landed payload mirror response
+	Mirror *Response `json:",omitempty"`
+	Status string
+	Error  string `json:",omitempty"`
+	//MDNHeader synchronous MDN HTTP header
+	MDNHeader http.Header `json:"-"`
+	//MDN synchronous MDN body, empty if receipt was not requested
+	MDN []byte `json:"-"`
+}
+
+//NewAS2Response creates AS2 response
+func NewAS2Response() *AS2Response {
+	return &AS2Response{Status: base.StatusOK}
+}
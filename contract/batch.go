@@ -0,0 +1,54 @@
+package contract
+
+import (
+	"fmt"
+	"github.com/viant/smirror/base"
+)
+
+//BatchRequest represents multiple source objects mirror request, processed concurrently within one invocation
+type BatchRequest struct {
+	//URLs source object URLs
+	URLs []string
+	//Parallelism max concurrently mirrored objects, default 8
+	Parallelism int `json:",omitempty"`
+	//CorrelationID upstream correlation ID propagated to each mirror request
+	CorrelationID string `json:",omitempty"`
+	//SkipActions skips rule post actions
+	SkipActions bool `json:",omitempty"`
+}
+
+//BatchResponse represents combined batch mirror response
+type BatchResponse struct {
+	//Responses mirror responses in request URLs order
+	Responses   []*Response
+	Succeeded   int
+	Failed      int
+	TimeTakenMs int
+	Status      string
+	Error       string `json:",omitempty"`
+}
+
+//Init sets succeeded and failed counts, response status is error if any source object failed
+func (r *BatchResponse) Init() {
+	r.Succeeded, r.Failed = 0, 0
+	firstError := ""
+	for _, response := range r.Responses {
+		if response.Status == base.StatusError {
+			if r.Failed == 0 {
+				firstError = response.Error
+			}
+			r.Failed++
+			continue
+		}
+		r.Succeeded++
+	}
+	if r.Failed > 0 && r.Status == base.StatusOK {
+		r.Status = base.StatusError
+		r.Error = fmt.Sprintf("%v of %v source objects failed: %v", r.Failed, len(r.Responses), firstError)
+	}
+}
+
+//NewBatchResponse creates batch response
+func NewBatchResponse() *BatchResponse {
+	return &BatchResponse{Status: base.StatusOK, Responses: make([]*Response, 0)}
+}
@@ -0,0 +1,14 @@
+package contract
+
+//ClaimCheck represents message published instead of payload exceeding destination size limit,
+//the payload is stored in URL object
+type ClaimCheck struct {
+	SourceURL  string
+	TransferID string `json:",omitempty"`
+	//URL claim-check object URL
+	URL       string
+	Algorithm string
+	//Digest hex encoded payload digest
+	Digest string
+	Size   int
+}
@@ -0,0 +1,110 @@
+package contract
+
+import (
+	"github.com/viant/smirror/base"
+	"time"
+)
+
+const (
+	//MetricTransfers successful transfers count
+	MetricTransfers = "transfers"
+	//MetricBytes successfully transferred source bytes
+	MetricBytes = "bytes"
+	//MetricErrors failed transfers count
+	MetricErrors = "errors"
+	//MetricDuration transfer time taken in ms, emitted only by metrics emitter
+	MetricDuration = "duration"
+	//MetricThrottle time spent waiting for provider rate limit in ms, emitted only by metrics emitter
+	MetricThrottle = "throttle"
+
+	defaultDashboardRange    = 24 * time.Hour
+	defaultDashboardInterval = 5 * time.Minute
+	//maxDashboardRange max query range, older range start is moved forward
+	maxDashboardRange = 90 * 24 * time.Hour
+	//maxDashboardDataPoints max number of buckets per series, interval is widened to fit
+	maxDashboardDataPoints = 2000
+)
+
+//Metrics dashboard rule metrics, target is <rule>.<metric>
+var Metrics = []string{MetricTransfers, MetricBytes, MetricErrors}
+
+//DashboardRequest represents Grafana JSON datasource search or query request
+type DashboardRequest struct {
+	//Tenant optional tenant name for multi tenant config
+	Tenant string `json:"-"`
+	//Search returns available targets instead of series
+	Search bool `json:"-"`
+	//Target search term, targets containing it are returned
+	Target string `json:"target,omitempty"`
+	//Range query time range, default last 24 hours
+	Range DashboardRange `json:"range"`
+	//IntervalMs series bucket interval, default 5 minutes
+	IntervalMs int64 `json:"intervalMs,omitempty"`
+	//MaxDataPoints max number of buckets per series, interval is widened to fit, up to 2000
+	MaxDataPoints int `json:"maxDataPoints,omitempty"`
+	//Targets queried targets, all targets by default
+	Targets []*DashboardTarget `json:"targets,omitempty"`
+}
+
+//DashboardRange represents query time range
+type DashboardRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+//DashboardTarget represents query target
+type DashboardTarget struct {
+	Target string `json:"target"`
+	RefID  string `json:"refId,omitempty"`
+}
+
+//Window returns query range aligned to bucket interval, range and number of buckets are capped
+func (r *DashboardRequest) Window(now time.Time) (time.Time, time.Time, time.Duration) {
+	to := r.Range.To
+	if to.IsZero() {
+		to = now
+	}
+	from := r.Range.From
+	if from.IsZero() || !from.Before(to) {
+		from = to.Add(-defaultDashboardRange)
+	}
+	if from.Before(to.Add(-maxDashboardRange)) {
+		from = to.Add(-maxDashboardRange)
+	}
+	interval := time.Duration(r.IntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultDashboardInterval
+	}
+	maxDataPoints := r.MaxDataPoints
+	if maxDataPoints <= 0 || maxDataPoints > maxDashboardDataPoints {
+		maxDataPoints = maxDashboardDataPoints
+	}
+	if maxDataPoints < 2 {
+		maxDataPoints = 2
+	}
+	//one bucket is reserved for range start alignment
+	if minInterval := to.Sub(from) / time.Duration(maxDataPoints-1); interval < minInterval {
+		interval = minInterval.Truncate(time.Second) + time.Second
+	}
+	return from.Truncate(interval), to, interval
+}
+
+//Series represents Grafana time series, each data point is a [value, unix epoch ms] pair
+type Series struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+//DashboardResponse represents dashboard response
+type DashboardResponse struct {
+	//Targets available <rule>.<metric> targets, returned for search request
+	Targets []string  `json:",omitempty"`
+	Series  []*Series `json:",omitempty"`
+	Status  string
+	Error   string `json:",omitempty"`
+}
+
+//NewDashboardResponse creates dashboard response
+func NewDashboardResponse() *DashboardResponse {
+	return &DashboardResponse{Status: base.StatusOK}
+}
@@ -0,0 +1,30 @@
+package contract
+
+import (
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/eventlog"
+)
+
+//EventsRequest represents event log read request
+type EventsRequest struct {
+	//Tenant optional tenant name for multi tenant config
+	Tenant string
+	//Cursor cursor returned by previous read, empty cursor reads from the log beginning
+	Cursor string
+	//Limit max number of events, whole segments are returned, default 1000
+	Limit int
+}
+
+//EventsResponse represents event log read response
+type EventsResponse struct {
+	Events []*eventlog.Event
+	//Cursor position to continue reading from, it is unchanged if no new events were read
+	Cursor string
+	Status string
+	Error  string `json:",omitempty"`
+}
+
+//NewEventsResponse creates events response
+func NewEventsResponse() *EventsResponse {
+	return &EventsResponse{Status: base.StatusOK, Events: make([]*eventlog.Event, 0)}
+}
@@ -0,0 +1,22 @@
+package contract
+
+//MessageManifest represents manifest message coalescing split parts published to the same topic or queue within a transfer
+type MessageManifest struct {
+	SourceURL     string
+	TransferID    string `json:",omitempty"`
+	CorrelationID string `json:",omitempty"`
+	//Sequence manifest message sequence number within transfer and destination, starting from 1
+	Sequence int
+	Parts    []*ManifestPart
+}
+
+//ManifestPart represents split part
+type ManifestPart struct {
+	//Name split part dest name
+	Name string
+	//Split split part number
+	Split     int32
+	Partition string `json:",omitempty"`
+	//Data split part data, base64 encoded in JSON
+	Data []byte
+}
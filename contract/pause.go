@@ -0,0 +1,42 @@
+package contract
+
+import (
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+)
+
+const (
+	//PauseActionPause pauses all rules or a rule
+	PauseActionPause = "pause"
+	//PauseActionResume resumes all rules or a rule and restores deferred source objects
+	PauseActionResume = "resume"
+	//PauseActionCatchUp restores source objects deferred by all rules or a rule whose dest maintenance window is closed, it is run by scheduled tick
+	PauseActionCatchUp = "catch-up"
+)
+
+//PauseRequest represents ingestion pause request, empty action only returns active pause markers
+type PauseRequest struct {
+	//Tenant optional tenant name for multi tenant config
+	Tenant string
+	//Action pause, resume or empty
+	Action string
+	//Rule rule workflow name, empty applies to all rules
+	Rule string
+	//Reason pause reason, i.e. destination maintenance window
+	Reason string
+}
+
+//PauseResponse represents ingestion pause response
+type PauseResponse struct {
+	//Paused active pause markers
+	Paused []*config.PauseMarker `json:",omitempty"`
+	//Restored deferred source objects moved back to source location on resume or catch-up
+	Restored []string `json:",omitempty"`
+	Status   string
+	Error    string `json:",omitempty"`
+}
+
+//NewPauseResponse creates pause response
+func NewPauseResponse() *PauseResponse {
+	return &PauseResponse{Status: base.StatusOK}
+}
@@ -7,6 +7,16 @@ type Request struct {
 	URL       string
 	Attempt   int
 	Timestamp time.Time
+	//Requeue number of times event was requeued
+	Requeue int `json:",omitempty"`
+	//TransferID unique mirror transfer ID, preserved across retries
+	TransferID string `json:",omitempty"`
+	//CorrelationID upstream correlation ID supplied by trigger
+	CorrelationID string `json:",omitempty"`
+	//Generation GCS trigger event object generation, source reads are pinned to it
+	Generation int64 `json:",omitempty"`
+	//SkipActions skips rule post actions
+	SkipActions bool `json:",omitempty"`
 }
 
 //NewRequest create a request
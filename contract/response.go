@@ -4,18 +4,35 @@ import (
 	"github.com/viant/afs/option"
 	"github.com/viant/smirror/base"
 	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/eventlog"
+	"github.com/viant/smirror/shared"
 
 	"sync"
 	"time"
 )
 
+const (
+	//ValueSplitCount number of split parts value key
+	ValueSplitCount = "splitCount"
+	//ValueSortSpillCount number of sorted runs spilled to temp files value key
+	ValueSortSpillCount = "sortSpillCount"
+	//ValueLoadedRows number of rows loaded into database table value key
+	ValueLoadedRows = "loadedRows"
+)
+
 //Response represents a response
 type Response struct {
 	TriggeredBy   string
+	//TransferID unique mirror transfer ID
+	TransferID string `json:",omitempty"`
+	//CorrelationID upstream correlation ID from trigger or source object metadata
+	CorrelationID string `json:",omitempty"`
 	FileSize      int64 `json:",omitempty"`
 	LogError      string `json:",omitempty"`
 	DestURLs      []string `json:",omitempty"`
 	MessageIDs    []string `json:",omitempty"`
+	//ClaimCheckURLs claim-check objects storing offloaded message payloads
+	ClaimCheckURLs []string `json:",omitempty"`
 	TimeTakenMs   int
 	Rule          *config.Rule `json:",omitempty"`
 	RuleURL       string
@@ -24,11 +41,172 @@ type Response struct {
 	Error         string `json:",omitempty"`
 	SchemaError   string `json:",omitempty"`
 	NotFoundError string `json:",omitempty"`
+	//ErrorCode error taxonomy code: auth, notFound, schema, quota, transient, config or unknown
+	ErrorCode string `json:",omitempty"`
+	//ErrorClass retryable or terminal
+	ErrorClass string `json:",omitempty"`
+	//SourceCodec source compression detected with content magic bytes
+	SourceCodec string `json:",omitempty"`
+	//GzipPassthrough gzip source was copied as is to gzip dest, without decompression and recompression
+	GzipPassthrough bool `json:",omitempty"`
+	//DuplicateOf source URL of already mirrored object with the same content
+	DuplicateOf string `json:",omitempty"`
+	//OriginalTransferID transfer ID of already mirrored object with the same content
+	OriginalTransferID string `json:",omitempty"`
+	//QuarantineURL quarantined duplicate URL
+	QuarantineURL string `json:",omitempty"`
+	//DatabricksRunID job run triggered after Databricks delivery
+	DatabricksRunID int64 `json:",omitempty"`
+	//IndexedDocs number of documents indexed into Elasticsearch or OpenSearch
+	IndexedDocs int `json:",omitempty"`
+	//FailedDocs number of documents failed to index
+	FailedDocs int `json:",omitempty"`
+	//InferenceURL schema inference report URL
+	InferenceURL string `json:",omitempty"`
+	//SidecarURL checksum sidecar used to verify source object
+	SidecarURL string `json:",omitempty"`
+	//Checksum verified source object checksum
+	Checksum string `json:",omitempty"`
+	//PoisonError last error of dead-lettered source object
+	PoisonError   string `json:",omitempty"`
+	DeadLetterURL string `json:",omitempty"`
+	//DeferredURL paused source object defer location
+	DeferredURL string `json:",omitempty"`
+	//DeferredUntil dest maintenance window end
+	DeferredUntil *time.Time `json:",omitempty"`
+	//DebouncedBy event ID of a later event of the same source object
+	DebouncedBy string `json:",omitempty"`
+	//Delta delta transfer stats
+	Delta *DeltaStats `json:",omitempty"`
+	//Perf runtime memory and allocation telemetry
+	Perf *Perf `json:",omitempty"`
+	//Clients storage client cache and connection stats, stats are process wide
+	Clients *Clients `json:",omitempty"`
+	//PartSizing adaptive multipart upload part size
+	PartSizing *PartSizing `json:",omitempty"`
 	StartTime     time.Time
 	BadRecords    int            `json:",omitempty"`
 	ChecksumSkip  bool           `json:",omitempty"`
 	StreamOption  *option.Stream `json:",omitempty"`
+	ThrottleTimeMs int           `json:",omitempty"`
+	//Lane priority lane used by transfer
+	Lane string `json:",omitempty"`
+	//LaneWaitMs time spent waiting for lane slot
+	LaneWaitMs int `json:",omitempty"`
+	//SourceGeneration source object generation or modification time and size version, stamped on dest objects
+	SourceGeneration string `json:",omitempty"`
+	//PreflightMs time spent on dest preflight check
+	PreflightMs int `json:",omitempty"`
+	//Alert failure alert outcome: sent, suppressed, escalated or recovered
+	Alert string `json:",omitempty"`
+	Evaluations   []*config.Evaluation `json:",omitempty"`
+	Tenant        string               `json:",omitempty"`
+	Labels        map[string]string    `json:",omitempty"`
+	//DestOutcomes per destination output outcome
+	DestOutcomes []*DestOutcome `json:",omitempty"`
+	//Values key value bag populated by transformers
+	Values        map[string]interface{} `json:",omitempty"`
+	//Captures source filter named groups, i.e. partner for (?P<partner>[^/]+)
+	Captures      map[string]string `json:",omitempty"`
 	mutex         *sync.Mutex
+	events        []*eventlog.Event
+	logSuppressed bool
+}
+
+//DeltaStats represents delta transfer stats
+type DeltaStats struct {
+	//Blocks number of dest object blocks
+	Blocks int
+	//Changed number of uploaded blocks
+	Changed int
+	//Transferred uploaded bytes
+	Transferred int64
+	//Reused bytes copied server-side from existing dest object
+	Reused int64
+}
+
+//PartSizing represents adaptive multipart upload part size
+type PartSizing struct {
+	//Size chosen part size in bytes
+	Size int
+	//Reason static, throughput, deadline or maxParts
+	Reason string
+	//Parts expected number of parts
+	Parts int
+	//ThroughputBps observed dest upload throughput, bytes/sec
+	ThroughputBps float64 `json:",omitempty"`
+	//RemainingMs remaining deadline
+	RemainingMs int `json:",omitempty"`
+}
+
+//Perf represents runtime memory and allocation telemetry, heap stats are process wide and include concurrently processed events
+type Perf struct {
+	//HeapPeakBytes max sampled heap in use
+	HeapPeakBytes uint64
+	//SysPeakBytes max sampled memory obtained from OS
+	SysPeakBytes uint64
+	//AllocatedBytes cumulative bytes allocated for heap objects
+	AllocatedBytes uint64
+	//Allocations number of heap objects allocated
+	Allocations uint64
+	//GCCount number of completed GC cycles
+	GCCount uint32
+	//GCPauseMs total and max GC stop-the-world pause
+	GCPauseMs    float64
+	GCPauseMaxMs float64
+	//GoroutinesPeak max sampled number of goroutines
+	GoroutinesPeak int
+	//BufferedBytes source bytes buffered in memory: whole object without streaming, otherwise stream part size
+	BufferedBytes int64
+	//Samples number of runtime stats samples
+	Samples int
+}
+
+//Clients represents storage client cache and HTTP connection stats
+type Clients struct {
+	//Identities number of cached scheme and credential identity clients
+	Identities int
+	//Hits client cache hits
+	Hits uint64
+	//Misses client cache misses, each miss creates new storage clients
+	Misses uint64
+	//Dials new connections opened by shared transport
+	Dials uint64
+	//TLSDials new https connections, each one performs TLS handshake
+	TLSDials uint64
+}
+
+//DestOutcome represents a destination output outcome
+type DestOutcome struct {
+	URL    string
+	Bytes  int64 `json:",omitempty"`
+	Status string
+	Error  string `json:",omitempty"`
+}
+
+//AddOutcome adds destination output outcome
+func (r *Response) AddOutcome(URL string, bytes int64, err error) {
+	outcome := &DestOutcome{URL: URL, Bytes: bytes, Status: base.StatusOK}
+	if err != nil {
+		outcome.Status = base.StatusError
+		outcome.Error = err.Error()
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.DestOutcomes = append(r.DestOutcomes, outcome)
+}
+
+//FailedOutcomes returns failed destination outcomes
+func (r *Response) FailedOutcomes() []*DestOutcome {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var result = make([]*DestOutcome, 0)
+	for _, outcome := range r.DestOutcomes {
+		if outcome.Status != base.StatusOK {
+			result = append(result, outcome)
+		}
+	}
+	return result
 }
 
 //AddURL adds url to dest urls
@@ -36,6 +214,138 @@ func (r *Response) AddURL(URL string) {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	r.DestURLs = append(r.DestURLs, URL)
+	r.events = append(r.events, eventlog.NewEvent(eventlog.TypeUploaded, r.TransferID, r.TriggeredBy, URL))
+}
+
+//AddEvent adds lifecycle event
+func (r *Response) AddEvent(eventType string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, eventlog.NewEvent(eventType, r.TransferID, r.TriggeredBy, ""))
+}
+
+//Events returns lifecycle events with rule name and error
+func (r *Response) Events() []*eventlog.Event {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var result = make([]*eventlog.Event, 0, len(r.events))
+	for _, event := range r.events {
+		if r.Rule != nil {
+			event.Rule = r.Rule.Info.Workflow
+		}
+		if event.Type == eventlog.TypeFailed {
+			event.Error = r.Error
+		}
+		result = append(result, event)
+	}
+	return result
+}
+
+//AddMessageIDs adds published message IDs
+func (r *Response) AddMessageIDs(IDs ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.MessageIDs = append(r.MessageIDs, IDs...)
+}
+
+//AddClaimCheckURLs adds claim-check object URLs
+func (r *Response) AddClaimCheckURLs(URLs ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.ClaimCheckURLs = append(r.ClaimCheckURLs, URLs...)
+}
+
+//AddDocumentCounts adds indexed and failed document counts
+func (r *Response) AddDocumentCounts(indexed, failed int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.IndexedDocs += indexed
+	r.FailedDocs += failed
+}
+
+//AddLabels adds labels, already defined labels take precedence
+func (r *Response) AddLabels(labels map[string]string) {
+	if len(labels) == 0 {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.Labels == nil {
+		r.Labels = make(map[string]string)
+	}
+	for k, v := range labels {
+		if _, ok := r.Labels[k]; !ok {
+			r.Labels[k] = v
+		}
+	}
+}
+
+//SetValue sets transformer value
+func (r *Response) SetValue(key string, value interface{}) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.Values == nil {
+		r.Values = make(map[string]interface{})
+	}
+	r.Values[key] = value
+}
+
+//IncrementValue increments int value, i.e. counts aggregated across split parts
+func (r *Response) IncrementValue(key string, delta int) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.Values == nil {
+		r.Values = make(map[string]interface{})
+	}
+	count, _ := r.Values[key].(int)
+	r.Values[key] = count + delta
+}
+
+//Redact masks secrets in response errors and dest URLs
+func (r *Response) Redact() {
+	r.Error = shared.Redact(r.Error)
+	r.LogError = shared.Redact(r.LogError)
+	r.SchemaError = shared.Redact(r.SchemaError)
+	r.NotFoundError = shared.Redact(r.NotFoundError)
+	r.PoisonError = shared.Redact(r.PoisonError)
+	for i, URL := range r.DestURLs {
+		r.DestURLs[i] = shared.Redact(URL)
+	}
+	for _, outcome := range r.DestOutcomes {
+		outcome.URL = shared.Redact(outcome.URL)
+		outcome.Error = shared.Redact(outcome.Error)
+	}
+}
+
+//ClassifyError sets error code and class
+func (r *Response) ClassifyError(err error) {
+	r.ErrorCode = base.ErrorCode(err)
+	r.ErrorClass = base.ErrorClass(r.ErrorCode)
+}
+
+//SuppressLog suppresses response log by rule log level or sampling
+func (r *Response) SuppressLog() {
+	r.logSuppressed = true
+}
+
+//IsLogSuppressed returns true if response should not be logged
+func (r *Response) IsLogSuppressed() bool {
+	return r.logSuppressed
+}
+
+//IsRetryable returns true if response error is retryable
+func (r *Response) IsRetryable() bool {
+	return r.Error != "" && r.ErrorClass != base.ErrorClassTerminal
+}
+
+//AddThrottleTime adds time spent waiting for provider rate limit
+func (r *Response) AddThrottleTime(duration time.Duration) {
+	if duration <= 0 {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.ThrottleTimeMs += int(duration / time.Millisecond)
 }
 
 //NewResponse returns a new response
@@ -0,0 +1,78 @@
+package contract
+
+import (
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"time"
+)
+
+//RuleHistoryRequest represents request evaluating source URL against rules active at a given time and now
+type RuleHistoryRequest struct {
+	//URL source object URL
+	URL string
+	//At time the rules were active
+	At time.Time
+	//Tenant optional tenant name for multi tenant config
+	Tenant string
+}
+
+//RuleRoute represents matched rule route
+type RuleRoute struct {
+	RuleURL       string
+	Workflow      string `json:",omitempty"`
+	ConfigVersion string `json:",omitempty"`
+	//Dest dest URL, topic or queue
+	Dest string `json:",omitempty"`
+}
+
+//RuleRouting represents source URL routing by a rule set
+type RuleRouting struct {
+	//Rules number of active rules
+	Rules       int
+	Routes      []*RuleRoute         `json:",omitempty"`
+	Evaluations []*config.Evaluation `json:",omitempty"`
+}
+
+//RuleHistoryResponse represents source URL routing then and now
+type RuleHistoryResponse struct {
+	URL  string
+	At   time.Time
+	Then *RuleRouting `json:",omitempty"`
+	Now  *RuleRouting `json:",omitempty"`
+	//Changed true if matched rules, their versions or dest differ
+	Changed bool
+	Status  string
+	Error   string `json:",omitempty"`
+}
+
+//Init sets changed flag comparing then and now routes
+func (r *RuleHistoryResponse) Init() {
+	if r.Then == nil || r.Now == nil {
+		return
+	}
+	r.Changed = len(r.Then.Routes) != len(r.Now.Routes)
+	for i := 0; !r.Changed && i < len(r.Then.Routes); i++ {
+		r.Changed = *r.Then.Routes[i] != *r.Now.Routes[i]
+	}
+}
+
+//NewRuleRouting creates source URL routing for supplied rules
+func NewRuleRouting(rules *config.Ruleset, URL string) *RuleRouting {
+	result := &RuleRouting{Rules: len(rules.Snapshot()), Routes: make([]*RuleRoute, 0), Evaluations: rules.Evaluate(URL)}
+	for _, rule := range rules.Match(URL) {
+		route := &RuleRoute{RuleURL: rule.Info.URL, Workflow: rule.Info.Workflow, ConfigVersion: rule.ConfigVersion}
+		if rule.Dest != nil {
+			route.Dest = rule.Dest.URL
+			if route.Dest == "" {
+				route.Dest = rule.Dest.Topic + rule.Dest.Queue
+			}
+		}
+		result.Routes = append(result.Routes, route)
+	}
+	return result
+}
+
+//NewRuleHistoryResponse creates rule history response
+func NewRuleHistoryResponse(URL string, at time.Time) *RuleHistoryResponse {
+	return &RuleHistoryResponse{URL: URL, At: at, Status: base.StatusOK}
+}
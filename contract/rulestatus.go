@@ -0,0 +1,39 @@
+package contract
+
+import (
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+)
+
+//RuleStatusRequest represents rule files status request
+type RuleStatusRequest struct {
+	//Tenant optional tenant name for multi tenant config
+	Tenant string
+}
+
+//RuleStatusResponse represents rule files status response
+type RuleStatusResponse struct {
+	Files []*config.RuleFileStatus `json:",omitempty"`
+	//Failed number of rule files failed to load
+	Failed int
+	Status string
+	Error  string `json:",omitempty"`
+}
+
+//Init sets failed count, response status is error if any rule file failed to load
+func (r *RuleStatusResponse) Init() {
+	r.Failed = 0
+	for _, file := range r.Files {
+		if file.Error != "" {
+			r.Failed++
+		}
+	}
+	if r.Failed > 0 && r.Status == base.StatusOK {
+		r.Status = base.StatusError
+	}
+}
+
+//NewRuleStatusResponse creates rule status response
+func NewRuleStatusResponse() *RuleStatusResponse {
+	return &RuleStatusResponse{Status: base.StatusOK}
+}
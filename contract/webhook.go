@@ -0,0 +1,53 @@
+package contract
+
+import (
+	"github.com/viant/smirror/base"
+	"net/http"
+	"sync"
+)
+
+//WebhookRequest represents partner push notification
+type WebhookRequest struct {
+	//Tenant optional tenant name for multi tenant config
+	Tenant string
+	//Partner partner name
+	Partner string
+	//Header notification HTTP header with partner signature and timestamp
+	Header http.Header `json:"-"`
+	//Payload raw notification payload, signature is verified against it
+	Payload []byte
+}
+
+//WebhookTrigger represents triggered source object mirror outcome
+type WebhookTrigger struct {
+	URL    string
+	Status string
+	Error  string `json:",omitempty"`
+}
+
+//WebhookResponse represents webhook response
+type WebhookResponse struct {
+	Partner   string `json:",omitempty"`
+	SourceURL string `json:",omitempty"`
+	//Triggered mirrored source objects
+	Triggered []*WebhookTrigger `json:",omitempty"`
+	//Truncated notified prefix had more than webhook MaxFiles objects
+	Truncated bool `json:",omitempty"`
+	//Denied notification failed partner or signature verification
+	Denied bool `json:",omitempty"`
+	Status string
+	Error  string `json:",omitempty"`
+	mux    sync.Mutex
+}
+
+//AddTriggered adds triggered object mirror outcome
+func (r *WebhookResponse) AddTriggered(URL string, response *Response) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.Triggered = append(r.Triggered, &WebhookTrigger{URL: URL, Status: response.Status, Error: response.Error})
+}
+
+//NewWebhookResponse creates webhook response
+func NewWebhookResponse() *WebhookResponse {
+	return &WebhookResponse{Status: base.StatusOK}
+}
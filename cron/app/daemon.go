@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/cron"
+	"github.com/viant/smirror/shared"
+	"log"
+	"time"
+)
+
+func main() {
+	ctx := context.Background()
+	config, err := cron.NewConfigFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		log.Fatalf("failed to load config: %v %v", base.ConfigEnvKey, err)
+	}
+	service, err := cron.New(ctx, config, afs.New())
+	if err != nil {
+		log.Fatalf("failed to create cron service: %v", err)
+	}
+	for {
+		response := service.Tick(ctx)
+		shared.LogLn(response)
+		time.Sleep(time.Duration(config.IntervalSec) * time.Second)
+	}
+}
@@ -0,0 +1,47 @@
+package cron
+
+import (
+	"context"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/box"
+	"github.com/viant/smirror/cron/config"
+	"github.com/viant/smirror/dropbox"
+	"path"
+	"time"
+)
+
+//changeLister lists objects changed since cursor, it returns next cursor
+type changeLister func(ctx context.Context, URL, cursor string, options ...storage.Option) ([]storage.Object, string, error)
+
+//changeListers cursor based change listers keyed by source URL scheme, used instead of listing with time window
+var changeListers = map[string]changeLister{
+	box.Scheme:     box.Changes,
+	dropbox.Scheme: dropbox.Changes,
+}
+
+//listChanges returns changed rule source objects and next cursor, without cursor (first run) only objects modified within time window are returned
+func (s *service) listChanges(ctx context.Context, lister changeLister, rule *config.Rule, options []storage.Option) ([]storage.Object, string, error) {
+	cursor, err := s.ruleMeta(rule).Cursor(ctx, rule.Source.URL)
+	if err != nil {
+		return nil, "", err
+	}
+	objects, next, err := lister(ctx, rule.Source.URL, cursor, options...)
+	if err != nil {
+		return nil, "", err
+	}
+	afterTime := time.Now().Add(-s.config.TimeWindow.Duration)
+	var result = make([]storage.Object, 0)
+	for i := range objects {
+		if cursor == "" && objects[i].ModTime().Before(afterTime) {
+			continue
+		}
+		_, URLPath := url.Base(objects[i].URL(), file.Scheme)
+		parent, _ := path.Split(URLPath)
+		if rule.Source.Match(parent, objects[i]) {
+			result = append(result, objects[i])
+		}
+	}
+	return result, next, nil
+}
@@ -0,0 +1,229 @@
+package cron
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/cron/config"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+//compact coalesces small objects under rule source URL per folder, writes compacted objects with folder manifest
+//and then removes compacted inputs
+func (s *service) compact(ctx context.Context, rule *config.Rule, response *Response) error {
+	compaction := rule.Compaction
+	now := time.Now()
+	if !compaction.IsDue(now) || !s.startCompaction(rule.Source.URL, now) {
+		return nil
+	}
+	options, err := s.secret.StorageOpts(ctx, &rule.Source)
+	if err != nil {
+		return err
+	}
+	destOptions := options
+	if rule.Dest.URL != "" {
+		if destOptions, err = s.secret.StorageOpts(ctx, &rule.Dest); err != nil {
+			return err
+		}
+	}
+	var objects = make([]storage.Object, 0)
+	if err = s.appendResources(ctx, rule.Source.URL, 1, &objects, &rule.Source, rule.Listing, options); err != nil {
+		return errors.Wrapf(err, "failed to list %v", rule.Source.URL)
+	}
+	folders := compactionCandidates(compaction, objects, now)
+	var folderURLs = make([]string, 0, len(folders))
+	for folderURL := range folders {
+		folderURLs = append(folderURLs, folderURL)
+	}
+	sort.Strings(folderURLs)
+	for _, folderURL := range folderURLs {
+		manifest, err := s.compactFolder(ctx, rule, folderURL, folders[folderURL], now, options, destOptions)
+		if err != nil {
+			return errors.Wrapf(err, "failed to compact %v", folderURL)
+		}
+		if manifest != nil {
+			response.Compacted = append(response.Compacted, manifest)
+		}
+	}
+	return nil
+}
+
+//startCompaction returns true if rule compaction did not run within supplied time minute yet
+func (s *service) startCompaction(sourceURL string, now time.Time) bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.compactedAt == nil {
+		s.compactedAt = make(map[string]time.Time)
+	}
+	minute := now.Truncate(time.Minute)
+	if last, ok := s.compactedAt[sourceURL]; ok && !last.Before(minute) {
+		return false
+	}
+	s.compactedAt[sourceURL] = minute
+	return true
+}
+
+//compactionCandidates returns small objects old enough to compact, grouped by folder URL and file extension
+func compactionCandidates(compaction *config.Compaction, objects []storage.Object, now time.Time) map[string]map[string][]storage.Object {
+	var result = make(map[string]map[string][]storage.Object)
+	for _, object := range objects {
+		if object.IsDir() || compaction.IsManifest(object.Name()) || object.Size() >= compaction.TargetSize() {
+			continue
+		}
+		if now.Sub(object.ModTime()) < compaction.MinAge.Duration() {
+			continue
+		}
+		parentURL, _ := url.Split(object.URL(), file.Scheme)
+		if _, ok := result[parentURL]; !ok {
+			result[parentURL] = make(map[string][]storage.Object)
+		}
+		ext := config.FileExt(object.Name())
+		result[parentURL][ext] = append(result[parentURL][ext], object)
+	}
+	return result
+}
+
+//compactFolder compacts folder objects grouped by extension, it returns nil manifest if nothing was compacted
+func (s *service) compactFolder(ctx context.Context, rule *config.Rule, folderURL string, groups map[string][]storage.Object, now time.Time, options, destOptions []storage.Option) (*config.CompactionManifest, error) {
+	compaction := rule.Compaction
+	destURL := folderURL
+	if rule.Dest.URL != "" {
+		relative := strings.Trim(strings.TrimPrefix(url.Path(folderURL), url.Path(rule.Source.URL)), "/")
+		destURL = url.Join(rule.Dest.URL, relative)
+	}
+	manifest := &config.CompactionManifest{
+		URL:     url.Join(destURL, compaction.ManifestName(now)),
+		Folder:  folderURL,
+		Created: now,
+	}
+	var exts = make([]string, 0, len(groups))
+	for ext := range groups {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	for _, ext := range exts {
+		objects := groups[ext]
+		if len(objects) < compaction.MinFiles {
+			continue
+		}
+		for _, bin := range compactionBins(objects, compaction.TargetSize()) {
+			data, err := s.compactedContent(ctx, compaction, bin, ext, options)
+			if err != nil {
+				return nil, err
+			}
+			output := &config.CompactedObject{
+				URL:  url.Join(destURL, compaction.ObjectName(now, len(manifest.Outputs)+1, ext)),
+				Size: int64(len(data)),
+			}
+			for _, object := range bin {
+				output.Inputs = append(output.Inputs, object.URL())
+			}
+			if err = s.fs.Upload(ctx, output.URL, file.DefaultFileOsMode, bytes.NewReader(data), destOptions...); err != nil {
+				return nil, errors.Wrapf(err, "failed to upload %v", output.URL)
+			}
+			manifest.Outputs = append(manifest.Outputs, output)
+		}
+	}
+	if len(manifest.Outputs) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.fs.Upload(ctx, manifest.URL, file.DefaultFileOsMode, bytes.NewReader(data), destOptions...); err != nil {
+		return nil, errors.Wrapf(err, "failed to upload manifest %v", manifest.URL)
+	}
+	//inputs are only removed once compacted objects and manifest were written
+	for _, output := range manifest.Outputs {
+		for _, inputURL := range output.Inputs {
+			if err = s.fs.Delete(ctx, inputURL, options...); err != nil {
+				return nil, errors.Wrapf(err, "failed to delete compacted %v", inputURL)
+			}
+		}
+	}
+	return manifest, nil
+}
+
+//compactionBins orders objects (modification time, then URL) and packs them into bins up to target size, single object bins are left out
+func compactionBins(objects []storage.Object, targetSize int64) [][]storage.Object {
+	sort.Slice(objects, func(i, j int) bool {
+		if modified := objects[i].ModTime(); !modified.Equal(objects[j].ModTime()) {
+			return modified.Before(objects[j].ModTime())
+		}
+		return objects[i].URL() < objects[j].URL()
+	})
+	var result [][]storage.Object
+	var bin []storage.Object
+	var size int64
+	for _, object := range objects {
+		if len(bin) > 0 && size+object.Size() > targetSize {
+			result = append(result, bin)
+			bin, size = nil, 0
+		}
+		bin = append(bin, object)
+		size += object.Size()
+	}
+	result = append(result, bin)
+	var bins = make([][]storage.Object, 0, len(result))
+	for _, candidate := range result {
+		if len(candidate) > 1 {
+			bins = append(bins, candidate)
+		}
+	}
+	return bins
+}
+
+//compactedContent returns concatenated objects content, CSV header is kept only once and CSV/NDJSON content ends with a new line
+func (s *service) compactedContent(ctx context.Context, compaction *config.Compaction, objects []storage.Object, ext string, options []storage.Option) ([]byte, error) {
+	compressed := path.Ext(ext) == ".gz"
+	format := compaction.FileFormat(ext)
+	buffer := new(bytes.Buffer)
+	for i, object := range objects {
+		data, err := s.fs.Download(ctx, object, options...)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to download %v", object.URL())
+		}
+		if compressed {
+			reader, err := gzip.NewReader(bytes.NewReader(data))
+			if err == nil {
+				data, err = ioutil.ReadAll(reader)
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to decompress %v", object.URL())
+			}
+		}
+		if format == config.FormatCSV && compaction.Header && i > 0 {
+			if index := bytes.IndexByte(data, '\n'); index != -1 {
+				data = data[index+1:]
+			} else {
+				data = nil
+			}
+		}
+		buffer.Write(data)
+		if format != config.FormatRaw && len(data) > 0 && data[len(data)-1] != '\n' {
+			buffer.WriteByte('\n')
+		}
+	}
+	if !compressed {
+		return buffer.Bytes(), nil
+	}
+	result := new(bytes.Buffer)
+	writer := gzip.NewWriter(result)
+	if _, err := writer.Write(buffer.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return result.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+package cron
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/cron/config"
+	"github.com/viant/smirror/proxy"
+	"github.com/viant/smirror/secret"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestService_Compact(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/compaction/data"
+	_ = fs.Delete(ctx, "mem://localhost/compaction")
+	old := time.Now().Add(-time.Hour)
+	for _, item := range []struct {
+		name    string
+		content string
+		modTime time.Time
+	}{
+		{"f1.csv", "id,name\n1,a\n", old},
+		{"f2.csv", "id,name\n2,b", old.Add(time.Second)},
+		{"f3.csv", "id,name\n3,c\n", old.Add(2 * time.Second)},
+		{"f4.csv", "id,name\n4,d\n", time.Now()},
+		{"f5.json", `{"id":5}`, old},
+		{"sub/f6.json", `{"id":6}`, old},
+		{"sub/f7.json", `{"id":7}` + "\n", old.Add(time.Second)},
+	} {
+		assert.Nil(t, fs.Upload(ctx, baseURL+"/"+item.name, 0644, strings.NewReader(item.content), item.modTime))
+	}
+	rule := &config.Rule{Compaction: &config.Compaction{Header: true}}
+	rule.Source.URL = baseURL
+	rule.Dest.URL = "mem://localhost/compaction/compacted"
+	assert.Nil(t, rule.Compaction.Validate())
+	srv := &service{config: &Config{}, fs: fs, secret: secret.New("mem", fs)}
+	response := NewResponse(proxy.NewResponse())
+	if !assert.Nil(t, srv.compact(ctx, rule, response)) || !assert.Equal(t, 2, len(response.Compacted)) {
+		return
+	}
+	expect := []struct {
+		folder  string
+		inputs  []string
+		content string
+	}{
+		{folder: baseURL, inputs: []string{"f1.csv", "f2.csv", "f3.csv"}, content: "id,name\n1,a\n2,b\n3,c\n"},
+		{folder: baseURL + "/sub", inputs: []string{"sub/f6.json", "sub/f7.json"}, content: "{\"id\":6}\n{\"id\":7}\n"},
+	}
+	for i, manifest := range response.Compacted {
+		assert.Equal(t, expect[i].folder, manifest.Folder)
+		if !assert.Equal(t, 1, len(manifest.Outputs)) {
+			continue
+		}
+		output := manifest.Outputs[0]
+		assert.True(t, strings.HasPrefix(output.URL, "mem://localhost/compaction/compacted/"), output.URL)
+		assert.Equal(t, len(expect[i].inputs), len(output.Inputs))
+		for j, name := range expect[i].inputs {
+			assert.Equal(t, baseURL+"/"+name, output.Inputs[j])
+			exists, _ := fs.Exists(ctx, output.Inputs[j])
+			assert.False(t, exists, "compacted input is removed")
+		}
+		content, err := fs.DownloadWithURL(ctx, output.URL)
+		assert.Nil(t, err)
+		assert.Equal(t, expect[i].content, string(content))
+		exists, _ := fs.Exists(ctx, manifest.URL)
+		assert.True(t, exists, manifest.URL)
+	}
+	for _, name := range []string{"f4.csv", "f5.json"} {
+		exists, _ := fs.Exists(ctx, baseURL+"/"+name)
+		assert.True(t, exists, name)
+	}
+
+	response = NewResponse(proxy.NewResponse())
+	assert.Nil(t, srv.compact(ctx, rule, response))
+	assert.Equal(t, 0, len(response.Compacted), "compaction runs once per minute")
+}
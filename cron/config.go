@@ -6,18 +6,36 @@ import (
 	"github.com/pkg/errors"
 	"github.com/viant/smirror/base"
 	"github.com/viant/smirror/cron/config"
+	"github.com/viant/smirror/throttle"
 	"github.com/viant/afs"
 	"github.com/viant/toolbox"
 	"os"
 	"strings"
 )
 
+const (
+	defaultIntervalSec    = 60
+	defaultStreamFlushSec = 10
+)
+
 //Config represents cron config
 type Config struct {
 	base.Config
 	MetaURL    string
 	TimeWindow config.TimeWindow
 	Resources  config.Ruleset
+	//RateLimit destination provider API rate limit shared by all tick workers
+	RateLimit throttle.Config
+	//Sharding assigns rules to daemon replicas with consistent hashing
+	Sharding *Sharding `json:",omitempty"`
+	//IntervalSec daemon tick interval, default 60
+	IntervalSec base.Seconds `json:",omitempty"`
+	//MetaPerRule keeps processed state per rule next to MetaURL, it is always the case with sharding
+	MetaPerRule bool `json:",omitempty"`
+	//StreamFlushSec streamed HTTP tick progress record interval, so that caller does not time out waiting for data, default 10
+	StreamFlushSec base.Seconds `json:",omitempty"`
+	//Queue persisted work queue, pending resources are queued and drained with bounded batches across ticks
+	Queue *config.Queue `json:",omitempty"`
 }
 
 //Load initialises routes
@@ -30,6 +48,24 @@ func (c *Config) Init(ctx context.Context, fs afs.Service) error {
 	if c.MetaURL == "" {
 		return errors.New("metaURL was empty")
 	}
+	if c.IntervalSec == 0 {
+		c.IntervalSec = defaultIntervalSec
+	}
+	if c.StreamFlushSec == 0 {
+		c.StreamFlushSec = defaultStreamFlushSec
+	}
+	if c.Queue != nil {
+		c.Queue.Init()
+		if err := c.Queue.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.Sharding != nil {
+		c.Sharding.Init()
+		if err := c.Sharding.Validate(); err != nil {
+			return err
+		}
+	}
 	return c.Resources.Init(ctx, fs, c.ProjectID)
 }
 
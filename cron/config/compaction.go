@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	//FormatCSV CSV compaction format, header line is kept only once
+	FormatCSV = "csv"
+	//FormatNDJSON new line delimited JSON compaction format, each file content ends with a new line
+	FormatNDJSON = "ndjson"
+	//FormatRaw raw compaction format, files content is concatenated as is
+	FormatRaw = "raw"
+
+	defaultCompactionPrefix       = "compacted"
+	defaultCompactionTargetSizeMb = 128
+	defaultCompactionMinFiles     = 2
+	defaultCompactionMinAge       = 300
+	compactionTimeLayout          = "20060102-150405"
+)
+
+//Compaction represents small files compaction rule, small objects under rule source URL are periodically coalesced
+//per folder into fewer objects up to the target size
+type Compaction struct {
+	//Schedule optional cron expression (minute hour day-of-month month day-of-week), every tick by default
+	Schedule string `json:",omitempty"`
+	//TargetSizeMb compacted object target size, default 128
+	TargetSizeMb base.Megabytes `json:",omitempty"`
+	//MinFiles min number of small files in a folder to compact, default 2
+	MinFiles int `json:",omitempty"`
+	//MinAge min object age, number of seconds or duration text, so that objects still being written are left out, default 5 min
+	MinAge base.Seconds `json:",omitempty"`
+	//Format csv, ndjson or raw, detected from file extension by default
+	Format string `json:",omitempty"`
+	//Header flags CSV files with header line
+	Header bool `json:",omitempty"`
+	//Prefix compacted object and manifest name prefix, default compacted
+	Prefix string `json:",omitempty"`
+	schedule *config.Schedule
+}
+
+//CompactionManifest represents compaction rewrite of a folder
+type CompactionManifest struct {
+	URL     string
+	Folder  string
+	Created time.Time
+	Outputs []*CompactedObject
+}
+
+//CompactedObject represents compacted object with its inputs
+type CompactedObject struct {
+	URL    string
+	Size   int64
+	Inputs []string
+}
+
+//Init initialises compaction
+func (c *Compaction) Init() {
+	if c.TargetSizeMb == 0 {
+		c.TargetSizeMb = defaultCompactionTargetSizeMb
+	}
+	if c.MinFiles == 0 {
+		c.MinFiles = defaultCompactionMinFiles
+	}
+	if c.MinAge == 0 {
+		c.MinAge = defaultCompactionMinAge
+	}
+	if c.Prefix == "" {
+		c.Prefix = defaultCompactionPrefix
+	}
+	if c.Schedule != "" && c.schedule == nil {
+		c.schedule, _ = config.NewSchedule(c.Schedule)
+	}
+}
+
+//Validate checks if compaction is valid
+func (c *Compaction) Validate() error {
+	c.Init()
+	if c.TargetSizeMb < 0 || c.MinAge < 0 || c.MinFiles < 2 {
+		return fmt.Errorf("invalid compaction: TargetSizeMb: %v, MinAge: %v, MinFiles: %v (min 2)", c.TargetSizeMb, c.MinAge, c.MinFiles)
+	}
+	if strings.Contains(c.Prefix, "/") {
+		return fmt.Errorf("invalid compaction.Prefix: %v", c.Prefix)
+	}
+	switch c.Format {
+	case "", FormatCSV, FormatNDJSON, FormatRaw:
+	default:
+		return fmt.Errorf("unsupported compaction format: %v, supported: %v, %v, %v", c.Format, FormatCSV, FormatNDJSON, FormatRaw)
+	}
+	if c.Schedule != "" {
+		if _, err := config.NewSchedule(c.Schedule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//IsDue returns true if compaction is scheduled at supplied time minute, unscheduled compaction runs every tick
+func (c *Compaction) IsDue(now time.Time) bool {
+	return c.schedule == nil || c.schedule.Match(now)
+}
+
+//TargetSize returns compacted object target size in bytes
+func (c *Compaction) TargetSize() int64 {
+	return int64(c.TargetSizeMb) * 1024 * 1024
+}
+
+//FileFormat returns compaction format for supplied file name
+func (c *Compaction) FileFormat(name string) string {
+	if c.Format != "" {
+		return c.Format
+	}
+	switch path.Ext(strings.TrimSuffix(name, ".gz")) {
+	case ".csv":
+		return FormatCSV
+	case ".json", ".ndjson", ".jsonl":
+		return FormatNDJSON
+	}
+	return FormatRaw
+}
+
+//IsManifest returns true if supplied name is compaction manifest name
+func (c *Compaction) IsManifest(name string) bool {
+	return strings.HasPrefix(name, "_"+c.Prefix+"-") && strings.HasSuffix(name, ".json")
+}
+
+//ObjectName returns compacted object name
+func (c *Compaction) ObjectName(now time.Time, seq int, ext string) string {
+	return fmt.Sprintf("%v-%v-%04d%v", c.Prefix, now.UTC().Format(compactionTimeLayout), seq, ext)
+}
+
+//ManifestName returns compaction manifest name
+func (c *Compaction) ManifestName(now time.Time) string {
+	return "_" + c.Prefix + "-" + now.UTC().Format(compactionTimeLayout) + ".json"
+}
+
+//FileExt returns file extension including compression, i.e. .csv.gz
+func FileExt(name string) string {
+	ext := path.Ext(name)
+	if ext == ".gz" {
+		ext = path.Ext(strings.TrimSuffix(name, ext)) + ext
+	}
+	return ext
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCompaction_Validate(t *testing.T) {
+	var useCases = []struct {
+		description string
+		compaction  *Compaction
+		name        string
+		expectFmt   string
+		hasError    bool
+	}{
+		{description: "csv detection", compaction: &Compaction{}, name: "events.csv.gz", expectFmt: FormatCSV},
+		{description: "ndjson detection", compaction: &Compaction{}, name: "events.jsonl", expectFmt: FormatNDJSON},
+		{description: "raw detection", compaction: &Compaction{}, name: "events.log", expectFmt: FormatRaw},
+		{description: "explicit format", compaction: &Compaction{Format: FormatNDJSON}, name: "events.log", expectFmt: FormatNDJSON},
+		{description: "invalid format", compaction: &Compaction{Format: "parquet"}, hasError: true},
+		{description: "invalid min files", compaction: &Compaction{MinFiles: 1}, hasError: true},
+		{description: "invalid schedule", compaction: &Compaction{Schedule: "* *"}, hasError: true},
+	}
+	for _, useCase := range useCases {
+		err := useCase.compaction.Validate()
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+		assert.Equal(t, useCase.expectFmt, useCase.compaction.FileFormat(useCase.name), useCase.description)
+	}
+	compaction := &Compaction{Schedule: "0 * * * *"}
+	assert.Nil(t, compaction.Validate())
+	assert.True(t, compaction.IsDue(time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)))
+	assert.False(t, compaction.IsDue(time.Date(2026, 10, 15, 10, 1, 0, 0, time.UTC)))
+	assert.True(t, compaction.IsManifest(compaction.ManifestName(time.Now())))
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"fmt"
+	"github.com/viant/afs/storage"
+	"sort"
+)
+
+const (
+	//OrderOldestFirst processes pending resources with the oldest modification time first
+	OrderOldestFirst = "oldest-first"
+	//OrderNewestFirst processes pending resources with the newest modification time first
+	OrderNewestFirst = "newest-first"
+)
+
+//Listing represents source listing limits, it keeps listing of deep trees within function memory and time
+type Listing struct {
+	//MaxDepth max listed folder depth, source folder is depth 1, 0 - no limit
+	MaxDepth int `json:",omitempty"`
+	//MaxResults max pending resources processed per tick, the rest is processed with the following ticks, 0 - no limit
+	MaxResults int `json:",omitempty"`
+	//PageSize max objects fetched with one listing call, a folder is listed with subsequent page calls, 0 - one call per folder
+	PageSize int `json:",omitempty"`
+	//Order pending resources order: oldest-first or newest-first, it decides what is processed once MaxResults is reached
+	Order string `json:",omitempty"`
+}
+
+//Validate checks if listing is valid
+func (l *Listing) Validate() error {
+	if l.MaxDepth < 0 || l.MaxResults < 0 || l.PageSize < 0 {
+		return fmt.Errorf("listing MaxDepth, MaxResults and PageSize can not be negative")
+	}
+	switch l.Order {
+	case "", OrderOldestFirst, OrderNewestFirst:
+		return nil
+	}
+	return fmt.Errorf("invalid listing order: %v, supported: %v, %v", l.Order, OrderOldestFirst, OrderNewestFirst)
+}
+
+//CanDescend returns true if subfolders of folder at supplied depth are listed
+func (l *Listing) CanDescend(depth int) bool {
+	return l == nil || l.MaxDepth == 0 || depth < l.MaxDepth
+}
+
+//Limit orders objects (modification time, then URL) and applies MaxResults, it returns true if some objects were left out
+func (l *Listing) Limit(objects []storage.Object) ([]storage.Object, bool) {
+	if l == nil {
+		return objects, false
+	}
+	if l.Order != "" {
+		newestFirst := l.Order == OrderNewestFirst
+		sort.Slice(objects, func(i, j int) bool {
+			if modified := objects[i].ModTime(); !modified.Equal(objects[j].ModTime()) {
+				return modified.After(objects[j].ModTime()) == newestFirst
+			}
+			return objects[i].URL() < objects[j].URL()
+		})
+	}
+	if l.MaxResults == 0 || len(objects) <= l.MaxResults {
+		return objects, false
+	}
+	return objects[:l.MaxResults], true
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/object"
+	"github.com/viant/afs/storage"
+	"testing"
+	"time"
+)
+
+func TestListing_Limit(t *testing.T) {
+	now := time.Now()
+	var objects = func() []storage.Object {
+		var result []storage.Object
+		for _, item := range []struct {
+			name     string
+			modified time.Time
+		}{
+			{"f2", now.Add(-2 * time.Minute)},
+			{"f1", now.Add(-time.Minute)},
+			{"f3", now.Add(-3 * time.Minute)},
+			{"f0", now.Add(-time.Minute)},
+		} {
+			info := file.NewInfo(item.name, 1, file.DefaultFileOsMode, item.modified, false)
+			result = append(result, object.New("mem://localhost/listing/"+item.name, info, nil))
+		}
+		return result
+	}
+
+	var useCases = []struct {
+		description string
+		listing     *Listing
+		expect      []string
+		truncated   bool
+	}{
+		{
+			description: "no listing",
+			expect:      []string{"f2", "f1", "f3", "f0"},
+		},
+		{
+			description: "oldest first",
+			listing:     &Listing{Order: OrderOldestFirst, MaxResults: 3},
+			expect:      []string{"f3", "f2", "f0"},
+			truncated:   true,
+		},
+		{
+			description: "newest first",
+			listing:     &Listing{Order: OrderNewestFirst, MaxResults: 2},
+			expect:      []string{"f0", "f1"},
+			truncated:   true,
+		},
+		{
+			description: "max results not reached",
+			listing:     &Listing{Order: OrderNewestFirst, MaxResults: 10},
+			expect:      []string{"f0", "f1", "f2", "f3"},
+		},
+	}
+
+	for _, useCase := range useCases {
+		actual, truncated := useCase.listing.Limit(objects())
+		var names []string
+		for _, candidate := range actual {
+			names = append(names, candidate.Name())
+		}
+		assert.EqualValues(t, useCase.expect, names, useCase.description)
+		assert.EqualValues(t, useCase.truncated, truncated, useCase.description)
+	}
+}
+
+func TestListing_Validate(t *testing.T) {
+	assert.Nil(t, (&Listing{MaxDepth: 2, Order: OrderOldestFirst}).Validate())
+	assert.NotNil(t, (&Listing{Order: "random"}).Validate())
+	assert.NotNil(t, (&Listing{PageSize: -1}).Validate())
+	assert.True(t, (*Listing)(nil).CanDescend(5))
+	assert.True(t, (&Listing{MaxDepth: 2}).CanDescend(1))
+	assert.False(t, (&Listing{MaxDepth: 2}).CanDescend(2))
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	defaultQueueBatchSize   = 1000
+	defaultQueueMinPerRule  = 10
+	defaultQueueMaxFailures = 3
+)
+
+//Queue represents persisted work queue settings, pending resources are queued and drained with bounded batches across ticks
+type Queue struct {
+	//BaseURL queue state base URL, default queue folder next to MetaURL
+	BaseURL string `json:",omitempty"`
+	//BatchSize max queued resources claimed per tick across all rules, default 1000
+	BatchSize int `json:",omitempty"`
+	//MinPerRule min queued resources claimed per rule with backlog, so that one large backlog does not starve other rules, default 10
+	MinPerRule int `json:",omitempty"`
+	//MaxFailures number of failed processing attempts after which queued resource is dead-lettered, default 3
+	MaxFailures int `json:",omitempty"`
+}
+
+//Init initialises queue defaults
+func (q *Queue) Init() {
+	if q.BatchSize == 0 {
+		q.BatchSize = defaultQueueBatchSize
+	}
+	if q.MinPerRule == 0 {
+		q.MinPerRule = defaultQueueMinPerRule
+	}
+	if q.MaxFailures == 0 {
+		q.MaxFailures = defaultQueueMaxFailures
+	}
+	if q.MinPerRule > q.BatchSize {
+		q.MinPerRule = q.BatchSize
+	}
+}
+
+//Validate checks if queue is valid
+func (q *Queue) Validate() error {
+	if q.BatchSize < 0 || q.MinPerRule < 0 || q.MaxFailures < 0 {
+		return fmt.Errorf("queue BatchSize, MinPerRule and MaxFailures can not be negative")
+	}
+	return nil
+}
+
+//Allocate returns number of resources claimed per backlog, backlogs are served in least recently claimed order:
+//each backlog first gets MinPerRule share, the remaining batch is then assigned in the same order
+func (q *Queue) Allocate(backlogs []int, claimedAt []time.Time) []int {
+	order := make([]int, len(backlogs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return claimedAt[order[i]].Before(claimedAt[order[j]])
+	})
+	result := make([]int, len(backlogs))
+	budget := q.BatchSize
+	for _, share := range []int{q.MinPerRule, q.BatchSize} {
+		for _, i := range order {
+			claim := backlogs[i] - result[i]
+			if claim > share-result[i] {
+				claim = share - result[i]
+			}
+			if claim > budget {
+				claim = budget
+			}
+			if claim <= 0 {
+				continue
+			}
+			result[i] += claim
+			budget -= claim
+		}
+	}
+	return result
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestQueue_Allocate(t *testing.T) {
+	now := time.Now()
+	var useCases = []struct {
+		description string
+		queue       *Queue
+		backlogs    []int
+		claimedAt   []time.Time
+		expect      []int
+	}{
+		{
+			description: "batch covers all backlogs",
+			queue:       &Queue{BatchSize: 100, MinPerRule: 10},
+			backlogs:    []int{20, 5, 0},
+			claimedAt:   []time.Time{now, now, now},
+			expect:      []int{20, 5, 0},
+		},
+		{
+			description: "large backlog does not starve small ones",
+			queue:       &Queue{BatchSize: 30, MinPerRule: 5},
+			backlogs:    []int{1000, 8, 3},
+			claimedAt:   []time.Time{now.Add(-time.Hour), now, now.Add(-time.Minute)},
+			expect:      []int{22, 5, 3},
+		},
+		{
+			description: "least recently claimed first when batch is smaller than min shares",
+			queue:       &Queue{BatchSize: 8, MinPerRule: 5},
+			backlogs:    []int{10, 10},
+			claimedAt:   []time.Time{now, now.Add(-time.Minute)},
+			expect:      []int{3, 5},
+		},
+	}
+	for _, useCase := range useCases {
+		actual := useCase.queue.Allocate(useCase.backlogs, useCase.claimedAt)
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+}
@@ -9,4 +9,10 @@ type Rule struct {
 	Source   config.Resource
 	Dest     config.Resource
 	Move     bool `json:",omitempty"`
+	//Listing source listing limits, it applies to sources listed with time window
+	Listing *Listing `json:",omitempty"`
+	//Compaction coalesces small objects under source URL instead of mirroring them, dest URL is optional
+	Compaction *Compaction `json:",omitempty"`
+	//Batch bulk move settings, with Move, sources are copied server side in parallel and deleted in batches
+	Batch *config.Batch `json:",omitempty"`
 }
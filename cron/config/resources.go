@@ -9,41 +9,112 @@ import (
 	"github.com/viant/afs/matcher"
 	"github.com/viant/afs/storage"
 	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"io/ioutil"
+	"path"
+	"sort"
+	"sync"
 	"sync/atomic"
 	"time"
 )
 
-//Ruleset represents resources rules to check for changes to trigger storage event
+//Ruleset represents resources rules to check for changes to trigger storage event,
+//loaded rules are published as immutable snapshot, so a reload never changes rules a tick iterates
 type Ruleset struct {
-	BaseURL      string
-	CheckInMs    int
-	Rules        []*Rule
-	initialRules []*Rule
-	inited       int32
-	projectID    string
-	meta         *base.Meta
+	BaseURL   string
+	CheckInMs base.Milliseconds
+	//Rules initial rules, loaded rules are available with Snapshot
+	Rules     []*Rule
+	//EncryptionKey KMS key decrypting KMS encrypted rule files, SOPS files carry their own key
+	EncryptionKey string `json:",omitempty"`
+	decrypter config.Decrypter
+	projectID string
+	meta      *base.Meta
+	snapshot  atomic.Value
+	files     atomic.Value
+	good      map[string][]*Rule
+	mux       sync.Mutex
+	prepare   func(ctx context.Context, rules []*Rule) error
+	onReload  func(statuses []*config.RuleFileStatus)
 }
 
 //Load initialises resources
 func (r *Ruleset) Init(ctx context.Context, fs afs.Service, projectID string) error {
-	r.initRules()
+	r.mux.Lock()
+	defer r.mux.Unlock()
 	r.projectID = projectID
 	r.meta = base.NewMeta(r.BaseURL, time.Duration(r.CheckInMs)*time.Millisecond)
 	return r.loadAndInit(ctx, fs)
 }
 
+//OnLoad sets handler preparing loaded rules (i.e. secrets) before they are published
+func (r *Ruleset) OnLoad(prepare func(ctx context.Context, rules []*Rule) error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.prepare = prepare
+}
+
+//OnDecrypt sets decrypter used for SOPS or KMS encrypted rule files
+func (r *Ruleset) OnDecrypt(decrypter config.Decrypter) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.decrypter = decrypter
+}
+
+//OnReload sets handler notified with rule files status after rules are (re)loaded
+func (r *Ruleset) OnReload(handler func(statuses []*config.RuleFileStatus)) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.onReload = handler
+}
+
+//Status returns rule files load status
+func (r *Ruleset) Status() []*config.RuleFileStatus {
+	if statuses, ok := r.files.Load().([]*config.RuleFileStatus); ok {
+		return statuses
+	}
+	return []*config.RuleFileStatus{}
+}
+
+//Snapshot returns current rules snapshot, returned rules must not be modified
+func (r *Ruleset) Snapshot() []*Rule {
+	if rules, ok := r.snapshot.Load().([]*Rule); ok {
+		return rules
+	}
+	return r.Rules
+}
+
 func (r *Ruleset) loadAndInit(ctx context.Context, fs afs.Service) (err error) {
-	if err = r.loadAllResources(ctx, fs); err != nil {
+	rules, err := r.loadAllResources(ctx, fs)
+	if err != nil {
 		return err
 	}
-	for i := range r.Rules {
-		r.Rules[i].Source.Init(r.projectID)
-		r.Rules[i].Dest.Init(r.projectID)
+	for i := range rules {
+		rules[i].Source.Init(r.projectID)
+		rules[i].Dest.Init(r.projectID)
+		if rules[i].Compaction != nil {
+			rules[i].Compaction.Init()
+		}
+		if rules[i].Batch != nil {
+			rules[i].Batch.Init()
+			if err = rules[i].Batch.Validate(); err != nil {
+				return errors.Wrapf(err, "invalid rule: %v", rules[i].Source.URL)
+			}
+		}
+	}
+	if r.prepare != nil {
+		if err = r.prepare(ctx, rules); err != nil {
+			return err
+		}
 	}
+	r.snapshot.Store(rules)
 	return nil
 }
 
+//ReloadIfNeeded reloads and publishes new rules snapshot if rules changed, concurrent reloads are serialized
 func (r *Ruleset) ReloadIfNeeded(ctx context.Context, fs afs.Service) (bool, error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
 	changed, err := r.meta.HasChanged(ctx, fs)
 	if err != nil || !changed {
 		return changed, err
@@ -51,63 +122,120 @@ func (r *Ruleset) ReloadIfNeeded(ctx context.Context, fs afs.Service) (bool, err
 	return true, r.loadAndInit(ctx, fs)
 }
 
-func (r *Ruleset) loadAllResources(ctx context.Context, fs afs.Service) error {
+//loadAllResources returns copies of initial rules followed by rules loaded from BaseURL
+func (r *Ruleset) loadAllResources(ctx context.Context, fs afs.Service) ([]*Rule, error) {
+	rules := cloneRules(r.Rules)
 	if r.BaseURL == "" {
-		return nil
+		return rules, nil
 	}
-	r.Rules = r.initialRules
 	exists, err := fs.Exists(ctx, r.BaseURL)
 	if err != nil || !exists {
-		return err
+		return rules, err
 	}
 
 	suffixMatcher, _ := matcher.NewBasic("", ".json", "", nil)
 	routesObject, err := fs.List(ctx, r.BaseURL, suffixMatcher)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	previous := r.statusByURL()
+	good := make(map[string][]*Rule)
+	statuses := make([]*config.RuleFileStatus, 0)
 	for _, object := range routesObject {
 		if object.IsDir() {
 			continue
 		}
-		if err = r.loadResources(ctx, fs, object); err != nil {
-			return err
+		status := &config.RuleFileStatus{URL: object.URL(), ModTime: object.ModTime(), Status: base.StatusOK, LoadedAt: time.Now()}
+		loaded, err := r.loadResources(ctx, fs, object)
+		if err != nil {
+			//broken file does not affect other files, previous good version is kept active
+			status.Status = base.StatusError
+			status.Error = err.Error()
+			status.LoadedAt = time.Time{}
+			if prev, ok := r.good[object.URL()]; ok {
+				status.Stale = true
+				loaded = cloneRules(prev)
+				if prevStatus, ok := previous[object.URL()]; ok {
+					status.LoadedAt = prevStatus.LoadedAt
+				}
+			}
+		}
+		if len(loaded) > 0 {
+			good[object.URL()] = loaded
 		}
+		status.Rules = len(loaded)
+		statuses = append(statuses, status)
+		rules = append(rules, loaded...)
 	}
-	return nil
+	r.good = good
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].URL < statuses[j].URL })
+	r.files.Store(statuses)
+	if r.onReload != nil {
+		r.onReload(statuses)
+	}
+	return rules, nil
+}
+
+func (r *Ruleset) statusByURL() map[string]*config.RuleFileStatus {
+	var result = make(map[string]*config.RuleFileStatus)
+	for _, status := range r.Status() {
+		result[status.URL] = status
+	}
+	return result
+}
+
+//cloneRules returns rules shallow copies, so that rules published with previous snapshot are not modified
+func cloneRules(rules []*Rule) []*Rule {
+	var result = make([]*Rule, 0, len(rules))
+	for _, rule := range rules {
+		clone := *rule
+		result = append(result, &clone)
+	}
+	return result
 }
 
-func (r *Ruleset) loadResources(ctx context.Context, storage afs.Service, object storage.Object) error {
+func (r *Ruleset) loadResources(ctx context.Context, storage afs.Service, object storage.Object) ([]*Rule, error) {
 	reader, err := storage.Open(ctx, object)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		_ = reader.Close()
 	}()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read: %v", object.URL())
+	}
+	if data, err = config.DecryptRuleFile(ctx, data, path.Ext(object.Name()), r.EncryptionKey, r.decrypter); err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt: %v", object.URL())
+	}
 	resources := make([]*Rule, 0)
-	err = json.NewDecoder(reader).Decode(&resources)
+	err = json.Unmarshal(data, &resources)
 	if err != nil {
-		return errors.Wrapf(err, "failed to decode: %v", object.URL())
+		return nil, errors.Wrapf(err, "failed to decode: %v", object.URL())
 	}
 	for i := range resources {
 		if resources[i].Source.URL == "" {
-			return fmt.Errorf("source.url was empty: %v", object.URL())
+			return nil, fmt.Errorf("source.url was empty: %v", object.URL())
 		}
-		if resources[i].Dest.URL == "" {
-			return fmt.Errorf("dest.url was empty: %v", object.URL())
+		if resources[i].Dest.URL == "" && resources[i].Compaction == nil {
+			return nil, fmt.Errorf("dest.url was empty: %v", object.URL())
 		}
-	}
-	r.Rules = append(r.Rules, resources...)
-	return err
-}
-
-func (r *Ruleset) initRules() {
-	if atomic.CompareAndSwapInt32(&r.inited, 0, 1) {
-		if len(r.Rules) > 0 {
-			r.initialRules = r.Rules
-		} else {
-			r.initialRules = make([]*Rule, 0)
+		if glob := resources[i].Source.Glob; glob != "" {
+			if _, err := config.CompileGlob(glob); err != nil {
+				return nil, errors.Wrapf(err, "invalid rule: %v", object.URL())
+			}
+		}
+		if listing := resources[i].Listing; listing != nil {
+			if err := listing.Validate(); err != nil {
+				return nil, errors.Wrapf(err, "invalid rule: %v", object.URL())
+			}
+		}
+		if compaction := resources[i].Compaction; compaction != nil {
+			if err := compaction.Validate(); err != nil {
+				return nil, errors.Wrapf(err, "invalid rule: %v", object.URL())
+			}
 		}
 	}
+	return resources, nil
 }
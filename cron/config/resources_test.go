@@ -0,0 +1,75 @@
+package config
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRuleset_ReloadIfNeeded(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/cron/rules"
+	ruleURL := baseURL + "/rule1.json"
+	_ = fs.Delete(ctx, baseURL)
+	err := fs.Upload(ctx, ruleURL, 0644, strings.NewReader(`[{"Source":{"URL":"mem://localhost/data1"},"Dest":{"URL":"mem://localhost/dest1"}}]`))
+	if !assert.Nil(t, err) {
+		return
+	}
+	var prepared [][]*Rule
+	ruleset := &Ruleset{
+		BaseURL:   baseURL,
+		CheckInMs: 1,
+		Rules:     []*Rule{{Source: config.Resource{URL: "mem://localhost/data0"}, Dest: config.Resource{URL: "mem://localhost/dest0"}}},
+	}
+	ruleset.OnLoad(func(ctx context.Context, rules []*Rule) error {
+		prepared = append(prepared, rules)
+		return nil
+	})
+	if !assert.Nil(t, ruleset.Init(ctx, fs, "")) {
+		return
+	}
+	snapshot := ruleset.Snapshot()
+	assert.EqualValues(t, 2, len(snapshot))
+	_, err = ruleset.ReloadIfNeeded(ctx, fs)
+	assert.Nil(t, err)
+
+	err = fs.Upload(ctx, baseURL+"/rule2.json", 0644, strings.NewReader(`[{"Source":{"URL":"mem://localhost/data2"},"Dest":{"URL":"mem://localhost/dest2"}}]`))
+	if !assert.Nil(t, err) {
+		return
+	}
+	time.Sleep(2 * time.Millisecond)
+	changed, err := ruleset.ReloadIfNeeded(ctx, fs)
+	assert.Nil(t, err)
+	assert.True(t, changed)
+
+	reloaded := ruleset.Snapshot()
+	assert.EqualValues(t, 3, len(reloaded), "reloaded snapshot")
+	assert.EqualValues(t, 2, len(snapshot), "previous snapshot is not modified")
+	assert.EqualValues(t, "mem://localhost/data1", snapshot[1].Source.URL)
+	assert.True(t, reloaded[0] != snapshot[0], "initial rules are copied for each snapshot")
+	assert.EqualValues(t, 1, len(ruleset.Rules), "initial rules are not modified")
+	assert.EqualValues(t, reloaded, prepared[len(prepared)-1], "rules are prepared before publishing")
+
+	err = fs.Upload(ctx, ruleURL, 0644, strings.NewReader(`[{"Source":{"URL":`))
+	if !assert.Nil(t, err) {
+		return
+	}
+	time.Sleep(2 * time.Millisecond)
+	changed, err = ruleset.ReloadIfNeeded(ctx, fs)
+	assert.Nil(t, err, "broken file does not abort reload")
+	assert.True(t, changed)
+	assert.EqualValues(t, 3, len(ruleset.Snapshot()), "previous good version is kept active")
+	statuses := ruleset.Status()
+	if assert.EqualValues(t, 2, len(statuses)) {
+		assert.EqualValues(t, ruleURL, statuses[0].URL)
+		assert.EqualValues(t, base.StatusError, statuses[0].Status)
+		assert.True(t, statuses[0].Stale)
+		assert.EqualValues(t, base.StatusOK, statuses[1].Status)
+	}
+}
@@ -1,14 +1,21 @@
 package config
 
 import (
+	"github.com/viant/smirror/base"
 	"fmt"
 	"time"
 )
 
-//TimeWindow represents resource asset last modification loopback time window
+//defaultOverlap default listing overlap with last successful scan, it covers clock skew and late modification time updates
+const defaultOverlap = time.Minute
+
+//TimeWindow represents resource asset last modification loopback time window, used until rule source has successful scan watermark
 type TimeWindow struct {
 	Duration      time.Duration
-	DurationInSec int
+	DurationInSec base.Seconds
+	//Overlap listing overlap before last successful scan watermark, default 1 min (not more than Duration)
+	Overlap      time.Duration `json:",omitempty"`
+	OverlapInSec base.Seconds  `json:",omitempty"`
 }
 
 //Load initialises time window
@@ -16,6 +23,15 @@ func (t *TimeWindow) Init() {
 	if t.DurationInSec != 0 {
 		t.Duration = time.Duration(t.DurationInSec) * time.Second
 	}
+	if t.OverlapInSec != 0 {
+		t.Overlap = time.Duration(t.OverlapInSec) * time.Second
+	}
+	if t.Overlap == 0 {
+		t.Overlap = defaultOverlap
+		if t.Duration > 0 && t.Duration < t.Overlap {
+			t.Overlap = t.Duration
+		}
+	}
 }
 
 //Validate checks if setting is valid
@@ -23,5 +39,8 @@ func (t *TimeWindow) Validate() error {
 	if t.Duration == 0 {
 		return fmt.Errorf("time duration was empty")
 	}
+	if t.Overlap < 0 {
+		return fmt.Errorf("time window overlap was negative")
+	}
 	return nil
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/viant/afs"
 	"github.com/viant/afs/storage"
+	"sync"
 	"time"
 )
 
@@ -17,11 +18,29 @@ type Service interface {
 
 	//AddProcessed add processed resources
 	AddProcessed(ctx context.Context, processed []storage.Object) error
+
+	//Cursor returns change listing cursor for supplied source URL
+	Cursor(ctx context.Context, sourceURL string) (string, error)
+
+	//SetCursor stores change listing cursor for supplied source URL
+	SetCursor(ctx context.Context, sourceURL, cursor string) error
+
+	//Watermark returns last successful scan time for supplied source URL, zero time if source was not scanned yet
+	Watermark(ctx context.Context, sourceURL string) (time.Time, error)
+
+	//SetWatermark stores last successful scan time for supplied source URL
+	SetWatermark(ctx context.Context, sourceURL string, watermark time.Time) error
+
+	//Stats returns last loaded or stored state size
+	Stats() *Stats
 }
 
 type service struct {
 	metaURL       string
 	pruneDuration time.Duration
+	overlap       time.Duration
+	mux           sync.Mutex
+	stats         Stats
 	afs.Service
 }
 
@@ -31,11 +50,13 @@ func (s *service) loadState(ctx context.Context) (*State, error) {
 	if !has {
 		return state, nil
 	}
-	reader, err := s.OpenURL(ctx, s.metaURL)
+	data, err := s.DownloadWithURL(ctx, s.metaURL)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to load meta file: %v", s.metaURL)
 	}
-	err = json.NewDecoder(reader).Decode(state)
+	if err = json.Unmarshal(data, state); err == nil {
+		s.updateStats(state, len(data))
+	}
 	return state, err
 }
 
@@ -45,13 +66,44 @@ func (s *service) storeState(ctx context.Context, state *State) error {
 	if err != nil {
 		err = errors.Wrapf(err, "failed to encode meta: %v with %v", s.metaURL, state)
 	}
+	size := buffer.Len()
 	err = s.Upload(ctx, s.metaURL, 0644, buffer)
 	if err != nil {
 		err = errors.Wrapf(err, "failed to upload meta: %v", s.metaURL)
 	}
+	if err == nil {
+		s.updateStats(state, size)
+	}
 	return err
 }
 
+//compactState prunes and compacts processed resources before state is stored
+func (s *service) compactState(ctx context.Context, state *State) error {
+	entries := len(state.Processed)
+	state.Prune(time.Now(), s.pruneDuration)
+	state.Compact(s.overlap)
+	s.mux.Lock()
+	s.stats.Removed = entries - len(state.Processed)
+	s.mux.Unlock()
+	return s.storeState(ctx, state)
+}
+
+func (s *service) updateStats(state *State, size int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.stats.Entries = len(state.Processed)
+	s.stats.Bytes = size
+}
+
+//Stats returns last loaded or stored state size
+func (s *service) Stats() *Stats {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	stats := s.stats
+	stats.URL = s.metaURL
+	return &stats
+}
+
 //PendingResources filters pending resources for supplied candidate with processed resources
 func (s *service) PendingResources(ctx context.Context, candidates []storage.Object) ([]storage.Object, error) {
 	state, err := s.loadState(ctx)
@@ -76,15 +128,59 @@ func (s *service) AddProcessed(ctx context.Context, processed []storage.Object)
 		return errors.Wrapf(err, "failed to load meta state")
 	}
 	state.Add(processed...)
-	state.Prune(time.Now(), s.pruneDuration)
+	return s.compactState(ctx, state)
+}
+
+//Cursor returns change listing cursor for supplied source URL
+func (s *service) Cursor(ctx context.Context, sourceURL string) (string, error) {
+	state, err := s.loadState(ctx)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to load meta state")
+	}
+	return state.Cursors[sourceURL], nil
+}
+
+//SetCursor stores change listing cursor for supplied source URL
+func (s *service) SetCursor(ctx context.Context, sourceURL, cursor string) error {
+	state, err := s.loadState(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load meta state")
+	}
+	if state.Cursors == nil {
+		state.Cursors = make(map[string]string)
+	}
+	state.Cursors[sourceURL] = cursor
 	return s.storeState(ctx, state)
 }
 
-//New creates a new service
-func New(metaURL string, pruneDuration time.Duration, fs afs.Service) Service {
+//Watermark returns last successful scan time for supplied source URL
+func (s *service) Watermark(ctx context.Context, sourceURL string) (time.Time, error) {
+	state, err := s.loadState(ctx)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "failed to load meta state")
+	}
+	return state.Watermarks[sourceURL], nil
+}
+
+//SetWatermark stores last successful scan time for supplied source URL
+func (s *service) SetWatermark(ctx context.Context, sourceURL string, watermark time.Time) error {
+	state, err := s.loadState(ctx)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load meta state")
+	}
+	if state.Watermarks == nil {
+		state.Watermarks = make(map[string]time.Time)
+	}
+	state.Watermarks[sourceURL] = watermark
+	return s.compactState(ctx, state)
+}
+
+//New creates a new service, processed resources are pruned after prune duration, or compacted once they are older than source watermark minus overlap
+func New(metaURL string, pruneDuration, overlap time.Duration, fs afs.Service) Service {
 	return &service{
 		metaURL:       metaURL,
 		pruneDuration: pruneDuration,
+		overlap:       overlap,
 		Service:       fs,
 	}
 }
@@ -63,7 +63,7 @@ func TestService_PendingResources(t *testing.T) {
 		if useCase.baseURL == "" {
 			useCase.baseURL = fmt.Sprintf("mem://localhost/case%04d", i)
 		}
-		service := New(url.Join(useCase.baseURL, "/meta.json"), 0, fs)
+		service := New(url.Join(useCase.baseURL, "/meta.json"), 0, 0, fs)
 		processed := GetTestObjects(useCase.baseURL, useCase.processedSoFar)
 		err := service.AddProcessed(ctx, processed)
 		assert.Nil(t, err, useCase.description)
@@ -82,3 +82,44 @@ func TestService_PendingResources(t *testing.T) {
 		}
 	}
 }
+
+func TestService_Cursor(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/cursor"
+	service := New(url.Join(baseURL, "/meta.json"), time.Minute, time.Minute, fs)
+	cursor, err := service.Cursor(ctx, "dropbox://partners/inbound")
+	assert.Nil(t, err)
+	assert.Equal(t, "", cursor)
+	assert.Nil(t, service.SetCursor(ctx, "dropbox://partners/inbound", "AAF3"))
+	assert.Nil(t, service.AddProcessed(ctx, GetTestObjects(baseURL, map[string]time.Time{"f1": time.Now()})))
+	cursor, err = service.Cursor(ctx, "dropbox://partners/inbound")
+	assert.Nil(t, err)
+	assert.Equal(t, "AAF3", cursor)
+}
+
+func TestService_Watermark(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/watermark"
+	_ = fs.Delete(ctx, baseURL)
+	service := New(url.Join(baseURL, "/meta.json"), time.Minute, time.Minute, fs)
+	watermark, err := service.Watermark(ctx, "gs://bucket/inbound")
+	assert.Nil(t, err)
+	assert.True(t, watermark.IsZero())
+	scanTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	assert.Nil(t, service.SetWatermark(ctx, "gs://bucket/inbound", scanTime))
+	assert.Nil(t, service.AddProcessed(ctx, GetTestObjects(baseURL, map[string]time.Time{"f1": scanTime.Add(-30 * time.Second)})))
+	watermark, err = service.Watermark(ctx, "gs://bucket/inbound")
+	assert.Nil(t, err)
+	assert.True(t, scanTime.Equal(watermark))
+	pending, err := service.PendingResources(ctx, GetTestObjects(baseURL, map[string]time.Time{"f1": scanTime.Add(-30 * time.Second)}))
+	assert.Nil(t, err)
+	assert.EqualValues(t, 0, len(pending), "processed resources older than prune duration, but not older than watermark are kept")
+	assert.Nil(t, service.SetWatermark(ctx, baseURL, time.Now()))
+	stats := service.Stats()
+	assert.EqualValues(t, url.Join(baseURL, "/meta.json"), stats.URL)
+	assert.EqualValues(t, 0, stats.Entries, "resources older than watermark are compacted")
+	assert.EqualValues(t, 1, stats.Removed)
+	assert.True(t, stats.Bytes > 0)
+}
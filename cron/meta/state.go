@@ -2,12 +2,17 @@ package meta
 
 import (
 	"github.com/viant/afs/storage"
+	"strings"
 	"time"
 )
 
 //State meta files storing processed resources
 type State struct {
 	Processed []*Processed
+	//Cursors change listing cursors keyed by source URL
+	Cursors map[string]string `json:",omitempty"`
+	//Watermarks last successful scan time keyed by source URL
+	Watermarks map[string]time.Time `json:",omitempty"`
 }
 
 //Add adds storage object to processed resources
@@ -20,11 +25,17 @@ func (s *State) Add(objects ...storage.Object) {
 	}
 }
 
-//Prune removes any resourced older than supplied max age
+//Prune removes any resourced older than supplied max age, age is relative to the oldest watermark if it is before now,
+//so that resources listed again from watermark are still known as processed
 func (s *State) Prune(now time.Time, maxAge time.Duration) {
 	if maxAge <= 0 {
 		return
 	}
+	for _, watermark := range s.Watermarks {
+		if watermark.Before(now) {
+			now = watermark
+		}
+	}
 	var survivors = make([]*Processed, 0)
 	for i := range s.Processed {
 		age := now.Sub(s.Processed[i].Modified)
@@ -36,6 +47,38 @@ func (s *State) Prune(now time.Time, maxAge time.Duration) {
 	s.Processed = survivors
 }
 
+//Compact removes resources modified before their source watermark minus overlap, these are no longer listed,
+//it returns number of removed resources
+func (s *State) Compact(overlap time.Duration) int {
+	if len(s.Watermarks) == 0 || len(s.Processed) == 0 {
+		return 0
+	}
+	var survivors = make([]*Processed, 0, len(s.Processed))
+	for i := range s.Processed {
+		watermark := s.watermark(s.Processed[i].URL)
+		if !watermark.IsZero() && s.Processed[i].Modified.Before(watermark.Add(-overlap)) {
+			continue
+		}
+		survivors = append(survivors, s.Processed[i])
+	}
+	removed := len(s.Processed) - len(survivors)
+	s.Processed = survivors
+	return removed
+}
+
+//watermark returns watermark of the longest source URL matching resource URL
+func (s *State) watermark(URL string) time.Time {
+	var result time.Time
+	matched := 0
+	for sourceURL, watermark := range s.Watermarks {
+		if len(sourceURL) > matched && strings.HasPrefix(URL, sourceURL) {
+			result = watermark
+			matched = len(sourceURL)
+		}
+	}
+	return result
+}
+
 //ProcessMap returns processed resource map
 func (s *State) ProcessMap() map[string]time.Time {
 	var result = make(map[string]time.Time)
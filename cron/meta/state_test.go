@@ -64,3 +64,53 @@ func TestState_Prune(t *testing.T) {
 		}
 	}
 }
+
+func TestState_Compact(t *testing.T) {
+	var now = time.Now()
+	var useCases = []struct {
+		description string
+		watermarks  map[string]time.Time
+		objects     map[string]time.Time
+		expect      []string
+	}{
+		{
+			description: "no watermark",
+			objects: map[string]time.Time{
+				"f1": now.Add(-time.Hour),
+			},
+			expect: []string{"f1"},
+		},
+		{
+			description: "compacted before watermark minus overlap",
+			watermarks:  map[string]time.Time{"mem://localhost/": now},
+			objects: map[string]time.Time{
+				"f1": now.Add(-time.Hour),
+				"f2": now.Add(-30 * time.Second),
+				"f3": now.Add(-2 * time.Minute),
+			},
+			expect: []string{"f2"},
+		},
+		{
+			description: "other source watermark",
+			watermarks:  map[string]time.Time{"mem://localhost/other/": now},
+			objects: map[string]time.Time{
+				"f1": now.Add(-time.Hour),
+			},
+			expect: []string{"f1"},
+		},
+	}
+
+	for i, useCase := range useCases {
+		state := &State{Watermarks: useCase.watermarks}
+		baseURL := fmt.Sprintf("mem://localhost/compact%04d", i)
+		state.Add(GetTestObjects(baseURL, useCase.objects)...)
+		removed := state.Compact(time.Minute)
+		assert.EqualValues(t, len(useCase.objects)-len(useCase.expect), removed, useCase.description)
+		resources := state.ProcessMap()
+		assert.EqualValues(t, len(useCase.expect), len(resources), useCase.description)
+		for _, name := range useCase.expect {
+			_, ok := resources[url.Join(baseURL, name)]
+			assert.True(t, ok, useCase.description+" / "+name)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package meta
+
+//Stats represents processed state size, it is reported with every tick to keep meta growth visible
+type Stats struct {
+	URL string
+	//Entries number of processed resources kept in state
+	Entries int
+	//Bytes encoded state size
+	Bytes int
+	//Removed number of processed resources removed by the last prune and compaction
+	Removed int `json:",omitempty"`
+}
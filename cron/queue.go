@@ -0,0 +1,245 @@
+package cron
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/twmb/murmur3"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/object"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/cron/config"
+	"path"
+	"strings"
+	"time"
+)
+
+//Queued represents queued resource
+type Queued struct {
+	URL      string
+	Modified time.Time
+	Size     int64
+	//Failures number of failed processing attempts
+	Failures int `json:",omitempty"`
+}
+
+//QueueState represents rule persisted work queue, queued resources are drained in FIFO order
+type QueueState struct {
+	Rule string
+	//Queued resources waiting to be processed
+	Queued []*Queued `json:",omitempty"`
+	//DeadLettered resources that failed processing Queue.MaxFailures times, they are no longer retried
+	DeadLettered []*Queued `json:",omitempty"`
+	//ClaimedAt last time queued resources were claimed, the least recently claimed rule is served first
+	ClaimedAt time.Time
+}
+
+//QueueStats represents rule queue stats
+type QueueStats struct {
+	Rule         string
+	Added        int `json:",omitempty"`
+	Claimed      int `json:",omitempty"`
+	Failed       int `json:",omitempty"`
+	DeadLettered int `json:",omitempty"`
+	Queued       int
+}
+
+//ruleQueue represents loaded rule queue
+type ruleQueue struct {
+	rule  *config.Rule
+	URL   string
+	state *QueueState
+	stats *QueueStats
+}
+
+//enqueue appends resources that are not yet queued, queued resource with changed modification time is updated in place
+func (q *ruleQueue) enqueue(objects []storage.Object) {
+	index := make(map[string]int, len(q.state.Queued))
+	for i, queued := range q.state.Queued {
+		index[queued.URL] = i
+	}
+	for _, candidate := range objects {
+		if i, ok := index[candidate.URL()]; ok {
+			q.state.Queued[i].Modified = candidate.ModTime()
+			q.state.Queued[i].Size = candidate.Size()
+			continue
+		}
+		index[candidate.URL()] = len(q.state.Queued)
+		q.state.Queued = append(q.state.Queued, &Queued{URL: candidate.URL(), Modified: candidate.ModTime(), Size: candidate.Size()})
+		q.stats.Added++
+	}
+	q.stats.Queued = len(q.state.Queued)
+}
+
+//claim returns the oldest queued resources
+func (q *ruleQueue) claim(count int) []storage.Object {
+	var result = make([]storage.Object, 0, count)
+	for _, queued := range q.state.Queued[:count] {
+		_, name := url.Split(queued.URL, file.Scheme)
+		result = append(result, object.New(queued.URL, file.NewInfo(name, queued.Size, 0644, queued.Modified, false), nil))
+	}
+	return result
+}
+
+//complete removes claimed resources from queue, failed resources are queued again at the end of queue
+//unless they failed maxFailures times, in which case they are dead-lettered
+func (q *ruleQueue) complete(count int, failed []bool, maxFailures int, claimedAt time.Time) {
+	claimed := q.state.Queued[:count]
+	remaining := q.state.Queued[count:]
+	var retried = make([]*Queued, 0)
+	for i, queued := range claimed {
+		if !failed[i] {
+			q.stats.Claimed++
+			continue
+		}
+		q.stats.Failed++
+		queued.Failures++
+		if maxFailures > 0 && queued.Failures >= maxFailures {
+			q.state.DeadLettered = append(q.state.DeadLettered, queued)
+			q.stats.DeadLettered++
+			continue
+		}
+		retried = append(retried, queued)
+	}
+	q.state.Queued = append(append(make([]*Queued, 0, len(remaining)+len(retried)), remaining...), retried...)
+	q.state.ClaimedAt = claimedAt
+	q.stats.Queued = len(q.state.Queued)
+}
+
+//queueURL returns rule queue state URL
+func (s *service) queueURL(rule *config.Rule) string {
+	baseURL := s.config.Queue.BaseURL
+	if baseURL == "" {
+		baseURL = url.Join(strings.TrimSuffix(s.config.MetaURL, path.Ext(s.config.MetaURL)), "queue")
+	}
+	return url.Join(baseURL, fmt.Sprintf("%x.json", murmur3.Sum64([]byte(ruleKey(rule)))))
+}
+
+//loadQueue loads rule queue state
+func (s *service) loadQueue(ctx context.Context, rule *config.Rule) (*ruleQueue, error) {
+	key := ruleKey(rule)
+	result := &ruleQueue{rule: rule, URL: s.queueURL(rule), state: &QueueState{Rule: key}, stats: &QueueStats{Rule: key}}
+	if has, _ := s.fs.Exists(ctx, result.URL); !has {
+		return result, nil
+	}
+	data, err := s.fs.DownloadWithURL(ctx, result.URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load queue: %v", result.URL)
+	}
+	if err = json.Unmarshal(data, result.state); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode queue: %v", result.URL)
+	}
+	result.stats.Queued = len(result.state.Queued)
+	return result, nil
+}
+
+//storeQueue stores rule queue state
+func (s *service) storeQueue(ctx context.Context, queue *ruleQueue) error {
+	buffer := new(bytes.Buffer)
+	if err := json.NewEncoder(buffer).Encode(queue.state); err != nil {
+		return errors.Wrapf(err, "failed to encode queue: %v", queue.URL)
+	}
+	if err := s.fs.Upload(ctx, queue.URL, 0644, buffer); err != nil {
+		return errors.Wrapf(err, "failed to upload queue: %v", queue.URL)
+	}
+	return nil
+}
+
+//enqueueResource queues rule pending resources, once queued, listing cursor and watermark advance
+func (s *service) enqueueResource(ctx context.Context, rule *config.Rule) (*ruleQueue, error) {
+	scanTime := time.Now()
+	objects, cursor, err := s.getResourceCandidates(ctx, rule)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get resource candidate %v", rule.Source.URL)
+	}
+	pending, err := s.ruleMeta(rule).PendingResources(ctx, objects)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read pending resource %v", len(objects))
+	}
+	if rule.Listing != nil {
+		//queue keeps listing order, MaxResults is replaced by queue batch size
+		listing := *rule.Listing
+		listing.MaxResults = 0
+		pending, _ = listing.Limit(pending)
+	}
+	queue, err := s.loadQueue(ctx, rule)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 {
+		queue.enqueue(pending)
+		if err = s.storeQueue(ctx, queue); err != nil {
+			return nil, err
+		}
+	}
+	if cursor != "" {
+		if err = s.ruleMeta(rule).SetCursor(ctx, rule.Source.URL, cursor); err != nil {
+			return nil, errors.Wrapf(err, "failed to update cursor")
+		}
+	}
+	if !hasChangeLister(rule) {
+		if err = s.ruleMeta(rule).SetWatermark(ctx, rule.Source.URL, scanTime); err != nil {
+			return nil, errors.Wrapf(err, "failed to update watermark")
+		}
+	}
+	return queue, nil
+}
+
+//drainQueues claims queued resources with bounded batch across rules and processes them
+func (s *service) drainQueues(ctx context.Context, queues []*ruleQueue, response *Response) error {
+	backlogs := make([]int, len(queues))
+	claimedAt := make([]time.Time, len(queues))
+	for i, queue := range queues {
+		backlogs[i] = len(queue.state.Queued)
+		claimedAt[i] = queue.state.ClaimedAt
+	}
+	claims := s.config.Queue.Allocate(backlogs, claimedAt)
+	var messages = make([]string, 0)
+	for i, queue := range queues {
+		if claims[i] > 0 {
+			if err := s.drainQueue(ctx, queue, claims[i], response); err != nil {
+				messages = append(messages, err.Error())
+			}
+		}
+		response.Queues = append(response.Queues, queue.stats)
+	}
+	if len(messages) > 0 {
+		return errors.New(strings.Join(messages, "; "))
+	}
+	return nil
+}
+
+//drainQueue processes claimed resources, failed resources are requeued till they are dead-lettered
+func (s *service) drainQueue(ctx context.Context, queue *ruleQueue, count int, response *Response) error {
+	claimed := queue.claim(count)
+	errs := s.notifyEach(ctx, queue.rule, claimed, response)
+	var processed = make([]storage.Object, 0, len(claimed))
+	var failed = make([]bool, len(claimed))
+	var messages = make([]string, 0)
+	for i, err := range errs {
+		if err != nil {
+			failed[i] = true
+			messages = append(messages, fmt.Sprintf("%v: %v", claimed[i].URL(), err))
+			continue
+		}
+		processed = append(processed, claimed[i])
+	}
+	if err := s.ruleMeta(queue.rule).AddProcessed(ctx, processed); err != nil {
+		return errors.Wrapf(err, "failed to update processed")
+	}
+	queue.complete(count, failed, s.config.Queue.MaxFailures, time.Now())
+	if err := s.storeQueue(ctx, queue); err != nil {
+		return err
+	}
+	response.AddMeta(s.ruleMeta(queue.rule).Stats())
+	matched := &Matched{Resource: queue.rule, URLs: make([]string, 0)}
+	matched.Add(processed...)
+	response.AddMatched(matched)
+	if len(messages) > 0 {
+		return errors.Errorf("failed to process %v queued resource(s) of %v: %v", len(messages), queue.state.Rule, strings.Join(messages, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afsc/gs"
+	cfg "github.com/viant/smirror/config"
+	"github.com/viant/smirror/cron/config"
+	"github.com/viant/smirror/cron/meta"
+	"github.com/viant/smirror/native"
+	"github.com/viant/smirror/secret"
+	goption "google.golang.org/api/option"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//requesterPaysSecret returns fake gs endpoint storage options with resource requester pays billing
+type requesterPaysSecret struct {
+	secret.Service
+	endpoint string
+}
+
+func (s *requesterPaysSecret) StorageOpts(ctx context.Context, resource *cfg.Resource) ([]storage.Option, error) {
+	return []storage.Option{
+		gs.NewClientOptions(goption.WithEndpoint(s.endpoint+"/storage/v1/"), goption.WithoutAuthentication()),
+		cfg.NewRequesterPays(resource.RequesterPays, resource.ProjectID),
+	}, nil
+}
+
+//listRecorder serves gs object listing and records listing queries
+type listRecorder struct {
+	mux     sync.Mutex
+	queries []url.Values
+}
+
+func (r *listRecorder) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet || !strings.HasSuffix(request.URL.Path, "/b/bucket/o") {
+		writer.WriteHeader(http.StatusNotFound)
+		return
+	}
+	r.mux.Lock()
+	r.queries = append(r.queries, request.URL.Query())
+	r.mux.Unlock()
+	updated := time.Now().UTC().Format(time.RFC3339)
+	writer.Header().Set("Content-Type", "application/json")
+	switch request.URL.Query().Get("prefix") {
+	case "data/":
+		_, _ = fmt.Fprintf(writer, `{"prefixes":["data/sub/"],"items":[{"name":"data/f1.csv","bucket":"bucket","size":"3","updated":%q}]}`, updated)
+	default:
+		_, _ = fmt.Fprintf(writer, `{"items":[{"name":"data/sub/f2.csv","bucket":"bucket","size":"3","updated":%q}]}`, updated)
+	}
+}
+
+func TestService_GetResourceCandidatesRequesterPays(t *testing.T) {
+	ctx := context.Background()
+	recorder := &listRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+	fs := afs.New()
+	srv := &service{
+		config:      &Config{TimeWindow: config.TimeWindow{Duration: time.Hour}},
+		fs:          native.New(fs),
+		secret:      &requesterPaysSecret{endpoint: server.URL},
+		metaService: meta.New("mem://localhost/requesterpays/meta.json", 2*time.Hour, time.Minute, fs),
+	}
+	rule := &config.Rule{Source: cfg.Resource{URL: "gs://bucket/data/", RequesterPays: true, ProjectID: "billing-project"}}
+	objects, _, err := srv.getResourceCandidates(ctx, rule)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var URLs []string
+	for _, object := range objects {
+		URLs = append(URLs, object.URL())
+	}
+	assert.EqualValues(t, []string{"gs://bucket/data/sub/f2.csv", "gs://bucket/data/f1.csv"}, URLs)
+	if assert.EqualValues(t, 2, len(recorder.queries)) {
+		for _, query := range recorder.queries {
+			assert.EqualValues(t, "billing-project", query.Get("userProject"), query.Get("prefix"))
+		}
+	}
+}
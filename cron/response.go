@@ -2,6 +2,7 @@ package cron
 
 import (
 	"github.com/viant/smirror/cron/config"
+	"github.com/viant/smirror/cron/meta"
 	"github.com/viant/smirror/proxy"
 	"github.com/viant/afs/storage"
 )
@@ -10,6 +11,19 @@ import (
 type Response struct {
 	*proxy.Response
 	Matched []*Matched `json:",omitempty"`
+	//LogError response log persistence error
+	LogError string `json:",omitempty"`
+	//Shard replica shard assignment, with sharding enabled
+	Shard *Shard `json:",omitempty"`
+	//Meta processed state size per meta file
+	Meta []*meta.Stats `json:",omitempty"`
+	//Compacted compaction manifests
+	Compacted []*config.CompactionManifest `json:",omitempty"`
+	//Queues rule work queue stats, with persisted queue
+	Queues []*QueueStats `json:",omitempty"`
+	//Streamed number of matched URLs streamed as NDJSON records
+	Streamed int `json:",omitempty"`
+	emit func(matched *Matched)
 }
 
 type Matched struct {
@@ -23,6 +37,27 @@ func (m *Matched) Add(objects ...storage.Object) {
 	}
 }
 
+//AddMatched adds matched rule resources, streamed response emits them instead
+func (r *Response) AddMatched(matched *Matched) {
+	if r.emit != nil {
+		r.Streamed += len(matched.URLs)
+		r.emit(matched)
+		return
+	}
+	r.Matched = append(r.Matched, matched)
+}
+
+//AddMeta adds meta file stats unless they were already added
+func (r *Response) AddMeta(stats *meta.Stats) {
+	for _, candidate := range r.Meta {
+		if candidate.URL == stats.URL {
+			*candidate = *stats
+			return
+		}
+	}
+	r.Meta = append(r.Meta, stats)
+}
+
 //NewResponse create a response
 func NewResponse(baseResponse *proxy.Response) *Response {
 	return &Response{
@@ -7,21 +7,32 @@ import (
 	cfg "github.com/viant/smirror/config"
 	"github.com/viant/smirror/cron/config"
 	"github.com/viant/smirror/cron/meta"
+	"github.com/viant/smirror/native"
 	"github.com/viant/smirror/proxy"
 	"github.com/viant/smirror/secret"
+	"github.com/viant/smirror/shared"
 	"github.com/viant/afs"
 	"github.com/viant/afs/file"
 	"github.com/viant/afs/matcher"
+	"github.com/viant/afs/option"
 	"github.com/viant/afs/storage"
 	"github.com/viant/afs/url"
+	"io"
 	"path"
+	"strings"
 	"sync"
 	"time"
 )
 
+const responseLogPartition = "cron"
+
 //Service represents a cron service
 type Service interface {
 	Tick(ctx context.Context) *Response
+	//TickStream runs tick and streams matched results to writer as NDJSON, it returns summary response
+	TickStream(ctx context.Context, writer io.Writer) *Response
+	//Pending returns pending resources without triggering them
+	Pending(ctx context.Context) *Response
 }
 
 type service struct {
@@ -30,12 +41,48 @@ type service struct {
 	proxy       proxy.Service
 	secret      secret.Service
 	metaService meta.Service
+	mux         sync.Mutex
+	ruleMetas   map[string]meta.Service
+	compactedAt map[string]time.Time
 }
 
 //Tick run cron service
 func (s *service) Tick(ctx context.Context) *Response {
 	response := NewResponse(proxy.NewResponse())
+	s.run(ctx, response)
+	return response
+}
+
+//TickStream runs cron service, matched results are written as NDJSON records instead of being kept in response
+func (s *service) TickStream(ctx context.Context, writer io.Writer) *Response {
+	stream := newTickStream(writer)
+	response := NewResponse(proxy.NewResponse())
+	response.emit = stream.emitMatched(response)
+	done := stream.keepAlive(s.config.StreamFlushSec.Duration())
+	s.run(ctx, response)
+	close(done)
+	stream.write(&StreamRecord{Summary: response})
+	return response
+}
+
+//run runs tick and persists response log
+func (s *service) run(ctx context.Context, response *Response) {
 	err := s.tick(ctx, response)
+	if err != nil {
+		response.Status = base.StatusError
+		response.Error = shared.Redact(err.Error())
+	}
+	if responseLog := s.config.ResponseLog; responseLog != nil {
+		if _, err = responseLog.Persist(ctx, s.fs, responseLogPartition, response.Status, "", response); err != nil {
+			response.LogError = shared.Redact(err.Error())
+		}
+	}
+}
+
+//Pending returns pending resources
+func (s *service) Pending(ctx context.Context) *Response {
+	response := NewResponse(proxy.NewResponse())
+	err := s.pending(ctx, response)
 	if err != nil {
 		response.Status = base.StatusError
 		response.Error = err.Error()
@@ -43,20 +90,69 @@ func (s *service) Tick(ctx context.Context) *Response {
 	return response
 }
 
+func (s *service) pending(ctx context.Context, response *Response) error {
+	if _, err := s.config.Resources.ReloadIfNeeded(ctx, s.fs); err != nil {
+		return err
+	}
+	owned, err := s.shardFilter(ctx, response)
+	if err != nil {
+		return err
+	}
+	for _, resource := range s.config.Resources.Snapshot() {
+		if !owned(resource) || resource.Compaction != nil {
+			continue
+		}
+		pending, _, _, err := s.pendingResources(ctx, resource)
+		if err != nil {
+			return err
+		}
+		if len(pending) > 0 {
+			matched := &Matched{
+				Resource: resource,
+				URLs:     make([]string, 0),
+			}
+			matched.Add(pending...)
+			response.Matched = append(response.Matched, matched)
+		}
+	}
+	return nil
+}
+
 func (s *service) tick(ctx context.Context, response *Response) error {
-	changed, err := s.config.Resources.ReloadIfNeeded(ctx, s.fs)
-	if changed && err == nil {
-		err = s.UpdateSecrets(ctx)
+	if _, err := s.config.Resources.ReloadIfNeeded(ctx, s.fs); err != nil {
+		return err
 	}
+	owned, err := s.shardFilter(ctx, response)
 	if err != nil {
 		return err
 	}
+	//snapshot gives consistent rules view for the whole tick, even if other tick reloads rules
+	rules := s.config.Resources.Snapshot()
 	var matched = make([]storage.Object, 0)
-	for _, resource := range s.config.Resources.Rules {
+	var queues []*ruleQueue
+	for _, resource := range rules {
+		if !owned(resource) {
+			continue
+		}
+		if resource.Compaction != nil {
+			if err = s.compact(ctx, resource, response); err != nil {
+				return err
+			}
+			continue
+		}
+		if s.config.Queue != nil {
+			queue, err := s.enqueueResource(ctx, resource)
+			if err != nil {
+				return err
+			}
+			queues = append(queues, queue)
+			continue
+		}
 		processed, err := s.processResource(ctx, resource, response)
 		if err != nil {
 			return err
 		}
+		response.AddMeta(s.ruleMeta(resource).Stats())
 		if len(processed) > 0 {
 			matched = append(matched, processed...)
 			matched := &Matched{
@@ -64,30 +160,58 @@ func (s *service) tick(ctx context.Context, response *Response) error {
 				URLs:     make([]string, 0),
 			}
 			matched.Add(processed...)
-			response.Matched = append(response.Matched, matched)
+			response.AddMatched(matched)
 		}
 	}
+	if len(queues) > 0 {
+		return s.drainQueues(ctx, queues, response)
+	}
 	return err
 }
 
+//pendingResources returns pending resources and next change cursor for sources listed with cursor,
+//it returns true if some pending resources were left out by listing MaxResults
+func (s *service) pendingResources(ctx context.Context, resource *config.Rule) ([]storage.Object, string, bool, error) {
+	objects, cursor, err := s.getResourceCandidates(ctx, resource)
+	if err != nil {
+		return nil, "", false, errors.Wrapf(err, "failed to get resource candidate %v", resource.Source.URL)
+	}
+	pending, err := s.ruleMeta(resource).PendingResources(ctx, objects)
+	if err != nil {
+		return nil, "", false, errors.Wrapf(err, "failed to read pending resource %v", len(objects))
+	}
+	if hasChangeLister(resource) {
+		return pending, cursor, false, nil
+	}
+	pending, truncated := resource.Listing.Limit(pending)
+	return pending, cursor, truncated, nil
+}
+
 func (s *service) processResource(ctx context.Context, resource *config.Rule, response *Response) ([]storage.Object, error) {
-	objects, err := s.getResourceCandidates(ctx, resource)
+	scanTime := time.Now()
+	pending, cursor, truncated, err := s.pendingResources(ctx, resource)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get resource candidate %v", resource.Source.URL)
+		return nil, err
 	}
-	pending, err := s.metaService.PendingResources(ctx, objects)
-	if err != nil || len(pending) == 0 {
-		if err != nil {
-			err = errors.Wrapf(err, "failed to read pending resource %v", len(objects))
+	if len(pending) > 0 {
+		if err = s.notifyAll(ctx, resource, pending, response); err != nil {
+			return nil, errors.Wrapf(err, "failed to notify all")
+		}
+		if err = s.ruleMeta(resource).AddProcessed(ctx, pending); err != nil {
+			return pending, errors.Wrapf(err, "failed to update processed")
 		}
-		return nil, err
 	}
-	if err = s.notifyAll(ctx, resource, pending, response); err != nil {
-		return nil, errors.Wrapf(err, "failed to notify all")
+	//cursor only advances once changes were processed
+	if cursor != "" {
+		if err = s.ruleMeta(resource).SetCursor(ctx, resource.Source.URL, cursor); err != nil {
+			err = errors.Wrapf(err, "failed to update cursor")
+		}
 	}
-	err = s.metaService.AddProcessed(ctx, pending)
-	if err != nil {
-		err = errors.Wrapf(err, "failed to update processed")
+	//watermark only advances once all listed resources were processed
+	if !hasChangeLister(resource) && !truncated {
+		if err = s.ruleMeta(resource).SetWatermark(ctx, resource.Source.URL, scanTime); err != nil {
+			err = errors.Wrapf(err, "failed to update watermark")
+		}
 	}
 	return pending, err
 }
@@ -99,6 +223,27 @@ func (s *service) notify(ctx context.Context, rule *config.Rule, object storage.
 		Move:   rule.Move,
 		Stream: true,
 	})
+	return merge(proxyResponse, response)
+}
+
+//notifyBatch moves objects with batched server side copy and source delete
+func (s *service) notifyBatch(ctx context.Context, rule *config.Rule, objects []storage.Object, response *Response) error {
+	var requests = make([]*proxy.Request, 0, len(objects))
+	for _, object := range objects {
+		requests = append(requests, &proxy.Request{
+			Source: rule.Source.CloneWithURL(object.URL()),
+			Dest:   &rule.Dest,
+			Move:   rule.Move,
+			Stream: true,
+		})
+	}
+	proxyResponse := s.proxy.ProxyAll(ctx, requests, rule.Batch)
+	response.DeleteBatches += proxyResponse.DeleteBatches
+	return merge(proxyResponse, response)
+}
+
+//merge merges proxy response into cron response
+func merge(proxyResponse *proxy.Response, response *Response) error {
 	if proxyResponse.Error != "" {
 		return errors.New(proxyResponse.Error)
 	}
@@ -111,6 +256,7 @@ func (s *service) notify(ctx context.Context, rule *config.Rule, object storage.
 	for k, v := range proxyResponse.Invoked {
 		response.AddInvoked(k, v)
 	}
+	response.AddThrottleTime(time.Duration(proxyResponse.ThrottleTimeMs) * time.Millisecond)
 	return nil
 }
 
@@ -118,35 +264,76 @@ func (s *service) notifyAll(ctx context.Context, resource *config.Rule, objects
 	if len(objects) == 0 {
 		return nil
 	}
-	waitGroup := &sync.WaitGroup{}
-	waitGroup.Add(len(objects))
-	var errorChannel = make(chan error, len(objects))
-	for i := range objects {
-		go func(object storage.Object) {
-			defer waitGroup.Done()
-			errorChannel <- s.notify(ctx, resource, object, response)
-		}(objects[i])
+	if resource.Move && resource.Batch != nil {
+		return s.notifyBatch(ctx, resource, objects, response)
 	}
-	for i := 0; i < len(objects); i++ {
-		if err := <-errorChannel; err != nil {
+	for _, err := range s.notifyEach(ctx, resource, objects, response) {
+		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (s *service) getResourceCandidates(ctx context.Context, resource *config.Rule) ([]storage.Object, error) {
+//notifyEach processes objects and returns each object processing error, batched move that fails is retried per object still in source
+func (s *service) notifyEach(ctx context.Context, resource *config.Rule, objects []storage.Object, response *Response) []error {
+	var result = make([]error, len(objects))
+	if len(objects) == 0 {
+		return result
+	}
+	pending := objects
+	if resource.Move && resource.Batch != nil {
+		if err := s.notifyBatch(ctx, resource, objects, response); err == nil {
+			return result
+		}
+		options, err := s.secret.StorageOpts(ctx, &resource.Source)
+		if err != nil {
+			for i := range result {
+				result[i] = err
+			}
+			return result
+		}
+		pending = make([]storage.Object, len(objects))
+		for i, object := range objects {
+			if has, _ := s.fs.Exists(ctx, object.URL(), options...); has {
+				pending[i] = object
+			}
+		}
+	}
+	waitGroup := &sync.WaitGroup{}
+	for i := range pending {
+		if pending[i] == nil {
+			continue
+		}
+		waitGroup.Add(1)
+		go func(i int) {
+			defer waitGroup.Done()
+			result[i] = s.notify(ctx, resource, pending[i], response)
+		}(i)
+	}
+	waitGroup.Wait()
+	return result
+}
+
+func (s *service) getResourceCandidates(ctx context.Context, resource *config.Rule) ([]storage.Object, string, error) {
 	var result = make([]storage.Object, 0)
 	options, err := s.secret.StorageOpts(ctx, &resource.Source)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	if lister, ok := changeListers[url.Scheme(resource.Source.URL, file.Scheme)]; ok {
+		return s.listChanges(ctx, lister, resource, options)
 	}
-	options = s.addLastModifiedTimeMatcher(options)
-	return result, s.appendResources(ctx, resource.Source.URL, &result, &resource.Source.Basic, options)
+	since, err := s.scanSince(ctx, resource)
+	if err != nil {
+		return nil, "", err
+	}
+	options = append(options, matcher.NewModification(nil, &since))
+	return result, "", s.appendResources(ctx, resource.Source.URL, 1, &result, &resource.Source, resource.Listing, options)
 }
 
-func (s *service) appendResources(ctx context.Context, URL string, result *[]storage.Object, filter *matcher.Basic, options []storage.Option) error {
-	objects, err := s.fs.List(ctx, URL, options...)
+func (s *service) appendResources(ctx context.Context, URL string, depth int, result *[]storage.Object, filter option.Matcher, listing *config.Listing, options []storage.Option) error {
+	objects, err := s.list(ctx, URL, listing, options)
 	if err != nil {
 		return err
 	}
@@ -155,7 +342,10 @@ func (s *service) appendResources(ctx context.Context, URL string, result *[]sto
 			continue
 		}
 		if objects[i].IsDir() {
-			if err = s.appendResources(ctx, objects[i].URL(), result, filter, options); err != nil {
+			if !listing.CanDescend(depth) {
+				continue
+			}
+			if err = s.appendResources(ctx, objects[i].URL(), depth+1, result, filter, listing, options); err != nil {
 				return err
 			}
 			continue
@@ -169,43 +359,106 @@ func (s *service) appendResources(ctx context.Context, URL string, result *[]sto
 	return nil
 }
 
-func (s *service) addLastModifiedTimeMatcher(options []storage.Option) []storage.Option {
-	afterTime := time.Now().Add(-s.config.TimeWindow.Duration)
-	return append(options, matcher.NewModification(nil, &afterTime))
+//list lists folder objects, with listing PageSize folder is listed with subsequent page calls
+func (s *service) list(ctx context.Context, URL string, listing *config.Listing, options []storage.Option) ([]storage.Object, error) {
+	if listing == nil || listing.PageSize == 0 {
+		return s.fs.List(ctx, URL, options...)
+	}
+	var result []storage.Object
+	for offset := 0; ; offset += listing.PageSize {
+		//page counter starts with 1, items with counter below page offset are skipped
+		page := option.NewPage(offset, offset+listing.PageSize)
+		if offset > 0 {
+			page = option.NewPage(offset+1, offset+listing.PageSize)
+		}
+		objects, err := s.fs.List(ctx, URL, append(options[:len(options):len(options)], page)...)
+		if err != nil {
+			return nil, err
+		}
+		if offset == 0 {
+			if len(objects) > listing.PageSize+1 { //storage does not support paging
+				return objects, nil
+			}
+			result = append(result, objects...)
+		} else {
+			for i := range objects {
+				if i == 0 && objects[i].IsDir() && isSameLocation(objects[i].URL(), URL) {
+					continue
+				}
+				result = append(result, objects[i])
+			}
+		}
+		files := len(objects)
+		if len(objects) > 0 && objects[0].IsDir() && isSameLocation(objects[0].URL(), URL) {
+			files--
+		}
+		if files < listing.PageSize {
+			return result, nil
+		}
+	}
+}
+
+func isSameLocation(URL1, URL2 string) bool {
+	return strings.Trim(URL1, "/") == strings.Trim(URL2, "/")
+}
+
+//scanSince returns listing modification time lower bound: last successful scan watermark minus overlap,
+//or time window before now if rule source was not scanned yet
+func (s *service) scanSince(ctx context.Context, resource *config.Rule) (time.Time, error) {
+	watermark, err := s.ruleMeta(resource).Watermark(ctx, resource.Source.URL)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if watermark.IsZero() {
+		return time.Now().Add(-s.config.TimeWindow.Duration), nil
+	}
+	return watermark.Add(-s.config.TimeWindow.Overlap), nil
+}
+
+func hasChangeLister(resource *config.Rule) bool {
+	_, ok := changeListers[url.Scheme(resource.Source.URL, file.Scheme)]
+	return ok
 }
 
 func (s *service) Init(ctx context.Context, fs afs.Service) error {
 	if s.config.SourceScheme == "" {
 		s.config.SourceScheme = url.Scheme(s.config.MetaURL, "")
 	}
+	s.config.Resources.OnReload(cfg.LogRuleFiles)
 	var err error
 	cfg, _ := proxy.NewConfig(ctx)
-	s.proxy = proxy.New(s.fs, cfg, s.secret)
-	if err = s.config.Init(ctx, fs); err == nil {
-		err = s.UpdateSecrets(ctx)
+	if cfg == nil {
+		cfg = &proxy.Config{}
 	}
+	cfg.RateLimit = s.config.RateLimit
+	s.proxy = proxy.New(s.fs, cfg, s.secret)
+	s.config.Resources.OnLoad(s.updateSecrets)
+	err = s.config.Init(ctx, fs)
 	return err
 }
 
-func (s *service) UpdateSecrets(ctx context.Context) error {
+//updateSecrets decrypts rules secrets, it is called for loaded rules before snapshot is published
+func (s *service) updateSecrets(ctx context.Context, rules []*config.Rule) error {
 	if s.secret == nil {
 		return nil
 	}
 	resources := make([]*cfg.Resource, 0)
-	for i := range s.config.Resources.Rules {
-		resources = append(resources, &s.config.Resources.Rules[i].Source)
-		resources = append(resources, &s.config.Resources.Rules[i].Dest)
+	for i := range rules {
+		resources = append(resources, &rules[i].Source)
+		resources = append(resources, &rules[i].Dest)
 	}
 	return s.secret.Init(ctx, s.fs, resources)
 }
 
 //New returns new cron service
 func New(ctx context.Context, config *Config, fs afs.Service) (Service, error) {
+	fs = native.New(fs)
+	config.Resources.OnDecrypt(secret.NewDecrypter(fs))
 	err := config.Init(ctx, fs)
 	if err != nil {
 		return nil, err
 	}
-	meteService := meta.New(config.MetaURL, config.TimeWindow.Duration*2, fs)
+	meteService := meta.New(config.MetaURL, config.TimeWindow.Duration*2, config.TimeWindow.Overlap, fs)
 	result := &service{
 		config:      config,
 		fs:          fs,
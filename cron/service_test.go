@@ -3,15 +3,20 @@ package cron
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/cron/config"
 	"github.com/viant/smirror/cron/meta"
 	"github.com/stretchr/testify/assert"
 	"github.com/viant/afs"
 	"github.com/viant/afs/asset"
 	"github.com/viant/afs/file"
+	"github.com/viant/afs/matcher"
 	"github.com/viant/afs/mem"
+	"github.com/viant/afs/storage"
 	"github.com/viant/afs/url"
 	"github.com/viant/assertly"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
@@ -123,3 +128,194 @@ func getTestObjects(objects map[string]time.Time) []*asset.Resource {
 	}
 	return result
 }
+
+func TestService_AppendResources(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/listing/depth"
+	_ = fs.Delete(ctx, baseURL)
+	for _, name := range []string{"f1.csv", "sub/f2.csv", "sub/deep/f3.csv"} {
+		assert.Nil(t, fs.Upload(ctx, baseURL+"/"+name, 0644, strings.NewReader("x")))
+	}
+	var useCases = []struct {
+		description string
+		listing     *config.Listing
+		expect      int
+	}{
+		{description: "no limit", expect: 3},
+		{description: "source folder only", listing: &config.Listing{MaxDepth: 1}, expect: 1},
+		{description: "one subfolder level", listing: &config.Listing{MaxDepth: 2, PageSize: 1}, expect: 2},
+	}
+	srv := &service{config: &Config{}, fs: fs}
+	for _, useCase := range useCases {
+		var result []storage.Object
+		err := srv.appendResources(ctx, baseURL, 1, &result, &matcher.Basic{}, useCase.listing, nil)
+		assert.Nil(t, err, useCase.description)
+		assert.EqualValues(t, useCase.expect, len(result), useCase.description)
+	}
+}
+
+func TestService_TickStream(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/stream/case001/"
+	_ = fs.Delete(ctx, "mem://localhost/stream")
+	err := asset.Create(mem.Singleton(), baseURL, getTestObjects(map[string]time.Time{
+		"f1.txt": time.Now().Add(-time.Millisecond),
+		"f2.txt": time.Now().Add(-time.Millisecond),
+	}))
+	if !assert.Nil(t, err) {
+		return
+	}
+	cfg, err := NewConfigFromJSON(ctx, `{
+  "MetaURL": "mem://localhost/stream/ops/meta.json",
+  "TimeWindow": {"DurationInSec": 600},
+  "Resources": {"Rules": [{"Source": {"URL": "mem://localhost/stream/case001/"}, "Dest": {"URL": "mem://localhost/stream/dest/"}}]}
+}`)
+	if !assert.Nil(t, err) {
+		return
+	}
+	service, err := New(ctx, cfg, fs)
+	if !assert.Nil(t, err) {
+		return
+	}
+	writer := httptest.NewRecorder()
+	response := service.TickStream(ctx, writer)
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+	assert.Equal(t, 0, len(response.Matched))
+	assert.Equal(t, 2, response.Streamed)
+
+	var records []*StreamRecord
+	for _, line := range strings.Split(strings.TrimSpace(writer.Body.String()), "\n") {
+		record := &StreamRecord{}
+		if !assert.Nil(t, json.Unmarshal([]byte(line), record), line) {
+			return
+		}
+		records = append(records, record)
+	}
+	if !assert.Equal(t, 2, len(records)) {
+		return
+	}
+	assert.Equal(t, 2, len(records[0].Matched.URLs))
+	assert.Equal(t, 2, len(records[0].Copied))
+	assert.NotNil(t, records[1].Summary)
+	assert.Equal(t, 0, len(records[1].Summary.Copied))
+	assert.True(t, writer.Flushed)
+}
+
+func TestService_TickQueue(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/queue")
+	input := map[string]time.Time{}
+	for i := 0; i < 5; i++ {
+		input[fmt.Sprintf("f%v.txt", i)] = time.Now().Add(-time.Millisecond)
+	}
+	err := asset.Create(mem.Singleton(), "mem://localhost/queue/case001/", getTestObjects(input))
+	if !assert.Nil(t, err) {
+		return
+	}
+	cfg, err := NewConfigFromJSON(ctx, `{
+  "MetaURL": "mem://localhost/queue/ops/meta.json",
+  "TimeWindow": {"DurationInSec": 600},
+  "Queue": {"BatchSize": 2, "MinPerRule": 1},
+  "Resources": {"Rules": [{"Source": {"URL": "mem://localhost/queue/case001/"}, "Dest": {"URL": "mem://localhost/queue/dest/"}}]}
+}`)
+	if !assert.Nil(t, err) {
+		return
+	}
+	service, err := New(ctx, cfg, fs)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var useCases = []struct {
+		description string
+		added       int
+		claimed     int
+		queued      int
+	}{
+		{description: "first tick queues backlog", added: 5, claimed: 2, queued: 3},
+		{description: "second tick drains queue", claimed: 2, queued: 1},
+		{description: "third tick drains remainder", claimed: 1, queued: 0},
+		{description: "nothing left"},
+	}
+	for _, useCase := range useCases {
+		response := service.Tick(ctx)
+		if !assert.Equal(t, base.StatusOK, response.Status, useCase.description+" "+response.Error) {
+			return
+		}
+		if !assert.Equal(t, 1, len(response.Queues), useCase.description) {
+			return
+		}
+		stats := response.Queues[0]
+		assert.Equal(t, useCase.added, stats.Added, useCase.description)
+		assert.Equal(t, useCase.claimed, stats.Claimed, useCase.description)
+		assert.Equal(t, useCase.queued, stats.Queued, useCase.description)
+		assert.Equal(t, useCase.claimed, len(response.Copied), useCase.description)
+	}
+	objects, _ := fs.List(ctx, "mem://localhost/queue/dest/localhost/queue/case001/")
+	assert.Equal(t, 5, len(objects)-1)
+}
+
+func TestService_TickQueueFailures(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/queuefail")
+	input := map[string]time.Time{"f1.txt": time.Now().Add(-time.Millisecond)}
+	for _, source := range []string{"mem://localhost/queuefail/case001/", "mem://localhost/queuefail/case002/"} {
+		if err := asset.Create(mem.Singleton(), source, getTestObjects(input)); !assert.Nil(t, err) {
+			return
+		}
+	}
+	cfg, err := NewConfigFromJSON(ctx, `{
+  "MetaURL": "mem://localhost/queuefail/ops/meta.json",
+  "TimeWindow": {"DurationInSec": 600},
+  "Queue": {"BatchSize": 10, "MinPerRule": 1, "MaxFailures": 2},
+  "Resources": {"Rules": [
+    {"Source": {"URL": "mem://localhost/queuefail/case001/"}, "Dest": {"URL": "mem://localhost/queuefail/dest/"}},
+    {"Source": {"URL": "mem://localhost/queuefail/case002/"}, "Dest": {"URL": "mem://localhost/queuefail/dest/"}}
+  ]}
+}`)
+	if !assert.Nil(t, err) {
+		return
+	}
+	srv, err := New(ctx, cfg, fs)
+	if !assert.Nil(t, err) {
+		return
+	}
+	//poison resource queued before its source was removed
+	poisonURL := "mem://localhost/queuefail/case001/missing.txt"
+	poisoned := &QueueState{Queued: []*Queued{{URL: poisonURL, Modified: time.Now(), Size: 3}}}
+	data, _ := json.Marshal(poisoned)
+	if err = fs.Upload(ctx, srv.(*service).queueURL(cfg.Resources.Rules[0]), 0644, strings.NewReader(string(data))); !assert.Nil(t, err) {
+		return
+	}
+	var useCases = []struct {
+		description  string
+		copied       int
+		failed       int
+		deadLettered int
+		queued       int
+	}{
+		{description: "poison resource fails, other resources are processed", copied: 2, failed: 1, queued: 1},
+		{description: "poison resource is dead-lettered", failed: 1, deadLettered: 1},
+	}
+	for _, useCase := range useCases {
+		response := srv.Tick(ctx)
+		assert.Equal(t, base.StatusError, response.Status, useCase.description)
+		assert.Contains(t, response.Error, poisonURL, useCase.description)
+		assert.Equal(t, useCase.copied, len(response.Copied), useCase.description)
+		if !assert.Equal(t, 2, len(response.Queues), useCase.description) {
+			return
+		}
+		stats := response.Queues[0]
+		if stats.Rule != ruleKey(cfg.Resources.Rules[0]) {
+			stats = response.Queues[1]
+		}
+		assert.Equal(t, useCase.failed, stats.Failed, useCase.description)
+		assert.Equal(t, useCase.deadLettered, stats.DeadLettered, useCase.description)
+		assert.Equal(t, useCase.queued, stats.Queued, useCase.description)
+	}
+	response := srv.Tick(ctx)
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+}
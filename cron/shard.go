@@ -0,0 +1,179 @@
+package cron
+
+import (
+	"github.com/viant/smirror/base"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/twmb/murmur3"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/cron/config"
+	"github.com/viant/smirror/cron/meta"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHeartbeatTTLSec = 60
+	defaultVirtualNodes    = 64
+)
+
+//Sharding represents consistent hash rule assignment across cron daemon replicas
+type Sharding struct {
+	//MembersURL shared location storing replica heartbeats
+	MembersURL string
+	//ReplicaID replica identity, HOSTNAME env (pod name) by default
+	ReplicaID string `json:",omitempty"`
+	//HeartbeatTTLSec replica without heartbeat within TTL leaves the ring, default 60
+	HeartbeatTTLSec base.Seconds `json:",omitempty"`
+	//VirtualNodes ring virtual nodes per replica, default 64
+	VirtualNodes int `json:",omitempty"`
+}
+
+//Shard represents replica shard assignment
+type Shard struct {
+	ReplicaID string
+	Members   []string
+	Owned     int
+	Skipped   int
+}
+
+//Init initialises sharding
+func (s *Sharding) Init() {
+	if s.ReplicaID == "" {
+		s.ReplicaID, _ = os.Hostname()
+	}
+	if s.HeartbeatTTLSec == 0 {
+		s.HeartbeatTTLSec = defaultHeartbeatTTLSec
+	}
+	if s.VirtualNodes == 0 {
+		s.VirtualNodes = defaultVirtualNodes
+	}
+}
+
+//Validate checks if sharding is valid
+func (s *Sharding) Validate() error {
+	if s.MembersURL == "" {
+		return errors.New("sharding.MembersURL was empty")
+	}
+	if s.ReplicaID == "" {
+		return errors.New("sharding.ReplicaID was empty")
+	}
+	return nil
+}
+
+//TTL returns heartbeat TTL
+func (s *Sharding) TTL() time.Duration {
+	return time.Duration(s.HeartbeatTTLSec) * time.Second
+}
+
+//ring represents consistent hash ring
+type ring struct {
+	hashes  []uint64
+	members map[uint64]string
+}
+
+func (r *ring) owner(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	hash := murmur3.Sum64([]byte(key))
+	index := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= hash })
+	if index == len(r.hashes) {
+		index = 0
+	}
+	return r.members[r.hashes[index]]
+}
+
+func newRing(members []string, virtualNodes int) *ring {
+	result := &ring{members: make(map[uint64]string)}
+	for _, member := range members {
+		for i := 0; i < virtualNodes; i++ {
+			hash := murmur3.Sum64([]byte(fmt.Sprintf("%v#%v", member, i)))
+			result.members[hash] = member
+			result.hashes = append(result.hashes, hash)
+		}
+	}
+	sort.Slice(result.hashes, func(i, j int) bool { return result.hashes[i] < result.hashes[j] })
+	return result
+}
+
+//ruleKey returns rule shard key, glob is only included when set, so that existing rule keys do not change
+func ruleKey(rule *config.Rule) string {
+	parts := []string{rule.Source.URL, rule.Source.Prefix, rule.Source.Suffix, rule.Dest.URL}
+	if rule.Source.Glob != "" {
+		parts = append(parts, rule.Source.Glob)
+	}
+	return strings.Join(parts, "|")
+}
+
+//heartbeat records replica heartbeat and returns live members
+func (s *service) heartbeat(ctx context.Context) ([]string, error) {
+	sharding := s.config.Sharding
+	heartbeatURL := url.Join(sharding.MembersURL, sharding.ReplicaID)
+	now := time.Now()
+	if err := s.fs.Upload(ctx, heartbeatURL, file.DefaultFileOsMode, bytes.NewReader([]byte(now.UTC().Format(time.RFC3339)))); err != nil {
+		return nil, errors.Wrapf(err, "failed to record heartbeat: %v", heartbeatURL)
+	}
+	objects, err := s.fs.List(ctx, sharding.MembersURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list members: %v", sharding.MembersURL)
+	}
+	var members = []string{sharding.ReplicaID}
+	for _, object := range objects {
+		if object.IsDir() || object.Name() == sharding.ReplicaID || now.Sub(object.ModTime()) > sharding.TTL() {
+			continue
+		}
+		members = append(members, object.Name())
+	}
+	sort.Strings(members)
+	return members, nil
+}
+
+//shardFilter returns rule ownership filter, all rules are owned without sharding
+func (s *service) shardFilter(ctx context.Context, response *Response) (func(rule *config.Rule) bool, error) {
+	sharding := s.config.Sharding
+	if sharding == nil {
+		return func(rule *config.Rule) bool { return true }, nil
+	}
+	members, err := s.heartbeat(ctx)
+	if err != nil {
+		return nil, err
+	}
+	hashRing := newRing(members, sharding.VirtualNodes)
+	response.Shard = &Shard{ReplicaID: sharding.ReplicaID, Members: members}
+	return func(rule *config.Rule) bool {
+		if hashRing.owner(ruleKey(rule)) != sharding.ReplicaID {
+			response.Shard.Skipped++
+			return false
+		}
+		response.Shard.Owned++
+		return true
+	}, nil
+}
+
+//ruleMeta returns meta service, with sharding or MetaPerRule processed state is kept per rule, so it follows the rule on rebalancing
+//and busy rule state does not slow down other rules
+func (s *service) ruleMeta(rule *config.Rule) meta.Service {
+	if s.config.Sharding == nil && !s.config.MetaPerRule {
+		return s.metaService
+	}
+	baseURL := strings.TrimSuffix(s.config.MetaURL, path.Ext(s.config.MetaURL))
+	metaURL := url.Join(baseURL, fmt.Sprintf("%x.json", murmur3.Sum64([]byte(ruleKey(rule)))))
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.ruleMetas == nil {
+		s.ruleMetas = make(map[string]meta.Service)
+	}
+	if result, ok := s.ruleMetas[metaURL]; ok {
+		return result
+	}
+	result := meta.New(metaURL, s.config.TimeWindow.Duration*2, s.config.TimeWindow.Overlap, s.fs)
+	s.ruleMetas[metaURL] = result
+	return result
+}
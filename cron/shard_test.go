@@ -0,0 +1,59 @@
+package cron
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/cron/config"
+	"github.com/viant/smirror/proxy"
+	"testing"
+)
+
+func TestService_ShardFilter(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	var rules []*config.Rule
+	for i := 0; i < 100; i++ {
+		rule := &config.Rule{}
+		rule.Source.URL = fmt.Sprintf("mem://localhost/shard/partner%v/", i)
+		rules = append(rules, rule)
+	}
+	owners := map[string]string{}
+	for _, replicaID := range []string{"replica-0", "replica-1", "replica-2"} {
+		sharding := &Sharding{MembersURL: "mem://localhost/shard/members", ReplicaID: replicaID}
+		sharding.Init()
+		srv := &service{config: &Config{Sharding: sharding}, fs: fs}
+		_, err := srv.heartbeat(ctx)
+		assert.Nil(t, err)
+	}
+	for _, replicaID := range []string{"replica-0", "replica-1", "replica-2"} {
+		sharding := &Sharding{MembersURL: "mem://localhost/shard/members", ReplicaID: replicaID}
+		sharding.Init()
+		srv := &service{config: &Config{Sharding: sharding}, fs: fs}
+		response := NewResponse(proxy.NewResponse())
+		owned, err := srv.shardFilter(ctx, response)
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, []string{"replica-0", "replica-1", "replica-2"}, response.Shard.Members)
+		for _, rule := range rules {
+			if owned(rule) {
+				assert.Equal(t, "", owners[rule.Source.URL], "rule owned by single replica")
+				owners[rule.Source.URL] = replicaID
+			}
+		}
+		assert.True(t, response.Shard.Owned > 10, replicaID)
+	}
+	assert.Equal(t, len(rules), len(owners))
+
+	hashRing := newRing([]string{"replica-0", "replica-1"}, defaultVirtualNodes)
+	moved := 0
+	for _, rule := range rules {
+		if owner := hashRing.owner(ruleKey(rule)); owner != owners[rule.Source.URL] {
+			assert.Equal(t, "replica-2", owners[rule.Source.URL], "only departed replica rules move")
+			moved++
+		}
+	}
+	assert.True(t, moved > 0)
+}
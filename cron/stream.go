@@ -0,0 +1,92 @@
+package cron
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//StreamRecord represents streamed tick NDJSON record, the last record has Summary
+type StreamRecord struct {
+	Matched  *Matched          `json:",omitempty"`
+	Copied   map[string]string `json:",omitempty"`
+	Moved    map[string]string `json:",omitempty"`
+	Invoked  map[string]string `json:",omitempty"`
+	Progress *Progress         `json:",omitempty"`
+	Summary  *Response         `json:",omitempty"`
+}
+
+//Progress represents keep alive progress record
+type Progress struct {
+	Streamed  int
+	ElapsedMs int
+}
+
+//tickStream writes NDJSON records, each record is flushed right away
+type tickStream struct {
+	mux      sync.Mutex
+	writer   io.Writer
+	encoder  *json.Encoder
+	started  time.Time
+	written  time.Time
+	streamed int
+	err      error
+}
+
+//write encodes and flushes record, write errors (i.e. disconnected caller) do not stop the tick
+func (s *tickStream) write(record *StreamRecord) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.err != nil {
+		return
+	}
+	if s.err = s.encoder.Encode(record); s.err != nil {
+		return
+	}
+	if flusher, ok := s.writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	s.written = time.Now()
+}
+
+//emitMatched returns matched emitter, transfers done so far are drained with matched record
+func (s *tickStream) emitMatched(response *Response) func(matched *Matched) {
+	return func(matched *Matched) {
+		copied, moved, invoked := response.Drain()
+		s.mux.Lock()
+		s.streamed += len(matched.URLs)
+		s.mux.Unlock()
+		s.write(&StreamRecord{Matched: matched, Copied: copied, Moved: moved, Invoked: invoked})
+	}
+}
+
+//keepAlive writes progress record whenever nothing was written for interval, closing returned channel stops it
+func (s *tickStream) keepAlive(interval time.Duration) chan bool {
+	done := make(chan bool)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				s.mux.Lock()
+				idle := now.Sub(s.written) >= interval
+				progress := &Progress{Streamed: s.streamed, ElapsedMs: int(now.Sub(s.started) / time.Millisecond)}
+				s.mux.Unlock()
+				if idle {
+					s.write(&StreamRecord{Progress: progress})
+				}
+			}
+		}
+	}()
+	return done
+}
+
+func newTickStream(writer io.Writer) *tickStream {
+	now := time.Now()
+	return &tickStream{writer: writer, encoder: json.NewEncoder(writer), started: now, written: now}
+}
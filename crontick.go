@@ -0,0 +1,49 @@
+package smirror
+
+import (
+	"context"
+	"fmt"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/cron"
+	"github.com/viant/smirror/shared"
+	"log"
+	"net/http"
+)
+
+const ndjsonContentType = "application/x-ndjson"
+
+//StorageMirrorCron cloud function entry point, runs cron tick and streams matched results as NDJSON
+func StorageMirrorCron(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, auth.EndpointCron) {
+		return
+	}
+	if r.ContentLength > 0 {
+		defer func() {
+			_ = r.Body.Close()
+		}()
+	}
+	if err := tickCron(w); err != nil {
+		log.Print(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+//tickCron streams tick records, tick errors are reported with the summary record since status was already sent
+func tickCron(writer http.ResponseWriter) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	ctx := context.Background()
+	service, err := cron.NewFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		return err
+	}
+	writer.Header().Set("Content-Type", ndjsonContentType)
+	writer.WriteHeader(http.StatusOK)
+	response := service.TickStream(ctx, writer)
+	shared.LogLn(response)
+	return nil
+}
@@ -0,0 +1,292 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	//dashboardRollupName rule date partition pre-aggregated counters object name
+	dashboardRollupName = "_rollup.json"
+	//dashboardRollupResolution pre-aggregated counters resolution
+	dashboardRollupResolution = time.Minute
+	//dashboardRollupDelay response log objects modified within the delay are counted, but not rolled up yet,
+	//so objects still being written are not missed
+	dashboardRollupDelay = time.Minute
+)
+
+//dashboardCounter represents pre-aggregated rule metrics
+type dashboardCounter struct {
+	Transfers float64 `json:",omitempty"`
+	Bytes     float64 `json:",omitempty"`
+	Errors    float64 `json:",omitempty"`
+}
+
+//dashboardRollup represents rule date partition counters per minute, keyed by minute unix time
+type dashboardRollup struct {
+	//Until response log objects modified till this time are rolled up
+	Until    time.Time
+	Counters map[int64]dashboardCounter
+}
+
+//StorageMirrorDashboard cloud function entry point, serves Grafana JSON datasource: GET / health check,
+//POST /search available targets and POST /query per rule transfer time series
+func StorageMirrorDashboard(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, auth.EndpointDashboard) {
+		return
+	}
+	err := queryDashboard(w, r)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func queryDashboard(writer http.ResponseWriter, httpRequest *http.Request) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	request := &contract.DashboardRequest{Tenant: httpRequest.URL.Query().Get("tenant")}
+	switch path.Base(httpRequest.URL.Path) {
+	case "search":
+		request.Search = true
+	case "query":
+	default:
+		_, err = writer.Write([]byte(base.StatusOK))
+		return err
+	}
+	if httpRequest.ContentLength > 0 {
+		defer func() {
+			_ = httpRequest.Body.Close()
+		}()
+		if err = json.NewDecoder(httpRequest.Body).Decode(&request); err != nil {
+			return errors.Wrapf(err, "failed to decode %T", request)
+		}
+	}
+	ctx := context.Background()
+	service, err := NewFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		return err
+	}
+	response := service.Dashboard(ctx, request)
+	if response.Status != base.StatusOK {
+		return errors.New(response.Error)
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	if request.Search {
+		return json.NewEncoder(writer).Encode(response.Targets)
+	}
+	return json.NewEncoder(writer).Encode(response.Series)
+}
+
+//Dashboard returns per rule transfer counts, bytes and errors bucketed by interval, aggregated from response log
+func (s *service) Dashboard(ctx context.Context, request *contract.DashboardRequest) *contract.DashboardResponse {
+	response := contract.NewDashboardResponse()
+	if s.config.ResponseLog == nil || s.config.ResponseLog.URL == "" {
+		response.Status = base.StatusError
+		response.Error = "dashboard requires response log (ResponseLog.URL)"
+		return response
+	}
+	if request.Search {
+		for _, target := range s.dashboardTargets() {
+			if strings.Contains(target, request.Target) {
+				response.Targets = append(response.Targets, target)
+			}
+		}
+		return response
+	}
+	var err error
+	if response.Series, err = s.dashboardSeries(ctx, request); err != nil {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+	}
+	return response
+}
+
+//dashboardTargets returns <rule>.<metric> targets of active rules
+func (s *service) dashboardTargets() []string {
+	var result = make([]string, 0)
+	var unique = make(map[string]bool)
+	for _, rule := range s.config.Mirrors.Snapshot() {
+		name := rule.Info.Workflow
+		if name == "" {
+			name = ruleFilePartition(rule.Info.URL)
+		}
+		if name == "" || unique[name] {
+			continue
+		}
+		unique[name] = true
+		for _, metric := range contract.Metrics {
+			result = append(result, name+"."+metric)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+//dashboardSeries aggregates rule pre-aggregated counters within query range
+func (s *service) dashboardSeries(ctx context.Context, request *contract.DashboardRequest) ([]*contract.Series, error) {
+	from, to, interval := request.Window(time.Now())
+	buckets := int(to.Sub(from)/interval) + 1
+	var targets = make([]string, 0)
+	for _, target := range request.Targets {
+		targets = append(targets, target.Target)
+	}
+	if len(targets) == 0 {
+		targets = s.dashboardTargets()
+	}
+	var result = make([]*contract.Series, 0)
+	var values = make(map[string][]float64)
+	var rules = make(map[string]bool)
+	for _, target := range targets {
+		index := strings.LastIndex(target, ".")
+		if index == -1 {
+			return nil, errors.Errorf("invalid target: %v, expected <rule>.<metric>", target)
+		}
+		if _, ok := values[target]; ok {
+			continue
+		}
+		values[target] = make([]float64, buckets)
+		rules[target[:index]] = true
+		result = append(result, &contract.Series{Target: target})
+	}
+	now := time.Now()
+	for rule := range rules {
+		for day := from.UTC().Truncate(24 * time.Hour); !day.After(to); day = day.Add(24 * time.Hour) {
+			counters, err := s.dashboardCounters(ctx, rule, day, now)
+			if err != nil {
+				return nil, err
+			}
+			for minute, counter := range counters {
+				at := time.Unix(minute, 0)
+				if at.Before(from) || at.After(to) {
+					continue
+				}
+				bucket := int(at.Sub(from) / interval)
+				if series, ok := values[rule+"."+contract.MetricTransfers]; ok {
+					series[bucket] += counter.Transfers
+				}
+				if series, ok := values[rule+"."+contract.MetricBytes]; ok {
+					series[bucket] += counter.Bytes
+				}
+				if series, ok := values[rule+"."+contract.MetricErrors]; ok {
+					series[bucket] += counter.Errors
+				}
+			}
+		}
+	}
+	for _, series := range result {
+		series.Datapoints = make([][2]float64, buckets)
+		for i, value := range values[series.Target] {
+			series.Datapoints[i] = [2]float64{value, float64(from.Add(time.Duration(i)*interval).UnixNano() / int64(time.Millisecond))}
+		}
+	}
+	return result, nil
+}
+
+//dashboardCounters returns rule date partition counters per minute, only response log objects modified after partition rollup
+//are read, settled counters are stored back to rollup, so closed partitions are read with one call
+func (s *service) dashboardCounters(ctx context.Context, rule string, day, now time.Time) (map[int64]dashboardCounter, error) {
+	partitionURL := s.config.ResponseLog.PartitionURL(day, rule)
+	rollupURL := url.Join(partitionURL, dashboardRollupName)
+	rollup := &dashboardRollup{Counters: make(map[int64]dashboardCounter)}
+	if data, err := s.fs.DownloadWithURL(ctx, rollupURL); err == nil {
+		if err = json.Unmarshal(data, rollup); err != nil {
+			return nil, errors.Wrapf(err, "invalid dashboard rollup: %v", rollupURL)
+		}
+		if rollup.Counters == nil {
+			rollup.Counters = make(map[int64]dashboardCounter)
+		}
+	}
+	settled := now.Add(-dashboardRollupDelay)
+	if closed := day.Add(24 * time.Hour).Add(dashboardRollupDelay); settled.After(closed) {
+		settled = closed
+	}
+	if !rollup.Until.Before(settled) && !settled.Before(day.Add(24*time.Hour)) {
+		return rollup.Counters, nil
+	}
+	if exists, _ := s.fs.Exists(ctx, partitionURL); !exists {
+		return rollup.Counters, nil
+	}
+	objects, err := s.fs.List(ctx, partitionURL, option.NewRecursive(true))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list response log: %v", partitionURL)
+	}
+	var result = make(map[int64]dashboardCounter, len(rollup.Counters))
+	for minute, counter := range rollup.Counters {
+		result[minute] = counter
+	}
+	for _, object := range objects {
+		modified := object.ModTime()
+		if object.IsDir() || object.Name() == dashboardRollupName || path.Ext(object.Name()) != base.JSONExt || !modified.After(rollup.Until) {
+			continue
+		}
+		var delta dashboardCounter
+		parentURL, _ := url.Split(object.URL(), file.Scheme)
+		switch path.Base(parentURL) {
+		case base.StatusOK:
+			fileSize, err := s.responseFileSize(ctx, object.URL())
+			if err != nil {
+				return nil, err
+			}
+			delta = dashboardCounter{Transfers: 1, Bytes: float64(fileSize)}
+		case base.StatusError, base.StatusPartial:
+			delta = dashboardCounter{Errors: 1}
+		default:
+			continue
+		}
+		minute := modified.Truncate(dashboardRollupResolution).Unix()
+		result[minute] = result[minute].add(delta)
+		if !modified.After(settled) {
+			rollup.Counters[minute] = rollup.Counters[minute].add(delta)
+		}
+	}
+	if settled.After(rollup.Until) {
+		rollup.Until = settled
+		data, err := json.Marshal(rollup)
+		if err != nil {
+			return nil, err
+		}
+		if err = s.fs.Upload(ctx, rollupURL, file.DefaultFileOsMode, bytes.NewReader(data)); err != nil {
+			return nil, errors.Wrapf(err, "failed to upload dashboard rollup: %v", rollupURL)
+		}
+	}
+	return result, nil
+}
+
+//add returns counter sum
+func (c dashboardCounter) add(delta dashboardCounter) dashboardCounter {
+	return dashboardCounter{Transfers: c.Transfers + delta.Transfers, Bytes: c.Bytes + delta.Bytes, Errors: c.Errors + delta.Errors}
+}
+
+//responseFileSize returns logged response source file size
+func (s *service) responseFileSize(ctx context.Context, URL string) (int64, error) {
+	data, err := s.fs.DownloadWithURL(ctx, URL)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to read response log: %v", URL)
+	}
+	logged := struct {
+		FileSize int64
+	}{}
+	if err = json.Unmarshal(data, &logged); err != nil {
+		return 0, errors.Wrapf(err, "invalid response log: %v", URL)
+	}
+	return logged.FileSize, nil
+}
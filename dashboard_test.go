@@ -0,0 +1,144 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"testing"
+	"time"
+)
+
+func TestService_Dashboard(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/dashboard")
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Info:   base.Info{Workflow: "orders"},
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/dashboard/orders"}},
+				Dest:   &config.Resource{URL: "mem://localhost/dashboard/dest"},
+			},
+			{
+				Info:   base.Info{URL: "mem://localhost/dashboard/rules/clicks.json"},
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/dashboard/clicks"}},
+				Dest:   &config.Resource{URL: "mem://localhost/dashboard/dest"},
+			},
+		}},
+	}
+	cfg.ResponseLog = &base.ResponseLog{URL: "mem://localhost/dashboard/responses"}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, logged := range []*contract.Response{
+		{Status: base.StatusOK, FileSize: 100},
+		{Status: base.StatusOK, FileSize: 50},
+		{Status: base.StatusError},
+		{Status: base.StatusNoMatch},
+	} {
+		_, err = cfg.ResponseLog.Persist(ctx, fs, "orders", logged.Status, "", logged)
+		assert.Nil(t, err)
+	}
+	now := time.Now()
+	past := now.Add(-72 * time.Hour)
+	rollup := &dashboardRollup{
+		Until:    past.Truncate(24 * time.Hour).Add(24*time.Hour + dashboardRollupDelay),
+		Counters: map[int64]dashboardCounter{past.Truncate(time.Minute).Unix(): {Transfers: 3, Bytes: 30, Errors: 1}},
+	}
+	data, _ := json.Marshal(rollup)
+	err = fs.Upload(ctx, url.Join(cfg.ResponseLog.PartitionURL(past, "orders"), dashboardRollupName), 0644, bytes.NewReader(data))
+	assert.Nil(t, err)
+
+	var useCases = []struct {
+		description string
+		request     *contract.DashboardRequest
+		expectError bool
+		expect      map[string]float64
+	}{
+		{
+			description: "search",
+			request:     &contract.DashboardRequest{Search: true, Target: "orders"},
+			expect:      map[string]float64{"orders.bytes": 0, "orders.errors": 0, "orders.transfers": 0},
+		},
+		{
+			description: "query",
+			request: &contract.DashboardRequest{
+				Range:      contract.DashboardRange{From: now.Add(-time.Hour), To: now.Add(time.Minute)},
+				IntervalMs: int64(time.Minute / time.Millisecond),
+				Targets:    []*contract.DashboardTarget{{Target: "orders.transfers"}, {Target: "orders.bytes"}, {Target: "orders.errors"}, {Target: "clicks.transfers"}},
+			},
+			expect: map[string]float64{"orders.transfers": 2, "orders.bytes": 150, "orders.errors": 1, "clicks.transfers": 0},
+		},
+		{
+			description: "default targets outside range",
+			request:     &contract.DashboardRequest{Range: contract.DashboardRange{From: now.Add(-48 * time.Hour), To: now.Add(-24 * time.Hour)}},
+			expect:      map[string]float64{"orders.transfers": 0, "orders.bytes": 0, "orders.errors": 0, "clicks.transfers": 0, "clicks.bytes": 0, "clicks.errors": 0},
+		},
+		{
+			description: "closed partition rollup",
+			request: &contract.DashboardRequest{
+				Range:   contract.DashboardRange{From: past.Add(-time.Hour), To: past.Add(time.Hour)},
+				Targets: []*contract.DashboardTarget{{Target: "orders.transfers"}, {Target: "orders.bytes"}, {Target: "orders.errors"}},
+			},
+			expect: map[string]float64{"orders.transfers": 3, "orders.bytes": 30, "orders.errors": 1},
+		},
+		{
+			description: "invalid target",
+			request:     &contract.DashboardRequest{Targets: []*contract.DashboardTarget{{Target: "orders"}}},
+			expectError: true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		response := service.Dashboard(ctx, useCase.request)
+		if useCase.expectError {
+			assert.Equal(t, base.StatusError, response.Status, useCase.description)
+			continue
+		}
+		if !assert.Equal(t, base.StatusOK, response.Status, response.Error) {
+			continue
+		}
+		var actual = make(map[string]float64)
+		for _, target := range response.Targets {
+			actual[target] = 0
+		}
+		for _, series := range response.Series {
+			assert.True(t, len(series.Datapoints) > 1, useCase.description)
+			for _, point := range series.Datapoints {
+				actual[series.Target] += point[0]
+			}
+		}
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+	}
+	exists, _ := fs.Exists(ctx, url.Join(cfg.ResponseLog.PartitionURL(now, "orders"), dashboardRollupName))
+	assert.True(t, exists, "queried partition is rolled up")
+}
+
+func TestDashboardRequest_Window(t *testing.T) {
+	now := time.Now()
+	var useCases = []struct {
+		description string
+		request     *contract.DashboardRequest
+		maxBuckets  int
+		minFrom     time.Time
+	}{
+		{description: "default", request: &contract.DashboardRequest{}, maxBuckets: 24*12 + 1},
+		{description: "bucket cap", request: &contract.DashboardRequest{Range: contract.DashboardRange{From: now.Add(-30 * 24 * time.Hour)}, IntervalMs: 1000}, maxBuckets: 2000},
+		{description: "max data points", request: &contract.DashboardRequest{IntervalMs: 1000, MaxDataPoints: 100}, maxBuckets: 100},
+		{description: "range cap", request: &contract.DashboardRequest{Range: contract.DashboardRange{From: now.Add(-365 * 24 * time.Hour)}}, maxBuckets: 2000, minFrom: now.Add(-91 * 24 * time.Hour)},
+	}
+	for _, useCase := range useCases {
+		from, to, interval := useCase.request.Window(now)
+		buckets := int(to.Sub(from)/interval) + 1
+		assert.True(t, buckets <= useCase.maxBuckets, "%v: %v", useCase.description, buckets)
+		assert.True(t, from.After(useCase.minFrom), useCase.description)
+	}
+}
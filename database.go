@@ -0,0 +1,62 @@
+package smirror
+
+import (
+	"context"
+	"database/sql"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/database"
+	"sync"
+)
+
+//databases connection pools keyed by driver and DSN
+var databases = sync.Map{}
+
+func databaseConnection(resource *config.Resource) (*sql.DB, error) {
+	if resource.Credentials == nil || len(resource.Credentials.Auth) == 0 {
+		return nil, errors.New("database credentials were not decrypted")
+	}
+	DSN, err := database.NewDSN(resource.Credentials.Auth)
+	if err != nil {
+		return nil, err
+	}
+	key := resource.Database.Driver + "/" + DSN
+	if db, ok := databases.Load(key); ok {
+		return db.(*sql.DB), nil
+	}
+	db, err := sql.Open(resource.Database.Driver, DSN)
+	if err != nil {
+		return nil, err
+	}
+	actual, loaded := databases.LoadOrStore(key, db)
+	if loaded {
+		_ = db.Close()
+	}
+	return actual.(*sql.DB), nil
+}
+
+//loadDatabase loads transfer CSV into database table in per file transaction
+func (s *service) loadDatabase(ctx context.Context, transfer *Transfer, response *contract.Response) error {
+	db, err := databaseConnection(transfer.Resource)
+	if err != nil {
+		return base.NewCodedError(base.ErrorCodeAuth, err)
+	}
+	reader, err := transfer.GetReader()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get reader for: %v", transfer.Resource.URL)
+	}
+	waited, err := s.limiter.Wait(ctx, transfer.Resource.Database.Table)
+	response.AddThrottleTime(waited)
+	if err != nil {
+		return err
+	}
+	rows, err := database.Load(ctx, db, transfer.Resource.Database, reader, response.TriggeredBy, response.TransferID)
+	if err != nil {
+		s.limiter.Report(transfer.Resource.Database.Table, err)
+		return err
+	}
+	response.IncrementValue(contract.ValueLoadedRows, int(rows))
+	return nil
+}
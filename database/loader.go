@@ -0,0 +1,174 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/config"
+	"io"
+	"strings"
+	"sync/atomic"
+)
+
+var readerSequence int64
+
+//Load loads CSV into table within a transaction, pre and post SQL run in the same transaction, it returns number of loaded rows
+func Load(ctx context.Context, db *sql.DB, settings *config.Database, reader io.Reader, sourceURL, transferID string) (rows int64, err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+	if err = execAll(ctx, tx, settings, settings.PreSQL, sourceURL, transferID); err != nil {
+		return 0, err
+	}
+	switch settings.Driver {
+	case config.DriverPostgres:
+		rows, err = copyIn(ctx, tx, settings, reader)
+	case config.DriverMySQL:
+		rows, err = loadData(ctx, tx, settings, reader, transferID)
+	default:
+		err = errors.Errorf("unsupported driver: %v", settings.Driver)
+	}
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to load %v", settings.Table)
+	}
+	if err = execAll(ctx, tx, settings, settings.PostSQL, sourceURL, transferID); err != nil {
+		return 0, err
+	}
+	if err = tx.Commit(); err != nil {
+		return 0, errors.Wrap(err, "failed to commit transaction")
+	}
+	return rows, nil
+}
+
+//execAll executes statements with source URL and transfer ID bind parameters
+func execAll(ctx context.Context, tx *sql.Tx, settings *config.Database, statements []string, sourceURL, transferID string) error {
+	for _, statement := range statements {
+		SQL, args := settings.BindSQL(statement, sourceURL, transferID)
+		if _, err := tx.ExecContext(ctx, SQL, args...); err != nil {
+			return errors.Wrapf(err, "failed to execute: %v", SQL)
+		}
+	}
+	return nil
+}
+
+//copyIn streams CSV records with PostgreSQL COPY FROM STDIN, empty fields are loaded as NULL
+func copyIn(ctx context.Context, tx *sql.Tx, settings *config.Database, reader io.Reader) (int64, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma = []rune(settings.Delimiter)[0]
+	csvReader.FieldsPerRecord = -1
+	columns := settings.Columns
+	if settings.Header {
+		header, err := csvReader.Read()
+		if err == io.EOF {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if len(columns) == 0 {
+			for _, column := range header {
+				columns = append(columns, strings.TrimSpace(strings.TrimPrefix(column, "\uFEFF")))
+			}
+		}
+	}
+	copySQL := pq.CopyIn(settings.Table, columns...)
+	if schema, table := splitTable(settings.Table); schema != "" {
+		copySQL = pq.CopyInSchema(schema, table, columns...)
+	}
+	statement, err := tx.PrepareContext(ctx, copySQL)
+	if err != nil {
+		return 0, err
+	}
+	defer statement.Close()
+	var rows int64
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return rows, err
+		}
+		values := make([]interface{}, len(record))
+		for i, value := range record {
+			if value != "" {
+				values[i] = value
+			}
+		}
+		if _, err = statement.ExecContext(ctx, values...); err != nil {
+			return rows, err
+		}
+		rows++
+	}
+	//flushes buffered rows
+	_, err = statement.ExecContext(ctx)
+	return rows, err
+}
+
+//loadData streams CSV with MySQL LOAD DATA LOCAL INFILE, server has to enable local_infile
+func loadData(ctx context.Context, tx *sql.Tx, settings *config.Database, reader io.Reader, transferID string) (int64, error) {
+	name := fmt.Sprintf("smirror_%v_%v", transferID, atomic.AddInt64(&readerSequence, 1))
+	mysql.RegisterReaderHandler(name, func() io.Reader { return reader })
+	defer mysql.DeregisterReaderHandler(name)
+	result, err := tx.ExecContext(ctx, loadDataSQL(settings, name))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func loadDataSQL(settings *config.Database, readerName string) string {
+	builder := new(strings.Builder)
+	schema, table := splitTable(settings.Table)
+	table = "`" + table + "`"
+	if schema != "" {
+		table = "`" + schema + "`." + table
+	}
+	delimiter := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(settings.Delimiter)
+	fmt.Fprintf(builder, "LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s CHARACTER SET utf8mb4 FIELDS TERMINATED BY '%s' OPTIONALLY ENCLOSED BY '\"' LINES TERMINATED BY '\\n'", readerName, table, delimiter)
+	if settings.Header {
+		builder.WriteString(" IGNORE 1 LINES")
+	}
+	if len(settings.Columns) > 0 {
+		builder.WriteString(" (`" + strings.Join(settings.Columns, "`, `") + "`)")
+	}
+	return builder.String()
+}
+
+func splitTable(table string) (string, string) {
+	if index := strings.Index(table, "."); index != -1 {
+		return table[:index], table[index+1:]
+	}
+	return "", table
+}
+
+//NewDSN returns data source name from decrypted secret, raw DSN or JSON with DSN
+func NewDSN(secret []byte) (string, error) {
+	secret = bytes.TrimSpace(secret)
+	if len(secret) == 0 {
+		return "", errors.New("database credentials were empty")
+	}
+	if secret[0] != '{' {
+		return string(secret), nil
+	}
+	credentials := struct{ DSN string }{}
+	if err := json.Unmarshal(secret, &credentials); err != nil {
+		return "", errors.Wrap(err, "failed to decode database credentials")
+	}
+	if credentials.DSN == "" {
+		return "", errors.New("database credentials DSN was empty")
+	}
+	return credentials.DSN, nil
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/smirror/config"
+	"strings"
+	"testing"
+)
+
+//recorder fake driver recording executed statements and transaction outcome
+type recorder struct {
+	statements []string
+	args       [][]driver.Value
+}
+
+func (r *recorder) Open(name string) (driver.Conn, error) { return &conn{recorder: r}, nil }
+
+type conn struct{ recorder *recorder }
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) { return &stmt{conn: c, query: query}, nil }
+func (c *conn) Close() error                              { return nil }
+func (c *conn) Begin() (driver.Tx, error)                 { return c, nil }
+func (c *conn) Commit() error                             { return c.record("COMMIT") }
+func (c *conn) Rollback() error                           { return c.record("ROLLBACK") }
+
+func (c *conn) record(statement string) error {
+	c.recorder.statements = append(c.recorder.statements, statement)
+	if strings.Contains(statement, "fail") {
+		return errors.New("statement failed")
+	}
+	return nil
+}
+
+type stmt struct {
+	conn  *conn
+	query string
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.recorder.args = append(s.conn.recorder.args, args)
+	return driver.RowsAffected(2), s.conn.record(s.query)
+}
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) { return nil, errors.New("not supported") }
+
+func TestLoad(t *testing.T) {
+	fake := &recorder{}
+	sql.Register("smirror-recorder", fake)
+	db, err := sql.Open("smirror-recorder", "")
+	if !assert.Nil(t, err) {
+		return
+	}
+	ctx := context.Background()
+	settings := &config.Database{
+		Driver:  config.DriverMySQL,
+		Table:   "ops.orders",
+		Columns: []string{"id", "name"},
+		Header:  true,
+		PreSQL:  []string{"DELETE FROM $Table WHERE source = '$SourceURL'"},
+		PostSQL: []string{"UPDATE $Table SET source = '$SourceURL' WHERE source IS NULL"},
+	}
+	settings.Init()
+	rows, err := Load(ctx, db, settings, strings.NewReader("id,name\n1,a\n2,b\n"), "gs://bucket/orders.csv", "t1")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), rows)
+	assert.Equal(t, []string{
+		"DELETE FROM ops.orders WHERE source = ?",
+		"LOAD DATA LOCAL INFILE 'Reader::smirror_t1_1' INTO TABLE `ops`.`orders` CHARACTER SET utf8mb4 FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '\"' LINES TERMINATED BY '\\n' IGNORE 1 LINES (`id`, `name`)",
+		"UPDATE ops.orders SET source = ? WHERE source IS NULL",
+		"COMMIT",
+	}, fake.statements)
+	assert.Equal(t, []driver.Value{"gs://bucket/orders.csv"}, fake.args[0])
+
+	fake.statements = nil
+	settings.PostSQL = []string{"fail"}
+	_, err = Load(ctx, db, settings, strings.NewReader("1,a\n"), "gs://bucket/orders.csv", "t2")
+	assert.NotNil(t, err)
+	assert.Equal(t, "ROLLBACK", fake.statements[len(fake.statements)-1])
+}
+
+func TestDatabase_BindSQL(t *testing.T) {
+	var useCases = []struct {
+		description string
+		driver      string
+		statement   string
+		expectSQL   string
+		expectArgs  []interface{}
+	}{
+		{description: "mysql quoted", driver: config.DriverMySQL, statement: "DELETE FROM $Table WHERE file = '$SourceURL' AND id <> '$TransferID'",
+			expectSQL: "DELETE FROM ops.orders WHERE file = ? AND id <> ?", expectArgs: []interface{}{"gs://b/x'; DROP TABLE t; --", "t1"}},
+		{description: "postgres numbered", driver: config.DriverPostgres, statement: "INSERT INTO log VALUES ($TransferID, $SourceURL)",
+			expectSQL: "INSERT INTO log VALUES ($1, $2)", expectArgs: []interface{}{"t1", "gs://b/x'; DROP TABLE t; --"}},
+		{description: "no variables", driver: config.DriverPostgres, statement: "ANALYZE $Table", expectSQL: "ANALYZE ops.orders"},
+	}
+	for _, useCase := range useCases {
+		settings := &config.Database{Driver: useCase.driver, Table: "ops.orders"}
+		SQL, args := settings.BindSQL(useCase.statement, "gs://b/x'; DROP TABLE t; --", "t1")
+		assert.Equal(t, useCase.expectSQL, SQL, useCase.description)
+		assert.Equal(t, useCase.expectArgs, args, useCase.description)
+	}
+}
+
+func TestNewDSN(t *testing.T) {
+	DSN, err := NewDSN([]byte(`{"DSN":"postgres://app:secret@db:5432/ops"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, "postgres://app:secret@db:5432/ops", DSN)
+	DSN, err = NewDSN([]byte("app:secret@tcp(db:3306)/ops\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, "app:secret@tcp(db:3306)/ops", DSN)
+	_, err = NewDSN([]byte(`{}`))
+	assert.NotNil(t, err)
+}
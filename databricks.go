@@ -0,0 +1,100 @@
+package smirror
+
+import (
+	"compress/gzip"
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/databricks"
+	"github.com/viant/smirror/secret"
+	"io"
+	"net/http"
+	"sync"
+)
+
+//databricksClients workspace clients keyed by host and credentials, to reuse OAuth tokens
+var databricksClients = sync.Map{}
+
+func databricksClient(resource *config.Resource, httpClient *http.Client) (*databricks.Client, error) {
+	if resource.Credentials == nil || len(resource.Credentials.Auth) == 0 {
+		return nil, errors.New("databricks credentials were not decrypted")
+	}
+	key := resource.Databricks.Host + "/" + string(resource.Credentials.Auth)
+	if httpClient != nil {
+		key += "/" + string(secret.NewIdentity(url.Scheme(resource.URL, ""), resource))
+	}
+	if client, ok := databricksClients.Load(key); ok {
+		return client.(*databricks.Client), nil
+	}
+	credentials, err := databricks.NewCredentials(resource.Credentials.Auth)
+	if err != nil {
+		return nil, err
+	}
+	client, _ := databricksClients.LoadOrStore(key, databricks.New(resource.Databricks.Host, credentials, httpClient))
+	return client.(*databricks.Client), nil
+}
+
+//uploadDatabricks lands transfer into Databricks volume or DBFS path
+func (s *service) uploadDatabricks(ctx context.Context, transfer *Transfer, response *contract.Response) error {
+	httpClient, err := s.httpClient(ctx, transfer.Resource)
+	if err != nil {
+		return err
+	}
+	client, err := databricksClient(transfer.Resource, httpClient)
+	if err != nil {
+		return base.NewCodedError(base.ErrorCodeAuth, err)
+	}
+	reader, err := transfer.GetReader()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get reader for: %v", transfer.Resource.URL)
+	}
+	if transfer.Dest.CompressionCodec() == config.GZipCodec {
+		pipeReader, pipeWriter := io.Pipe()
+		go func(source io.Reader) {
+			gzipWriter := gzip.NewWriter(pipeWriter)
+			_, err := io.Copy(gzipWriter, source)
+			if err == nil {
+				err = gzipWriter.Close()
+			}
+			_ = pipeWriter.CloseWithError(err)
+		}(reader)
+		reader = pipeReader
+	}
+	waited, err := s.limiter.Wait(ctx, transfer.Dest.URL)
+	response.AddThrottleTime(waited)
+	if err != nil {
+		return err
+	}
+	if err = client.Upload(ctx, transfer.Dest.URL, reader, !transfer.Resource.Databricks.SkipOverwrite); err != nil {
+		s.limiter.Report(transfer.Dest.URL, err)
+		return errors.Wrapf(err, "failed to upload to databricks: %v", transfer.Dest.URL)
+	}
+	response.AddURL(transfer.Dest.URL)
+	return nil
+}
+
+//runDatabricksJob triggers configured job after delivery
+func (s *service) runDatabricksJob(ctx context.Context, rule *config.Rule, request *contract.Request, response *contract.Response) error {
+	httpClient, err := s.httpClient(ctx, rule.Dest)
+	if err != nil {
+		return err
+	}
+	client, err := databricksClient(rule.Dest, httpClient)
+	if err != nil {
+		return err
+	}
+	destURL := ""
+	if len(response.DestOutcomes) > 0 {
+		destURL = response.DestOutcomes[0].URL
+	}
+	settings := rule.Dest.Databricks
+	runID, err := client.RunNow(ctx, settings.JobID, settings.ExpandJobParameters(request.URL, destURL))
+	if err != nil {
+		return errors.Wrapf(err, "failed to run databricks job: %v", settings.JobID)
+	}
+	response.DatabricksRunID = runID
+	return nil
+}
@@ -0,0 +1,202 @@
+package databricks
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	//DBFSPrefix DBFS path prefix, other paths are written with Files API (i.e. /Volumes/catalog/schema/volume/path)
+	DBFSPrefix    = "dbfs:"
+	dbfsBlockSize = 1024 * 1024
+)
+
+//Credentials represents decrypted workspace credentials, either PAT token or OAuth machine to machine client
+type Credentials struct {
+	Token        string `json:",omitempty"`
+	ClientID     string `json:",omitempty"`
+	ClientSecret string `json:",omitempty"`
+}
+
+//Client represents Databricks workspace REST client
+type Client struct {
+	host        string
+	credentials *Credentials
+	http        *http.Client
+	mux         sync.Mutex
+	token       string
+	expiry      time.Time
+}
+
+//Upload uploads content to Unity Catalog volume path with Files API or to DBFS path (dbfs:/path) with DBFS streaming API
+func (c *Client) Upload(ctx context.Context, path string, reader io.Reader, overwrite bool) error {
+	if strings.HasPrefix(path, DBFSPrefix) {
+		return c.uploadDBFS(ctx, strings.TrimPrefix(path, DBFSPrefix), reader, overwrite)
+	}
+	URL := fmt.Sprintf("/api/2.0/fs/files%v?overwrite=%v", escapePath(path), overwrite)
+	_, err := c.do(ctx, http.MethodPut, URL, "application/octet-stream", reader)
+	return err
+}
+
+func (c *Client) uploadDBFS(ctx context.Context, path string, reader io.Reader, overwrite bool) error {
+	created := struct {
+		Handle int64 `json:"handle"`
+	}{}
+	if err := c.call(ctx, "/api/2.0/dbfs/create", map[string]interface{}{"path": path, "overwrite": overwrite}, &created); err != nil {
+		return err
+	}
+	block := make([]byte, dbfsBlockSize)
+	for {
+		read, err := io.ReadFull(reader, block)
+		if read > 0 {
+			data := base64.StdEncoding.EncodeToString(block[:read])
+			if e := c.call(ctx, "/api/2.0/dbfs/add-block", map[string]interface{}{"handle": created.Handle, "data": data}, nil); e != nil {
+				return e
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return c.call(ctx, "/api/2.0/dbfs/close", map[string]interface{}{"handle": created.Handle}, nil)
+}
+
+//RunNow triggers job run, it returns run ID
+func (c *Client) RunNow(ctx context.Context, jobID int64, parameters map[string]string) (int64, error) {
+	request := map[string]interface{}{"job_id": jobID}
+	if len(parameters) > 0 {
+		request["job_parameters"] = parameters
+	}
+	response := struct {
+		RunID int64 `json:"run_id"`
+	}{}
+	err := c.call(ctx, "/api/2.1/jobs/run-now", request, &response)
+	return response.RunID, err
+}
+
+func (c *Client) call(ctx context.Context, URI string, request, response interface{}) error {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	data, err := c.do(ctx, http.MethodPost, URI, "application/json", bytes.NewReader(payload))
+	if err != nil || response == nil {
+		return err
+	}
+	return json.Unmarshal(data, response)
+}
+
+func (c *Client) do(ctx context.Context, method, URI, contentType string, body io.Reader) ([]byte, error) {
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	request, err := http.NewRequestWithContext(ctx, method, c.host+URI, body)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", contentType)
+	response, err := c.http.Do(request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to %v %v", method, URI)
+	}
+	defer response.Body.Close()
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("failed to %v %v: %v %s", method, URI, response.Status, data)
+	}
+	return data, nil
+}
+
+//accessToken returns PAT token or cached OAuth access token
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	if c.credentials.Token != "" {
+		return c.credentials.Token, nil
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.token != "" && time.Now().Before(c.expiry) {
+		return c.token, nil
+	}
+	form := url.Values{"grant_type": {"client_credentials"}, "scope": {"all-apis"}}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.host+"/oidc/v1/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.SetBasicAuth(c.credentials.ClientID, c.credentials.ClientSecret)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	response, err := c.http.Do(request)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get OAuth token")
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		data, _ := ioutil.ReadAll(response.Body)
+		return "", fmt.Errorf("failed to get OAuth token: %v %s", response.Status, data)
+	}
+	token := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}{}
+	if err = json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return "", errors.Wrap(err, "failed to decode OAuth token")
+	}
+	c.token = token.AccessToken
+	//refresh a minute before expiry
+	c.expiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return c.token, nil
+}
+
+func escapePath(path string) string {
+	elements := strings.Split(path, "/")
+	for i := range elements {
+		elements[i] = url.PathEscape(elements[i])
+	}
+	return strings.Join(elements, "/")
+}
+
+//NewCredentials creates credentials from decrypted secret, JSON with Token or ClientID/ClientSecret, or raw PAT token
+func NewCredentials(secret []byte) (*Credentials, error) {
+	credentials := &Credentials{}
+	secret = bytes.TrimSpace(secret)
+	if len(secret) == 0 {
+		return nil, errors.New("databricks credentials were empty")
+	}
+	if secret[0] != '{' {
+		credentials.Token = string(secret)
+		return credentials, nil
+	}
+	if err := json.Unmarshal(secret, credentials); err != nil {
+		return nil, errors.Wrap(err, "failed to decode databricks credentials")
+	}
+	if credentials.Token == "" && (credentials.ClientID == "" || credentials.ClientSecret == "") {
+		return nil, errors.New("databricks credentials require Token or ClientID and ClientSecret")
+	}
+	return credentials, nil
+}
+
+//New creates a workspace client
+func New(host string, credentials *Credentials, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 15 * time.Minute}
+	}
+	return &Client{host: strings.TrimRight(host, "/"), credentials: credentials, http: httpClient}
+}
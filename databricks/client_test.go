@@ -0,0 +1,62 @@
+package databricks
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_Upload(t *testing.T) {
+	files := map[string]string{}
+	var authorizations []string
+	dbfs := ""
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		switch {
+		case request.URL.Path == "/oidc/v1/token":
+			clientID, secret, _ := request.BasicAuth()
+			assert.Equal(t, "id:secret", clientID+":"+secret)
+			_, _ = writer.Write([]byte(`{"access_token":"oauth-token","expires_in":3600}`))
+			return
+		case strings.HasPrefix(request.URL.Path, "/api/2.0/fs/files/"):
+			files[strings.TrimPrefix(request.URL.Path, "/api/2.0/fs/files")] = string(body)
+		case request.URL.Path == "/api/2.0/dbfs/create":
+			_, _ = writer.Write([]byte(`{"handle":7}`))
+		case request.URL.Path == "/api/2.0/dbfs/add-block":
+			block := map[string]interface{}{}
+			_ = json.Unmarshal(body, &block)
+			data, _ := base64.StdEncoding.DecodeString(block["data"].(string))
+			dbfs += string(data)
+		case request.URL.Path == "/api/2.1/jobs/run-now":
+			_, _ = writer.Write([]byte(`{"run_id":123}`))
+		}
+		authorizations = append(authorizations, request.Header.Get("Authorization"))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	credentials, err := NewCredentials([]byte("dapi-pat"))
+	assert.Nil(t, err)
+	client := New(server.URL, credentials, nil)
+	assert.Nil(t, client.Upload(ctx, "/Volumes/main/raw/landing/a.csv", strings.NewReader("1,2"), true))
+	assert.Equal(t, "1,2", files["/Volumes/main/raw/landing/a.csv"])
+	runID, err := client.RunNow(ctx, 1, map[string]string{"path": "a.csv"})
+	assert.Nil(t, err)
+	assert.Equal(t, int64(123), runID)
+
+	credentials, err = NewCredentials([]byte(`{"ClientID":"id","ClientSecret":"secret"}`))
+	assert.Nil(t, err)
+	client = New(server.URL, credentials, nil)
+	assert.Nil(t, client.Upload(ctx, "dbfs:/mnt/landing/b.csv", strings.NewReader("3,4"), true))
+	assert.Equal(t, "3,4", dbfs)
+	assert.Equal(t, []string{"Bearer dapi-pat", "Bearer dapi-pat", "Bearer oauth-token", "Bearer oauth-token", "Bearer oauth-token"}, authorizations)
+
+	_, err = NewCredentials([]byte(`{"ClientID":"id"}`))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,56 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestService_Databricks(t *testing.T) {
+	files := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		if request.URL.Path == "/api/2.1/jobs/run-now" {
+			assert.Contains(t, string(body), `"file":"/Volumes/main/raw/landing/databricks/data/a.csv"`)
+			_, _ = writer.Write([]byte(`{"run_id":42}`))
+			return
+		}
+		files[strings.TrimPrefix(request.URL.Path, "/api/2.0/fs/files")] = string(body)
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/databricks/data"}},
+				Dest: &config.Resource{
+					URL:         "/Volumes/main/raw/landing",
+					Credentials: &auth.Credentials{Auth: []byte("dapi-pat")},
+					Databricks:  &config.Databricks{Host: server.URL, JobID: 1, JobParameters: map[string]string{"file": "$DestURL"}},
+				},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sourceURL := "mem://localhost/databricks/data/a.csv"
+	_ = afs.New().Upload(ctx, sourceURL, 0644, strings.NewReader("1,2,3"))
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	if !assert.Equal(t, base.StatusOK, response.Status, response.Error) {
+		return
+	}
+	assert.Equal(t, "1,2,3", files["/Volumes/main/raw/landing/databricks/data/a.csv"])
+	assert.Equal(t, int64(42), response.DatabricksRunID)
+}
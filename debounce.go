@@ -0,0 +1,85 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/storage"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"time"
+)
+
+//debounce records event as the latest source object event and waits until source object is quiet,
+//it returns current source object, or nil if the event was superseded by a later event or source object rewrite,
+//winning event calls release once processed to remove its entry
+func (s *service) debounce(ctx context.Context, debounce *config.Debounce, object storage.Object, request *contract.Request, response *contract.Response, options []storage.Option) (storage.Object, func(), error) {
+	entryURL := debounce.EntryURL(request.URL)
+	entry := &config.DebounceEntry{
+		SourceURL: request.URL,
+		EventID:   uuid.New().String(),
+		Version:   config.ObjectVersion(object.ModTime(), object.Size()),
+		Recorded:  time.Now(),
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = s.fs.Upload(ctx, entryURL, file.DefaultFileOsMode, bytes.NewReader(data)); err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to record debounce entry %v", entryURL)
+	}
+	for {
+		wait := debounce.Wait(object.ModTime(), time.Now())
+		if wait == 0 {
+			break
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, nil, base.NewCodedError(base.ErrorCodeTransient, errors.Wrapf(ctx.Err(), "debounce of %v was interrupted", request.URL))
+		}
+		current, _ := s.fs.Object(ctx, request.URL, options...)
+		if current == nil {
+			response.Status = base.StatusNoFound
+			s.releaseDebounce(ctx, entryURL, entry.EventID)
+			return nil, nil, nil
+		}
+		if config.ObjectVersion(current.ModTime(), current.Size()) != entry.Version {
+			//source object was rewritten, the rewrite event mirrors the final version
+			response.Status = base.StatusDebounced
+			return nil, nil, nil
+		}
+		object = current
+	}
+	latest := &config.DebounceEntry{}
+	if data, err = s.fs.DownloadWithURL(ctx, entryURL); err == nil {
+		err = json.Unmarshal(data, latest)
+	} else if base.ErrorCode(err) == base.ErrorCodeNotFound {
+		//a later event has already been mirrored and released the entry
+		response.Status = base.StatusDebounced
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "failed to load debounce entry %v", entryURL)
+	}
+	if latest.EventID != entry.EventID {
+		response.DebouncedBy = latest.EventID
+		response.Status = base.StatusDebounced
+		return nil, nil, nil
+	}
+	return object, func() { s.releaseDebounce(ctx, entryURL, entry.EventID) }, nil
+}
+
+//releaseDebounce deletes debounce entry unless a later event of the same source object has replaced it
+func (s *service) releaseDebounce(ctx context.Context, entryURL, eventID string) {
+	latest := &config.DebounceEntry{}
+	data, err := s.fs.DownloadWithURL(ctx, entryURL)
+	if err != nil || json.Unmarshal(data, latest) != nil || latest.EventID != eventID {
+		return
+	}
+	_ = s.fs.Delete(ctx, entryURL)
+}
@@ -0,0 +1,68 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestService_Debounce(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/debounce")
+	debounce := &config.Debounce{StateURL: "mem://localhost/debounce/state", QuietPeriod: 1}
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source:   &config.Resource{Basic: matcher.Basic{Prefix: "/debounce/data"}},
+				Dest:     &config.Resource{URL: "mem://localhost/debounce/dest"},
+				Debounce: debounce,
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var useCases = []struct {
+		description string
+		name        string
+		rewrite     bool
+	}{
+		{description: "rewritten object", name: "f1.csv", rewrite: true},
+		{description: "duplicated event", name: "f2.csv"},
+	}
+	for _, useCase := range useCases {
+		sourceURL := "mem://localhost/debounce/data/" + useCase.name
+		_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("v1"))
+		first := make(chan *contract.Response, 1)
+		go func() {
+			first <- service.Mirror(ctx, contract.NewRequest(sourceURL))
+		}()
+		time.Sleep(200 * time.Millisecond)
+		if useCase.rewrite {
+			_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("v2"))
+		}
+		second := service.Mirror(ctx, contract.NewRequest(sourceURL))
+		assert.Equal(t, base.StatusOK, second.Status, useCase.description+" "+second.Error)
+		superseded := <-first
+		assert.Equal(t, base.StatusDebounced, superseded.Status, useCase.description+" "+superseded.Error)
+
+		content, err := fs.DownloadWithURL(ctx, "mem://localhost/debounce/dest/debounce/data/"+useCase.name)
+		assert.Nil(t, err, useCase.description)
+		expect := "v1"
+		if useCase.rewrite {
+			expect = "v2"
+		}
+		assert.Equal(t, expect, string(content), useCase.description)
+		exists, _ := fs.Exists(ctx, debounce.EntryURL(sourceURL))
+		assert.False(t, exists, useCase.description+" entry")
+	}
+}
@@ -0,0 +1,65 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/storage"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"time"
+)
+
+//contentDigest returns source object size and provider checksum, or computed dedup digest if provider does not expose one
+func (s *service) contentDigest(ctx context.Context, dedup *config.Dedup, object storage.Object, options []storage.Option) (string, error) {
+	checksum := objectChecksum(object)
+	if checksum == "" {
+		var err error
+		if checksum, err = s.checksum(ctx, dedup.Algorithm, object.URL(), options); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("%v:%v", object.Size(), checksum), nil
+}
+
+//checkDuplicate checks if source content was already mirrored from other object, it returns true if duplicate was skipped or quarantined,
+//source options apply to the quarantined source, quarantine location is accessed with service credentials
+func (s *service) checkDuplicate(ctx context.Context, dedup *config.Dedup, digest string, request *contract.Request, response *contract.Response, options []storage.Option) (bool, error) {
+	entry := &config.Digest{}
+	data, err := s.fs.DownloadWithURL(ctx, dedup.EntryURL(digest))
+	if err != nil || json.Unmarshal(data, entry) != nil || !dedup.IsDuplicate(entry, request.URL, time.Now()) {
+		return false, nil
+	}
+	response.DuplicateOf = entry.SourceURL
+	response.OriginalTransferID = entry.TransferID
+	switch dedup.OnDuplicate {
+	case config.DuplicateSkip:
+		response.Status = base.StatusDuplicate
+		return true, nil
+	case config.DuplicateQuarantine:
+		quarantineURL := dedup.QuarantineDestURL(request.URL)
+		if err = s.fs.Move(ctx, request.URL, quarantineURL, sourceMoveOptions(options)...); err != nil {
+			return false, errors.Wrapf(err, "failed to quarantine %v", request.URL)
+		}
+		response.QuarantineURL = quarantineURL
+		response.Status = base.StatusDuplicate
+		return true, nil
+	}
+	return false, nil
+}
+
+//recordDigest records mirrored content digest, flagged duplicates keep the original entry
+func (s *service) recordDigest(ctx context.Context, dedup *config.Dedup, digest string, request *contract.Request, response *contract.Response) error {
+	if response.DuplicateOf != "" {
+		return nil
+	}
+	data, err := json.Marshal(&config.Digest{Digest: digest, SourceURL: request.URL, TransferID: response.TransferID, Mirrored: time.Now()})
+	if err != nil {
+		return err
+	}
+	return s.fs.Upload(ctx, dedup.EntryURL(digest), file.DefaultFileOsMode, bytes.NewReader(data))
+}
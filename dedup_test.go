@@ -0,0 +1,87 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"testing"
+)
+
+func TestService_Dedup(t *testing.T) {
+	ctx := context.Background()
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source:     &config.Resource{Basic: matcher.Basic{Prefix: "/dedup/data"}},
+				Dest:       &config.Resource{URL: "mem://localhost/dedup/dest"},
+				Dedup:      &config.Dedup{IndexURL: "mem://localhost/dedup/index", OnDuplicate: config.DuplicateSkip},
+				AllowEmpty: true,
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	fs := afs.New()
+	originalURL := "mem://localhost/dedup/data/a.csv"
+	resentURL := "mem://localhost/dedup/data/b.csv"
+	_ = fs.Upload(ctx, originalURL, 0644, strings.NewReader("1,2,3"))
+	_ = fs.Upload(ctx, resentURL, 0644, strings.NewReader("1,2,3"))
+
+	response := service.Mirror(ctx, contract.NewRequest(originalURL))
+	if !assert.Equal(t, base.StatusOK, response.Status, response.Error) {
+		return
+	}
+	assert.Equal(t, "", response.DuplicateOf)
+
+	response = service.Mirror(ctx, contract.NewRequest(resentURL))
+	assert.Equal(t, base.StatusDuplicate, response.Status, response.Error)
+	assert.Equal(t, originalURL, response.DuplicateOf)
+	exists, _ := fs.Exists(ctx, "mem://localhost/dedup/dest/dedup/data/b.csv")
+	assert.False(t, exists)
+
+	response = service.Mirror(ctx, contract.NewRequest(originalURL))
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+	assert.Equal(t, "", response.DuplicateOf)
+}
+
+func TestService_DedupQuarantine(t *testing.T) {
+	ctx := context.Background()
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source:     &config.Resource{Basic: matcher.Basic{Prefix: "/dedupq/data"}},
+				Dest:       &config.Resource{URL: "mem://localhost/dedupq/dest"},
+				Dedup:      &config.Dedup{IndexURL: "mem://localhost/dedupq/index", OnDuplicate: config.DuplicateQuarantine, QuarantineURL: "mem://localhost/dedupq/quarantine"},
+				AllowEmpty: true,
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	fs := afs.New()
+	originalURL := "mem://localhost/dedupq/data/a.csv"
+	resentURL := "mem://localhost/dedupq/data/b.csv"
+	_ = fs.Upload(ctx, originalURL, 0644, strings.NewReader("1,2,3"))
+	_ = fs.Upload(ctx, resentURL, 0644, strings.NewReader("1,2,3"))
+
+	response := service.Mirror(ctx, contract.NewRequest(originalURL))
+	if !assert.Equal(t, base.StatusOK, response.Status, response.Error) {
+		return
+	}
+	response = service.Mirror(ctx, contract.NewRequest(resentURL))
+	assert.Equal(t, base.StatusDuplicate, response.Status, response.Error)
+	assert.Equal(t, "mem://localhost/dedupq/quarantine/localhost/dedupq/data/b.csv", response.QuarantineURL)
+	exists, _ := fs.Exists(ctx, resentURL)
+	assert.False(t, exists)
+	exists, _ = fs.Exists(ctx, response.QuarantineURL)
+	assert.True(t, exists)
+}
@@ -0,0 +1,190 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/afsc/s3"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/digest"
+	"io"
+)
+
+//maxDeltaCopySize max dest range copied with one server-side copy (S3 part copy limit is 5GB)
+const maxDeltaCopySize = 4 * 1024 * 1024 * 1024
+
+//deltaAssembler reassembles dest object server-side from existing dest object ranges and uploaded blocks
+type deltaAssembler interface {
+	//Copy copies existing dest object range
+	Copy(ctx context.Context, offset, size int64) error
+	//Upload uploads changed block
+	Upload(ctx context.Context, data []byte) error
+	//Complete replaces dest object with assembled object
+	Complete(ctx context.Context) error
+	//Abort discards assembled object
+	Abort(ctx context.Context)
+}
+
+//deltaAssemblers server-side assembler providers by dest scheme
+var deltaAssemblers = map[string]func(ctx context.Context, URL string, dest storage.Object) (deltaAssembler, error){
+	s3.Scheme: newS3Assembler,
+}
+
+//deltaTransfer uploads only changed blocks, unchanged blocks are copied server-side from existing dest object;
+//without valid dest signature or server-side assembly support the whole content is uploaded, block signatures are stored either way
+func (s *service) deltaTransfer(ctx context.Context, transfer *Transfer, reader io.Reader, options []storage.Option, response *contract.Response) error {
+	delta := transfer.Resource.Delta
+	destURL := transfer.Dest.URL
+	signatureURL := delta.SignatureURL(destURL)
+	stats := &contract.DeltaStats{}
+	response.Delta = stats
+	var blocks []string
+	var err error
+	dest, signature := s.loadSignature(ctx, signatureURL, destURL, delta, options)
+	newAssembler, ok := deltaAssemblers[url.Scheme(destURL, file.Scheme)]
+	if signature != nil && ok && transfer.Resource.Credentials == nil && transfer.Resource.CustomKey == nil {
+		var assembler deltaAssembler
+		if assembler, err = newAssembler(ctx, destURL, dest); err != nil {
+			return errors.Wrapf(err, "failed to start delta transfer %v", destURL)
+		}
+		if blocks, err = s.assembleDelta(ctx, assembler, signature, reader, delta, stats); err != nil {
+			assembler.Abort(ctx)
+			return errors.Wrapf(err, "failed to delta transfer %v", destURL)
+		}
+	} else {
+		writer, err := s.fs.NewWriter(ctx, destURL, file.DefaultFileOsMode, options...)
+		if err != nil {
+			return err
+		}
+		blocks, err = readBlocks(reader, delta, func(index int, offset int64, block []byte, digest string) error {
+			stats.Changed++
+			stats.Transferred += int64(len(block))
+			_, err := writer.Write(block)
+			return err
+		})
+		if err == nil {
+			err = writer.Close()
+		}
+		if err != nil {
+			_ = s.fs.Delete(ctx, destURL, options...)
+			return err
+		}
+	}
+	stats.Blocks = len(blocks)
+	size := stats.Transferred + stats.Reused
+	response.AddURL(destURL)
+	if dest, err = s.fs.Object(ctx, destURL, options...); err != nil {
+		return errors.Wrapf(err, "failed to load delta dest %v", destURL)
+	}
+	data, err := json.Marshal(&config.DeltaSignature{Version: destVersion(dest), Size: size, BlockSize: delta.BlockSize(), Algorithm: delta.DigestAlgorithm(), Blocks: blocks})
+	if err == nil {
+		err = s.fs.Upload(ctx, signatureURL, file.DefaultFileOsMode, bytes.NewReader(data), options...)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to upload delta signature %v", signatureURL)
+	}
+	return nil
+}
+
+//loadSignature returns dest object and its block signatures, signatures are only returned if they match current dest object version
+func (s *service) loadSignature(ctx context.Context, signatureURL, destURL string, delta *config.Delta, options []storage.Option) (storage.Object, *config.DeltaSignature) {
+	dest, _ := s.fs.Object(ctx, destURL, options...)
+	if dest == nil {
+		return nil, nil
+	}
+	data, err := s.fs.DownloadWithURL(ctx, signatureURL, options...)
+	if err != nil {
+		return dest, nil
+	}
+	signature := &config.DeltaSignature{}
+	if err = json.Unmarshal(data, signature); err != nil {
+		return dest, nil
+	}
+	if signature.Version != destVersion(dest) || signature.BlockSize != delta.BlockSize() || signature.DigestAlgorithm() != delta.DigestAlgorithm() {
+		return dest, nil //dest was modified, or block size or digest algorithm changed since the last delta transfer
+	}
+	return dest, signature
+}
+
+//assembleDelta uploads changed blocks and copies consecutive unchanged blocks with one server-side copy
+func (s *service) assembleDelta(ctx context.Context, assembler deltaAssembler, signature *config.DeltaSignature, reader io.Reader, delta *config.Delta, stats *contract.DeltaStats) ([]string, error) {
+	var copyOffset, copySize int64
+	flush := func() error {
+		if copySize == 0 {
+			return nil
+		}
+		err := assembler.Copy(ctx, copyOffset, copySize)
+		copySize = 0
+		return err
+	}
+	blocks, err := readBlocks(reader, delta, func(index int, offset int64, block []byte, digest string) error {
+		size := int64(len(block))
+		if !signature.Reusable(index, digest, size) {
+			stats.Changed++
+			stats.Transferred += size
+			if err := flush(); err != nil {
+				return err
+			}
+			return assembler.Upload(ctx, block)
+		}
+		stats.Reused += size
+		if copySize > 0 && copyOffset+copySize == offset && copySize+size <= maxDeltaCopySize {
+			copySize += size
+			return nil
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		copyOffset, copySize = offset, size
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err == nil {
+		err = assembler.Complete(ctx)
+	}
+	return blocks, err
+}
+
+//readBlocks reads content in fixed size blocks, it returns hex encoded block digests
+func readBlocks(reader io.Reader, delta *config.Delta, handler func(index int, offset int64, block []byte, digest string) error) ([]string, error) {
+	var blocks []string
+	buffer := make([]byte, delta.BlockSize())
+	hasher := digest.New(delta.DigestAlgorithm())
+	var offset int64
+	for index := 0; ; index++ {
+		n, err := io.ReadFull(reader, buffer)
+		if n > 0 {
+			hasher.Reset()
+			_, _ = hasher.Write(buffer[:n])
+			blocks = append(blocks, hex.EncodeToString(hasher.Sum(nil)))
+			if err := handler(index, offset, buffer[:n], blocks[index]); err != nil {
+				return nil, err
+			}
+			offset += int64(n)
+		}
+		switch err {
+		case nil:
+			continue
+		case io.EOF, io.ErrUnexpectedEOF:
+			return blocks, nil
+		default:
+			return nil, err
+		}
+	}
+}
+
+//destVersion returns dest object version: provider checksum, or modification time and size
+func destVersion(object storage.Object) string {
+	if checksum := objectChecksum(object); checksum != "" {
+		return checksum
+	}
+	return config.ObjectVersion(object.ModTime(), object.Size())
+}
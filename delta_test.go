@@ -0,0 +1,109 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/afs/mem"
+	"github.com/viant/afs/storage"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"testing"
+)
+
+//memAssembler assembles dest object from existing mem object ranges
+type memAssembler struct {
+	fs       afs.Service
+	URL      string
+	existing []byte
+	buffer   bytes.Buffer
+}
+
+func (a *memAssembler) Copy(ctx context.Context, offset, size int64) error {
+	a.buffer.Write(a.existing[offset : offset+size])
+	return nil
+}
+
+func (a *memAssembler) Upload(ctx context.Context, data []byte) error {
+	a.buffer.Write(data)
+	return nil
+}
+
+func (a *memAssembler) Complete(ctx context.Context) error {
+	return a.fs.Upload(ctx, a.URL, file.DefaultFileOsMode, &a.buffer)
+}
+
+func (a *memAssembler) Abort(ctx context.Context) {}
+
+func TestService_Delta(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/delta")
+	deltaAssemblers[mem.Scheme] = func(ctx context.Context, URL string, dest storage.Object) (deltaAssembler, error) {
+		existing, err := fs.DownloadWithURL(ctx, URL)
+		return &memAssembler{fs: fs, URL: URL, existing: existing}, err
+	}
+	defer delete(deltaAssemblers, mem.Scheme)
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/delta/data"}},
+				Dest: &config.Resource{
+					URL:   "mem://localhost/delta/dest",
+					Delta: &config.Delta{BlockSizeMb: 5},
+				},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	const block = 5 * 1024 * 1024
+	content := bytes.Repeat([]byte("0123456789abcdef"), (3*block+1024*1024)/16)
+	sourceURL := "mem://localhost/delta/data/large.bin"
+	destURL := "mem://localhost/delta/dest/delta/data/large.bin"
+
+	var useCases = []struct {
+		description string
+		update      func(content []byte) []byte
+		expect      contract.DeltaStats
+	}{
+		{
+			description: "initial full transfer",
+			update:      func(content []byte) []byte { return content },
+			expect:      contract.DeltaStats{Blocks: 4, Changed: 4, Transferred: int64(len(content))},
+		},
+		{
+			description: "second block changed",
+			update: func(content []byte) []byte {
+				content[block+10] = 'X'
+				return content
+			},
+			expect: contract.DeltaStats{Blocks: 4, Changed: 1, Transferred: block, Reused: int64(len(content)) - block},
+		},
+		{
+			description: "appended content",
+			update: func(content []byte) []byte {
+				return append(content, []byte("tail")...)
+			},
+			expect: contract.DeltaStats{Blocks: 4, Changed: 1, Transferred: 1024*1024 + 4, Reused: 3 * block},
+		},
+	}
+	for _, useCase := range useCases {
+		content = useCase.update(content)
+		_ = fs.Upload(ctx, sourceURL, 0644, bytes.NewReader(content))
+		response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+		if !assert.Equal(t, base.StatusOK, response.Status, useCase.description+" "+response.Error) || !assert.NotNil(t, response.Delta, useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.expect, *response.Delta, useCase.description)
+		actual, err := fs.DownloadWithURL(ctx, destURL)
+		assert.Nil(t, err, useCase.description)
+		assert.True(t, bytes.Equal(content, actual), useCase.description)
+	}
+}
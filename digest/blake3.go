@@ -0,0 +1,206 @@
+package digest
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+const (
+	blake3BlockLen   = 64
+	blake3ChunkLen   = 1024
+	blake3ChunkStart = 1 << 0
+	blake3ChunkEnd   = 1 << 1
+	blake3Parent     = 1 << 2
+	blake3Root       = 1 << 3
+)
+
+var blake3IV = [8]uint32{0x6A09E667, 0xBB67AE85, 0x3C6EF372, 0xA54FF53A, 0x510E527F, 0x9B05688C, 0x1F83D9AB, 0x5BE0CD19}
+
+var blake3Permutation = [16]int{2, 6, 3, 10, 7, 0, 4, 13, 1, 11, 12, 5, 9, 14, 15, 8}
+
+//blake3 represents streaming BLAKE3 hash with 32 byte output
+type blake3 struct {
+	chunk   blake3Chunk
+	cvStack [][8]uint32
+}
+
+//blake3Chunk represents current chunk state
+type blake3Chunk struct {
+	cv         [8]uint32
+	counter    uint64
+	block      [blake3BlockLen]byte
+	blockLen   int
+	compressed int
+}
+
+//blake3Output represents compression input that is either chained or extended as root output
+type blake3Output struct {
+	cv       [8]uint32
+	block    [16]uint32
+	counter  uint64
+	blockLen uint32
+	flags    uint32
+}
+
+//NewBLAKE3 returns BLAKE3 hash
+func NewBLAKE3() hash.Hash {
+	result := &blake3{}
+	result.Reset()
+	return result
+}
+
+func (b *blake3) Reset() {
+	b.chunk = blake3Chunk{cv: blake3IV}
+	b.cvStack = b.cvStack[:0]
+}
+
+func (b *blake3) Size() int {
+	return 32
+}
+
+func (b *blake3) BlockSize() int {
+	return blake3BlockLen
+}
+
+func (b *blake3) Write(data []byte) (int, error) {
+	n := len(data)
+	for len(data) > 0 {
+		if b.chunk.len() == blake3ChunkLen {
+			cv := b.chunk.output().chainingValue()
+			total := b.chunk.counter + 1
+			b.pushChunk(cv, total)
+			b.chunk = blake3Chunk{cv: blake3IV, counter: total}
+		}
+		take := blake3ChunkLen - b.chunk.len()
+		if take > len(data) {
+			take = len(data)
+		}
+		b.chunk.update(data[:take])
+		data = data[take:]
+	}
+	return n, nil
+}
+
+//pushChunk merges completed subtrees, the number of trailing zero bits of total chunks is the number of merges
+func (b *blake3) pushChunk(cv [8]uint32, total uint64) {
+	for total&1 == 0 {
+		left := b.cvStack[len(b.cvStack)-1]
+		b.cvStack = b.cvStack[:len(b.cvStack)-1]
+		cv = blake3ParentOutput(left, cv).chainingValue()
+		total >>= 1
+	}
+	b.cvStack = append(b.cvStack, cv)
+}
+
+func (b *blake3) Sum(in []byte) []byte {
+	output := b.chunk.output()
+	for i := len(b.cvStack) - 1; i >= 0; i-- {
+		output = blake3ParentOutput(b.cvStack[i], output.chainingValue())
+	}
+	words := blake3Compress(&output.cv, &output.block, 0, output.blockLen, output.flags|blake3Root)
+	var sum [32]byte
+	for i := 0; i < 8; i++ {
+		binary.LittleEndian.PutUint32(sum[4*i:], words[i])
+	}
+	return append(in, sum[:]...)
+}
+
+func (c *blake3Chunk) len() int {
+	return blake3BlockLen*c.compressed + c.blockLen
+}
+
+func (c *blake3Chunk) startFlag() uint32 {
+	if c.compressed == 0 {
+		return blake3ChunkStart
+	}
+	return 0
+}
+
+func (c *blake3Chunk) update(data []byte) {
+	for len(data) > 0 {
+		if c.blockLen == blake3BlockLen {
+			block := blake3Words(c.block[:])
+			words := blake3Compress(&c.cv, &block, c.counter, blake3BlockLen, c.startFlag())
+			copy(c.cv[:], words[:8])
+			c.compressed++
+			c.block = [blake3BlockLen]byte{}
+			c.blockLen = 0
+		}
+		copied := copy(c.block[c.blockLen:], data)
+		c.blockLen += copied
+		data = data[copied:]
+	}
+}
+
+func (c *blake3Chunk) output() *blake3Output {
+	return &blake3Output{
+		cv:       c.cv,
+		block:    blake3Words(c.block[:]),
+		counter:  c.counter,
+		blockLen: uint32(c.blockLen),
+		flags:    c.startFlag() | blake3ChunkEnd,
+	}
+}
+
+func (o *blake3Output) chainingValue() [8]uint32 {
+	var result [8]uint32
+	words := blake3Compress(&o.cv, &o.block, o.counter, o.blockLen, o.flags)
+	copy(result[:], words[:8])
+	return result
+}
+
+func blake3ParentOutput(left, right [8]uint32) *blake3Output {
+	output := &blake3Output{cv: blake3IV, blockLen: blake3BlockLen, flags: blake3Parent}
+	copy(output.block[:8], left[:])
+	copy(output.block[8:], right[:])
+	return output
+}
+
+func blake3Words(block []byte) [16]uint32 {
+	var result [16]uint32
+	for i := range result {
+		result[i] = binary.LittleEndian.Uint32(block[4*i:])
+	}
+	return result
+}
+
+func blake3Compress(cv *[8]uint32, block *[16]uint32, counter uint64, blockLen, flags uint32) [16]uint32 {
+	state := [16]uint32{
+		cv[0], cv[1], cv[2], cv[3], cv[4], cv[5], cv[6], cv[7],
+		blake3IV[0], blake3IV[1], blake3IV[2], blake3IV[3],
+		uint32(counter), uint32(counter >> 32), blockLen, flags,
+	}
+	m := *block
+	for round := 0; round < 7; round++ {
+		blake3G(&state, 0, 4, 8, 12, m[0], m[1])
+		blake3G(&state, 1, 5, 9, 13, m[2], m[3])
+		blake3G(&state, 2, 6, 10, 14, m[4], m[5])
+		blake3G(&state, 3, 7, 11, 15, m[6], m[7])
+		blake3G(&state, 0, 5, 10, 15, m[8], m[9])
+		blake3G(&state, 1, 6, 11, 12, m[10], m[11])
+		blake3G(&state, 2, 7, 8, 13, m[12], m[13])
+		blake3G(&state, 3, 4, 9, 14, m[14], m[15])
+		var permuted [16]uint32
+		for i, index := range blake3Permutation {
+			permuted[i] = m[index]
+		}
+		m = permuted
+	}
+	for i := 0; i < 8; i++ {
+		state[i] ^= state[i+8]
+		state[i+8] ^= cv[i]
+	}
+	return state
+}
+
+func blake3G(state *[16]uint32, a, b, c, d int, mx, my uint32) {
+	state[a] += state[b] + mx
+	state[d] = bits.RotateLeft32(state[d]^state[a], -16)
+	state[c] += state[d]
+	state[b] = bits.RotateLeft32(state[b]^state[c], -12)
+	state[a] += state[b] + my
+	state[d] = bits.RotateLeft32(state[d]^state[a], -8)
+	state[c] += state[d]
+	state[b] = bits.RotateLeft32(state[b]^state[c], -7)
+}
@@ -0,0 +1,74 @@
+package digest
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+const (
+	//MD5 md5 digest, matches provider native GCS md5 and single part S3 ETag
+	MD5 = "md5"
+	//SHA1 sha1 digest
+	SHA1 = "sha1"
+	//SHA256 sha256 digest
+	SHA256 = "sha256"
+	//SHA512 sha512 digest
+	SHA512 = "sha512"
+	//CRC32 crc32 (IEEE) checksum
+	CRC32 = "crc32"
+	//CRC32C crc32 (Castagnoli) checksum, matches provider native GCS crc32c, hardware accelerated
+	CRC32C = "crc32c"
+	//XXHash64 xxHash 64 bit non-cryptographic hash, fastest internal verification digest
+	XXHash64 = "xxhash64"
+	//BLAKE3 blake3 cryptographic hash, faster than md5 and sha256
+	BLAKE3 = "blake3"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+//New returns hash for supplied algorithm or nil if algorithm is not supported
+func New(algorithm string) hash.Hash {
+	switch algorithm {
+	case MD5:
+		return md5.New()
+	case SHA1:
+		return sha1.New()
+	case SHA256:
+		return sha256.New()
+	case SHA512:
+		return sha512.New()
+	case CRC32:
+		return crc32.NewIEEE()
+	case CRC32C:
+		return crc32.New(crc32cTable)
+	case XXHash64:
+		return NewXXHash64()
+	case BLAKE3:
+		return NewBLAKE3()
+	}
+	return nil
+}
+
+//IsSupported returns true if algorithm is supported
+func IsSupported(algorithm string) bool {
+	return New(algorithm) != nil
+}
+
+//Compute returns hex encoded reader content digest
+func Compute(algorithm string, reader io.Reader) (string, error) {
+	hasher := New(algorithm)
+	if hasher == nil {
+		return "", errors.Errorf("unsupported digest algorithm: %v", algorithm)
+	}
+	if _, err := io.Copy(hasher, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
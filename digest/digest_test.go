@@ -0,0 +1,67 @@
+package digest
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCompute(t *testing.T) {
+	var useCases = []struct {
+		description string
+		algorithm   string
+		input       []byte
+		expect      string
+		hasError    bool
+	}{
+		{description: "md5", algorithm: MD5, input: []byte("abc"), expect: "900150983cd24fb0d6963f7d28e17f72"},
+		{description: "crc32c", algorithm: CRC32C, input: []byte("123456789"), expect: "e3069283"},
+		{description: "xxhash64 empty", algorithm: XXHash64, input: []byte(""), expect: "ef46db3751d8e999"},
+		{description: "xxhash64 short", algorithm: XXHash64, input: []byte("abc"), expect: "44bc2cf5ad770999"},
+		{description: "xxhash64 stripes", algorithm: XXHash64, input: []byte("Nobody inspects the spammish repetition"), expect: "fbcea83c8a378bf1"},
+		{description: "blake3 empty", algorithm: BLAKE3, input: []byte(""), expect: "af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262"},
+		{description: "blake3 short", algorithm: BLAKE3, input: []byte("abc"), expect: "6437b3ac38465133ffb63b75273a8db548c558465d79db03fd359c6cd5bd9d85"},
+		{description: "blake3 one chunk", algorithm: BLAKE3, input: testInput(1024), expect: "42214739f095a406f3fc83deb889744ac00df831c10daa55189b5d121c855af7"},
+		{description: "blake3 two chunks", algorithm: BLAKE3, input: testInput(1025), expect: "d00278ae47eb27b34faecf67b4fe263f82d5412916c1ffd97c8cb7fb814b8444"},
+		{description: "unsupported", algorithm: "adler32", input: []byte("abc"), hasError: true},
+	}
+	for _, useCase := range useCases {
+		actual, err := Compute(useCase.algorithm, bytes.NewReader(useCase.input))
+		if useCase.hasError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.expect, actual, useCase.description)
+	}
+}
+
+func TestNew_Streaming(t *testing.T) {
+	input := testInput(102400)
+	for _, algorithm := range []string{XXHash64, BLAKE3} {
+		expect := New(algorithm)
+		_, _ = expect.Write(input)
+		actual := New(algorithm)
+		for offset, size := 0, 1; offset < len(input); offset, size = offset+size, size*3%1031+1 {
+			end := offset + size
+			if end > len(input) {
+				end = len(input)
+			}
+			_, _ = actual.Write(input[offset:end])
+		}
+		assert.Equal(t, expect.Sum(nil), actual.Sum(nil), algorithm)
+		actual.Reset()
+		assert.Equal(t, New(algorithm).Sum(nil), actual.Sum(nil), algorithm)
+	}
+}
+
+//testInput returns BLAKE3 test vector input: repeating 0..250 byte sequence
+func testInput(size int) []byte {
+	result := make([]byte, size)
+	for i := range result {
+		result[i] = byte(i % 251)
+	}
+	return result
+}
@@ -0,0 +1,126 @@
+package digest
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+)
+
+//primes are variables so that seed arithmetic wraps around
+var (
+	xxPrime1 uint64 = 11400714785074694791
+	xxPrime2 uint64 = 14029467366897019727
+	xxPrime3 uint64 = 1609587929392839161
+	xxPrime4 uint64 = 9650029242287828579
+	xxPrime5 uint64 = 2870177450012600261
+)
+
+//xxHash64 represents streaming XXH64 (seed 0) non-cryptographic hash
+type xxHash64 struct {
+	v1, v2, v3, v4 uint64
+	total          uint64
+	buffer         [32]byte
+	buffered       int
+}
+
+//NewXXHash64 returns XXH64 hash with zero seed
+func NewXXHash64() hash.Hash64 {
+	result := &xxHash64{}
+	result.Reset()
+	return result
+}
+
+func (x *xxHash64) Reset() {
+	x.v1 = xxPrime1 + xxPrime2
+	x.v2 = xxPrime2
+	x.v3 = 0
+	x.v4 = -xxPrime1
+	x.total = 0
+	x.buffered = 0
+}
+
+func (x *xxHash64) Size() int {
+	return 8
+}
+
+func (x *xxHash64) BlockSize() int {
+	return 32
+}
+
+func (x *xxHash64) Write(data []byte) (int, error) {
+	n := len(data)
+	x.total += uint64(n)
+	if x.buffered > 0 {
+		copied := copy(x.buffer[x.buffered:], data)
+		x.buffered += copied
+		data = data[copied:]
+		if x.buffered < len(x.buffer) {
+			return n, nil
+		}
+		x.stripe(x.buffer[:])
+		x.buffered = 0
+	}
+	for ; len(data) >= 32; data = data[32:] {
+		x.stripe(data)
+	}
+	x.buffered = copy(x.buffer[:], data)
+	return n, nil
+}
+
+func (x *xxHash64) stripe(data []byte) {
+	x.v1 = xxRound(x.v1, binary.LittleEndian.Uint64(data[0:8]))
+	x.v2 = xxRound(x.v2, binary.LittleEndian.Uint64(data[8:16]))
+	x.v3 = xxRound(x.v3, binary.LittleEndian.Uint64(data[16:24]))
+	x.v4 = xxRound(x.v4, binary.LittleEndian.Uint64(data[24:32]))
+}
+
+func (x *xxHash64) Sum64() uint64 {
+	var h uint64
+	if x.total >= 32 {
+		h = bits.RotateLeft64(x.v1, 1) + bits.RotateLeft64(x.v2, 7) + bits.RotateLeft64(x.v3, 12) + bits.RotateLeft64(x.v4, 18)
+		h = xxMergeRound(h, x.v1)
+		h = xxMergeRound(h, x.v2)
+		h = xxMergeRound(h, x.v3)
+		h = xxMergeRound(h, x.v4)
+	} else {
+		h = x.v3 + xxPrime5
+	}
+	h += x.total
+	data := x.buffer[:x.buffered]
+	for ; len(data) >= 8; data = data[8:] {
+		h ^= xxRound(0, binary.LittleEndian.Uint64(data))
+		h = bits.RotateLeft64(h, 27)*xxPrime1 + xxPrime4
+	}
+	if len(data) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(data)) * xxPrime1
+		h = bits.RotateLeft64(h, 23)*xxPrime2 + xxPrime3
+		data = data[4:]
+	}
+	for _, b := range data {
+		h ^= uint64(b) * xxPrime5
+		h = bits.RotateLeft64(h, 11) * xxPrime1
+	}
+	h ^= h >> 33
+	h *= xxPrime2
+	h ^= h >> 29
+	h *= xxPrime3
+	h ^= h >> 32
+	return h
+}
+
+func (x *xxHash64) Sum(b []byte) []byte {
+	var sum [8]byte
+	binary.BigEndian.PutUint64(sum[:], x.Sum64())
+	return append(b, sum[:]...)
+}
+
+func xxRound(acc, input uint64) uint64 {
+	acc += input * xxPrime2
+	acc = bits.RotateLeft64(acc, 31)
+	return acc * xxPrime1
+}
+
+func xxMergeRound(acc, value uint64) uint64 {
+	acc ^= xxRound(0, value)
+	return acc*xxPrime1 + xxPrime4
+}
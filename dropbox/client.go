@@ -0,0 +1,134 @@
+package dropbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+const maxRetries = 3
+
+var (
+	apiURL     = "https://api.dropboxapi.com/2"
+	contentURL = "https://content.dropboxapi.com/2"
+	tokenURL   = "https://api.dropboxapi.com/oauth2/token"
+)
+
+//Error represents Dropbox API error
+type Error struct {
+	StatusCode int
+	Summary    string `json:"error_summary"`
+}
+
+//Error returns error message
+func (e *Error) Error() string {
+	return fmt.Sprintf("dropbox API error: %v %v", e.StatusCode, e.Summary)
+}
+
+//IsNotFound returns true if error is Dropbox not_found error
+func IsNotFound(err error) bool {
+	apiError, ok := errors.Cause(err).(*Error)
+	return ok && strings.Contains(apiError.Summary, "not_found")
+}
+
+type client struct {
+	credentials *Credentials
+	http        *http.Client
+}
+
+//rpc calls RPC endpoint with JSON argument
+func (c *client) rpc(ctx context.Context, endpoint string, arg, result interface{}) error {
+	body, err := json.Marshal(arg)
+	if err != nil {
+		return err
+	}
+	response, err := c.do(ctx, apiURL+endpoint, "application/json", nil, body)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+//content calls content endpoint with argument passed in Dropbox-API-Arg header
+func (c *client) content(ctx context.Context, endpoint string, arg interface{}, body []byte) (*http.Response, error) {
+	return c.do(ctx, contentURL+endpoint, "application/octet-stream", arg, body)
+}
+
+//do sends request, rate limited requests are retried after server advised delay
+func (c *client) do(ctx context.Context, URL, contentType string, arg interface{}, body []byte) (*http.Response, error) {
+	token, err := c.credentials.TokenSource().Token()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to refresh dropbox access token")
+	}
+	for attempt := 0; ; attempt++ {
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, URL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Authorization", "Bearer "+token.AccessToken)
+		if body != nil {
+			request.Header.Set("Content-Type", contentType)
+		}
+		if arg != nil {
+			encoded, err := headerArg(arg)
+			if err != nil {
+				return nil, err
+			}
+			request.Header.Set("Dropbox-API-Arg", encoded)
+		}
+		response, err := c.http.Do(request)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to call %v", URL)
+		}
+		if response.StatusCode == http.StatusOK {
+			return response, nil
+		}
+		data, _ := ioutil.ReadAll(response.Body)
+		_ = response.Body.Close()
+		if (response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable) && attempt < maxRetries {
+			delay, _ := strconv.Atoi(response.Header.Get("Retry-After"))
+			select {
+			case <-time.After(time.Duration(delay+1) * time.Second):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			continue
+		}
+		apiError := &Error{StatusCode: response.StatusCode}
+		if json.Unmarshal(data, apiError) != nil || apiError.Summary == "" {
+			apiError.Summary = strings.TrimSpace(string(data))
+		}
+		return nil, apiError
+	}
+}
+
+//headerArg encodes header argument, non ASCII characters have to be escaped
+func headerArg(arg interface{}) (string, error) {
+	data, err := json.Marshal(arg)
+	if err != nil {
+		return "", err
+	}
+	builder := new(strings.Builder)
+	for _, r := range string(data) {
+		if r < 0x80 {
+			builder.WriteRune(r)
+			continue
+		}
+		for _, unit := range utf16.Encode([]rune{r}) {
+			fmt.Fprintf(builder, `\u%04x`, unit)
+		}
+	}
+	return builder.String(), nil
+}
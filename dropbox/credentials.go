@@ -0,0 +1,51 @@
+package dropbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"sync"
+)
+
+//tokenSources OAuth token sources keyed by client ID and refresh token, shared by managers
+var tokenSources = sync.Map{}
+
+//Credentials represents decrypted Dropbox app credentials with offline refresh token
+type Credentials struct {
+	ClientID     string
+	ClientSecret string `json:",omitempty"`
+	RefreshToken string
+}
+
+//TokenSource returns cached token source refreshing access token with refresh token
+func (c *Credentials) TokenSource() oauth2.TokenSource {
+	key := c.ClientID + "/" + c.RefreshToken
+	if source, ok := tokenSources.Load(key); ok {
+		return source.(oauth2.TokenSource)
+	}
+	config := &oauth2.Config{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		Endpoint:     oauth2.Endpoint{TokenURL: tokenURL, AuthStyle: oauth2.AuthStyleInParams},
+	}
+	source, _ := tokenSources.LoadOrStore(key, config.TokenSource(context.Background(), &oauth2.Token{RefreshToken: c.RefreshToken}))
+	return source.(oauth2.TokenSource)
+}
+
+//NewCredentials creates credentials from decrypted secret JSON
+func NewCredentials(secret []byte) (*Credentials, error) {
+	secret = bytes.TrimSpace(secret)
+	if len(secret) == 0 {
+		return nil, errors.New("dropbox credentials were empty")
+	}
+	credentials := &Credentials{}
+	if err := json.Unmarshal(secret, credentials); err != nil {
+		return nil, errors.Wrap(err, "failed to decode dropbox credentials")
+	}
+	if credentials.ClientID == "" || credentials.RefreshToken == "" {
+		return nil, errors.New("dropbox credentials require ClientID and RefreshToken")
+	}
+	return credentials, nil
+}
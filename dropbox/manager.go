@@ -0,0 +1,85 @@
+package dropbox
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/afs/base"
+	"github.com/viant/afs/object"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"net/http"
+	"strings"
+)
+
+//Scheme dropbox URL scheme, URL host is an account alias, i.e. dropbox://partners/inbound/file.csv
+const Scheme = "dropbox"
+
+type manager struct {
+	*base.Manager
+}
+
+func (m *manager) provider(ctx context.Context, baseURL string, options ...storage.Option) (storage.Storager, error) {
+	credentials := &Credentials{}
+	if _, ok := option.Assign(options, &credentials); !ok {
+		return nil, errors.Errorf("dropbox credentials were empty: %v", baseURL)
+	}
+	return newStorager(credentials, options...), nil
+}
+
+//Changes returns files changed since cursor with next cursor, empty or expired cursor lists all files under URL recursively
+func Changes(ctx context.Context, URL, cursor string, options ...storage.Option) ([]storage.Object, string, error) {
+	credentials := &Credentials{}
+	if _, ok := option.Assign(options, &credentials); !ok {
+		return nil, "", errors.Errorf("dropbox credentials were empty: %v", URL)
+	}
+	client := newStorager(credentials, options...)
+	baseURL, location := url.Base(URL, Scheme)
+	page := &listing{}
+	var err error
+	if cursor != "" {
+		err = client.rpc(ctx, "/files/list_folder/continue", map[string]string{"cursor": cursor}, page)
+		if apiError, ok := errors.Cause(err).(*Error); ok && apiError.StatusCode == http.StatusConflict && strings.Contains(apiError.Summary, "reset") {
+			cursor = ""
+		}
+	}
+	if cursor == "" {
+		err = client.rpc(ctx, "/files/list_folder", map[string]interface{}{"path": dropboxPath(location), "recursive": true, "limit": 2000}, page)
+	}
+	var result = make([]storage.Object, 0)
+	for err == nil {
+		for _, entry := range page.Entries {
+			if entry.Tag == "file" {
+				result = append(result, object.New(url.Join(baseURL, entry.PathDisplay), entry.info(), nil))
+			}
+		}
+		if !page.HasMore {
+			return result, page.Cursor, nil
+		}
+		next := &listing{}
+		err = client.rpc(ctx, "/files/list_folder/continue", map[string]string{"cursor": page.Cursor}, next)
+		page = next
+	}
+	return nil, "", err
+}
+
+func newManager(options ...storage.Option) *manager {
+	result := &manager{}
+	result.Manager = base.New(result, Scheme, result.provider, options)
+	return result
+}
+
+//New creates Dropbox manager
+func New(options ...storage.Option) storage.Manager {
+	return newManager(options...)
+}
+
+//Provider returns Dropbox manager
+func Provider(options ...storage.Option) (storage.Manager, error) {
+	return New(options...), nil
+}
+
+func init() {
+	afs.GetRegistry().Register(Scheme, Provider)
+}
@@ -0,0 +1,231 @@
+package dropbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	ahttp "github.com/viant/afs/http"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+//ChunkSize upload session chunk size, smaller files are uploaded with a single request
+var ChunkSize = 8 * 1024 * 1024
+
+type metadata struct {
+	Tag            string    `json:".tag"`
+	Name           string    `json:"name"`
+	PathDisplay    string    `json:"path_display"`
+	Size           int64     `json:"size"`
+	ServerModified time.Time `json:"server_modified"`
+}
+
+func (m *metadata) info() os.FileInfo {
+	if m.Tag == "folder" {
+		return file.NewInfo(m.Name, 0, file.DefaultDirOsMode, time.Time{}, true)
+	}
+	return file.NewInfo(m.Name, m.Size, file.DefaultFileOsMode, m.ServerModified, false)
+}
+
+type listing struct {
+	Entries []*metadata `json:"entries"`
+	Cursor  string      `json:"cursor"`
+	HasMore bool        `json:"has_more"`
+}
+
+type cursor struct {
+	SessionID string `json:"session_id"`
+	Offset    int64  `json:"offset"`
+}
+
+type commit struct {
+	Path string `json:"path"`
+	Mode string `json:"mode"`
+	Mute bool   `json:"mute"`
+}
+
+type storager struct {
+	client
+}
+
+//dropboxPath returns API path, root folder is represented by empty path
+func dropboxPath(location string) string {
+	location = path.Clean("/" + location)
+	if location == "/" {
+		return ""
+	}
+	return location
+}
+
+//Exists returns true if location exists
+func (s *storager) Exists(ctx context.Context, location string, options ...storage.Option) (bool, error) {
+	_, err := s.Get(ctx, location, options...)
+	if IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+//Get returns file info for supplied location
+func (s *storager) Get(ctx context.Context, location string, options ...storage.Option) (os.FileInfo, error) {
+	entry, err := s.metadata(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	return entry.info(), nil
+}
+
+func (s *storager) metadata(ctx context.Context, location string) (*metadata, error) {
+	dropboxPath := dropboxPath(location)
+	if dropboxPath == "" {
+		return &metadata{Tag: "folder"}, nil
+	}
+	entry := &metadata{}
+	err := s.rpc(ctx, "/files/get_metadata", map[string]string{"path": dropboxPath}, entry)
+	return entry, err
+}
+
+//List lists location, folder itself is returned as the first element
+func (s *storager) List(ctx context.Context, location string, options ...storage.Option) ([]os.FileInfo, error) {
+	entry, err := s.metadata(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+	var result = []os.FileInfo{entry.info()}
+	if entry.Tag != "folder" {
+		return result, nil
+	}
+	page := &listing{}
+	if err = s.rpc(ctx, "/files/list_folder", map[string]interface{}{"path": dropboxPath(location), "limit": 2000}, page); err != nil {
+		return nil, err
+	}
+	for {
+		for _, entry := range page.Entries {
+			if entry.Tag != "deleted" {
+				result = append(result, entry.info())
+			}
+		}
+		if !page.HasMore {
+			return result, nil
+		}
+		next := &listing{}
+		if err = s.rpc(ctx, "/files/list_folder/continue", map[string]string{"cursor": page.Cursor}, next); err != nil {
+			return nil, err
+		}
+		page = next
+	}
+}
+
+//Open returns file content reader
+func (s *storager) Open(ctx context.Context, location string, options ...storage.Option) (io.ReadCloser, error) {
+	response, err := s.content(ctx, "/files/download", map[string]string{"path": dropboxPath(location)}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return response.Body, nil
+}
+
+//Upload uploads content, content larger than chunk size is uploaded with upload session
+func (s *storager) Upload(ctx context.Context, destination string, mode os.FileMode, reader io.Reader, options ...storage.Option) error {
+	target := &commit{Path: dropboxPath(destination), Mode: "overwrite", Mute: true}
+	chunk, err := readChunk(reader)
+	if err != nil {
+		return err
+	}
+	if len(chunk) < ChunkSize {
+		return s.send(ctx, "/files/upload", target, chunk, nil)
+	}
+	session := &cursor{}
+	if err = s.send(ctx, "/files/upload_session/start", map[string]bool{"close": false}, chunk, session); err != nil {
+		return errors.Wrapf(err, "failed to start upload session: %v", destination)
+	}
+	session.Offset = int64(len(chunk))
+	for {
+		if chunk, err = readChunk(reader); err != nil {
+			return err
+		}
+		if len(chunk) < ChunkSize {
+			arg := map[string]interface{}{"cursor": session, "commit": target}
+			return s.send(ctx, "/files/upload_session/finish", arg, chunk, nil)
+		}
+		if err = s.send(ctx, "/files/upload_session/append_v2", map[string]interface{}{"cursor": session, "close": false}, chunk, nil); err != nil {
+			return errors.Wrapf(err, "failed to append upload session: %v", destination)
+		}
+		session.Offset += int64(len(chunk))
+	}
+}
+
+func (s *storager) send(ctx context.Context, endpoint string, arg interface{}, chunk []byte, result interface{}) error {
+	response, err := s.content(ctx, endpoint, arg, chunk)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+//readChunk reads up to chunk size, shorter chunk indicates end of data
+func readChunk(reader io.Reader) ([]byte, error) {
+	chunk := make([]byte, ChunkSize)
+	read, err := io.ReadFull(reader, chunk)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return chunk[:read], err
+}
+
+//Create creates folder or file
+func (s *storager) Create(ctx context.Context, destination string, mode os.FileMode, reader io.Reader, isDir bool, options ...storage.Option) error {
+	if !isDir {
+		if reader == nil {
+			reader = bytes.NewReader(nil)
+		}
+		return s.Upload(ctx, destination, mode, reader, options...)
+	}
+	dropboxPath := dropboxPath(destination)
+	if dropboxPath == "" {
+		return nil
+	}
+	err := s.rpc(ctx, "/files/create_folder_v2", map[string]interface{}{"path": dropboxPath, "autorename": false}, nil)
+	if apiError, ok := errors.Cause(err).(*Error); ok && apiError.StatusCode == http.StatusConflict && strings.Contains(apiError.Summary, "path/conflict/folder") {
+		return nil
+	}
+	return err
+}
+
+//Delete deletes location
+func (s *storager) Delete(ctx context.Context, location string, options ...storage.Option) error {
+	err := s.rpc(ctx, "/files/delete_v2", map[string]string{"path": dropboxPath(location)}, nil)
+	if IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+//Close closes storager
+func (s *storager) Close() error {
+	return nil
+}
+
+//newStorager creates storager, http client provider option supplies resource proxy and TLS transport
+func newStorager(credentials *Credentials, options ...storage.Option) *storager {
+	httpClient := &http.Client{Timeout: 30 * time.Minute}
+	var provider ahttp.ClientProvider
+	if _, ok := option.Assign(options, &provider); ok {
+		if provided, err := provider(Scheme, options...); err == nil && provided != nil {
+			httpClient.Transport = provided.Transport
+		}
+	}
+	return &storager{client: client{credentials: credentials, http: httpClient}}
+}
@@ -0,0 +1,172 @@
+package dropbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//fakeDropbox emulates subset of Dropbox API used by storager
+type fakeDropbox struct {
+	mux      sync.Mutex
+	files    map[string]string
+	sessions map[string]*bytes.Buffer
+	calls    []string
+}
+
+func (f *fakeDropbox) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if request.URL.Path == "/oauth2/token" {
+		_ = request.ParseForm()
+		if request.Form.Get("refresh_token") != "refresh" {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`{"access_token":"access","token_type":"bearer","expires_in":14400}`))
+		return
+	}
+	if request.Header.Get("Authorization") != "Bearer access" {
+		writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	endpoint := request.URL.Path[strings.Index(request.URL.Path, "/files/"):]
+	f.calls = append(f.calls, endpoint)
+	arg := map[string]interface{}{}
+	if header := request.Header.Get("Dropbox-API-Arg"); header != "" {
+		_ = json.Unmarshal([]byte(header), &arg)
+	} else {
+		_ = json.NewDecoder(request.Body).Decode(&arg)
+	}
+	body, _ := ioutil.ReadAll(request.Body)
+	reply := func(value interface{}) {
+		_ = json.NewEncoder(writer).Encode(value)
+	}
+	notFound := func() {
+		writer.WriteHeader(http.StatusConflict)
+		reply(map[string]string{"error_summary": "path/not_found/.."})
+	}
+	entry := func(name string) map[string]interface{} {
+		return map[string]interface{}{".tag": "file", "name": name[strings.LastIndex(name, "/")+1:], "path_display": name, "size": len(f.files[name]), "server_modified": time.Now().UTC().Format(time.RFC3339)}
+	}
+	switch endpoint {
+	case "/files/get_metadata":
+		name := arg["path"].(string)
+		if _, ok := f.files[name]; ok {
+			reply(entry(name))
+			return
+		}
+		for candidate := range f.files {
+			if strings.HasPrefix(candidate, name+"/") {
+				reply(map[string]interface{}{".tag": "folder", "name": name[strings.LastIndex(name, "/")+1:], "path_display": name})
+				return
+			}
+		}
+		notFound()
+	case "/files/list_folder", "/files/list_folder/continue":
+		var entries []interface{}
+		if cursor, ok := arg["cursor"]; ok {
+			//cursor holds number of known files, files added later are changes
+			var known int
+			_, _ = fmt.Sscanf(cursor.(string), "files-%d", &known)
+			if known < len(f.files) {
+				entries = append(entries, entry("/inbound/c.csv"))
+			}
+		} else {
+			for name := range f.files {
+				if strings.HasPrefix(name, arg["path"].(string)+"/") {
+					entries = append(entries, entry(name))
+				}
+			}
+		}
+		reply(map[string]interface{}{"entries": entries, "cursor": fmt.Sprintf("files-%d", len(f.files)), "has_more": false})
+	case "/files/download":
+		data, ok := f.files[arg["path"].(string)]
+		if !ok {
+			notFound()
+			return
+		}
+		_, _ = writer.Write([]byte(data))
+	case "/files/upload":
+		f.files[arg["path"].(string)] = string(body)
+		reply(entry(arg["path"].(string)))
+	case "/files/upload_session/start":
+		ID := fmt.Sprintf("session-%d", len(f.sessions))
+		f.sessions[ID] = bytes.NewBuffer(body)
+		reply(map[string]string{"session_id": ID})
+	case "/files/upload_session/append_v2", "/files/upload_session/finish":
+		cursor := arg["cursor"].(map[string]interface{})
+		session := f.sessions[cursor["session_id"].(string)]
+		if int(cursor["offset"].(float64)) != session.Len() {
+			writer.WriteHeader(http.StatusConflict)
+			reply(map[string]string{"error_summary": "incorrect_offset/"})
+			return
+		}
+		session.Write(body)
+		if commit, ok := arg["commit"].(map[string]interface{}); ok {
+			f.files[commit["path"].(string)] = session.String()
+		}
+		reply(map[string]string{})
+	case "/files/create_folder_v2":
+		reply(map[string]string{})
+	case "/files/delete_v2":
+		delete(f.files, arg["path"].(string))
+		reply(map[string]string{})
+	}
+}
+
+func TestStorager(t *testing.T) {
+	dropbox := &fakeDropbox{files: map[string]string{"/inbound/a.csv": "a", "/inbound/b.csv": "b"}, sessions: map[string]*bytes.Buffer{}}
+	server := httptest.NewServer(dropbox)
+	defer server.Close()
+	apiURL, contentURL, tokenURL = server.URL+"/2", server.URL+"/2", server.URL+"/oauth2/token"
+	ChunkSize = 4
+	ctx := context.Background()
+	credentials, err := NewCredentials([]byte(`{"ClientID":"app","ClientSecret":"secret","RefreshToken":"refresh"}`))
+	if !assert.Nil(t, err) {
+		return
+	}
+	fs := afs.New()
+
+	objects, err := fs.List(ctx, "dropbox://partners/inbound", credentials)
+	if assert.Nil(t, err) && assert.Equal(t, 3, len(objects)) {
+		assert.True(t, objects[0].IsDir())
+	}
+	data, err := fs.DownloadWithURL(ctx, "dropbox://partners/inbound/a.csv", credentials)
+	assert.Nil(t, err)
+	assert.Equal(t, "a", string(data))
+
+	err = fs.Upload(ctx, "dropbox://partners/outbound/small.csv", 0644, strings.NewReader("abc"), credentials)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", dropbox.files["/outbound/small.csv"])
+	err = fs.Upload(ctx, "dropbox://partners/outbound/large.csv", 0644, strings.NewReader("0123456789"), credentials)
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789", dropbox.files["/outbound/large.csv"])
+	assert.Contains(t, dropbox.calls, "/files/upload_session/append_v2")
+
+	changed, cursor, err := Changes(ctx, "dropbox://partners/inbound", "", credentials)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(changed))
+	dropbox.files["/inbound/c.csv"] = "c"
+	changed, _, err = Changes(ctx, "dropbox://partners/inbound", cursor, credentials)
+	if assert.Nil(t, err) && assert.Equal(t, 1, len(changed)) {
+		assert.Equal(t, "dropbox://partners/inbound/c.csv", changed[0].URL())
+	}
+}
+
+func TestHeaderArg(t *testing.T) {
+	encoded, err := headerArg(map[string]string{"path": "/raport/żółw.csv"})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"path":"/raport/\u017c\u00f3\u0142w.csv"}`, encoded)
+}
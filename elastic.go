@@ -0,0 +1,157 @@
+package smirror
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/elastic"
+	"io"
+	"strings"
+	"time"
+)
+
+//maxElasticRecordSize max NDJSON record size
+const maxElasticRecordSize = 64 * 1024 * 1024
+
+//indexElasticsearch converts NDJSON or CSV records into bulk index requests
+func (s *service) indexElasticsearch(ctx context.Context, transfer *Transfer, response *contract.Response) error {
+	settings := transfer.Resource.Elasticsearch
+	var credentials *elastic.Credentials
+	if transfer.Resource.Credentials != nil {
+		var err error
+		if credentials, err = elastic.NewCredentials(transfer.Resource.Credentials.Auth); err != nil {
+			return base.NewCodedError(base.ErrorCodeAuth, err)
+		}
+	}
+	httpClient, err := s.httpClient(ctx, transfer.Resource)
+	if err != nil {
+		return err
+	}
+	client := elastic.New(transfer.Resource.URL, credentials, settings.MaxRetries, time.Duration(settings.BackoffMs)*time.Millisecond, httpClient)
+	reader, err := transfer.GetReader()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get reader for: %v", transfer.Resource.URL)
+	}
+	index := settings.ExpandIndex(response.TriggeredBy, transfer.partition, time.Now())
+	next := elasticNDJSONRecords(reader)
+	if settings.IsCSV(response.TriggeredBy) {
+		if next, err = elasticCSVRecords(reader, settings.Delimiter); err != nil {
+			return err
+		}
+	}
+	result := &elastic.Result{}
+	batch := make([]*elastic.Document, 0, settings.BatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		waited, err := s.limiter.Wait(ctx, transfer.Resource.URL)
+		response.AddThrottleTime(waited)
+		if err != nil {
+			return err
+		}
+		batchResult, err := client.Bulk(ctx, batch)
+		if err != nil {
+			s.limiter.Report(transfer.Resource.URL, err)
+		}
+		result.Indexed += batchResult.Indexed
+		result.Failed += batchResult.Failed
+		result.Errors = append(result.Errors, batchResult.Errors...)
+		batch = batch[:0]
+		return err
+	}
+	for err == nil {
+		var record json.RawMessage
+		if record, err = next(); err != nil {
+			break
+		}
+		document := &elastic.Document{Index: index, Source: record}
+		if settings.IDField != "" {
+			if document.ID, err = documentID(record, settings.IDField); err != nil {
+				break
+			}
+		}
+		if batch = append(batch, document); len(batch) >= settings.BatchSize {
+			err = flush()
+		}
+	}
+	if err == io.EOF {
+		err = flush()
+	}
+	response.AddDocumentCounts(result.Indexed, result.Failed)
+	if err != nil {
+		return err
+	}
+	if result.Failed > settings.MaxFailedDocs {
+		return errors.Errorf("failed to index %v document(s) into %v: %v", result.Failed, index, strings.Join(result.Errors, "; "))
+	}
+	return nil
+}
+
+func documentID(record json.RawMessage, field string) (string, error) {
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(record, &fields); err != nil {
+		return "", errors.Wrapf(err, "invalid JSON record: %s", record)
+	}
+	value, ok := fields[field]
+	if !ok || value == nil {
+		return "", errors.Errorf("document ID field %v was empty: %s", field, record)
+	}
+	if number, ok := value.(float64); ok {
+		return fmt.Sprintf("%v", int64(number)), nil
+	}
+	return fmt.Sprintf("%v", value), nil
+}
+
+func elasticNDJSONRecords(reader io.Reader) func() (json.RawMessage, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), maxElasticRecordSize)
+	return func() (json.RawMessage, error) {
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			if !json.Valid(line) {
+				return nil, errors.Errorf("invalid JSON record: %s", line)
+			}
+			return append(json.RawMessage{}, line...), nil
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+}
+
+func elasticCSVRecords(reader io.Reader, delimiter string) (func() (json.RawMessage, error), error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.Comma = []rune(delimiter)[0]
+	csvReader.FieldsPerRecord = -1
+	header, err := csvReader.Read()
+	if err == io.EOF {
+		return func() (json.RawMessage, error) { return nil, io.EOF }, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read CSV header")
+	}
+	return func() (json.RawMessage, error) {
+		record, err := csvReader.Read()
+		if err != nil {
+			return nil, err
+		}
+		document := make(map[string]string, len(header))
+		for i, value := range record {
+			if i < len(header) {
+				document[header[i]] = value
+			}
+		}
+		return json.Marshal(document)
+	}, nil
+}
@@ -0,0 +1,189 @@
+package elastic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//Credentials represents decrypted cluster credentials, either basic auth or API key
+type Credentials struct {
+	Username string `json:",omitempty"`
+	Password string `json:",omitempty"`
+	//APIKey base64 encoded id:api_key
+	APIKey string `json:",omitempty"`
+}
+
+//Document represents a document to index
+type Document struct {
+	Index  string
+	ID     string
+	Source json.RawMessage
+}
+
+//Result represents bulk indexing result
+type Result struct {
+	Indexed int
+	Failed  int
+	//Errors distinct failure reasons
+	Errors []string
+}
+
+func (r *Result) addError(reason string) {
+	for _, candidate := range r.Errors {
+		if candidate == reason {
+			return
+		}
+	}
+	r.Errors = append(r.Errors, reason)
+}
+
+//Client represents Elasticsearch/OpenSearch bulk API client
+type Client struct {
+	URL         string
+	credentials *Credentials
+	http        *http.Client
+	maxRetries  int
+	backoff     time.Duration
+}
+
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+//Bulk indexes documents, rejected (429) requests and documents are retried with exponential backoff
+func (c *Client) Bulk(ctx context.Context, documents []*Document) (*Result, error) {
+	result := &Result{}
+	pending := documents
+	backoff := c.backoff
+	for attempt := 0; len(pending) > 0; attempt++ {
+		response, status, err := c.bulk(ctx, pending)
+		if err != nil {
+			return result, err
+		}
+		var rejected []*Document
+		if status == http.StatusTooManyRequests {
+			rejected = pending
+		} else {
+			if len(response.Items) != len(pending) {
+				return result, errors.Errorf("unexpected bulk response items: %v, expected: %v", len(response.Items), len(pending))
+			}
+			for i, item := range response.Items {
+				for _, outcome := range item {
+					switch {
+					case outcome.Status/100 == 2:
+						result.Indexed++
+					case outcome.Status == http.StatusTooManyRequests:
+						rejected = append(rejected, pending[i])
+					default:
+						result.Failed++
+						if outcome.Error != nil {
+							result.addError(outcome.Error.Type + ": " + outcome.Error.Reason)
+						}
+					}
+				}
+			}
+		}
+		if len(rejected) == 0 {
+			break
+		}
+		if attempt >= c.maxRetries {
+			result.Failed += len(rejected)
+			result.addError(fmt.Sprintf("rejected after %v retries", c.maxRetries))
+			break
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+		backoff *= 2
+		pending = rejected
+	}
+	return result, nil
+}
+
+func (c *Client) bulk(ctx context.Context, documents []*Document) (*bulkResponse, int, error) {
+	body := new(bytes.Buffer)
+	encoder := json.NewEncoder(body)
+	for _, document := range documents {
+		action := map[string]string{"_index": document.Index}
+		if document.ID != "" {
+			action["_id"] = document.ID
+		}
+		if err := encoder.Encode(map[string]interface{}{"index": action}); err != nil {
+			return nil, 0, err
+		}
+		body.Write(document.Source)
+		body.WriteByte('\n')
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(c.URL, "/")+"/_bulk", body)
+	if err != nil {
+		return nil, 0, err
+	}
+	request.Header.Set("Content-Type", "application/x-ndjson")
+	if c.credentials != nil {
+		if c.credentials.APIKey != "" {
+			request.Header.Set("Authorization", "ApiKey "+c.credentials.APIKey)
+		} else if c.credentials.Username != "" {
+			request.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+		}
+	}
+	httpResponse, err := c.http.Do(request)
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to call %v/_bulk", c.URL)
+	}
+	defer httpResponse.Body.Close()
+	data, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	if httpResponse.StatusCode == http.StatusTooManyRequests {
+		return nil, httpResponse.StatusCode, nil
+	}
+	if httpResponse.StatusCode/100 != 2 {
+		return nil, httpResponse.StatusCode, fmt.Errorf("failed to call %v/_bulk: %v %s", c.URL, httpResponse.Status, data)
+	}
+	response := &bulkResponse{}
+	if err = json.Unmarshal(data, response); err != nil {
+		return nil, httpResponse.StatusCode, errors.Wrap(err, "failed to decode bulk response")
+	}
+	return response, httpResponse.StatusCode, nil
+}
+
+//NewCredentials creates credentials from decrypted secret JSON
+func NewCredentials(secret []byte) (*Credentials, error) {
+	secret = bytes.TrimSpace(secret)
+	if len(secret) == 0 {
+		return nil, errors.New("elasticsearch credentials were empty")
+	}
+	credentials := &Credentials{}
+	if err := json.Unmarshal(secret, credentials); err != nil {
+		return nil, errors.Wrap(err, "failed to decode elasticsearch credentials")
+	}
+	if credentials.APIKey == "" && credentials.Username == "" {
+		return nil, errors.New("elasticsearch credentials require APIKey or Username and Password")
+	}
+	return credentials, nil
+}
+
+//New creates a client
+func New(URL string, credentials *Credentials, maxRetries int, backoff time.Duration, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 5 * time.Minute}
+	}
+	return &Client{URL: URL, credentials: credentials, http: httpClient, maxRetries: maxRetries, backoff: backoff}
+}
@@ -0,0 +1,61 @@
+package elastic
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_Bulk(t *testing.T) {
+	calls := 0
+	var indexed []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		calls++
+		assert.Equal(t, "ApiKey key", request.Header.Get("Authorization"))
+		if calls == 1 {
+			writer.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		var items []string
+		scanner := bufio.NewScanner(request.Body)
+		for scanner.Scan() {
+			action := map[string]map[string]string{}
+			assert.Nil(t, json.Unmarshal(scanner.Bytes(), &action))
+			scanner.Scan()
+			switch ID := action["index"]["_id"]; {
+			case ID == "2" && calls == 2:
+				items = append(items, `{"index":{"status":429}}`)
+			case ID == "3":
+				items = append(items, `{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}`)
+			default:
+				indexed = append(indexed, ID)
+				items = append(items, `{"index":{"status":201}}`)
+			}
+		}
+		_, _ = writer.Write([]byte(`{"errors":true,"items":[` + strings.Join(items, ",") + `]}`))
+	}))
+	defer server.Close()
+	credentials, err := NewCredentials([]byte(`{"APIKey":"key"}`))
+	assert.Nil(t, err)
+	client := New(server.URL, credentials, 3, time.Millisecond, nil)
+	result, err := client.Bulk(context.Background(), []*Document{
+		{Index: "logs", ID: "1", Source: json.RawMessage(`{"a":1}`)},
+		{Index: "logs", ID: "2", Source: json.RawMessage(`{"a":2}`)},
+		{Index: "logs", ID: "3", Source: json.RawMessage(`{"a":"x"}`)},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []string{"1", "2"}, indexed)
+	assert.Equal(t, 2, result.Indexed)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, []string{"mapper_parsing_exception: failed to parse"}, result.Errors)
+
+	_, err = NewCredentials([]byte(`{}`))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,59 @@
+package smirror
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestService_Elasticsearch(t *testing.T) {
+	var lines []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		var items []string
+		scanner := bufio.NewScanner(request.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+			if len(lines)%2 == 0 {
+				items = append(items, `{"index":{"status":201}}`)
+			}
+		}
+		_, _ = writer.Write([]byte(`{"errors":false,"items":[` + strings.Join(items, ",") + `]}`))
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/elastic/data"}},
+				Dest: &config.Resource{
+					URL:           server.URL,
+					Elasticsearch: &config.Elasticsearch{Index: "orders-$name", IDField: "id", BatchSize: 1},
+				},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sourceURL := "mem://localhost/elastic/data/eu.csv"
+	_ = afs.New().Upload(ctx, sourceURL, 0644, strings.NewReader("id,name\n1,a\n2,b\n"))
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	if !assert.Equal(t, base.StatusOK, response.Status, response.Error) {
+		return
+	}
+	assert.Equal(t, 2, response.IndexedDocs)
+	assert.Equal(t, []string{
+		`{"index":{"_id":"1","_index":"orders-eu"}}`, `{"id":"1","name":"a"}`,
+		`{"index":{"_id":"2","_index":"orders-eu"}}`, `{"id":"2","name":"b"}`,
+	}, lines)
+}
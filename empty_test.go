@@ -0,0 +1,49 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/afs/option"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"testing"
+)
+
+func TestService_OnEmpty(t *testing.T) {
+	ctx := context.Background()
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source:     &config.Resource{Basic: matcher.Basic{Prefix: "/empty/data"}},
+				Dest:       &config.Resource{URL: "mem://localhost/empty/dest"},
+				OnEmpty:    config.EmptySkip,
+				AllowEmpty: true,
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	fs := afs.New()
+	placeholderURL := "mem://localhost/empty/data/folder_$folder$"
+	_ = fs.Upload(ctx, placeholderURL, 0644, strings.NewReader(""), option.NewEmpty(true))
+
+	response := service.Mirror(ctx, contract.NewRequest(placeholderURL))
+	assert.Equal(t, base.StatusEmpty, response.Status, response.Error)
+	exists, _ := fs.Exists(ctx, "mem://localhost/empty/dest/empty/data/folder_$folder$")
+	assert.False(t, exists)
+
+	cfg.Mirrors.Rules[0].OnEmpty = config.EmptyMirror
+	response = service.Mirror(ctx, contract.NewRequest(placeholderURL))
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+	exists, _ = fs.Exists(ctx, "mem://localhost/empty/dest/empty/data/folder_$folder$")
+	assert.True(t, exists)
+
+	assert.True(t, config.IsPlaceholder("s3://bucket/data/2020/", 0))
+	assert.False(t, config.IsPlaceholder("s3://bucket/data/2020/file.csv", 10))
+}
@@ -0,0 +1,145 @@
+package emulator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/mem"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"io/ioutil"
+	"strings"
+)
+
+//Harness represents local end to end rule emulation harness, it runs full mirror pipeline against mem:// or file:// storage
+type Harness struct {
+	Config  *smirror.Config
+	fs      afs.Service
+	service smirror.Service
+}
+
+//Event represents synthetic storage event
+type Event struct {
+	URL      string
+	Content  string
+	Compress bool
+}
+
+//Init initialises harness mirror service
+func (h *Harness) Init(ctx context.Context) (err error) {
+	if h.service, err = smirror.New(ctx, h.Config); err != nil {
+		return err
+	}
+	for _, rule := range h.Config.Mirrors.Snapshot() {
+		if rule.Dest == nil {
+			continue
+		}
+		if err = validateURL(rule.Dest.URL); err != nil {
+			return errors.Wrapf(err, "invalid rule %v dest", rule.Info.URL)
+		}
+	}
+	return nil
+}
+
+//Put uploads source asset, content is gzip compressed if compress flag is set
+func (h *Harness) Put(ctx context.Context, URL string, content []byte, compress bool) error {
+	if err := validateURL(URL); err != nil {
+		return err
+	}
+	if compress {
+		buffer := new(bytes.Buffer)
+		writer := gzip.NewWriter(buffer)
+		if _, err := writer.Write(content); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+		content = buffer.Bytes()
+	}
+	return h.fs.Upload(ctx, URL, 0644, bytes.NewReader(content))
+}
+
+//Trigger injects synthetic storage event for supplied URL
+func (h *Harness) Trigger(ctx context.Context, URL string) *contract.Response {
+	return h.service.Mirror(ctx, contract.NewRequest(URL))
+}
+
+//Emulate uploads events assets and triggers mirror for each of them
+func (h *Harness) Emulate(ctx context.Context, events ...*Event) ([]*contract.Response, error) {
+	var responses = make([]*contract.Response, 0)
+	for _, event := range events {
+		if err := h.Put(ctx, event.URL, []byte(event.Content), event.Compress); err != nil {
+			return responses, errors.Wrapf(err, "failed to upload %v", event.URL)
+		}
+		responses = append(responses, h.Trigger(ctx, event.URL))
+	}
+	return responses, nil
+}
+
+//Exists returns true if asset exists
+func (h *Harness) Exists(ctx context.Context, URL string) (bool, error) {
+	return h.fs.Exists(ctx, URL)
+}
+
+//Content returns asset content, gzip asset are uncompressed
+func (h *Harness) Content(ctx context.Context, URL string) ([]byte, error) {
+	data, err := h.fs.DownloadWithURL(ctx, URL)
+	if err != nil || !strings.HasSuffix(URL, config.GZIPExtension) {
+		return data, err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create gzip reader for %v", URL)
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+//AssertContent asserts that asset exists with expected content
+func (h *Harness) AssertContent(t assert.TestingT, URL string, expected string, msgAndArgs ...interface{}) bool {
+	data, err := h.Content(context.Background(), URL)
+	if !assert.Nil(t, err, msgAndArgs...) {
+		return false
+	}
+	return assert.Equal(t, expected, string(data), msgAndArgs...)
+}
+
+//AssertNotExists asserts that asset does not exists
+func (h *Harness) AssertNotExists(t assert.TestingT, URL string, msgAndArgs ...interface{}) bool {
+	exists, err := h.Exists(context.Background(), URL)
+	if !assert.Nil(t, err, msgAndArgs...) {
+		return false
+	}
+	return assert.False(t, exists, msgAndArgs...)
+}
+
+func validateURL(URL string) error {
+	if URL == "" {
+		return nil
+	}
+	switch scheme := url.Scheme(URL, file.Scheme); scheme {
+	case mem.Scheme, file.Scheme:
+		return nil
+	default:
+		return fmt.Errorf("unsupported emulation scheme: %v, %v", scheme, URL)
+	}
+}
+
+//New creates emulation harness for supplied rules
+func New(ctx context.Context, rules ...*config.Rule) (*Harness, error) {
+	return NewWithConfig(ctx, &smirror.Config{Mirrors: config.Ruleset{Rules: rules}})
+}
+
+//NewWithConfig creates emulation harness for supplied config, rules can be loaded from Mirrors.BaseURL
+func NewWithConfig(ctx context.Context, cfg *smirror.Config) (*Harness, error) {
+	result := &Harness{Config: cfg, fs: afs.New()}
+	return result, result.Init(ctx)
+}
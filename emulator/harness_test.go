@@ -0,0 +1,58 @@
+package emulator
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/job"
+	"testing"
+)
+
+func TestHarness_Emulate(t *testing.T) {
+	ctx := context.Background()
+	harness, err := New(ctx, &config.Rule{
+		PreserveDepth: base.IntPtr(1),
+		Labels:        map[string]string{"team": "emulator"},
+		Source: &config.Resource{
+			Basic: matcher.Basic{Prefix: "/emulator/trigger/", Suffix: ".csv"},
+		},
+		Dest: &config.Resource{
+			URL: "mem://localhost/emulator/dest",
+		},
+		Compression: &config.Compression{Codec: config.GZipCodec},
+		Actions: job.Actions{
+			OnSuccess: []*job.Action{{Action: job.ActionDelete}},
+		},
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	responses, err := harness.Emulate(ctx, &Event{
+		URL:     "mem://localhost/emulator/trigger/2020/data.csv",
+		Content: "line1\nline2\n",
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, base.StatusOK, responses[0].Status, responses[0].Error)
+	assert.Equal(t, "emulator", responses[0].Labels["team"])
+	assert.NotEmpty(t, responses[0].TransferID)
+	if assert.Equal(t, 1, len(responses[0].DestOutcomes)) {
+		outcome := responses[0].DestOutcomes[0]
+		assert.Equal(t, "mem://localhost/emulator/dest/2020/data.csv.gz", outcome.URL)
+		assert.Equal(t, int64(12), outcome.Bytes)
+		assert.Equal(t, base.StatusOK, outcome.Status)
+	}
+	harness.AssertContent(t, "mem://localhost/emulator/dest/2020/data.csv.gz", "line1\nline2\n")
+	harness.AssertNotExists(t, "mem://localhost/emulator/trigger/2020/data.csv")
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(context.Background(), &config.Rule{
+		Source: &config.Resource{Basic: matcher.Basic{Prefix: "/emulator/"}},
+		Dest:   &config.Resource{URL: "gs://bucket/dest"},
+	})
+	assert.NotNil(t, err)
+}
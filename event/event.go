@@ -1,11 +1,29 @@
 package event
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
 
 // StorageEvent is the payload of a GCS event.
 type StorageEvent struct {
 	Bucket string `json:"bucket"`
 	Name   string `json:"name"`
+	//Attempt requeue attempt
+	Attempt int `json:"attempt,omitempty"`
+	//NotBefore requeued event delay, event is not processed before
+	NotBefore *time.Time `json:"notBefore,omitempty"`
+	//Metadata object custom metadata
+	Metadata map[string]string `json:"metadata,omitempty"`
+	//Generation object generation
+	Generation string `json:"generation,omitempty"`
+}
+
+//GenerationNumber returns object generation or 0 if not specified
+func (e StorageEvent) GenerationNumber() int64 {
+	generation, _ := strconv.ParseInt(e.Generation, 10, 64)
+	return generation
 }
 
 //URL returns event source URL
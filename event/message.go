@@ -1,6 +1,9 @@
 package event
 
-import "time"
+import (
+	"strconv"
+	"time"
+)
 
 // PubsubBucketNotification
 type PubsubBucketNotification struct {
@@ -14,6 +17,8 @@ type Attributes struct {
 	BucketId           string     `json:"bucketId"`
 	EventTime          *time.Time `json:"eventTime"`
 	EventType          string     `json:"eventTime"`
+	Attempt            string     `json:"attempt,omitempty"`
+	NotBefore          string     `json:"notBefore,omitempty"`
 }
 
 //StorageEvent returns a storage event
@@ -21,8 +26,14 @@ func (e *PubsubBucketNotification) StorageEvent() *StorageEvent {
 	if e.Attributes == nil {
 		return nil
 	}
-	return &StorageEvent{
-		Bucket: e.Attributes.BucketId,
-		Name:   e.Attributes.ObjectId,
+	attempt, _ := strconv.Atoi(e.Attributes.Attempt)
+	result := &StorageEvent{
+		Bucket:  e.Attributes.BucketId,
+		Name:    e.Attributes.ObjectId,
+		Attempt: attempt,
 	}
+	if notBefore, err := time.Parse(time.RFC3339, e.Attributes.NotBefore); err == nil {
+		result.NotBefore = &notBefore
+	}
+	return result
 }
@@ -0,0 +1,134 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/eventlog"
+	"github.com/viant/smirror/msgbus/pubsub"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+//defaultEventsLimit default number of events returned by events cursor API
+const defaultEventsLimit = 1000
+
+//StorageMirrorEvents cloud function entry point, returns lifecycle events appended to bucket event log after cursor
+func StorageMirrorEvents(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, auth.EndpointEvents) {
+		return
+	}
+	err := readEvents(w, r)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func readEvents(writer http.ResponseWriter, httpRequest *http.Request) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	query := httpRequest.URL.Query()
+	request := &contract.EventsRequest{Tenant: query.Get("tenant"), Cursor: query.Get("cursor")}
+	if limit := query.Get("limit"); limit != "" {
+		if request.Limit, err = strconv.Atoi(limit); err != nil {
+			return errors.Wrapf(err, "invalid limit: %v", limit)
+		}
+	}
+	ctx := context.Background()
+	service, err := NewFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		return err
+	}
+	response := service.ReadEvents(ctx, request)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+//ReadEvents returns lifecycle events appended to bucket event log after cursor
+func (s *service) ReadEvents(ctx context.Context, request *contract.EventsRequest) *contract.EventsResponse {
+	response := contract.NewEventsResponse()
+	response.Cursor = request.Cursor
+	settings := s.config.EventLog
+	if settings == nil || settings.URL == "" {
+		response.Status = base.StatusError
+		response.Error = "events cursor API requires bucket event log (EventLog.URL)"
+		return response
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultEventsLimit
+	}
+	page, err := eventlog.NewBucket(s.fs, settings.URL, settings.SettleDuration()).Read(ctx, request.Cursor, limit)
+	if err != nil {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+		return response
+	}
+	response.Events = page.Events
+	response.Cursor = page.Cursor
+	return response
+}
+
+//appendEvents appends response lifecycle events to event log, append error is reported as response log error
+func (s *service) appendEvents(ctx context.Context, response *contract.Response) {
+	settings := s.config.EventLog
+	if settings == nil || settings.IsLogged(response.TriggeredBy) {
+		return
+	}
+	var events = make([]*eventlog.Event, 0)
+	for _, event := range response.Events() {
+		if settings.Accepts(event.Type) {
+			events = append(events, event)
+		}
+	}
+	if len(events) == 0 {
+		return
+	}
+	appender, err := s.eventAppender(ctx)
+	if err == nil {
+		err = appender.Append(ctx, events)
+	}
+	if err != nil {
+		response.LogError = err.Error()
+	}
+}
+
+//eventAppender returns event log appender, it is created once per instance
+func (s *service) eventAppender(ctx context.Context) (eventlog.Appender, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.eventLog != nil {
+		return s.eventLog, nil
+	}
+	settings := s.config.EventLog
+	switch {
+	case settings.URL != "":
+		s.eventLog = eventlog.NewBucket(s.fs, settings.URL, settings.SettleDuration())
+	case settings.Topic != "":
+		projectID := s.config.ProjectID
+		if projectID == "" {
+			projectID = os.Getenv("GCLOUD_PROJECT")
+		}
+		bus, err := pubsub.New(ctx, projectID)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to create event log publisher")
+		}
+		s.eventLog = eventlog.NewTopic(bus, settings.Topic)
+	default:
+		stream, err := eventlog.NewStream(settings.Region, settings.Stream)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to create event log stream client")
+		}
+		s.eventLog = stream
+	}
+	return s.eventLog, nil
+}
@@ -0,0 +1,168 @@
+package eventlog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	hourLayout     = "15"
+	segmentExt     = ".jsonl"
+	maxEventLength = 1024 * 1024
+)
+
+//Bucket represents log structured bucket prefix event log, each append writes an immutable segment
+//<URL>/<yyyy-MM-dd>/<HH>/<unix nano>-<ID>.jsonl, segment path relative to URL is a consumer cursor
+type Bucket struct {
+	fs  afs.Service
+	URL string
+	//settle segments younger than settle are not read, so segments still being uploaded by other instances are not skipped by cursor
+	settle time.Duration
+}
+
+//Page represents events read after cursor
+type Page struct {
+	Events []*Event
+	//Cursor last read segment, next read continues after it
+	Cursor string
+}
+
+//Append writes events as a new segment
+func (b *Bucket) Append(ctx context.Context, events []*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	buffer := new(bytes.Buffer)
+	encoder := json.NewEncoder(buffer)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	now := time.Now().UTC()
+	segment := fmt.Sprintf("%019d-%v%v", now.UnixNano(), uuid.New().String(), segmentExt)
+	URL := url.Join(b.URL, now.Format(dateLayout), now.Format(hourLayout), segment)
+	if err := b.fs.Upload(ctx, URL, file.DefaultFileOsMode, buffer); err != nil {
+		return errors.Wrapf(err, "failed to append events: %v", URL)
+	}
+	return nil
+}
+
+//Read returns events from segments written after cursor, empty cursor reads from the log beginning,
+//whole segments are read until limit events
+func (b *Bucket) Read(ctx context.Context, cursor string, limit int) (*Page, error) {
+	result := &Page{Cursor: cursor, Events: make([]*Event, 0)}
+	horizon := time.Now().Add(-b.settle).UnixNano()
+	cursorDate, cursorHour := "", ""
+	if parts := strings.Split(cursor, "/"); len(parts) == 3 {
+		cursorDate, cursorHour = parts[0], parts[1]
+	} else if cursor != "" {
+		return nil, errors.Errorf("invalid event log cursor: %v", cursor)
+	}
+	dates, err := b.list(ctx, b.URL, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, date := range dates {
+		if date < cursorDate {
+			continue
+		}
+		hours, err := b.list(ctx, url.Join(b.URL, date), true)
+		if err != nil {
+			return nil, err
+		}
+		for _, hour := range hours {
+			if date == cursorDate && hour < cursorHour {
+				continue
+			}
+			segments, err := b.list(ctx, url.Join(b.URL, date, hour), false)
+			if err != nil {
+				return nil, err
+			}
+			for _, segment := range segments {
+				position := date + "/" + hour + "/" + segment
+				if position <= cursor {
+					continue
+				}
+				if segmentTime(segment) > horizon {
+					return result, nil
+				}
+				events, err := b.readSegment(ctx, url.Join(b.URL, position))
+				if err != nil {
+					return nil, err
+				}
+				result.Events = append(result.Events, events...)
+				result.Cursor = position
+				if limit > 0 && len(result.Events) >= limit {
+					return result, nil
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+//list returns sorted child folder or segment names
+func (b *Bucket) list(ctx context.Context, URL string, folders bool) ([]string, error) {
+	objects, err := b.fs.List(ctx, URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list event log: %v", URL)
+	}
+	var result = make([]string, 0, len(objects))
+	for i, object := range objects {
+		if i == 0 && object.IsDir() && strings.TrimRight(object.URL(), "/") == strings.TrimRight(URL, "/") {
+			continue
+		}
+		if object.IsDir() != folders || (!folders && !strings.HasSuffix(object.Name(), segmentExt)) {
+			continue
+		}
+		result = append(result, object.Name())
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func (b *Bucket) readSegment(ctx context.Context, URL string) ([]*Event, error) {
+	data, err := b.fs.DownloadWithURL(ctx, URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read event log segment: %v", URL)
+	}
+	var result = make([]*Event, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventLength)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		event := &Event{}
+		if err = json.Unmarshal(scanner.Bytes(), event); err != nil {
+			return nil, errors.Wrapf(err, "invalid event log segment: %v", URL)
+		}
+		result = append(result, event)
+	}
+	return result, scanner.Err()
+}
+
+//segmentTime returns segment write time in unix nano
+func segmentTime(segment string) int64 {
+	value, _ := strconv.ParseInt(strings.SplitN(segment, "-", 2)[0], 10, 64)
+	return value
+}
+
+//NewBucket creates bucket event log
+func NewBucket(fs afs.Service, URL string, settle time.Duration) *Bucket {
+	return &Bucket{fs: fs, URL: URL, settle: settle}
+}
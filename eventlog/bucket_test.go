@@ -0,0 +1,58 @@
+package eventlog
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"testing"
+	"time"
+)
+
+func TestBucket_Read(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/eventlog/bucket"
+	_ = fs.Delete(ctx, baseURL)
+	bucket := NewBucket(fs, baseURL, 0)
+	for i, eventType := range Types[:3] {
+		event := NewEvent(eventType, "t1", "gs://bucket/data/f1.csv", "")
+		if !assert.Nil(t, bucket.Append(ctx, []*Event{event}), i) {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	assert.Nil(t, bucket.Append(ctx, []*Event{NewEvent(TypeUploaded, "t1", "gs://bucket/data/f1.csv", "s3://dest/f1.csv"), NewEvent(TypeNotified, "t1", "gs://bucket/data/f1.csv", "")}))
+
+	var useCases = []struct {
+		description string
+		limit       int
+		expect      []string
+	}{
+		{description: "first page", limit: 2, expect: []string{TypeReceived, TypeMatched}},
+		{description: "whole segment is returned", limit: 2, expect: []string{TypeTransformed, TypeUploaded, TypeNotified}},
+		{description: "no new events", limit: 2, expect: []string{}},
+	}
+	cursor := ""
+	for _, useCase := range useCases {
+		page, err := bucket.Read(ctx, cursor, useCase.limit)
+		if !assert.Nil(t, err, useCase.description) {
+			return
+		}
+		var actual = make([]string, 0)
+		for _, event := range page.Events {
+			actual = append(actual, event.Type)
+		}
+		assert.EqualValues(t, useCase.expect, actual, useCase.description)
+		if len(useCase.expect) == 0 {
+			assert.Equal(t, cursor, page.Cursor, useCase.description)
+		}
+		cursor = page.Cursor
+	}
+
+	settled := NewBucket(fs, baseURL, time.Hour)
+	page, err := settled.Read(ctx, "", 10)
+	assert.Nil(t, err)
+	assert.Empty(t, page.Events, "segments younger than settle are not read")
+	_, err = bucket.Read(ctx, "invalid", 10)
+	assert.NotNil(t, err)
+}
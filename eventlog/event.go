@@ -0,0 +1,55 @@
+package eventlog
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	//TypeReceived mirror request was received
+	TypeReceived = "received"
+	//TypeMatched source matched a rule
+	TypeMatched = "matched"
+	//TypeTransformed source was transformed (split, compressed, replaced or converted) on transfer
+	TypeTransformed = "transformed"
+	//TypeUploaded dest object was uploaded
+	TypeUploaded = "uploaded"
+	//TypeNotified rule post actions were run
+	TypeNotified = "notified"
+	//TypeFailed mirror failed
+	TypeFailed = "failed"
+)
+
+//Types all lifecycle event types in lifecycle order
+var Types = []string{TypeReceived, TypeMatched, TypeTransformed, TypeUploaded, TypeNotified, TypeFailed}
+
+//Event represents transfer lifecycle event
+type Event struct {
+	ID         string
+	Time       time.Time
+	Type       string
+	TransferID string
+	SourceURL  string
+	DestURL    string `json:",omitempty"`
+	Rule       string `json:",omitempty"`
+	Error      string `json:",omitempty"`
+}
+
+//Appender represents append-only event log
+type Appender interface {
+	//Append appends events, appended events are never modified
+	Append(ctx context.Context, events []*Event) error
+}
+
+//NewEvent creates lifecycle event
+func NewEvent(eventType, transferID, sourceURL, destURL string) *Event {
+	return &Event{
+		ID:         uuid.New().String(),
+		Time:       time.Now().UTC(),
+		Type:       eventType,
+		TransferID: transferID,
+		SourceURL:  sourceURL,
+		DestURL:    destURL,
+	}
+}
@@ -0,0 +1,53 @@
+package eventlog
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
+	"github.com/pkg/errors"
+)
+
+//Stream represents Kinesis stream event log, records are partitioned by transfer ID, consumers use shard iterator as a cursor
+type Stream struct {
+	client kinesisiface.KinesisAPI
+	name   string
+}
+
+//Append puts events as stream records
+func (s *Stream) Append(ctx context.Context, events []*Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	input := &kinesis.PutRecordsInput{StreamName: aws.String(s.name)}
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		input.Records = append(input.Records, &kinesis.PutRecordsRequestEntry{Data: data, PartitionKey: aws.String(event.TransferID)})
+	}
+	output, err := s.client.PutRecordsWithContext(ctx, input)
+	if err != nil {
+		return errors.Wrapf(err, "failed to put events to stream: %v", s.name)
+	}
+	if failed := aws.Int64Value(output.FailedRecordCount); failed > 0 {
+		return errors.Errorf("failed to put %v of %v events to stream: %v", failed, len(events), s.name)
+	}
+	return nil
+}
+
+//NewStream creates Kinesis stream event log
+func NewStream(region, name string) (*Stream, error) {
+	config := aws.NewConfig()
+	if region != "" {
+		config = config.WithRegion(region)
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Stream{client: kinesis.New(sess), name: name}, nil
+}
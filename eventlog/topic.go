@@ -0,0 +1,38 @@
+package eventlog
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/msgbus"
+)
+
+//Topic represents Pub/Sub topic event log, consumers use subscription as a cursor
+type Topic struct {
+	bus   msgbus.Service
+	topic string
+}
+
+//Append publishes each event as a message with type and transfer ID attributes
+func (t *Topic) Append(ctx context.Context, events []*Event) error {
+	for _, event := range events {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		_, err = t.bus.Publish(ctx, &msgbus.Request{
+			Dest:       t.topic,
+			Data:       data,
+			Attributes: map[string]interface{}{"type": event.Type, "transferID": event.TransferID},
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to publish event to: %v", t.topic)
+		}
+	}
+	return nil
+}
+
+//NewTopic creates Pub/Sub topic event log
+func NewTopic(bus msgbus.Service, topic string) *Topic {
+	return &Topic{bus: bus, topic: topic}
+}
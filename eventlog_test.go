@@ -0,0 +1,61 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/eventlog"
+	"strings"
+	"testing"
+)
+
+func TestService_ReadEvents(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/eventlog")
+	cfg := &Config{
+		EventLog: &config.EventLog{URL: "mem://localhost/eventlog/log"},
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Info:   base.Info{Workflow: "orders"},
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/eventlog/data"}},
+				Dest:   &config.Resource{URL: "mem://localhost/eventlog/dest"},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sourceURL := "mem://localhost/eventlog/data/f1.csv"
+	_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("1,2,3"))
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+	unmatched := service.Mirror(ctx, contract.NewRequest("mem://localhost/eventlog/other/f1.csv"))
+	assert.Equal(t, base.StatusNoMatch, unmatched.Status)
+
+	settling := service.ReadEvents(ctx, &contract.EventsRequest{})
+	if !assert.Equal(t, base.StatusOK, settling.Status, settling.Error) {
+		return
+	}
+	assert.Empty(t, settling.Events, "segments younger than settle duration are not read")
+
+	page, err := eventlog.NewBucket(fs, cfg.EventLog.URL, 0).Read(ctx, "", 100)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var actual = make([]string, 0)
+	for _, event := range page.Events {
+		if event.TransferID == response.TransferID {
+			assert.Equal(t, "orders", event.Rule)
+			actual = append(actual, event.Type)
+		}
+	}
+	assert.EqualValues(t, []string{eventlog.TypeReceived, eventlog.TypeMatched, eventlog.TypeUploaded}, actual)
+	assert.EqualValues(t, 4, len(page.Events), "unmatched request logs received event")
+	assert.NotEmpty(t, page.Cursor)
+}
@@ -0,0 +1,97 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"log"
+	"net/http"
+	"sort"
+)
+
+//ConfigExport represents effective config canonical export
+type ConfigExport struct {
+	Hash   string
+	Config json.RawMessage
+}
+
+//ExportConfig renders effective config (defaults, rules and env) as canonical JSON with stable hash
+func ExportConfig(cfg *Config) (*ConfigExport, error) {
+	effective := *cfg
+	rules := cfg.Mirrors.Snapshot()
+	effective.Mirrors = config.Ruleset{
+		BaseURL:   cfg.Mirrors.BaseURL,
+		CheckInMs: cfg.Mirrors.CheckInMs,
+		Rules:     make([]*config.Rule, len(rules)),
+	}
+	copy(effective.Mirrors.Rules, rules)
+	sort.SliceStable(effective.Mirrors.Rules, func(i, j int) bool {
+		return effective.Mirrors.Rules[i].Info.URL < effective.Mirrors.Rules[j].Info.URL
+	})
+	if cfg.SlackCredentials != nil { //do not export plain token
+		effective.SlackCredentials = &auth.Credentials{Secret: cfg.SlackCredentials.Secret}
+	}
+	data, err := json.Marshal(effective)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode config")
+	}
+	canonical, err := canonicalJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(canonical)
+	return &ConfigExport{Hash: hex.EncodeToString(hash[:]), Config: canonical}, nil
+}
+
+//canonicalJSON re-encodes JSON with sorted object keys
+func canonicalJSON(data []byte) ([]byte, error) {
+	var aMap map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&aMap); err != nil {
+		return nil, errors.Wrap(err, "failed to decode config")
+	}
+	return json.Marshal(aMap)
+}
+
+//StorageMirrorConfig cloud function entry point, renders effective config export
+func StorageMirrorConfig(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, auth.EndpointConfig) {
+		return
+	}
+	if r.ContentLength > 0 {
+		defer func() {
+			_ = r.Body.Close()
+		}()
+	}
+	err := exportConfig(w)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func exportConfig(writer http.ResponseWriter) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	cfg, err := NewConfigFromEnv(context.Background(), base.ConfigEnvKey)
+	if err != nil {
+		return err
+	}
+	export, err := ExportConfig(cfg)
+	if err != nil {
+		return err
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(writer).Encode(export)
+}
@@ -0,0 +1,25 @@
+package smirror
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"testing"
+)
+
+func TestExportConfig(t *testing.T) {
+	rule1 := &config.Rule{Info: base.Info{URL: "mem://localhost/rules/a.json"}, Dest: &config.Resource{URL: "mem://localhost/a"}}
+	rule2 := &config.Rule{Info: base.Info{URL: "mem://localhost/rules/b.json"}, Dest: &config.Resource{URL: "mem://localhost/b"}}
+
+	export1, err := ExportConfig(&Config{Mirrors: config.Ruleset{Rules: []*config.Rule{rule1, rule2}}})
+	assert.Nil(t, err)
+	export2, err := ExportConfig(&Config{Mirrors: config.Ruleset{Rules: []*config.Rule{rule2, rule1}}})
+	assert.Nil(t, err)
+	assert.Equal(t, export1.Hash, export2.Hash)
+	assert.Equal(t, string(export1.Config), string(export2.Config))
+
+	rule2.Dest.URL = "mem://localhost/c"
+	export3, err := ExportConfig(&Config{Mirrors: config.Ruleset{Rules: []*config.Rule{rule1, rule2}}})
+	assert.Nil(t, err)
+	assert.NotEqual(t, export1.Hash, export3.Hash)
+}
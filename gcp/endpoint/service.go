@@ -96,13 +96,19 @@ func (s *Service) handleMessage(ctx context.Context, msg *pubsub.Message) (bool,
 	if os.Getenv("DEBUG_MSG") != "" {
 		fmt.Printf("%s\n", data)
 	}
-	response := service.Mirror(ctx, contract.NewRequest(gcsEvent.URL()))
+	request := contract.NewRequest(gcsEvent.URL())
+	request.CorrelationID = msg.Attributes[base.CorrelationIDKey]
+	request.Generation = gcsEvent.GenerationNumber()
+	response := service.Mirror(ctx, request)
+	if response.IsLogSuppressed() {
+		return !response.IsRetryable(), nil
+	}
 	output, err := json.Marshal(response)
 	if err != nil {
 		fmt.Printf("failed marshal reported %v\n", response)
 	}
 	fmt.Printf("%s\n", output)
-	return true, nil
+	return !response.IsRetryable(), nil
 }
 
 func (s *Service) Close() error {
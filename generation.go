@@ -0,0 +1,33 @@
+package smirror
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/native"
+	"google.golang.org/api/googleapi"
+	"io"
+	"net/http"
+	"strings"
+)
+
+//openGeneration opens gs source object pinned to trigger event generation, so that concurrent overwrite does not produce mixed content,
+//storage service is authorized with rule source options (credentials, impersonation, federation)
+func (s *service) openGeneration(ctx context.Context, URL string, generation int64, options []storage.Option) (io.ReadCloser, error) {
+	service, err := native.GSService(ctx, URL, options)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create storage service")
+	}
+	bucket := url.Host(URL)
+	name := strings.Trim(url.Path(URL), "/")
+	response, err := service.Objects.Get(bucket, name).Generation(generation).Context(ctx).Download()
+	if err != nil {
+		if apiErr, ok := err.(*googleapi.Error); ok && apiErr.Code == http.StatusNotFound {
+			return nil, base.NewCodedError(base.ErrorCodeGenerationGone, errors.Errorf("generation %v of %v is no longer available", generation, URL))
+		}
+		return nil, err
+	}
+	return response.Body, nil
+}
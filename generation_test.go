@@ -0,0 +1,25 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestService_OpenGeneration(t *testing.T) {
+	recorder := &requestRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+	srv := &service{}
+	reader, err := srv.openGeneration(context.Background(), "gs://bucket/data/file.csv", 7, fakeStorageOptions(server))
+	if !assert.Nil(t, err) {
+		return
+	}
+	data, err := ioutil.ReadAll(reader)
+	_ = reader.Close()
+	assert.Nil(t, err)
+	assert.EqualValues(t, "id,name", string(data))
+	assert.EqualValues(t, "7", recorder.query.Get("generation"))
+}
@@ -13,19 +13,22 @@ require (
 	github.com/aws/aws-lambda-go v1.41.0
 	github.com/aws/aws-sdk-go v1.47.13
 	github.com/frankban/quicktest v1.9.0 // indirect
+	github.com/go-sql-driver/mysql v1.7.1
 	github.com/google/uuid v1.1.4
 	github.com/jessevdk/go-flags v1.5.0
+	github.com/lib/pq v1.10.9
 	github.com/linkedin/goavro v2.1.0+incompatible
 	github.com/nlopes/slack v0.6.0
 	github.com/peterbourgon/diskv v2.0.1+incompatible // indirect
 	github.com/pkg/errors v0.9.1
-	github.com/stretchr/testify v1.7.1
+	github.com/stretchr/testify v1.7.2
 	github.com/tealeg/xlsx v1.0.5
 	github.com/twmb/murmur3 v1.0.0
 	github.com/viant/afs v1.16.1-0.20220708154004-5cc767a16d95
 	github.com/viant/afsc v1.8.1-0.20220906205710-ef242d9f3b61
 	github.com/viant/assertly v0.5.1
 	github.com/viant/toolbox v0.34.5
+	go.mozilla.org/pkcs7 v0.9.0
 	golang.org/x/oauth2 v0.0.0-20220608161450-d0670ef3b1eb
 	google.golang.org/api v0.84.0
 	gopkg.in/linkedin/goavro.v1 v1.0.5 // indirect
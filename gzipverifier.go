@@ -0,0 +1,71 @@
+package smirror
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/base"
+	"io"
+)
+
+//gzipVerifier returns raw gzip content as is, while inflating it to validate gzip members trailer CRC32 and size
+type gzipVerifier struct {
+	source   *capturingReader
+	gzip     *gzip.Reader
+	inflated []byte
+	err      error
+}
+
+//capturingReader captures read content and source read error
+type capturingReader struct {
+	io.Reader
+	captured bytes.Buffer
+	err      error
+}
+
+func (r *capturingReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.captured.Write(p[:n])
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+//Read reads raw gzip content, corrupted content fails before EOF is returned
+func (v *gzipVerifier) Read(p []byte) (int, error) {
+	for v.source.captured.Len() == 0 {
+		if v.err != nil {
+			return 0, v.err
+		}
+		v.inflate()
+	}
+	return v.source.captured.Read(p)
+}
+
+//inflate inflates next data, raw content consumed by gzip reader is captured
+func (v *gzipVerifier) inflate() {
+	var err error
+	if v.gzip == nil {
+		v.gzip, err = gzip.NewReader(v.source)
+	}
+	if err == nil {
+		_, err = v.gzip.Read(v.inflated)
+	}
+	switch {
+	case err == nil:
+	case err == io.EOF:
+		v.err = io.EOF
+	case v.source.err != nil: //transient source errors are not reported as invalid content
+		v.err = v.source.err
+	default:
+		v.err = base.NewCodedError(base.ErrorCodeChecksum, errors.Wrap(err, "invalid gzip content"))
+	}
+}
+
+func newGzipVerifier(reader io.Reader) io.Reader {
+	return &gzipVerifier{
+		source:   &capturingReader{Reader: reader},
+		inflated: make([]byte, 64*1024),
+	}
+}
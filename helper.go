@@ -1,20 +1,29 @@
 package smirror
 
-import "strings"
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/option/content"
+	"github.com/viant/afs/storage"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/job"
+	"github.com/viant/smirror/throttle"
+	gstorage "google.golang.org/api/storage/v1"
+	"strconv"
+	"strings"
+)
 
 const (
-	notFoundCode    = "404"
-	notFound        = "not found"
 	backendError    = "backendError"
 	connectionReset = "connection reset by peer"
 )
 
-//IsNotFound returns true if not found error
+//IsNotFound returns true if not found error, 404 status is only matched in provider error format
 func IsNotFound(message string) bool {
-	if message == "" {
-		return false
-	}
-	return strings.Contains(message, notFound) || strings.Contains(message, notFoundCode)
+	return base.ClassifyError(message) == base.ErrorCodeNotFound
 }
 
 //IsRetryError returns true if backend error
@@ -22,5 +31,98 @@ func IsRetryError(message string) bool {
 	if message == "" {
 		return false
 	}
-	return strings.Contains(message, backendError) || strings.Contains(message, connectionReset)
+	return strings.Contains(message, backendError) || strings.Contains(message, connectionReset) || throttle.IsThrottled(message)
+}
+
+//sourceMoveOptions returns move options applying source storage options to the source side only,
+//move target (dead letter, defer or quarantine location) is service owned
+func sourceMoveOptions(options []storage.Option) []storage.Option {
+	return []storage.Option{option.NewSource(options...), option.NewDest()}
+}
+
+//destMoveOptions returns move options applying source storage options to the dest side only, used when service owned location is restored to source
+func destMoveOptions(options []storage.Option) []storage.Option {
+	return []storage.Option{option.NewSource(), option.NewDest(options...)}
+}
+
+//objectChecksum returns provider object checksum
+func objectChecksum(object storage.Object) string {
+	switch sys := object.Sys().(type) {
+	case *gstorage.Object:
+		if sys.Md5Hash != "" {
+			return sys.Md5Hash
+		}
+		return sys.Crc32c
+	case *s3.Object:
+		if sys.ETag != nil {
+			return strings.Trim(*sys.ETag, `"`)
+		}
+	}
+	return ""
+}
+
+//objectMetadata returns provider object custom metadata
+func objectMetadata(object storage.Object) map[string]string {
+	switch sys := object.Sys().(type) {
+	case *gstorage.Object:
+		return sys.Metadata
+	}
+	return nil
+}
+
+//lineageMeta returns destination object lineage and provenance metadata
+func lineageMeta(response *contract.Response, pipelineVersion string) *content.Meta {
+	meta := content.NewMeta(base.TransferIDKey, response.TransferID)
+	values := map[string]string{
+		base.CorrelationIDKey:    response.CorrelationID,
+		base.SourceURLKey:        response.TriggeredBy,
+		base.SourceGenerationKey: response.SourceGeneration,
+		base.PipelineVersionKey:  pipelineVersion,
+	}
+	if rule := response.Rule; rule != nil {
+		values[base.RuleKey] = rule.Info.Workflow
+		values[base.ConfigVersionKey] = rule.ConfigVersion
+	}
+	for key, value := range values {
+		if value != "" {
+			meta.Values[key] = value
+		}
+	}
+	return meta
+}
+
+//objectGeneration returns source object generation, or modification time and size version if provider has no generation
+func objectGeneration(object storage.Object, request *contract.Request) string {
+	if request.Generation > 0 {
+		return strconv.FormatInt(request.Generation, 10)
+	}
+	if sys, ok := object.Sys().(*gstorage.Object); ok && sys.Generation > 0 {
+		return strconv.FormatInt(sys.Generation, 10)
+	}
+	return config.ObjectVersion(object.ModTime(), object.Size())
+}
+
+//newJobContext creates post action job context
+func newJobContext(ctx context.Context, err error, request *contract.Request, response *contract.Response, object storage.Object) *job.Context {
+	result := job.NewContext(ctx, err, request.URL, response.Rule.Name(request.URL))
+	result.Labels = response.Labels
+	result.Values = response.Values
+	result.Captures = response.Captures
+	result.DestURLs = response.DestURLs
+	result.Rule = response.Rule
+	result.Response = response
+	result.StartTime = response.StartTime
+	result.TimeTakenMs = response.TimeTakenMs
+	result.Requeue = request.Requeue
+	result.TransferID = response.TransferID
+	result.CorrelationID = response.CorrelationID
+	if object != nil {
+		result.Source = &job.Source{
+			URL:      request.URL,
+			Size:     object.Size(),
+			Modified: object.ModTime(),
+			Checksum: objectChecksum(object),
+		}
+	}
+	return result
 }
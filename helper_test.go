@@ -0,0 +1,67 @@
+package smirror
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"testing"
+)
+
+func TestLineageMeta(t *testing.T) {
+	var useCases = []struct {
+		description     string
+		response        *contract.Response
+		pipelineVersion string
+		expect          map[string]string
+	}{
+		{
+			description:     "full provenance",
+			pipelineVersion: "1.4.2",
+			response: &contract.Response{
+				TriggeredBy:      "gs://inbound/acme/orders.csv",
+				TransferID:       "t1",
+				CorrelationID:    "c1",
+				SourceGeneration: "1700000000000001",
+				Rule:             &config.Rule{Info: base.Info{Workflow: "acme"}, ConfigVersion: "0cc175b9"},
+			},
+			expect: map[string]string{
+				base.TransferIDKey:       "t1",
+				base.CorrelationIDKey:    "c1",
+				base.SourceURLKey:        "gs://inbound/acme/orders.csv",
+				base.SourceGenerationKey: "1700000000000001",
+				base.RuleKey:             "acme",
+				base.ConfigVersionKey:    "0cc175b9",
+				base.PipelineVersionKey:  "1.4.2",
+			},
+		},
+		{
+			description: "empty values are skipped",
+			response:    &contract.Response{TriggeredBy: "s3://inbound/orders.csv", TransferID: "t2"},
+			expect: map[string]string{
+				base.TransferIDKey: "t2",
+				base.SourceURLKey:  "s3://inbound/orders.csv",
+			},
+		},
+	}
+	for _, useCase := range useCases {
+		meta := lineageMeta(useCase.response, useCase.pipelineVersion)
+		assert.EqualValues(t, useCase.expect, meta.Values, useCase.description)
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	var useCases = []struct {
+		description string
+		message     string
+		expect      bool
+	}{
+		{description: "not found", message: "gs://bucket/file.csv: not found", expect: true},
+		{description: "googleapi not found", message: "googleapi: Error 404: No such object: bucket/file.csv", expect: true},
+		{description: "status digits in object URL", message: "failed to download gs://bucket/part-00404.csv: googleapi: Error 503: backendError"},
+		{description: "empty"},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expect, IsNotFound(useCase.message), useCase.description)
+	}
+}
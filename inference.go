@@ -0,0 +1,75 @@
+package smirror
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/storage"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/config/inference"
+	"github.com/viant/smirror/contract"
+	"io"
+)
+
+//inferSchema samples top source records and writes schema inference report next to the destination
+func (s *service) inferSchema(ctx context.Context, rule *config.Rule, request *contract.Request, response *contract.Response, options []storage.Option) error {
+	if !rule.Dest.IsStorage() {
+		return nil
+	}
+	settings := rule.Inference
+	destURL := ""
+	for _, outcome := range response.DestOutcomes {
+		if outcome.Status == base.StatusOK {
+			destURL = outcome.URL
+			break
+		}
+	}
+	if destURL == "" {
+		return nil
+	}
+	pattern := config.FilePattern(request.URL)
+	if settings.StateURL != "" {
+		if exists, _ := s.fs.Exists(ctx, settings.PatternURL(pattern)); exists {
+			return nil
+		}
+	}
+	reader, err := s.fs.OpenURL(ctx, request.URL, options...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to open %v", request.URL)
+	}
+	defer reader.Close()
+	var source io.Reader = reader
+	if codec := detectSourceCodec(&source); codec == config.GZipCodec {
+		if source, err = gzip.NewReader(source); err != nil {
+			return err
+		}
+	}
+	format := settings.SourceFormat(request.URL)
+	report, err := inference.Infer(source, format, settings.Delimiter, settings.Header, settings.SampleSize)
+	if err != nil {
+		return errors.Wrapf(err, "failed to infer schema: %v", request.URL)
+	}
+	report.Pattern = pattern
+	report.SourceURL = request.URL
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	reportURL := destURL + settings.ReportSuffix
+	destOptions, err := s.secret.StorageOpts(ctx, rule.Dest.CloneWithURL(reportURL))
+	if err != nil {
+		return base.NewCodedError(base.ErrorCodeAuth, err)
+	}
+	if err = s.fs.Upload(ctx, reportURL, file.DefaultFileOsMode, bytes.NewReader(data), destOptions...); err != nil {
+		return errors.Wrapf(err, "failed to upload inference report: %v", reportURL)
+	}
+	response.InferenceURL = reportURL
+	if settings.StateURL != "" {
+		return s.fs.Upload(ctx, settings.PatternURL(pattern), file.DefaultFileOsMode, bytes.NewReader([]byte(reportURL)))
+	}
+	return nil
+}
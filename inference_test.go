@@ -0,0 +1,56 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/config/inference"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"testing"
+)
+
+func TestService_Inference(t *testing.T) {
+	ctx := context.Background()
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source:     &config.Resource{Basic: matcher.Basic{Prefix: "/inference/data"}},
+				Dest:       &config.Resource{URL: "mem://localhost/inference/dest"},
+				Inference:  &config.Inference{Header: true, StateURL: "mem://localhost/inference/state"},
+				AllowEmpty: true,
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	fs := afs.New()
+	sourceURL := "mem://localhost/inference/data/sales_20200101.csv"
+	_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("id,amount\n1,2.5\n2,3.1"))
+
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	if !assert.Equal(t, base.StatusOK, response.Status, response.Error) {
+		return
+	}
+	assert.Equal(t, "mem://localhost/inference/dest/inference/data/sales_20200101.csv.schema.json", response.InferenceURL)
+	data, err := fs.DownloadWithURL(ctx, response.InferenceURL)
+	if !assert.Nil(t, err) {
+		return
+	}
+	report := &inference.Report{}
+	assert.Nil(t, json.Unmarshal(data, report))
+	assert.Equal(t, "sales_{n}.csv", report.Pattern)
+	assert.Equal(t, 2, report.Records)
+
+	nextURL := "mem://localhost/inference/data/sales_20200102.csv"
+	_ = fs.Upload(ctx, nextURL, 0644, strings.NewReader("id,amount\n3,1.5"))
+	response = service.Mirror(ctx, contract.NewRequest(nextURL))
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+	assert.Equal(t, "", response.InferenceURL)
+}
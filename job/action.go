@@ -17,6 +17,14 @@ const (
 	ActionMove = "move"
 	//Action notify
 	ActionNotify = "notify"
+	//ActionBigQuery bigquery query action
+	ActionBigQuery = "bigquery.query"
+	//ActionInvoke cloud function, cloud run or lambda invoke action
+	ActionInvoke = "invoke"
+	//ActionCloudEvent publish cloud event action
+	ActionCloudEvent = "cloudevent"
+	//ActionRequeue republish source event action
+	ActionRequeue = "requeue"
 )
 
 //Action represents an action
@@ -28,6 +36,24 @@ type Action struct {
 	Body        interface{}       `json:",omitempty"`
 	Channels    []string          `json:",omitempty"`
 	Credentials *auth.Credentials `json:",omitempty"`
+	BigQuery    *BigQuery         `json:",omitempty"`
+	Invoke      *Invoke           `json:",omitempty"`
+	CloudEvent  *CloudEvent       `json:",omitempty"`
+	Requeue     *Requeue          `json:",omitempty"`
+	//Retry max number of action retries
+	Retry int `json:",omitempty"`
+	//RetryNonIdempotent retries move, delete, requeue, invoke and cloudevent actions, by default they run once
+	RetryNonIdempotent bool `json:",omitempty"`
+	//RetryDelayMs initial retry delay, doubled with each retry, default 500ms
+	RetryDelayMs base.Milliseconds `json:",omitempty"`
+	//TimeoutMs per attempt timeout
+	TimeoutMs base.Milliseconds `json:",omitempty"`
+	//BestEffort action error is logged, but not propagated
+	BestEffort bool `json:",omitempty"`
+	//Name optional action name referenced by DependsOn
+	Name string `json:",omitempty"`
+	//DependsOn names of actions that have to complete first with parallel actions
+	DependsOn []string `json:",omitempty"`
 }
 
 //DestURL returns destination URL
@@ -51,12 +77,17 @@ func (a Action) Do(context *Context, service afs.Service, notify Notify, info *b
 	case ActionDelete:
 		err = service.Delete(context.Context, URL)
 	case ActionNotify:
+		state := context.TemplateState()
 		body := a.Body
-		if textBody, ok := a.Body.(string); ok && textBody == "$Response" {
-			body = response
+		if textBody, ok := a.Body.(string); ok {
+			if textBody == "$Response" {
+				body = response
+			} else {
+				body = state.ExpandAsText(textBody)
+			}
 		}
-		title := strings.Replace(a.Title, "$SourceURL", context.SourceURL, 1)
-		message := strings.Replace(a.Message, "$SourceURL", context.SourceURL, 1)
+		title := state.ExpandAsText(a.Title)
+		message := state.ExpandAsText(a.Message)
 		if len(a.Channels) == 0 && info.SlackChannel != "" {
 			a.Channels = []string{info.SlackChannel}
 		}
@@ -66,7 +97,17 @@ func (a Action) Do(context *Context, service afs.Service, notify Notify, info *b
 			Credentials: a.Credentials,
 			Message:     message,
 			Body:        body,
+			Labels:      context.Labels,
+			TransferID:  context.TransferID,
 		})
+	case ActionBigQuery:
+		err = a.runQuery(context)
+	case ActionInvoke:
+		err = a.invoke(context)
+	case ActionCloudEvent:
+		err = a.emit(context)
+	case ActionRequeue:
+		err = a.requeue(context, service)
 	case ActionMove:
 		destURL := a.DestURL(context.RelativePath)
 		_, name := url.Split(URL, file.Scheme)
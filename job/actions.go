@@ -5,12 +5,26 @@ import (
 	"github.com/pkg/errors"
 	"github.com/viant/afs"
 	"github.com/viant/smirror/base"
+	"sync"
 )
 
 //Actions represents a job completion
 type Actions struct {
 	OnSuccess []*Action
 	OnFailure []*Action
+	//ParallelActions runs actions concurrently, respecting action DependsOn ordering
+	ParallelActions bool `json:",omitempty"`
+}
+
+//Validate checks if actions dependencies are valid
+func (a *Actions) Validate() error {
+	if err := validateDependencies(a.OnSuccess); err != nil {
+		return errors.Wrap(err, "invalid OnSuccess")
+	}
+	if err := validateDependencies(a.OnFailure); err != nil {
+		return errors.Wrap(err, "invalid OnFailure")
+	}
+	return nil
 }
 
 //Run run completion
@@ -33,14 +47,122 @@ func (a *Actions) run(context *Context, service afs.Service, notify Notify, info
 	if len(actions) == 0 {
 		return nil
 	}
-	for _, action := range actions {
-		e := action.Do(context, service, notify, info, body)
-		if e == nil && isError {
-			e = action.WriteError(context, service)
+	var e error
+	if a.ParallelActions {
+		e = runParallel(actions, context, service, notify, info, body)
+	} else {
+		e = runSequential(actions, context, service, notify, info, body)
+	}
+	if e != nil && !isError && len(a.OnFailure) > 0 { //propagate success action error to failure actions
+		context.Error = e
+		if fe := a.run(context, service, notify, info, body); fe != nil {
+			return errors.Wrapf(e, "failed to run failure actions: %v", fe)
 		}
-		if e != nil {
+	}
+	return e
+}
+
+func runAction(action *Action, context *Context, service afs.Service, notify Notify, info *base.Info, body interface{}) error {
+	e := action.Run(context, service, notify, info, body)
+	if e == nil && context.Error != nil {
+		e = action.WriteError(context, service)
+	}
+	return e
+}
+
+func runSequential(actions []*Action, context *Context, service afs.Service, notify Notify, info *base.Info, body interface{}) error {
+	for _, action := range actions {
+		if e := runAction(action, context, service, notify, info, body); e != nil {
 			return e
 		}
 	}
 	return nil
 }
+
+//runParallel runs actions concurrently, an action waits for its DependsOn actions and is skipped if any of them failed
+func runParallel(actions []*Action, context *Context, service afs.Service, notify Notify, info *base.Info, body interface{}) error {
+	if err := validateDependencies(actions); err != nil {
+		return err
+	}
+	index := actionIndex(actions)
+	done := make([]chan bool, len(actions))
+	errs := make([]error, len(actions))
+	for i := range actions {
+		done[i] = make(chan bool)
+	}
+	waitGroup := &sync.WaitGroup{}
+	waitGroup.Add(len(actions))
+	for i := range actions {
+		go func(i int) {
+			defer waitGroup.Done()
+			defer close(done[i])
+			action := actions[i]
+			for _, dependency := range action.DependsOn {
+				j := index[dependency]
+				<-done[j]
+				if errs[j] != nil {
+					errs[i] = errors.Errorf("skipped %v action: dependency %v failed", action.Action, dependency)
+					return
+				}
+			}
+			errs[i] = runAction(action, context, service, notify, info, body)
+		}(i)
+	}
+	waitGroup.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func actionIndex(actions []*Action) map[string]int {
+	var result = make(map[string]int)
+	for i, action := range actions {
+		if action.Name != "" {
+			result[action.Name] = i
+		}
+	}
+	return result
+}
+
+//validateDependencies checks that dependencies exist and are acyclic
+func validateDependencies(actions []*Action) error {
+	index := actionIndex(actions)
+	for _, action := range actions {
+		for _, dependency := range action.DependsOn {
+			if _, ok := index[dependency]; !ok {
+				return errors.Errorf("unknown %v action dependency: %v", action.Action, dependency)
+			}
+		}
+	}
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make([]int, len(actions))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visiting:
+			return errors.Errorf("cyclic action dependency: %v", actions[i].Name)
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		for _, dependency := range actions[i].DependsOn {
+			if err := visit(index[dependency]); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		return nil
+	}
+	for i := range actions {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package job
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/base"
+	"strings"
+	"testing"
+)
+
+func TestActions_Run(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	sourceURL := "mem://localhost/actions/data/file.csv"
+	_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("line1\n"))
+	actions := &Actions{
+		OnSuccess: []*Action{{Action: "unsupported"}},
+		OnFailure: []*Action{{Action: ActionMove, URL: "mem://localhost/actions/errors"}},
+	}
+	err := actions.Run(NewContext(ctx, nil, sourceURL, "data/file.csv"), fs, nil, &base.Info{}, nil)
+	assert.NotNil(t, err)
+	exists, _ := fs.Exists(ctx, sourceURL)
+	assert.False(t, exists, "source should be moved by failure action")
+	exists, _ = fs.Exists(ctx, "mem://localhost/actions/errors/data/file.csv")
+	assert.True(t, exists)
+}
+
+func TestActions_RunParallel(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	sourceURL := "mem://localhost/actions/parallel/file.csv"
+	_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("line1\n"))
+	var notified = make(chan string, 2)
+	notify := func(ctx context.Context, request *NotifyRequest) error {
+		notified <- request.Title
+		return nil
+	}
+	actions := &Actions{
+		ParallelActions: true,
+		OnSuccess: []*Action{
+			{Action: ActionDelete, Name: "delete", DependsOn: []string{"notify1", "notify2"}},
+			{Action: ActionNotify, Name: "notify1", Title: "n1"},
+			{Action: ActionNotify, Name: "notify2", Title: "n2"},
+		},
+	}
+	assert.Nil(t, actions.Validate())
+	err := actions.Run(NewContext(ctx, nil, sourceURL, "file.csv"), fs, notify, &base.Info{}, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(notified))
+	exists, _ := fs.Exists(ctx, sourceURL)
+	assert.False(t, exists)
+
+	actions.OnSuccess[1].DependsOn = []string{"delete"}
+	assert.NotNil(t, actions.Validate())
+	actions.OnSuccess[1].DependsOn = []string{"unknown"}
+	assert.NotNil(t, actions.Validate())
+}
@@ -0,0 +1,187 @@
+package job
+
+import (
+	"github.com/viant/smirror/base"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/toolbox/data"
+	"google.golang.org/api/bigquery/v2"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	defaultQueryTimeoutMs = 600000
+	defaultQueryPollingMs = 1000
+	bigQueryJobStatusDone = "DONE"
+)
+
+//sqlIdentifierValue matches template values safe to expand into SQL text, i.e. table or date suffix
+var sqlIdentifierValue = regexp.MustCompile(`^[A-Za-z0-9_]+(-[A-Za-z0-9_]+)*$`)
+
+//sqlTemplateVariable matches SQL template variable reference, i.e. $DateSuffix or ${Captures.partner}
+var sqlTemplateVariable = regexp.MustCompile(`\$\{?([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z0-9_]+)*)\}?`)
+
+//sqlQueryParameter matches named query parameter reference, i.e. @SourceURL or @Captures_partner
+var sqlQueryParameter = regexp.MustCompile(`@([A-Za-z_][A-Za-z0-9_]*)`)
+
+//BigQuery represents bigquery.query action settings
+type BigQuery struct {
+	//ProjectID job project, default config project
+	ProjectID string `json:",omitempty"`
+	//Location job location
+	Location string `json:",omitempty"`
+	//SQL templated query, i.e. MERGE INTO ... USING staging_${DateSuffix} ... WHERE file = @SourceURL,
+	//only identifier values are expanded into SQL text, other state values are sent as named query parameters
+	SQL string
+	//UseLegacySQL legacy SQL flag
+	UseLegacySQL bool `json:",omitempty"`
+	//TimeoutMs max job completion wait time
+	TimeoutMs base.Milliseconds `json:",omitempty"`
+}
+
+//Timeout returns job completion timeout
+func (q *BigQuery) Timeout() time.Duration {
+	if q.TimeoutMs == 0 {
+		return time.Duration(defaultQueryTimeoutMs) * time.Millisecond
+	}
+	return time.Duration(q.TimeoutMs) * time.Millisecond
+}
+
+//Validate checks if query settings are valid
+func (q *BigQuery) Validate() error {
+	if q.SQL == "" {
+		return errors.New("bigQuery.SQL was empty")
+	}
+	return nil
+}
+
+//runQuery runs templated query and waits for job completion
+func (a Action) runQuery(context *Context) error {
+	if a.BigQuery == nil {
+		return errors.Errorf("bigQuery was empty for %v action", a.Action)
+	}
+	if err := a.BigQuery.Validate(); err != nil {
+		return err
+	}
+	service, err := bigquery.NewService(context.Context)
+	if err != nil {
+		return errors.Wrap(err, "failed to create bigquery service")
+	}
+	useLegacySQL := a.BigQuery.UseLegacySQL
+	state := context.TemplateState()
+	SQL, err := expandSQL(state, a.BigQuery.SQL)
+	if err != nil {
+		return err
+	}
+	query := &bigquery.JobConfigurationQuery{
+		Query:           SQL,
+		UseLegacySql:    &useLegacySQL,
+		ForceSendFields: []string{"UseLegacySql"},
+	}
+	if query.QueryParameters = queryParameters(state, SQL); len(query.QueryParameters) > 0 {
+		if useLegacySQL {
+			return errors.New("bigQuery query parameters are not supported with legacy SQL")
+		}
+		query.ParameterMode = "NAMED"
+	}
+	job := &bigquery.Job{
+		JobReference:  &bigquery.JobReference{ProjectId: a.BigQuery.ProjectID, Location: a.BigQuery.Location},
+		Configuration: &bigquery.JobConfiguration{Query: query},
+	}
+	if job, err = service.Jobs.Insert(a.BigQuery.ProjectID, job).Context(context.Context).Do(); err != nil {
+		return errors.Wrap(err, "failed to insert query job")
+	}
+	return waitForJob(context, service, job, a.BigQuery.Timeout())
+}
+
+//expandSQL expands SQL template, expanded values have to be identifiers, so that partner controlled object names can not inject SQL
+func expandSQL(state data.Map, SQL string) (string, error) {
+	for _, match := range sqlTemplateVariable.FindAllStringSubmatch(SQL, -1) {
+		value, ok := state.GetValue(match[1])
+		if !ok || value == nil {
+			continue
+		}
+		switch actual := value.(type) {
+		case string:
+			if actual == "" || sqlIdentifierValue.MatchString(actual) {
+				continue
+			}
+		case int, int64, float64, bool:
+			continue
+		default:
+			if reflect.ValueOf(value).Kind() == reflect.Func {
+				continue
+			}
+		}
+		return "", errors.Errorf("%v can not be expanded into bigQuery SQL, use @%v query parameter", match[0], strings.Replace(match[1], ".", "_", 1))
+	}
+	return state.ExpandAsText(SQL), nil
+}
+
+//queryParameters returns named query parameters referenced by SQL, @Captures_partner refers to ${Captures.partner} state value
+func queryParameters(state data.Map, SQL string) []*bigquery.QueryParameter {
+	var result []*bigquery.QueryParameter
+	var seen = map[string]bool{}
+	for _, match := range sqlQueryParameter.FindAllStringSubmatch(SQL, -1) {
+		name := match[1]
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		value, ok := state.GetValue(name)
+		if !ok {
+			value, ok = state.GetValue(strings.Replace(name, "_", ".", 1))
+		}
+		if !ok || value == nil {
+			continue
+		}
+		parameterType := ""
+		switch actual := value.(type) {
+		case string:
+			parameterType = "STRING"
+		case int, int64:
+			parameterType = "INT64"
+		case float64:
+			parameterType = "FLOAT64"
+		case bool:
+			parameterType = "BOOL"
+		case time.Time:
+			parameterType, value = "TIMESTAMP", actual.Format(time.RFC3339Nano)
+		default:
+			continue
+		}
+		result = append(result, &bigquery.QueryParameter{
+			Name:           name,
+			ParameterType:  &bigquery.QueryParameterType{Type: parameterType},
+			ParameterValue: &bigquery.QueryParameterValue{Value: fmt.Sprintf("%v", value)},
+		})
+	}
+	return result
+}
+
+func waitForJob(context *Context, service *bigquery.Service, job *bigquery.Job, timeout time.Duration) (err error) {
+	deadline := time.Now().Add(timeout)
+	reference := job.JobReference
+	for {
+		if job.Status != nil && job.Status.State == bigQueryJobStatusDone {
+			if job.Status.ErrorResult != nil {
+				return fmt.Errorf("query job %v failed: %v", reference.JobId, job.Status.ErrorResult.Message)
+			}
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("query job %v timed out after %v", reference.JobId, timeout)
+		}
+		time.Sleep(time.Duration(defaultQueryPollingMs) * time.Millisecond)
+		call := service.Jobs.Get(reference.ProjectId, reference.JobId).Context(context.Context)
+		if reference.Location != "" {
+			call = call.Location(reference.Location)
+		}
+		if job, err = call.Do(); err != nil {
+			return errors.Wrapf(err, "failed to get query job %v", reference.JobId)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package job
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestExpandSQL(t *testing.T) {
+	ctx := NewContext(context.Background(), nil, "gs://bucket/data/o'reilly.csv", "data/o'reilly.csv")
+	ctx.StartTime = time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	ctx.Captures = map[string]string{"partner": "acme", "name": "x'; DROP TABLE t; --"}
+	var useCases = []struct {
+		description  string
+		SQL          string
+		expect       string
+		expectParams map[string]string
+		expectError  bool
+	}{
+		{
+			description:  "identifiers expanded, object name bound",
+			SQL:          "MERGE ds.events_${Captures.partner} t USING ds.staging_$DateSuffix s ON s.file = @SourceURL AND s.date = @Date",
+			expect:       "MERGE ds.events_acme t USING ds.staging_20240115 s ON s.file = @SourceURL AND s.date = @Date",
+			expectParams: map[string]string{"SourceURL": "gs://bucket/data/o'reilly.csv", "Date": "2024-01-15"},
+		},
+		{
+			description:  "capture parameter",
+			SQL:          "DELETE FROM ds.t WHERE name = @Captures_name",
+			expect:       "DELETE FROM ds.t WHERE name = @Captures_name",
+			expectParams: map[string]string{"Captures_name": "x'; DROP TABLE t; --"},
+		},
+		{
+			description: "object URL expansion rejected",
+			SQL:         "DELETE FROM ds.t WHERE file = '$SourceURL'",
+			expectError: true,
+		},
+		{
+			description: "unsafe capture expansion rejected",
+			SQL:         "DELETE FROM ds.t_${Captures.name}",
+			expectError: true,
+		},
+	}
+	for _, useCase := range useCases {
+		state := ctx.TemplateState()
+		SQL, err := expandSQL(state, useCase.SQL)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.EqualValues(t, useCase.expect, SQL, useCase.description)
+		params := map[string]string{}
+		for _, param := range queryParameters(state, SQL) {
+			params[param.Name] = param.ParameterValue.Value
+			assert.EqualValues(t, "STRING", param.ParameterType.Type, useCase.description)
+		}
+		assert.EqualValues(t, useCase.expectParams, params, useCase.description)
+	}
+}
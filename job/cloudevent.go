@@ -0,0 +1,175 @@
+package job
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2/google"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	cloudEventSpecVersion   = "1.0"
+	cloudEventContentType   = "application/cloudevents+json"
+	defaultCloudEventSource = "smirror"
+	//CloudEventTypeSuccess default success event type
+	CloudEventTypeSuccess = "smirror.mirror.succeeded"
+	//CloudEventTypeFailure default failure event type
+	CloudEventTypeFailure = "smirror.mirror.failed"
+	eventarcPublishURL    = "https://eventarcpublishing.googleapis.com/v1/"
+)
+
+//CloudEvent represents cloudevent action settings, one of URL, Channel or EventBus is required
+type CloudEvent struct {
+	//Type templated event type, default smirror.mirror.succeeded or smirror.mirror.failed
+	Type string `json:",omitempty"`
+	//Source templated event source, default smirror
+	Source string `json:",omitempty"`
+	//Subject templated event subject, default $SourceURL
+	Subject string `json:",omitempty"`
+	//URL HTTP broker URL, event is sent in structured content mode
+	URL string `json:",omitempty"`
+	//Channel EventArc channel, projects/{project}/locations/{region}/channels/{channel}
+	Channel string `json:",omitempty"`
+	//EventBus EventBridge bus name or ARN
+	EventBus string `json:",omitempty"`
+}
+
+//Event represents structured CloudEvent
+type Event struct {
+	SpecVersion     string      `json:"specversion"`
+	ID              string      `json:"id"`
+	Type            string      `json:"type"`
+	Source          string      `json:"source"`
+	Subject         string      `json:"subject,omitempty"`
+	Time            time.Time   `json:"time"`
+	DataContentType string      `json:"datacontenttype"`
+	Data            interface{} `json:"data"`
+}
+
+//Validate checks if cloud event settings are valid
+func (e *CloudEvent) Validate() error {
+	count := 0
+	for _, target := range []string{e.URL, e.Channel, e.EventBus} {
+		if target != "" {
+			count++
+		}
+	}
+	if count != 1 {
+		return errors.New("cloudEvent requires exactly one of URL, Channel or EventBus")
+	}
+	return nil
+}
+
+//NewEvent creates structured event for job context
+func (e *CloudEvent) NewEvent(context *Context) *Event {
+	state := context.State()
+	template := context.TemplateState()
+	result := &Event{
+		SpecVersion:     cloudEventSpecVersion,
+		ID:              uuid.New().String(),
+		Type:            template.ExpandAsText(e.Type),
+		Source:          template.ExpandAsText(e.Source),
+		Subject:         template.ExpandAsText(e.Subject),
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		Data:            state,
+	}
+	if result.Type == "" {
+		result.Type = CloudEventTypeSuccess
+		if context.Error != nil {
+			result.Type = CloudEventTypeFailure
+		}
+	}
+	if result.Source == "" {
+		result.Source = defaultCloudEventSource
+	}
+	if result.Subject == "" {
+		result.Subject = context.SourceURL
+	}
+	return result
+}
+
+//emit publishes cloud event to configured broker
+func (a Action) emit(context *Context) error {
+	if a.CloudEvent == nil {
+		return errors.Errorf("cloudEvent was empty for %v action", a.Action)
+	}
+	if err := a.CloudEvent.Validate(); err != nil {
+		return err
+	}
+	event := a.CloudEvent.NewEvent(context)
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode cloud event")
+	}
+	switch {
+	case a.CloudEvent.URL != "":
+		err = postEvent(context, http.DefaultClient, a.CloudEvent.URL, cloudEventContentType, payload)
+	case a.CloudEvent.Channel != "":
+		err = a.CloudEvent.publishToChannel(context, payload)
+	default:
+		err = a.CloudEvent.putEvent(context, event, payload)
+	}
+	return err
+}
+
+func (e *CloudEvent) publishToChannel(context *Context, payload []byte) error {
+	client, err := google.DefaultClient(context.Context, "https://www.googleapis.com/auth/cloud-platform")
+	if err != nil {
+		return errors.Wrap(err, "failed to create google client")
+	}
+	body, err := json.Marshal(map[string]interface{}{"textEvents": []string{string(payload)}})
+	if err != nil {
+		return err
+	}
+	return postEvent(context, client, eventarcPublishURL+e.Channel+":publishEvents", "application/json", body)
+}
+
+func (e *CloudEvent) putEvent(context *Context, event *Event, payload []byte) error {
+	sess, err := session.NewSession()
+	if err != nil {
+		return err
+	}
+	output, err := eventbridge.New(sess).PutEventsWithContext(context.Context, &eventbridge.PutEventsInput{
+		Entries: []*eventbridge.PutEventsRequestEntry{
+			{
+				EventBusName: aws.String(e.EventBus),
+				Source:       aws.String(event.Source),
+				DetailType:   aws.String(event.Type),
+				Detail:       aws.String(string(payload)),
+			},
+		},
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to put event to %v", e.EventBus)
+	}
+	if output.FailedEntryCount != nil && *output.FailedEntryCount > 0 && len(output.Entries) > 0 {
+		return errors.Errorf("failed to put event to %v: %v", e.EventBus, aws.StringValue(output.Entries[0].ErrorMessage))
+	}
+	return nil
+}
+
+func postEvent(context *Context, client *http.Client, URL, contentType string, payload []byte) error {
+	request, err := http.NewRequestWithContext(context.Context, http.MethodPost, URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", contentType)
+	response, err := client.Do(request)
+	if err != nil {
+		return errors.Wrapf(err, "failed to post event to %v", URL)
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(response.Body)
+		return errors.Errorf("invalid %v response status: %v, %s", URL, response.StatusCode, body)
+	}
+	return nil
+}
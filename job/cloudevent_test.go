@@ -0,0 +1,32 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAction_CloudEvent(t *testing.T) {
+	var received = &Event{}
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		contentType = request.Header.Get("Content-Type")
+		_ = json.NewDecoder(request.Body).Decode(received)
+	}))
+	defer server.Close()
+
+	action := &Action{Action: ActionCloudEvent, CloudEvent: &CloudEvent{URL: server.URL, Subject: "${Labels.team}/$SourceURL"}}
+	jobContext := NewContext(context.Background(), errors.New("test"), "gs://bucket/data/file.csv", "data/file.csv")
+	jobContext.Labels = map[string]string{"team": "ingestion"}
+	err := action.Do(jobContext, nil, nil, nil, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, cloudEventContentType, contentType)
+	assert.Equal(t, CloudEventTypeFailure, received.Type)
+	assert.Equal(t, "smirror", received.Source)
+	assert.Equal(t, "ingestion/gs://bucket/data/file.csv", received.Subject)
+	assert.Equal(t, "1.0", received.SpecVersion)
+}
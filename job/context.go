@@ -2,6 +2,10 @@ package job
 
 import (
 	"context"
+	"github.com/viant/toolbox"
+	"github.com/viant/smirror/udf"
+	"github.com/viant/toolbox/data"
+	"time"
 )
 
 //Context represents job context
@@ -11,6 +15,103 @@ type Context struct {
 	Name         string
 	RelativePath string
 	SourceURL    string
+	Labels       map[string]string
+	//Source source object attributes
+	Source *Source
+	//DestURLs transferred destination URLs
+	DestURLs []string
+	//Rule matched rule
+	Rule interface{}
+	//Response mirror response
+	Response interface{}
+	//Values key value bag populated by transformers
+	Values      map[string]interface{}
+	//Captures source filter named groups, i.e. ${Captures.partner}
+	Captures    map[string]string
+	StartTime   time.Time
+	TimeTakenMs int
+	//Requeue number of times source event was requeued
+	Requeue int
+	//TransferID unique mirror transfer ID
+	TransferID string
+	//CorrelationID upstream correlation ID
+	CorrelationID string
+}
+
+//Source represents source object attributes
+type Source struct {
+	URL      string
+	Size     int64
+	Modified time.Time
+	//Checksum provider checksum (md5/crc32c or etag)
+	Checksum string `json:",omitempty"`
+}
+
+//State returns context state for action template expansion, i.e. $SourceURL, ${Source.Checksum}, ${Response.Status}, ${Labels.team}, ${Captures.partner}
+func (c *Context) State() data.Map {
+	state := data.NewMap()
+	state.Put("SourceURL", c.SourceURL)
+	state.Put("RelativePath", c.RelativePath)
+	state.Put("Name", c.Name)
+	state.Put("DestURLs", c.DestURLs)
+	if len(c.DestURLs) > 0 {
+		state.Put("DestURL", c.DestURLs[0])
+	}
+	state.Put("TimeTakenMs", c.TimeTakenMs)
+	state.Put("Requeue", c.Requeue)
+	state.Put("TransferID", c.TransferID)
+	state.Put("CorrelationID", c.CorrelationID)
+	state.Put("StartTime", c.StartTime)
+	if !c.StartTime.IsZero() {
+		state.Put("Date", c.StartTime.Format("2006-01-02"))
+		state.Put("DateSuffix", c.StartTime.Format("20060102"))
+	}
+	if c.Error != nil {
+		state.Put("Error", c.Error.Error())
+	}
+	if c.Labels != nil {
+		state.Put("Labels", asMap(c.Labels))
+	}
+	if c.Values != nil {
+		state.Put("Values", asMap(c.Values))
+	}
+	if c.Captures != nil {
+		state.Put("Captures", asMap(c.Captures))
+	}
+	if c.Source != nil {
+		state.Put("Source", asMap(c.Source))
+	}
+	if c.Rule != nil {
+		state.Put("Rule", asMap(c.Rule))
+	}
+	if c.Response != nil {
+		state.Put("Response", asMap(c.Response))
+	}
+	return state
+}
+
+//TemplateState returns context state with template functions, i.e. $Upper($Name), $DateAdd($Date, -1d, yyyy/MM/dd), state keys take precedence
+func (c *Context) TemplateState() data.Map {
+	result := udf.NewMap()
+	for key, value := range c.State() {
+		result.Put(key, value)
+	}
+	return result
+}
+
+//Expand expands text with context state
+func (c *Context) Expand(text string) string {
+	if text == "" {
+		return text
+	}
+	state := c.TemplateState()
+	return state.ExpandAsText(text)
+}
+
+func asMap(source interface{}) map[string]interface{} {
+	var result = map[string]interface{}{}
+	_ = toolbox.DefaultConverter.AssignConverted(&result, source)
+	return result
 }
 
 //NewContext creates a context
@@ -0,0 +1,26 @@
+package job
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestContext_Expand(t *testing.T) {
+	ctx := NewContext(context.Background(), nil, "gs://bucket/data/file.csv", "data/file.csv")
+	ctx.Labels = map[string]string{"team": "ingestion"}
+	ctx.Source = &Source{URL: ctx.SourceURL, Size: 10, Checksum: "abc"}
+	ctx.Values = map[string]interface{}{"splitCount": 3}
+	ctx.Response = struct{ Status string }{Status: "ok"}
+
+	assert.Equal(t, "gs://bucket/data/file.csv: ok, abc, ingestion, 3",
+		ctx.Expand("$SourceURL: ${Response.Status}, ${Source.Checksum}, ${Labels.team}, ${Values.splitCount}"))
+
+	ctx.TransferID = "t1"
+	ctx.CorrelationID = "c1"
+	assert.Equal(t, "t1/c1", ctx.Expand("$TransferID/$CorrelationID"))
+	assert.Equal(t, "INGESTION/file", ctx.Expand("$Upper(${Labels.team})/$Substr($RelativePath, 5, 9)"))
+
+	ctx.Captures = map[string]string{"partner": "acme"}
+	assert.Equal(t, "INSERT INTO partner_acme", ctx.Expand("INSERT INTO partner_${Captures.partner}"))
+}
@@ -0,0 +1,138 @@
+package job
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/lambda"
+	"github.com/pkg/errors"
+	"google.golang.org/api/idtoken"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+//Invoke represents invoke action settings, one of Function, URL or ARN is required
+type Invoke struct {
+	//Function cloud function name or full projects/{project}/locations/{region}/functions/{name} resource
+	Function string `json:",omitempty"`
+	//URL cloud run or any HTTP endpoint URL, identity token is used for authentication
+	URL string `json:",omitempty"`
+	//ARN lambda function name or ARN
+	ARN string `json:",omitempty"`
+	//ProjectID cloud function project, default config project
+	ProjectID string `json:",omitempty"`
+	//Region cloud function region, default config region
+	Region string `json:",omitempty"`
+	//Async invokes lambda with Event invocation type without waiting for completion, it applies to ARN only
+	Async bool `json:",omitempty"`
+}
+
+//Validate checks if invoke settings are valid
+func (i *Invoke) Validate() error {
+	count := 0
+	for _, target := range []string{i.Function, i.URL, i.ARN} {
+		if target != "" {
+			count++
+		}
+	}
+	if count != 1 {
+		return errors.New("invoke requires exactly one of Function, URL or ARN")
+	}
+	if i.Async && i.ARN == "" {
+		return errors.New("invoke Async is supported with ARN only")
+	}
+	if i.Function != "" && strings.Contains(i.Function, "/") && len(strings.Split(i.Function, "/")) != 6 {
+		return errors.Errorf("invalid invoke function: %v, expected projects/{project}/locations/{region}/functions/{name}", i.Function)
+	}
+	return nil
+}
+
+//FunctionName returns full cloud function resource name
+func (i *Invoke) FunctionName() string {
+	if strings.Contains(i.Function, "/") {
+		return i.Function
+	}
+	region := i.Region
+	if region == "" {
+		region = DefaultRegion
+	}
+	return fmt.Sprintf("projects/%v/locations/%v/functions/%v", i.ProjectID, region, i.Function)
+}
+
+//FunctionURL returns cloud function HTTPS trigger URL
+func (i *Invoke) FunctionURL() string {
+	parts := strings.Split(i.FunctionName(), "/")
+	return fmt.Sprintf("https://%v-%v.cloudfunctions.net/%v", parts[3], parts[1], parts[5])
+}
+
+//invoke calls function or endpoint with job context state JSON payload
+func (a Action) invoke(context *Context) error {
+	if a.Invoke == nil {
+		return errors.Errorf("invoke was empty for %v action", a.Action)
+	}
+	if err := a.Invoke.Validate(); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(context.State())
+	if err != nil {
+		return errors.Wrap(err, "failed to encode invoke payload")
+	}
+	switch {
+	case a.Invoke.Function != "":
+		err = a.Invoke.post(context, a.Invoke.FunctionURL(), payload)
+	case a.Invoke.URL != "":
+		err = a.Invoke.post(context, a.Invoke.URL, payload)
+	default:
+		err = a.Invoke.invokeLambda(context, payload)
+	}
+	return err
+}
+
+//post posts payload to HTTPS endpoint authenticated with identity token for endpoint audience
+func (i *Invoke) post(context *Context, URL string, payload []byte) error {
+	client, err := idtoken.NewClient(context.Context, URL)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create identity token client for %v", URL)
+	}
+	request, err := http.NewRequestWithContext(context.Context, http.MethodPost, URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := client.Do(request)
+	if err != nil {
+		return errors.Wrapf(err, "failed to post to %v", URL)
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(response.Body)
+		return errors.Errorf("invalid %v response status: %v, %s", URL, response.StatusCode, body)
+	}
+	return nil
+}
+
+func (i *Invoke) invokeLambda(context *Context, payload []byte) error {
+	sess, err := session.NewSession()
+	if err != nil {
+		return err
+	}
+	invocationType := lambda.InvocationTypeRequestResponse
+	if i.Async {
+		invocationType = lambda.InvocationTypeEvent
+	}
+	output, err := lambda.New(sess).InvokeWithContext(context.Context, &lambda.InvokeInput{
+		FunctionName:   aws.String(i.ARN),
+		InvocationType: aws.String(invocationType),
+		Payload:        payload,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to invoke %v", i.ARN)
+	}
+	if output.FunctionError != nil {
+		return errors.Errorf("lambda %v failed: %v, %s", i.ARN, *output.FunctionError, output.Payload)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package job
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestInvoke_Validate(t *testing.T) {
+	assert.NotNil(t, (&Invoke{}).Validate())
+	assert.NotNil(t, (&Invoke{Function: "fn", ARN: "arn"}).Validate())
+	invoke := &Invoke{Function: "fn", ProjectID: "p1", Region: "us-east1"}
+	assert.Nil(t, invoke.Validate())
+	assert.Equal(t, "projects/p1/locations/us-east1/functions/fn", invoke.FunctionName())
+	assert.Equal(t, "https://us-east1-p1.cloudfunctions.net/fn", invoke.FunctionURL())
+	invoke = &Invoke{Function: "projects/p2/locations/europe-west1/functions/fn2"}
+	assert.Nil(t, invoke.Validate())
+	assert.Equal(t, "https://europe-west1-p2.cloudfunctions.net/fn2", invoke.FunctionURL())
+	assert.NotNil(t, (&Invoke{Function: "locations/europe-west1/functions/fn2"}).Validate())
+	assert.NotNil(t, (&Invoke{URL: "https://hook.mycompany.com/ingestion", Async: true}).Validate())
+	assert.Nil(t, (&Invoke{ARN: "fn", Async: true}).Validate())
+}
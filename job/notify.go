@@ -23,6 +23,9 @@ type NotifyRequest struct {
 	Body     interface{}
 	*auth.Credentials
 	BodyType string
+	Labels   map[string]string `json:",omitempty"`
+	//TransferID mirror transfer ID
+	TransferID string `json:",omitempty"`
 }
 
 //Load initializes request
@@ -0,0 +1,144 @@
+package job
+
+import (
+	"encoding/json"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/event"
+	"github.com/viant/smirror/msgbus"
+	"github.com/viant/smirror/msgbus/pubsub"
+	"github.com/viant/smirror/msgbus/sqs"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRequeueMaxAttempts = 3
+	//sqsMaxDelaySec max SQS message delay
+	sqsMaxDelaySec = 900
+	//RequeueAttemptAttribute message attribute with requeue attempt
+	RequeueAttemptAttribute = "attempt"
+	//RequeueNotBeforeAttribute Pub/Sub message attribute with RFC3339 time before which requeued event is not processed
+	RequeueNotBeforeAttribute = "notBefore"
+)
+
+//Requeue represents requeue action settings, one of Topic or Queue is required
+type Requeue struct {
+	//Topic Pub/Sub topic, message uses storage notification attributes (bucketId, objectId)
+	Topic string `json:",omitempty"`
+	//Queue SQS queue name, message uses S3 event body
+	Queue string `json:",omitempty"`
+	//ProjectID topic project, default config project
+	ProjectID string `json:",omitempty"`
+	//DelaySec requeue delay, native message delay with SQS (max 900), Pub/Sub message is redelivered by subscription retry till notBefore
+	DelaySec int `json:",omitempty"`
+	//MaxAttempts max number of requeue attempts, default 3
+	MaxAttempts int `json:",omitempty"`
+	//DeadLetterURL base URL where source object is moved once MaxAttempts were exhausted
+	DeadLetterURL string `json:",omitempty"`
+}
+
+//Validate checks if requeue settings are valid
+func (r *Requeue) Validate() error {
+	if (r.Topic == "") == (r.Queue == "") {
+		return errors.New("requeue requires exactly one of Topic or Queue")
+	}
+	if r.Queue != "" && r.DelaySec > sqsMaxDelaySec {
+		return errors.Errorf("requeue.DelaySec exceeded SQS max delay: %v", sqsMaxDelaySec)
+	}
+	return nil
+}
+
+//Attempts returns max requeue attempts
+func (r *Requeue) Attempts() int {
+	if r.MaxAttempts == 0 {
+		return defaultRequeueMaxAttempts
+	}
+	return r.MaxAttempts
+}
+
+//DeadLetterDestURL returns source object dead letter URL
+func (r *Requeue) DeadLetterDestURL(sourceURL string) string {
+	return url.Join(r.DeadLetterURL, url.Host(sourceURL), url.Path(sourceURL))
+}
+
+//Request returns message bus request republishing the source event with supplied attempt
+func (r *Requeue) Request(sourceURL string, attempt int, now time.Time) (*msgbus.Request, error) {
+	bucket := url.Host(sourceURL)
+	key := strings.Trim(url.Path(sourceURL), "/")
+	result := &msgbus.Request{
+		Attributes: map[string]interface{}{RequeueAttemptAttribute: strconv.Itoa(attempt)},
+	}
+	var message interface{}
+	if r.Queue != "" {
+		result.Dest = r.Queue
+		result.DelaySec = r.DelaySec
+		result.Attributes[RequeueAttemptAttribute] = attempt
+		message = event.S3Event{
+			Records: []events.S3EventRecord{
+				{S3: events.S3Entity{Bucket: events.S3Bucket{Name: bucket}, Object: events.S3Object{Key: key}}},
+			},
+		}
+	} else {
+		result.Dest = r.Topic
+		result.Attributes["bucketId"] = bucket
+		result.Attributes["objectId"] = key
+		if r.DelaySec > 0 {
+			result.Attributes[RequeueNotBeforeAttribute] = now.Add(time.Duration(r.DelaySec) * time.Second).UTC().Format(time.RFC3339)
+		}
+		message = event.StorageEvent{Bucket: bucket, Name: key, Attempt: attempt}
+	}
+	var err error
+	result.Data, err = json.Marshal(message)
+	return result, err
+}
+
+//SQSAttempt returns requeue attempt of SQS message
+func SQSAttempt(message events.SQSMessage) int {
+	attribute, ok := message.MessageAttributes[RequeueAttemptAttribute]
+	if !ok || attribute.StringValue == nil {
+		return 0
+	}
+	attempt, _ := strconv.Atoi(*attribute.StringValue)
+	return attempt
+}
+
+//requeue republishes the source event to topic or queue, once max attempts were exhausted source object is dead-lettered
+func (a Action) requeue(context *Context, fs afs.Service) error {
+	if a.Requeue == nil {
+		return errors.Errorf("requeue was empty for %v action", a.Action)
+	}
+	if err := a.Requeue.Validate(); err != nil {
+		return err
+	}
+	if context.Requeue >= a.Requeue.Attempts() {
+		if a.Requeue.DeadLetterURL == "" {
+			return errors.Errorf("exceeded max requeue attempts: %v, %v", a.Requeue.Attempts(), context.SourceURL)
+		}
+		deadLetterURL := a.Requeue.DeadLetterDestURL(context.SourceURL)
+		if err := fs.Move(context.Context, context.SourceURL, deadLetterURL); err != nil {
+			return errors.Wrapf(err, "failed to dead-letter %v after %v requeue attempts", context.SourceURL, context.Requeue)
+		}
+		return nil
+	}
+	request, err := a.Requeue.Request(context.SourceURL, context.Requeue+1, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "failed to encode requeue message")
+	}
+	var service msgbus.Service
+	if a.Requeue.Queue != "" {
+		service, err = sqs.New(context.Context)
+	} else {
+		service, err = pubsub.New(context.Context, a.Requeue.ProjectID)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to create message bus service")
+	}
+	if _, err = service.Publish(context.Context, request); err != nil {
+		return errors.Wrapf(err, "failed to requeue %v to %v", context.SourceURL, request.Dest)
+	}
+	return nil
+}
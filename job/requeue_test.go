@@ -0,0 +1,78 @@
+package job
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/event"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequeue_Request(t *testing.T) {
+	assert.NotNil(t, (&Requeue{}).Validate())
+	assert.NotNil(t, (&Requeue{Topic: "t", Queue: "q"}).Validate())
+	assert.NotNil(t, (&Requeue{Queue: "q", DelaySec: 1000}).Validate())
+
+	requeue := &Requeue{Topic: "retry", DelaySec: 60}
+	assert.Nil(t, requeue.Validate())
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	request, err := requeue.Request("gs://bucket1/data/file.csv", 2, now)
+	assert.Nil(t, err)
+	assert.Equal(t, "retry", request.Dest)
+	assert.Equal(t, "bucket1", request.Attributes["bucketId"])
+	assert.Equal(t, "data/file.csv", request.Attributes["objectId"])
+	assert.Equal(t, "2", request.Attributes[RequeueAttemptAttribute])
+	assert.Equal(t, "2024-01-15T10:01:00Z", request.Attributes[RequeueNotBeforeAttribute])
+	notification := event.PubsubBucketNotification{Attributes: &event.Attributes{BucketId: "bucket1", ObjectId: "data/file.csv", Attempt: "2", NotBefore: "2024-01-15T10:01:00Z"}}
+	if notified := notification.StorageEvent(); assert.NotNil(t, notified.NotBefore) {
+		assert.Equal(t, now.Add(time.Minute), *notified.NotBefore)
+		assert.Equal(t, 2, notified.Attempt)
+	}
+	storageEvent := event.StorageEvent{}
+	assert.Nil(t, json.Unmarshal(request.Data, &storageEvent))
+	assert.Equal(t, 2, storageEvent.Attempt)
+	assert.Equal(t, "gs://bucket1/data/file.csv", storageEvent.URL())
+
+	requeue = &Requeue{Queue: "retry", DelaySec: 60}
+	request, err = requeue.Request("s3://bucket1/data/file.csv", 1, now)
+	assert.Nil(t, err)
+	assert.Equal(t, 60, request.DelaySec)
+	assert.Nil(t, request.Attributes[RequeueNotBeforeAttribute])
+	s3Event, err := event.NewS3EventFromJSON(request.Data)
+	assert.Nil(t, err)
+	assert.Equal(t, "s3://bucket1/data/file.csv", s3Event.URL())
+}
+
+func TestAction_Requeue_MaxAttempts(t *testing.T) {
+	action := Action{Action: ActionRequeue, Requeue: &Requeue{Topic: "retry", MaxAttempts: 2}}
+	jobContext := NewContext(context.Background(), nil, "gs://bucket1/data/file.csv", "data/file.csv")
+	jobContext.Requeue = 2
+	err := action.requeue(jobContext, afs.New())
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "exceeded max requeue attempts")
+}
+
+func TestAction_Requeue_DeadLetter(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	sourceURL := "mem://localhost/requeue/data/file.csv"
+	assert.Nil(t, fs.Upload(ctx, sourceURL, 0644, strings.NewReader("id,name")))
+	action := Action{Action: ActionRequeue, Requeue: &Requeue{Queue: "retry", MaxAttempts: 2, DeadLetterURL: "mem://localhost/dead"}}
+	jobContext := NewContext(ctx, nil, sourceURL, "data/file.csv")
+	jobContext.Requeue = 2
+	assert.Nil(t, action.requeue(jobContext, fs))
+	exists, _ := fs.Exists(ctx, sourceURL)
+	assert.False(t, exists)
+	exists, _ = fs.Exists(ctx, "mem://localhost/dead/localhost/requeue/data/file.csv")
+	assert.True(t, exists)
+}
+
+func TestSQSAttempt(t *testing.T) {
+	attempt := "2"
+	assert.Equal(t, 2, SQSAttempt(events.SQSMessage{MessageAttributes: map[string]events.SQSMessageAttribute{RequeueAttemptAttribute: {StringValue: &attempt, DataType: "Number"}}}))
+	assert.Equal(t, 0, SQSAttempt(events.SQSMessage{}))
+}
@@ -0,0 +1,79 @@
+package job
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/shared"
+	"time"
+)
+
+const defaultRetryDelayMs = 500
+
+//Idempotent returns true if action can be safely repeated, move, delete, requeue, invoke and cloudevent actions are not idempotent
+func (a Action) Idempotent() bool {
+	switch a.Action {
+	case ActionMove, ActionDelete, ActionRequeue, ActionInvoke, ActionCloudEvent:
+		return false
+	}
+	return true
+}
+
+//Run runs an action with retries and per attempt timeout, best effort action error is only logged
+func (a Action) Run(context *Context, service afs.Service, notify Notify, info *base.Info, response interface{}) (err error) {
+	delay := time.Duration(a.RetryDelayMs) * time.Millisecond
+	if delay == 0 {
+		delay = defaultRetryDelayMs * time.Millisecond
+	}
+	retries := a.Retry
+	if !a.Idempotent() && !a.RetryNonIdempotent {
+		retries = 0
+	}
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			if sleepErr := sleep(context, delay); sleepErr != nil {
+				err = errors.Wrapf(sleepErr, "%v action retry was cancelled, last error: %v", a.Action, err)
+				break
+			}
+			delay *= 2
+		}
+		if err = a.doWithTimeout(context, service, notify, info, response); err == nil {
+			return nil
+		}
+	}
+	if a.BestEffort {
+		shared.LogF("best effort %v action failed: %v\n", a.Action, err)
+		return nil
+	}
+	return err
+}
+
+//doWithTimeout runs action with TimeoutMs deadline context, it returns timeout error if action fails after the deadline
+func (a Action) doWithTimeout(jobContext *Context, service afs.Service, notify Notify, info *base.Info, response interface{}) error {
+	if a.TimeoutMs == 0 {
+		return a.Do(jobContext, service, notify, info, response)
+	}
+	timeout := time.Duration(a.TimeoutMs) * time.Millisecond
+	ctx, cancel := context.WithTimeout(jobContext.Context, timeout)
+	defer cancel()
+	attemptContext := *jobContext
+	attemptContext.Context = ctx
+	err := a.Do(&attemptContext, service, notify, info, response)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("%v action timed out after %v: %v", a.Action, timeout, err)
+	}
+	return err
+}
+
+//sleep waits for delay, it returns context error if context is done first
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,87 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/storage"
+	"github.com/viant/smirror/base"
+	"testing"
+	"time"
+)
+
+func TestAction_Run(t *testing.T) {
+	jobContext := NewContext(context.Background(), nil, "mem://localhost/retry/file.csv", "file.csv")
+	info := &base.Info{}
+
+	attempts := 0
+	failing := func(ctx context.Context, request *NotifyRequest) error {
+		attempts++
+		return errors.New("webhook error")
+	}
+	action := Action{Action: ActionNotify, Title: "test", Retry: 2, RetryDelayMs: 1}
+	assert.NotNil(t, action.Run(jobContext, nil, failing, info, nil))
+	assert.Equal(t, 3, attempts)
+
+	action.BestEffort = true
+	assert.Nil(t, action.Run(jobContext, nil, failing, info, nil))
+
+	slow := func(ctx context.Context, request *NotifyRequest) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+			return nil
+		}
+	}
+	action = Action{Action: ActionNotify, Title: "test", TimeoutMs: 10}
+	started := time.Now()
+	assert.NotNil(t, action.Run(jobContext, nil, slow, info, nil))
+	assert.True(t, time.Since(started) < 200*time.Millisecond)
+
+	attempts = 0
+	cancelCtx, cancel := context.WithCancel(context.Background())
+	cancelling := func(ctx context.Context, request *NotifyRequest) error {
+		attempts++
+		cancel()
+		return errors.New("webhook error")
+	}
+	action = Action{Action: ActionNotify, Title: "test", Retry: 2, RetryDelayMs: 10000}
+	started = time.Now()
+	assert.NotNil(t, action.Run(NewContext(cancelCtx, nil, "mem://localhost/retry/file.csv", "file.csv"), nil, cancelling, info, nil))
+	assert.Equal(t, 1, attempts)
+	assert.True(t, time.Since(started) < time.Second)
+}
+
+func TestAction_RunNonIdempotent(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	info := &base.Info{}
+	var useCases = []struct {
+		description        string
+		retryNonIdempotent bool
+		expectAttempts     int
+	}{
+		{description: "non idempotent action runs once", expectAttempts: 1},
+		{description: "non idempotent action retried when asked", retryNonIdempotent: true, expectAttempts: 3},
+	}
+	for _, useCase := range useCases {
+		counter := &deleteCounter{Service: fs}
+		jobContext := NewContext(ctx, nil, "mem://localhost/retry/missing.csv", "missing.csv")
+		action := Action{Action: ActionDelete, Retry: 2, RetryDelayMs: 1, RetryNonIdempotent: useCase.retryNonIdempotent}
+		assert.NotNil(t, action.Run(jobContext, counter, nil, info, nil), useCase.description)
+		assert.Equal(t, useCase.expectAttempts, counter.count, useCase.description)
+	}
+}
+
+//deleteCounter counts delete calls
+type deleteCounter struct {
+	afs.Service
+	count int
+}
+
+func (c *deleteCounter) Delete(ctx context.Context, URL string, options ...storage.Option) error {
+	c.count++
+	return c.Service.Delete(ctx, URL, options...)
+}
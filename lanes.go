@@ -0,0 +1,42 @@
+package smirror
+
+import (
+	"context"
+	"github.com/viant/smirror/config"
+	"time"
+)
+
+//lanes represents per priority lane transfer slots
+type lanes struct {
+	config *config.Lanes
+	slots  map[string]chan bool
+}
+
+//acquire waits for lane slot, it returns time waited and release function
+func (l *lanes) acquire(ctx context.Context, lane string) (time.Duration, func(), error) {
+	slots, ok := l.slots[lane]
+	if !ok {
+		return 0, func() {}, nil
+	}
+	started := time.Now()
+	select {
+	case slots <- true:
+	case <-ctx.Done():
+		return time.Since(started), nil, ctx.Err()
+	}
+	return time.Since(started), func() { <-slots }, nil
+}
+
+func newLanes(lanesConfig *config.Lanes) *lanes {
+	result := &lanes{config: lanesConfig, slots: make(map[string]chan bool)}
+	if lanesConfig == nil {
+		result.config = &config.Lanes{}
+		return result
+	}
+	for lane, max := range lanesConfig.MaxConcurrent {
+		if max > 0 {
+			result.slots[lane] = make(chan bool, max)
+		}
+	}
+	return result
+}
@@ -0,0 +1,34 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/smirror/config"
+	"testing"
+	"time"
+)
+
+func TestLanes_Acquire(t *testing.T) {
+	ctx := context.Background()
+	lanes := newLanes(&config.Lanes{MaxConcurrent: map[string]int{config.PriorityLow: 1}, SmallFileBytes: 100})
+	assert.Equal(t, config.PriorityHigh, lanes.config.Lane(config.PriorityLow, 10))
+	assert.Equal(t, config.PriorityLow, lanes.config.Lane(config.PriorityLow, 1000))
+	assert.Equal(t, config.PriorityNormal, lanes.config.Lane("", 1000))
+
+	_, release, err := lanes.acquire(ctx, config.PriorityLow)
+	assert.Nil(t, err)
+	//high lane has no budget and is not blocked by low lane
+	waited, releaseHigh, err := lanes.acquire(ctx, config.PriorityHigh)
+	assert.Nil(t, err)
+	assert.True(t, waited < 10*time.Millisecond)
+	releaseHigh()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, _, err = lanes.acquire(timeoutCtx, config.PriorityLow)
+	assert.NotNil(t, err)
+	release()
+	_, release, err = lanes.acquire(ctx, config.PriorityLow)
+	assert.Nil(t, err)
+	release()
+}
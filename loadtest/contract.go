@@ -0,0 +1,130 @@
+package loadtest
+
+import (
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/base"
+)
+
+const (
+	defaultCount       = 100
+	defaultSize        = 1024 * 1024
+	defaultExt         = ".csv"
+	defaultRate        = 10
+	defaultParallelism = 8
+
+	//StageGenerate synthetic source file upload
+	StageGenerate = "generate"
+	//StageQueue delay between scheduled and actual mirror start, it grows once pipeline can not keep up with target rate
+	StageQueue = "queue"
+	//StageMirror mirror call
+	StageMirror = "mirror"
+	//StageLaneWait time spent waiting for priority lane
+	StageLaneWait = "laneWait"
+	//StageThrottle time spent waiting for dest provider rate limit
+	StageThrottle = "throttle"
+)
+
+//Files represents synthetic files specification
+type Files struct {
+	//Count number of files, default 100
+	Count int `json:",omitempty"`
+	//Size file size, number of bytes or size text (i.e. 64KB, 5MB), default 1MB
+	Size base.Bytes `json:",omitempty"`
+	//Ext file extension, default .csv
+	Ext string `json:",omitempty"`
+}
+
+//Request represents a load test request
+type Request struct {
+	//SourceURL location where synthetic files are generated, it has to match rules under test
+	SourceURL string
+	//Files synthetic files specifications, default 100 files of 1MB
+	Files []*Files `json:",omitempty"`
+	//Rate target number of mirror calls per second, default 10
+	Rate float64 `json:",omitempty"`
+	//Parallelism max number of concurrent mirror calls and uploads, default 8
+	Parallelism int `json:",omitempty"`
+	//Cleanup removes generated source files once load test is completed
+	Cleanup bool `json:",omitempty"`
+	//ReportURL optional report location
+	ReportURL string `json:",omitempty"`
+}
+
+//Init initialises request
+func (r *Request) Init() {
+	if len(r.Files) == 0 {
+		r.Files = []*Files{{}}
+	}
+	for _, files := range r.Files {
+		if files.Count == 0 {
+			files.Count = defaultCount
+		}
+		if files.Size == 0 {
+			files.Size = defaultSize
+		}
+		if files.Ext == "" {
+			files.Ext = defaultExt
+		}
+	}
+	if r.Rate == 0 {
+		r.Rate = defaultRate
+	}
+	if r.Parallelism == 0 {
+		r.Parallelism = defaultParallelism
+	}
+}
+
+//Validate checks if request is valid
+func (r *Request) Validate() error {
+	if r.SourceURL == "" {
+		return errors.New("sourceURL was empty")
+	}
+	if r.Rate < 0 || r.Parallelism < 0 {
+		return errors.Errorf("invalid rate: %v or parallelism: %v", r.Rate, r.Parallelism)
+	}
+	for _, files := range r.Files {
+		if files.Count < 0 || files.Size < 0 {
+			return errors.Errorf("invalid files count: %v or size: %v", files.Count, files.Size)
+		}
+	}
+	return nil
+}
+
+//Stage represents stage latency percentiles
+type Stage struct {
+	Count  int
+	MinMs  float64
+	MeanMs float64
+	P50Ms  float64
+	P90Ms  float64
+	P95Ms  float64
+	P99Ms  float64
+	MaxMs  float64
+}
+
+//Response represents a load test report
+type Response struct {
+	Status string
+	Error  string `json:",omitempty"`
+	//RunURL location of generated source files
+	RunURL string
+	//Total number of mirror calls
+	Total int
+	//Succeeded number of mirror calls with ok status
+	Succeeded int
+	Failed    int `json:",omitempty"`
+	//Statuses number of mirror calls per response status
+	Statuses map[string]int
+	//Bytes number of generated source bytes
+	Bytes int64
+	//TargetRate requested mirror calls per second
+	TargetRate float64
+	//Throughput achieved mirror calls per second
+	Throughput float64
+	//BytesPerSec achieved source bytes per second
+	BytesPerSec float64
+	//Stages latency percentiles per stage
+	Stages      map[string]*Stage
+	Errors      []string `json:",omitempty"`
+	TimeTakenMs int
+}
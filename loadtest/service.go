@@ -0,0 +1,219 @@
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"sync"
+	"time"
+)
+
+//Mirrorer represents a mirror service
+type Mirrorer interface {
+	Mirror(ctx context.Context, request *contract.Request) *contract.Response
+}
+
+//Service represents load test service
+type Service interface {
+	Run(ctx context.Context, request *Request) *Response
+}
+
+type service struct {
+	fs       afs.Service
+	mirrorer Mirrorer
+}
+
+//Run generates synthetic source files, drives mirror pipeline at target rate and reports throughput with stage latency percentiles
+func (s *service) Run(ctx context.Context, request *Request) *Response {
+	started := time.Now()
+	response := &Response{Status: base.StatusOK, Statuses: make(map[string]int)}
+	err := s.run(ctx, request, response)
+	response.TimeTakenMs = int(time.Since(started) / time.Millisecond)
+	if err != nil {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+	} else if response.Failed > 0 {
+		response.Status = base.StatusError
+	}
+	if request.ReportURL != "" {
+		if err = s.upload(ctx, request.ReportURL, response); err != nil && response.Error == "" {
+			response.Error = err.Error()
+		}
+	}
+	return response
+}
+
+func (s *service) run(ctx context.Context, request *Request, response *Response) error {
+	request.Init()
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	response.TargetRate = request.Rate
+	response.RunURL = url.Join(request.SourceURL, fmt.Sprintf("loadtest-%v", time.Now().UTC().Format("20060102-150405")))
+	stats := newLatencies()
+	URLs, err := s.generate(ctx, request, response.RunURL, stats)
+	if err != nil {
+		return err
+	}
+	for _, files := range request.Files {
+		response.Bytes += int64(files.Count) * int64(files.Size)
+	}
+	if request.Cleanup {
+		defer func() {
+			_ = s.fs.Delete(ctx, response.RunURL)
+		}()
+	}
+	started := time.Now()
+	s.drive(ctx, request, URLs, stats, response)
+	if elapsed := time.Since(started).Seconds(); elapsed > 0 {
+		response.Throughput = float64(response.Total) / elapsed
+		response.BytesPerSec = float64(response.Bytes) / elapsed
+	}
+	response.Stages = stats.summary()
+	return nil
+}
+
+//generate uploads synthetic source files, it returns generated file URLs
+func (s *service) generate(ctx context.Context, request *Request, runURL string, stats *latencies) ([]string, error) {
+	var URLs = make([]string, 0)
+	var sizes = make([]int, 0)
+	for i, files := range request.Files {
+		for j := 0; j < files.Count; j++ {
+			URLs = append(URLs, url.Join(runURL, fmt.Sprintf("%03d-%06d%v", i, j, files.Ext)))
+			sizes = append(sizes, int(files.Size))
+		}
+	}
+	indexChan := make(chan int, request.Parallelism)
+	waitGroup := &sync.WaitGroup{}
+	errs := make(chan error, 1)
+	for i := 0; i < request.Parallelism; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for index := range indexChan {
+				started := time.Now()
+				err := s.fs.Upload(ctx, URLs[index], file.DefaultFileOsMode, bytes.NewReader(syntheticContent(index, sizes[index])))
+				if err != nil {
+					select {
+					case errs <- errors.Wrapf(err, "failed to generate %v", URLs[index]):
+					default:
+					}
+					continue
+				}
+				stats.add(StageGenerate, time.Since(started))
+			}
+		}()
+	}
+	for index := range URLs {
+		indexChan <- index
+	}
+	close(indexChan)
+	waitGroup.Wait()
+	select {
+	case err := <-errs:
+		return nil, err
+	default:
+	}
+	return URLs, nil
+}
+
+//drive schedules mirror calls at target rate, calls that can not start on schedule report growing queue latency
+func (s *service) drive(ctx context.Context, request *Request, URLs []string, stats *latencies, response *Response) {
+	interval := time.Duration(float64(time.Second) / request.Rate)
+	type call struct {
+		URL       string
+		scheduled time.Time
+	}
+	callChan := make(chan *call, len(URLs))
+	mux := &sync.Mutex{}
+	waitGroup := &sync.WaitGroup{}
+	for i := 0; i < request.Parallelism; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for call := range callChan {
+				started := time.Now()
+				stats.add(StageQueue, started.Sub(call.scheduled))
+				mirrorResponse := s.mirrorer.Mirror(ctx, contract.NewRequest(call.URL))
+				stats.add(StageMirror, time.Since(started))
+				if mirrorResponse.LaneWaitMs > 0 {
+					stats.add(StageLaneWait, time.Duration(mirrorResponse.LaneWaitMs)*time.Millisecond)
+				}
+				if mirrorResponse.ThrottleTimeMs > 0 {
+					stats.add(StageThrottle, time.Duration(mirrorResponse.ThrottleTimeMs)*time.Millisecond)
+				}
+				mux.Lock()
+				s.record(call.URL, mirrorResponse, response)
+				mux.Unlock()
+			}
+		}()
+	}
+	scheduled := time.Now()
+	for i, URL := range URLs {
+		if i > 0 {
+			scheduled = scheduled.Add(interval)
+			if wait := time.Until(scheduled); wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-ctx.Done():
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		callChan <- &call{URL: URL, scheduled: scheduled}
+	}
+	close(callChan)
+	waitGroup.Wait()
+}
+
+//record updates load test counters with mirror response
+func (s *service) record(URL string, mirrorResponse *contract.Response, response *Response) {
+	response.Total++
+	response.Statuses[mirrorResponse.Status]++
+	switch mirrorResponse.Status {
+	case base.StatusOK:
+		response.Succeeded++
+	case base.StatusError:
+		message := mirrorResponse.Error
+		if message == "" {
+			message = mirrorResponse.Status
+		}
+		response.Failed++
+		response.Errors = append(response.Errors, URL+": "+message)
+	}
+}
+
+//syntheticContent returns CSV content of supplied size, rows are unique per file
+func syntheticContent(index, size int) []byte {
+	buffer := bytes.NewBuffer(make([]byte, 0, size+64))
+	buffer.WriteString("id,file,payload\n")
+	for row := 0; buffer.Len() < size; row++ {
+		buffer.WriteString(fmt.Sprintf("%d,%d,%08x\n", row, index, uint32((index+1)*(row+1))*2654435761))
+	}
+	return buffer.Bytes()[:size]
+}
+
+func (s *service) upload(ctx context.Context, URL string, source interface{}) error {
+	data, err := json.Marshal(source)
+	if err != nil {
+		return err
+	}
+	return s.fs.Upload(ctx, URL, file.DefaultFileOsMode, bytes.NewReader(data))
+}
+
+//New creates a load test service
+func New(mirrorer Mirrorer) Service {
+	return &service{
+		fs:       afs.New(),
+		mirrorer: mirrorer,
+	}
+}
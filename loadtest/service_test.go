@@ -0,0 +1,91 @@
+package loadtest
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeMirrorer struct {
+	fs afs.Service
+}
+
+func (m *fakeMirrorer) Mirror(ctx context.Context, request *contract.Request) *contract.Response {
+	response := contract.NewResponse(request.URL)
+	if strings.HasSuffix(request.URL, ".bad") {
+		response.Status = base.StatusError
+		response.Error = "corrupted file"
+		return response
+	}
+	data, err := m.fs.DownloadWithURL(ctx, request.URL)
+	if err != nil {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+		return response
+	}
+	response.FileSize = int64(len(data))
+	response.LaneWaitMs = 2
+	time.Sleep(5 * time.Millisecond)
+	return response
+}
+
+func TestService_Run(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/loadtest"
+	_ = fs.Delete(ctx, baseURL)
+	service := New(&fakeMirrorer{fs: fs})
+	request := &Request{
+		SourceURL: baseURL + "/source",
+		Files: []*Files{
+			{Count: 8, Size: 2048},
+			{Count: 2, Size: 100, Ext: ".bad"},
+		},
+		Rate:        200,
+		Parallelism: 4,
+		Cleanup:     true,
+		ReportURL:   baseURL + "/report.json",
+	}
+	response := service.Run(ctx, request)
+	assert.Equal(t, base.StatusError, response.Status)
+	assert.Equal(t, 10, response.Total)
+	assert.Equal(t, 8, response.Succeeded)
+	assert.Equal(t, 2, response.Failed)
+	assert.Equal(t, int64(8*2048+2*100), response.Bytes)
+	assert.True(t, response.Throughput > 0)
+	for name, count := range map[string]int{StageGenerate: 10, StageQueue: 10, StageMirror: 10, StageLaneWait: 8} {
+		stage, ok := response.Stages[name]
+		if !assert.True(t, ok, name) {
+			continue
+		}
+		assert.Equal(t, count, stage.Count, name)
+		assert.True(t, stage.MinMs <= stage.P50Ms && stage.P50Ms <= stage.P99Ms && stage.P99Ms <= stage.MaxMs, name)
+	}
+	assert.True(t, response.Stages[StageMirror].MaxMs >= 5)
+	exists, _ := fs.Exists(ctx, request.ReportURL)
+	assert.True(t, exists)
+	exists, _ = fs.Exists(ctx, response.RunURL)
+	assert.False(t, exists)
+}
+
+func TestPercentile(t *testing.T) {
+	var useCases = []struct {
+		description string
+		values      []time.Duration
+		percentile  int
+		expect      time.Duration
+	}{
+		{description: "single value", values: []time.Duration{3}, percentile: 99, expect: 3},
+		{description: "median", values: []time.Duration{1, 2, 3, 4}, percentile: 50, expect: 2},
+		{description: "p90 of ten", values: []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, percentile: 90, expect: 9},
+		{description: "p99 of ten", values: []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, percentile: 99, expect: 10},
+	}
+	for _, useCase := range useCases {
+		assert.Equal(t, useCase.expect, percentile(useCase.values, useCase.percentile), useCase.description)
+	}
+}
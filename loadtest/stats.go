@@ -0,0 +1,65 @@
+package loadtest
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+//latencies collects stage latencies
+type latencies struct {
+	mux    sync.Mutex
+	stages map[string][]time.Duration
+}
+
+//add adds stage latency
+func (l *latencies) add(stage string, latency time.Duration) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.stages[stage] = append(l.stages[stage], latency)
+}
+
+//summary returns stage latency percentiles
+func (l *latencies) summary() map[string]*Stage {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	var result = make(map[string]*Stage)
+	for name, values := range l.stages {
+		if len(values) == 0 {
+			continue
+		}
+		sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+		var total time.Duration
+		for _, value := range values {
+			total += value
+		}
+		result[name] = &Stage{
+			Count:  len(values),
+			MinMs:  toMs(values[0]),
+			MeanMs: toMs(total / time.Duration(len(values))),
+			P50Ms:  toMs(percentile(values, 50)),
+			P90Ms:  toMs(percentile(values, 90)),
+			P95Ms:  toMs(percentile(values, 95)),
+			P99Ms:  toMs(percentile(values, 99)),
+			MaxMs:  toMs(values[len(values)-1]),
+		}
+	}
+	return result
+}
+
+//percentile returns nearest-rank percentile of sorted values
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func toMs(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
+
+func newLatencies() *latencies {
+	return &latencies{stages: make(map[string][]time.Duration)}
+}
@@ -0,0 +1,31 @@
+package smirror
+
+import (
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/shared"
+	"math/rand"
+)
+
+//sampleLog suppresses response log according to matched rule log level and sample rate
+func (s *service) sampleLog(response *contract.Response) {
+	if !ruleLogging(response.Rule).Accepts(response.Error != "", rand.Float64()) {
+		response.SuppressLog()
+	}
+}
+
+//debugf logs transfer step if rule log level is debug
+func debugf(rule *config.Rule, response *contract.Response, template string, params ...interface{}) {
+	if !ruleLogging(rule).IsDebug() {
+		return
+	}
+	shared.LogF("[%v] "+template+"\n", append([]interface{}{response.TransferID}, params...)...)
+}
+
+//ruleLogging returns rule logging, LOGGING env variable level applies without rule logging
+func ruleLogging(rule *config.Rule) *config.Logging {
+	if rule == nil || rule.Logging == nil {
+		return &config.Logging{}
+	}
+	return rule.Logging
+}
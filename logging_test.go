@@ -0,0 +1,58 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/shared"
+	"strings"
+	"testing"
+)
+
+func TestService_SampleLog(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/logging")
+	var useCases = []struct {
+		description string
+		logging     *config.Logging
+		sourceURL   string
+		expect      bool
+	}{
+		{description: "default", sourceURL: "mem://localhost/logging/data/f1.csv", expect: false},
+		{description: "error level success", logging: &config.Logging{Level: shared.LoggingLevelError}, sourceURL: "mem://localhost/logging/data/f1.csv", expect: true},
+		{description: "error level failure", logging: &config.Logging{Level: shared.LoggingLevelError}, sourceURL: "mem://localhost/logging/data/f2.csv", expect: false},
+		{description: "off", logging: &config.Logging{Level: shared.LoggingLevelOff}, sourceURL: "mem://localhost/logging/data/f2.csv", expect: true},
+		{description: "debug", logging: &config.Logging{Level: shared.LoggingLevelDebug, SampleRate: 0.000001}, sourceURL: "mem://localhost/logging/data/f1.csv", expect: false},
+	}
+	_ = fs.Upload(ctx, "mem://localhost/logging/data/f1.csv", 0644, strings.NewReader("1,2,3"))
+	_ = fs.Upload(ctx, "mem://localhost/logging/data/f2.csv", 0644, strings.NewReader("1,2,3"))
+	for _, useCase := range useCases {
+		cfg := &Config{
+			Mirrors: config.Ruleset{Rules: []*config.Rule{
+				{
+					Info:    base.Info{Workflow: "partner"},
+					Logging: useCase.logging,
+					Source:  &config.Resource{Basic: matcher.Basic{Prefix: "/logging/data", Filter: "f1"}},
+					Dest:    &config.Resource{URL: "mem://localhost/logging/dest"},
+				},
+				{
+					Info:    base.Info{Workflow: "failing"},
+					Logging: useCase.logging,
+					Source:  &config.Resource{Basic: matcher.Basic{Prefix: "/logging/data", Filter: "f2"}},
+					Dest:    &config.Resource{URL: "xyz://localhost/logging/dest"},
+				},
+			}},
+		}
+		service, err := New(ctx, cfg)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		response := service.Mirror(ctx, contract.NewRequest(useCase.sourceURL))
+		assert.Equal(t, useCase.expect, response.IsLogSuppressed(), useCase.description+" "+response.Status)
+	}
+}
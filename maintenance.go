@@ -0,0 +1,77 @@
+package smirror
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/shared"
+	"time"
+)
+
+//checkMaintenance defers rule event while dest maintenance window is open, objects deferred to Pause.DeferURL
+//are moved back to source location by scheduled catch-up once the window is closed
+func (s *service) checkMaintenance(ctx context.Context, rule *config.Rule, request *contract.Request, response *contract.Response) (bool, error) {
+	if rule.Dest == nil || len(rule.Dest.Maintenance) == 0 {
+		return false, nil
+	}
+	marker := config.MaintenanceMarker(rule.Info.Workflow)
+	window, end := rule.Dest.MaintenanceWindow(time.Now())
+	if window == nil {
+		return false, nil
+	}
+	cause := fmt.Errorf("dest maintenance window until %v: %v", end.Format(time.RFC3339), window.Reason)
+	if err := s.deferEvent(ctx, rule, marker, cause, request, response); err != nil {
+		return true, err
+	}
+	response.DeferredUntil = &end
+	response.Status = base.StatusMaintenance
+	return true, nil
+}
+
+//catchUp restores objects deferred by all rules or a rule workflow once dest maintenance window is closed,
+//marker shared by rules is only restored if no rule window is open
+func (s *service) catchUp(ctx context.Context, pause *config.Pause, name string) ([]string, error) {
+	if pause.DeferURL == "" {
+		return nil, errors.New("catch-up requires Pause.DeferURL")
+	}
+	if _, err := s.config.Mirrors.ReloadIfNeeded(ctx, s.cfs); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var markers = make([]string, 0)
+	var open = make(map[string]bool)
+	for _, rule := range s.config.Mirrors.Snapshot() {
+		if rule.Dest == nil || len(rule.Dest.Maintenance) == 0 {
+			continue
+		}
+		if name != config.PauseAll && rule.Info.Workflow != name {
+			continue
+		}
+		marker := config.MaintenanceMarker(rule.Info.Workflow)
+		if _, ok := open[marker]; !ok {
+			markers = append(markers, marker)
+			open[marker] = false
+		}
+		if window, _ := rule.Dest.MaintenanceWindow(now); window != nil {
+			open[marker] = true
+		}
+	}
+	var result []string
+	for _, marker := range markers {
+		if open[marker] {
+			continue
+		}
+		restored, err := s.restoreDeferred(ctx, pause, pause.DeferBaseURL(marker))
+		result = append(result, restored...)
+		if len(restored) > 0 {
+			shared.LogF("maintenance catch-up: %v, restored: %v\n", marker, len(restored))
+		}
+		if err != nil {
+			return result, errors.Wrapf(err, "failed to catch up %v", marker)
+		}
+	}
+	return result, nil
+}
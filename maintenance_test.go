@@ -0,0 +1,128 @@
+package smirror
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestService_Maintenance(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/maintenance")
+	dest := &config.Resource{
+		URL:         "mem://localhost/maintenance/dest",
+		Maintenance: []*config.Maintenance{{Cron: "* * * * *", Duration: 3600, Reason: "upgrade"}},
+	}
+	cfg := &Config{
+		Pause: &config.Pause{
+			ControlURL: "mem://localhost/maintenance/control",
+			DeferURL:   "mem://localhost/maintenance/deferred",
+			CheckInMs:  1,
+		},
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Info:   base.Info{Workflow: "warehouse"},
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/maintenance/data", Suffix: ".csv"}},
+				Dest:   dest,
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sourceURL := "mem://localhost/maintenance/data/f1.csv"
+	_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("1,2,3"))
+
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	assert.Equal(t, base.StatusMaintenance, response.Status, response.Error)
+	assert.Equal(t, "mem://localhost/maintenance/deferred/maintenance-warehouse/localhost/maintenance/data/f1.csv", response.DeferredURL)
+	assert.NotNil(t, response.DeferredUntil)
+	exists, _ := fs.Exists(ctx, sourceURL)
+	assert.False(t, exists, "source is deferred during maintenance")
+
+	closedHour := (time.Now().UTC().Hour() + 12) % 24
+	dest.Maintenance = []*config.Maintenance{{Cron: fmt.Sprintf("0 %d * * *", closedHour), Duration: 3600}}
+	otherURL := "mem://localhost/maintenance/data/f2.csv"
+	_ = fs.Upload(ctx, otherURL, 0644, strings.NewReader("4,5,6"))
+	response = service.Mirror(ctx, contract.NewRequest(otherURL))
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+	exists, _ = fs.Exists(ctx, sourceURL)
+	assert.False(t, exists, "deferred source is not restored with event")
+
+	pauseResponse := service.Pause(ctx, &contract.PauseRequest{Action: contract.PauseActionCatchUp})
+	assert.Equal(t, base.StatusOK, pauseResponse.Status, pauseResponse.Error)
+	assert.EqualValues(t, []string{sourceURL}, pauseResponse.Restored)
+	exists, _ = fs.Exists(ctx, sourceURL)
+	assert.True(t, exists, "deferred source is restored by catch-up once window is closed")
+}
+
+func TestService_CatchUpOpenWindow(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/catchup")
+	cfg := &Config{
+		Pause: &config.Pause{
+			ControlURL: "mem://localhost/catchup/control",
+			DeferURL:   "mem://localhost/catchup/deferred",
+		},
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Info:   base.Info{Workflow: "warehouse"},
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/catchup/data"}},
+				Dest: &config.Resource{
+					URL:         "mem://localhost/catchup/dest",
+					Maintenance: []*config.Maintenance{{Cron: "* * * * *", Duration: 3600}},
+				},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	deferredURL := "mem://localhost/catchup/deferred/maintenance-warehouse/localhost/catchup/data/f1.csv"
+	_ = fs.Upload(ctx, deferredURL, 0644, strings.NewReader("1,2,3"))
+	response := service.Pause(ctx, &contract.PauseRequest{Action: contract.PauseActionCatchUp})
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+	assert.Empty(t, response.Restored)
+	exists, _ := fs.Exists(ctx, deferredURL)
+	assert.True(t, exists, "deferred source stays deferred while window is open")
+}
+
+func TestService_MaintenanceWithoutDefer(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/maintenancenodefer")
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/maintenancenodefer/data"}},
+				Dest: &config.Resource{
+					URL:         "mem://localhost/maintenancenodefer/dest",
+					Maintenance: []*config.Maintenance{{Cron: "* * * * *", Duration: 3600}},
+				},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sourceURL := "mem://localhost/maintenancenodefer/data/f1.csv"
+	_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("1,2,3"))
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	assert.Equal(t, base.ErrorCodePaused, response.ErrorCode)
+	assert.True(t, response.IsRetryable())
+	exists, _ := fs.Exists(ctx, "mem://localhost/maintenancenodefer/dest/f1.csv")
+	assert.False(t, exists, "source is not mirrored during maintenance")
+}
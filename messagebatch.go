@@ -0,0 +1,201 @@
+package smirror
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"sort"
+	"sync"
+	"time"
+)
+
+//messageBatch represents parts pending manifest message for a message destination
+type messageBatch struct {
+	dest     string
+	sequence int
+	parts    []*contract.ManifestPart
+	size     int
+	started  time.Time
+}
+
+//manifestSequenceReserve reserves manifest envelope bytes for sequence number digits
+const manifestSequenceReserve = 20
+
+//messageBatcher groups split parts by message destination, a batch is ready once it reaches max parts, encoded bytes or age
+type messageBatcher struct {
+	maxParts int
+	maxBytes int
+	maxAge   time.Duration
+	//envelope encoded manifest size without parts
+	envelope int
+	mux      sync.Mutex
+	batches  map[string]*messageBatch
+}
+
+//encodedPartSize returns manifest part JSON size including base64 encoded data and parts separator
+func encodedPartSize(part *contract.ManifestPart) int {
+	data := part.Data
+	part.Data = nil
+	encoded, _ := json.Marshal(part)
+	part.Data = data
+	if data == nil {
+		return len(encoded) + len(",")
+	}
+	//"Data":null is replaced with "Data":"<base64>"
+	return len(encoded) - len("null") + base64.StdEncoding.EncodedLen(len(data)) + len(`""`) + len(",")
+}
+
+//manifestEnvelopeSize returns encoded manifest size without parts
+func manifestEnvelopeSize(response *contract.Response) int {
+	encoded, _ := json.Marshal(&contract.MessageManifest{
+		SourceURL:     response.TriggeredBy,
+		TransferID:    response.TransferID,
+		CorrelationID: response.CorrelationID,
+		Parts:         []*contract.ManifestPart{},
+	})
+	return len(encoded) + manifestSequenceReserve
+}
+
+//fits returns true if part fits manifest message on its own
+func (b *messageBatcher) fits(part *contract.ManifestPart) bool {
+	return b.envelope+encodedPartSize(part) <= b.maxBytes
+}
+
+//add adds part to dest batch, it returns batches ready for publishing
+func (b *messageBatcher) add(dest string, part *contract.ManifestPart, now time.Time) []*messageBatch {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	var ready []*messageBatch
+	batch, ok := b.batches[dest]
+	if !ok {
+		batch = &messageBatch{dest: dest}
+		b.batches[dest] = batch
+	}
+	partSize := encodedPartSize(part)
+	if len(batch.parts) > 0 && b.envelope+batch.size+partSize > b.maxBytes {
+		ready = append(ready, b.take(batch))
+	}
+	if len(batch.parts) == 0 {
+		batch.started = now
+	}
+	batch.parts = append(batch.parts, part)
+	batch.size += partSize
+	if len(batch.parts) >= b.maxParts || b.envelope+batch.size >= b.maxBytes || now.Sub(batch.started) >= b.maxAge {
+		ready = append(ready, b.take(batch))
+	}
+	return ready
+}
+
+//flush returns all pending batches sorted by dest
+func (b *messageBatcher) flush() []*messageBatch {
+	return b.expired(time.Time{})
+}
+
+//expired returns pending batches started before now minus max age sorted by dest, zero now returns all pending batches
+func (b *messageBatcher) expired(now time.Time) []*messageBatch {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	var ready []*messageBatch
+	for _, batch := range b.batches {
+		if len(batch.parts) > 0 && (now.IsZero() || now.Sub(batch.started) >= b.maxAge) {
+			ready = append(ready, b.take(batch))
+		}
+	}
+	sort.Slice(ready, func(i, j int) bool {
+		return ready[i].dest < ready[j].dest
+	})
+	return ready
+}
+
+//take returns batch parts snapshot with the next sequence number and resets batch
+func (b *messageBatcher) take(batch *messageBatch) *messageBatch {
+	batch.sequence++
+	result := &messageBatch{dest: batch.dest, sequence: batch.sequence, parts: batch.parts, size: batch.size, started: batch.started}
+	batch.parts = nil
+	batch.size = 0
+	return result
+}
+
+//publishBatched adds transfer data as manifest part and publishes ready manifest messages,
+//part that does not fit manifest message is claim-checked if dest has ClaimCheck, otherwise it is rejected
+func (s *service) publishBatched(ctx context.Context, transfer *Transfer, data []byte, response *contract.Response) error {
+	part := &contract.ManifestPart{
+		Name:      transfer.Dest.URL,
+		Split:     transfer.splitCounter,
+		Partition: transfer.partition,
+		Data:      data,
+	}
+	if !transfer.batcher.fits(part) {
+		if transfer.Resource.ClaimCheck == nil {
+			return errors.Errorf("split part %v encoded size exceeds MessageBatch.MaxBytes: %v, use smaller Split or dest ClaimCheck", transfer.Dest.URL, transfer.batcher.maxBytes)
+		}
+		var err error
+		if part.Data, err = s.offload(ctx, transfer, data, response); err != nil {
+			return err
+		}
+		if !transfer.batcher.fits(part) {
+			return errors.Errorf("split part %v claim-check reference exceeds MessageBatch.MaxBytes: %v", transfer.Dest.URL, transfer.batcher.maxBytes)
+		}
+	}
+	return s.publishManifests(ctx, transfer.batcher.add(transfer.MessageDest(), part, time.Now()), response)
+}
+
+//publishExpired publishes batches reaching max age on timer till done is closed, it returns the first publishing error
+func (s *service) publishExpired(ctx context.Context, batcher *messageBatcher, response *contract.Response, done chan struct{}) chan error {
+	result := make(chan error, 1)
+	go func() {
+		var err error
+		defer func() {
+			result <- err
+		}()
+		ticker := time.NewTicker(batcher.maxAge / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if err == nil {
+					err = s.publishManifests(ctx, batcher.expired(now), response)
+				}
+			}
+		}
+	}()
+	return result
+}
+
+//publishManifests publishes batches as manifest messages
+func (s *service) publishManifests(ctx context.Context, batches []*messageBatch, response *contract.Response) error {
+	for _, batch := range batches {
+		manifest := &contract.MessageManifest{
+			SourceURL:     response.TriggeredBy,
+			TransferID:    response.TransferID,
+			CorrelationID: response.CorrelationID,
+			Sequence:      batch.sequence,
+			Parts:         batch.parts,
+		}
+		data, err := json.Marshal(manifest)
+		if err != nil {
+			return errors.Wrapf(err, "failed to encode manifest: %v", batch.dest)
+		}
+		if err = s.publishMessage(ctx, batch.dest, response.TriggeredBy, data, response); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//newMessageBatcher creates message batcher for dest resource
+func newMessageBatcher(resource *config.Resource) *messageBatcher {
+	return &messageBatcher{
+		maxParts: resource.MessageBatch.MaxPartsCount(),
+		maxBytes: resource.MessageBatch.MaxBytesSize(resource),
+		maxAge:   resource.MessageBatch.MaxAgeDuration(),
+		batches:  make(map[string]*messageBatch),
+	}
+}
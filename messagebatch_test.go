@@ -0,0 +1,167 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessageBatcher_Add(t *testing.T) {
+	now := time.Now()
+	var useCases = []struct {
+		description string
+		batch       *config.MessageBatch
+		parts       []string
+		dests       []string
+		elapsed     time.Duration
+		expectReady []int
+		expectFlush []int
+	}{
+		{
+			description: "coalesce parts",
+			batch:       &config.MessageBatch{},
+			parts:       []string{"a", "b", "c"},
+			dests:       []string{"topic", "topic", "topic"},
+			expectFlush: []int{3},
+		},
+		{
+			description: "max parts",
+			batch:       &config.MessageBatch{MaxParts: 2},
+			parts:       []string{"a", "b", "c"},
+			dests:       []string{"topic", "topic", "topic"},
+			expectReady: []int{2},
+			expectFlush: []int{1},
+		},
+		{
+			description: "max bytes",
+			batch:       &config.MessageBatch{MaxBytes: base.Bytes(80)},
+			parts:       []string{"abc", "def", "g"},
+			dests:       []string{"topic", "topic", "topic"},
+			expectReady: []int{2},
+			expectFlush: []int{1},
+		},
+		{
+			description: "max age",
+			batch:       &config.MessageBatch{MaxAge: base.Milliseconds(10)},
+			parts:       []string{"a", "b"},
+			dests:       []string{"topic", "topic"},
+			elapsed:     time.Second,
+			expectReady: []int{2},
+		},
+		{
+			description: "group by dest",
+			batch:       &config.MessageBatch{},
+			parts:       []string{"a", "b", "c"},
+			dests:       []string{"topic-2", "topic-1", "topic-2"},
+			expectFlush: []int{1, 2},
+		},
+	}
+
+	for _, useCase := range useCases {
+		batcher := newMessageBatcher(&config.Resource{Topic: "topic", MessageBatch: useCase.batch})
+		var ready []int
+		for i, part := range useCase.parts {
+			batches := batcher.add(useCase.dests[i], &contract.ManifestPart{Split: int32(i + 1), Data: []byte(part)}, now.Add(time.Duration(i)*useCase.elapsed))
+			for _, batch := range batches {
+				ready = append(ready, len(batch.parts))
+			}
+		}
+		var flushed []int
+		for _, batch := range batcher.flush() {
+			flushed = append(flushed, len(batch.parts))
+		}
+		assert.EqualValues(t, useCase.expectReady, ready, useCase.description)
+		assert.EqualValues(t, useCase.expectFlush, flushed, useCase.description)
+		assert.Nil(t, batcher.flush(), useCase.description)
+	}
+}
+
+func TestMessageBatcher_Sequence(t *testing.T) {
+	batcher := newMessageBatcher(&config.Resource{Queue: "queue", MessageBatch: &config.MessageBatch{MaxParts: 1}})
+	first := batcher.add("queue", &contract.ManifestPart{Split: 1}, time.Now())
+	second := batcher.add("queue", &contract.ManifestPart{Split: 2}, time.Now())
+	assert.Equal(t, 1, first[0].sequence)
+	assert.Equal(t, 2, second[0].sequence)
+	assert.EqualValues(t, 2, second[0].parts[0].Split)
+}
+
+func TestEncodedPartSize(t *testing.T) {
+	var useCases = []*contract.ManifestPart{
+		{Name: "topic/data/f1_00001.csv", Split: 1, Data: []byte("id,name\n1,abc\n")},
+		{Name: "topic/data/f1_00002.csv", Split: 2, Partition: "p1", Data: []byte(strings.Repeat("\"", 100))},
+		{Split: 3},
+	}
+	for _, part := range useCases {
+		encoded, err := json.Marshal(part)
+		assert.Nil(t, err)
+		assert.Equal(t, len(encoded)+1, encodedPartSize(part), part.Name)
+	}
+}
+
+func TestMessageBatcher_Expired(t *testing.T) {
+	now := time.Now()
+	batcher := newMessageBatcher(&config.Resource{Topic: "topic", MessageBatch: &config.MessageBatch{MaxAge: base.Milliseconds(100)}})
+	batcher.add("topic-1", &contract.ManifestPart{Split: 1}, now)
+	batcher.add("topic-2", &contract.ManifestPart{Split: 2}, now.Add(time.Second))
+	assert.Nil(t, batcher.expired(now.Add(50*time.Millisecond)))
+	expired := batcher.expired(now.Add(200 * time.Millisecond))
+	if assert.Equal(t, 1, len(expired)) {
+		assert.Equal(t, "topic-1", expired[0].dest)
+	}
+	assert.Equal(t, 1, len(batcher.flush()))
+}
+
+func TestService_PublishBatched(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/batchclaim")
+	srv := &service{fs: fs}
+	var useCases = []struct {
+		description   string
+		claimCheck    *config.ClaimCheck
+		data          string
+		expectError   bool
+		expectOffload bool
+	}{
+		{description: "part within budget", data: "abc"},
+		{description: "oversize part rejected", data: strings.Repeat("x", 2000), expectError: true},
+		{description: "oversize part claim-checked", claimCheck: &config.ClaimCheck{URL: "mem://localhost/batchclaim"}, data: strings.Repeat("x", 2000), expectOffload: true},
+	}
+	for _, useCase := range useCases {
+		resource := &config.Resource{Topic: "topic", ClaimCheck: useCase.claimCheck, MessageBatch: &config.MessageBatch{MaxBytes: base.Bytes(1024)}}
+		response := contract.NewResponse("mem://localhost/data/f1.csv")
+		response.TransferID = "t1"
+		transfer := &Transfer{
+			Resource:     resource,
+			Dest:         NewDatafile("topic/data/f1_00001.csv", nil),
+			splitCounter: 1,
+			batcher:      newMessageBatcher(resource),
+		}
+		transfer.batcher.envelope = manifestEnvelopeSize(response)
+		err := srv.publishBatched(ctx, transfer, []byte(useCase.data), response)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		batches := transfer.batcher.flush()
+		if !assert.Equal(t, 1, len(batches), useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.expectOffload, len(response.ClaimCheckURLs) > 0, useCase.description)
+		if useCase.expectOffload {
+			claimCheck := &contract.ClaimCheck{}
+			assert.Nil(t, json.Unmarshal(batches[0].parts[0].Data, claimCheck), useCase.description)
+			assert.Equal(t, len(useCase.data), claimCheck.Size, useCase.description)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package smirror
+
+import (
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/statsd"
+)
+
+//emitMetrics pushes response transfer metrics to StatsD/DogStatsD agent, emit error is reported as response log error
+func (s *service) emitMetrics(response *contract.Response) {
+	settings := s.config.Metrics
+	if settings == nil {
+		return
+	}
+	client, err := s.statsdClient()
+	if err == nil {
+		err = client.Send(responseMetrics(response, settings.IsTagged()))
+	}
+	if err != nil {
+		response.LogError = err.Error()
+	}
+}
+
+//statsdClient returns StatsD client, it is created once per instance
+func (s *service) statsdClient() (*statsd.Client, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.statsd != nil {
+		return s.statsd, nil
+	}
+	settings := s.config.Metrics
+	client, err := statsd.New(settings.Address, settings.Prefix, settings.IsTagged())
+	if err != nil {
+		return nil, err
+	}
+	s.statsd = client
+	return client, nil
+}
+
+//responseMetrics returns dashboard metrics (transfers, bytes, errors) and duration, plain StatsD metric names are prefixed with rule
+func responseMetrics(response *contract.Response, tagged bool) []*statsd.Metric {
+	rule := responseRule(response)
+	tags := map[string]string{"rule": rule, "status": response.Status}
+	for key, value := range response.Labels {
+		if _, ok := tags[key]; !ok {
+			tags[key] = value
+		}
+	}
+	name := func(metric string) string {
+		if tagged {
+			return metric
+		}
+		return statsd.Sanitize(rule) + "." + metric
+	}
+	var result = []*statsd.Metric{
+		{Name: name(contract.MetricDuration), Value: float64(response.TimeTakenMs), Type: statsd.TypeTiming, Tags: tags},
+	}
+	if response.ThrottleTimeMs > 0 {
+		result = append(result, &statsd.Metric{Name: name(contract.MetricThrottle), Value: float64(response.ThrottleTimeMs), Type: statsd.TypeTiming, Tags: tags})
+	}
+	switch response.Status {
+	case base.StatusOK:
+		result = append(result,
+			&statsd.Metric{Name: name(contract.MetricTransfers), Value: 1, Type: statsd.TypeCount, Tags: tags},
+			&statsd.Metric{Name: name(contract.MetricBytes), Value: float64(response.FileSize), Type: statsd.TypeCount, Tags: tags})
+	case base.StatusError, base.StatusPartial:
+		errorTags := map[string]string{"error_code": response.ErrorCode, "error_class": response.ErrorClass}
+		for key, value := range tags {
+			errorTags[key] = value
+		}
+		result = append(result, &statsd.Metric{Name: name(contract.MetricErrors), Value: 1, Type: statsd.TypeCount, Tags: errorTags})
+	}
+	return result
+}
+
+//responseRule returns response rule name: workflow, rule file name or unmatched
+func responseRule(response *contract.Response) string {
+	if response.Rule != nil && response.Rule.Info.Workflow != "" {
+		return response.Rule.Info.Workflow
+	}
+	if response.Rule != nil && response.Rule.Info.URL != "" {
+		return ruleFilePartition(response.Rule.Info.URL)
+	}
+	if response.RuleURL != "" {
+		return ruleFilePartition(response.RuleURL)
+	}
+	return base.UnmatchedPartition
+}
@@ -0,0 +1,102 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestService_EmitMetrics(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/metrics")
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+
+	var useCases = []struct {
+		description string
+		emitter     string
+		expect      []string
+	}{
+		{
+			description: "dogstatsd",
+			emitter:     config.EmitterDogStatsD,
+			expect:      []string{"smirror.transfers:1|c|#rule:orders,status:ok,team:data", "smirror.bytes:", "smirror.duration:"},
+		},
+		{
+			description: "statsd",
+			emitter:     config.EmitterStatsD,
+			expect:      []string{"smirror.orders.transfers:1|c\n", "smirror.orders.bytes:", "smirror.orders.duration:"},
+		},
+	}
+	for _, useCase := range useCases {
+		cfg := &Config{
+			Metrics: &config.Metrics{Emitter: useCase.emitter, Address: listener.LocalAddr().String()},
+			Mirrors: config.Ruleset{Rules: []*config.Rule{
+				{
+					Info:   base.Info{Workflow: "orders"},
+					Labels: map[string]string{"team": "data"},
+					Source: &config.Resource{Basic: matcher.Basic{Prefix: "/metrics/data"}},
+					Dest:   &config.Resource{URL: "mem://localhost/metrics/dest"},
+				},
+			}},
+		}
+		service, err := New(ctx, cfg)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		sourceURL := "mem://localhost/metrics/data/f1.csv"
+		_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("1,2,3"))
+		response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+		assert.Equal(t, base.StatusOK, response.Status, response.Error)
+		assert.Empty(t, response.LogError, useCase.description)
+
+		buffer := make([]byte, 4096)
+		_ = listener.SetReadDeadline(time.Now().Add(2 * time.Second))
+		n, _, err := listener.ReadFrom(buffer)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		packet := string(buffer[:n]) + "\n"
+		for _, expect := range useCase.expect {
+			assert.Contains(t, packet, expect, useCase.description)
+		}
+	}
+}
+
+func TestResponseMetrics(t *testing.T) {
+	var useCases = []struct {
+		description string
+		response    *contract.Response
+		expect      []string
+	}{
+		{
+			description: "transfer",
+			response:    &contract.Response{Status: base.StatusOK, TimeTakenMs: 10},
+			expect:      []string{contract.MetricDuration, contract.MetricTransfers, contract.MetricBytes},
+		},
+		{
+			description: "throttled transfer",
+			response:    &contract.Response{Status: base.StatusOK, TimeTakenMs: 10, ThrottleTimeMs: 5},
+			expect:      []string{contract.MetricDuration, contract.MetricThrottle, contract.MetricTransfers, contract.MetricBytes},
+		},
+	}
+	for _, useCase := range useCases {
+		var names []string
+		for _, metric := range responseMetrics(useCase.response, true) {
+			names = append(names, metric.Name)
+		}
+		assert.EqualValues(t, useCase.expect, names, useCase.description)
+	}
+}
@@ -0,0 +1,141 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/migrate"
+	"log"
+	"net/http"
+	"strings"
+)
+
+//StorageMirrorMigrate cloud function entry point for bulk initial seed migration
+func StorageMirrorMigrate(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, auth.EndpointMigrate) {
+		return
+	}
+	if r.ContentLength > 0 {
+		defer func() {
+			_ = r.Body.Close()
+		}()
+	}
+	err := migratePrefix(w, r)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func migratePrefix(writer http.ResponseWriter, httpRequest *http.Request) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	request := &migrate.Request{}
+	if err = json.NewDecoder(httpRequest.Body).Decode(&request); err != nil {
+		return errors.Wrapf(err, "failed to decode %T", request)
+	}
+	ctx := context.Background()
+	service, err := NewFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		return err
+	}
+	response := migrate.New(service).Migrate(ctx, request)
+	if data, err := json.Marshal(response); err == nil {
+		fmt.Printf("%s\n", data)
+	}
+	return json.NewEncoder(writer).Encode(response)
+}
+
+//sourceRule returns rule matching source URL, or the first rule which source prefix covers source folder URL
+func (s *service) sourceRule(URL string) *config.Rule {
+	if matched := s.config.Mirrors.Match(URL); len(matched) > 0 {
+		return matched[0]
+	}
+	location := strings.Trim(url.Path(URL), "/")
+	for _, rule := range s.config.Mirrors.Snapshot() {
+		if rule.Source == nil || (rule.Source.Bucket != "" && rule.Source.Bucket != url.Host(URL)) {
+			continue
+		}
+		prefix := strings.Trim(rule.Source.Prefix, "/")
+		if strings.HasPrefix(location, prefix) || strings.HasPrefix(prefix, location) {
+			return rule
+		}
+	}
+	return nil
+}
+
+//SourceStorage returns storage service and source storage options of the rule covering source URL or prefix
+func (s *service) SourceStorage(ctx context.Context, sourceURL string) (afs.Service, []storage.Option, error) {
+	rule := s.sourceRule(sourceURL)
+	if rule == nil {
+		return s.fs, nil, nil
+	}
+	if err := s.initRule(ctx, rule); err != nil {
+		return nil, nil, err
+	}
+	options, err := s.secret.StorageOpts(ctx, rule.Source.CloneWithURL(sourceURL))
+	if err != nil {
+		return nil, nil, base.NewCodedError(base.ErrorCodeAuth, err)
+	}
+	return s.fs, options, nil
+}
+
+//DestStorage returns storage service and dest storage options of the rule matching source URL for supplied dest URL
+func (s *service) DestStorage(ctx context.Context, sourceURL, destURL string) (afs.Service, []storage.Option, error) {
+	matched := s.config.Mirrors.Match(sourceURL)
+	if len(matched) == 0 || matched[0].Dest == nil {
+		return s.fs, nil, nil
+	}
+	if err := s.initRule(ctx, matched[0]); err != nil {
+		return nil, nil, err
+	}
+	options, err := s.secret.StorageOpts(ctx, matched[0].Dest.CloneWithURL(destURL))
+	if err != nil {
+		return nil, nil, base.NewCodedError(base.ErrorCodeAuth, err)
+	}
+	return s.fs, options, nil
+}
+
+//tenantStorage returns migration storage of the tenant routed by source URL
+func (r *tenantRouter) tenantStorage(sourceURL string) (migrate.Storage, error) {
+	tenant := r.match(sourceURL)
+	if tenant == nil {
+		return nil, errors.Errorf("no tenant matched: %v", sourceURL)
+	}
+	if tenant.err != nil {
+		return nil, tenant.err
+	}
+	storager, ok := tenant.Service.(migrate.Storage)
+	if !ok {
+		return nil, errors.Errorf("unsupported tenant %v service: %T", tenant.Name, tenant.Service)
+	}
+	return storager, nil
+}
+
+//SourceStorage routes source storage lookup to tenant service
+func (r *tenantRouter) SourceStorage(ctx context.Context, sourceURL string) (afs.Service, []storage.Option, error) {
+	storager, err := r.tenantStorage(sourceURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return storager.SourceStorage(ctx, sourceURL)
+}
+
+//DestStorage routes dest storage lookup to tenant service
+func (r *tenantRouter) DestStorage(ctx context.Context, sourceURL, destURL string) (afs.Service, []storage.Option, error) {
+	storager, err := r.tenantStorage(sourceURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return storager.DestStorage(ctx, sourceURL, destURL)
+}
@@ -0,0 +1,35 @@
+package migrate
+
+import (
+	"sync"
+	"time"
+)
+
+//bandwidth paces transfers to max bytes per second
+type bandwidth struct {
+	bytesPerSec int64
+	started     time.Time
+	total       int64
+	mux         sync.Mutex
+}
+
+//Wait reserves size bytes, it returns time spent waiting
+func (b *bandwidth) Wait(size int64) time.Duration {
+	if b.bytesPerSec <= 0 {
+		return 0
+	}
+	b.mux.Lock()
+	expected := time.Duration(float64(b.total) / float64(b.bytesPerSec) * float64(time.Second))
+	b.total += size
+	b.mux.Unlock()
+	delay := expected - time.Since(b.started)
+	if delay <= 0 {
+		return 0
+	}
+	time.Sleep(delay)
+	return delay
+}
+
+func newBandwidth(bytesPerSec int64) *bandwidth {
+	return &bandwidth{bytesPerSec: bytesPerSec, started: time.Now()}
+}
@@ -0,0 +1,105 @@
+package migrate
+
+import (
+	"github.com/pkg/errors"
+	"sync"
+)
+
+const (
+	defaultParallelism     = 8
+	defaultCheckpointEvery = 100
+)
+
+//Request represents a bulk initial seed migration request
+type Request struct {
+	//SourceURL prefix to migrate, each file is mirrored with matching rule transformations
+	SourceURL string
+	//CheckpointURL resumable checkpoint location, already migrated files are skipped on rerun
+	CheckpointURL string `json:",omitempty"`
+	//CheckpointEvery number of processed files after which checkpoint is persisted, default 100
+	CheckpointEvery int `json:",omitempty"`
+	//Parallelism number of concurrent transfers, default 8
+	Parallelism int `json:",omitempty"`
+	//BytesPerSec source bandwidth limit, no limit by default
+	BytesPerSec int64 `json:",omitempty"`
+	//RunActions runs rule post actions, by default post actions are skipped for migrated files
+	RunActions bool `json:",omitempty"`
+	//ReportURL optional reconciliation report location
+	ReportURL string `json:",omitempty"`
+}
+
+//Init initialises request
+func (r *Request) Init() {
+	if r.Parallelism == 0 {
+		r.Parallelism = defaultParallelism
+	}
+	if r.CheckpointEvery == 0 {
+		r.CheckpointEvery = defaultCheckpointEvery
+	}
+}
+
+//Validate checks if request is valid
+func (r *Request) Validate() error {
+	if r.SourceURL == "" {
+		return errors.New("sourceURL was empty")
+	}
+	return nil
+}
+
+//Failure represents migration failure
+type Failure struct {
+	URL   string
+	Error string
+}
+
+//Response represents migration reconciliation report
+type Response struct {
+	Status string
+	Error  string `json:",omitempty"`
+	//Total number of source files
+	Total int
+	//Migrated number of files migrated in this run
+	Migrated int
+	//Resumed number of files skipped as already migrated by previous run
+	Resumed int `json:",omitempty"`
+	//NoMatch number of files without matching rule
+	NoMatch int `json:",omitempty"`
+	//Skipped number of files not found or waiting for done marker
+	Skipped int `json:",omitempty"`
+	Failed  int `json:",omitempty"`
+	//Bytes number of migrated source bytes
+	Bytes int64
+	//ThrottleTimeMs time spent waiting for bandwidth limit
+	ThrottleTimeMs int `json:",omitempty"`
+	TimeTakenMs    int
+	Failures       []*Failure `json:",omitempty"`
+	//Unreconciled destination URLs reported as transferred, but missing in destination
+	Unreconciled []string `json:",omitempty"`
+}
+
+//Checkpoint represents resumable migration state
+type Checkpoint struct {
+	SourceURL string
+	//Completed source URLs that do not need to be migrated again
+	Completed map[string]bool
+	mux       sync.Mutex
+}
+
+//IsCompleted returns true if source URL was completed
+func (c *Checkpoint) IsCompleted(URL string) bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.Completed[URL]
+}
+
+//Complete marks source URL as completed
+func (c *Checkpoint) Complete(URL string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.Completed[URL] = true
+}
+
+//NewCheckpoint creates a checkpoint
+func NewCheckpoint(sourceURL string) *Checkpoint {
+	return &Checkpoint{SourceURL: sourceURL, Completed: make(map[string]bool)}
+}
@@ -0,0 +1,254 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"sync"
+	"time"
+)
+
+//Mirrorer represents a mirror service
+type Mirrorer interface {
+	Mirror(ctx context.Context, request *contract.Request) *contract.Response
+}
+
+//Storage represents mirror service storage with matching rule storage options, source listing, checkpoint and reconciliation use it when mirrorer implements it
+type Storage interface {
+	//SourceStorage returns storage service and source storage options of the rule covering source URL or prefix
+	SourceStorage(ctx context.Context, sourceURL string) (afs.Service, []storage.Option, error)
+	//DestStorage returns storage service and dest storage options of the rule matching source URL for supplied dest URL
+	DestStorage(ctx context.Context, sourceURL, destURL string) (afs.Service, []storage.Option, error)
+}
+
+//Service represents migration service
+type Service interface {
+	Migrate(ctx context.Context, request *Request) *Response
+}
+
+type service struct {
+	fs       afs.Service
+	mirrorer Mirrorer
+}
+
+//Migrate performs bulk initial copy of source prefix with rule transformations
+func (s *service) Migrate(ctx context.Context, request *Request) *Response {
+	started := time.Now()
+	response := &Response{Status: base.StatusOK}
+	fs, options, err := s.sourceStorage(ctx, request.SourceURL)
+	if err == nil {
+		err = s.migrate(ctx, fs, options, request, response)
+	} else {
+		fs = s.fs
+	}
+	response.TimeTakenMs = int(time.Since(started) / time.Millisecond)
+	if err != nil {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+	} else if response.Failed > 0 || len(response.Unreconciled) > 0 {
+		response.Status = base.StatusError
+	}
+	if request.ReportURL != "" {
+		if err = s.upload(ctx, fs, request.ReportURL, response); err != nil && response.Error == "" {
+			response.Error = err.Error()
+		}
+	}
+	return response
+}
+
+//sourceStorage returns mirrorer storage with source URL rule storage options, or service storage
+func (s *service) sourceStorage(ctx context.Context, sourceURL string) (afs.Service, []storage.Option, error) {
+	if storager, ok := s.mirrorer.(Storage); ok {
+		fs, options, err := storager.SourceStorage(ctx, sourceURL)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to resolve source storage: %v", sourceURL)
+		}
+		return fs, options, nil
+	}
+	return s.fs, nil, nil
+}
+
+//destStorage returns mirrorer storage with dest URL rule storage options, or service storage
+func (s *service) destStorage(ctx context.Context, sourceURL, destURL string) (afs.Service, []storage.Option, error) {
+	if storager, ok := s.mirrorer.(Storage); ok {
+		return storager.DestStorage(ctx, sourceURL, destURL)
+	}
+	return s.fs, nil, nil
+}
+
+func (s *service) migrate(ctx context.Context, fs afs.Service, options []storage.Option, request *Request, response *Response) error {
+	request.Init()
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	checkpoint, err := s.loadCheckpoint(ctx, fs, request)
+	if err != nil {
+		return err
+	}
+	objects, err := fs.List(ctx, request.SourceURL, append([]storage.Option{option.NewRecursive(true)}, options...)...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to list %v", request.SourceURL)
+	}
+	limiter := newBandwidth(request.BytesPerSec)
+	objectChan := make(chan storage.Object, request.Parallelism)
+	var transferred = make([]*transferredObject, 0)
+	mux := &sync.Mutex{}
+	processed := 0
+	waitGroup := &sync.WaitGroup{}
+	for i := 0; i < request.Parallelism; i++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for object := range objectChan {
+				waited := limiter.Wait(object.Size())
+				mirrorRequest := contract.NewRequest(object.URL())
+				mirrorRequest.SkipActions = !request.RunActions
+				mirrorResponse := s.mirrorer.Mirror(ctx, mirrorRequest)
+				mux.Lock()
+				response.ThrottleTimeMs += int(waited / time.Millisecond)
+				s.record(object, mirrorResponse, checkpoint, response)
+				if mirrorResponse.Status == base.StatusOK {
+					transferred = append(transferred, succeededObjects(object.URL(), mirrorResponse)...)
+				}
+				processed++
+				if processed%request.CheckpointEvery == 0 {
+					if err := s.saveCheckpoint(ctx, fs, request, checkpoint); err != nil {
+						response.Error = err.Error()
+					}
+				}
+				mux.Unlock()
+			}
+		}()
+	}
+	for _, object := range objects {
+		if object.IsDir() {
+			continue
+		}
+		response.Total++
+		if checkpoint.IsCompleted(object.URL()) {
+			response.Resumed++
+			continue
+		}
+		objectChan <- object
+	}
+	close(objectChan)
+	waitGroup.Wait()
+	if err = s.saveCheckpoint(ctx, fs, request, checkpoint); err != nil {
+		return err
+	}
+	s.reconcile(ctx, transferred, response)
+	return nil
+}
+
+//record updates migration counters and checkpoint with mirror response
+func (s *service) record(object storage.Object, mirrorResponse *contract.Response, checkpoint *Checkpoint, response *Response) {
+	switch mirrorResponse.Status {
+	case base.StatusOK:
+		response.Migrated++
+		response.Bytes += mirrorResponse.FileSize
+		checkpoint.Complete(object.URL())
+	case base.StatusNoMatch, base.StatusDisabled, base.StatusEmpty, base.StatusCompletion, base.StatusSidecar, base.StatusDuplicate:
+		response.NoMatch++
+		checkpoint.Complete(object.URL())
+	case base.StatusNoFound, base.StatusPartial:
+		response.Skipped++
+	default:
+		message := mirrorResponse.Error
+		if message == "" {
+			message = mirrorResponse.Status
+		}
+		response.Failed++
+		response.Failures = append(response.Failures, &Failure{URL: object.URL(), Error: message})
+	}
+}
+
+//transferredObject represents destination object reported as transferred
+type transferredObject struct {
+	sourceURL string
+	*contract.DestOutcome
+}
+
+//reconcile checks that transferred destination objects exist with reported size
+func (s *service) reconcile(ctx context.Context, transferred []*transferredObject, response *Response) {
+	for _, dest := range transferred {
+		if url.Scheme(dest.URL, "") == "" { //topic or queue
+			continue
+		}
+		fs, options, err := s.destStorage(ctx, dest.sourceURL, dest.URL)
+		if err != nil {
+			response.Unreconciled = append(response.Unreconciled, dest.URL)
+			continue
+		}
+		object, err := fs.Object(ctx, dest.URL, options...)
+		if err != nil || (dest.Bytes > 0 && object.Size() != dest.Bytes) {
+			response.Unreconciled = append(response.Unreconciled, dest.URL)
+		}
+	}
+}
+
+func succeededObjects(sourceURL string, response *contract.Response) []*transferredObject {
+	var result = make([]*transferredObject, 0)
+	for _, outcome := range response.DestOutcomes {
+		if outcome.Status == base.StatusOK {
+			result = append(result, &transferredObject{sourceURL: sourceURL, DestOutcome: outcome})
+		}
+	}
+	return result
+}
+
+func (s *service) loadCheckpoint(ctx context.Context, fs afs.Service, request *Request) (*Checkpoint, error) {
+	checkpoint := NewCheckpoint(request.SourceURL)
+	if request.CheckpointURL == "" {
+		return checkpoint, nil
+	}
+	if exists, _ := fs.Exists(ctx, request.CheckpointURL); !exists {
+		return checkpoint, nil
+	}
+	data, err := fs.DownloadWithURL(ctx, request.CheckpointURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load checkpoint: %v", request.CheckpointURL)
+	}
+	if err = json.Unmarshal(data, checkpoint); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode checkpoint: %v", request.CheckpointURL)
+	}
+	if checkpoint.SourceURL != request.SourceURL {
+		return nil, errors.Errorf("checkpoint %v was created for %v", request.CheckpointURL, checkpoint.SourceURL)
+	}
+	if checkpoint.Completed == nil {
+		checkpoint.Completed = make(map[string]bool)
+	}
+	return checkpoint, nil
+}
+
+func (s *service) saveCheckpoint(ctx context.Context, fs afs.Service, request *Request, checkpoint *Checkpoint) error {
+	if request.CheckpointURL == "" {
+		return nil
+	}
+	checkpoint.mux.Lock()
+	defer checkpoint.mux.Unlock()
+	return s.upload(ctx, fs, request.CheckpointURL, checkpoint)
+}
+
+func (s *service) upload(ctx context.Context, fs afs.Service, URL string, source interface{}) error {
+	data, err := json.Marshal(source)
+	if err != nil {
+		return err
+	}
+	return fs.Upload(ctx, URL, file.DefaultFileOsMode, bytes.NewReader(data))
+}
+
+//New creates a migration service, mirrorer implementing Storage supplies storage service and rule storage options
+func New(mirrorer Mirrorer) Service {
+	return &service{
+		fs:       afs.New(),
+		mirrorer: mirrorer,
+	}
+}
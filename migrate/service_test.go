@@ -0,0 +1,123 @@
+package migrate
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type copyMirrorer struct {
+	fs      afs.Service
+	destURL string
+	calls   int32
+}
+
+func (m *copyMirrorer) Mirror(ctx context.Context, request *contract.Request) *contract.Response {
+	atomic.AddInt32(&m.calls, 1)
+	response := contract.NewResponse(request.URL)
+	response.FileSize = 6
+	if strings.HasSuffix(request.URL, ".txt") {
+		response.Status = base.StatusNoMatch
+		return response
+	}
+	if strings.HasSuffix(request.URL, ".bad") {
+		response.Status = base.StatusError
+		response.Error = "corrupted file"
+		return response
+	}
+	destURL := url.Join(m.destURL, url.Path(request.URL))
+	err := m.fs.Copy(ctx, request.URL, destURL)
+	response.AddOutcome(destURL, 0, err)
+	return response
+}
+
+func TestService_Migrate(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/migrate/source/"
+	for _, name := range []string{"2019/a.csv", "2019/b.csv", "2020/c.csv", "2020/readme.txt", "2020/d.bad"} {
+		_ = fs.Upload(ctx, url.Join(baseURL, name), 0644, strings.NewReader("line1\n"))
+	}
+	mirrorer := &copyMirrorer{fs: fs, destURL: "mem://localhost/migrate/dest"}
+	service := New(mirrorer)
+	request := &Request{
+		SourceURL:     baseURL,
+		CheckpointURL: "mem://localhost/migrate/checkpoint.json",
+		ReportURL:     "mem://localhost/migrate/report.json",
+		Parallelism:   2,
+	}
+	response := service.Migrate(ctx, request)
+	assert.Equal(t, base.StatusError, response.Status)
+	assert.Equal(t, 5, response.Total)
+	assert.Equal(t, 3, response.Migrated)
+	assert.Equal(t, 1, response.NoMatch)
+	assert.Equal(t, 1, response.Failed)
+	assert.Equal(t, int64(18), response.Bytes)
+	assert.Equal(t, 0, len(response.Unreconciled))
+	exists, _ := fs.Exists(ctx, request.ReportURL)
+	assert.True(t, exists)
+
+	mirrorer.calls = 0
+	response = service.Migrate(ctx, request)
+	assert.Equal(t, 4, response.Resumed)
+	assert.Equal(t, int32(1), mirrorer.calls)
+}
+
+//storageMirrorer reports transferred bytes and supplies rule storage options
+type storageMirrorer struct {
+	copyMirrorer
+	mux     sync.Mutex
+	options map[string][]storage.Option
+}
+
+func (m *storageMirrorer) Mirror(ctx context.Context, request *contract.Request) *contract.Response {
+	response := m.copyMirrorer.Mirror(ctx, request)
+	for _, outcome := range response.DestOutcomes {
+		outcome.Bytes = 6
+		if strings.HasSuffix(outcome.URL, "b.csv") {
+			outcome.Bytes = 7
+		}
+	}
+	return response
+}
+
+func (m *storageMirrorer) SourceStorage(ctx context.Context, sourceURL string) (afs.Service, []storage.Option, error) {
+	return m.storage(sourceURL, option.NewPage(0, 100))
+}
+
+func (m *storageMirrorer) DestStorage(ctx context.Context, sourceURL, destURL string) (afs.Service, []storage.Option, error) {
+	return m.storage(destURL, option.NewPage(0, 1))
+}
+
+func (m *storageMirrorer) storage(URL string, options ...storage.Option) (afs.Service, []storage.Option, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.options[URL] = options
+	return m.fs, options, nil
+}
+
+func TestService_MigrateStorage(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/migrate/storage/source/"
+	for _, name := range []string{"a.csv", "b.csv"} {
+		_ = fs.Upload(ctx, url.Join(baseURL, name), 0644, strings.NewReader("line1\n"))
+	}
+	mirrorer := &storageMirrorer{copyMirrorer: copyMirrorer{fs: fs, destURL: "file://" + t.TempDir()}, options: map[string][]storage.Option{}}
+	response := New(mirrorer).Migrate(ctx, &Request{SourceURL: baseURL, Parallelism: 1})
+	assert.Equal(t, 2, response.Migrated)
+	assert.Equal(t, 1, len(mirrorer.options[baseURL]))
+	assert.Equal(t, 3, len(mirrorer.options))
+	if assert.Equal(t, 1, len(response.Unreconciled)) {
+		assert.True(t, strings.HasSuffix(response.Unreconciled[0], "b.csv"))
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/viant/smirror/contract"
 	"github.com/viant/smirror/event"
 	"github.com/viant/smirror/shared"
+	"time"
 )
 
 //StorageMirror cloud function entry point
@@ -25,15 +26,35 @@ func StorageMirror(ctx context.Context, event event.StorageEvent) (err error) {
 	return err
 }
 
+//StorageMirrorRequeue cloud function entry point for events republished by requeue action, function has to be deployed with retry,
+//an event before its notBefore delay returns error, so that Pub/Sub redelivers it with subscription retry backoff
+func StorageMirrorRequeue(ctx context.Context, notification event.PubsubBucketNotification) (err error) {
+	storageEvent := notification.StorageEvent()
+	if storageEvent == nil {
+		return nil
+	}
+	if notBefore := storageEvent.NotBefore; notBefore != nil && time.Now().Before(*notBefore) {
+		return errors.Errorf("requeued event %v is delayed till %v", storageEvent.URL(), notBefore.Format(time.RFC3339))
+	}
+	return StorageMirror(ctx, *storageEvent)
+}
+
 func storageMirror(ctx context.Context, event event.StorageEvent) (response *contract.Response, err error) {
 	service, err := NewFromEnv(ctx, base.ConfigEnvKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create storage mirror: %v", err)
 	}
-	response = service.Mirror(ctx, contract.NewRequest(event.URL()))
-	shared.LogLn(response)
+	request := contract.NewRequest(event.URL())
+	request.Requeue = event.Attempt
+	request.CorrelationID = event.Metadata[base.CorrelationIDKey]
+	request.Generation = event.GenerationNumber()
+	response = service.Mirror(ctx, request)
+	if !response.IsLogSuppressed() {
+		shared.LogLn(response)
+	}
 	//Schema error
-	if response.Error != "" && response.SchemaError == ""{
+	//only retryable errors are returned, so platform retries do not repeat terminal failures
+	if response.IsRetryable() {
 		return nil, fmt.Errorf(response.Error)
 	}
 	return response, nil
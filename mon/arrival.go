@@ -0,0 +1,186 @@
+package mon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/job"
+	"github.com/viant/smirror/secret"
+	"github.com/viant/smirror/slack"
+	"time"
+)
+
+const (
+	//AnomalyDrop no arrivals while baseline expects files
+	AnomalyDrop = "drop"
+	//AnomalySpike arrivals exceed baseline by spike factor
+	AnomalySpike = "spike"
+
+	defaultSpikeFactor   = 3.0
+	defaultMinBaseline   = 1.0
+	defaultSmoothing     = 0.2
+	defaultWarmupWindows = 3
+)
+
+//Arrival represents per rule rate of arrival anomaly detection settings
+type Arrival struct {
+	//StateURL per rule arrival baseline state URL
+	StateURL string
+	//SpikeFactor alerts when files/hour exceeds baseline by factor, default 3
+	SpikeFactor float64 `json:",omitempty"`
+	//MinBaseline min baseline files/hour to alert on zero arrivals, default 1
+	MinBaseline float64 `json:",omitempty"`
+	//Smoothing baseline moving average weight of the latest window, default 0.2
+	Smoothing float64 `json:",omitempty"`
+	//WarmupWindows number of checks building baseline before alerting, default 3
+	WarmupWindows int `json:",omitempty"`
+	//OnAnomaly alert actions, $Error holds anomaly description, ${Response.Kind} drop or spike
+	OnAnomaly        []*job.Action     `json:",omitempty"`
+	SlackCredentials *auth.Credentials `json:",omitempty"`
+}
+
+//Baseline represents rule arrival baseline
+type Baseline struct {
+	PerHour float64
+	Windows int
+	Updated time.Time
+}
+
+//Anomaly represents rule arrival anomaly
+type Anomaly struct {
+	Workflow string
+	Kind     string
+	PerHour  float64
+	Baseline float64
+}
+
+//Init initialises arrival settings
+func (a *Arrival) Init() {
+	if a.SpikeFactor == 0 {
+		a.SpikeFactor = defaultSpikeFactor
+	}
+	if a.MinBaseline == 0 {
+		a.MinBaseline = defaultMinBaseline
+	}
+	if a.Smoothing == 0 {
+		a.Smoothing = defaultSmoothing
+	}
+	if a.WarmupWindows == 0 {
+		a.WarmupWindows = defaultWarmupWindows
+	}
+}
+
+//Validate checks if arrival settings are valid
+func (a *Arrival) Validate() error {
+	if a.StateURL == "" {
+		return errors.New("arrival stateURL was empty")
+	}
+	if a.Smoothing < 0 || a.Smoothing > 1 {
+		return errors.Errorf("invalid arrival smoothing: %v", a.Smoothing)
+	}
+	return nil
+}
+
+//Detect returns anomaly kind for files/hour and baseline or empty string
+func (a *Arrival) Detect(baseline *Baseline, perHour float64) string {
+	if baseline == nil || baseline.Windows < a.WarmupWindows {
+		return ""
+	}
+	if perHour == 0 && baseline.PerHour >= a.MinBaseline {
+		return AnomalyDrop
+	}
+	if baseline.PerHour > 0 && perHour > a.SpikeFactor*baseline.PerHour {
+		return AnomalySpike
+	}
+	return ""
+}
+
+//Update updates baseline moving average with files/hour
+func (a *Arrival) Update(baseline *Baseline, perHour float64, now time.Time) {
+	if baseline.Windows == 0 {
+		baseline.PerHour = perHour
+	} else {
+		baseline.PerHour = a.Smoothing*perHour + (1-a.Smoothing)*baseline.PerHour
+	}
+	baseline.Windows++
+	baseline.Updated = now
+}
+
+//checkArrival compares rules arrivals within processed recency window with baseline
+func (s *service) checkArrival(ctx context.Context, request *Request, rules []*config.Rule, response *Response) error {
+	arrival := request.Arrival
+	baselines, err := s.loadBaselines(ctx, arrival.StateURL)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	hours := now.Sub(*request.processedModifiedAfter).Hours()
+	if hours <= 0 {
+		return nil
+	}
+	checked := map[string]bool{}
+	for _, rule := range rules {
+		workflow := rule.Info.Workflow
+		if rule.Disabled || workflow == "" || checked[workflow] {
+			continue
+		}
+		checked[workflow] = true
+		count := 0
+		if info, ok := response.rulesMap[workflow]; ok {
+			count = info.ProcessedCount
+		}
+		perHour := float64(count) / hours
+		baseline, ok := baselines[workflow]
+		if !ok {
+			baseline = &Baseline{}
+			baselines[workflow] = baseline
+		}
+		if kind := arrival.Detect(baseline, perHour); kind != "" {
+			anomaly := &Anomaly{Workflow: workflow, Kind: kind, PerHour: perHour, Baseline: baseline.PerHour}
+			response.Anomalies = append(response.Anomalies, anomaly)
+			if err = s.alert(ctx, arrival, rule, anomaly); err != nil {
+				response.Error = err.Error()
+			}
+		}
+		arrival.Update(baseline, perHour, now)
+	}
+	data, err := json.Marshal(baselines)
+	if err != nil {
+		return err
+	}
+	return s.fs.Upload(ctx, arrival.StateURL, file.DefaultFileOsMode, bytes.NewReader(data))
+}
+
+func (s *service) loadBaselines(ctx context.Context, URL string) (map[string]*Baseline, error) {
+	var result = make(map[string]*Baseline)
+	if exists, _ := s.fs.Exists(ctx, URL); !exists {
+		return result, nil
+	}
+	data, err := s.fs.DownloadWithURL(ctx, URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load arrival state: %v", URL)
+	}
+	return result, json.Unmarshal(data, &result)
+}
+
+//alert runs anomaly alert actions
+func (s *service) alert(ctx context.Context, arrival *Arrival, rule *config.Rule, anomaly *Anomaly) error {
+	if len(arrival.OnAnomaly) == 0 {
+		return nil
+	}
+	err := fmt.Errorf("%v arrival %v: %.2f files/hour, baseline: %.2f files/hour", anomaly.Workflow, anomaly.Kind, anomaly.PerHour, anomaly.Baseline)
+	jobContext := job.NewContext(ctx, err, rule.Info.URL, "")
+	jobContext.Labels = rule.Labels
+	jobContext.Rule = rule
+	jobContext.Response = anomaly
+	jobContext.StartTime = time.Now()
+	notifier := slack.NewSlack(s.Region, s.ProjectID, s.fs, secret.New(s.SourceScheme, s.fs), arrival.SlackCredentials)
+	actions := &job.Actions{OnFailure: arrival.OnAnomaly}
+	return actions.Run(jobContext, s.fs, notifier.Notify, &rule.Info, anomaly)
+}
+
@@ -0,0 +1,27 @@
+package mon
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestArrival_Detect(t *testing.T) {
+	arrival := &Arrival{StateURL: "mem://localhost/mon/arrival.json"}
+	arrival.Init()
+	baseline := &Baseline{}
+	now := time.Now()
+	for _, perHour := range []float64{10, 12, 8} {
+		assert.Equal(t, "", arrival.Detect(baseline, perHour), "warmup")
+		arrival.Update(baseline, perHour, now)
+	}
+	assert.Equal(t, 3, baseline.Windows)
+	assert.InDelta(t, 9.92, baseline.PerHour, 0.01)
+
+	assert.Equal(t, "", arrival.Detect(baseline, 11))
+	assert.Equal(t, AnomalyDrop, arrival.Detect(baseline, 0))
+	assert.Equal(t, AnomalySpike, arrival.Detect(baseline, 40))
+
+	quiet := &Baseline{PerHour: 0.1, Windows: 5}
+	assert.Equal(t, "", arrival.Detect(quiet, 0), "below min baseline")
+}
@@ -30,6 +30,8 @@ type Request struct {
 	ConfigURL  string
 	TriggerURL string
 	ErrorURL   string
+	//Arrival per rule rate of arrival anomaly detection, uses ProcessedURL arrivals within ProcessedRecency
+	Arrival *Arrival `json:",omitempty"`
 }
 
 //Response represents monitoring response
@@ -41,6 +43,7 @@ type Response struct {
 	ErrorCount       int
 	Rules            []*RuleInfo `json:",omitempty"`
 	Errors           []*Error    `json:",omitempty"`
+	Anomalies        []*Anomaly  `json:",omitempty"`
 	Status           string
 	Error            string `json:",omitempty"`
 	rulesMap         map[string]*RuleInfo
@@ -62,6 +65,9 @@ func (r *Response) AddUnprocessed(now time.Time, route *config.Rule, file storag
 	workflow, ok := r.rulesMap[info.Workflow]
 	if !ok {
 		workflow = NewWorkflow(info)
+		if route != nil {
+			workflow.Labels = route.Labels
+		}
 		r.Rules = append(r.Rules, workflow)
 		r.rulesMap[info.Workflow] = workflow
 	}
@@ -86,6 +92,9 @@ func (r *Response) AddProcessed(route *config.Rule, object storage.Object) {
 	workflow, ok := r.rulesMap[info.Workflow]
 	if !ok {
 		workflow = NewWorkflow(info)
+		if route != nil {
+			workflow.Labels = route.Labels
+		}
 		r.Rules = append(r.Rules, workflow)
 		r.rulesMap[info.Workflow] = workflow
 	}
@@ -132,6 +141,9 @@ func (r *Request) Init() (err error) {
 	if r.errorModifiedAfter, err = toolbox.TimeAt(r.ErrorRecency); err != nil {
 		return errors.Wrapf(err, "invalid ErrorRecency: %v", r.ErrorRecency)
 	}
+	if r.Arrival != nil {
+		r.Arrival.Init()
+	}
 	return err
 }
 
@@ -143,6 +155,12 @@ func (r *Request) Validate() (err error) {
 	if r.TriggerURL == "" {
 		return errors.Errorf("triggerURL was empty")
 	}
+	if r.Arrival != nil {
+		if r.ProcessedURL == "" {
+			return errors.Errorf("processedURL was empty, arrival detection requires processed files")
+		}
+		return r.Arrival.Validate()
+	}
 	return nil
 }
 
@@ -7,6 +7,7 @@ import (
 //RuleInfo represents workflow info with unprocessed files
 type RuleInfo struct {
 	base.Info
+	Labels           map[string]string `json:",omitempty"`
 	ProcessedCount   int
 	MaxProcessedSize int
 	MinProcessedSize int
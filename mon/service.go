@@ -41,6 +41,8 @@ func (s *service) Check(ctx context.Context, request *Request) *Response {
 	} else if len(response.Errors) > 0 {
 		response.Status = base.StatusError
 		response.Error = response.Errors[0].Message
+	} else if len(response.Anomalies) > 0 {
+		response.Status = base.StatusAnomaly
 	}
 	return response
 }
@@ -49,6 +51,9 @@ func (s *service) check(ctx context.Context, request *Request, response *Respons
 	if err = request.Init(); err != nil {
 		return err
 	}
+	if err = request.Validate(); err != nil {
+		return err
+	}
 	if request.ErrorURL != "" {
 		if err = s.checkErrors(ctx, request, response); err != nil {
 			return err
@@ -139,6 +144,9 @@ func (s *service) checkProcessed(ctx context.Context, request *Request, response
 		}
 		response.AddProcessed(route, object)
 	}
+	if request.Arrival != nil {
+		return s.checkArrival(ctx, request, routes.Mirrors.Snapshot(), response)
+	}
 	return nil
 }
 
@@ -7,12 +7,19 @@ import (
 	"github.com/pkg/errors"
 	"log"
 	"net/http"
+	"github.com/viant/smirror/auth"
 	"github.com/viant/smirror/base"
 	"github.com/viant/smirror/mon"
 )
 
 //StorageMirrorMonitor cloud function entry point
 func StorageMonitor(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, auth.EndpointMonitor) {
+		return
+	}
+	if !validRequest(w, r, pathMonitor) {
+		return
+	}
 	if r.ContentLength > 0 {
 		defer func() {
 			_ = r.Body.Close()
@@ -0,0 +1,100 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/mqtt"
+	"io"
+	"io/ioutil"
+	"sync"
+)
+
+//mqttClients clients keyed by broker URL, client ID and credentials, to reuse connections
+var mqttClients = sync.Map{}
+
+//arrivalEvent represents file arrival event published for files larger than MQTT.DataMaxBytes
+type arrivalEvent struct {
+	Source        string
+	TransferID    string
+	CorrelationID string `json:",omitempty"`
+	Partition     string `json:",omitempty"`
+	Size          int64
+}
+
+func mqttClient(resource *config.Resource) (*mqtt.Client, error) {
+	key := resource.URL + "/" + resource.MQTT.ClientID
+	var credentials *mqtt.Credentials
+	if resource.Credentials != nil {
+		if len(resource.Credentials.Auth) == 0 {
+			return nil, errors.New("mqtt credentials were not decrypted")
+		}
+		key += "/" + string(resource.Credentials.Auth)
+		var err error
+		if credentials, err = mqtt.NewCredentials(resource.Credentials.Auth); err != nil {
+			return nil, err
+		}
+	}
+	if client, ok := mqttClients.Load(key); ok {
+		return client.(*mqtt.Client), nil
+	}
+	client, err := mqtt.New(resource.URL, resource.MQTT.ClientID, credentials)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := mqttClients.LoadOrStore(key, client)
+	return actual.(*mqtt.Client), nil
+}
+
+//publishMQTT publishes small transfer data as payload, or file arrival event otherwise
+func (s *service) publishMQTT(ctx context.Context, transfer *Transfer, response *contract.Response) error {
+	client, err := mqttClient(transfer.Resource)
+	if err != nil {
+		return base.NewCodedError(base.ErrorCodeAuth, err)
+	}
+	reader, err := transfer.GetReader()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get reader for: %v", transfer.Resource.URL)
+	}
+	settings := transfer.Resource.MQTT
+	data, err := ioutil.ReadAll(io.LimitReader(reader, settings.DataMaxBytes+1))
+	if err != nil {
+		return err
+	}
+	payload := data
+	if !settings.IsDataMode(len(data)) {
+		remaining, err := io.Copy(ioutil.Discard, reader)
+		if err != nil {
+			return err
+		}
+		event := &arrivalEvent{
+			Source:        response.TriggeredBy,
+			TransferID:    response.TransferID,
+			CorrelationID: response.CorrelationID,
+			Partition:     transfer.partition,
+			Size:          int64(len(data)) + remaining,
+		}
+		if payload, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+	message := &mqtt.Message{
+		Topic:   settings.ExpandTopic(response.TriggeredBy, transfer.partition),
+		QoS:     settings.QoS,
+		Retain:  settings.Retain,
+		Payload: payload,
+	}
+	waited, err := s.limiter.Wait(ctx, transfer.Resource.URL)
+	response.AddThrottleTime(waited)
+	if err != nil {
+		return err
+	}
+	if err = client.Publish(ctx, message); err != nil {
+		s.limiter.Report(transfer.Resource.URL, err)
+		return err
+	}
+	return nil
+}
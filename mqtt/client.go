@@ -0,0 +1,352 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	packetConnect = 0x10
+	packetConnAck = 0x20
+	packetPublish = 0x30
+	packetPubAck  = 0x40
+	packetPubRec  = 0x50
+	packetPubRel  = 0x62
+	packetPubComp = 0x70
+
+	protocolLevel  = 4 //MQTT 3.1.1
+	defaultTimeout = 30 * time.Second
+)
+
+var tlsSchemes = map[string]bool{"mqtts": true, "ssl": true, "tls": true}
+var defaultPorts = map[string]string{"mqtt": "1883", "tcp": "1883", "mqtts": "8883", "ssl": "8883", "tls": "8883"}
+
+//Credentials represents decrypted broker credentials, username and password and/or TLS client certificate
+type Credentials struct {
+	Username string `json:",omitempty"`
+	Password string `json:",omitempty"`
+	//Certificate PEM encoded client certificate
+	Certificate string `json:",omitempty"`
+	//Key PEM encoded client certificate private key
+	Key string `json:",omitempty"`
+	//CA optional PEM encoded broker CA certificate(s), system pool is used otherwise
+	CA string `json:",omitempty"`
+}
+
+//Message represents a message
+type Message struct {
+	Topic   string
+	QoS     byte
+	Retain  bool
+	Payload []byte
+}
+
+//Client represents minimal MQTT 3.1.1 publisher
+type Client struct {
+	address     string
+	useTLS      bool
+	clientID    string
+	credentials *Credentials
+	tlsConfig   *tls.Config
+	mux         sync.Mutex
+	conn        net.Conn
+	reader      *bufio.Reader
+	packetID    uint16
+}
+
+//Publish publishes messages, QoS 1 and 2 messages are acknowledged before returning
+func (c *Client) Publish(ctx context.Context, messages ...*Message) error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	reused := c.conn != nil
+	err := c.publish(ctx, messages)
+	if err != nil && reused {
+		//broker may have dropped idle connection, retry once on new connection
+		err = c.publish(ctx, messages)
+	}
+	return err
+}
+
+func (c *Client) publish(ctx context.Context, messages []*Message) (err error) {
+	if err = c.connect(ctx); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			c.close()
+		}
+	}()
+	c.setDeadline(ctx)
+	for _, message := range messages {
+		if message.QoS > 2 {
+			return errors.Errorf("unsupported QoS: %v", message.QoS)
+		}
+		var ID uint16
+		body := new(bytes.Buffer)
+		writeString(body, message.Topic)
+		if message.QoS > 0 {
+			c.packetID++
+			if c.packetID == 0 {
+				c.packetID = 1
+			}
+			ID = c.packetID
+			_ = binary.Write(body, binary.BigEndian, ID)
+		}
+		body.Write(message.Payload)
+		header := byte(packetPublish) | message.QoS<<1
+		if message.Retain {
+			header |= 1
+		}
+		if err = c.write(header, body.Bytes()); err != nil {
+			return err
+		}
+		switch message.QoS {
+		case 1:
+			err = c.expect(packetPubAck, ID)
+		case 2:
+			if err = c.expect(packetPubRec, ID); err == nil {
+				if err = c.write(packetPubRel, packetIDBytes(ID)); err == nil {
+					err = c.expect(packetPubComp, ID)
+				}
+			}
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to publish to %v", message.Topic)
+		}
+	}
+	return nil
+}
+
+//expect reads packets until acknowledgement of expected type and packet ID
+func (c *Client) expect(packetType byte, ID uint16) error {
+	for {
+		header, body, err := c.read()
+		if err != nil {
+			return err
+		}
+		if header&0xF0 == packetType&0xF0 && len(body) >= 2 && binary.BigEndian.Uint16(body) == ID {
+			return nil
+		}
+	}
+}
+
+func (c *Client) write(header byte, body []byte) error {
+	packet := []byte{header}
+	size := len(body)
+	for {
+		digit := byte(size % 128)
+		size /= 128
+		if size > 0 {
+			digit |= 0x80
+		}
+		packet = append(packet, digit)
+		if size == 0 {
+			break
+		}
+	}
+	_, err := c.conn.Write(append(packet, body...))
+	return err
+}
+
+func (c *Client) read() (byte, []byte, error) {
+	header, err := c.reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	size, multiplier := 0, 1
+	for i := 0; ; i++ {
+		digit, err := c.reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		size += int(digit&0x7F) * multiplier
+		if digit&0x80 == 0 {
+			break
+		}
+		if multiplier *= 128; i == 3 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+	}
+	body := make([]byte, size)
+	if _, err = io.ReadFull(c.reader, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+func (c *Client) connect(ctx context.Context) (err error) {
+	if c.conn != nil {
+		return nil
+	}
+	dialer := &net.Dialer{Timeout: defaultTimeout}
+	if c.useTLS {
+		c.conn, err = (&tls.Dialer{NetDialer: dialer, Config: c.tlsConfig}).DialContext(ctx, "tcp", c.address)
+	} else {
+		c.conn, err = dialer.DialContext(ctx, "tcp", c.address)
+	}
+	if err != nil {
+		c.conn = nil
+		return errors.Wrapf(err, "failed to connect to %v", c.address)
+	}
+	c.reader = bufio.NewReader(c.conn)
+	if err = c.handshake(ctx); err != nil {
+		c.close()
+		return errors.Wrapf(err, "failed to connect to %v", c.address)
+	}
+	return nil
+}
+
+func (c *Client) handshake(ctx context.Context) error {
+	c.setDeadline(ctx)
+	body := new(bytes.Buffer)
+	writeString(body, "MQTT")
+	flags := byte(0x02) //clean session
+	if c.credentials != nil && c.credentials.Username != "" {
+		flags |= 0x80
+		if c.credentials.Password != "" {
+			flags |= 0x40
+		}
+	}
+	body.Write([]byte{protocolLevel, flags, 0, 0}) //keep alive disabled
+	writeString(body, c.clientID)
+	if flags&0x80 != 0 {
+		writeString(body, c.credentials.Username)
+	}
+	if flags&0x40 != 0 {
+		writeString(body, c.credentials.Password)
+	}
+	if err := c.write(packetConnect, body.Bytes()); err != nil {
+		return err
+	}
+	header, ack, err := c.read()
+	if err != nil {
+		return err
+	}
+	if header != packetConnAck || len(ack) != 2 {
+		return errors.Errorf("expected CONNACK, but had: %x", header)
+	}
+	if code := ack[1]; code != 0 {
+		return errors.Errorf("connection refused, return code: %v", code)
+	}
+	return nil
+}
+
+func (c *Client) setDeadline(ctx context.Context) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+	_ = c.conn.SetDeadline(deadline)
+}
+
+func (c *Client) close() {
+	if c.conn != nil {
+		_ = c.conn.Close()
+	}
+	c.conn = nil
+	c.reader = nil
+}
+
+//Close disconnects from broker
+func (c *Client) Close() error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.conn != nil {
+		_ = c.write(0xE0, nil) //DISCONNECT
+	}
+	c.close()
+	return nil
+}
+
+func writeString(buffer *bytes.Buffer, value string) {
+	_ = binary.Write(buffer, binary.BigEndian, uint16(len(value)))
+	buffer.WriteString(value)
+}
+
+func packetIDBytes(ID uint16) []byte {
+	result := make([]byte, 2)
+	binary.BigEndian.PutUint16(result, ID)
+	return result
+}
+
+//NewCredentials creates credentials from decrypted secret JSON
+func NewCredentials(secret []byte) (*Credentials, error) {
+	secret = bytes.TrimSpace(secret)
+	if len(secret) == 0 {
+		return nil, errors.New("mqtt credentials were empty")
+	}
+	credentials := &Credentials{}
+	if err := json.Unmarshal(secret, credentials); err != nil {
+		return nil, errors.Wrap(err, "failed to decode mqtt credentials")
+	}
+	if (credentials.Certificate == "") != (credentials.Key == "") {
+		return nil, errors.New("mqtt client certificate requires both Certificate and Key")
+	}
+	return credentials, nil
+}
+
+//New creates a client for mqtt://, tcp://, mqtts://, ssl:// or tls:// broker URL
+func New(URL, clientID string, credentials *Credentials) (*Client, error) {
+	parsed, err := url.Parse(URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid mqtt URL: %v", URL)
+	}
+	port, ok := defaultPorts[parsed.Scheme]
+	if !ok {
+		return nil, errors.Errorf("unsupported mqtt URL scheme: %v", parsed.Scheme)
+	}
+	if clientID == "" {
+		random := make([]byte, 6)
+		_, _ = rand.Read(random)
+		clientID = "smirror-" + hex.EncodeToString(random)
+	}
+	client := &Client{address: parsed.Host, useTLS: tlsSchemes[parsed.Scheme], clientID: clientID, credentials: credentials}
+	if parsed.Port() == "" {
+		client.address = net.JoinHostPort(parsed.Hostname(), port)
+	}
+	if client.credentials == nil && parsed.User != nil {
+		password, _ := parsed.User.Password()
+		client.credentials = &Credentials{Username: parsed.User.Username(), Password: password}
+	}
+	if client.useTLS {
+		if client.tlsConfig, err = newTLSConfig(parsed.Hostname(), client.credentials); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+func newTLSConfig(host string, credentials *Credentials) (*tls.Config, error) {
+	config := &tls.Config{ServerName: host}
+	if credentials == nil {
+		return config, nil
+	}
+	if credentials.Certificate != "" {
+		certificate, err := tls.X509KeyPair([]byte(credentials.Certificate), []byte(credentials.Key))
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid mqtt client certificate")
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	if credentials.CA != "" {
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM([]byte(credentials.CA)) {
+			return nil, fmt.Errorf("invalid mqtt CA certificate")
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,112 @@
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"sync"
+	"testing"
+)
+
+//broker emulates MQTT broker, it records published topics and payloads
+type broker struct {
+	listener  net.Listener
+	mux       sync.Mutex
+	username  string
+	published map[string]string
+}
+
+func (b *broker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(conn)
+	}
+}
+
+func (b *broker) handle(conn net.Conn) {
+	defer conn.Close()
+	client := &Client{conn: conn, reader: bufio.NewReader(conn)}
+	for {
+		header, body, err := client.read()
+		if err != nil {
+			return
+		}
+		switch header & 0xF0 {
+		case packetConnect:
+			usernameOffset := 10 + 2 + int(binary.BigEndian.Uint16(body[10:]))
+			b.mux.Lock()
+			b.username = string(body[usernameOffset+2 : usernameOffset+2+int(binary.BigEndian.Uint16(body[usernameOffset:]))])
+			b.mux.Unlock()
+			_ = client.write(packetConnAck, []byte{0, 0})
+		case packetPublish:
+			qos := header >> 1 & 3
+			topicLength := int(binary.BigEndian.Uint16(body))
+			topic := string(body[2 : 2+topicLength])
+			offset := 2 + topicLength
+			if qos > 0 {
+				offset += 2
+			}
+			b.mux.Lock()
+			b.published[topic] = string(body[offset:])
+			b.mux.Unlock()
+			switch qos {
+			case 1:
+				_ = client.write(packetPubAck, body[2+topicLength:offset])
+			case 2:
+				_ = client.write(packetPubRec, body[2+topicLength:offset])
+			}
+		case packetPubRel & 0xF0:
+			_ = client.write(packetPubComp, body)
+		}
+	}
+}
+
+func TestClient_Publish(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+	server := &broker{listener: listener, published: map[string]string{}}
+	go server.serve()
+
+	credentials, err := NewCredentials([]byte(`{"Username":"edge","Password":"secret"}`))
+	assert.Nil(t, err)
+	client, err := New("mqtt://"+listener.Addr().String(), "", credentials)
+	if !assert.Nil(t, err) {
+		return
+	}
+	ctx := context.Background()
+	err = client.Publish(ctx,
+		&Message{Topic: "files/q0", Payload: []byte("zero")},
+		&Message{Topic: "files/q1", QoS: 1, Payload: []byte("one")},
+		&Message{Topic: "files/q2", QoS: 2, Payload: make([]byte, 300)},
+	)
+	assert.Nil(t, err)
+	server.mux.Lock()
+	assert.Equal(t, "edge", server.username)
+	assert.Equal(t, "zero", server.published["files/q0"])
+	assert.Equal(t, "one", server.published["files/q1"])
+	assert.Equal(t, 300, len(server.published["files/q2"]))
+	server.mux.Unlock()
+
+	//stale connection is re-established
+	client.conn.Close()
+	assert.Nil(t, client.Publish(ctx, &Message{Topic: "files/q1", QoS: 1, Payload: []byte("again")}))
+	assert.Nil(t, client.Close())
+	server.mux.Lock()
+	assert.Equal(t, "again", server.published["files/q1"])
+	server.mux.Unlock()
+
+	_, err = NewCredentials([]byte(`{"Certificate":"pem"}`))
+	assert.NotNil(t, err)
+	_, err = New("mqtts://localhost", "", &Credentials{Certificate: "pem", Key: "pem"})
+	assert.NotNil(t, err)
+	_, err = New("http://localhost", "", nil)
+	assert.NotNil(t, err)
+}
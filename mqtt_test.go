@@ -0,0 +1,76 @@
+package smirror
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestService_MQTT(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+	published := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			header, err := reader.ReadByte()
+			if err != nil {
+				return
+			}
+			//test packets are smaller than 128 bytes, remaining length takes one byte
+			size, _ := reader.ReadByte()
+			body := make([]byte, size)
+			_, _ = io.ReadFull(reader, body)
+			switch header & 0xF0 {
+			case 0x10:
+				_, _ = conn.Write([]byte{0x20, 2, 0, 0})
+			case 0x30:
+				published <- body[2+int(body[1]):]
+			}
+		}
+	}()
+	ctx := context.Background()
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/mqtt/data"}},
+				Dest: &config.Resource{
+					URL:  "mqtt://" + listener.Addr().String(),
+					MQTT: &config.MQTT{Topic: "files/$name", DataMaxBytes: 2},
+				},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sourceURL := "mem://localhost/mqtt/data/a.csv"
+	_ = afs.New().Upload(ctx, sourceURL, 0644, strings.NewReader("1,2,3"))
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	if !assert.Equal(t, base.StatusOK, response.Status, response.Error) {
+		return
+	}
+	event := &arrivalEvent{}
+	assert.Nil(t, json.Unmarshal(<-published, event))
+	assert.Equal(t, sourceURL, event.Source)
+	assert.Equal(t, int64(5), event.Size)
+}
@@ -13,6 +13,8 @@ type Request struct {
 	Dest       string
 	Data       []byte
 	Attributes map[string]interface{}
+	//DelaySec message delivery delay, supported by SQS
+	DelaySec int
 }
 
 //Response represents response
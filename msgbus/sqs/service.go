@@ -27,8 +27,12 @@ func (s *service) publish(ctx context.Context, request *msgbus.Request, response
 	if err != nil {
 		return err
 	}
+	delaySec := int64(1)
+	if request.DelaySec > 0 {
+		delaySec = int64(request.DelaySec)
+	}
 	input := &sqs.SendMessageInput{
-		DelaySeconds: aws.Int64(1),
+		DelaySeconds: aws.Int64(delaySec),
 		QueueUrl:     &queueURL,
 	}
 	if len(request.Attributes) > 0 {
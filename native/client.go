@@ -0,0 +1,84 @@
+package native
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/afsc/gs"
+	as3 "github.com/viant/afsc/s3"
+	gstorage "google.golang.org/api/storage/v1"
+	"os"
+	"reflect"
+)
+
+const (
+	awsCredentialsEnvKey = "AWS_CREDENTIALS"
+	awsRegionEnvKey      = "AWS_REGION"
+	awsDefaultRegion     = "us-east-1"
+)
+
+//GSService returns GCS JSON API service authorized with resource storage options (credentials, impersonation, proxy)
+func GSService(ctx context.Context, URL string, options []storage.Option) (*gstorage.Service, error) {
+	storager, err := gs.NewStorager(ctx, URL, options...)
+	if err != nil {
+		return nil, err
+	}
+	//afsc gs storager embeds *gstorage.Service
+	field := reflect.Indirect(reflect.ValueOf(storager)).FieldByName("Service")
+	if !field.IsValid() {
+		return nil, errors.Errorf("unsupported gs storager: %T", storager)
+	}
+	service, ok := field.Interface().(*gstorage.Service)
+	if !ok || service == nil {
+		return nil, errors.Errorf("unsupported gs storager: %T", storager)
+	}
+	return service, nil
+}
+
+//S3Client returns S3 client configured with resource storage options (credentials, region) the way afsc s3 storager is
+func S3Client(ctx context.Context, URL string, options []storage.Option) (*s3.S3, error) {
+	config := &aws.Config{}
+	if _, ok := option.Assign(options, &config); !ok {
+		var provider as3.AwsConfigProvider
+		var err error
+		if _, ok := option.Assign(options, &provider); ok {
+			if config, err = provider.AwsConfig(); err != nil {
+				return nil, err
+			}
+		} else if location := os.Getenv(awsCredentialsEnvKey); location != "" {
+			authConfig, err := as3.NewAuthConfig(&option.Location{Path: location})
+			if err != nil {
+				return nil, err
+			}
+			if config, err = authConfig.AwsConfig(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	config = config.Copy()
+	region := &option.Region{}
+	if _, ok := option.Assign(options, &region); ok {
+		config.Region = &region.Name
+	}
+	if awsRegion := os.Getenv(awsRegionEnvKey); awsRegion != "" {
+		config.Region = &awsRegion
+	}
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+	if aws.StringValue(config.Region) == "" {
+		region, err := s3manager.GetBucketRegion(ctx, sess, url.Host(URL), awsDefaultRegion)
+		if err != nil {
+			region = awsDefaultRegion
+		}
+		config.Region = &region
+	}
+	return s3.New(sess, config), nil
+}
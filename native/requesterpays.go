@@ -0,0 +1,361 @@
+package native
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/object"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/afsc/gs"
+	as3 "github.com/viant/afsc/s3"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"google.golang.org/api/googleapi"
+	gstorage "google.golang.org/api/storage/v1"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+//errLimitReached stops listing once page limit has been reached
+var errLimitReached = errors.New("limit reached")
+
+//UsesRequesterPays returns true if resource objects can only be accessed with provider native requester pays calls
+func UsesRequesterPays(resource *config.Resource) bool {
+	return isSupported(resource.URL) && resource.RequesterPays
+}
+
+//RequesterPays returns enabled requester pays option for gs and s3 URL or nil
+func RequesterPays(URL string, options []storage.Option) *config.RequesterPays {
+	if !isSupported(URL) {
+		return nil
+	}
+	var requesterPays *config.RequesterPays
+	option.Assign(options, &requesterPays)
+	if requesterPays == nil || !requesterPays.Enabled {
+		return nil
+	}
+	return requesterPays
+}
+
+//UserProject returns gs requester pays billing project
+func UserProject(requesterPays *config.RequesterPays) (string, error) {
+	if requesterPays == nil || !requesterPays.Enabled {
+		return "", nil
+	}
+	if requesterPays.UserProject == "" {
+		return "", errors.New("gs requester pays resource requires ProjectID")
+	}
+	return requesterPays.UserProject, nil
+}
+
+//Object returns object info with provider native requester pays call
+func Object(ctx context.Context, URL string, options []storage.Option) (storage.Object, error) {
+	requesterPays := RequesterPays(URL, options)
+	bucket, name := url.Host(URL), strings.Trim(url.Path(URL), "/")
+	switch url.Scheme(URL, "") {
+	case gs.Scheme:
+		userProject, err := UserProject(requesterPays)
+		if err != nil {
+			return nil, err
+		}
+		service, err := GSService(ctx, URL, options)
+		if err != nil {
+			return nil, err
+		}
+		call := service.Objects.Get(bucket, name).Context(ctx)
+		if userProject != "" {
+			call.UserProject(userProject)
+		}
+		gsObject, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		return gsStorageObject(URL, gsObject), nil
+	case as3.Scheme:
+		client, err := S3Client(ctx, URL, options)
+		if err != nil {
+			return nil, err
+		}
+		input := &s3.HeadObjectInput{Bucket: &bucket, Key: &name}
+		if requesterPays != nil {
+			input.RequestPayer = aws.String(s3.RequestPayerRequester)
+		}
+		output, err := client.HeadObjectWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return s3StorageObject(URL, &s3.Object{Key: &name, ETag: output.ETag, Size: output.ContentLength, LastModified: output.LastModified}), nil
+	}
+	return nil, errors.Errorf("unsupported native read scheme: %v", URL)
+}
+
+//Open opens object with provider native requester pays call, gs object is pinned to generation if specified
+func Open(ctx context.Context, URL string, generation int64, options []storage.Option) (io.ReadCloser, error) {
+	requesterPays := RequesterPays(URL, options)
+	bucket, name := url.Host(URL), strings.Trim(url.Path(URL), "/")
+	switch url.Scheme(URL, "") {
+	case gs.Scheme:
+		userProject, err := UserProject(requesterPays)
+		if err != nil {
+			return nil, err
+		}
+		service, err := GSService(ctx, URL, options)
+		if err != nil {
+			return nil, err
+		}
+		call := service.Objects.Get(bucket, name).Context(ctx)
+		if userProject != "" {
+			call.UserProject(userProject)
+		}
+		if generation > 0 {
+			call.Generation(generation)
+		}
+		response, err := call.Download()
+		if err != nil {
+			if apiErr, ok := err.(*googleapi.Error); ok && apiErr.Code == http.StatusNotFound && generation > 0 {
+				return nil, base.NewCodedError(base.ErrorCodeGenerationGone, errors.Errorf("generation %v of %v is no longer available", generation, URL))
+			}
+			return nil, err
+		}
+		return response.Body, nil
+	case as3.Scheme:
+		client, err := S3Client(ctx, URL, options)
+		if err != nil {
+			return nil, err
+		}
+		input := &s3.GetObjectInput{Bucket: &bucket, Key: &name}
+		if requesterPays != nil {
+			input.RequestPayer = aws.String(s3.RequestPayerRequester)
+		}
+		output, err := client.GetObjectWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return output.Body, nil
+	}
+	return nil, errors.Errorf("unsupported native read scheme: %v", URL)
+}
+
+//List lists folder objects with provider native requester pays calls, the first listed object is the folder itself like with afs,
+//list matcher and page options are applied
+func List(ctx context.Context, URL string, options []storage.Option) ([]storage.Object, error) {
+	requesterPays := RequesterPays(URL, options)
+	matcher, page := option.GetListOptions(options)
+	scheme, bucket := url.Scheme(URL, ""), url.Host(URL)
+	location := strings.Trim(url.Path(URL), "/")
+	prefix := location
+	if prefix != "" {
+		prefix += "/"
+	}
+	_, name := path.Split(location)
+	if name == "" {
+		name = "/"
+	}
+	var result = []storage.Object{object.New(URL, file.NewInfo(name, 0, file.DefaultDirOsMode, time.Now(), true, nil), nil)}
+	add := func(objectName string, info os.FileInfo, source interface{}) error {
+		if !matcher(prefix, info) {
+			return nil
+		}
+		page.Increment()
+		if page.ShallSkip() {
+			return nil
+		}
+		result = append(result, object.New(fmt.Sprintf("%v://%v/%v", scheme, bucket, objectName), info, source))
+		if page.HasReachedLimit() {
+			return errLimitReached
+		}
+		return nil
+	}
+	var err error
+	switch scheme {
+	case gs.Scheme:
+		err = gsList(ctx, URL, prefix, requesterPays, options, add)
+	case as3.Scheme:
+		err = s3List(ctx, URL, prefix, requesterPays, options, add)
+	default:
+		return nil, errors.Errorf("unsupported native list scheme: %v", URL)
+	}
+	if err != nil && err != errLimitReached {
+		return nil, err
+	}
+	return result, nil
+}
+
+func gsList(ctx context.Context, URL, prefix string, requesterPays *config.RequesterPays, options []storage.Option, add func(name string, info os.FileInfo, source interface{}) error) error {
+	userProject, err := UserProject(requesterPays)
+	if err != nil {
+		return err
+	}
+	service, err := GSService(ctx, URL, options)
+	if err != nil {
+		return err
+	}
+	call := service.Objects.List(url.Host(URL)).Prefix(prefix).Delimiter("/")
+	if userProject != "" {
+		call.UserProject(userProject)
+	}
+	return call.Pages(ctx, func(objects *gstorage.Objects) error {
+		for _, folder := range objects.Prefixes {
+			folder = strings.Trim(folder, "/")
+			info := file.NewInfo(path.Base(folder), 0, file.DefaultDirOsMode, time.Now(), true, nil)
+			if err := add(folder, info, nil); err != nil {
+				return err
+			}
+		}
+		for _, item := range objects.Items {
+			if strings.HasSuffix(item.Name, "/") {
+				continue
+			}
+			modified, _ := time.Parse(time.RFC3339, item.Updated)
+			info := file.NewInfo(path.Base(item.Name), int64(item.Size), file.DefaultFileOsMode, modified, false, item)
+			if err := add(item.Name, info, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func s3List(ctx context.Context, URL, prefix string, requesterPays *config.RequesterPays, options []storage.Option, add func(name string, info os.FileInfo, source interface{}) error) error {
+	client, err := S3Client(ctx, URL, options)
+	if err != nil {
+		return err
+	}
+	input := &s3.ListObjectsV2Input{Bucket: aws.String(url.Host(URL)), Prefix: &prefix, Delimiter: aws.String("/")}
+	if requesterPays != nil {
+		input.RequestPayer = aws.String(s3.RequestPayerRequester)
+	}
+	err = client.ListObjectsV2PagesWithContext(ctx, input, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, folder := range output.CommonPrefixes {
+			name := strings.Trim(aws.StringValue(folder.Prefix), "/")
+			info := file.NewInfo(path.Base(name), 0, file.DefaultDirOsMode, time.Now(), true, nil)
+			if err = add(name, info, nil); err != nil {
+				return false
+			}
+		}
+		for _, item := range output.Contents {
+			name := aws.StringValue(item.Key)
+			if strings.HasSuffix(name, "/") {
+				continue
+			}
+			info := file.NewInfo(path.Base(name), aws.Int64Value(item.Size), file.DefaultFileOsMode, aws.TimeValue(item.LastModified), false, item)
+			if err = add(name, info, item); err != nil {
+				return false
+			}
+		}
+		return true
+	})
+	return err
+}
+
+//Delete deletes object with provider native requester pays call
+func Delete(ctx context.Context, URL string, options []storage.Option) error {
+	requesterPays := RequesterPays(URL, options)
+	bucket, name := url.Host(URL), strings.Trim(url.Path(URL), "/")
+	switch url.Scheme(URL, "") {
+	case gs.Scheme:
+		userProject, err := UserProject(requesterPays)
+		if err != nil {
+			return err
+		}
+		service, err := GSService(ctx, URL, options)
+		if err != nil {
+			return err
+		}
+		call := service.Objects.Delete(bucket, name).Context(ctx)
+		if userProject != "" {
+			call.UserProject(userProject)
+		}
+		return call.Do()
+	case as3.Scheme:
+		client, err := S3Client(ctx, URL, options)
+		if err != nil {
+			return err
+		}
+		input := &s3.DeleteObjectInput{Bucket: &bucket, Key: &name}
+		if requesterPays != nil {
+			input.RequestPayer = aws.String(s3.RequestPayerRequester)
+		}
+		_, err = client.DeleteObjectWithContext(ctx, input)
+		return err
+	}
+	return errors.Errorf("unsupported native delete scheme: %v", URL)
+}
+
+//Copy copies object within the same provider with server side native requester pays call,
+//source requester pays billing takes precedence over dest one
+func Copy(ctx context.Context, sourceURL, destURL string, sourceOptions, destOptions []storage.Option) error {
+	requesterPays := RequesterPays(sourceURL, sourceOptions)
+	if requesterPays == nil {
+		requesterPays = RequesterPays(destURL, destOptions)
+	}
+	sourceBucket, sourceName := url.Host(sourceURL), strings.Trim(url.Path(sourceURL), "/")
+	destBucket, destName := url.Host(destURL), strings.Trim(url.Path(destURL), "/")
+	scheme := url.Scheme(sourceURL, "")
+	if scheme != url.Scheme(destURL, "") {
+		return errors.Errorf("unsupported native copy: %v to %v", sourceURL, destURL)
+	}
+	switch scheme {
+	case gs.Scheme:
+		userProject, err := UserProject(requesterPays)
+		if err != nil {
+			return err
+		}
+		service, err := GSService(ctx, sourceURL, sourceOptions)
+		if err != nil {
+			return err
+		}
+		call := service.Objects.Rewrite(sourceBucket, sourceName, destBucket, destName, &gstorage.Object{}).Context(ctx)
+		if userProject != "" {
+			call.UserProject(userProject)
+		}
+		for {
+			response, err := call.Do()
+			if err != nil {
+				return err
+			}
+			if response.Done {
+				return nil
+			}
+			call.RewriteToken(response.RewriteToken)
+		}
+	case as3.Scheme:
+		client, err := S3Client(ctx, sourceURL, sourceOptions)
+		if err != nil {
+			return err
+		}
+		input := &s3.CopyObjectInput{Bucket: &destBucket, Key: &destName, CopySource: aws.String(neturl.PathEscape(sourceBucket + "/" + sourceName))}
+		if requesterPays != nil {
+			input.RequestPayer = aws.String(s3.RequestPayerRequester)
+		}
+		_, err = client.CopyObjectWithContext(ctx, input)
+		return err
+	}
+	return errors.Errorf("unsupported native copy scheme: %v", sourceURL)
+}
+
+func isSupported(URL string) bool {
+	scheme := url.Scheme(URL, "")
+	return scheme == gs.Scheme || scheme == as3.Scheme
+}
+
+func gsStorageObject(URL string, gsObject *gstorage.Object) storage.Object {
+	modified, _ := time.Parse(time.RFC3339, gsObject.Updated)
+	info := file.NewInfo(path.Base(gsObject.Name), int64(gsObject.Size), file.DefaultFileOsMode, modified, false, gsObject)
+	return object.New(URL, info, gsObject)
+}
+
+func s3StorageObject(URL string, s3Object *s3.Object) storage.Object {
+	info := file.NewInfo(path.Base(aws.StringValue(s3Object.Key)), aws.Int64Value(s3Object.Size), file.DefaultFileOsMode, aws.TimeValue(s3Object.LastModified), false, s3Object)
+	return object.New(URL, info, s3Object)
+}
@@ -0,0 +1,140 @@
+package native
+
+import (
+	"context"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"io"
+	"io/ioutil"
+)
+
+//service represents storage service routing gs and s3 requester pays reads, lists, copies and deletes through provider native calls,
+//afs storagers do not send requester pays billing, other calls are delegated to wrapped service
+type service struct {
+	afs.Service
+}
+
+//Object returns an object, requester pays object is read natively
+func (s *service) Object(ctx context.Context, URL string, options ...storage.Option) (storage.Object, error) {
+	if RequesterPays(URL, options) == nil {
+		return s.Service.Object(ctx, URL, options...)
+	}
+	return Object(ctx, URL, options)
+}
+
+//Exists returns true if resource exists, requester pays resource is checked natively
+func (s *service) Exists(ctx context.Context, URL string, options ...storage.Option) (bool, error) {
+	if RequesterPays(URL, options) == nil {
+		return s.Service.Exists(ctx, URL, options...)
+	}
+	if _, err := Object(ctx, URL, options); err != nil {
+		if base.ErrorCode(err) == base.ErrorCodeNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+//List lists resources, requester pays folder is listed natively
+func (s *service) List(ctx context.Context, URL string, options ...storage.Option) ([]storage.Object, error) {
+	if RequesterPays(URL, options) == nil {
+		return s.Service.List(ctx, URL, options...)
+	}
+	return List(ctx, URL, options)
+}
+
+//Open opens an object, requester pays object is opened natively
+func (s *service) Open(ctx context.Context, object storage.Object, options ...storage.Option) (io.ReadCloser, error) {
+	if RequesterPays(object.URL(), options) == nil {
+		return s.Service.Open(ctx, object, options...)
+	}
+	return Open(ctx, object.URL(), 0, options)
+}
+
+//OpenURL opens URL, requester pays object is opened natively
+func (s *service) OpenURL(ctx context.Context, URL string, options ...storage.Option) (io.ReadCloser, error) {
+	if RequesterPays(URL, options) == nil {
+		return s.Service.OpenURL(ctx, URL, options...)
+	}
+	return Open(ctx, URL, 0, options)
+}
+
+//Download downloads object, requester pays object is downloaded natively
+func (s *service) Download(ctx context.Context, object storage.Object, options ...storage.Option) ([]byte, error) {
+	if RequesterPays(object.URL(), options) == nil {
+		return s.Service.Download(ctx, object, options...)
+	}
+	return s.DownloadWithURL(ctx, object.URL(), options...)
+}
+
+//DownloadWithURL downloads URL, requester pays object is downloaded natively
+func (s *service) DownloadWithURL(ctx context.Context, URL string, options ...storage.Option) ([]byte, error) {
+	if RequesterPays(URL, options) == nil {
+		return s.Service.DownloadWithURL(ctx, URL, options...)
+	}
+	reader, err := Open(ctx, URL, 0, options)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(reader)
+}
+
+//Delete deletes resource, requester pays object is deleted natively
+func (s *service) Delete(ctx context.Context, URL string, options ...storage.Option) error {
+	if RequesterPays(URL, options) == nil {
+		return s.Service.Delete(ctx, URL, options...)
+	}
+	return Delete(ctx, URL, options)
+}
+
+//Copy copies resource, requester pays object is copied server side natively within provider, otherwise it is streamed from native reader
+func (s *service) Copy(ctx context.Context, sourceURL, destURL string, options ...storage.Option) error {
+	sourceOptions, destOptions := splitOptions(options)
+	sourcePays, destPays := RequesterPays(sourceURL, sourceOptions) != nil, RequesterPays(destURL, destOptions) != nil
+	if !sourcePays && !destPays {
+		return s.Service.Copy(ctx, sourceURL, destURL, options...)
+	}
+	if url.IsSchemeEquals(sourceURL, destURL) {
+		return Copy(ctx, sourceURL, destURL, sourceOptions, destOptions)
+	}
+	reader, err := s.OpenURL(ctx, sourceURL, sourceOptions...)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return s.Upload(ctx, destURL, file.DefaultFileOsMode, reader, destOptions...)
+}
+
+//Move moves resource, requester pays object is copied and deleted natively
+func (s *service) Move(ctx context.Context, sourceURL, destURL string, options ...storage.Option) error {
+	sourceOptions, destOptions := splitOptions(options)
+	if RequesterPays(sourceURL, sourceOptions) == nil && RequesterPays(destURL, destOptions) == nil {
+		return s.Service.Move(ctx, sourceURL, destURL, options...)
+	}
+	if err := s.Copy(ctx, sourceURL, destURL, options...); err != nil {
+		return err
+	}
+	return s.Delete(ctx, sourceURL, sourceOptions...)
+}
+
+//splitOptions returns copy/move source and dest options
+func splitOptions(options []storage.Option) ([]storage.Option, []storage.Option) {
+	sourceOptions := option.NewSource()
+	destOptions := option.NewDest()
+	option.Assign(options, &sourceOptions, &destOptions)
+	return *sourceOptions, *destOptions
+}
+
+//New returns storage service routing requester pays calls through provider native API
+func New(fs afs.Service) afs.Service {
+	if _, ok := fs.(*service); ok {
+		return fs
+	}
+	return &service{Service: fs}
+}
@@ -0,0 +1,233 @@
+package smirror
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/option/content"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/afsc/gs"
+	as3 "github.com/viant/afsc/s3"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/native"
+	gstorage "google.golang.org/api/storage/v1"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	//s3MaxPutSize max single PutObject size
+	s3MaxPutSize = 5 * 1024 * 1024 * 1024
+)
+
+//errUploadAborted aborts native upload of incomplete content
+var errUploadAborted = errors.New("upload aborted")
+
+//etagPrecondition represents s3 conditional write option, empty ETag requires dest object not to exist
+type etagPrecondition struct {
+	ETag string
+}
+
+//headers returns conditional write request headers
+func (p *etagPrecondition) headers() map[string]string {
+	if p.ETag == "" {
+		return map[string]string{"If-None-Match": "*"}
+	}
+	return map[string]string{"If-Match": `"` + strings.Trim(p.ETag, `"`) + `"`}
+}
+
+//nativeUploader uploads reader content to dest object
+type nativeUploader func(ctx context.Context, reader io.Reader) error
+
+//nativeWriter streams written content to native upload, Close waits for upload completion
+type nativeWriter struct {
+	*io.PipeWriter
+	cancel context.CancelFunc
+	done   chan error
+}
+
+//Close completes upload
+func (w *nativeWriter) Close() error {
+	defer w.cancel()
+	if err := w.PipeWriter.Close(); err != nil {
+		return err
+	}
+	return <-w.done
+}
+
+//abort stops upload unless it was completed with Close
+func (w *nativeWriter) abort() {
+	_ = w.PipeWriter.CloseWithError(errUploadAborted)
+	w.cancel()
+}
+
+//usesNativeUpload returns true if dest resource has storage options that are only applied with provider native upload
+func usesNativeUpload(resource *config.Resource) bool {
+	if scheme := url.Scheme(resource.URL, ""); scheme != gs.Scheme && scheme != as3.Scheme {
+		return false
+	}
+	return resource.Retention != nil || resource.RequesterPays || resource.KMSKeyARN != "" || resource.KMSKeyName != ""
+}
+
+//newNativeUploader creates dest uploader with provider native API
+func newNativeUploader(ctx context.Context, URL string, options []storage.Option) (nativeUploader, error) {
+	switch url.Scheme(URL, "") {
+	case gs.Scheme:
+		return gsUploader(ctx, URL, options)
+	case as3.Scheme:
+		return s3Uploader(ctx, URL, options)
+	}
+	return nil, errors.Errorf("unsupported native upload scheme: %v", URL)
+}
+
+//newNativeWriter creates dest writer uploading with provider native API
+func newNativeWriter(ctx context.Context, URL string, options []storage.Option) (*nativeWriter, error) {
+	upload, err := newNativeUploader(ctx, URL, options)
+	if err != nil {
+		return nil, err
+	}
+	reader, writer := io.Pipe()
+	ctx, cancel := context.WithCancel(ctx)
+	result := &nativeWriter{PipeWriter: writer, cancel: cancel, done: make(chan error, 1)}
+	go func() {
+		err := upload(ctx, reader)
+		_ = reader.CloseWithError(err)
+		result.done <- err
+	}()
+	return result, nil
+}
+
+//gsUploader returns GCS uploader applying object retention, requester pays and CMEK storage options
+func gsUploader(ctx context.Context, URL string, options []storage.Option) (nativeUploader, error) {
+	service, err := native.GSService(ctx, URL, options)
+	if err != nil {
+		return nil, err
+	}
+	bucket := url.Host(URL)
+	object := &gstorage.Object{Bucket: bucket, Name: strings.Trim(url.Path(URL), "/")}
+	meta := &content.Meta{}
+	key := &option.AES256Key{}
+	var retention *config.ObjectRetention
+	var requesterPays *config.RequesterPays
+	var kmsKeyName *config.KMSKeyName
+	var generation *option.Generation
+	option.Assign(options, &meta, &key, &retention, &requesterPays, &kmsKeyName, &generation)
+	object.Metadata = meta.Values
+	if kmsKeyName != nil {
+		object.KmsKeyName = kmsKeyName.Name
+	}
+	userProject, err := native.UserProject(requesterPays)
+	if err != nil {
+		return nil, err
+	}
+	if retention != nil {
+		//gs legal hold is object temporary hold, retention period is governed by bucket retention policy
+		object.TemporaryHold = retention.LegalHold
+		if !retention.RetainUntil.IsZero() {
+			if err = checkBucketRetention(ctx, service, bucket, userProject, retention.RetainUntil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return func(ctx context.Context, reader io.Reader) error {
+		call := service.Objects.Insert(bucket, object).Media(reader).Context(ctx)
+		if userProject != "" {
+			call.UserProject(userProject)
+		}
+		if generation != nil {
+			if generation.WhenMatch {
+				call.IfGenerationMatch(generation.Generation)
+			} else {
+				call.IfGenerationNotMatch(generation.Generation)
+			}
+		}
+		if len(key.Key) > 0 {
+			if err := gs.SetCustomKeyHeader(key, call.Header()); err != nil {
+				return err
+			}
+		}
+		_, err := call.Do()
+		return err
+	}, nil
+}
+
+//checkBucketRetention checks that bucket retention policy retains objects uploaded now at least till retainUntil
+func checkBucketRetention(ctx context.Context, service *gstorage.Service, bucket, userProject string, retainUntil time.Time) error {
+	call := service.Buckets.Get(bucket).Context(ctx)
+	if userProject != "" {
+		call.UserProject(userProject)
+	}
+	info, err := call.Do()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get bucket retention policy: %v", bucket)
+	}
+	required := time.Until(retainUntil)
+	if info.RetentionPolicy == nil || time.Duration(info.RetentionPolicy.RetentionPeriod)*time.Second < required {
+		return errors.Errorf("bucket %v retention policy does not retain objects till %v", bucket, retainUntil.Format(time.RFC3339))
+	}
+	return nil
+}
+
+//s3Uploader returns S3 uploader applying object lock, requester pays and SSE-KMS storage options
+func s3Uploader(ctx context.Context, URL string, options []storage.Option) (nativeUploader, error) {
+	client, err := native.S3Client(ctx, URL, options)
+	if err != nil {
+		return nil, err
+	}
+	input := &s3manager.UploadInput{
+		Bucket: aws.String(url.Host(URL)),
+		Key:    aws.String(strings.Trim(url.Path(URL), "/")),
+	}
+	meta := &content.Meta{}
+	key := &option.AES256Key{}
+	var retention *config.ObjectRetention
+	var requesterPays *config.RequesterPays
+	var kmsKey *config.KMSKey
+	var precondition *etagPrecondition
+	option.Assign(options, &meta, &key, &retention, &requesterPays, &kmsKey, &precondition)
+	if kmsKey != nil {
+		input.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAwsKms)
+		input.SSEKMSKeyId = aws.String(kmsKey.ARN)
+	}
+	if requesterPays != nil && requesterPays.Enabled {
+		input.RequestPayer = aws.String(s3.RequestPayerRequester)
+	}
+	if len(meta.Values) > 0 {
+		input.Metadata = aws.StringMap(meta.Values)
+	}
+	if len(key.Key) > 0 {
+		if err = key.Init(); err != nil {
+			return nil, err
+		}
+		input.SSECustomerAlgorithm = aws.String(s3.ServerSideEncryptionAes256)
+		input.SSECustomerKey = aws.String(string(key.Key))
+		input.SSECustomerKeyMD5 = aws.String(key.Base64KeyMd5Hash)
+	}
+	if retention != nil {
+		if !retention.RetainUntil.IsZero() {
+			input.ObjectLockMode = aws.String(retention.Mode)
+			input.ObjectLockRetainUntilDate = aws.Time(retention.RetainUntil)
+		}
+		if retention.LegalHold {
+			input.ObjectLockLegalHoldStatus = aws.String(s3.ObjectLockLegalHoldStatusOn)
+		}
+	}
+	uploader := s3manager.NewUploaderWithClient(client)
+	if precondition != nil {
+		//conditional write is only supported with single PutObject
+		uploader.PartSize = s3MaxPutSize
+		uploader.RequestOptions = append(uploader.RequestOptions, request.WithSetRequestHeaders(precondition.headers()))
+	}
+	return func(ctx context.Context, reader io.Reader) error {
+		upload := *input
+		upload.Body = reader
+		_, err := uploader.UploadWithContext(ctx, &upload)
+		return err
+	}, nil
+}
@@ -0,0 +1,247 @@
+package smirror
+
+import (
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afsc/gs"
+	"github.com/viant/smirror/config"
+	goption "google.golang.org/api/option"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//requestRecorder records requests sent to fake storage endpoint
+type requestRecorder struct {
+	mux             sync.Mutex
+	header          http.Header
+	query           url.Values
+	body            string
+	retentionPeriod int
+}
+
+func (r *requestRecorder) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	body, _ := ioutil.ReadAll(request.Body)
+	r.mux.Lock()
+	r.header = request.Header.Clone()
+	r.query = request.URL.Query()
+	r.mux.Unlock()
+	switch {
+	case request.Method == http.MethodGet && strings.HasSuffix(request.URL.Path, "/b/bucket"):
+		writer.Header().Set("Content-Type", "application/json")
+		if r.retentionPeriod > 0 {
+			_, _ = fmt.Fprintf(writer, `{"name":"bucket","retentionPolicy":{"retentionPeriod":"%d"}}`, r.retentionPeriod)
+			return
+		}
+		_, _ = io.WriteString(writer, `{"name":"bucket"}`)
+	case request.Method == http.MethodGet && strings.Contains(request.URL.Path, "/b/bucket/o/"):
+		if request.URL.Query().Get("alt") == "media" {
+			_, _ = io.WriteString(writer, "id,name")
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(writer, `{"name":"data/file.csv","bucket":"bucket","size":"7","md5Hash":"md5","updated":"2024-01-15T10:00:00Z"}`)
+	case (request.Method == http.MethodGet || request.Method == http.MethodHead) && request.URL.Path == "/bucket/data/file.csv":
+		writer.Header().Set("ETag", `"etag"`)
+		writer.Header().Set("Content-Length", "7")
+		writer.Header().Set("Last-Modified", "Mon, 15 Jan 2024 10:00:00 GMT")
+		if request.Method == http.MethodGet {
+			_, _ = io.WriteString(writer, "id,name")
+		}
+	case request.Method == http.MethodPost || request.Method == http.MethodPut:
+		r.mux.Lock()
+		r.body = string(body)
+		r.mux.Unlock()
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Header().Set("ETag", `"etag"`)
+		_, _ = io.WriteString(writer, `{"name":"data/file.csv","bucket":"bucket"}`)
+	default:
+		writer.WriteHeader(http.StatusNotFound)
+	}
+}
+
+//fakeStorageOptions returns gs and s3 storage options pointing to fake storage endpoint
+func fakeStorageOptions(server *httptest.Server, options ...storage.Option) []storage.Option {
+	return append(options,
+		gs.NewClientOptions(goption.WithEndpoint(server.URL+"/storage/v1/"), goption.WithoutAuthentication()),
+		&aws.Config{
+			Endpoint:         aws.String(server.URL),
+			Region:           aws.String("us-east-1"),
+			S3ForcePathStyle: aws.Bool(true),
+			Credentials:      credentials.NewStaticCredentials("key", "secret", ""),
+		},
+	)
+}
+
+func TestNewNativeWriter(t *testing.T) {
+	now := time.Now()
+	var useCases = []struct {
+		description     string
+		URL             string
+		retention       *config.Retention
+		requesterPays   *config.RequesterPays
+		option          storage.Option
+		retentionPeriod int
+		expectError     bool
+		expectBody      []string
+		expectHeader    map[string]string
+		expectQuery     map[string]string
+	}{
+		{
+			description: "gs legal hold",
+			URL:         "gs://bucket/data/file.csv",
+			retention:   &config.Retention{LegalHold: true},
+			expectBody:  []string{`"temporaryHold":true`, "id,name"},
+		},
+		{
+			description:     "gs retention covered by bucket policy",
+			URL:             "gs://bucket/data/file.csv",
+			retention:       &config.Retention{Days: 30},
+			retentionPeriod: 31 * 24 * 3600,
+			expectBody:      []string{"id,name"},
+		},
+		{
+			description:     "gs retention exceeding bucket policy",
+			URL:             "gs://bucket/data/file.csv",
+			retention:       &config.Retention{Days: 30},
+			retentionPeriod: 24 * 3600,
+			expectError:     true,
+		},
+		{
+			description: "s3 object lock",
+			URL:         "s3://bucket/data/file.csv",
+			retention:   &config.Retention{Mode: "governance", Days: 30, LegalHold: true},
+			expectBody:  []string{"id,name"},
+			expectHeader: map[string]string{
+				"X-Amz-Object-Lock-Mode":              config.RetentionGovernance,
+				"X-Amz-Object-Lock-Retain-Until-Date": now.UTC().AddDate(0, 0, 30).Format("2006-01-02"),
+				"X-Amz-Object-Lock-Legal-Hold":        "ON",
+			},
+		},
+		{
+			description:   "gs requester pays",
+			URL:           "gs://bucket/data/file.csv",
+			requesterPays: config.NewRequesterPays(true, "billing-project"),
+			expectBody:    []string{"id,name"},
+			expectQuery:   map[string]string{"userProject": "billing-project"},
+		},
+		{
+			description:   "gs requester pays without project",
+			URL:           "gs://bucket/data/file.csv",
+			requesterPays: config.NewRequesterPays(true, ""),
+			expectError:   true,
+		},
+		{
+			description:   "s3 requester pays",
+			URL:           "s3://bucket/data/file.csv",
+			requesterPays: config.NewRequesterPays(true, ""),
+			expectBody:    []string{"id,name"},
+			expectHeader:  map[string]string{"X-Amz-Request-Payer": "requester"},
+		},
+		{
+			description: "gs CMEK",
+			URL:         "gs://bucket/data/file.csv",
+			option:      config.NewKMSKeyName("projects/p/locations/us/keyRings/r/cryptoKeys/k"),
+			expectBody:  []string{`"kmsKeyName":"projects/p/locations/us/keyRings/r/cryptoKeys/k"`, "id,name"},
+		},
+		{
+			description: "s3 SSE-KMS",
+			URL:         "s3://bucket/data/file.csv",
+			option:      config.NewKMSKey("alias/mirror"),
+			expectBody:  []string{"id,name"},
+			expectHeader: map[string]string{
+				"X-Amz-Server-Side-Encryption":                "aws:kms",
+				"X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id": "alias/mirror",
+			},
+		},
+		{
+			description: "gs generation precondition",
+			URL:         "gs://bucket/data/file.csv",
+			option:      option.NewGeneration(true, 12),
+			expectBody:  []string{"id,name"},
+			expectQuery: map[string]string{"ifGenerationMatch": "12"},
+		},
+		{
+			description:  "s3 ETag precondition",
+			URL:          "s3://bucket/data/file.csv",
+			option:       &etagPrecondition{ETag: "abc"},
+			expectBody:   []string{"id,name"},
+			expectHeader: map[string]string{"If-Match": `"abc"`},
+		},
+		{
+			description:  "s3 create precondition",
+			URL:          "s3://bucket/data/file.csv",
+			option:       &etagPrecondition{},
+			expectBody:   []string{"id,name"},
+			expectHeader: map[string]string{"If-None-Match": "*"},
+		},
+	}
+
+	for _, useCase := range useCases {
+		recorder := &requestRecorder{retentionPeriod: useCase.retentionPeriod}
+		server := httptest.NewServer(recorder)
+		var options []storage.Option
+		if useCase.retention != nil {
+			useCase.retention.Init()
+			options = append(options, useCase.retention.Option(now))
+		}
+		if useCase.requesterPays != nil {
+			options = append(options, useCase.requesterPays)
+		}
+		if useCase.option != nil {
+			options = append(options, useCase.option)
+		}
+		options = fakeStorageOptions(server, options...)
+		writer, err := newNativeWriter(context.Background(), useCase.URL, options)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			server.Close()
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			server.Close()
+			continue
+		}
+		_, err = io.WriteString(writer, "id,name\n1,abc\n")
+		assert.Nil(t, err, useCase.description)
+		assert.Nil(t, writer.Close(), useCase.description)
+		server.Close()
+
+		for _, expect := range useCase.expectBody {
+			assert.Contains(t, recorder.body, expect, useCase.description)
+		}
+		for key, expect := range useCase.expectHeader {
+			assert.Contains(t, recorder.header.Get(key), expect, useCase.description+" "+key)
+		}
+		for key, expect := range useCase.expectQuery {
+			assert.EqualValues(t, expect, recorder.query.Get(key), useCase.description+" "+key)
+		}
+	}
+}
+
+func TestNativeWriter_Abort(t *testing.T) {
+	recorder := &requestRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+	options := fakeStorageOptions(server, (&config.Retention{LegalHold: true}).Option(time.Now()))
+	writer, err := newNativeWriter(context.Background(), "gs://bucket/data/file.csv", options)
+	if !assert.Nil(t, err) {
+		return
+	}
+	_, err = io.WriteString(writer, "id,name\n")
+	assert.Nil(t, err)
+	writer.abort()
+	assert.NotNil(t, <-writer.done)
+	assert.Empty(t, recorder.body)
+}
@@ -0,0 +1,86 @@
+package smirror
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/nats"
+	"io/ioutil"
+	"sync"
+)
+
+//natsClients clients keyed by server URL and credentials, to reuse connections
+var natsClients = sync.Map{}
+
+func natsClient(resource *config.Resource) (*nats.Client, error) {
+	key := resource.URL
+	var credentials *nats.Credentials
+	if resource.Credentials != nil {
+		if len(resource.Credentials.Auth) == 0 {
+			return nil, errors.New("nats credentials were not decrypted")
+		}
+		key += "/" + string(resource.Credentials.Auth)
+		var err error
+		if credentials, err = nats.NewCredentials(resource.Credentials.Auth); err != nil {
+			return nil, err
+		}
+	}
+	if client, ok := natsClients.Load(key); ok {
+		return client.(*nats.Client), nil
+	}
+	client, err := nats.New(resource.URL, credentials)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := natsClients.LoadOrStore(key, client)
+	return actual.(*nats.Client), nil
+}
+
+//publishNATS publishes transfer data to JetStream, message ID is derived from transfer ID for JetStream deduplication
+func (s *service) publishNATS(ctx context.Context, transfer *Transfer, response *contract.Response) error {
+	client, err := natsClient(transfer.Resource)
+	if err != nil {
+		return base.NewCodedError(base.ErrorCodeAuth, err)
+	}
+	reader, err := transfer.GetReader()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get reader for: %v", transfer.Resource.URL)
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	if data, err = s.claimCheck(ctx, transfer, data, response); err != nil {
+		return err
+	}
+	settings := transfer.Resource.NATS
+	message := &nats.Message{
+		Subject: settings.ExpandSubject(response.TriggeredBy, transfer.partition),
+		ID:      fmt.Sprintf("%v-%v", response.TransferID, transfer.splitCounter),
+		Headers: map[string]string{base.SourceAttribute: response.TriggeredBy, base.TransferIDKey: response.TransferID},
+		Data:    data,
+	}
+	if response.CorrelationID != "" {
+		message.Headers[base.CorrelationIDKey] = response.CorrelationID
+	}
+	if settings.Stream != "" {
+		message.Headers[nats.ExpectedStreamHeader] = settings.Stream
+	}
+	waited, err := s.limiter.Wait(ctx, transfer.Resource.URL)
+	response.AddThrottleTime(waited)
+	if err != nil {
+		return err
+	}
+	acks, err := client.Publish(ctx, []*nats.Message{message})
+	if err != nil {
+		s.limiter.Report(transfer.Resource.URL, err)
+		return errors.Wrapf(err, "failed to publish to %v", message.Subject)
+	}
+	for _, ack := range acks {
+		response.AddMessageIDs(fmt.Sprintf("%v:%v", ack.Stream, ack.Sequence))
+	}
+	return nil
+}
@@ -0,0 +1,330 @@
+package nats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	//Scheme nats scheme
+	Scheme = "nats"
+	//TLSScheme nats over TLS scheme
+	TLSScheme = "tls"
+	//MsgIDHeader JetStream deduplication header
+	MsgIDHeader = "Nats-Msg-Id"
+	//ExpectedStreamHeader JetStream expected stream header
+	ExpectedStreamHeader = "Nats-Expected-Stream"
+
+	defaultPort    = "4222"
+	defaultTimeout = 30 * time.Second
+	headerVersion  = "NATS/1.0"
+)
+
+//Message represents a message
+type Message struct {
+	Subject string
+	//ID JetStream deduplication ID
+	ID      string
+	Headers map[string]string
+	Data    []byte
+}
+
+//Ack represents JetStream publish acknowledgement
+type Ack struct {
+	Stream    string `json:"stream"`
+	Sequence  uint64 `json:"seq"`
+	Duplicate bool   `json:"duplicate,omitempty"`
+	Error     *struct {
+		Code        int    `json:"code"`
+		Description string `json:"description"`
+	} `json:"error,omitempty"`
+}
+
+type serverInfo struct {
+	TLSRequired bool   `json:"tls_required"`
+	MaxPayload  int    `json:"max_payload"`
+	Nonce       string `json:"nonce"`
+	Headers     bool   `json:"headers"`
+}
+
+//Client represents minimal NATS JetStream publisher, publishes are pipelined over a single connection
+type Client struct {
+	address     string
+	useTLS      bool
+	credentials *Credentials
+	mux         sync.Mutex
+	conn        net.Conn
+	reader      *bufio.Reader
+	info        *serverInfo
+	inbox       string
+	sequence    uint64
+}
+
+//Publish publishes messages to JetStream, it returns acknowledgements of messages published before the first error
+func (c *Client) Publish(ctx context.Context, messages []*Message) ([]*Ack, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if err := c.connect(ctx); err != nil {
+		return nil, err
+	}
+	acks, err := c.publish(ctx, messages)
+	if err != nil {
+		c.close()
+	}
+	return acks, err
+}
+
+func (c *Client) publish(ctx context.Context, messages []*Message) ([]*Ack, error) {
+	c.setDeadline(ctx)
+	buffer := new(bytes.Buffer)
+	pending := make(map[string]int)
+	for i, message := range messages {
+		header := encodeHeader(message)
+		if c.info.MaxPayload > 0 && len(header)+len(message.Data) > c.info.MaxPayload {
+			return nil, errors.Errorf("message %v size %v exceeds server max payload %v", message.ID, len(header)+len(message.Data), c.info.MaxPayload)
+		}
+		c.sequence++
+		reply := c.inbox + "." + strconv.FormatUint(c.sequence, 10)
+		pending[reply] = i
+		fmt.Fprintf(buffer, "HPUB %s %s %d %d\r\n", message.Subject, reply, len(header), len(header)+len(message.Data))
+		buffer.WriteString(header)
+		buffer.Write(message.Data)
+		buffer.WriteString("\r\n")
+	}
+	if _, err := c.conn.Write(buffer.Bytes()); err != nil {
+		return nil, err
+	}
+	acks := make([]*Ack, len(messages))
+	for len(pending) > 0 {
+		reply, status, payload, err := c.next()
+		if err != nil {
+			return collect(acks), err
+		}
+		index, ok := pending[reply]
+		if !ok {
+			continue
+		}
+		delete(pending, reply)
+		if status == "503" {
+			return collect(acks), errors.Errorf("no JetStream stream for subject: %v", messages[index].Subject)
+		}
+		ack := &Ack{}
+		if err = json.Unmarshal(payload, ack); err != nil {
+			return collect(acks), errors.Wrapf(err, "invalid publish ack: %s", payload)
+		}
+		if ack.Error != nil {
+			return collect(acks), errors.Errorf("failed to publish to %v: %v (%v)", messages[index].Subject, ack.Error.Description, ack.Error.Code)
+		}
+		acks[index] = ack
+	}
+	return acks, nil
+}
+
+//collect returns leading acknowledged messages
+func collect(acks []*Ack) []*Ack {
+	for i, ack := range acks {
+		if ack == nil {
+			return acks[:i]
+		}
+	}
+	return acks
+}
+
+func encodeHeader(message *Message) string {
+	header := headerVersion + "\r\n"
+	if message.ID != "" {
+		header += MsgIDHeader + ": " + message.ID + "\r\n"
+	}
+	for key, value := range message.Headers {
+		header += key + ": " + value + "\r\n"
+	}
+	return header + "\r\n"
+}
+
+//next reads next protocol message, it returns reply subject, header status and payload for MSG and HMSG
+func (c *Client) next() (string, string, []byte, error) {
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return "", "", nil, err
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "PING":
+			if _, err = c.conn.Write([]byte("PONG\r\n")); err != nil {
+				return "", "", nil, err
+			}
+		case "-ERR":
+			return "", "", nil, errors.Errorf("nats error: %v", strings.TrimSpace(line[4:]))
+		case "MSG":
+			//MSG <subject> <sid> [reply-to] <#bytes>
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload, err := c.readPayload(size)
+			return fields[1], "", payload, err
+		case "HMSG":
+			//HMSG <subject> <sid> [reply-to] <#header bytes> <#total bytes>
+			headerSize, _ := strconv.Atoi(fields[len(fields)-2])
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload, err := c.readPayload(size)
+			if err != nil || headerSize > len(payload) {
+				return "", "", nil, errors.Errorf("invalid HMSG: %v", line)
+			}
+			status := ""
+			if statusLine := strings.SplitN(string(payload[:headerSize]), "\r\n", 2)[0]; len(statusLine) > len(headerVersion) {
+				status = strings.Fields(statusLine[len(headerVersion):])[0]
+			}
+			return fields[1], status, payload[headerSize:], nil
+		}
+	}
+}
+
+func (c *Client) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (c *Client) readPayload(size int) ([]byte, error) {
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(c.reader, data); err != nil {
+		return nil, err
+	}
+	return data[:size], nil
+}
+
+func (c *Client) setDeadline(ctx context.Context) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+	_ = c.conn.SetDeadline(deadline)
+}
+
+func (c *Client) connect(ctx context.Context) (err error) {
+	if c.conn != nil {
+		return nil
+	}
+	dialer := &net.Dialer{Timeout: defaultTimeout}
+	if c.conn, err = dialer.DialContext(ctx, "tcp", c.address); err != nil {
+		return errors.Wrapf(err, "failed to connect to %v", c.address)
+	}
+	c.reader = bufio.NewReader(c.conn)
+	if err = c.handshake(ctx); err != nil {
+		c.close()
+		return errors.Wrapf(err, "failed to connect to %v", c.address)
+	}
+	return nil
+}
+
+func (c *Client) handshake(ctx context.Context) error {
+	c.setDeadline(ctx)
+	line, err := c.readLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return errors.Errorf("expected INFO, but had: %v", line)
+	}
+	c.info = &serverInfo{}
+	if err = json.Unmarshal([]byte(line[5:]), c.info); err != nil {
+		return errors.Wrap(err, "invalid server INFO")
+	}
+	if !c.info.Headers {
+		return errors.New("server does not support headers")
+	}
+	if c.useTLS || c.info.TLSRequired {
+		host, _, _ := net.SplitHostPort(c.address)
+		conn := tls.Client(c.conn, &tls.Config{ServerName: host})
+		if err = conn.Handshake(); err != nil {
+			return err
+		}
+		c.conn = conn
+		c.reader = bufio.NewReader(conn)
+		c.setDeadline(ctx)
+	}
+	options := map[string]interface{}{"verbose": false, "pedantic": false, "headers": true, "no_responders": true, "protocol": 1, "lang": "go", "name": "smirror"}
+	if credentials := c.credentials; credentials != nil {
+		switch {
+		case credentials.JWT != "":
+			signature, err := credentials.Sign(c.info.Nonce)
+			if err != nil {
+				return err
+			}
+			options["jwt"], options["sig"] = credentials.JWT, signature
+		case credentials.Token != "":
+			options["auth_token"] = credentials.Token
+		case credentials.User != "":
+			options["user"], options["pass"] = credentials.User, credentials.Password
+		}
+	}
+	connect, _ := json.Marshal(options)
+	random := make([]byte, 8)
+	_, _ = rand.Read(random)
+	c.inbox = "_INBOX." + hex.EncodeToString(random)
+	if _, err = fmt.Fprintf(c.conn, "CONNECT %s\r\nSUB %s.* 1\r\nPING\r\n", connect, c.inbox); err != nil {
+		return err
+	}
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case strings.HasPrefix(line, "PONG"):
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.Errorf("nats error: %v", strings.TrimSpace(line[4:]))
+		}
+	}
+}
+
+func (c *Client) close() {
+	if c.conn != nil {
+		_ = c.conn.Close()
+	}
+	c.conn = nil
+	c.reader = nil
+}
+
+//Close closes connection
+func (c *Client) Close() error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.close()
+	return nil
+}
+
+//New creates a client for nats://host[:port] or tls://host[:port] server URL
+func New(URL string, credentials *Credentials) (*Client, error) {
+	parsed, err := url.Parse(URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid nats URL: %v", URL)
+	}
+	if parsed.Scheme != Scheme && parsed.Scheme != TLSScheme {
+		return nil, errors.Errorf("unsupported nats URL scheme: %v", parsed.Scheme)
+	}
+	client := &Client{address: parsed.Host, useTLS: parsed.Scheme == TLSScheme, credentials: credentials}
+	if parsed.Port() == "" {
+		client.address = net.JoinHostPort(parsed.Hostname(), defaultPort)
+	}
+	return client, nil
+}
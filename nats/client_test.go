@@ -0,0 +1,116 @@
+package nats
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func encodeSeed(seed []byte) string {
+	raw := []byte{seedPrefixByte | userPrefixByte>>5, (userPrefixByte & 31) << 3}
+	raw = append(raw, seed...)
+	checksum := make([]byte, 2)
+	binary.LittleEndian.PutUint16(checksum, crc16(raw))
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(append(raw, checksum...))
+}
+
+//serve emulates JetStream server with single stream bound to orders.> subjects
+func serve(t *testing.T, listener net.Listener, publicKey ed25519.PublicKey, published map[string]string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	_, _ = conn.Write([]byte(`INFO {"headers":true,"max_payload":1024,"nonce":"abc"}` + "\r\n"))
+	seen := map[string]bool{}
+	sequence := 0
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "CONNECT":
+			options := map[string]interface{}{}
+			_ = json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "CONNECT ")), &options)
+			signature, _ := base64.RawURLEncoding.DecodeString(options["sig"].(string))
+			assert.True(t, ed25519.Verify(publicKey, []byte("abc"), signature))
+			assert.Equal(t, "user.jwt", options["jwt"])
+		case "PING":
+			_, _ = conn.Write([]byte("PONG\r\n"))
+		case "HPUB":
+			headerSize, _ := strconv.Atoi(fields[3])
+			size, _ := strconv.Atoi(fields[4])
+			payload := make([]byte, size+2)
+			_, _ = io.ReadFull(reader, payload)
+			if !strings.HasPrefix(fields[1], "orders.") {
+				status := "NATS/1.0 503\r\n\r\n"
+				_, _ = fmt.Fprintf(conn, "HMSG %s 1 %d %d\r\n%s\r\n", fields[2], len(status), len(status), status)
+				continue
+			}
+			header := string(payload[:headerSize])
+			ID := strings.TrimSpace(strings.SplitN(header[strings.Index(header, MsgIDHeader+":"):], "\r\n", 2)[0][len(MsgIDHeader)+1:])
+			ack := fmt.Sprintf(`{"stream":"ORDERS","seq":%d,"duplicate":true}`, sequence)
+			if !seen[ID] {
+				seen[ID] = true
+				sequence++
+				published[fields[1]] = string(payload[headerSize:size])
+				ack = fmt.Sprintf(`{"stream":"ORDERS","seq":%d}`, sequence)
+			}
+			_, _ = fmt.Fprintf(conn, "MSG %s 1 %d\r\n%s\r\n", fields[2], len(ack), ack)
+		}
+	}
+}
+
+func TestClient_Publish(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+	seed := make([]byte, ed25519.SeedSize)
+	copy(seed, "smirror-test-seed")
+	published := map[string]string{}
+	go serve(t, listener, ed25519.NewKeyFromSeed(seed).Public().(ed25519.PublicKey), published)
+
+	creds := fmt.Sprintf("-----BEGIN NATS USER JWT-----\nuser.jwt\n------END NATS USER JWT------\n\n-----BEGIN USER NKEY SEED-----\n%s\n------END USER NKEY SEED------\n", encodeSeed(seed))
+	credentials, err := NewCredentials([]byte(creds))
+	if !assert.Nil(t, err) {
+		return
+	}
+	client, err := New("nats://"+listener.Addr().String(), credentials)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer client.Close()
+	ctx := context.Background()
+	acks, err := client.Publish(ctx, []*Message{
+		{Subject: "orders.a", ID: "t1-0", Data: []byte("a")},
+		{Subject: "orders.b", ID: "t1-1", Data: []byte("b")},
+		{Subject: "orders.a", ID: "t1-0", Data: []byte("a")},
+	})
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, uint64(2), acks[1].Sequence)
+	assert.True(t, acks[2].Duplicate)
+	assert.Equal(t, map[string]string{"orders.a": "a", "orders.b": "b"}, published)
+
+	_, err = client.Publish(ctx, []*Message{{Subject: "orders.c", Data: make([]byte, 2048)}})
+	assert.NotNil(t, err, "max payload should be enforced")
+	_, err = NewCredentials([]byte("-----BEGIN NATS USER JWT-----\nuser.jwt\n------END NATS USER JWT------\n"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,100 @@
+package nats
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"regexp"
+	"strings"
+)
+
+const (
+	seedPrefixByte = 18 << 3 //S
+	userPrefixByte = 20 << 3 //U
+)
+
+var credsBlock = regexp.MustCompile(`-{3,}[^\n]*-{3,}\r?\n([\w\-.=]+)\r?\n-{3,}[^\n]*-{3,}`)
+
+//Credentials represents decrypted NATS credentials, one of token, user and password or user JWT and NKey seed
+type Credentials struct {
+	Token    string `json:",omitempty"`
+	User     string `json:",omitempty"`
+	Password string `json:",omitempty"`
+	//JWT user JWT
+	JWT string `json:",omitempty"`
+	//Seed user NKey seed, used to sign server nonce
+	Seed string `json:",omitempty"`
+}
+
+//Sign signs server nonce with NKey seed
+func (c *Credentials) Sign(nonce string) (string, error) {
+	key, err := decodeSeed(c.Seed)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(ed25519.Sign(key, []byte(nonce))), nil
+}
+
+func decodeSeed(seed string) (ed25519.PrivateKey, error) {
+	raw, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimSpace(seed))
+	if err != nil || len(raw) != 2+ed25519.SeedSize+2 {
+		return nil, errors.New("invalid nkey seed")
+	}
+	checksum := binary.LittleEndian.Uint16(raw[len(raw)-2:])
+	if crc16(raw[:len(raw)-2]) != checksum {
+		return nil, errors.New("invalid nkey seed checksum")
+	}
+	if raw[0]&248 != seedPrefixByte || ((raw[0]&7)<<5|(raw[1]&248)>>3) != userPrefixByte {
+		return nil, errors.New("nkey seed is not a user seed")
+	}
+	return ed25519.NewKeyFromSeed(raw[2 : 2+ed25519.SeedSize]), nil
+}
+
+//crc16 CRC-16/XMODEM checksum used by NKeys
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+//NewCredentials creates credentials from decrypted secret, .creds file content, JSON or raw token
+func NewCredentials(secret []byte) (*Credentials, error) {
+	secret = bytes.TrimSpace(secret)
+	if len(secret) == 0 {
+		return nil, errors.New("nats credentials were empty")
+	}
+	credentials := &Credentials{}
+	switch {
+	case bytes.Contains(secret, []byte("BEGIN NATS USER JWT")):
+		blocks := credsBlock.FindAllSubmatch(secret, -1)
+		if len(blocks) < 2 {
+			return nil, errors.New("invalid nats creds, expected user JWT and NKey seed")
+		}
+		credentials.JWT, credentials.Seed = string(blocks[0][1]), string(blocks[1][1])
+	case secret[0] == '{':
+		if err := json.Unmarshal(secret, credentials); err != nil {
+			return nil, errors.Wrap(err, "failed to decode nats credentials")
+		}
+	default:
+		credentials.Token = string(secret)
+	}
+	if credentials.JWT != "" {
+		if _, err := decodeSeed(credentials.Seed); err != nil {
+			return nil, err
+		}
+	}
+	return credentials, nil
+}
@@ -0,0 +1,87 @@
+package smirror
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/mon"
+	"github.com/viant/smirror/openapi"
+	"github.com/viant/smirror/replay"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sync"
+)
+
+const (
+	apiVersion = "1.0.0"
+
+	pathSubmit      = "/StorageMirrorSubmit"
+	pathBatch       = "/StorageMirrorBatch"
+	pathReplay      = "/StorageReplay"
+	pathRuleStatus  = "/StorageMirrorRuleStatus"
+	pathRuleHistory = "/StorageMirrorRuleHistory"
+	pathMonitor     = "/StorageMonitor"
+)
+
+var apiDocument struct {
+	once     sync.Once
+	document *openapi.Document
+}
+
+//apiSpec returns OpenAPI document of mirror, batch, replay, rules, rule history and status endpoints, it is generated once from contract types
+func apiSpec() *openapi.Document {
+	apiDocument.once.Do(func() {
+		document := openapi.New("smirror", "Serverless cloud storage mirror control plane API", apiVersion)
+		tenant := []*openapi.Parameter{{Name: "tenant", In: "query", Description: "tenant name for multi tenant config", Schema: &openapi.Schema{Type: "string"}}}
+		document.Add(&openapi.Endpoint{Path: pathSubmit, Method: http.MethodPost, OperationID: "mirror", Summary: "Mirrors source object", Request: contract.Request{}, Required: []string{"URL"}, Response: contract.Response{}})
+		document.Add(&openapi.Endpoint{Path: pathBatch, Method: http.MethodPost, OperationID: "mirrorBatch", Summary: "Mirrors source objects concurrently", Request: contract.BatchRequest{}, Required: []string{"URLs"}, Response: contract.BatchResponse{}})
+		document.Add(&openapi.Endpoint{Path: pathReplay, Method: http.MethodPost, OperationID: "replay", Summary: "Replays unprocessed or failed source objects", Request: replay.Request{}, Response: replay.Response{}})
+		document.Add(&openapi.Endpoint{Path: pathRuleStatus, Method: http.MethodPost, OperationID: "ruleStatus", Summary: "Returns rule files load status", Query: tenant, Request: contract.RuleStatusRequest{}, Response: contract.RuleStatusResponse{}})
+		document.Add(&openapi.Endpoint{Path: pathRuleHistory, Method: http.MethodPost, OperationID: "ruleHistory", Summary: "Evaluates source URL against rules active at a given time and now", Request: contract.RuleHistoryRequest{}, Required: []string{"URL", "At"}, Response: contract.RuleHistoryResponse{}})
+		document.Add(&openapi.Endpoint{Path: pathMonitor, Method: http.MethodPost, OperationID: "status", Summary: "Checks unprocessed files, errors and arrival anomalies", Request: mon.Request{}, Response: mon.Response{}})
+		apiDocument.document = document
+	})
+	return apiDocument.document
+}
+
+//StorageMirrorOpenAPI cloud function entry point, returns OpenAPI 3 document
+func StorageMirrorOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, auth.EndpointOpenAPI) {
+		return
+	}
+	w.Header().Set("Content-Type", openapi.ContentTypeJSON)
+	if err := json.NewEncoder(w).Encode(apiSpec()); err != nil {
+		log.Print(err)
+	}
+}
+
+//validRequest validates JSON request body against path request schema, it writes bad request response if request is invalid,
+//request body is restored for handler
+func validRequest(writer http.ResponseWriter, request *http.Request, path string) bool {
+	document := apiSpec()
+	schema := document.RequestSchema(path, request.Method)
+	if schema == nil || request.Body == nil {
+		return true
+	}
+	data, err := ioutil.ReadAll(request.Body)
+	_ = request.Body.Close()
+	if err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	request.Body = ioutil.NopCloser(bytes.NewReader(data))
+	var value interface{}
+	if len(bytes.TrimSpace(data)) == 0 {
+		value = map[string]interface{}{}
+	} else if err = json.Unmarshal(data, &value); err != nil {
+		http.Error(writer, "invalid JSON request: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	if err = document.Validate(schema, value); err != nil {
+		http.Error(writer, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
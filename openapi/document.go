@@ -0,0 +1,188 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+)
+
+const (
+	//Version OpenAPI specification version
+	Version = "3.0.3"
+	//ContentTypeJSON JSON media type
+	ContentTypeJSON = "application/json"
+
+	securityAPIKey = "apiKey"
+	securityBearer = "bearer"
+)
+
+//Document represents OpenAPI 3 document
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       *Info                 `json:"info"`
+	Paths      map[string]*PathItem  `json:"paths"`
+	Components *Components           `json:"components,omitempty"`
+	Security   []map[string][]string `json:"security,omitempty"`
+}
+
+//Info represents API info
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+//PathItem represents path operations
+type PathItem struct {
+	Get  *Operation `json:"get,omitempty"`
+	Post *Operation `json:"post,omitempty"`
+}
+
+//Operation represents API operation
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+//Parameter represents query parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+//RequestBody represents operation request body
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+//Response represents operation response
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+//MediaType represents media type schema
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+//Components represents reusable schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+//SecurityScheme represents security scheme
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Name   string `json:"name,omitempty"`
+	In     string `json:"in,omitempty"`
+	Scheme string `json:"scheme,omitempty"`
+}
+
+//Endpoint represents HTTP endpoint with typed request and response
+type Endpoint struct {
+	//Path endpoint path, i.e. entry point function name
+	Path        string
+	Method      string
+	OperationID string
+	Summary     string
+	//Request request body type, nil for endpoints without body
+	Request interface{}
+	//Required request body required fields, body is optional without required fields
+	Required []string
+	//Query query parameters
+	Query    []*Parameter
+	Response interface{}
+}
+
+//Operation returns method operation
+func (p *PathItem) Operation(method string) *Operation {
+	if method == http.MethodGet {
+		return p.Get
+	}
+	return p.Post
+}
+
+//RequestSchema returns path method request body schema, it is nil if operation has no body
+func (d *Document) RequestSchema(path, method string) *Schema {
+	item, ok := d.Paths[path]
+	if !ok {
+		return nil
+	}
+	operation := item.Operation(method)
+	if operation == nil || operation.RequestBody == nil {
+		return nil
+	}
+	return operation.RequestBody.Content[ContentTypeJSON].Schema
+}
+
+//Add adds endpoint operation, request and response schemas are generated from endpoint types
+func (d *Document) Add(endpoint *Endpoint) {
+	generator := &generator{schemas: d.Components.Schemas}
+	operation := &Operation{
+		OperationID: endpoint.OperationID,
+		Summary:     endpoint.Summary,
+		Parameters:  endpoint.Query,
+		Responses: map[string]*Response{
+			"400": {Description: "invalid request"},
+			"401": {Description: "unauthorized"},
+			"500": {Description: "internal error"},
+		},
+	}
+	if endpoint.Request != nil {
+		schema := generator.schema(reflect.TypeOf(endpoint.Request))
+		if len(endpoint.Required) > 0 {
+			d.Resolve(schema).Required = endpoint.Required
+		}
+		operation.RequestBody = &RequestBody{Required: len(endpoint.Required) > 0, Content: map[string]*MediaType{ContentTypeJSON: {Schema: schema}}}
+	}
+	operation.Responses["200"] = &Response{Description: "OK"}
+	if endpoint.Response != nil {
+		operation.Responses["200"].Content = map[string]*MediaType{ContentTypeJSON: {Schema: generator.schema(reflect.TypeOf(endpoint.Response))}}
+	}
+	item, ok := d.Paths[endpoint.Path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[endpoint.Path] = item
+	}
+	if endpoint.Method == http.MethodGet {
+		item.Get = operation
+	} else {
+		item.Post = operation
+	}
+}
+
+//Resolve returns referenced component schema or schema itself
+func (d *Document) Resolve(schema *Schema) *Schema {
+	if schema == nil || schema.Ref == "" {
+		return schema
+	}
+	if resolved, ok := d.Components.Schemas[schema.Ref[len(componentPrefix):]]; ok {
+		return resolved
+	}
+	return schema
+}
+
+//New creates OpenAPI document with API key and bearer token security schemes
+func New(title, description, version string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    &Info{Title: title, Description: description, Version: version},
+		Paths:   make(map[string]*PathItem),
+		Components: &Components{
+			Schemas: make(map[string]*Schema),
+			SecuritySchemes: map[string]*SecurityScheme{
+				securityAPIKey: {Type: "apiKey", Name: "X-Api-Key", In: "header"},
+				securityBearer: {Type: "http", Scheme: "bearer"},
+			},
+		},
+		Security: []map[string][]string{{securityAPIKey: {}}, {securityBearer: {}}},
+	}
+}
@@ -0,0 +1,126 @@
+package openapi
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const componentPrefix = "#/components/schemas/"
+
+var (
+	timeType        = reflect.TypeOf(time.Time{})
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+//Schema represents JSON schema subset used by OpenAPI 3
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+//Property returns property schema matching name the way encoding/json does: exact match first, then case insensitive
+func (s *Schema) Property(name string) *Schema {
+	if property, ok := s.Properties[name]; ok {
+		return property
+	}
+	for key, property := range s.Properties {
+		if strings.EqualFold(key, name) {
+			return property
+		}
+	}
+	return nil
+}
+
+//generator generates schemas from Go types, named structs are registered as components
+type generator struct {
+	schemas map[string]*Schema
+}
+
+func (g *generator) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if t.Kind() != reflect.Struct && reflect.PtrTo(t).Implements(unmarshalerType) {
+		return &Schema{Description: "custom JSON value"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		name := path.Base(t.PkgPath()) + "." + t.Name()
+		if _, ok := g.schemas[name]; !ok {
+			g.schemas[name] = &Schema{Type: "object"} //placeholder for recursive types
+			g.schemas[name] = g.object(t)
+		}
+		return &Schema{Ref: componentPrefix + name}
+	}
+	return &Schema{}
+}
+
+//object returns struct schema, properties follow encoding/json field names, embedded struct fields are promoted
+func (g *generator) object(t reflect.Type) *Schema {
+	result := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct && fieldType != timeType {
+			embedded := g.object(fieldType)
+			for key, property := range embedded.Properties {
+				if _, ok := result.Properties[key]; !ok {
+					result.Properties[key] = property
+				}
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		switch fieldType.Kind() {
+		case reflect.Chan, reflect.Func, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		result.Properties[name] = g.schema(field.Type)
+	}
+	return result
+}
@@ -0,0 +1,67 @@
+package openapi
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type node struct {
+	Name     string
+	Children []*node `json:",omitempty"`
+}
+
+type embedded struct {
+	Kind string
+}
+
+type payload struct {
+	embedded
+	ID       int64  `json:"id"`
+	Skipped  string `json:"-"`
+	Created  time.Time
+	Data     []byte
+	Labels   map[string]string
+	Root     *node
+	internal string
+}
+
+func TestDocument_Validate(t *testing.T) {
+	document := New("test", "", "1.0.0")
+	document.Add(&Endpoint{Path: "/test", Method: http.MethodPost, OperationID: "test", Request: payload{}, Required: []string{"id"}})
+	schema := document.RequestSchema("/test", http.MethodPost)
+	resolved := document.Resolve(schema)
+	if !assert.NotNil(t, resolved) {
+		return
+	}
+	assert.Equal(t, []string{"Created", "Data", "Kind", "Labels", "Root", "id"}, propertyNames(resolved))
+	assert.Equal(t, componentPrefix+"openapi.node", document.Resolve(resolved.Properties["Root"]).Properties["Children"].Items.Ref)
+
+	var useCases = []struct {
+		description string
+		value       interface{}
+		hasError    bool
+	}{
+		{description: "valid", value: map[string]interface{}{"id": 1.0, "Kind": "a", "Created": "2026-01-01T00:00:00Z", "Labels": map[string]interface{}{"k": "v"}}},
+		{description: "missing required", value: map[string]interface{}{"Kind": "a"}, hasError: true},
+		{description: "not integer", value: map[string]interface{}{"id": 1.5}, hasError: true},
+		{description: "invalid map value", value: map[string]interface{}{"id": 1.0, "Labels": map[string]interface{}{"k": 1.0}}, hasError: true},
+		{description: "invalid nested item", value: map[string]interface{}{"id": 1.0, "Root": map[string]interface{}{"Children": []interface{}{map[string]interface{}{"Name": true}}}}, hasError: true},
+		{description: "unknown property", value: map[string]interface{}{"id": 1.0, "Other": 1.0}},
+	}
+	for _, useCase := range useCases {
+		err := document.Validate(schema, useCase.value)
+		assert.Equal(t, useCase.hasError, err != nil, useCase.description)
+	}
+}
+
+func propertyNames(schema *Schema) []string {
+	var result = make([]string, 0)
+	for _, name := range []string{"Created", "Data", "Kind", "Labels", "Root", "id", "Skipped", "internal", "embedded"} {
+		if _, ok := schema.Properties[name]; ok {
+			result = append(result, name)
+		}
+	}
+	return result
+}
@@ -0,0 +1,99 @@
+package openapi
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+//Validate checks if decoded JSON value conforms to schema, unknown object properties are ignored as with encoding/json
+func (d *Document) Validate(schema *Schema, value interface{}) error {
+	return d.validate(schema, value, "$")
+}
+
+func (d *Document) validate(schema *Schema, value interface{}, location string) error {
+	schema = d.Resolve(schema)
+	if schema == nil || value == nil {
+		return nil
+	}
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%v: expected object", location)
+		}
+		for _, name := range schema.Required {
+			if !hasValue(object, name) {
+				return fmt.Errorf("%v.%v: required", location, name)
+			}
+		}
+		for key, item := range object {
+			property := schema.Property(key)
+			if property == nil {
+				property = schema.AdditionalProperties
+			}
+			if err := d.validate(property, item, location+"."+key); err != nil {
+				return err
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%v: expected array", location)
+		}
+		for i, item := range items {
+			if err := d.validate(schema.Items, item, fmt.Sprintf("%v[%v]", location, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%v: expected string", location)
+		}
+		switch schema.Format {
+		case "date-time":
+			if _, err := time.Parse(time.RFC3339Nano, text); err != nil {
+				return fmt.Errorf("%v: expected RFC3339 date-time: %v", location, text)
+			}
+		case "byte":
+			if _, err := base64.StdEncoding.DecodeString(text); err != nil {
+				return fmt.Errorf("%v: expected base64 encoded bytes", location)
+			}
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return fmt.Errorf("%v: expected integer", location)
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%v: expected number", location)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%v: expected boolean", location)
+		}
+	}
+	return nil
+}
+
+//hasValue returns true if object has not null, not empty string property matched the way encoding/json does
+func hasValue(object map[string]interface{}, name string) bool {
+	value, ok := object[name]
+	if !ok {
+		for key, candidate := range object {
+			if strings.EqualFold(key, name) {
+				value, ok = candidate, true
+				break
+			}
+		}
+	}
+	if !ok || value == nil {
+		return false
+	}
+	text, isText := value.(string)
+	return !isText || text != ""
+}
@@ -0,0 +1,55 @@
+package smirror
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestValidRequest(t *testing.T) {
+	var useCases = []struct {
+		description string
+		path        string
+		body        string
+		expect      bool
+	}{
+		{description: "valid mirror request", path: pathSubmit, body: `{"URL":"gs://bucket/data/f1.csv","Attempt":1}`, expect: true},
+		{description: "case insensitive field", path: pathSubmit, body: `{"url":"gs://bucket/data/f1.csv"}`, expect: true},
+		{description: "missing URL", path: pathSubmit, body: `{"Attempt":1}`, expect: false},
+		{description: "invalid type", path: pathSubmit, body: `{"URL":"gs://bucket/data/f1.csv","Attempt":"1"}`, expect: false},
+		{description: "invalid timestamp", path: pathSubmit, body: `{"URL":"gs://bucket/data/f1.csv","Timestamp":"yesterday"}`, expect: false},
+		{description: "invalid JSON", path: pathSubmit, body: `{"URL":`, expect: false},
+		{description: "empty rule status body", path: pathRuleStatus, body: ``, expect: true},
+		{description: "nested monitor request", path: pathMonitor, body: `{"Arrival":{"StateURL":"gs://bucket/state","SpikeFactor":"x"}}`, expect: false},
+		{description: "valid replay request", path: pathReplay, body: `{"TriggerURL":"gs://bucket/trigger"}`, expect: true},
+	}
+	for _, useCase := range useCases {
+		request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(useCase.body))
+		recorder := httptest.NewRecorder()
+		actual := validRequest(recorder, request, useCase.path)
+		assert.Equal(t, useCase.expect, actual, useCase.description+" "+recorder.Body.String())
+		if !actual {
+			assert.Equal(t, http.StatusBadRequest, recorder.Code, useCase.description)
+		}
+	}
+}
+
+func TestStorageMirrorOpenAPI(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	StorageMirrorOpenAPI(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	document := map[string]interface{}{}
+	if !assert.Nil(t, json.Unmarshal(recorder.Body.Bytes(), &document)) {
+		return
+	}
+	assert.Equal(t, "3.0.3", document["openapi"])
+	paths, _ := document["paths"].(map[string]interface{})
+	for _, path := range []string{pathSubmit, pathReplay, pathRuleStatus, pathMonitor} {
+		assert.Contains(t, paths, path)
+	}
+	schemas := document["components"].(map[string]interface{})["schemas"].(map[string]interface{})
+	assert.Contains(t, schemas, "contract.Request")
+	assert.Contains(t, schemas, "replay.Response")
+}
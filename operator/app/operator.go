@@ -0,0 +1,24 @@
+package main
+
+import (
+	"context"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/operator"
+	"log"
+)
+
+func main() {
+	ctx := context.Background()
+	config, err := operator.NewConfigFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		log.Fatalf("failed to load config: %v %v", base.ConfigEnvKey, err)
+	}
+	srv, err := operator.New(config, afs.New())
+	if err != nil {
+		log.Fatalf("failed to create operator service: %v", err)
+	}
+	if err = srv.Run(ctx); err != nil {
+		log.Fatalf("failed to run service: %v ", err)
+	}
+}
@@ -0,0 +1,92 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+//client represents MirrorRule custom resource client
+type client interface {
+	//List returns MirrorRule resources
+	List(ctx context.Context) ([]*MirrorRule, error)
+	//UpdateStatus updates MirrorRule status subresource
+	UpdateStatus(ctx context.Context, rule *MirrorRule) error
+}
+
+type restClient struct {
+	config *Config
+	http   *http.Client
+}
+
+type ruleList struct {
+	Items []*MirrorRule `json:"items"`
+}
+
+func (c *restClient) List(ctx context.Context) ([]*MirrorRule, error) {
+	data, err := c.do(ctx, http.MethodGet, c.config.ResourceURL(), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	list := &ruleList{}
+	if err = json.Unmarshal(data, list); err != nil {
+		return nil, errors.Wrap(err, "failed to decode MirrorRule list")
+	}
+	return list.Items, nil
+}
+
+func (c *restClient) UpdateStatus(ctx context.Context, rule *MirrorRule) error {
+	patch, err := json.Marshal(map[string]interface{}{"status": rule.Status})
+	if err != nil {
+		return err
+	}
+	_, err = c.do(ctx, http.MethodPatch, c.config.StatusURL(&rule.Metadata), "application/merge-patch+json", patch)
+	return err
+}
+
+func (c *restClient) do(ctx context.Context, method, URL, contentType string, body []byte) ([]byte, error) {
+	request, err := http.NewRequestWithContext(ctx, method, URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	//service account tokens are rotated, thus read with each request
+	if token, err := ioutil.ReadFile(c.config.TokenPath); err == nil {
+		request.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	response, err := c.http.Do(request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to %v %v", method, URL)
+	}
+	defer response.Body.Close()
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("failed to %v %v: %v %s", method, URL, response.Status, data)
+	}
+	return data, nil
+}
+
+func newRestClient(config *Config) (*restClient, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if ca, err := ioutil.ReadFile(config.CAPath); err == nil {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.Errorf("invalid CA certificate: %v", config.CAPath)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return &restClient{config: config, http: &http.Client{Transport: transport, Timeout: time.Minute}}, nil
+}
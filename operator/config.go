@@ -0,0 +1,120 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/base"
+	"github.com/viant/toolbox"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultGroup       = "smirror.viant.io"
+	defaultVersion     = "v1"
+	defaultPlural      = "mirrorrules"
+	defaultIntervalSec = 30
+	serviceAccountPath = "/var/run/secrets/kubernetes.io/serviceaccount/"
+)
+
+//Config represents operator config
+type Config struct {
+	base.Config
+	//RulesURL dedicated rules location materialized from MirrorRule resources, i.e. mirror Mirrors.BaseURL
+	RulesURL string
+	//Namespace watched namespace, empty - all namespaces
+	Namespace string `json:",omitempty"`
+	Group     string `json:",omitempty"`
+	Version   string `json:",omitempty"`
+	Plural    string `json:",omitempty"`
+	//IntervalSec reconciliation interval
+	IntervalSec int `json:",omitempty"`
+	//APIURL kubernetes API server URL, in cluster service env by default
+	APIURL string `json:",omitempty"`
+	//TokenPath service account token path
+	TokenPath string `json:",omitempty"`
+	//CAPath API server CA certificate path
+	CAPath string `json:",omitempty"`
+}
+
+//Init initialises config
+func (c *Config) Init() {
+	c.Config.Init()
+	if c.Group == "" {
+		c.Group = defaultGroup
+	}
+	if c.Version == "" {
+		c.Version = defaultVersion
+	}
+	if c.Plural == "" {
+		c.Plural = defaultPlural
+	}
+	if c.IntervalSec == 0 {
+		c.IntervalSec = defaultIntervalSec
+	}
+	if c.APIURL == "" && os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+		c.APIURL = fmt.Sprintf("https://%v:%v", os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT"))
+	}
+	if c.TokenPath == "" {
+		c.TokenPath = serviceAccountPath + "token"
+	}
+	if c.CAPath == "" {
+		c.CAPath = serviceAccountPath + "ca.crt"
+	}
+}
+
+//Validate checks if config is valid
+func (c *Config) Validate() error {
+	if c.RulesURL == "" {
+		return errors.New("rulesURL was empty")
+	}
+	if c.APIURL == "" {
+		return errors.New("apiURL was empty")
+	}
+	return nil
+}
+
+//Interval returns reconciliation interval
+func (c *Config) Interval() time.Duration {
+	return time.Duration(c.IntervalSec) * time.Second
+}
+
+//ResourceURL returns custom resource collection URL
+func (c *Config) ResourceURL() string {
+	if c.Namespace == "" {
+		return fmt.Sprintf("%v/apis/%v/%v/%v", strings.TrimRight(c.APIURL, "/"), c.Group, c.Version, c.Plural)
+	}
+	return fmt.Sprintf("%v/apis/%v/%v/namespaces/%v/%v", strings.TrimRight(c.APIURL, "/"), c.Group, c.Version, c.Namespace, c.Plural)
+}
+
+//StatusURL returns custom resource status subresource URL
+func (c *Config) StatusURL(metadata *Metadata) string {
+	return fmt.Sprintf("%v/apis/%v/%v/namespaces/%v/%v/%v/status", strings.TrimRight(c.APIURL, "/"), c.Group, c.Version, metadata.Namespace, c.Plural, metadata.Name)
+}
+
+//NewConfigFromEnv returns new config from env
+func NewConfigFromEnv(ctx context.Context, key string) (*Config, error) {
+	JSONOrURL := strings.TrimSpace(os.Getenv(key))
+	cfg := &Config{}
+	if toolbox.IsStructuredJSON(JSONOrURL) {
+		if err := json.NewDecoder(strings.NewReader(JSONOrURL)).Decode(cfg); err != nil {
+			return nil, errors.Wrap(err, "failed to decode config")
+		}
+	} else {
+		reader, err := afs.New().OpenURL(ctx, JSONOrURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to download config: %v", JSONOrURL)
+		}
+		defer reader.Close()
+		if err = json.NewDecoder(reader).Decode(cfg); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode config: %v ", JSONOrURL)
+		}
+		cfg.URL = JSONOrURL
+	}
+	cfg.Init()
+	return cfg, cfg.Validate()
+}
@@ -0,0 +1,17 @@
+package operator
+
+import "github.com/viant/smirror/base"
+
+//Response represents reconciliation response
+type Response struct {
+	Status       string
+	Error        string            `json:",omitempty"`
+	Materialized []string          `json:",omitempty"`
+	Removed      []string          `json:",omitempty"`
+	Invalid      map[string]string `json:",omitempty"`
+}
+
+//NewResponse creates a response
+func NewResponse() *Response {
+	return &Response{Status: base.StatusOK, Invalid: make(map[string]string)}
+}
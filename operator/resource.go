@@ -0,0 +1,91 @@
+package operator
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	//ConditionValid rule spec validation condition
+	ConditionValid = "Valid"
+	//ConditionMaterialized rule materialization condition
+	ConditionMaterialized = "Materialized"
+
+	conditionTrue  = "True"
+	conditionFalse = "False"
+)
+
+//MirrorRule represents MirrorRule custom resource
+type MirrorRule struct {
+	APIVersion string          `json:"apiVersion,omitempty"`
+	Kind       string          `json:"kind,omitempty"`
+	Metadata   Metadata        `json:"metadata"`
+	Spec       json.RawMessage `json:"spec,omitempty"`
+	Status     Status          `json:"status,omitempty"`
+}
+
+//Metadata represents custom resource metadata
+type Metadata struct {
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	Generation int64  `json:"generation,omitempty"`
+}
+
+//Status represents MirrorRule status
+type Status struct {
+	ObservedGeneration int64        `json:"observedGeneration,omitempty"`
+	RuleURL            string       `json:"ruleURL,omitempty"`
+	Conditions         []*Condition `json:"conditions,omitempty"`
+	LastTransfer       *Transfer    `json:"lastTransfer,omitempty"`
+}
+
+//Condition represents status condition
+type Condition struct {
+	Type               string    `json:"type"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Message            string    `json:"message,omitempty"`
+	LastTransitionTime time.Time `json:"lastTransitionTime"`
+}
+
+//Transfer represents last transfer stats taken from response log
+type Transfer struct {
+	Status string    `json:"status"`
+	Time   time.Time `json:"time"`
+	Count  int       `json:"count"`
+}
+
+//Key returns rule key used as materialized rule name
+func (r *MirrorRule) Key() string {
+	if r.Metadata.Namespace == "" {
+		return r.Metadata.Name
+	}
+	return r.Metadata.Namespace + "_" + r.Metadata.Name
+}
+
+//SetCondition sets status condition, transition time changes only with condition status
+func (r *MirrorRule) SetCondition(kind, status, reason, message string) {
+	for _, condition := range r.Status.Conditions {
+		if condition.Type != kind {
+			continue
+		}
+		if condition.Status != status {
+			condition.LastTransitionTime = time.Now().UTC()
+		}
+		condition.Status = status
+		condition.Reason = reason
+		condition.Message = message
+		return
+	}
+	r.Status.Conditions = append(r.Status.Conditions, &Condition{Type: kind, Status: status, Reason: reason, Message: message, LastTransitionTime: time.Now().UTC()})
+}
+
+//Condition returns status condition for type
+func (r *MirrorRule) Condition(kind string) *Condition {
+	for _, condition := range r.Status.Conditions {
+		if condition.Type == kind {
+			return condition
+		}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package operator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/viant/afs"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"path"
+	"strings"
+	"time"
+)
+
+//Service represents MirrorRule operator service
+type Service interface {
+	//Reconcile materializes MirrorRule resources into rules location and updates their status
+	Reconcile(ctx context.Context) *Response
+	//Run reconciles resources with config interval till context is done
+	Run(ctx context.Context) error
+}
+
+type service struct {
+	config *Config
+	fs     afs.Service
+	client client
+}
+
+//Reconcile materializes MirrorRule resources into rules location and updates their status
+func (s *service) Reconcile(ctx context.Context) *Response {
+	response := NewResponse()
+	if err := s.reconcile(ctx, response); err != nil {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+	}
+	return response
+}
+
+//Run reconciles resources with config interval till context is done
+func (s *service) Run(ctx context.Context) error {
+	for {
+		response := s.Reconcile(ctx)
+		if data, err := json.Marshal(response); err == nil {
+			fmt.Printf("%s\n", data)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.config.Interval()):
+		}
+	}
+}
+
+func (s *service) reconcile(ctx context.Context, response *Response) error {
+	resources, err := s.client.List(ctx)
+	if err != nil {
+		return err
+	}
+	var statusErrors []string
+	desired := make(map[string]bool)
+	for _, resource := range resources {
+		ruleURL := url.Join(s.config.RulesURL, resource.Key()+base.JSONExt)
+		desired[ruleURL] = true
+		rule := s.materialize(ctx, resource, ruleURL, response)
+		resource.Status.ObservedGeneration = resource.Metadata.Generation
+		resource.Status.LastTransfer = s.lastTransfer(ctx, resource, rule)
+		if err := s.client.UpdateStatus(ctx, resource); err != nil {
+			statusErrors = append(statusErrors, err.Error())
+		}
+	}
+	if err = s.removeOrphans(ctx, desired, response); err != nil {
+		return err
+	}
+	if len(statusErrors) > 0 {
+		return fmt.Errorf("failed to update status: %v", strings.Join(statusErrors, "; "))
+	}
+	return nil
+}
+
+//materialize validates resource spec and uploads it as rule, invalid spec keeps last valid rule in place
+func (s *service) materialize(ctx context.Context, resource *MirrorRule, ruleURL string, response *Response) *config.Rule {
+	rule := &config.Rule{}
+	err := json.Unmarshal(resource.Spec, rule)
+	if err == nil {
+		rule.Info.URL = ruleURL
+		if err = rule.Init(ctx, s.fs); err == nil {
+			err = rule.Validate()
+		}
+	}
+	if err != nil {
+		response.Invalid[resource.Key()] = err.Error()
+		resource.SetCondition(ConditionValid, conditionFalse, "InvalidSpec", err.Error())
+		resource.SetCondition(ConditionMaterialized, conditionFalse, "InvalidSpec", "last valid rule is kept")
+		return nil
+	}
+	resource.SetCondition(ConditionValid, conditionTrue, "Validated", "")
+	resource.Status.RuleURL = ruleURL
+	data := new(bytes.Buffer)
+	if err = json.Indent(data, resource.Spec, "", "  "); err != nil {
+		data = bytes.NewBuffer(resource.Spec)
+	}
+	if existing, err := s.fs.DownloadWithURL(ctx, ruleURL); err == nil && bytes.Equal(existing, data.Bytes()) {
+		resource.SetCondition(ConditionMaterialized, conditionTrue, "Synced", "")
+		return rule
+	}
+	if err = s.fs.Upload(ctx, ruleURL, file.DefaultFileOsMode, data); err != nil {
+		resource.SetCondition(ConditionMaterialized, conditionFalse, "UploadFailed", err.Error())
+		return rule
+	}
+	response.Materialized = append(response.Materialized, ruleURL)
+	resource.SetCondition(ConditionMaterialized, conditionTrue, "Synced", "")
+	return rule
+}
+
+//removeOrphans removes materialized rules without MirrorRule resource
+func (s *service) removeOrphans(ctx context.Context, desired map[string]bool, response *Response) error {
+	if exists, _ := s.fs.Exists(ctx, s.config.RulesURL); !exists {
+		return nil
+	}
+	objects, err := s.fs.List(ctx, s.config.RulesURL)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if object.IsDir() || path.Ext(object.Name()) != base.JSONExt || desired[object.URL()] {
+			continue
+		}
+		if err = s.fs.Delete(ctx, object.URL()); err != nil {
+			return err
+		}
+		response.Removed = append(response.Removed, object.URL())
+	}
+	return nil
+}
+
+//lastTransfer returns latest response log entry stats for the rule partition, today or yesterday
+func (s *service) lastTransfer(ctx context.Context, resource *MirrorRule, rule *config.Rule) *Transfer {
+	responseLog := s.config.ResponseLog
+	if responseLog == nil {
+		return resource.Status.LastTransfer
+	}
+	partition := resource.Key()
+	if rule != nil && rule.Info.Workflow != "" {
+		partition = rule.Info.Workflow
+	}
+	now := time.Now()
+	for _, at := range []time.Time{now, now.Add(-24 * time.Hour)} {
+		partitionURL := responseLog.PartitionURL(at, partition)
+		statuses, err := s.fs.List(ctx, partitionURL)
+		if err != nil {
+			continue
+		}
+		var result *Transfer
+		count := 0
+		for _, status := range statuses {
+			if !status.IsDir() || url.Equals(status.URL(), partitionURL) {
+				continue
+			}
+			objects, err := s.fs.List(ctx, status.URL())
+			if err != nil {
+				continue
+			}
+			for _, object := range objects {
+				if object.IsDir() {
+					continue
+				}
+				count++
+				if result == nil || object.ModTime().After(result.Time) {
+					result = &Transfer{Status: status.Name(), Time: object.ModTime().UTC()}
+				}
+			}
+		}
+		if result != nil {
+			result.Count = count
+			return result
+		}
+	}
+	return resource.Status.LastTransfer
+}
+
+func newService(config *Config, fs afs.Service, client client) *service {
+	return &service{config: config, fs: fs, client: client}
+}
+
+//New creates an operator service
+func New(config *Config, fs afs.Service) (Service, error) {
+	client, err := newRestClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return newService(config, fs, client), nil
+}
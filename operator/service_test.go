@@ -0,0 +1,72 @@
+package operator
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/base"
+	"strings"
+	"testing"
+)
+
+type fakeClient struct {
+	rules   []*MirrorRule
+	updated map[string]*MirrorRule
+}
+
+func (c *fakeClient) List(ctx context.Context) ([]*MirrorRule, error) {
+	return c.rules, nil
+}
+
+func (c *fakeClient) UpdateStatus(ctx context.Context, rule *MirrorRule) error {
+	c.updated[rule.Key()] = rule
+	return nil
+}
+
+func TestService_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	rulesURL := "mem://localhost/operator/rules"
+	responseLog := &base.ResponseLog{URL: "mem://localhost/operator/responses"}
+	_, _ = responseLog.Persist(ctx, fs, "data_valid", base.StatusOK, "t1", map[string]string{"Status": "ok"})
+	_ = fs.Upload(ctx, rulesURL+"/data_deleted.json", 0644, strings.NewReader("{}"))
+
+	client := &fakeClient{updated: map[string]*MirrorRule{}, rules: []*MirrorRule{
+		{
+			Metadata: Metadata{Name: "valid", Namespace: "data", Generation: 2},
+			Spec:     json.RawMessage(`{"Source":{"Prefix":"/data/"},"Dest":{"URL":"mem://localhost/dest"}}`),
+		},
+		{
+			Metadata: Metadata{Name: "invalid", Namespace: "data", Generation: 1},
+			Spec:     json.RawMessage(`{"Source":{"Prefix":"/data/"}}`),
+		},
+	}}
+	config := &Config{RulesURL: rulesURL, APIURL: "https://localhost"}
+	config.ResponseLog = responseLog
+	srv := newService(config, fs, client)
+
+	response := srv.Reconcile(ctx)
+	if !assert.Equal(t, base.StatusOK, response.Status, response.Error) {
+		return
+	}
+	assert.Equal(t, []string{rulesURL + "/data_valid.json"}, response.Materialized)
+	assert.Equal(t, []string{rulesURL + "/data_deleted.json"}, response.Removed)
+	assert.Contains(t, response.Invalid["data_invalid"], "dest was empty")
+
+	valid := client.updated["data_valid"]
+	assert.Equal(t, int64(2), valid.Status.ObservedGeneration)
+	assert.Equal(t, conditionTrue, valid.Condition(ConditionValid).Status)
+	assert.Equal(t, conditionTrue, valid.Condition(ConditionMaterialized).Status)
+	if assert.NotNil(t, valid.Status.LastTransfer) {
+		assert.Equal(t, base.StatusOK, valid.Status.LastTransfer.Status)
+		assert.Equal(t, 1, valid.Status.LastTransfer.Count)
+	}
+	invalid := client.updated["data_invalid"]
+	assert.Equal(t, conditionFalse, invalid.Condition(ConditionValid).Status)
+	exists, _ := fs.Exists(ctx, rulesURL+"/data_invalid.json")
+	assert.False(t, exists)
+
+	response = srv.Reconcile(ctx)
+	assert.Empty(t, response.Materialized)
+}
@@ -0,0 +1,73 @@
+package smirror
+
+import (
+	"context"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"sync"
+	"time"
+)
+
+const (
+	//throughputWeight weight of the latest observation in dest throughput moving average
+	throughputWeight = 0.3
+	//minObservedBytes min upload size used for throughput observation, smaller uploads are dominated by request overhead
+	minObservedBytes = 1024 * 1024
+)
+
+//partSizer tracks observed upload throughput per dest bucket
+type partSizer struct {
+	mux        sync.Mutex
+	throughput map[string]float64
+}
+
+//observe records upload throughput
+func (p *partSizer) observe(destURL string, bytes int64, elapsed time.Duration) {
+	if bytes < minObservedBytes || elapsed <= 0 {
+		return
+	}
+	observed := float64(bytes) / elapsed.Seconds()
+	key := partSizerKey(destURL)
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if current, ok := p.throughput[key]; ok {
+		observed = throughputWeight*observed + (1-throughputWeight)*current
+	}
+	p.throughput[key] = observed
+}
+
+//Throughput returns observed dest bucket upload throughput in bytes/sec or 0
+func (p *partSizer) Throughput(destURL string) float64 {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	return p.throughput[partSizerKey(destURL)]
+}
+
+func partSizerKey(destURL string) string {
+	return url.Scheme(destURL, "") + "://" + url.Host(destURL)
+}
+
+func newPartSizer() *partSizer {
+	return &partSizer{throughput: make(map[string]float64)}
+}
+
+//adaptivePartSize returns part size tuned with observed dest throughput and remaining deadline, chosen size is reported in response
+func (s *service) adaptivePartSize(ctx context.Context, adaptive *config.AdaptivePartSize, destURL string, static int, response *contract.Response) int {
+	throughput := s.partSizer.Throughput(destURL)
+	var remaining time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining = time.Until(deadline); remaining < time.Millisecond {
+			remaining = time.Millisecond
+		}
+	}
+	size, reason := adaptive.PartSize(destURL, response.FileSize, static, throughput, remaining)
+	response.PartSizing = &contract.PartSizing{
+		Size:          size,
+		Reason:        reason,
+		Parts:         int((response.FileSize + int64(size) - 1) / int64(size)),
+		ThroughputBps: throughput,
+		RemainingMs:   int(remaining / time.Millisecond),
+	}
+	return size
+}
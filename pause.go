@@ -0,0 +1,280 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/job"
+	"github.com/viant/smirror/shared"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+//StorageMirrorPause cloud function entry point, pauses or resumes ingestion
+func StorageMirrorPause(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, auth.EndpointPause) {
+		return
+	}
+	err := pauseIngestion(w, r)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func pauseIngestion(writer http.ResponseWriter, httpRequest *http.Request) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	request := &contract.PauseRequest{Tenant: httpRequest.URL.Query().Get("tenant")}
+	if httpRequest.ContentLength > 0 {
+		defer func() {
+			_ = httpRequest.Body.Close()
+		}()
+		if err = json.NewDecoder(httpRequest.Body).Decode(&request); err != nil {
+			return errors.Wrapf(err, "failed to decode %T", request)
+		}
+	}
+	ctx := context.Background()
+	service, err := NewFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		return err
+	}
+	response := service.Pause(ctx, request)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+//Pause pauses or resumes ingestion for all rules or a rule, resume moves deferred source objects back to source location
+func (s *service) Pause(ctx context.Context, request *contract.PauseRequest) *contract.PauseResponse {
+	response := contract.NewPauseResponse()
+	err := s.pause(ctx, request, response)
+	s.mux.Lock()
+	s.paused = nil //markers are reloaded with the next event
+	s.mux.Unlock()
+	if err != nil {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+	}
+	markers, err := s.loadPauseMarkers(ctx)
+	if err != nil && response.Error == "" {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+	}
+	for _, marker := range markers {
+		response.Paused = append(response.Paused, marker)
+	}
+	sort.Slice(response.Paused, func(i, j int) bool { return response.Paused[i].Name < response.Paused[j].Name })
+	return response
+}
+
+func (s *service) pause(ctx context.Context, request *contract.PauseRequest, response *contract.PauseResponse) error {
+	pause := s.config.Pause
+	if pause == nil {
+		return errors.New("pause was not configured")
+	}
+	name := request.Rule
+	if name == "" {
+		name = config.PauseAll
+	}
+	if strings.Contains(name, "/") {
+		return errors.Errorf("invalid rule name: %v", name)
+	}
+	switch request.Action {
+	case "":
+		return nil
+	case contract.PauseActionPause:
+		data, err := json.Marshal(&config.PauseMarker{Name: name, Reason: request.Reason, Paused: time.Now()})
+		if err == nil {
+			err = s.fs.Upload(ctx, pause.MarkerURL(name), file.DefaultFileOsMode, bytes.NewReader(data))
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to pause %v", name)
+		}
+		shared.LogF("ingestion paused: %v, %v\n", name, request.Reason)
+	case contract.PauseActionResume:
+		if ok, _ := s.fs.Exists(ctx, pause.MarkerURL(name)); ok {
+			if err := s.fs.Delete(ctx, pause.MarkerURL(name)); err != nil {
+				return errors.Wrapf(err, "failed to resume %v", name)
+			}
+		}
+		shared.LogF("ingestion resumed: %v\n", name)
+		if pause.DeferURL == "" {
+			return nil
+		}
+		restored, err := s.restoreDeferred(ctx, pause, pause.DeferBaseURL(name))
+		response.Restored = restored
+		return err
+	case contract.PauseActionCatchUp:
+		restored, err := s.catchUp(ctx, pause, name)
+		response.Restored = restored
+		return err
+	default:
+		return errors.Errorf("unsupported pause action: %v", request.Action)
+	}
+	return nil
+}
+
+//restoreDeferred moves deferred objects back to source location, which triggers new storage events
+func (s *service) restoreDeferred(ctx context.Context, pause *config.Pause, baseURL string) ([]string, error) {
+	objects, err := s.fs.List(ctx, baseURL)
+	if err != nil {
+		return nil, nil //nothing was deferred
+	}
+	var restored []string
+	for _, object := range objects {
+		if url.Equals(object.URL(), baseURL) {
+			continue
+		}
+		if object.IsDir() {
+			folderRestored, err := s.restoreDeferred(ctx, pause, object.URL())
+			restored = append(restored, folderRestored...)
+			if err != nil {
+				return restored, err
+			}
+			continue
+		}
+		sourceURL := s.deferredSourceURL(pause, object)
+		options, err := s.restoreOptions(ctx, sourceURL)
+		if err != nil {
+			return restored, errors.Wrapf(err, "failed to restore %v", object.URL())
+		}
+		if err = s.fs.Move(ctx, object.URL(), sourceURL, destMoveOptions(options)...); err != nil {
+			return restored, errors.Wrapf(err, "failed to restore %v", object.URL())
+		}
+		restored = append(restored, sourceURL)
+	}
+	return restored, nil
+}
+
+//restoreOptions returns storage options of rule matching restored source URL
+func (s *service) restoreOptions(ctx context.Context, sourceURL string) ([]storage.Option, error) {
+	matched := s.config.Mirrors.Match(sourceURL)
+	if len(matched) == 0 || matched[0].Source == nil {
+		return nil, nil
+	}
+	return s.secret.StorageOpts(ctx, matched[0].Source.CloneWithURL(sourceURL))
+}
+
+//deferredSourceURL returns deferred object source URL, DeferURL/marker/bucket/path is restored as scheme://bucket/path
+func (s *service) deferredSourceURL(pause *config.Pause, object storage.Object) string {
+	relative := strings.Trim(strings.TrimPrefix(url.Path(object.URL()), url.Path(pause.DeferURL)), "/")
+	if index := strings.Index(relative, "/"); index != -1 {
+		relative = relative[index+1:] //marker
+	}
+	return url.Scheme(pause.DeferURL, file.Scheme) + "://" + relative
+}
+
+//loadPauseMarkers loads pause markers from control location
+func (s *service) loadPauseMarkers(ctx context.Context) (map[string]*config.PauseMarker, error) {
+	var result = make(map[string]*config.PauseMarker)
+	pause := s.config.Pause
+	if pause == nil {
+		return result, nil
+	}
+	if ok, _ := s.fs.Exists(ctx, pause.ControlURL); !ok {
+		return result, nil
+	}
+	objects, err := s.fs.List(ctx, pause.ControlURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list pause markers: %v", pause.ControlURL)
+	}
+	for _, object := range objects {
+		if object.IsDir() {
+			continue
+		}
+		marker := &config.PauseMarker{Name: object.Name(), Paused: object.ModTime()}
+		if data, err := s.fs.DownloadWithURL(ctx, object.URL()); err == nil && len(data) > 0 {
+			_ = json.Unmarshal(data, marker)
+		}
+		marker.Name = object.Name()
+		result[marker.Name] = marker
+	}
+	return result, nil
+}
+
+//pauseMarker returns pause marker for supplied rule, pause markers are reloaded with Pause.CheckInMs frequency
+func (s *service) pauseMarker(ctx context.Context, rule *config.Rule) *config.PauseMarker {
+	now := time.Now()
+	s.mux.Lock()
+	refresh := s.paused == nil || now.After(s.nextPause)
+	if refresh {
+		s.nextPause = now.Add(s.config.Pause.CheckInMs.Duration())
+	}
+	s.mux.Unlock()
+	if refresh {
+		markers, err := s.loadPauseMarkers(ctx)
+		if err != nil {
+			shared.LogF("%v\n", err)
+		} else {
+			s.mux.Lock()
+			s.paused = markers
+			s.mux.Unlock()
+		}
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if marker, ok := s.paused[config.PauseAll]; ok {
+		return marker
+	}
+	return s.paused[rule.Info.Workflow]
+}
+
+//checkPause defers paused rule event
+func (s *service) checkPause(ctx context.Context, rule *config.Rule, request *contract.Request, response *contract.Response) (bool, error) {
+	pause := s.config.Pause
+	if pause == nil {
+		return false, nil
+	}
+	marker := s.pauseMarker(ctx, rule)
+	if marker == nil {
+		return false, nil
+	}
+	pauseErr := fmt.Errorf("ingestion paused by %v marker: %v", marker.Name, marker.Reason)
+	if err := s.deferEvent(ctx, rule, marker.Name, pauseErr, request, response); err != nil {
+		return true, err
+	}
+	response.Status = base.StatusPaused
+	return true, nil
+}
+
+//deferEvent requeues event with delay or moves source object to marker defer location,
+//without pause defer settings it returns retryable paused error so that platform redelivers the event
+func (s *service) deferEvent(ctx context.Context, rule *config.Rule, marker string, cause error, request *contract.Request, response *contract.Response) error {
+	pause := s.config.Pause
+	switch {
+	case pause != nil && pause.Requeue != nil:
+		action := &job.Action{Action: job.ActionRequeue, Requeue: pause.Requeue}
+		jobContext := job.NewContext(ctx, cause, request.URL, request.URL)
+		jobContext.Requeue = request.Requeue
+		if err := action.Do(jobContext, s.fs, s.notifier.Notify, &rule.Info, response); err != nil {
+			return errors.Wrapf(err, "failed to requeue deferred %v", request.URL)
+		}
+	case pause != nil && pause.DeferURL != "":
+		deferURL := pause.DeferDestURL(marker, request.URL)
+		options, err := s.secret.StorageOpts(ctx, rule.Source.CloneWithURL(request.URL))
+		if err != nil {
+			return base.NewCodedError(base.ErrorCodeAuth, err)
+		}
+		if err := s.fs.Move(ctx, request.URL, deferURL, sourceMoveOptions(options)...); err != nil {
+			return errors.Wrapf(err, "failed to defer %v", request.URL)
+		}
+		response.DeferredURL = deferURL
+	default:
+		return base.NewCodedError(base.ErrorCodePaused, cause)
+	}
+	return nil
+}
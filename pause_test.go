@@ -0,0 +1,97 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"testing"
+)
+
+func TestService_Pause(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/pause")
+	cfg := &Config{
+		Pause: &config.Pause{
+			ControlURL: "mem://localhost/pause/control",
+			DeferURL:   "mem://localhost/pause/deferred",
+			CheckInMs:  1,
+		},
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Info:   base.Info{Workflow: "partner"},
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/pause/data", Suffix: ".csv"}},
+				Dest:   &config.Resource{URL: "mem://localhost/pause/dest"},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sourceURL := "mem://localhost/pause/data/f1.csv"
+	_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("1,2,3"))
+
+	pauseResponse := service.Pause(ctx, &contract.PauseRequest{Action: contract.PauseActionPause, Rule: "partner", Reason: "maintenance"})
+	assert.Equal(t, base.StatusOK, pauseResponse.Status, pauseResponse.Error)
+	if assert.Equal(t, 1, len(pauseResponse.Paused)) {
+		assert.Equal(t, "partner", pauseResponse.Paused[0].Name)
+		assert.Equal(t, "maintenance", pauseResponse.Paused[0].Reason)
+	}
+
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	assert.Equal(t, base.StatusPaused, response.Status, response.Error)
+	assert.Equal(t, "mem://localhost/pause/deferred/partner/localhost/pause/data/f1.csv", response.DeferredURL)
+	exists, _ := fs.Exists(ctx, sourceURL)
+	assert.False(t, exists, "paused source is deferred")
+	exists, _ = fs.Exists(ctx, "mem://localhost/pause/dest/f1.csv")
+	assert.False(t, exists, "paused source is not mirrored")
+
+	pauseResponse = service.Pause(ctx, &contract.PauseRequest{Action: contract.PauseActionResume, Rule: "partner"})
+	assert.Equal(t, base.StatusOK, pauseResponse.Status, pauseResponse.Error)
+	assert.Equal(t, 0, len(pauseResponse.Paused))
+	assert.EqualValues(t, []string{sourceURL}, pauseResponse.Restored)
+	exists, _ = fs.Exists(ctx, sourceURL)
+	assert.True(t, exists, "deferred source is restored")
+
+	response = service.Mirror(ctx, contract.NewRequest(sourceURL))
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+}
+
+func TestService_PauseWithoutDefer(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/pauseall")
+	cfg := &Config{
+		Pause: &config.Pause{ControlURL: "mem://localhost/pauseall/control", CheckInMs: 1},
+		Poison: &config.Poison{
+			FailureURL:    "mem://localhost/pauseall/failures",
+			DeadLetterURL: "mem://localhost/pauseall/deadletter",
+			MaxFailures:   1,
+		},
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/pauseall/data"}},
+				Dest:   &config.Resource{URL: "mem://localhost/pauseall/dest"},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sourceURL := "mem://localhost/pauseall/data/f1.csv"
+	_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("1,2,3"))
+	service.Pause(ctx, &contract.PauseRequest{Action: contract.PauseActionPause})
+
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	assert.Equal(t, base.ErrorCodePaused, response.ErrorCode)
+	assert.True(t, response.IsRetryable())
+	exists, _ := fs.Exists(ctx, sourceURL)
+	assert.True(t, exists, "paused source is not poisoned")
+}
@@ -0,0 +1,82 @@
+package smirror
+
+import (
+	"github.com/viant/smirror/contract"
+	"runtime"
+	"sync"
+	"time"
+)
+
+//perfMonitor samples runtime memory stats and goroutines while event is processed
+type perfMonitor struct {
+	start runtime.MemStats
+	perf  *contract.Perf
+	mux   sync.Mutex
+	done  chan bool
+	wait  sync.WaitGroup
+}
+
+//sample records runtime stats sample
+func (m *perfMonitor) sample() *runtime.MemStats {
+	stats := &runtime.MemStats{}
+	runtime.ReadMemStats(stats)
+	goroutines := runtime.NumGoroutine()
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.perf.Samples++
+	if stats.HeapInuse > m.perf.HeapPeakBytes {
+		m.perf.HeapPeakBytes = stats.HeapInuse
+	}
+	if stats.Sys > m.perf.SysPeakBytes {
+		m.perf.SysPeakBytes = stats.Sys
+	}
+	if goroutines > m.perf.GoroutinesPeak {
+		m.perf.GoroutinesPeak = goroutines
+	}
+	return stats
+}
+
+//stop stops sampling and returns telemetry since monitor start
+func (m *perfMonitor) stop(response *contract.Response) *contract.Perf {
+	close(m.done)
+	m.wait.Wait()
+	end := m.sample()
+	perf := m.perf
+	perf.AllocatedBytes = end.TotalAlloc - m.start.TotalAlloc
+	perf.Allocations = end.Mallocs - m.start.Mallocs
+	perf.GCCount = end.NumGC - m.start.NumGC
+	perf.GCPauseMs = float64(end.PauseTotalNs-m.start.PauseTotalNs) / float64(time.Millisecond)
+	//PauseNs keeps the most recent 256 GC pauses
+	for i := end.NumGC; i > m.start.NumGC && end.NumGC-i < uint32(len(end.PauseNs)); i-- {
+		if pause := float64(end.PauseNs[(i+255)%256]) / float64(time.Millisecond); pause > perf.GCPauseMaxMs {
+			perf.GCPauseMaxMs = pause
+		}
+	}
+	perf.BufferedBytes = response.FileSize
+	if stream := response.StreamOption; stream != nil && stream.PartSize > 0 && int64(stream.PartSize) < response.FileSize {
+		perf.BufferedBytes = int64(stream.PartSize)
+	}
+	return perf
+}
+
+//startPerfMonitor starts runtime stats sampling with supplied interval
+func startPerfMonitor(interval time.Duration) *perfMonitor {
+	result := &perfMonitor{perf: &contract.Perf{}, done: make(chan bool)}
+	runtime.ReadMemStats(&result.start)
+	result.sample()
+	result.wait.Add(1)
+	go func() {
+		defer result.wait.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				result.sample()
+			case <-result.done:
+				return
+			}
+		}
+	}()
+	return result
+}
@@ -0,0 +1,39 @@
+package smirror
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs/option"
+	"github.com/viant/smirror/contract"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestPerfMonitor(t *testing.T) {
+	var useCases = []struct {
+		description    string
+		response       *contract.Response
+		expectBuffered int64
+	}{
+		{description: "whole object", response: &contract.Response{FileSize: 1024}, expectBuffered: 1024},
+		{description: "streamed object", response: &contract.Response{FileSize: 1 << 30, StreamOption: option.NewStream(64*1024*1024, 1<<30)}, expectBuffered: 64 * 1024 * 1024},
+	}
+	for _, useCase := range useCases {
+		monitor := startPerfMonitor(10 * time.Millisecond)
+		var buffers [][]byte
+		for i := 0; i < 16; i++ {
+			buffers = append(buffers, make([]byte, 1024*1024))
+		}
+		runtime.GC()
+		time.Sleep(30 * time.Millisecond)
+		perf := monitor.stop(useCase.response)
+		assert.True(t, perf.AllocatedBytes >= 16*1024*1024, useCase.description)
+		assert.True(t, perf.HeapPeakBytes > 0, useCase.description)
+		assert.True(t, perf.GCCount >= 1, useCase.description)
+		assert.True(t, perf.GCPauseMs >= perf.GCPauseMaxMs, useCase.description)
+		assert.True(t, perf.GoroutinesPeak >= 2, useCase.description)
+		assert.True(t, perf.Samples >= 2, useCase.description)
+		assert.EqualValues(t, useCase.expectBuffered, perf.BufferedBytes, useCase.description)
+		assert.Equal(t, 16, len(buffers))
+	}
+}
@@ -0,0 +1,91 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/storage"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/job"
+	"time"
+)
+
+//checkPoison resets source object failure counter on success, or registers a failure
+func (s *service) checkPoison(ctx context.Context, request *contract.Request, response *contract.Response) {
+	poison := s.config.Poison
+	if poison == nil || response.Status == base.StatusNoFound || response.Status == base.StatusNoMatch || response.ErrorCode == base.ErrorCodePaused {
+		return
+	}
+	counterURL := poison.CounterURL(request.URL)
+	if response.Error == "" {
+		if ok, _ := s.fs.Exists(ctx, counterURL); ok {
+			_ = s.fs.Delete(ctx, counterURL)
+		}
+		return
+	}
+	if err := s.registerFailure(ctx, poison, counterURL, request, response); err != nil {
+		response.LogError = err.Error()
+	}
+}
+
+//registerFailure increments source object failure counter, after max failures object is moved to dead letter location
+func (s *service) registerFailure(ctx context.Context, poison *config.Poison, counterURL string, request *contract.Request, response *contract.Response) error {
+	var options []storage.Option
+	if rule := response.Rule; rule != nil && rule.Source != nil {
+		var err error
+		if options, err = s.secret.StorageOpts(ctx, rule.Source.CloneWithURL(request.URL)); err != nil {
+			return err
+		}
+	}
+	object, err := s.fs.Object(ctx, request.URL, options...)
+	if err != nil {
+		if base.ErrorCode(err) == base.ErrorCodeNotFound {
+			return nil //source object no longer exists
+		}
+		return errors.Wrapf(err, "failed to get poison source %v", request.URL)
+	}
+	failure := &config.Failure{}
+	if data, err := s.fs.DownloadWithURL(ctx, counterURL); err == nil {
+		_ = json.Unmarshal(data, failure)
+	}
+	if !failure.Modified.Equal(object.ModTime()) {
+		failure = &config.Failure{URL: request.URL, Modified: object.ModTime()}
+	}
+	failure.Count++
+	failure.LastError = response.Error
+	failure.Updated = time.Now()
+	if failure.Count < poison.MaxFailures {
+		data, err := json.Marshal(failure)
+		if err != nil {
+			return err
+		}
+		return s.fs.Upload(ctx, counterURL, file.DefaultFileOsMode, bytes.NewReader(data))
+	}
+	deadLetterURL := poison.DeadLetterDestURL(request.URL)
+	if err = s.fs.Move(ctx, request.URL, deadLetterURL, sourceMoveOptions(options)...); err != nil {
+		return errors.Wrapf(err, "failed to dead-letter %v", request.URL)
+	}
+	_ = s.fs.Delete(ctx, counterURL)
+	poisonErr := fmt.Errorf("poisoned after %v failures: %v", failure.Count, response.Error)
+	response.Status = base.StatusPoisoned
+	response.PoisonError = poisonErr.Error()
+	response.DeadLetterURL = deadLetterURL
+	response.Error = ""
+	if len(poison.OnPoison) == 0 {
+		return nil
+	}
+	info := &base.Info{}
+	jobContext := job.NewContext(ctx, poisonErr, request.URL, request.URL)
+	if response.Rule != nil {
+		info = &response.Rule.Info
+		jobContext = newJobContext(ctx, poisonErr, request, response, object)
+	}
+	jobContext.Response = response
+	actions := &job.Actions{OnFailure: poison.OnPoison}
+	return actions.Run(jobContext, s.fs, s.notifier.Notify, info, response)
+}
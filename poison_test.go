@@ -0,0 +1,84 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"testing"
+)
+
+func TestService_Poison(t *testing.T) {
+	ctx := context.Background()
+	cfg := &Config{
+		Poison: &config.Poison{
+			FailureURL:    "mem://localhost/poison/failures",
+			DeadLetterURL: "mem://localhost/poison/deadletter",
+			MaxFailures:   2,
+		},
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/poison/data", Suffix: ".gz"}},
+				Dest:   &config.Resource{URL: "mem://localhost/poison/dest"},
+				Split:  &config.Split{MaxLines: 10},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	fs := afs.New()
+	sourceURL := "mem://localhost/poison/data/corrupted.csv.gz"
+	_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("not a gzip content"))
+
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	assert.Equal(t, base.StatusError, response.Status)
+	exists, _ := fs.Exists(ctx, cfg.Poison.CounterURL(sourceURL))
+	assert.True(t, exists)
+
+	response = service.Mirror(ctx, contract.NewRequest(sourceURL))
+	assert.Equal(t, base.StatusPoisoned, response.Status, response.Error)
+	assert.Equal(t, "", response.Error)
+	assert.Equal(t, "", response.LogError)
+	assert.Equal(t, "mem://localhost/poison/deadletter/localhost/poison/data/corrupted.csv.gz", response.DeadLetterURL)
+	exists, _ = fs.Exists(ctx, sourceURL)
+	assert.False(t, exists)
+	exists, _ = fs.Exists(ctx, response.DeadLetterURL)
+	assert.True(t, exists)
+	exists, _ = fs.Exists(ctx, cfg.Poison.CounterURL(sourceURL))
+	assert.False(t, exists)
+}
+
+func TestService_RegisterFailure(t *testing.T) {
+	ctx := context.Background()
+	cfg := &Config{
+		Poison: &config.Poison{
+			FailureURL:    "mem://localhost/poisonerr/failures",
+			DeadLetterURL: "mem://localhost/poisonerr/deadletter",
+		},
+	}
+	srv, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	service := srv.(*service)
+	var useCases = []struct {
+		description string
+		URL         string
+		expectError bool
+	}{
+		{description: "source no longer exists", URL: "mem://localhost/poisonerr/data/missing.csv"},
+		{description: "source check error", URL: "unsupported://localhost/poisonerr/data/file.csv", expectError: true},
+	}
+	for _, useCase := range useCases {
+		request := contract.NewRequest(useCase.URL)
+		response := &contract.Response{Error: "failed"}
+		err := service.registerFailure(ctx, cfg.Poison, cfg.Poison.CounterURL(useCase.URL), request, response)
+		assert.Equal(t, useCase.expectError, err != nil, useCase.description)
+	}
+}
@@ -0,0 +1,62 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"testing"
+)
+
+func TestService_Policy(t *testing.T) {
+	var useCases = []struct {
+		description     string
+		destURL         string
+		expectLoadError bool
+	}{
+		{
+			description: "allowed dest",
+			destURL:     "mem://localhost/policy/allowed",
+		},
+		{
+			description:     "denied dest",
+			destURL:         "mem://localhost/policy/external",
+			expectLoadError: true,
+		},
+	}
+
+	ctx := context.Background()
+	for _, useCase := range useCases {
+		cfg := &Config{
+			Policy: &config.Policy{AllowURLs: []string{"mem://localhost/policy/allowed"}},
+			Mirrors: config.Ruleset{Rules: []*config.Rule{
+				{
+					Source: &config.Resource{Basic: matcher.Basic{Prefix: "/policy/data"}},
+					Dest:   &config.Resource{URL: useCase.destURL},
+				},
+			}},
+		}
+		service, err := New(ctx, cfg)
+		if useCase.expectLoadError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		sourceURL := "mem://localhost/policy/data/events.csv"
+		_ = afs.New().Upload(ctx, sourceURL, 0644, strings.NewReader("id\n1\n"))
+		response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+		assert.Equal(t, base.StatusOK, response.Status, useCase.description+" "+response.Error)
+
+		//rule changed to denied dest after load is refused at runtime
+		cfg.Mirrors.Rules[0].Dest.URL = "mem://localhost/policy/external"
+		response = service.Mirror(ctx, contract.NewRequest(sourceURL))
+		assert.Equal(t, base.StatusError, response.Status, useCase.description)
+		assert.Equal(t, base.ErrorCodePolicy, response.ErrorCode, useCase.description)
+	}
+}
@@ -0,0 +1,100 @@
+package smirror
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"net"
+	"net/http"
+	"time"
+)
+
+//checkPreflight checks dest is reachable from this instance before transfer, successful check is reused for Preflight.TTL
+func (s *service) checkPreflight(ctx context.Context, dest *config.Resource, size int64, response *contract.Response) error {
+	if dest == nil || dest.Preflight == nil || size < int64(dest.Preflight.MinSize) {
+		return nil
+	}
+	address, err := dest.Preflight.Address(dest)
+	if err != nil {
+		return base.NewCodedError(base.ErrorCodeConfig, err)
+	}
+	now := time.Now()
+	s.mux.Lock()
+	passed := now.Before(s.preflights[address])
+	s.mux.Unlock()
+	if passed {
+		return nil
+	}
+	err = s.preflight(ctx, dest, address)
+	response.PreflightMs = int(time.Since(now) / time.Millisecond)
+	if err != nil {
+		return base.NewCodedError(base.ErrorCodeUnreachable, errors.Wrapf(err, "destination %v unreachable from this egress IP %v", address, egressIP(address)))
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.preflights == nil {
+		s.preflights = make(map[string]time.Time)
+	}
+	s.preflights[address] = now.Add(dest.Preflight.TTLDuration())
+	return nil
+}
+
+//preflight connects to dest address and sends optional HEAD request, proxied dest is only checked with HEAD request
+func (s *service) preflight(ctx context.Context, dest *config.Resource, address string) error {
+	preflight := dest.Preflight
+	timeout := preflight.TimeoutDuration()
+	host, _, _ := net.SplitHostPort(address)
+	if dest.Proxy == nil || dest.Proxy.Bypass(host) {
+		dialer := &net.Dialer{Timeout: timeout}
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if preflight.IsTLS(dest.URL) {
+			_ = conn.SetDeadline(time.Now().Add(timeout))
+			//handshake only checks TLS is not blocked on the way, server certificate is verified by transfer client
+			if err = tls.Client(conn, &tls.Config{ServerName: host, InsecureSkipVerify: true}).Handshake(); err != nil {
+				return errors.Wrap(err, "tls handshake failed")
+			}
+		}
+	}
+	if !preflight.HEAD {
+		return nil
+	}
+	client, err := s.httpClient(ctx, dest)
+	if err != nil {
+		return err
+	}
+	headClient := &http.Client{Timeout: timeout}
+	if client != nil {
+		headClient.Transport = client.Transport
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, preflight.HeadURL(dest.URL), nil)
+	if err != nil {
+		return err
+	}
+	//any HTTP status proves reachability, auth and path errors are reported by transfer
+	httpResponse, err := headClient.Do(request)
+	if err != nil {
+		return err
+	}
+	return httpResponse.Body.Close()
+}
+
+//egressIP returns local address routed to dest address, NAT gateway may translate it to a different public IP
+func egressIP(address string) string {
+	//UDP dial only selects route, no packet is sent
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return "unknown"
+	}
+	defer conn.Close()
+	if local, ok := conn.LocalAddr().(*net.UDPAddr); ok {
+		return local.IP.String()
+	}
+	return "unknown"
+}
@@ -0,0 +1,98 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestService_Preflight(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/preflight")
+	var heads, uploads int
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		switch request.Method {
+		case http.MethodHead:
+			heads++
+			writer.WriteHeader(http.StatusMethodNotAllowed)
+		case http.MethodPost, http.MethodPut:
+			uploads++
+		}
+	}))
+	defer server.Close()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	closedURL := "http://" + listener.Addr().String() + "/inbound"
+	_ = listener.Close()
+
+	var useCases = []struct {
+		description string
+		destURL     string
+		preflight   *config.Preflight
+		expectCode  string
+		expectHeads int
+	}{
+		{
+			description: "reachable dest with HEAD request",
+			destURL:     server.URL + "/inbound",
+			preflight:   &config.Preflight{HEAD: true},
+			expectHeads: 1,
+		},
+		{
+			description: "unreachable dest",
+			destURL:     closedURL,
+			preflight:   &config.Preflight{Timeout: 500},
+			expectCode:  base.ErrorCodeUnreachable,
+		},
+		{
+			description: "transfer below min size",
+			destURL:     closedURL,
+			preflight:   &config.Preflight{MinSize: 1024 * 1024},
+			expectCode:  base.ErrorCodeTransient,
+		},
+	}
+
+	for i, useCase := range useCases {
+		heads, uploads = 0, 0
+		cfg := &Config{
+			Mirrors: config.Ruleset{Rules: []*config.Rule{
+				{
+					Source: &config.Resource{Basic: matcher.Basic{Prefix: "/preflight/data"}},
+					Dest:   &config.Resource{URL: useCase.destURL, Preflight: useCase.preflight},
+				},
+			}},
+		}
+		service, err := New(ctx, cfg)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		sourceURL := "mem://localhost/preflight/data/f" + string(rune('1'+i)) + ".csv"
+		_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("1,2,3"))
+		response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+		assert.EqualValues(t, useCase.expectHeads, heads, useCase.description)
+		if useCase.expectCode == base.ErrorCodeUnreachable {
+			assert.Equal(t, base.ErrorCodeUnreachable, response.ErrorCode, useCase.description)
+			assert.True(t, response.IsRetryable(), useCase.description)
+			assert.Contains(t, response.Error, "unreachable from this egress IP", useCase.description)
+			continue
+		}
+		if useCase.expectCode != "" {
+			assert.NotEqual(t, base.ErrorCodeUnreachable, response.ErrorCode, useCase.description)
+			continue
+		}
+		assert.Equal(t, base.StatusOK, response.Status, response.Error)
+		assert.True(t, uploads > 0, useCase.description)
+	}
+}
@@ -0,0 +1,176 @@
+package proxy
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	as3 "github.com/viant/afsc/s3"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/native"
+	"strings"
+	"sync"
+)
+
+//ProxyAll copies requests sources server side with batch parallelism, move requests sources are deleted in batches once all copies completed
+func (s *service) ProxyAll(ctx context.Context, requests []*Request, batch *config.Batch) *Response {
+	response := NewResponse()
+	if err := s.proxyAll(ctx, requests, batch, response); err != nil {
+		response.setError(err)
+	}
+	return response
+}
+
+func (s *service) proxyAll(ctx context.Context, requests []*Request, batch *config.Batch, response *Response) error {
+	var moved = make([]*config.Resource, 0)
+	var firstErr error
+	mux := &sync.Mutex{}
+	waitGroup := &sync.WaitGroup{}
+	limiter := make(chan bool, batch.Parallelism)
+	for i := range requests {
+		request := requests[i]
+		if !s.canProxy(request) {
+			continue
+		}
+		waitGroup.Add(1)
+		limiter <- true
+		go func() {
+			defer func() {
+				<-limiter
+				waitGroup.Done()
+			}()
+			//each request gets its own response, merged once proxied
+			requestResponse := NewResponse()
+			err := s.proxy(ctx, request, requestResponse, true)
+			found := requestResponse.movedTo(request.Source.URL) != base.StatusNoFound
+			response.merge(requestResponse)
+			mux.Lock()
+			defer mux.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			if request.Move && found {
+				moved = append(moved, request.Source)
+			}
+		}()
+	}
+	waitGroup.Wait()
+	//sources copied successfully are deleted even if other copies failed, so that they are not moved again
+	if err := s.deleteAll(ctx, moved, batch, response); err != nil {
+		return err
+	}
+	return firstErr
+}
+
+//deleteAll deletes moved sources in batches, s3 sources use DeleteObjects, other sources are deleted in parallel
+func (s *service) deleteAll(ctx context.Context, sources []*config.Resource, batch *config.Batch, response *Response) error {
+	for len(sources) > 0 {
+		size := batch.Size
+		if size > len(sources) {
+			size = len(sources)
+		}
+		var err error
+		if url.Scheme(sources[0].URL, file.Scheme) == as3.Scheme && sameBucket(sources[:size]) {
+			err = s.deleteS3Batch(ctx, sources[:size])
+		} else {
+			err = s.deleteBatch(ctx, sources[:size], batch.Parallelism)
+		}
+		if err != nil {
+			return err
+		}
+		response.DeleteBatches++
+		sources = sources[size:]
+	}
+	return nil
+}
+
+//deleteBatch deletes sources with batch parallelism
+func (s *service) deleteBatch(ctx context.Context, sources []*config.Resource, parallelism int) error {
+	var errorChannel = make(chan error, len(sources))
+	limiter := make(chan bool, parallelism)
+	for i := range sources {
+		limiter <- true
+		go func(source *config.Resource) {
+			defer func() { <-limiter }()
+			options, err := s.secret.StorageOpts(ctx, source)
+			if err == nil {
+				err = s.fs.Delete(ctx, source.URL, options...)
+			}
+			errorChannel <- err
+		}(sources[i])
+	}
+	var result error
+	for range sources {
+		if err := <-errorChannel; err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+//deleteS3Batch deletes same bucket sources with single DeleteObjects request
+func (s *service) deleteS3Batch(ctx context.Context, sources []*config.Resource) error {
+	options, err := s.secret.StorageOpts(ctx, sources[0])
+	if err != nil {
+		return base.NewCodedError(base.ErrorCodeAuth, err)
+	}
+	cfg, err := awsConfig(options)
+	if err != nil {
+		return base.NewCodedError(base.ErrorCodeAuth, err)
+	}
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return err
+	}
+	bucket := url.Host(sources[0].URL)
+	input := &s3.DeleteObjectsInput{Bucket: &bucket, Delete: &s3.Delete{Quiet: aws.Bool(true)}}
+	for _, source := range sources {
+		key := strings.TrimLeft(url.Path(source.URL), "/")
+		input.Delete.Objects = append(input.Delete.Objects, &s3.ObjectIdentifier{Key: aws.String(key)})
+	}
+	if native.RequesterPays(sources[0].URL, options) != nil {
+		input.RequestPayer = aws.String(s3.RequestPayerRequester)
+	}
+	output, err := s3.New(sess).DeleteObjectsWithContext(ctx, input)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete %v objects in %v", len(sources), bucket)
+	}
+	if len(output.Errors) > 0 {
+		failed := output.Errors[0]
+		return errors.Errorf("failed to delete %v objects in %v, s3://%v/%v: %v", len(output.Errors), bucket, bucket, aws.StringValue(failed.Key), aws.StringValue(failed.Message))
+	}
+	return nil
+}
+
+//awsConfig returns credentials aws config or default config
+func awsConfig(options []storage.Option) (*aws.Config, error) {
+	result := &aws.Config{}
+	if _, ok := option.Assign(options, &result); ok {
+		return result, nil
+	}
+	var provider as3.AwsConfigProvider
+	if _, ok := option.Assign(options, &provider); ok {
+		return provider.AwsConfig()
+	}
+	return &aws.Config{}, nil
+}
+
+//sameBucket returns true if all sources share bucket and credentials
+func sameBucket(sources []*config.Resource) bool {
+	bucket := url.Host(sources[0].URL)
+	for _, source := range sources[1:] {
+		if url.Host(source.URL) != bucket || source.Credentials != sources[0].Credentials || source.Region != sources[0].Region || source.RequesterPays != sources[0].RequesterPays {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/secret"
+	"strings"
+	"testing"
+)
+
+func TestService_ProxyAll(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/proxyall"
+	_ = fs.Delete(ctx, baseURL)
+
+	var useCases = []struct {
+		description   string
+		files         int
+		batch         *config.Batch
+		move          bool
+		expectBatches int
+	}{
+		{description: "move in 3 batches", files: 5, batch: &config.Batch{Size: 2, Parallelism: 2}, move: true, expectBatches: 3},
+		{description: "move in single batch", files: 3, batch: &config.Batch{Size: 100, Parallelism: 4}, move: true, expectBatches: 1},
+		{description: "copy", files: 2, batch: &config.Batch{Size: 1, Parallelism: 1}, expectBatches: 0},
+	}
+	for i, useCase := range useCases {
+		sourceURL := fmt.Sprintf("%v/case%03d/source", baseURL, i)
+		destURL := fmt.Sprintf("%v/case%03d/dest", baseURL, i)
+		var requests []*Request
+		for j := 0; j < useCase.files; j++ {
+			URL := fmt.Sprintf("%v/f%v.csv", sourceURL, j)
+			assert.Nil(t, fs.Upload(ctx, URL, 0644, strings.NewReader("data")), useCase.description)
+			requests = append(requests, &Request{Source: &config.Resource{URL: URL}, Dest: &config.Resource{URL: destURL}, Move: useCase.move})
+		}
+		srv := New(fs, &Config{Dest: config.Resource{URL: destURL}}, secret.New("gs", fs))
+		response := srv.ProxyAll(ctx, requests, useCase.batch)
+		if !assert.Equal(t, base.StatusOK, response.Status, useCase.description+" "+response.Error) {
+			continue
+		}
+		assert.Equal(t, useCase.expectBatches, response.DeleteBatches, useCase.description)
+		for _, request := range requests {
+			exists, _ := fs.Exists(ctx, request.Source.URL)
+			assert.Equal(t, !useCase.move, exists, useCase.description)
+		}
+		transferred := response.Copied
+		if useCase.move {
+			transferred = response.Moved
+		}
+		assert.Equal(t, useCase.files, len(transferred), useCase.description)
+		for _, URL := range transferred {
+			exists, _ := fs.Exists(ctx, URL)
+			assert.True(t, exists, useCase.description+" "+URL)
+		}
+	}
+}
@@ -9,15 +9,55 @@ import (
 	"os"
 	"github.com/viant/smirror/base"
 	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/throttle"
 	"strings"
 )
 
+const (
+	megaBytes                     = 1024 * 1024
+	defaultBufferSizeMb           = 32
+	defaultLargeObjectThresholdMb = 512
+)
+
 //Config represents proxy config
 type Config struct {
 	base.Config
 	Dest   config.Resource
 	Source config.Resource
 	Move   bool
+	//BufferSizeMb streaming ranged read buffer size
+	BufferSizeMb base.Megabytes `json:",omitempty"`
+	//LargeObjectThresholdMb object size beyond which proxy always streams
+	LargeObjectThresholdMb int `json:",omitempty"`
+	//RateLimit destination provider API rate limit
+	RateLimit throttle.Config
+}
+
+//Init initialises config
+func (c *Config) Init() {
+	c.Config.Init()
+	if c.BufferSizeMb == 0 {
+		c.BufferSizeMb = defaultBufferSizeMb
+	}
+	if c.LargeObjectThresholdMb == 0 {
+		c.LargeObjectThresholdMb = defaultLargeObjectThresholdMb
+	}
+}
+
+//BufferSize returns streaming buffer size in bytes
+func (c *Config) BufferSize() int {
+	if c.BufferSizeMb == 0 {
+		return defaultBufferSizeMb * megaBytes
+	}
+	return int(c.BufferSizeMb) * megaBytes
+}
+
+//LargeObjectThreshold returns large object threshold in bytes
+func (c *Config) LargeObjectThreshold() int64 {
+	if c.LargeObjectThresholdMb == 0 {
+		return defaultLargeObjectThresholdMb * megaBytes
+	}
+	return int64(c.LargeObjectThresholdMb) * megaBytes
 }
 
 //Validate checks if config is valid
@@ -2,7 +2,9 @@ package proxy
 
 import (
 	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/shared"
 	"sync"
+	"time"
 )
 
 type Response struct {
@@ -11,7 +13,14 @@ type Response struct {
 	Moved   map[string]string `json:",omitempty"`
 	Invoked map[string]string `json:",omitempty"`
 	Status  string            `json:",omitempty"`
+	ThrottleTimeMs int        `json:",omitempty"`
 	Error   string            `json:",omitempty"`
+	//ErrorCode error taxonomy code
+	ErrorCode string `json:",omitempty"`
+	//ErrorClass retryable or terminal
+	ErrorClass string `json:",omitempty"`
+	//DeleteBatches number of batched source deletes for bulk move
+	DeleteBatches int `json:",omitempty"`
 	mux     *sync.Mutex
 }
 
@@ -36,6 +45,59 @@ func (r *Response) AddInvoked(key, value string) {
 	r.Invoked[key] = value
 }
 
+//AddThrottleTime adds time spent waiting for provider rate limit
+func (r *Response) AddThrottleTime(duration time.Duration) {
+	if duration <= 0 {
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.ThrottleTimeMs += int(duration / time.Millisecond)
+}
+
+//Drain returns and resets transferred maps, so that streamed response does not grow with every transfer
+func (r *Response) Drain() (copied, moved, invoked map[string]string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	copied, moved, invoked = r.Copied, r.Moved, r.Invoked
+	r.Copied = make(map[string]string)
+	r.Moved = make(map[string]string)
+	r.Invoked = make(map[string]string)
+	return copied, moved, invoked
+}
+
+//setError sets response error
+func (r *Response) setError(err error) {
+	r.Status = base.StatusError
+	r.Error = shared.Redact(err.Error())
+	r.ErrorCode = base.ErrorCode(err)
+	r.ErrorClass = base.ErrorClass(r.ErrorCode)
+}
+
+//merge adds other response transferred, invoked and throttle time
+func (r *Response) merge(other *Response) {
+	copied, moved, invoked := other.Drain()
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	for k, v := range copied {
+		r.Copied[k] = v
+	}
+	for k, v := range moved {
+		r.Moved[k] = v
+	}
+	for k, v := range invoked {
+		r.Invoked[k] = v
+	}
+	r.ThrottleTimeMs += other.ThrottleTimeMs
+}
+
+//movedTo returns moved source dest URL
+func (r *Response) movedTo(sourceURL string) string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.Moved[sourceURL]
+}
+
 //NewResponse create a response
 func NewResponse() *Response {
 	return &Response{
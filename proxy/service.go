@@ -15,45 +15,52 @@ import (
 	"github.com/viant/afs/url"
 	"path"
 	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
 	"github.com/viant/smirror/event"
+	"github.com/viant/smirror/native"
 	"github.com/viant/smirror/secret"
+	"github.com/viant/smirror/throttle"
 	"time"
 )
 
-const partSize = 32 * 1024 * 1024
-
 //Service represents trigger service
 type Service interface {
 	Proxy(ctx context.Context, request *Request) *Response
+	//ProxyAll proxies requests with batch parallelism, move requests sources are deleted in batches once copied
+	ProxyAll(ctx context.Context, requests []*Request, batch *config.Batch) *Response
 }
 
 type service struct {
-	fs     afs.Service
-	secret secret.Service
-	config *Config
+	fs      afs.Service
+	secret  secret.Service
+	config  *Config
+	limiter *throttle.Limiter
 }
 
 //Trigger triggers lambda execution
 func (s *service) Proxy(ctx context.Context, request *Request) *Response {
 	response := NewResponse()
-	location := url.Path(request.Source.URL)
-	parent, name := path.Split(location)
-	canProxy :=  s.config.Source.Match(parent, file.NewInfo(name, 0, 0644, time.Now(), false))
-	if ! canProxy {
+	if !s.canProxy(request) {
 		response.Status = base.StatusNoMatch
 		return response
 	}
-	if err := s.proxy(ctx, request, response); err != nil {
-		response.Status = base.StatusError
-		response.Error = err.Error()
+	if err := s.proxy(ctx, request, response, false); err != nil {
+		response.setError(err)
 	}
 	return response
 }
 
-//Trigger triggers lambda execution
-func (s *service) proxy(ctx context.Context, request *Request, response *Response) error {
+//canProxy returns true if request source matches proxy source
+func (s *service) canProxy(request *Request) bool {
+	location := url.Path(request.Source.URL)
+	parent, name := path.Split(location)
+	return s.config.Source.Match(parent, file.NewInfo(name, 0, 0644, time.Now(), false))
+}
+
+//Trigger triggers lambda execution, with deferDelete move request source is only copied, caller deletes it
+func (s *service) proxy(ctx context.Context, request *Request, response *Response, deferDelete bool) error {
 	if err := request.Validate(); err != nil {
-		return err
+		return base.NewCodedError(base.ErrorCodeConfig, err)
 	}
 
 	scheme := url.Scheme(request.Dest.URL, "")
@@ -67,14 +74,14 @@ func (s *service) proxy(ctx context.Context, request *Request, response *Respons
 	var options = make([]storage.Option, 0)
 	sourceOptions, err := s.secret.StorageOpts(ctx, request.Source)
 	if err != nil {
-		return err
+		return base.NewCodedError(base.ErrorCodeAuth, err)
 	}
 	if len(sourceOptions) == 0 {
 		sourceOptions = make([]storage.Option, 0)
 	}
 	destOptions, err := s.secret.StorageOpts(ctx, request.Dest)
 	if err != nil {
-		return err
+		return base.NewCodedError(base.ErrorCodeAuth, err)
 	}
 	if len(destOptions) == 0 {
 		destOptions = make([]storage.Option, 0)
@@ -83,28 +90,42 @@ func (s *service) proxy(ctx context.Context, request *Request, response *Respons
 		if _, ok := sourceOptions[0].(*option.AES256Key); !ok || len(sourceOptions) > 1 {
 			destOptions = append(destOptions, option.NewAuth(true))
 		}
+	}
+	streamOption, err := s.streamOption(ctx, request, sourceOptions)
+	if err != nil {
+		return err
+	}
+	if streamOption != nil {
+		sourceOptions = append(sourceOptions, streamOption)
+		destOptions = append(destOptions, option.NewSkipChecksum(true))
+	}
+	if len(sourceOptions) > 0 {
 		options = append(options, option.NewSource(sourceOptions...))
 	}
 	if len(destOptions) > 0 {
 		options = append(options, option.NewDest(destOptions...))
 	}
-
-	if request.Stream {
-		object, err := s.fs.Object(ctx, request.Source.URL, sourceOptions...)
-		if err != nil {
-			return errors.Wrapf(err, "source not found: %v", request.Source.URL)
-		}
-		sourceOptions = append(sourceOptions, option.NewStream(partSize, int(object.Size())))
-		destOptions = append(destOptions, option.NewSkipChecksum(true))
-	}
 	sourceBucket := url.Host(request.Source.URL)
 	_, sourcePath := url.Base(request.Source.URL, "")
 	destURL := url.Join(request.Dest.URL, path.Join(sourceBucket, sourcePath))
-	transferred := response.Copied
+	transferred := response.AddCopied
 	if request.Move {
-		transferred = response.Moved
+		transferred = response.AddMoved
 	}
-	return s.propagate(ctx, request.Move, request.Source.URL, destURL, transferred, options...)
+	return s.propagate(ctx, request.Move && !deferDelete, request.Stream, request.Source.URL, destURL, transferred, response, options...)
+}
+
+//streamOption returns ranged read stream option if request asks for streaming or source is larger than large object threshold,
+//source size is only looked up when threshold decides, storage reader sets streamed object size on open
+func (s *service) streamOption(ctx context.Context, request *Request, sourceOptions []storage.Option) (*option.Stream, error) {
+	if request.Stream {
+		return option.NewStream(s.config.BufferSize(), 0), nil
+	}
+	object, err := s.fs.Object(ctx, request.Source.URL, sourceOptions...)
+	if err != nil || object.Size() <= s.config.LargeObjectThreshold() {
+		return nil, nil
+	}
+	return option.NewStream(s.config.BufferSize(), int(object.Size())), nil
 }
 
 func invokeLambda(ctx context.Context, request *Request, response *Response) error {
@@ -133,19 +154,25 @@ func (s *service) invokeCloudFunction(ctx context.Context, request *Request, res
 	return fmt.Errorf("calling cloud function is not yet supported")
 }
 
-//propagate propagate source event with copy or move operation both are stream
-func (s *service) propagate(ctx context.Context, isMove bool, sourceURL, destURL string, triggered map[string]string, options ...storage.Option) error {
+//propagate propagate source event with copy or move operation both are stream, missing source is reported as not found unless it must exist
+func (s *service) propagate(ctx context.Context, isMove, mustExist bool, sourceURL, destURL string, triggered func(key, value string), response *Response, options ...storage.Option) error {
 	triggerFunc := s.fs.Copy
 	if isMove {
 		triggerFunc = s.fs.Move
 	}
-	triggered[sourceURL] = destURL
+	triggered(sourceURL, destURL)
 
-	err := triggerFunc(ctx, sourceURL, destURL, options...)
+	waited, err := s.limiter.Do(ctx, destURL, func() error {
+		return triggerFunc(ctx, sourceURL, destURL, options...)
+	})
+	response.AddThrottleTime(waited)
 	if err != nil {
 		if exists, e := s.fs.Exists(ctx, sourceURL); e == nil && !exists {
+			if mustExist {
+				return errors.Wrapf(err, "source not found: %v", sourceURL)
+			}
 			err = nil
-			triggered[sourceURL] = base.StatusNoFound
+			triggered(sourceURL, base.StatusNoFound)
 		}
 	}
 	return err
@@ -153,5 +180,5 @@ func (s *service) propagate(ctx context.Context, isMove bool, sourceURL, destURL
 
 //New create trigger service
 func New(fs afs.Service, config *Config, secret secret.Service) Service {
-	return &service{fs: fs, secret: secret, config: config}
+	return &service{fs: native.New(fs), secret: secret, config: config, limiter: throttle.New(&config.RateLimit)}
 }
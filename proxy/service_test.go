@@ -0,0 +1,64 @@
+package proxy
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/storage"
+	"github.com/viant/smirror/config"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+//objectCounter counts source object lookups
+type objectCounter struct {
+	afs.Service
+	count int32
+}
+
+func (c *objectCounter) Object(ctx context.Context, URL string, options ...storage.Option) (storage.Object, error) {
+	atomic.AddInt32(&c.count, 1)
+	return c.Service.Object(ctx, URL, options...)
+}
+
+func TestService_StreamOption(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "file://" + t.TempDir()
+	smallURL, largeURL := baseURL+"/small.csv", baseURL+"/large.csv"
+	assert.Nil(t, fs.Upload(ctx, smallURL, 0644, strings.NewReader(strings.Repeat("x", megaBytes))))
+	assert.Nil(t, fs.Upload(ctx, largeURL, 0644, strings.NewReader(strings.Repeat("x", megaBytes+1))))
+
+	var useCases = []struct {
+		description  string
+		URL          string
+		stream       bool
+		expectStream bool
+		expectSize   int
+		expectLookup int32
+	}{
+		{description: "at threshold is buffered", URL: smallURL, expectLookup: 1},
+		{description: "above threshold is streamed", URL: largeURL, expectStream: true, expectSize: megaBytes + 1, expectLookup: 1},
+		{description: "requested stream skips lookup", URL: smallURL, stream: true, expectStream: true},
+		{description: "missing source is buffered", URL: baseURL + "/missing.csv", expectLookup: 1},
+	}
+	for _, useCase := range useCases {
+		counter := &objectCounter{Service: fs}
+		srv := &service{fs: counter, config: &Config{LargeObjectThresholdMb: 1}}
+		request := &Request{Source: &config.Resource{URL: useCase.URL}, Dest: &config.Resource{URL: baseURL + "/dest"}, Stream: useCase.stream}
+		stream, err := srv.streamOption(ctx, request, nil)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.EqualValues(t, useCase.expectLookup, counter.count, useCase.description)
+		if !useCase.expectStream {
+			assert.Nil(t, stream, useCase.description)
+			continue
+		}
+		if assert.NotNil(t, stream, useCase.description) {
+			assert.EqualValues(t, useCase.expectSize, stream.Size, useCase.description)
+			assert.EqualValues(t, defaultBufferSizeMb*megaBytes, stream.PartSize, useCase.description)
+		}
+	}
+}
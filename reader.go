@@ -1,17 +1,31 @@
 package smirror
 
 import (
+	"bufio"
 	"compress/gzip"
 	"io"
 	"github.com/viant/smirror/config"
 	"github.com/viant/smirror/config/schema"
+	"github.com/viant/smirror/config/sorting"
 	"github.com/viant/smirror/contract"
 )
 
 //NewReader returns a reader for a rule
 func NewReader(rule *config.Rule, reader io.Reader, response *contract.Response, sourceURL string) (io.Reader, error) {
 	compression := rule.SourceCompression(sourceURL)
+	sourceCodec := ""
+	if urlCompression := config.NewCompressionForURL(sourceURL); urlCompression != nil {
+		sourceCodec = urlCompression.Codec
+	}
 	var err error
+	if rule.Compression != nil && rule.Compression.Detect && rule.Compression.Uncompress {
+		reader, compression = detectCompression(reader, response)
+		sourceCodec = response.SourceCodec
+	}
+	response.GzipPassthrough = sourceCodec == config.GZipCodec && rule.GzipPassthrough()
+	if response.GzipPassthrough {
+		return newGzipVerifier(reader), nil
+	}
 	if compression != nil && compression.Codec == config.GZipCodec {
 		if reader, err = gzip.NewReader(reader); err != nil {
 			return reader, err
@@ -25,5 +39,28 @@ func NewReader(rule *config.Rule, reader io.Reader, response *contract.Response,
 			return nil, err
 		}
 	}
+	if rule.Sort != nil {
+		if reader, err = sorting.NewReader(reader, rule.Sort, response); err != nil {
+			return nil, err
+		}
+	}
 	return reader, err
 }
+
+//detectCompression detects source compression with content magic bytes
+func detectCompression(reader io.Reader, response *contract.Response) (io.Reader, *config.Compression) {
+	codec := detectSourceCodec(&reader)
+	response.SourceCodec = codec
+	if codec != config.GZipCodec {
+		return reader, nil
+	}
+	return reader, &config.Compression{Codec: codec, Uncompress: true}
+}
+
+//detectSourceCodec returns codec detected with content magic bytes, reader is replaced with buffered reader
+func detectSourceCodec(reader *io.Reader) string {
+	bufReader := bufio.NewReader(*reader)
+	*reader = bufReader
+	header, _ := bufReader.Peek(config.MagicSize())
+	return config.DetectCodec(header)
+}
@@ -0,0 +1,88 @@
+package smirror
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"io/ioutil"
+	"testing"
+)
+
+func TestNewReader_Detect(t *testing.T) {
+	payload := []byte("line1\nline2\n")
+	compressed := new(bytes.Buffer)
+	writer := gzip.NewWriter(compressed)
+	_, _ = writer.Write(payload)
+	_ = writer.Close()
+
+	var useCases = []struct {
+		description string
+		URL         string
+		content     []byte
+		codec       string
+	}{
+		{description: "gzip content with gz extension", URL: "gs://bucket/data/file1.csv.gz", content: compressed.Bytes(), codec: config.GZipCodec},
+		{description: "gzip content without extension", URL: "gs://bucket/data/file2.csv", content: compressed.Bytes(), codec: config.GZipCodec},
+		{description: "plain content with gz extension", URL: "gs://bucket/data/file3.csv.gz", content: payload},
+		{description: "plain content", URL: "gs://bucket/data/file4.csv", content: payload},
+	}
+	rule := &config.Rule{Compression: &config.Compression{Uncompress: true, Detect: true}}
+	for _, useCase := range useCases {
+		response := contract.NewResponse(useCase.URL)
+		reader, err := NewReader(rule, bytes.NewReader(useCase.content), response, useCase.URL)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		actual, err := ioutil.ReadAll(reader)
+		assert.Nil(t, err, useCase.description)
+		assert.Equal(t, payload, actual, useCase.description)
+		assert.Equal(t, useCase.codec, response.SourceCodec, useCase.description)
+	}
+}
+
+func TestNewReader_GzipPassthrough(t *testing.T) {
+	compressed := new(bytes.Buffer)
+	writer := gzip.NewWriter(compressed)
+	_, _ = writer.Write(bytes.Repeat([]byte("line1\nline2\n"), 20000))
+	_ = writer.Close()
+	valid := compressed.Bytes()
+	corrupted := append([]byte{}, valid...)
+	corrupted[len(corrupted)-5] ^= 0xFF //trailer CRC32
+	truncated := valid[:len(valid)/2]
+
+	var useCases = []struct {
+		description string
+		rule        *config.Rule
+		URL         string
+		content     []byte
+		passthrough bool
+		hasError    bool
+	}{
+		{description: "gzip to gzip", rule: &config.Rule{Compression: &config.Compression{Codec: config.GZipCodec}}, URL: "gs://bucket/data/file1.csv.gz", content: valid, passthrough: true},
+		{description: "detected gzip to gzip", rule: &config.Rule{Compression: &config.Compression{Codec: config.GZipCodec, Uncompress: true, Detect: true}}, URL: "gs://bucket/data/file2.csv", content: valid, passthrough: true},
+		{description: "corrupted trailer", rule: &config.Rule{Compression: &config.Compression{Codec: config.GZipCodec}}, URL: "gs://bucket/data/file3.csv.gz", content: corrupted, passthrough: true, hasError: true},
+		{description: "truncated", rule: &config.Rule{Compression: &config.Compression{Codec: config.GZipCodec}}, URL: "gs://bucket/data/file4.csv.gz", content: truncated, passthrough: true, hasError: true},
+		{description: "transformer", rule: &config.Rule{Compression: &config.Compression{Codec: config.GZipCodec, Uncompress: true}, Replace: []*config.Replace{{From: "line1", To: "line3"}}}, URL: "gs://bucket/data/file5.csv.gz", content: valid},
+	}
+	for _, useCase := range useCases {
+		response := contract.NewResponse(useCase.URL)
+		reader, err := NewReader(useCase.rule, bytes.NewReader(useCase.content), response, useCase.URL)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.Equal(t, useCase.passthrough, response.GzipPassthrough, useCase.description)
+		if !useCase.passthrough {
+			continue
+		}
+		actual, err := ioutil.ReadAll(reader)
+		if useCase.hasError {
+			assert.Equal(t, base.ErrorCodeChecksum, base.ErrorCode(err), useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+		assert.Equal(t, useCase.content, actual, useCase.description)
+	}
+}
@@ -0,0 +1,86 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/redis"
+	"io/ioutil"
+	"sync"
+)
+
+//redisClients clients keyed by server URL and credentials, to reuse connections
+var redisClients = sync.Map{}
+
+func redisClient(resource *config.Resource) (*redis.Client, error) {
+	key := resource.URL
+	var credentials *redis.Credentials
+	if resource.Credentials != nil {
+		if len(resource.Credentials.Auth) == 0 {
+			return nil, errors.New("redis credentials were not decrypted")
+		}
+		key += "/" + string(resource.Credentials.Auth)
+		var err error
+		if credentials, err = redis.NewCredentials(resource.Credentials.Auth); err != nil {
+			return nil, err
+		}
+	}
+	if client, ok := redisClients.Load(key); ok {
+		return client.(*redis.Client), nil
+	}
+	client, err := redis.New(resource.URL, credentials)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := redisClients.LoadOrStore(key, client)
+	return actual.(*redis.Client), nil
+}
+
+//addRedis adds transfer data to Redis Stream, one entry per file or per record for small files
+func (s *service) addRedis(ctx context.Context, transfer *Transfer, response *contract.Response) error {
+	client, err := redisClient(transfer.Resource)
+	if err != nil {
+		return base.NewCodedError(base.ErrorCodeAuth, err)
+	}
+	reader, err := transfer.GetReader()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get reader for: %v", transfer.Resource.URL)
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	settings := transfer.Resource.Redis
+	stream := settings.StreamKey(transfer.partition)
+	fields := []string{"source", response.TriggeredBy, "transferID", response.TransferID}
+	if response.CorrelationID != "" {
+		fields = append(fields, "correlationID", response.CorrelationID)
+	}
+	var entries [][]string
+	if settings.IsRecordMode(len(data)) {
+		for i, record := range bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n")) {
+			if len(record) == 0 {
+				continue
+			}
+			entries = append(entries, append(fields[:len(fields):len(fields)], "record", fmt.Sprintf("%d", i), "data", string(record)))
+		}
+	} else {
+		entries = append(entries, append(fields, "data", string(data)))
+	}
+	waited, err := s.limiter.Wait(ctx, transfer.Resource.URL)
+	response.AddThrottleTime(waited)
+	if err != nil {
+		return err
+	}
+	IDs, err := client.XAdd(ctx, stream, &redis.Trim{MaxLen: settings.MaxLen, Exact: settings.ExactTrim}, entries)
+	response.AddMessageIDs(IDs...)
+	if err != nil {
+		s.limiter.Report(transfer.Resource.URL, err)
+		return err
+	}
+	return nil
+}
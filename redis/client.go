@@ -0,0 +1,303 @@
+package redis
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	//Scheme redis scheme
+	Scheme = "redis"
+	//TLSScheme redis over TLS scheme
+	TLSScheme = "rediss"
+
+	defaultPort    = "6379"
+	defaultTimeout = 30 * time.Second
+	maxPipeline    = 1000
+)
+
+//Credentials represents decrypted Redis credentials, either raw password or JSON with Username and Password
+type Credentials struct {
+	Username string `json:",omitempty"`
+	Password string `json:",omitempty"`
+}
+
+//Trim represents stream trimming
+type Trim struct {
+	//MaxLen stream max length, 0 - no trimming
+	MaxLen int64
+	//Exact uses exact trimming, otherwise approximate (~) trimming is used
+	Exact bool
+}
+
+//Client represents minimal Redis client, commands are pipelined over a single connection
+type Client struct {
+	address     string
+	useTLS      bool
+	db          int
+	credentials *Credentials
+	mux         sync.Mutex
+	conn        net.Conn
+	reader      *bufio.Reader
+}
+
+//Error represents Redis error reply
+type Error string
+
+//Error returns error message
+func (e Error) Error() string {
+	return string(e)
+}
+
+//XAdd adds stream entries, each entry is a field value pair list, it returns entry IDs
+func (c *Client) XAdd(ctx context.Context, stream string, trim *Trim, entries [][]string) ([]string, error) {
+	var IDs = make([]string, 0, len(entries))
+	for len(entries) > 0 {
+		size := len(entries)
+		if size > maxPipeline {
+			size = maxPipeline
+		}
+		commands := make([][]string, 0, size)
+		for _, entry := range entries[:size] {
+			commands = append(commands, xAddCommand(stream, trim, entry))
+		}
+		replies, err := c.Do(ctx, commands...)
+		IDs = append(IDs, replies...)
+		if err != nil {
+			return IDs, errors.Wrapf(err, "failed to XADD %v", stream)
+		}
+		entries = entries[size:]
+	}
+	return IDs, nil
+}
+
+func xAddCommand(stream string, trim *Trim, entry []string) []string {
+	command := []string{"XADD", stream}
+	if trim != nil && trim.MaxLen > 0 {
+		operator := "~"
+		if trim.Exact {
+			operator = "="
+		}
+		command = append(command, "MAXLEN", operator, strconv.FormatInt(trim.MaxLen, 10))
+	}
+	command = append(command, "*")
+	return append(command, entry...)
+}
+
+//Do sends pipelined commands, it returns replies read before the first error
+func (c *Client) Do(ctx context.Context, commands ...[]string) ([]string, error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if err := c.connect(ctx); err != nil {
+		return nil, err
+	}
+	replies, err := c.do(ctx, commands)
+	if _, ok := err.(Error); !ok && err != nil {
+		c.close()
+	}
+	return replies, err
+}
+
+func (c *Client) do(ctx context.Context, commands [][]string) ([]string, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	buffer := new(bytes.Buffer)
+	for _, command := range commands {
+		encode(buffer, command)
+	}
+	if _, err := c.conn.Write(buffer.Bytes()); err != nil {
+		return nil, err
+	}
+	var replies = make([]string, 0, len(commands))
+	var replyErr error
+	for range commands {
+		reply, err := readReply(c.reader)
+		if err != nil {
+			if _, ok := err.(Error); !ok {
+				return replies, err
+			}
+			//keep reading to drain pipelined replies
+			if replyErr == nil {
+				replyErr = err
+			}
+			continue
+		}
+		if replyErr == nil {
+			replies = append(replies, reply)
+		}
+	}
+	return replies, replyErr
+}
+
+func (c *Client) connect(ctx context.Context) error {
+	if c.conn != nil {
+		return nil
+	}
+	dialer := &net.Dialer{Timeout: defaultTimeout}
+	var conn net.Conn
+	var err error
+	if c.useTLS {
+		host, _, _ := net.SplitHostPort(c.address)
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", c.address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", c.address)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to connect to %v", c.address)
+	}
+	c.conn = conn
+	c.reader = bufio.NewReader(conn)
+	var commands [][]string
+	if c.credentials != nil && c.credentials.Password != "" {
+		if c.credentials.Username != "" {
+			commands = append(commands, []string{"AUTH", c.credentials.Username, c.credentials.Password})
+		} else {
+			commands = append(commands, []string{"AUTH", c.credentials.Password})
+		}
+	}
+	if c.db > 0 {
+		commands = append(commands, []string{"SELECT", strconv.Itoa(c.db)})
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+	if _, err = c.do(ctx, commands); err != nil {
+		c.close()
+		return errors.Wrapf(err, "failed to initialise connection to %v", c.address)
+	}
+	return nil
+}
+
+func (c *Client) close() {
+	if c.conn != nil {
+		_ = c.conn.Close()
+	}
+	c.conn = nil
+	c.reader = nil
+}
+
+//Close closes connection
+func (c *Client) Close() error {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.close()
+	return nil
+}
+
+func encode(buffer *bytes.Buffer, command []string) {
+	buffer.WriteString("*" + strconv.Itoa(len(command)) + "\r\n")
+	for _, arg := range command {
+		buffer.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n")
+		buffer.WriteString(arg)
+		buffer.WriteString("\r\n")
+	}
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+//readReply reads simple, error, integer, bulk reply, array elements are skipped
+func readReply(reader *bufio.Reader) (string, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return "", err
+	}
+	if line == "" {
+		return "", errors.New("empty redis reply")
+	}
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", Error(line[1:])
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid bulk reply: %v", line)
+		}
+		if size < 0 {
+			return "", nil
+		}
+		data := make([]byte, size+2)
+		if _, err = io.ReadFull(reader, data); err != nil {
+			return "", err
+		}
+		return string(data[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid array reply: %v", line)
+		}
+		for i := 0; i < count; i++ {
+			if _, err = readReply(reader); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+	}
+	return "", fmt.Errorf("unsupported redis reply: %v", line)
+}
+
+//NewCredentials creates credentials from decrypted secret, raw password or JSON with Username and Password
+func NewCredentials(secret []byte) (*Credentials, error) {
+	secret = bytes.TrimSpace(secret)
+	if len(secret) == 0 {
+		return nil, errors.New("redis credentials were empty")
+	}
+	credentials := &Credentials{}
+	if secret[0] != '{' {
+		credentials.Password = string(secret)
+		return credentials, nil
+	}
+	if err := json.Unmarshal(secret, credentials); err != nil {
+		return nil, errors.Wrap(err, "failed to decode redis credentials")
+	}
+	return credentials, nil
+}
+
+//New creates a client for redis://[user:password@]host[:port][/db] or rediss:// URL, credentials take precedence over URL user info
+func New(URL string, credentials *Credentials) (*Client, error) {
+	parsed, err := url.Parse(URL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid redis URL: %v", URL)
+	}
+	if parsed.Scheme != Scheme && parsed.Scheme != TLSScheme {
+		return nil, errors.Errorf("unsupported redis URL scheme: %v", parsed.Scheme)
+	}
+	client := &Client{address: parsed.Host, useTLS: parsed.Scheme == TLSScheme, credentials: credentials}
+	if parsed.Port() == "" {
+		client.address = net.JoinHostPort(parsed.Hostname(), defaultPort)
+	}
+	if db := strings.Trim(parsed.Path, "/"); db != "" {
+		if client.db, err = strconv.Atoi(db); err != nil {
+			return nil, errors.Errorf("invalid redis database: %v", db)
+		}
+	}
+	if client.credentials == nil && parsed.User != nil {
+		password, _ := parsed.User.Password()
+		client.credentials = &Credentials{Username: parsed.User.Username(), Password: password}
+	}
+	return client, nil
+}
@@ -0,0 +1,101 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+//fakeServer records received commands, it replies with entry ID to XADD and OK otherwise
+type fakeServer struct {
+	listener net.Listener
+	mux      sync.Mutex
+	commands [][]string
+}
+
+func (s *fakeServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			return
+		}
+		count, _ := strconv.Atoi(line[1:])
+		command := make([]string, count)
+		for i := range command {
+			if command[i], err = readReply(reader); err != nil {
+				return
+			}
+		}
+		s.mux.Lock()
+		s.commands = append(s.commands, command)
+		reply := "+OK\r\n"
+		switch {
+		case command[0] == "XADD" && command[len(command)-1] == "fail":
+			reply = "-ERR failed\r\n"
+		case command[0] == "XADD":
+			ID := fmt.Sprintf("1-%d", len(s.commands))
+			reply = fmt.Sprintf("$%d\r\n%s\r\n", len(ID), ID)
+		}
+		s.mux.Unlock()
+		_, _ = conn.Write([]byte(reply))
+	}
+}
+
+func TestClient_XAdd(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+	server := &fakeServer{listener: listener}
+	go server.serve()
+	ctx := context.Background()
+
+	credentials, err := NewCredentials([]byte(`{"Username":"app","Password":"secret"}`))
+	assert.Nil(t, err)
+	client, err := New("redis://"+listener.Addr().String()+"/2", credentials)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer client.Close()
+	IDs, err := client.XAdd(ctx, "events", &Trim{MaxLen: 1000}, [][]string{{"data", "a"}, {"data", "b"}})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"1-3", "1-4"}, IDs)
+	assert.Equal(t, []string{"AUTH", "app", "secret"}, server.commands[0])
+	assert.Equal(t, []string{"SELECT", "2"}, server.commands[1])
+	assert.Equal(t, "XADD events MAXLEN ~ 1000 * data a", strings.Join(server.commands[2], " "))
+
+	IDs, err = client.XAdd(ctx, "events", &Trim{MaxLen: 10, Exact: true}, [][]string{{"data", "c"}, {"data", "fail"}, {"data", "d"}})
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{"1-5"}, IDs)
+	assert.Equal(t, "XADD events MAXLEN = 10 * data c", strings.Join(server.commands[4], " "))
+
+	IDs, err = client.XAdd(ctx, "events", nil, [][]string{{"data", "e"}})
+	assert.Nil(t, err, "connection should be reused after error reply")
+	assert.Equal(t, []string{"1-8"}, IDs)
+	assert.Equal(t, 8, len(server.commands))
+
+	_, err = New("http://localhost", nil)
+	assert.NotNil(t, err)
+	credentials, err = NewCredentials([]byte("password"))
+	assert.Nil(t, err)
+	assert.Equal(t, "password", credentials.Password)
+}
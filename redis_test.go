@@ -0,0 +1,85 @@
+package smirror
+
+import (
+	"bufio"
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestService_Redis(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+	var commands []string
+	mux := sync.Mutex{}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		var args []string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			//skip array and bulk headers, note auto ID argument (*) is skipped too
+			switch line[0] {
+			case '*', '$':
+				continue
+			}
+			args = append(args, line)
+			if args[0] != "XADD" || len(args) < 4 || args[len(args)-2] != "data" {
+				continue
+			}
+			mux.Lock()
+			commands = append(commands, strings.Join(args, " "))
+			mux.Unlock()
+			args = nil
+			_, _ = conn.Write([]byte("$3\r\n1-1\r\n"))
+		}
+	}()
+	ctx := context.Background()
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/redis/data"}},
+				Dest: &config.Resource{
+					URL:   "redis://" + listener.Addr().String(),
+					Redis: &config.Redis{Stream: "events", MaxLen: 100, RecordMaxBytes: 1024},
+				},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sourceURL := "mem://localhost/redis/data/a.csv"
+	_ = afs.New().Upload(ctx, sourceURL, 0644, strings.NewReader("1,2\n3,4\n"))
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	if !assert.Equal(t, base.StatusOK, response.Status, response.Error) {
+		return
+	}
+	assert.Equal(t, 2, len(response.MessageIDs))
+	mux.Lock()
+	defer mux.Unlock()
+	if assert.Equal(t, 2, len(commands)) {
+		assert.True(t, strings.HasPrefix(commands[0], "XADD events MAXLEN ~ 100 source mem://localhost/redis/data/a.csv transferID "))
+		assert.True(t, strings.HasSuffix(commands[1], " record 1 data 3,4"))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/pkg/errors"
+	"github.com/viant/smirror/auth"
 	"github.com/viant/smirror/replay"
 	"log"
 	"net/http"
@@ -12,6 +13,12 @@ import (
 
 //StorageReplay cloud function entry point
 func StorageReplay(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, auth.EndpointReplay) {
+		return
+	}
+	if !validRequest(w, r, pathReplay) {
+		return
+	}
 	if r.ContentLength > 0 {
 		defer func() {
 			_ = r.Body.Close()
@@ -15,6 +15,8 @@ const (
 type Request struct {
 	TriggerURL                string
 	UnprocessedDuration       string
+	//ResponseURL response log URL (or its date partition), source objects of failed responses are replayed
+	ResponseURL               string
 	unprocessedModifiedBefore *time.Time
 }
 
@@ -39,8 +41,8 @@ func (r *Request) Init() (err error) {
 }
 
 func (r *Request) Validate() error {
-	if r.TriggerURL == "" {
-		return errors.New("triggerURL was empty")
+	if r.TriggerURL == "" && r.ResponseURL == "" {
+		return errors.New("triggerURL and responseURL were empty")
 	}
 	return nil
 }
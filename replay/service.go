@@ -2,16 +2,25 @@ package replay
 
 import (
 	"context"
+	"encoding/json"
 	"github.com/viant/smirror/base"
 	"github.com/viant/afs"
+	"github.com/viant/afs/file"
 	"github.com/viant/afs/matcher"
 	"github.com/viant/afs/option"
 	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"path"
 	"time"
 )
 
 const replayExtension = ".replay"
 
+//failedResponse represents persisted mirror response
+type failedResponse struct {
+	TriggeredBy string
+}
+
 //Service represents replay service
 type Service interface {
 	Replay(context.Context, *Request) *Response
@@ -42,6 +51,9 @@ func (s *service) replay(ctx context.Context, request *Request, response *Respon
 	if err != nil {
 		return err
 	}
+	if request.ResponseURL != "" {
+		return s.replayFailed(ctx, request.ResponseURL, response)
+	}
 	objects, err := s.list(ctx, request.TriggerURL, request.unprocessedModifiedBefore)
 	replayer := base.NewReplayer(s.fs)
 	replayer.Run(ctx, 10)
@@ -54,6 +66,38 @@ func (s *service) replay(ctx context.Context, request *Request, response *Respon
 	return replayer.Wait()
 }
 
+//replayFailed replays source objects of failed responses stored with response log
+func (s *service) replayFailed(ctx context.Context, responseURL string, response *Response) error {
+	objects, err := s.list(ctx, responseURL, nil)
+	if err != nil {
+		return err
+	}
+	replayer := base.NewReplayer(s.fs)
+	replayer.Run(ctx, 10)
+	replayed := map[string]bool{}
+	for _, object := range objects {
+		parentURL, _ := url.Split(object.URL(), file.Scheme)
+		if object.IsDir() || path.Ext(object.Name()) != base.JSONExt || path.Base(parentURL) != base.StatusError {
+			continue
+		}
+		data, err := s.fs.Download(ctx, object)
+		if err != nil {
+			return err
+		}
+		failed := &failedResponse{}
+		if err = json.Unmarshal(data, failed); err != nil || failed.TriggeredBy == "" || replayed[failed.TriggeredBy] {
+			continue
+		}
+		replayed[failed.TriggeredBy] = true
+		if exists, _ := s.fs.Exists(ctx, failed.TriggeredBy); !exists {
+			continue
+		}
+		response.Replayed = append(response.Replayed, failed.TriggeredBy)
+		replayer.Schedule(failed.TriggeredBy)
+	}
+	return replayer.Wait()
+}
+
 func (s *service) list(ctx context.Context, URL string, modifiedBefore *time.Time) ([]storage.Object, error) {
 	timeMatcher := matcher.NewModification(modifiedBefore, nil)
 	recursive := option.NewRecursive(true)
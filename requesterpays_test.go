@@ -0,0 +1,86 @@
+package smirror
+
+import (
+	"context"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/native"
+	gstorage "google.golang.org/api/storage/v1"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNativeRead(t *testing.T) {
+	var useCases = []struct {
+		description   string
+		URL           string
+		requesterPays *config.RequesterPays
+		generation    int64
+		expectHeader  map[string]string
+		expectQuery   map[string]string
+		expectError   bool
+	}{
+		{
+			description:   "gs requester pays",
+			URL:           "gs://bucket/data/file.csv",
+			requesterPays: config.NewRequesterPays(true, "billing-project"),
+			generation:    7,
+			expectQuery:   map[string]string{"userProject": "billing-project", "generation": "7"},
+		},
+		{
+			description:   "gs requester pays without project",
+			URL:           "gs://bucket/data/file.csv",
+			requesterPays: config.NewRequesterPays(true, ""),
+			expectError:   true,
+		},
+		{
+			description:   "s3 requester pays",
+			URL:           "s3://bucket/data/file.csv",
+			requesterPays: config.NewRequesterPays(true, ""),
+			expectHeader:  map[string]string{"X-Amz-Request-Payer": "requester"},
+		},
+	}
+
+	ctx := context.Background()
+	for _, useCase := range useCases {
+		recorder := &requestRecorder{}
+		server := httptest.NewServer(recorder)
+		options := fakeStorageOptions(server, useCase.requesterPays)
+		object, err := native.Object(ctx, useCase.URL, options)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			server.Close()
+			continue
+		}
+		if !assert.Nil(t, err, useCase.description) {
+			server.Close()
+			continue
+		}
+		assert.EqualValues(t, 7, object.Size(), useCase.description)
+		assert.NotEmpty(t, objectChecksum(object), useCase.description)
+		switch object.Sys().(type) {
+		case *gstorage.Object, *s3.Object:
+		default:
+			assert.Fail(t, "unexpected object type", useCase.description)
+		}
+
+		reader, err := native.Open(ctx, useCase.URL, useCase.generation, options)
+		if !assert.Nil(t, err, useCase.description) {
+			server.Close()
+			continue
+		}
+		data, err := ioutil.ReadAll(reader)
+		_ = reader.Close()
+		server.Close()
+		assert.Nil(t, err, useCase.description)
+		assert.EqualValues(t, "id,name", string(data), useCase.description)
+		for key, expect := range useCase.expectHeader {
+			assert.EqualValues(t, expect, recorder.header.Get(key), useCase.description+" "+key)
+		}
+		for key, expect := range useCase.expectQuery {
+			assert.EqualValues(t, expect, recorder.query.Get(key), useCase.description+" "+key)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestService_ResponseLog(t *testing.T) {
+	ctx := context.Background()
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Info:   base.Info{Workflow: "events"},
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/responselog/data"}},
+				Dest:   &config.Resource{URL: "mem://localhost/responselog/dest"},
+			},
+		}},
+	}
+	cfg.ResponseLog = &base.ResponseLog{URL: "mem://localhost/responselog/responses"}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	fs := afs.New()
+	sourceURL := "mem://localhost/responselog/data/file.csv"
+	_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("line1\n"))
+
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+	logURL := cfg.ResponseLog.ObjectURL(time.Now(), "events", base.StatusOK, response.TransferID)
+	assert.True(t, strings.HasSuffix(logURL, "/events/ok/"+response.TransferID+".json"), logURL)
+	exists, _ := fs.Exists(ctx, logURL)
+	assert.True(t, exists)
+
+	response = service.Mirror(ctx, contract.NewRequest("mem://localhost/responselog/other/file.csv"))
+	exists, _ = fs.Exists(ctx, cfg.ResponseLog.ObjectURL(time.Now(), "", response.Status, response.TransferID))
+	assert.True(t, exists)
+}
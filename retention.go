@@ -0,0 +1,24 @@
+package smirror
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/storage"
+	"github.com/viant/smirror/base"
+)
+
+//checkRetention refuses to overwrite an existing object in WORM (retention or legal hold) destination
+func (s *service) checkRetention(ctx context.Context, transfer *Transfer, options []storage.Option) error {
+	retention := transfer.Resource.Retention
+	if retention == nil {
+		return nil
+	}
+	exists, err := s.fs.Exists(ctx, transfer.Dest.URL, options...)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check retained dest: %v", transfer.Dest.URL)
+	}
+	if exists {
+		return base.NewCodedError(base.ErrorCodeImmutable, errors.Errorf("refusing to overwrite %v, dest objects are immutable (%v)", transfer.Dest.URL, retention))
+	}
+	return nil
+}
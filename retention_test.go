@@ -0,0 +1,56 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"strings"
+	"testing"
+)
+
+func TestService_CheckRetention(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	existingURL := "mem://localhost/worm/dest/existing.csv"
+	_ = fs.Upload(ctx, existingURL, 0644, strings.NewReader("a,b\n"))
+
+	var useCases = []struct {
+		description string
+		retention   *config.Retention
+		URL         string
+		expectCode  string
+	}{
+		{
+			description: "new object",
+			retention:   &config.Retention{Mode: config.RetentionCompliance, Days: 30},
+			URL:         "mem://localhost/worm/dest/new.csv",
+		},
+		{
+			description: "existing object",
+			retention:   &config.Retention{Mode: config.RetentionCompliance, Days: 30, LegalHold: true},
+			URL:         existingURL,
+			expectCode:  base.ErrorCodeImmutable,
+		},
+		{
+			description: "no retention",
+			URL:         existingURL,
+		},
+	}
+
+	srv := &service{fs: fs}
+	for _, useCase := range useCases {
+		transfer := &Transfer{
+			Resource: &config.Resource{URL: "mem://localhost/worm/dest", Retention: useCase.retention},
+			Dest:     &Datafile{URL: useCase.URL},
+		}
+		err := srv.checkRetention(ctx, transfer, nil)
+		if useCase.expectCode == "" {
+			assert.Nil(t, err, useCase.description)
+			continue
+		}
+		assert.Equal(t, useCase.expectCode, base.ErrorCode(err), useCase.description)
+		assert.Equal(t, base.ErrorClassTerminal, base.ErrorClass(base.ErrorCode(err)), useCase.description)
+	}
+}
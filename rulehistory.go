@@ -0,0 +1,71 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"log"
+	"net/http"
+)
+
+//StorageMirrorRuleHistory cloud function entry point, evaluates source URL against rules active at a given time and now
+func StorageMirrorRuleHistory(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, auth.EndpointHistory) {
+		return
+	}
+	if !validRequest(w, r, pathRuleHistory) {
+		return
+	}
+	err := ruleHistory(w, r)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func ruleHistory(writer http.ResponseWriter, httpRequest *http.Request) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	defer func() {
+		_ = httpRequest.Body.Close()
+	}()
+	request := &contract.RuleHistoryRequest{}
+	if err = json.NewDecoder(httpRequest.Body).Decode(&request); err != nil {
+		return errors.Wrapf(err, "failed to decode %T", request)
+	}
+	ctx := context.Background()
+	service, err := NewFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		return err
+	}
+	response := service.RuleHistory(ctx, request)
+	writer.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(writer).Encode(response)
+}
+
+//RuleHistory evaluates source URL against rules restored from rule files history as of request time and current rules
+func (s *service) RuleHistory(ctx context.Context, request *contract.RuleHistoryRequest) *contract.RuleHistoryResponse {
+	response := contract.NewRuleHistoryResponse(request.URL, request.At)
+	if _, err := s.config.Mirrors.ReloadIfNeeded(ctx, s.cfs); err != nil {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+		return response
+	}
+	then, err := s.config.Mirrors.AsOf(ctx, s.cfs, request.At)
+	if err != nil {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+		return response
+	}
+	response.Then = contract.NewRuleRouting(then, request.URL)
+	response.Now = contract.NewRuleRouting(&s.config.Mirrors, request.URL)
+	response.Init()
+	return response
+}
@@ -0,0 +1,57 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestService_RuleHistory(t *testing.T) {
+	ctx := context.Background()
+	fs := afs.New()
+	baseURL := "mem://localhost/rulehistory/rules"
+	_ = fs.Delete(ctx, "mem://localhost/rulehistory")
+	upload := func(content string) {
+		assert.Nil(t, fs.Upload(ctx, baseURL+"/rule.json", 0644, strings.NewReader(content)))
+	}
+	upload(`{"Source":{"Prefix":"/data/a"},"Dest":{"URL":"mem://localhost/dest/a"}}`)
+	cfg := &Config{Mirrors: config.Ruleset{BaseURL: baseURL, History: &config.RuleHistory{URL: "mem://localhost/rulehistory/history"}}}
+	if !assert.Nil(t, cfg.Mirrors.Load(ctx, fs)) {
+		return
+	}
+	srv := &service{config: cfg, cfs: fs}
+	before := time.Now()
+	time.Sleep(5 * time.Millisecond)
+	upload(`{"Source":{"Prefix":"/data/b"},"Dest":{"URL":"mem://localhost/dest/b"}}`)
+	assert.Nil(t, cfg.Mirrors.Reload(ctx, fs))
+
+	var useCases = []struct {
+		description   string
+		URL           string
+		at            time.Time
+		expectThen    int
+		expectNow     int
+		expectChanged bool
+	}{
+		{description: "routed then", URL: "mem://localhost/data/a/1.csv", at: before, expectThen: 1, expectChanged: true},
+		{description: "routed now", URL: "mem://localhost/data/b/1.csv", at: before, expectNow: 1, expectChanged: true},
+		{description: "unchanged", URL: "mem://localhost/data/b/1.csv", at: time.Now(), expectThen: 1, expectNow: 1},
+		{description: "not routed", URL: "mem://localhost/data/c/1.csv", at: before},
+	}
+	for _, useCase := range useCases {
+		response := srv.RuleHistory(ctx, &contract.RuleHistoryRequest{URL: useCase.URL, At: useCase.at})
+		if !assert.EqualValues(t, base.StatusOK, response.Status, useCase.description) {
+			continue
+		}
+		assert.EqualValues(t, useCase.expectThen, len(response.Then.Routes), useCase.description)
+		assert.EqualValues(t, useCase.expectNow, len(response.Now.Routes), useCase.description)
+		assert.EqualValues(t, useCase.expectChanged, response.Changed, useCase.description)
+		assert.EqualValues(t, 1, len(response.Then.Evaluations), useCase.description)
+	}
+}
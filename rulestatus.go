@@ -0,0 +1,64 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"log"
+	"net/http"
+)
+
+//StorageMirrorRuleStatus cloud function entry point, returns rule files load status
+func StorageMirrorRuleStatus(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, auth.EndpointRules) {
+		return
+	}
+	if !validRequest(w, r, pathRuleStatus) {
+		return
+	}
+	err := ruleStatus(w, r)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func ruleStatus(writer http.ResponseWriter, httpRequest *http.Request) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	request := &contract.RuleStatusRequest{Tenant: httpRequest.URL.Query().Get("tenant")}
+	if httpRequest.ContentLength > 0 {
+		defer func() {
+			_ = httpRequest.Body.Close()
+		}()
+		if err = json.NewDecoder(httpRequest.Body).Decode(&request); err != nil {
+			return errors.Wrapf(err, "failed to decode %T", request)
+		}
+	}
+	ctx := context.Background()
+	service, err := NewFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		return err
+	}
+	response := service.RuleStatus(ctx, request)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+//RuleStatus returns rule files load status, broken files keep previous good version active
+func (s *service) RuleStatus(ctx context.Context, request *contract.RuleStatusRequest) *contract.RuleStatusResponse {
+	response := contract.NewRuleStatusResponse()
+	if _, err := s.config.Mirrors.ReloadIfNeeded(ctx, s.cfs); err != nil {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+	}
+	response.Files = s.config.Mirrors.Status()
+	response.Init()
+	return response
+}
@@ -0,0 +1,106 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	neturl "net/url"
+	"strings"
+)
+
+//s3Assembler reassembles dest object with multipart upload, unchanged ranges are copied with upload part copy
+type s3Assembler struct {
+	client   *s3.S3
+	bucket   string
+	key      string
+	etag     string
+	uploadID *string
+	parts    []*s3.CompletedPart
+}
+
+//Copy copies existing dest object range as the next part, copy fails if dest object was modified in the meanwhile
+func (a *s3Assembler) Copy(ctx context.Context, offset, size int64) error {
+	input := &s3.UploadPartCopyInput{
+		Bucket:          &a.bucket,
+		Key:             &a.key,
+		UploadId:        a.uploadID,
+		PartNumber:      aws.Int64(int64(len(a.parts) + 1)),
+		CopySource:      aws.String((&neturl.URL{Path: a.bucket + "/" + a.key}).EscapedPath()),
+		CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+size-1)),
+	}
+	if a.etag != "" {
+		input.CopySourceIfMatch = aws.String(`"` + a.etag + `"`)
+	}
+	output, err := a.client.UploadPartCopyWithContext(ctx, input)
+	if err != nil {
+		return err
+	}
+	a.parts = append(a.parts, &s3.CompletedPart{PartNumber: input.PartNumber, ETag: output.CopyPartResult.ETag})
+	return nil
+}
+
+//Upload uploads changed block as the next part
+func (a *s3Assembler) Upload(ctx context.Context, data []byte) error {
+	partNumber := aws.Int64(int64(len(a.parts) + 1))
+	output, err := a.client.UploadPartWithContext(ctx, &s3.UploadPartInput{
+		Bucket:        &a.bucket,
+		Key:           &a.key,
+		UploadId:      a.uploadID,
+		PartNumber:    partNumber,
+		Body:          bytes.NewReader(data),
+		ContentLength: aws.Int64(int64(len(data))),
+	})
+	if err != nil {
+		return err
+	}
+	a.parts = append(a.parts, &s3.CompletedPart{PartNumber: partNumber, ETag: output.ETag})
+	return nil
+}
+
+//Complete completes multipart upload, which replaces dest object
+func (a *s3Assembler) Complete(ctx context.Context) error {
+	_, err := a.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          &a.bucket,
+		Key:             &a.key,
+		UploadId:        a.uploadID,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: a.parts},
+	})
+	return err
+}
+
+//Abort aborts multipart upload
+func (a *s3Assembler) Abort(ctx context.Context) {
+	_, _ = a.client.AbortMultipartUploadWithContext(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   &a.bucket,
+		Key:      &a.key,
+		UploadId: a.uploadID,
+	})
+}
+
+//newS3Assembler starts dest object multipart upload
+func newS3Assembler(ctx context.Context, URL string, dest storage.Object) (deltaAssembler, error) {
+	sess, err := session.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	result := &s3Assembler{
+		client: s3.New(sess),
+		bucket: url.Host(URL),
+		key:    strings.TrimLeft(url.Path(URL), "/"),
+		etag:   objectChecksum(dest),
+	}
+	output, err := result.client.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: &result.bucket,
+		Key:    &result.key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.uploadID = output.UploadId
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package secret
+
+import (
+	"cloud.google.com/go/compute/metadata"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afsc/gs"
+	"github.com/viant/smirror/config"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	defaultWebIdentityAudience = "sts.amazonaws.com"
+	defaultRoleSessionName     = "smirror"
+	//federationExpiryWindow federated AWS credentials are refreshed ahead of expiry, so long transfers do not fail mid-way
+	federationExpiryWindow = 5 * time.Minute
+)
+
+//federatedCredentials federated credentials keyed by federation settings
+var federatedCredentials = sync.Map{}
+
+//awsSTSConfig AWS STS client config used to assume role with web identity
+var awsSTSConfig = &aws.Config{}
+
+//googleSTSURL Google STS token exchange URL
+var googleSTSURL = "https://sts.googleapis.com/v1/token"
+
+//identityToken fetches runtime Google ID token from metadata server for AssumeRoleWithWebIdentity
+type identityToken string
+
+//FetchToken returns Google ID token with identityToken audience
+func (a identityToken) FetchToken(ctx credentials.Context) ([]byte, error) {
+	token, err := metadata.Get("instance/service-accounts/default/identity?format=full&audience=" + url.QueryEscape(string(a)))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch google identity token")
+	}
+	return []byte(token), nil
+}
+
+//federatedOption returns gs client options or s3 aws config authorized with federated credentials
+func federatedOption(federation *config.Federation, region string) (storage.Option, error) {
+	if federation.RoleARN != "" {
+		return awsFederatedConfig(federation, region)
+	}
+	return gcpFederatedOptions(federation)
+}
+
+//awsFederatedConfig returns aws config with role credentials assumed with Google ID token, credentials are refreshed before expiry
+func awsFederatedConfig(federation *config.Federation, region string) (*aws.Config, error) {
+	audience := federation.Audience
+	if audience == "" {
+		audience = defaultWebIdentityAudience
+	}
+	sessionName := federation.SessionName
+	if sessionName == "" {
+		sessionName = defaultRoleSessionName
+	}
+	key := federation.RoleARN + "/" + sessionName + "/" + audience
+	cached, ok := federatedCredentials.Load(key)
+	if !ok {
+		sess, err := session.NewSession(awsSTSConfig)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create sts session")
+		}
+		provider := stscreds.NewWebIdentityRoleProviderWithOptions(sts.New(sess), federation.RoleARN, sessionName, identityToken(audience), func(provider *stscreds.WebIdentityRoleProvider) {
+			provider.ExpiryWindow = federationExpiryWindow
+		})
+		cached, _ = federatedCredentials.LoadOrStore(key, credentials.NewCredentials(provider))
+	}
+	result := aws.NewConfig().WithCredentials(cached.(*credentials.Credentials))
+	if region != "" {
+		result = result.WithRegion(region)
+	}
+	return result, nil
+}
+
+//gcpFederatedOptions returns gs client options with external account token exchanged for runtime AWS identity, token is refreshed before expiry
+func gcpFederatedOptions(federation *config.Federation) (gs.ClientOptions, error) {
+	key := federation.Audience + "/" + federation.ServiceAccount
+	cached, ok := federatedCredentials.Load(key)
+	if !ok {
+		JSON, err := externalAccountJSON(federation)
+		if err != nil {
+			return nil, err
+		}
+		//cached credentials outlive request, token refresh uses background context
+		creds, err := google.CredentialsFromJSON(context.Background(), JSON, cloudPlatformScope)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create federated credentials: %v", federation.Audience)
+		}
+		cached, _ = federatedCredentials.LoadOrStore(key, creds)
+	}
+	return gs.NewClientOptions(option.WithTokenSource(cached.(*google.Credentials).TokenSource)), nil
+}
+
+//externalAccountJSON returns external account credentials config using AWS environment or EC2 metadata credentials
+func externalAccountJSON(federation *config.Federation) ([]byte, error) {
+	externalAccount := map[string]interface{}{
+		"type":               "external_account",
+		"audience":           federation.Audience,
+		"subject_token_type": "urn:ietf:params:aws:token-type:aws4_request",
+		"token_url":          googleSTSURL,
+		"credential_source": map[string]string{
+			"environment_id":                 "aws1",
+			"region_url":                     "http://169.254.169.254/latest/meta-data/placement/availability-zone",
+			"url":                            "http://169.254.169.254/latest/meta-data/iam/security-credentials",
+			"regional_cred_verification_url": "https://sts.{region}.amazonaws.com?Action=GetCallerIdentity&Version=2011-06-15",
+		},
+	}
+	if federation.ServiceAccount != "" {
+		externalAccount["service_account_impersonation_url"] = fmt.Sprintf("https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/%v:generateAccessToken", federation.ServiceAccount)
+	}
+	return json.Marshal(externalAccount)
+}
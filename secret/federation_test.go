@@ -0,0 +1,73 @@
+package secret
+
+import (
+	"encoding/json"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/smirror/config"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFederatedOption(t *testing.T) {
+	var webIdentityToken string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if strings.HasPrefix(request.URL.Path, "/computeMetadata/") {
+			assert.Equal(t, "sts.amazonaws.com", request.URL.Query().Get("audience"))
+			_, _ = writer.Write([]byte("google-id-token"))
+			return
+		}
+		_ = request.ParseForm()
+		webIdentityToken = request.Form.Get("WebIdentityToken")
+		_, _ = writer.Write([]byte(`<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>AKIDFEDERATED</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>session</SessionToken>
+      <Expiration>2099-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`))
+	}))
+	defer server.Close()
+	_ = os.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(server.URL, "http://"))
+	defer os.Unsetenv("GCE_METADATA_HOST")
+	awsSTSConfig = &aws.Config{Endpoint: aws.String(server.URL), Region: aws.String("us-east-1")}
+	defer func() { awsSTSConfig = &aws.Config{} }()
+
+	option, err := federatedOption(&config.Federation{RoleARN: "arn:aws:iam::123456789012:role/mirror"}, "us-west-2")
+	if !assert.Nil(t, err) {
+		return
+	}
+	awsConfig, ok := option.(*aws.Config)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, "us-west-2", *awsConfig.Region)
+	value, err := awsConfig.Credentials.Get()
+	if !assert.Nil(t, err) {
+		return
+	}
+	assert.Equal(t, "AKIDFEDERATED", value.AccessKeyID)
+	assert.Equal(t, "google-id-token", webIdentityToken)
+
+	federation := &config.Federation{
+		Audience:       "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/aws/providers/mirror",
+		ServiceAccount: "mirror@myproject.iam.gserviceaccount.com",
+	}
+	JSON, err := externalAccountJSON(federation)
+	if !assert.Nil(t, err) {
+		return
+	}
+	externalAccount := map[string]interface{}{}
+	_ = json.Unmarshal(JSON, &externalAccount)
+	assert.Equal(t, "external_account", externalAccount["type"])
+	assert.Equal(t, federation.Audience, externalAccount["audience"])
+	assert.Equal(t, "https://iamcredentials.googleapis.com/v1/projects/-/serviceAccounts/mirror@myproject.iam.gserviceaccount.com:generateAccessToken", externalAccount["service_account_impersonation_url"])
+	_, err = federatedOption(federation, "")
+	assert.Nil(t, err)
+}
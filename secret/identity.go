@@ -0,0 +1,69 @@
+package secret
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"github.com/viant/afs/storage"
+	"github.com/viant/smirror/config"
+	"strings"
+	"sync"
+)
+
+//Identity storage option with scheme and credential identity, client cache uses it to reuse storage clients across invocations
+type Identity string
+
+//authOptions built auth options keyed by identity, jwt/aws configs are parsed once per warm instance
+var authOptions = sync.Map{}
+
+//NewIdentity returns resource credential identity or empty identity for default credentials
+func NewIdentity(scheme string, resource *config.Resource) Identity {
+	var parts []string
+	if resource.Credentials != nil && len(resource.Credentials.Auth) > 0 {
+		parts = append(parts, "auth:"+digest(resource.Credentials.Auth))
+	}
+	if federation := resource.Federation; federation != nil {
+		parts = append(parts, "federation:"+digest([]byte(federation.Audience+"|"+federation.ServiceAccount+"|"+federation.RoleARN+"|"+federation.SessionName)))
+	}
+	if resource.ImpersonateServiceAccount != "" {
+		parts = append(parts, "impersonate:"+resource.ImpersonateServiceAccount)
+	}
+	if oauth := resource.OAuth2; oauth != nil && len(parts) > 0 {
+		parts = append(parts, "oauth2:"+digest([]byte(oauth.TokenURL+"|"+strings.Join(oauth.Scopes, " ")+"|"+oauth.Audience+"|"+oauth.AuthStyle)))
+	}
+	if settings := resource.TLS; settings != nil {
+		parts = append(parts, "tls:"+digest([]byte(fmt.Sprintf("%v|%v|%v|%s", settings.CAURL, settings.MinVersion, settings.InsecureSkipVerify, settings.ClientCertificate))))
+	}
+	if proxy := resource.Proxy; proxy != nil {
+		parts = append(parts, "proxy:"+digest([]byte(fmt.Sprintf("%v|%v|%v|%v|%s", proxy.URL, proxy.TimeoutMs, proxy.Fallback, strings.Join(proxy.NoProxy, ","), proxy.Auth))))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	if resource.Region != "" {
+		parts = append(parts, "region:"+resource.Region)
+	}
+	return Identity(scheme + "://" + strings.Join(parts, "/"))
+}
+
+//cachedAuthOptions returns auth options for identity, build is called only when identity was not seen before
+func cachedAuthOptions(identity Identity, build func() ([]storage.Option, error)) ([]storage.Option, error) {
+	if identity == "" {
+		return build()
+	}
+	if options, ok := authOptions.Load(identity); ok {
+		return options.([]storage.Option), nil
+	}
+	options, err := build()
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := authOptions.LoadOrStore(identity, options)
+	return actual.([]storage.Option), nil
+}
+
+//digest returns credentials digest, raw secrets are never part of identity
+func digest(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,30 @@
+package secret
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/config"
+	"strings"
+	"testing"
+)
+
+func TestNewIdentity(t *testing.T) {
+	var useCases = []struct {
+		description string
+		resource    *config.Resource
+		expect      string
+	}{
+		{description: "default credentials", resource: &config.Resource{URL: "gs://bucket/data"}, expect: ""},
+		{description: "impersonation", resource: &config.Resource{ImpersonateServiceAccount: "sa@p.iam.gserviceaccount.com"}, expect: "gs://impersonate:sa@p.iam.gserviceaccount.com"},
+		{description: "credentials with region", resource: &config.Resource{Region: "us-east-1", Credentials: &auth.Credentials{Auth: []byte(`{"key":"secret"}`)}}, expect: "gs://auth:"},
+	}
+	for _, useCase := range useCases {
+		actual := string(NewIdentity("gs", useCase.resource))
+		if useCase.expect == "" {
+			assert.Equal(t, "", actual, useCase.description)
+			continue
+		}
+		assert.True(t, strings.HasPrefix(actual, useCase.expect), useCase.description+" "+actual)
+		assert.False(t, strings.Contains(actual, "secret"), useCase.description)
+	}
+}
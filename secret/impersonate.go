@@ -0,0 +1,63 @@
+package secret
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/afsc/gs"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/iamcredentials/v1"
+	"google.golang.org/api/option"
+	"sync"
+	"time"
+)
+
+const (
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+	//impersonatedTokenLifetime generated access token lifetime, token is refreshed shortly before expiry
+	impersonatedTokenLifetime = "3600s"
+)
+
+//impersonatedSources impersonated token sources keyed by target service account
+var impersonatedSources = sync.Map{}
+
+//iamCredentialsOptions iamcredentials client options, default credentials are used to impersonate target service account
+var iamCredentialsOptions []option.ClientOption
+
+//impersonatedTokenSource generates target service account access token with iamcredentials generateAccessToken
+type impersonatedTokenSource struct {
+	serviceAccount string
+}
+
+//Token generates access token, caller identity requires roles/iam.serviceAccountTokenCreator on target service account
+func (s *impersonatedTokenSource) Token() (*oauth2.Token, error) {
+	ctx := context.Background()
+	service, err := iamcredentials.NewService(ctx, iamCredentialsOptions...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create iamcredentials service")
+	}
+	name := "projects/-/serviceAccounts/" + s.serviceAccount
+	request := &iamcredentials.GenerateAccessTokenRequest{Scope: []string{cloudPlatformScope}, Lifetime: impersonatedTokenLifetime}
+	response, err := service.Projects.ServiceAccounts.GenerateAccessToken(name, request).Context(ctx).Do()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to impersonate %v", s.serviceAccount)
+	}
+	expiry, err := time.Parse(time.RFC3339, response.ExpireTime)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid %v token expiry: %v", s.serviceAccount, response.ExpireTime)
+	}
+	return &oauth2.Token{AccessToken: response.AccessToken, TokenType: "Bearer", Expiry: expiry}, nil
+}
+
+//impersonatedToken returns cached token source for target service account, token is reused until it expires
+func impersonatedToken(serviceAccount string) oauth2.TokenSource {
+	if source, ok := impersonatedSources.Load(serviceAccount); ok {
+		return source.(oauth2.TokenSource)
+	}
+	source, _ := impersonatedSources.LoadOrStore(serviceAccount, oauth2.ReuseTokenSource(nil, &impersonatedTokenSource{serviceAccount: serviceAccount}))
+	return source.(oauth2.TokenSource)
+}
+
+//impersonatedClientOptions returns google storage client options authorized as target service account
+func impersonatedClientOptions(serviceAccount string) gs.ClientOptions {
+	return gs.NewClientOptions(option.WithTokenSource(impersonatedToken(serviceAccount)))
+}
@@ -0,0 +1,52 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afsc/gs"
+	"github.com/viant/smirror/config"
+	"google.golang.org/api/option"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestImpersonatedToken(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		paths = append(paths, request.URL.Path)
+		_ = json.NewEncoder(writer).Encode(map[string]string{
+			"accessToken": "token-1",
+			"expireTime":  time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+		})
+	}))
+	defer server.Close()
+	iamCredentialsOptions = []option.ClientOption{option.WithEndpoint(server.URL + "/"), option.WithoutAuthentication()}
+	defer func() { iamCredentialsOptions = nil }()
+
+	serviceAccount := "mirror@myproject.iam.gserviceaccount.com"
+	for i := 0; i < 2; i++ {
+		token, err := impersonatedToken(serviceAccount).Token()
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, "token-1", token.AccessToken)
+	}
+	assert.Equal(t, []string{"/v1/projects/-/serviceAccounts/" + serviceAccount + ":generateAccessToken"}, paths)
+
+	service := New("gs", afs.New())
+	options, err := service.StorageOpts(context.Background(), &config.Resource{URL: "gs://mybucket/data", ImpersonateServiceAccount: serviceAccount})
+	if !assert.Nil(t, err) {
+		return
+	}
+	clientOptions := gs.ClientOptions{}
+	for _, candidate := range options {
+		if value, ok := candidate.(gs.ClientOptions); ok {
+			clientOptions = value
+		}
+	}
+	assert.Equal(t, 1, len(clientOptions))
+}
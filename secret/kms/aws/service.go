@@ -32,6 +32,59 @@ func (s *service) Decrypt(ctx context.Context, secret *auth.Secret) ([]byte, err
 	return []byte(*parameter.Value), nil
 }
 
+//DecryptData decrypts ciphertext with supplied key
+func (s *service) DecryptData(ctx context.Context, key string, ciphertext []byte) ([]byte, error) {
+	input := &akms.DecryptInput{CiphertextBlob: ciphertext}
+	if key != "" {
+		input.KeyId = aws.String(key)
+	}
+	output, err := s.DecryptWithContext(ctx, input)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt with key %v", key)
+	}
+	return output.Plaintext, nil
+}
+
+//Encrypt stores data as secret parameter encrypted with secret key
+func (s *service) Encrypt(ctx context.Context, secret *auth.Secret, data []byte) error {
+	if secret.Parameter == "" {
+		return errors.New("parameter was empty")
+	}
+	if secret.Key == "" {
+		return errors.New("key was empty")
+	}
+	_, err := s.PutParameterWithContext(ctx, &ssm.PutParameterInput{
+		Name:      aws.String(secret.Parameter),
+		KeyId:     aws.String(secret.Key),
+		Type:      aws.String(ssm.ParameterTypeSecureString),
+		Value:     aws.String(string(data)),
+		Overwrite: aws.Bool(true),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to put parameter %v", secret.Parameter)
+	}
+	return nil
+}
+
+//CheckKey checks if key exists and is enabled
+func (s *service) CheckKey(ctx context.Context, key string) error {
+	keyID, err := s.getKeyByAlias(key)
+	if err != nil {
+		return err
+	}
+	output, err := s.DescribeKeyWithContext(ctx, &akms.DescribeKeyInput{KeyId: aws.String(keyID)})
+	if err != nil {
+		return errors.Wrapf(err, "failed to describe key %v", key)
+	}
+	if output.KeyMetadata == nil || output.KeyMetadata.KeyState == nil {
+		return errors.Errorf("key %v metadata was empty", key)
+	}
+	if state := *output.KeyMetadata.KeyState; state != akms.KeyStateEnabled {
+		return errors.Errorf("key %v is not enabled: %v", key, state)
+	}
+	return nil
+}
+
 func (s *service) getKeyByAlias(keyOrAlias string) (string, error) {
 	if strings.Count(keyOrAlias, ":") > 0 {
 		return keyOrAlias, nil
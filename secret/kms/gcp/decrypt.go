@@ -6,13 +6,17 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/viant/afs"
+	"github.com/viant/afs/file"
 	"google.golang.org/api/cloudkms/v1"
 	"google.golang.org/api/option"
 	"io/ioutil"
+	"strings"
 	"github.com/viant/smirror/auth"
 	"github.com/viant/smirror/secret/kms"
 )
 
+const useToEncryptPermission = "cloudkms.cryptoKeyVersions.useToEncrypt"
+
 type service struct {
 	afs.Service
 }
@@ -37,16 +41,72 @@ func (s *service) Decrypt(ctx context.Context, secret *auth.Secret) ([]byte, err
 	if err != nil {
 		return nil, err
 	}
+	plainText, err = s.decrypt(ctx, secret.Key, plainText)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(plainText), nil
+}
+
+//DecryptData decrypts ciphertext with supplied key
+func (s *service) DecryptData(ctx context.Context, key string, ciphertext []byte) ([]byte, error) {
+	plainText, err := s.decrypt(ctx, key, base64.StdEncoding.EncodeToString(ciphertext))
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(plainText)
+}
+
+//decrypt decrypts base64 ciphertext, it returns base64 plaintext
+func (s *service) decrypt(ctx context.Context, key, ciphertext string) (string, error) {
 	kmsService, err := cloudkms.NewService(ctx, option.WithScopes(cloudkms.CloudPlatformScope, cloudkms.CloudkmsScope))
 	if err != nil {
-		return nil, errors.Wrap(err, fmt.Sprintf("failed to create kmsService server for key %v", secret.Key))
+		return "", errors.Wrap(err, fmt.Sprintf("failed to create kmsService server for key %v", key))
 	}
 	service := cloudkms.NewProjectsLocationsKeyRingsCryptoKeysService(kmsService)
-	response, err := service.Decrypt(secret.Key, &cloudkms.DecryptRequest{Ciphertext: plainText}).Context(ctx).Do()
+	response, err := service.Decrypt(key, &cloudkms.DecryptRequest{Ciphertext: ciphertext}).Context(ctx).Do()
 	if err != nil {
-		return nil, errors.Wrap(err, fmt.Sprintf("failed to decrypt with key %v", secret.Key))
+		return "", errors.Wrap(err, fmt.Sprintf("failed to decrypt with key %v", key))
+	}
+	return response.Plaintext, nil
+}
+
+//Encrypt encrypts data with secret key and uploads base64 ciphertext to secret URL
+func (s *service) Encrypt(ctx context.Context, secret *auth.Secret, data []byte) error {
+	kmsService, err := cloudkms.NewService(ctx, option.WithScopes(cloudkms.CloudPlatformScope, cloudkms.CloudkmsScope))
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to create kmsService server for key %v", secret.Key))
+	}
+	service := cloudkms.NewProjectsLocationsKeyRingsCryptoKeysService(kmsService)
+	response, err := service.Encrypt(secret.Key, &cloudkms.EncryptRequest{Plaintext: base64.StdEncoding.EncodeToString(data)}).Context(ctx).Do()
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to encrypt with key %v", secret.Key))
+	}
+	if err = s.Service.Upload(ctx, secret.URL, file.DefaultFileOsMode, strings.NewReader(response.Ciphertext)); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to upload secret %v", secret.URL))
+	}
+	return nil
+}
+
+//CheckKey checks if caller can use the key to encrypt data
+func (s *service) CheckKey(ctx context.Context, key string) error {
+	kmsService, err := cloudkms.NewService(ctx, option.WithScopes(cloudkms.CloudPlatformScope, cloudkms.CloudkmsScope))
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to create kmsService server for key %v", key))
+	}
+	service := cloudkms.NewProjectsLocationsKeyRingsCryptoKeysService(kmsService)
+	response, err := service.TestIamPermissions(key, &cloudkms.TestIamPermissionsRequest{
+		Permissions: []string{useToEncryptPermission},
+	}).Context(ctx).Do()
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("failed to check key %v", key))
+	}
+	for _, permission := range response.Permissions {
+		if permission == useToEncryptPermission {
+			return nil
+		}
 	}
-	return []byte(response.Plaintext), nil
+	return errors.Errorf("key %v is not accessible, missing %v permission", key, useToEncryptPermission)
 }
 
 //New creates GCP kms service
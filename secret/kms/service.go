@@ -7,4 +7,10 @@ import (
 
 type Service interface {
 	Decrypt(ctx context.Context, secret *auth.Secret) ([]byte, error)
+	//DecryptData decrypts ciphertext with supplied key
+	DecryptData(ctx context.Context, key string, ciphertext []byte) ([]byte, error)
+	//CheckKey checks if key exists and can be used for encryption
+	CheckKey(ctx context.Context, key string) error
+	//Encrypt encrypts data with secret key and stores it at secret location
+	Encrypt(ctx context.Context, secret *auth.Secret, data []byte) error
 }
@@ -0,0 +1,46 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/config"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"net/http"
+	"net/url"
+)
+
+//oauth2Client represents decrypted OAuth2 client credentials
+type oauth2Client struct {
+	ClientID     string
+	ClientSecret string
+}
+
+//oauth2TokenSource returns client credentials token source, it caches token and fetches a new one before expiry,
+//so it is shared by all requests of a credential identity
+func oauth2TokenSource(settings *config.OAuth2, auth []byte, client *http.Client) (oauth2.TokenSource, error) {
+	credentials := &oauth2Client{}
+	if err := json.Unmarshal(auth, credentials); err != nil {
+		return nil, errors.Wrap(err, "failed to decode oauth2 client credentials")
+	}
+	if credentials.ClientID == "" || credentials.ClientSecret == "" {
+		return nil, errors.New("oauth2 credentials require ClientID and ClientSecret")
+	}
+	cfg := &clientcredentials.Config{
+		ClientID:     credentials.ClientID,
+		ClientSecret: credentials.ClientSecret,
+		TokenURL:     settings.TokenURL,
+		Scopes:       settings.Scopes,
+		AuthStyle:    oauth2.AuthStyleInHeader,
+	}
+	if settings.AuthStyle == config.OAuth2AuthStyleParams {
+		cfg.AuthStyle = oauth2.AuthStyleInParams
+	}
+	if settings.Audience != "" {
+		cfg.EndpointParams = url.Values{"audience": []string{settings.Audience}}
+	}
+	//token source outlives request context
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
+	return cfg.TokenSource(ctx), nil
+}
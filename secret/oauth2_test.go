@@ -0,0 +1,89 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestService_StorageOpts_OAuth2(t *testing.T) {
+	var useCases = []struct {
+		description       string
+		expiresIn         int
+		authStyle         string
+		uploads           int
+		expectTokenCalls  int32
+		expectClientInURL bool
+	}{
+		{
+			description:      "token is cached",
+			expiresIn:        3600,
+			uploads:          3,
+			expectTokenCalls: 1,
+		},
+		{
+			description:      "expired token is refreshed",
+			expiresIn:        1,
+			uploads:          2,
+			expectTokenCalls: 2,
+		},
+		{
+			description:       "client credentials in params",
+			expiresIn:         3600,
+			authStyle:         config.OAuth2AuthStyleParams,
+			uploads:           1,
+			expectTokenCalls:  1,
+			expectClientInURL: true,
+		},
+	}
+
+	for i, useCase := range useCases {
+		var tokenCalls int32
+		var clientInParams bool
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			count := atomic.AddInt32(&tokenCalls, 1)
+			_ = request.ParseForm()
+			clientInParams = request.PostForm.Get("client_id") == "mirror"
+			assert.EqualValues(t, "client_credentials", request.PostForm.Get("grant_type"), useCase.description)
+			assert.EqualValues(t, "upload", request.PostForm.Get("scope"), useCase.description)
+			writer.Header().Set("Content-Type", "application/json")
+			_, _ = fmt.Fprintf(writer, `{"access_token":"token-%v","token_type":"Bearer","expires_in":%v}`, count, useCase.expiresIn)
+		}))
+		var authorizations []string
+		destServer := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			authorizations = append(authorizations, request.Header.Get("Authorization"))
+		}))
+
+		resource := &config.Resource{
+			URL:         destServer.URL + "/data",
+			Credentials: &auth.Credentials{Auth: []byte(fmt.Sprintf(`{"ClientID":"mirror","ClientSecret":"secret-%v"}`, i))},
+			OAuth2:      &config.OAuth2{TokenURL: tokenServer.URL, Scopes: []string{"upload"}, AuthStyle: useCase.authStyle},
+		}
+		assert.Nil(t, resource.Validate(), useCase.description)
+		service := New("gs", afs.New())
+		fs := afs.New()
+		for j := 0; j < useCase.uploads; j++ {
+			options, err := service.StorageOpts(context.Background(), resource)
+			if !assert.Nil(t, err, useCase.description) {
+				break
+			}
+			err = fs.Upload(context.Background(), fmt.Sprintf("%v/%v.csv", resource.URL, j), 0644, strings.NewReader("1,2"), options...)
+			assert.Nil(t, err, useCase.description)
+		}
+		assert.EqualValues(t, useCase.expectTokenCalls, atomic.LoadInt32(&tokenCalls), useCase.description)
+		assert.EqualValues(t, useCase.expectClientInURL, clientInParams, useCase.description)
+		if assert.EqualValues(t, useCase.uploads, len(authorizations), useCase.description) {
+			assert.EqualValues(t, fmt.Sprintf("Bearer token-%v", useCase.expectTokenCalls), authorizations[len(authorizations)-1], useCase.description)
+		}
+		tokenServer.Close()
+		destServer.Close()
+	}
+}
@@ -0,0 +1,78 @@
+package secret
+
+import (
+	"context"
+	"encoding/base64"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	aoption "github.com/viant/afs/option"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestService_StorageOpts_Proxy(t *testing.T) {
+	ctx := context.Background()
+	var proxied, direct int
+	var proxyAuth string
+	proxy := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		proxied++
+		proxyAuth = request.Header.Get("Proxy-Authorization")
+	}))
+	defer proxy.Close()
+	target := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		direct++
+	}))
+	defer target.Close()
+
+	var useCases = []struct {
+		description string
+		Proxy       *config.Proxy
+		expectProxy bool
+		expectAuth  string
+	}{
+		{
+			description: "proxy with user:password credentials",
+			Proxy:       &config.Proxy{Proxy: aoption.Proxy{URL: proxy.URL}, Auth: []byte("user:secret")},
+			expectProxy: true,
+			expectAuth:  "Basic " + base64.StdEncoding.EncodeToString([]byte("user:secret")),
+		},
+		{
+			description: "proxy with JSON credentials",
+			Proxy:       &config.Proxy{Proxy: aoption.Proxy{URL: proxy.URL}, Auth: []byte(`{"Username":"u2","Password":"p2"}`)},
+			expectProxy: true,
+			expectAuth:  "Basic " + base64.StdEncoding.EncodeToString([]byte("u2:p2")),
+		},
+		{
+			description: "target host in no proxy list",
+			Proxy:       &config.Proxy{Proxy: aoption.Proxy{URL: proxy.URL}, NoProxy: []string{"127.0.0.0/8"}},
+		},
+	}
+
+	for _, useCase := range useCases {
+		proxied, direct, proxyAuth = 0, 0, ""
+		resource := &config.Resource{URL: target.URL + "/data", Proxy: useCase.Proxy}
+		if !assert.Nil(t, resource.Validate(), useCase.description) {
+			continue
+		}
+		options, err := New("gs", afs.New()).StorageOpts(ctx, resource)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		err = afs.New().Upload(ctx, url.Join(target.URL, "data/f1.csv"), 0644, strings.NewReader("1,2"), options...)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		if useCase.expectProxy {
+			assert.True(t, proxied > 0, useCase.description)
+			assert.EqualValues(t, 0, direct, useCase.description)
+			assert.EqualValues(t, useCase.expectAuth, proxyAuth, useCase.description)
+			continue
+		}
+		assert.EqualValues(t, 0, proxied, useCase.description)
+		assert.True(t, direct > 0, useCase.description)
+	}
+}
@@ -15,10 +15,15 @@ import (
 	"github.com/viant/afsc/s3"
 
 	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/box"
 	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/dropbox"
 	"github.com/viant/smirror/secret/kms"
 	"github.com/viant/smirror/secret/kms/aws"
 	"github.com/viant/smirror/secret/kms/gcp"
+	"github.com/viant/smirror/shared"
+	"strings"
+	"time"
 )
 
 //Service represents kms service
@@ -30,8 +35,13 @@ type Service interface {
 
 	//StorageOpts returns storage option for supplied resource
 	StorageOpts(ctx context.Context, resource *config.Resource) ([]storage.Option, error)
+
+	//CheckKeys checks if resources encryption keys are accessible
+	CheckKeys(ctx context.Context, resources []*config.Resource) error
 }
 
+const gcpKeyPrefix = "projects/"
+
 type service struct {
 	sourceScheme string
 	fs           afs.Service
@@ -47,9 +57,22 @@ func (s service) Decrypt(ctx context.Context, secret *auth.Secret) ([]byte, erro
 		return nil, err
 	}
 	data = decodeBase64IfNeeded(data)
+	shared.RegisterSecret(data)
 	return data, err
 }
 
+//DecryptData decrypts ciphertext with GCP KMS key name (projects/...) or AWS KMS key ARN/alias
+func (s service) DecryptData(ctx context.Context, key string, ciphertext []byte) ([]byte, error) {
+	var kmsService kms.Service
+	var err error
+	if strings.HasPrefix(key, gcpKeyPrefix) {
+		kmsService = gcp.New(s.fs)
+	} else if kmsService, err = aws.New(); err != nil {
+		return nil, err
+	}
+	return kmsService.DecryptData(ctx, key, ciphertext)
+}
+
 //Kms returns kms service
 func (s service) Kms(service afs.Service) (kms.Service, error) {
 	switch s.sourceScheme {
@@ -69,6 +92,32 @@ func (s *service) Init(ctx context.Context, service afs.Service, resources []*co
 		if resource == nil {
 			continue
 		}
+		if settings := resource.TLS; settings != nil && settings.Certificate != nil && settings.ClientCertificate == nil {
+			if kmsService == nil {
+				if kmsService, err = s.Kms(service); err != nil {
+					return err
+				}
+			}
+			data, err := kmsService.Decrypt(ctx, settings.Certificate)
+			if err != nil {
+				return errors.Wrapf(err, "failed to decrypt tls client certificate: %v", resource.URL)
+			}
+			settings.ClientCertificate = decodeBase64IfNeeded(data)
+			shared.RegisterSecret(settings.ClientCertificate)
+		}
+		if proxy := resource.Proxy; proxy != nil && proxy.Credentials != nil && proxy.Auth == nil {
+			if kmsService == nil {
+				if kmsService, err = s.Kms(service); err != nil {
+					return err
+				}
+			}
+			data, err := kmsService.Decrypt(ctx, proxy.Credentials)
+			if err != nil {
+				return errors.Wrapf(err, "failed to decrypt proxy credentials: %v", resource.URL)
+			}
+			proxy.Auth = decodeBase64IfNeeded(data)
+			shared.RegisterSecret(proxy.Auth)
+		}
 		if resource.Credentials == nil && resource.CustomKey == nil {
 			continue
 		}
@@ -91,6 +140,7 @@ func (s *service) Init(ctx context.Context, service afs.Service, resources []*co
 				return err
 			}
 			resources[i].Credentials.Auth = decodeBase64IfNeeded(data)
+			shared.RegisterSecret(resources[i].Credentials.Auth)
 		}
 
 		if resource.CustomKey != nil && resources[i].CustomKey.AES256Key == nil {
@@ -99,6 +149,7 @@ func (s *service) Init(ctx context.Context, service afs.Service, resources []*co
 				return err
 			}
 			data = decodeBase64IfNeeded(data)
+			shared.RegisterSecret(data)
 			if resources[i].CustomKey.AES256Key, err = option.NewAES256Key(data); err != nil {
 				return err
 			}
@@ -107,6 +158,35 @@ func (s *service) Init(ctx context.Context, service afs.Service, resources []*co
 	return nil
 }
 
+//CheckKeys checks if resources encryption keys are accessible, customer supplied keys are checked by decryption
+func (s *service) CheckKeys(ctx context.Context, resources []*config.Resource) (err error) {
+	var gcpKms, awsKms kms.Service
+	for _, resource := range resources {
+		if resource == nil {
+			continue
+		}
+		if resource.KMSKeyName != "" {
+			if gcpKms == nil {
+				gcpKms = gcp.New(s.fs)
+			}
+			if err = gcpKms.CheckKey(ctx, resource.KMSKeyName); err != nil {
+				return errors.Wrapf(err, "failed to check KMSKeyName: %v", resource.URL)
+			}
+		}
+		if resource.KMSKeyARN != "" {
+			if awsKms == nil {
+				if awsKms, err = aws.New(); err != nil {
+					return err
+				}
+			}
+			if err = awsKms.CheckKey(ctx, resource.KMSKeyARN); err != nil {
+				return errors.Wrapf(err, "failed to check KMSKeyARN: %v", resource.URL)
+			}
+		}
+	}
+	return s.Init(ctx, s.fs, resources)
+}
+
 //StorageOpts returns storage option for supplied resource
 func (s service) StorageOpts(ctx context.Context, resource *config.Resource) ([]storage.Option, error) {
 	var result = make([]storage.Option, 0)
@@ -116,9 +196,6 @@ func (s service) StorageOpts(ctx context.Context, resource *config.Resource) ([]
 	if resource.CustomKey != nil && resource.CustomKey.AES256Key != nil {
 		result = append(result, resource.CustomKey.AES256Key)
 	}
-	if resource.Proxy != nil {
-		result = append(result, resource.Proxy)
-	}
 	if resource.Grant != nil {
 		result = append(result, resource.Grant)
 	}
@@ -128,11 +205,40 @@ func (s service) StorageOpts(ctx context.Context, resource *config.Resource) ([]
 	if resource.ServerSideEncryption != nil {
 		result = append(result, resource.ServerSideEncryption)
 	}
+	if resource.RequesterPays {
+		result = append(result, config.NewRequesterPays(true, resource.ProjectID))
+	}
+	if resource.KMSKeyARN != "" {
+		result = append(result, config.NewKMSKey(resource.KMSKeyARN))
+	}
+	if resource.KMSKeyName != "" {
+		result = append(result, config.NewKMSKeyName(resource.KMSKeyName))
+	}
+	if resource.Retention != nil {
+		result = append(result, resource.Retention.Option(time.Now()))
+	}
 	if resource.URL == "" {
 		return result, nil
 	}
-	var err error
 	scheme := url.Scheme(resource.URL, file.Scheme)
+	identity := NewIdentity(scheme, resource)
+	authOpts, err := cachedAuthOptions(identity, func() ([]storage.Option, error) {
+		return s.authOptions(ctx, scheme, resource)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result = append(result, authOpts...)
+	if identity != "" {
+		result = append(result, identity)
+	}
+	return result, nil
+}
+
+//authOptions returns resource credentials storage options
+func (s service) authOptions(ctx context.Context, scheme string, resource *config.Resource) ([]storage.Option, error) {
+	var result = make([]storage.Option, 0)
+	var err error
 	if resource.Credentials != nil && len(resource.Credentials.Auth) > 0 {
 		if !json.Valid(resource.Credentials.Auth) {
 			return nil, errors.Errorf("invalid credentials format, expected JSON but had: %s", resource.Credentials.Auth)
@@ -154,13 +260,77 @@ func (s service) StorageOpts(ctx context.Context, resource *config.Resource) ([]
 			if resource.Region != "" {
 				result = append(result, &option.Region{Name: resource.Region})
 			}
+		case box.Scheme:
+			credentials, err := box.NewCredentials(resource.Credentials.Auth)
+			if err != nil {
+				return nil, err
+			}
+			if resource.Credentials.URL != "" || resource.Credentials.Parameter != "" {
+				credentials.Persist = s.persister(resource.Credentials.Secret)
+			}
+			result = append(result, credentials)
+		case dropbox.Scheme:
+			if authOpt, err = dropbox.NewCredentials(resource.Credentials.Auth); err != nil {
+				return nil, err
+			}
+			result = append(result, authOpt)
 		default:
 			//do nothing init should take care of validating supported URL scheme
 		}
 	}
+	if resource.Federation != nil && (scheme == gs.Scheme || scheme == s3.Scheme) {
+		federated, err := federatedOption(resource.Federation, resource.Region)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, federated)
+	}
+	if resource.ImpersonateServiceAccount != "" && scheme == gs.Scheme {
+		result = append(result, impersonatedClientOptions(resource.ImpersonateServiceAccount))
+	}
+	switch scheme {
+	case gs.Scheme:
+		if resource.Proxy != nil {
+			proxyOpt, err := resource.Proxy.Option(gcsHost)
+			if err != nil {
+				return nil, err
+			}
+			if proxyOpt != nil {
+				result = append(result, proxyOpt)
+			}
+		}
+	case s3.Scheme:
+		if resource.Proxy != nil {
+			return s.proxiedAwsOptions(ctx, resource, result)
+		}
+	default:
+		if resource.TLS != nil || resource.OAuth2 != nil || resource.Proxy != nil {
+			clientOpt, err := s.httpClientOption(ctx, resource)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, clientOpt)
+		}
+	}
 	return result, nil
 }
 
+//persister returns persister encrypting and storing rotated secret at the secret location
+func (s service) persister(secret auth.Secret) box.Persister {
+	return func(ctx context.Context, data []byte) error {
+		kmsService, err := s.Kms(s.fs)
+		if err != nil {
+			return err
+		}
+		return kmsService.Encrypt(ctx, &secret, data)
+	}
+}
+
+//NewDecrypter returns rule file decrypter, KMS provider is selected by key format
+func NewDecrypter(fs afs.Service) config.Decrypter {
+	return service{fs: fs}.DecryptData
+}
+
 //New creates a new secret service
 func New(sourceScheme string, fs afs.Service) Service {
 	return &service{
@@ -0,0 +1,134 @@
+package secret
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/defaults"
+	"github.com/pkg/errors"
+	ahttp "github.com/viant/afs/http"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afsc/s3"
+	"github.com/viant/smirror/config"
+	"golang.org/x/oauth2"
+	"net"
+	"net/http"
+	"time"
+)
+
+//gcsHost google storage API host, proxy option is skipped if it matches NoProxy list
+const gcsHost = "storage.googleapis.com"
+
+//httpClientOption returns http client provider with resource TLS and proxy transport and OAuth2 bearer token
+func (s service) httpClientOption(ctx context.Context, resource *config.Resource) (storage.Option, error) {
+	transport, err := s.transport(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+	if resource.OAuth2 != nil {
+		if resource.Credentials == nil || len(resource.Credentials.Auth) == 0 {
+			return nil, errors.Errorf("oauth2 client credentials were empty: %v", resource.URL)
+		}
+		//token endpoint is called with the same TLS and proxy settings
+		source, err := oauth2TokenSource(resource.OAuth2, resource.Credentials.Auth, &http.Client{Transport: transport})
+		if err != nil {
+			return nil, err
+		}
+		transport = &oauth2.Transport{Source: source, Base: transport}
+	}
+	client := &http.Client{Transport: transport}
+	return ahttp.ClientProvider(func(baseURL string, options ...storage.Option) (*http.Client, error) {
+		return client, nil
+	}), nil
+}
+
+//transport returns http transport with resource TLS and proxy settings
+func (s service) transport(ctx context.Context, resource *config.Resource) (http.RoundTripper, error) {
+	if resource.TLS == nil && resource.Proxy == nil {
+		return http.DefaultTransport, nil
+	}
+	var result *http.Transport
+	if base, ok := http.DefaultTransport.(*http.Transport); ok {
+		//cloned transport keeps tuned idle connection settings
+		result = base.Clone()
+	} else {
+		result = &http.Transport{Proxy: http.ProxyFromEnvironment}
+	}
+	if resource.TLS != nil {
+		tlsConfig, err := s.tlsConfig(ctx, resource.TLS)
+		if err != nil {
+			return nil, err
+		}
+		result.TLSClientConfig = tlsConfig
+	}
+	if proxy := resource.Proxy; proxy != nil {
+		if _, err := proxy.ProxyURL(); err != nil {
+			return nil, errors.Wrap(err, "invalid proxy settings")
+		}
+		result.Proxy = proxy.Func()
+		if proxy.TimeoutMs > 0 {
+			result.DialContext = (&net.Dialer{Timeout: time.Duration(proxy.TimeoutMs) * time.Millisecond, KeepAlive: 30 * time.Second}).DialContext
+		}
+	}
+	return result, nil
+}
+
+//proxiedAwsOptions replaces aws auth options with aws config using proxied http client
+func (s service) proxiedAwsOptions(ctx context.Context, resource *config.Resource, options []storage.Option) ([]storage.Option, error) {
+	var awsConfig *aws.Config
+	var provider s3.AwsConfigProvider
+	var err error
+	if _, ok := option.Assign(options, &awsConfig); !ok {
+		if _, ok = option.Assign(options, &provider); ok {
+			if awsConfig, err = provider.AwsConfig(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if awsConfig == nil {
+		awsConfig = aws.NewConfig().WithCredentials(defaults.CredChain(defaults.Config(), defaults.Handlers()))
+	}
+	transport, err := s.transport(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+	awsConfig = awsConfig.Copy().WithHTTPClient(&http.Client{Transport: transport})
+	var result = []storage.Option{awsConfig}
+	for _, candidate := range options {
+		switch candidate.(type) {
+		case *aws.Config, s3.AwsConfigProvider:
+		default:
+			result = append(result, candidate)
+		}
+	}
+	return result, nil
+}
+
+//tlsConfig returns TLS config with CA bundle appended to system roots and mTLS client certificate
+func (s service) tlsConfig(ctx context.Context, settings *config.TLS) (*tls.Config, error) {
+	result := &tls.Config{MinVersion: settings.Version(), InsecureSkipVerify: settings.InsecureSkipVerify}
+	if settings.CAURL != "" {
+		data, err := s.fs.DownloadWithURL(ctx, settings.CAURL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load tls CA bundle: %v", settings.CAURL)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, errors.Errorf("tls CA bundle had no PEM certificates: %v", settings.CAURL)
+		}
+		result.RootCAs = pool
+	}
+	if len(settings.ClientCertificate) > 0 {
+		certificate, err := tls.X509KeyPair(settings.ClientCertificate, settings.ClientCertificate)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid tls client certificate")
+		}
+		result.Certificates = []tls.Certificate{certificate}
+	}
+	return result, nil
+}
@@ -0,0 +1,111 @@
+package secret
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/smirror/config"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+//clientCertificatePEM returns self signed client certificate with private key PEM
+func clientCertificatePEM(t *testing.T) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "mirror"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	result := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	return append(result, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})...)
+}
+
+func TestService_StorageOpts_TLS(t *testing.T) {
+	ctx := context.Background()
+	var clientCerts int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		clientCerts = len(request.TLS.PeerCertificates)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert, MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	fs := afs.New()
+	caURL := "mem://localhost/tls/ca.pem"
+	_ = fs.Upload(ctx, caURL, 0644, strings.NewReader(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))))
+	clientPEM := clientCertificatePEM(t)
+
+	var useCases = []struct {
+		description string
+		TLS         *config.TLS
+		expectError bool
+	}{
+		{
+			description: "private CA with client certificate",
+			TLS:         &config.TLS{CAURL: caURL, ClientCertificate: clientPEM},
+		},
+		{
+			description: "private CA without client certificate",
+			TLS:         &config.TLS{CAURL: caURL},
+			expectError: true,
+		},
+		{
+			description: "client certificate without private CA",
+			TLS:         &config.TLS{ClientCertificate: clientPEM},
+			expectError: true,
+		},
+		{
+			description: "insecure skip verify",
+			TLS:         &config.TLS{InsecureSkipVerify: true, ClientCertificate: clientPEM},
+		},
+		{
+			description: "min version above server version",
+			TLS:         &config.TLS{CAURL: caURL, ClientCertificate: clientPEM, MinVersion: "1.3"},
+			expectError: true,
+		},
+	}
+
+	for _, useCase := range useCases {
+		clientCerts = 0
+		resource := &config.Resource{URL: server.URL + "/data", TLS: useCase.TLS}
+		if !assert.Nil(t, resource.Validate(), useCase.description) {
+			continue
+		}
+		options, err := New("gs", fs).StorageOpts(ctx, resource)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		err = afs.New().Upload(ctx, server.URL+"/data/f1.csv", 0644, strings.NewReader("1,2"), options...)
+		if useCase.expectError {
+			assert.NotNil(t, err, useCase.description)
+			continue
+		}
+		assert.Nil(t, err, useCase.description)
+		assert.EqualValues(t, 1, clientCerts, useCase.description)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"github.com/google/uuid"
@@ -16,16 +17,23 @@ import (
 	"github.com/viant/afs/url"
 	"github.com/viant/afsc/gs"
 	"github.com/viant/afsc/s3"
+	"github.com/viant/smirror/as2"
 	"github.com/viant/smirror/base"
 	"github.com/viant/smirror/config"
 	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/eventlog"
 	"github.com/viant/smirror/job"
 	"github.com/viant/smirror/msgbus"
 	"github.com/viant/smirror/msgbus/pubsub"
 	"github.com/viant/smirror/msgbus/sqs"
+	"github.com/viant/smirror/native"
 	"github.com/viant/smirror/secret"
 	"github.com/viant/smirror/shared"
 	"github.com/viant/smirror/slack"
+	"github.com/viant/smirror/stackdriver"
+	"github.com/viant/smirror/statsd"
+	"github.com/viant/smirror/throttle"
+	"google.golang.org/api/monitoring/v3"
 	"io"
 	"io/ioutil"
 	"os"
@@ -40,10 +48,32 @@ import (
 type Service interface {
 	//Mirror copies/split source to matched destination
 	Mirror(ctx context.Context, request *contract.Request) *contract.Response
+	//MirrorBatch mirrors multiple source objects concurrently
+	MirrorBatch(ctx context.Context, request *contract.BatchRequest) *contract.BatchResponse
+	//Activate validates pending rules and promotes them to active rules
+	Activate(ctx context.Context, request *contract.ActivationRequest) *contract.ActivationResponse
+	//RuleStatus returns rule files load status
+	RuleStatus(ctx context.Context, request *contract.RuleStatusRequest) *contract.RuleStatusResponse
+	//RuleHistory evaluates source URL against rules active at a given time and now
+	RuleHistory(ctx context.Context, request *contract.RuleHistoryRequest) *contract.RuleHistoryResponse
+	//Pause pauses or resumes ingestion for all rules or a rule
+	Pause(ctx context.Context, request *contract.PauseRequest) *contract.PauseResponse
+	//Webhook verifies partner push notification and mirrors notified source objects
+	Webhook(ctx context.Context, request *contract.WebhookRequest) *contract.WebhookResponse
+	//ReceiveAS2 verifies AS2 message, lands and mirrors its payload
+	ReceiveAS2(ctx context.Context, request *contract.AS2Request) *contract.AS2Response
+	//ReadEvents returns lifecycle events appended to bucket event log after cursor
+	ReadEvents(ctx context.Context, request *contract.EventsRequest) *contract.EventsResponse
+	//Dashboard returns per rule transfer time series aggregated from response log
+	Dashboard(ctx context.Context, request *contract.DashboardRequest) *contract.DashboardResponse
 }
 
 type service struct {
 	mux          *sync.Mutex
+	//initMux serializes rule initialisation, batch mirrors objects concurrently with shared rules
+	initMux      sync.Mutex
+	//ruleInits tracks initialised rules, a published rule is initialised once before concurrent events use it
+	ruleInits    sync.Map
 	config       *Config
 	fs           afs.Service
 	cfs          afs.Service
@@ -51,22 +81,72 @@ type service struct {
 	msgbus       msgbus.Service
 	msgbusVendor string
 	notifier     slack.Slack
+	limiter      *throttle.Limiter
+	lanes        *lanes
+	pendingHash  string
+	nextStaging  time.Time
+	paused       map[string]*config.PauseMarker
+	nextPause    time.Time
+	appendLocks  map[string]*sync.Mutex
+	partSizer    *partSizer
+	clients      *clients
+	//webhookSecrets decrypted webhook shared secrets keyed by partner
+	webhookSecrets map[string][]byte
+	//as2PartnerCertificates AS2 partner certificates keyed by partner ID
+	as2PartnerCertificates map[string][]*x509.Certificate
+	//as2MDNSigner decrypted AS2 identity used to sign MDN and decrypt messages
+	as2MDNSigner *as2.Signer
+	//preflights successful dest preflight expiry keyed by dest address
+	preflights map[string]time.Time
+	//eventLog lifecycle event log appender, created with the first append
+	eventLog eventlog.Appender
+	//statsd metrics emitter client, created with the first emit
+	statsd *statsd.Client
+	//monitoring Cloud Monitoring instance metrics recorder, created with the first response
+	monitoring *stackdriver.Recorder
+	//monitoringService Cloud Monitoring client
+	monitoringService *monitoring.Service
 }
 
 func (s *service) Mirror(ctx context.Context, request *contract.Request) *contract.Response {
 
 	request.Attempt++
+	if request.TransferID == "" {
+		request.TransferID = uuid.New().String()
+	}
 	response := contract.NewResponse(request.URL)
+	response.TransferID = request.TransferID
+	response.CorrelationID = request.CorrelationID
+	response.AddEvent(eventlog.TypeReceived)
 
+	var monitor *perfMonitor
+	if s.config.Perf != nil {
+		monitor = startPerfMonitor(s.config.Perf.SampleInterval())
+	}
 	err := s.mirror(ctx, request, response)
+	if monitor != nil {
+		response.Perf = monitor.stop(response)
+	}
+	if s.config.Connections.Stats {
+		response.Clients = s.clients.Stats()
+	}
 	if err != nil {
 		response.Status = base.StatusError
 		response.Error = err.Error()
+		response.ClassifyError(err)
+		response.AddEvent(eventlog.TypeFailed)
 	}
+	s.sampleLog(response)
+	response.Redact()
 	if s.config.ResponseURL != "" {
 		s.logResponse(ctx, response)
 	}
 	if response.Error == "" {
+		s.checkPoison(ctx, request, response)
+		s.persistResponse(ctx, response)
+		s.appendEvents(ctx, response)
+		s.emitMetrics(response)
+		s.recordMonitoring(ctx, response)
 		return response
 	}
 	if IsNotFound(response.Error) {
@@ -78,18 +158,30 @@ func (s *service) Mirror(ctx context.Context, request *contract.Request) *contra
 			return s.Mirror(ctx, request)
 		}
 	}
+	s.checkPoison(ctx, request, response)
 	if s.config.ResponseURL != "" {
 		s.logResponse(ctx, response)
 	}
+	s.persistResponse(ctx, response)
+	s.appendEvents(ctx, response)
+	s.emitMetrics(response)
+	s.recordMonitoring(ctx, response)
 	return response
 }
 
 func (s *service) mirror(ctx context.Context, request *contract.Request, response *contract.Response) (err error) {
-	_, err = s.config.Mirrors.ReloadIfNeeded(ctx, s.cfs)
+	changed, err := s.config.Mirrors.ReloadIfNeeded(ctx, s.cfs)
 	if err != nil {
 		return err
 	}
+	if changed {
+		s.retainRuleInits()
+	}
+	s.checkStaging(ctx)
 	var rule *config.Rule
+	if s.config.EvaluationTrace {
+		response.Evaluations = s.config.Mirrors.Evaluate(request.URL)
+	}
 	matched := s.config.Mirrors.Match(request.URL)
 	switch len(matched) {
 	case 0:
@@ -97,10 +189,10 @@ func (s *service) mirror(ctx context.Context, request *contract.Request, respons
 		rule = matched[0]
 	default:
 		JSON, _ := json.Marshal(matched)
-		return errors.Errorf("multi rule match currently not supported: %s", JSON)
+		return base.NewCodedError(base.ErrorCodeConfig, errors.Errorf("multi rule match currently not supported: %s", JSON))
 	}
 
-	response.TotalRules = len(s.config.Mirrors.Rules)
+	response.TotalRules = len(s.config.Mirrors.Snapshot())
 	if rule == nil {
 		response.Status = base.StatusNoMatch
 		return nil
@@ -109,14 +201,29 @@ func (s *service) mirror(ctx context.Context, request *contract.Request, respons
 		response.Status = base.StatusDisabled
 		return nil
 	}
+	if paused, err := s.checkPause(ctx, rule, request, response); paused || err != nil {
+		return err
+	}
+	if deferred, err := s.checkMaintenance(ctx, rule, request, response); deferred || err != nil {
+		return err
+	}
 
 	if err := s.initRule(ctx, rule); err != nil {
 		return errors.Wrapf(err, "frailed to initialise rule: %v", rule.Info.Workflow)
 	}
 	response.Rule = rule
+	response.AddEvent(eventlog.TypeMatched)
+	response.Captures = rule.Source.Captures(request.URL)
+	response.AddLabels(rule.CaptureLabels(response.Captures))
+	response.AddLabels(s.config.Labels)
+	debugf(rule, response, "matched rule %v: %v", rule.Info.Workflow, request.URL)
+	if err := rule.CheckClassification(); err != nil {
+		shared.LogF("policy violation: %v, source: %v\n", err, request.URL)
+		return base.NewCodedError(base.ErrorCodePolicy, err)
+	}
 	options, err := s.secret.StorageOpts(ctx, rule.Source.CloneWithURL(request.URL))
 	if err != nil {
-		return err
+		return base.NewCodedError(base.ErrorCodeAuth, err)
 	}
 	object, err := s.fs.Object(ctx, request.URL, options...)
 	if object == nil {
@@ -124,7 +231,53 @@ func (s *service) mirror(ctx context.Context, request *contract.Request, respons
 		response.NotFoundError = fmt.Sprintf("does not exist: %v", err)
 		return nil
 	}
+	if rule.Debounce != nil {
+		var release func()
+		if object, release, err = s.debounce(ctx, rule.Debounce, object, request, response, options); object == nil || err != nil {
+			return err
+		}
+		defer release()
+	}
 	response.FileSize = object.Size()
+	if s.config.EvaluationTrace {
+		response.Evaluations = s.config.Mirrors.EvaluateObject(request.URL, object.Size())
+	}
+	response.SourceGeneration = objectGeneration(object, request)
+	if response.CorrelationID == "" {
+		response.CorrelationID = objectMetadata(object)[base.CorrelationIDKey]
+	}
+	if rule.OnEmpty != "" && rule.OnEmpty != config.EmptyMirror && config.IsPlaceholder(request.URL, object.Size()) {
+		return s.handleEmpty(ctx, rule, request, response)
+	}
+	if rule.Sidecar != nil {
+		if skip, err := s.handleSidecar(ctx, rule.Sidecar, request, response, options); skip || err != nil {
+			return err
+		}
+	}
+	response.Lane = s.lanes.config.Lane(rule.Priority, object.Size())
+	waited, release, err := s.lanes.acquire(ctx, response.Lane)
+	response.LaneWaitMs = int(waited / time.Millisecond)
+	debugf(rule, response, "source size: %v, lane: %v, lane wait: %vms", object.Size(), response.Lane, response.LaneWaitMs)
+	if err != nil {
+		return base.NewCodedError(base.ErrorCodeTransient, errors.Wrapf(err, "failed to acquire %v lane", response.Lane))
+	}
+	defer release()
+	var checksum *sidecarChecksum
+	if rule.Sidecar != nil {
+		var proceed bool
+		if checksum, proceed, err = s.verifySidecar(ctx, rule.Sidecar, request, response, options); !proceed || err != nil {
+			return err
+		}
+	}
+	digest := ""
+	if rule.Dedup != nil {
+		if digest, err = s.contentDigest(ctx, rule.Dedup, object, options); err != nil {
+			return err
+		}
+		if handled, err := s.checkDuplicate(ctx, rule.Dedup, digest, request, response, options); handled || err != nil {
+			return err
+		}
+	}
 	if rule.Source.Overflow != nil {
 		if rule.Source.Overflow.Size() < object.Size() {
 			return s.handleOverflow(ctx, object, rule.Source.Overflow, rule, request, response)
@@ -134,7 +287,9 @@ func (s *service) mirror(ctx context.Context, request *contract.Request, respons
 	if rule.DoneMarker != "" {
 		parentURL, _ := url.Split(request.URL, file.Scheme)
 		if object.Name() == rule.DoneMarker {
-			return s.replay(ctx, parentURL, rule.DoneMarker)
+			return s.replay(ctx, parentURL, func(object storage.Object) bool {
+				return object.Name() == rule.DoneMarker
+			})
 		}
 		//Check if marker file is present, otherwise delay transfer
 		markerURL := url.Join(parentURL, rule.DoneMarker)
@@ -144,6 +299,10 @@ func (s *service) mirror(ctx context.Context, request *contract.Request, respons
 		}
 	}
 
+	if err = s.checkPreflight(ctx, rule.Dest, object.Size(), response); err != nil {
+		return err
+	}
+
 	var streaming = &s.config.Streaming
 	if rule.Streaming != nil {
 		streaming = rule.Streaming
@@ -153,11 +312,34 @@ func (s *service) mirror(ctx context.Context, request *contract.Request, respons
 		response.StreamOption = option.NewStream(streaming.PartSize(), int(object.Size()))
 	}
 
-	err = s.mirrorAsset(ctx, rule, request.URL, response)
-	jobContent := job.NewContext(ctx, err, request.URL, response.Rule.Name(request.URL))
+	if rule.HasTransformer() {
+		response.AddEvent(eventlog.TypeTransformed)
+	}
+	err = s.mirrorAsset(ctx, rule, request, response, checksum)
+	debugf(rule, response, "mirrored to: %v, error: %v", response.DestURLs, err)
+	if err == nil && rule.Dedup != nil {
+		if e := s.recordDigest(ctx, rule.Dedup, digest, request, response); e != nil {
+			response.LogError = e.Error()
+		}
+	}
+	if err == nil && rule.Dest.Databricks != nil && rule.Dest.Databricks.JobID > 0 {
+		err = s.runDatabricksJob(ctx, rule, request, response)
+	}
+	if err == nil && rule.Inference != nil {
+		if e := s.inferSchema(ctx, rule, request, response, options); e != nil {
+			response.LogError = e.Error()
+		}
+	}
 	response.TimeTakenMs = int(time.Now().Sub(request.Timestamp) / time.Millisecond)
-	if e := rule.Actions.Run(jobContent, s.fs, s.notifier.Notify, &response.Rule.Info, response); e != nil && err == nil {
+	jobContent := newJobContext(ctx, err, request, response, object)
+	if request.SkipActions {
+		return err
+	}
+	actions := s.alertActions(ctx, rule, response, err)
+	if e := actions.Run(jobContent, s.fs, s.notifier.Notify, &response.Rule.Info, response); e != nil && err == nil {
 		err = e
+	} else if e == nil && len(actions.OnSuccess)+len(actions.OnFailure) > 0 {
+		response.AddEvent(eventlog.TypeNotified)
 	}
 	return err
 }
@@ -169,17 +351,31 @@ func (s *service) addStreamingOptions(options []storage.Option, streamOpt *optio
 	return options
 }
 
-func (s *service) mirrorAsset(ctx context.Context, rule *config.Rule, URL string, response *contract.Response) error {
+//mirrorAsset transfers source asset, with sidecar checksum source content is verified while it is transferred
+func (s *service) mirrorAsset(ctx context.Context, rule *config.Rule, request *contract.Request, response *contract.Response, checksum *sidecarChecksum) error {
+	URL := request.URL
 	transferStream := s.transferStream
 	if rule.Split != nil {
 		transferStream = s.transferChunkStream
 	}
 	options, err := s.secret.StorageOpts(ctx, rule.Source.CloneWithURL(URL))
+	if err == nil {
+		err = s.chaosFault(chaosSecret, URL)
+	}
 	if err != nil {
-		return errors.Wrapf(err, "failed to get storage option for %v", rule.Source)
+		return errors.Wrapf(base.NewCodedError(base.ErrorCodeAuth, err), "failed to get storage option for %v", rule.Source)
 	}
 	options = s.addStreamingOptions(options, response.StreamOption)
 	if rule.ShallArchiveWalk(URL) {
+		if checksum != nil { //archive entries are transferred, not the archive content
+			actual, err := s.checksum(ctx, checksum.algorithm, URL, options)
+			if err != nil {
+				return err
+			}
+			if err = checksum.verify(URL, actual, response); err != nil {
+				return err
+			}
+		}
 		archvieURL := rule.ArchiveWalkURL(URL)
 		return s.fs.Walk(ctx, archvieURL, func(ctx context.Context, baseURL string, parent string, info os.FileInfo, reader io.Reader) (toContinue bool, err error) {
 			if info.IsDir() {
@@ -191,29 +387,43 @@ func (s *service) mirrorAsset(ctx context.Context, rule *config.Rule, URL string
 			return err == nil, err
 		}, options...)
 	}
-	reader, err := s.fs.OpenURL(ctx, URL, options...)
+	var reader io.ReadCloser
+	if native.UsesRequesterPays(rule.Source) {
+		reader, err = native.Open(ctx, URL, request.Generation, options)
+	} else if request.Generation > 0 && url.Scheme(URL, "") == gs.Scheme {
+		reader, err = s.openGeneration(ctx, URL, request.Generation, options)
+	} else {
+		reader, err = s.fs.OpenURL(ctx, URL, options...)
+	}
 	if err != nil {
 		return errors.Wrapf(err, "failed to download source: %v", URL)
 	}
+	if checksum != nil {
+		reader = newChecksumVerifier(reader, checksum, URL, response)
+	}
 	defer func() {
 		_ = reader.Close()
 	}()
-	return transferStream(ctx, reader, URL, rule, response)
+	if err = transferStream(ctx, reader, URL, rule, response); err != nil && checksum != nil && base.ErrorCode(err) == base.ErrorCodeChecksum {
+		s.removeCorrupted(ctx, rule, response)
+	}
+	return err
 }
 
 func (s *service) transferStream(ctx context.Context, reader io.Reader, URL string, rule *config.Rule, response *contract.Response) (err error) {
+	reader = s.chaosReader(reader, URL)
 	reader, err = NewReader(rule, reader, response, URL)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create reader")
 	}
 	destName := rule.Name(URL)
-	baseDestURL, err := rule.Dest.ExpandURL(URL)
+	baseDestURL, err := rule.Dest.ExpandURL(URL, response.Captures)
 	if err != nil {
 		return errors.Wrapf(err, "failed to expanded URL")
 	}
 	destURL := url.Join(baseDestURL, destName)
 	destCompression := rule.Compression
-	if path.Ext(URL) == path.Ext(destURL) && !rule.HasTransformer() {
+	if response.GzipPassthrough || (path.Ext(URL) == path.Ext(destURL) && !rule.HasTransformer()) {
 		destCompression = nil
 	}
 
@@ -229,25 +439,83 @@ func (s *service) transferStream(ctx context.Context, reader io.Reader, URL stri
 }
 
 func (s *service) transferChunkStream(ctx context.Context, reader io.Reader, URL string, rule *config.Rule, response *contract.Response) (err error) {
+	reader = s.chaosReader(reader, URL)
 	reader, err = NewReader(rule, reader, response, URL)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create reader")
 	}
 	counter := int32(0)
 	waitGroup := &sync.WaitGroup{}
-	err = Split(reader, s.chunkWriter(ctx, URL, rule, &counter, waitGroup, response), rule)
+	var batcher *messageBatcher
+	var expired chan error
+	done := make(chan struct{})
+	if rule.Dest.MessageBatch != nil {
+		batcher = newMessageBatcher(rule.Dest)
+		batcher.envelope = manifestEnvelopeSize(response)
+		expired = s.publishExpired(ctx, batcher, response, done)
+	}
+	err = Split(reader, s.chunkWriter(ctx, URL, rule, &counter, waitGroup, batcher, response), rule)
 	if err == nil {
 		waitGroup.Wait()
+		response.SetValue(contract.ValueSplitCount, int(atomic.LoadInt32(&counter)))
+	}
+	if batcher != nil {
+		close(done)
+		if expiredErr := <-expired; err == nil {
+			err = expiredErr
+		}
+	}
+	if err == nil && batcher != nil {
+		err = s.publishManifests(ctx, batcher.flush(), response)
 	}
 	return err
 }
 
-func (s *service) transfer(ctx context.Context, transfer *Transfer, response *contract.Response) error {
+func (s *service) transfer(ctx context.Context, transfer *Transfer, response *contract.Response) (err error) {
+	defer func() {
+		response.AddOutcome(transfer.DestURL(), transfer.Bytes(), err)
+	}()
+	if policy := s.config.Policy; policy != nil {
+		if err = policy.Check(transfer.Resource, transfer.DestURL()); err != nil {
+			shared.LogF("policy violation: %v\n", err)
+			return base.NewCodedError(base.ErrorCodePolicy, err)
+		}
+	}
+	if err = s.chaosFault(chaosUpload, transfer.DestURL()); err != nil {
+		return err
+	}
 	if transfer.Resource.Topic != "" || transfer.Resource.Queue != "" {
 		return s.publish(ctx, transfer, response)
 	}
+	if transfer.Resource.Databricks != nil {
+		return s.uploadDatabricks(ctx, transfer, response)
+	}
+	if transfer.Resource.Redis != nil {
+		return s.addRedis(ctx, transfer, response)
+	}
+	if transfer.Resource.NATS != nil {
+		return s.publishNATS(ctx, transfer, response)
+	}
+	if transfer.Resource.MQTT != nil {
+		return s.publishMQTT(ctx, transfer, response)
+	}
+	if transfer.Resource.Database != nil {
+		return s.loadDatabase(ctx, transfer, response)
+	}
+	if transfer.Resource.Elasticsearch != nil {
+		return s.indexElasticsearch(ctx, transfer, response)
+	}
+	if transfer.Resource.ClickHouse != nil {
+		return s.insertClickHouse(ctx, transfer, response)
+	}
+	if transfer.Resource.Sheets != nil {
+		return s.writeSheets(ctx, transfer, response)
+	}
+	if transfer.Resource.IsWebDAV() {
+		return s.uploadWebDAV(ctx, transfer, response)
+	}
 	if transfer.Resource.URL != "" {
-		err := s.upload(ctx, transfer, response)
+		err = s.upload(ctx, transfer, response)
 		if base.IsSchemaError(err) {
 			response.SchemaError = err.Error()
 		}
@@ -269,16 +537,28 @@ func (s *service) publish(ctx context.Context, transfer *Transfer, response *con
 	if err != nil {
 		return err
 	}
+	if data, err = s.claimCheck(ctx, transfer, data, response); err != nil {
+		return err
+	}
+	if transfer.Resource.Azure != nil {
+		return s.publishAzure(ctx, transfer, data, response)
+	}
+	if transfer.batcher != nil {
+		return s.publishBatched(ctx, transfer, data, response)
+	}
+	return s.publishMessage(ctx, transfer.MessageDest(), transfer.Dest.URL, data, response)
+}
 
+//publishMessage publishes data to topic or queue, source is passed as message attribute
+func (s *service) publishMessage(ctx context.Context, dest, source string, data []byte, response *contract.Response) error {
 	switch s.msgbusVendor {
 	case shared.VendorPubsub, shared.VendorSQS:
 		attributes := make(map[string]interface{})
-		attributes[base.SourceAttribute] = transfer.Dest.URL
-		dest := transfer.Resource.Topic
-		if dest == "" {
-			dest = transfer.Resource.Queue
+		attributes[base.SourceAttribute] = source
+		attributes[base.TransferIDKey] = response.TransferID
+		if response.CorrelationID != "" {
+			attributes[base.CorrelationIDKey] = response.CorrelationID
 		}
-		dest = strings.Replace(dest, "$partition", transfer.partition, 1)
 		pubResponse, err := s.msgbus.Publish(ctx, &msgbus.Request{
 			Dest:       dest,
 			Data:       data,
@@ -286,7 +566,7 @@ func (s *service) publish(ctx context.Context, transfer *Transfer, response *con
 		})
 		if err != nil {
 			if IsNotFound(err.Error()) {
-				return errors.Errorf("failed to publish data, no such topic: %v", transfer.Resource.Topic)
+				return errors.Errorf("failed to publish data, no such topic: %v", dest)
 			}
 			return err
 		}
@@ -302,23 +582,81 @@ func (s *service) upload(ctx context.Context, transfer *Transfer, response *cont
 		return errors.Wrapf(err, "failed to get reader for: %v", transfer.Resource.URL)
 	}
 	options, err := s.secret.StorageOpts(ctx, transfer.Resource)
+	if err == nil {
+		err = s.chaosFault(chaosSecret, transfer.Dest.URL)
+	}
 	if err != nil {
+		return base.NewCodedError(base.ErrorCodeAuth, err)
+	}
+	if err = s.checkRetention(ctx, transfer, options); err != nil {
 		return err
 	}
 	if transfer.skipChecksum {
 		options = append(options, option.NewSkipChecksum(true))
 		if stream := transfer.stream; stream != nil && stream.PartSizeMb > 0 {
-			options = append(options, option.NewStream(stream.PartSize(), int(response.FileSize)))
+			partSize := stream.PartSize()
+			if stream.Adaptive != nil {
+				partSize = s.adaptivePartSize(ctx, stream.Adaptive, transfer.Dest.URL, partSize, response)
+			}
+			options = append(options, option.NewStream(partSize, int(response.FileSize)))
 		}
 	}
+	options = append(options, lineageMeta(response, s.config.PipelineVersion))
 	if rule := transfer.rule; rule != nil && rule.AllowEmpty {
 		options = append(options, option.NewEmpty(rule.AllowEmpty))
 	}
-	writer, err := s.fs.NewWriter(ctx, transfer.Dest.URL, file.DefaultFileOsMode, options...)
+	if transfer.Resource.Append != nil {
+		waited, err := s.limiter.Wait(ctx, transfer.Dest.URL)
+		response.AddThrottleTime(waited)
+		if err != nil {
+			return err
+		}
+		err = s.appendTransfer(ctx, transfer, reader, options, response)
+		s.limiter.Report(transfer.Dest.URL, err)
+		return err
+	}
+	if delta := transfer.Resource.Delta; delta != nil && response.FileSize >= delta.MinSize() {
+		waited, err := s.limiter.Wait(ctx, transfer.Dest.URL)
+		response.AddThrottleTime(waited)
+		if err != nil {
+			return err
+		}
+		err = s.deltaTransfer(ctx, transfer, reader, options, response)
+		s.limiter.Report(transfer.Dest.URL, err)
+		return err
+	}
+	writeURL := transfer.Dest.URL
+	layout := transfer.Resource.ContentAddressed
+	if layout != nil {
+		writeURL = layout.TempURL(transfer.Resource, uuid.New().String())
+	}
+	waited, err := s.limiter.Wait(ctx, writeURL)
+	response.AddThrottleTime(waited)
+	if err != nil {
+		return err
+	}
+	started := time.Now()
+	var writer io.WriteCloser
+	if usesNativeUpload(transfer.Resource) {
+		var native *nativeWriter
+		if native, err = newNativeWriter(ctx, writeURL, options); err == nil {
+			defer native.abort()
+			writer = native
+		}
+	} else {
+		writer, err = s.fs.NewWriter(ctx, writeURL, file.DefaultFileOsMode, options...)
+	}
 	if err != nil {
+		s.limiter.Report(writeURL, err)
 		return err
 	}
-	response.AddURL(transfer.Dest.URL)
+	var digester *digestWriter
+	if layout != nil {
+		digester = &digestWriter{WriteCloser: writer, hash: layout.NewHash()}
+		writer = digester
+	} else {
+		response.AddURL(transfer.Dest.URL)
+	}
 	if transfer.Dest.CompressionCodec() == config.GZipCodec {
 		gzipWriter := gzip.NewWriter(writer)
 		if _, err = io.Copy(gzipWriter, reader); err != nil {
@@ -334,16 +672,30 @@ func (s *service) upload(ctx context.Context, transfer *Transfer, response *cont
 		}
 	}
 	err = writer.Close()
-	if err != nil {
-		//if errors mirroring delete dest corrupted transfer
-		s.fs.Delete(ctx, transfer.Dest.URL)
+	s.limiter.Report(writeURL, err)
+	if err == nil && response.PartSizing != nil {
+		s.partSizer.observe(writeURL, transfer.Bytes(), time.Since(started))
+	}
+	if err != nil && transfer.Resource.Retention == nil {
+		//if errors mirroring delete dest corrupted transfer, retained objects can not be deleted
+		s.fs.Delete(ctx, writeURL, options...)
+	}
+	if err == nil && digester != nil {
+		err = s.storeContent(ctx, transfer, writeURL, digester, options, response)
 	}
 	return err
 }
 
 //Load initialises this service
 func (s *service) Init(ctx context.Context) error {
-	return s.config.Init(ctx, s.cfs)
+	s.config.Mirrors.OnReload(config.LogRuleFiles)
+	if err := s.config.Init(ctx, s.cfs); err != nil {
+		return err
+	}
+	if s.config.Poison != nil {
+		s.initActions(s.config.Poison.OnPoison)
+	}
+	return nil
 }
 
 func (s *service) initActions(actions []*job.Action) {
@@ -354,21 +706,92 @@ func (s *service) initActions(actions []*job.Action) {
 		if actions[i].Action == job.ActionNotify && actions[i].Credentials == nil {
 			actions[i].Credentials = s.config.SlackCredentials
 		}
+		if actions[i].BigQuery != nil && actions[i].BigQuery.ProjectID == "" {
+			actions[i].BigQuery.ProjectID = s.config.ProjectID
+		}
+		if requeue := actions[i].Requeue; requeue != nil && requeue.ProjectID == "" {
+			requeue.ProjectID = s.config.ProjectID
+		}
+		if invoke := actions[i].Invoke; invoke != nil {
+			if invoke.ProjectID == "" {
+				invoke.ProjectID = s.config.ProjectID
+			}
+			if invoke.Region == "" {
+				invoke.Region = s.config.Region
+			}
+		}
 	}
 }
 
-//initRule updates resources
-func (s *service) initRule(ctx context.Context, rule *config.Rule) (err error) {
+//ruleInit represents rule initialisation state
+type ruleInit struct {
+	mux  sync.Mutex
+	done bool
+}
+
+//initRule initialises rule once, rule resources are updated before the rule is used by concurrent events
+func (s *service) initRule(ctx context.Context, rule *config.Rule) error {
+	value, _ := s.ruleInits.LoadOrStore(rule, &ruleInit{})
+	state := value.(*ruleInit)
+	state.mux.Lock()
+	defer state.mux.Unlock()
+	if state.done {
+		return nil
+	}
+	if err := s.updateRule(ctx, rule); err != nil {
+		return err
+	}
+	state.done = true
+	return nil
+}
+
+//retainRuleInits removes initialisation state of rules no longer published with rules snapshot
+func (s *service) retainRuleInits() {
+	active := make(map[*config.Rule]bool)
+	for _, rule := range s.config.Mirrors.Snapshot() {
+		active[rule] = true
+	}
+	s.ruleInits.Range(func(key, value interface{}) bool {
+		if !active[key.(*config.Rule)] {
+			s.ruleInits.Delete(key)
+		}
+		return true
+	})
+}
+
+//updateRule updates rule resources
+func (s *service) updateRule(ctx context.Context, rule *config.Rule) (err error) {
+	s.initMux.Lock()
+	defer s.initMux.Unlock()
+	if err = checkSecretScope(rule, s.config.SecretScopes, s.config.ImpersonationScopes); err != nil {
+		return base.NewCodedError(base.ErrorCodeConfig, err)
+	}
+	if policy := s.config.Policy; policy != nil {
+		if err = policy.CheckRule(rule); err != nil {
+			shared.LogF("policy violation: %v\n", err)
+			return base.NewCodedError(base.ErrorCodePolicy, err)
+		}
+	}
+	for _, resource := range []*config.Resource{rule.Source, rule.Dest} {
+		if resource != nil && resource.RequesterPays && resource.ProjectID == "" {
+			resource.ProjectID = s.config.ProjectID
+		}
+	}
 	resources := rule.Resources()
 	s.initActions(rule.OnSuccess)
 	s.initActions(rule.OnFailure)
 	if len(resources) > 0 {
 		if err = s.secret.Init(ctx, s.fs, resources); err != nil {
-			return errors.Wrap(err, "failed to init resource secrets")
+			return errors.Wrap(base.NewCodedError(base.ErrorCodeAuth, err), "failed to init resource secrets")
+		}
+	}
+	if rule.Dest != nil && rule.Dest.HasEncryptionKey() {
+		if err = s.secret.CheckKeys(ctx, []*config.Resource{rule.Dest}); err != nil {
+			return errors.Wrapf(base.NewCodedError(base.ErrorCodeConfig, err), "rule %v dest encryption key is not accessible", rule.Info.URL)
 		}
 	}
 
-	if s.config.UseMessageDest() && s.msgbus == nil {
+	if s.config.UseMessageDest() && s.msgbus == nil && rule.Dest.Azure == nil {
 		if rule.Dest.Vendor == "" {
 			switch s.config.SourceScheme {
 			case gs.Scheme:
@@ -406,12 +829,12 @@ func (s *service) initRule(ctx context.Context, rule *config.Rule) (err error) {
 	return nil
 }
 
-func (s *service) chunkWriter(ctx context.Context, URL string, rule *config.Rule, counter *int32, waitGroup *sync.WaitGroup, response *contract.Response) func(partition interface{}) io.WriteCloser {
+func (s *service) chunkWriter(ctx context.Context, URL string, rule *config.Rule, counter *int32, waitGroup *sync.WaitGroup, batcher *messageBatcher, response *contract.Response) func(partition interface{}) io.WriteCloser {
 	return func(partition interface{}) io.WriteCloser {
 		splitCounter := atomic.AddInt32(counter, 1)
 		destName := rule.Split.Name(rule, URL, splitCounter, partition)
 		return NewWriter(rule, func(writer *Writer) error {
-			baseDestURL, err := rule.Dest.ExpandURL(URL)
+			baseDestURL, err := rule.Dest.ExpandURL(URL, response.Captures)
 			if err != nil {
 				return fmt.Errorf("failed to expand URL due to %w", err)
 			}
@@ -430,13 +853,14 @@ func (s *service) chunkWriter(ctx context.Context, URL string, rule *config.Rule
 				Resource:     rule.Dest,
 				Reader:       writer.Reader,
 				Dest:         NewDatafile(destURL, nil),
+				batcher:      batcher,
 			}
 			return s.transfer(ctx, dataCopy, response)
 		})
 	}
 }
 
-func (s *service) replay(ctx context.Context, parentURL, doneMarker string) error {
+func (s *service) replay(ctx context.Context, parentURL string, skip func(object storage.Object) bool) error {
 	objects, err := s.fs.List(ctx, parentURL)
 	if err != nil {
 		return err
@@ -444,7 +868,7 @@ func (s *service) replay(ctx context.Context, parentURL, doneMarker string) erro
 	replayer := base.NewReplayer(s.fs)
 	replayer.Run(ctx, 5)
 	for _, object := range objects {
-		if object.IsDir() || object.Name() == doneMarker {
+		if object.IsDir() || skip(object) {
 			continue
 		}
 		replayer.Schedule(object.URL())
@@ -452,6 +876,49 @@ func (s *service) replay(ctx context.Context, parentURL, doneMarker string) erro
 	return replayer.Wait()
 }
 
+//handleEmpty skips zero-byte or placeholder object, or treats it as parent folder completion signal
+func (s *service) handleEmpty(ctx context.Context, rule *config.Rule, request *contract.Request, response *contract.Response) error {
+	if rule.OnEmpty == config.EmptySkip {
+		response.Status = base.StatusEmpty
+		return nil
+	}
+	response.Status = base.StatusCompletion
+	parentURL, _ := url.Split(request.URL, file.Scheme)
+	return s.replay(ctx, parentURL, func(object storage.Object) bool {
+		return config.IsPlaceholder(object.URL(), object.Size())
+	})
+}
+
+//persistResponse stores final response JSON with response log partitioned by date, rule and status
+func (s *service) persistResponse(ctx context.Context, response *contract.Response) {
+	responseLog := s.config.ResponseLog
+	if responseLog == nil || responseLog.IsLogged(response.TriggeredBy) {
+		return
+	}
+	partition := ""
+	if response.Rule != nil {
+		partition = response.Rule.Info.Workflow
+		if partition == "" {
+			response.RuleURL = response.Rule.Info.URL
+		}
+	}
+	if partition == "" && response.RuleURL != "" {
+		partition = ruleFilePartition(response.RuleURL)
+	}
+	response.Redact()
+	logged := *response
+	logged.Rule = nil
+	if _, err := responseLog.Persist(ctx, s.fs, partition, response.Status, response.TransferID, &logged); err != nil {
+		response.LogError = shared.Redact(err.Error())
+	}
+}
+
+//ruleFilePartition returns response log partition of rule without workflow name
+func ruleFilePartition(ruleURL string) string {
+	_, name := url.Split(ruleURL, file.Scheme)
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
 func (s *service) logResponse(ctx context.Context, response *contract.Response) {
 	if response.Rule != nil {
 		response.RuleURL = response.Rule.Info.URL
@@ -462,6 +929,7 @@ func (s *service) logResponse(ctx context.Context, response *contract.Response)
 		return
 	}
 	response.Rule = nil
+	response.Redact()
 	JSON, err := json.Marshal(response)
 	if err != nil {
 		response.LogError = err.Error()
@@ -505,9 +973,12 @@ func (s *service) handleOverflow(ctx context.Context, object storage.Object, ove
 		return err
 	}
 	response.MessageIDs = output.MessageIDs
-	jobContent := job.NewContext(ctx, err, request.URL, response.Rule.Name(request.URL))
 	response.TimeTakenMs = int(time.Now().Sub(request.Timestamp) / time.Millisecond)
-	if e := rule.Actions.Run(jobContent, s.fs, s.notifier.Notify, &response.Rule.Info, response); e != nil && err == nil {
+	jobContent := newJobContext(ctx, err, request, response, object)
+	if request.SkipActions {
+		return err
+	}
+	if e := s.alertActions(ctx, rule, response, err).Run(jobContent, s.fs, s.notifier.Notify, &response.Rule.Info, response); e != nil && err == nil {
 		err = e
 	}
 	return err
@@ -527,18 +998,27 @@ func (s *service) overflowBusService(ctx context.Context, overflow *config.Overf
 //NewSlack creates a new mirror service
 func New(ctx context.Context, config *Config) (Service, error) {
 	cfs := cache.Singleton(config.URL)
+	fs := newClients()
+	config.Mirrors.OnDecrypt(secret.NewDecrypter(fs))
 	err := config.Init(ctx, cfs)
 	if err != nil {
 		return nil, err
 	}
-	fs := afs.New()
+	tuneTransport(&config.Connections)
+	if len(config.Tenants) > 0 {
+		return newTenantRouter(ctx, config)
+	}
 	secretService := secret.New(config.SourceScheme, fs)
 	result := &service{config: config,
-		fs:       fs,
-		cfs:      cfs,
-		mux:      &sync.Mutex{},
-		secret:   secretService,
-		notifier: slack.NewSlack(config.Region, config.ProjectID, fs, secretService, config.SlackCredentials),
+		fs:        native.New(fs),
+		cfs:       cfs,
+		mux:       &sync.Mutex{},
+		secret:    secretService,
+		limiter:   throttle.New(&config.RateLimit),
+		lanes:     newLanes(config.Lanes),
+		partSizer: newPartSizer(),
+		clients:   fs,
+		notifier:  slack.NewSlack(config.Region, config.ProjectID, fs, secretService, config.SlackCredentials),
 	}
 	return result, result.Init(ctx)
 }
@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"fmt"
 	"github.com/viant/smirror/base"
 	"github.com/viant/smirror/config"
 	"github.com/viant/smirror/contract"
 	"github.com/viant/smirror/job"
+	"github.com/viant/smirror/secret"
 	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
 	"github.com/viant/afs/matcher"
 	"github.com/viant/afs/mem"
 	"github.com/viant/afs/storage"
@@ -580,3 +583,43 @@ func initUseCase(ctx context.Context, useCase *serviceUseCase, memStorage storag
 	err := memStorage.Upload(ctx, useCase.sourceURL, 0644, sourceReader)
 	assert.Nil(t, err, useCase.description)
 }
+
+//keyCheckSecret reports all resources encryption keys as inaccessible
+type keyCheckSecret struct {
+	secret.Service
+}
+
+func (s *keyCheckSecret) CheckKeys(ctx context.Context, resources []*config.Resource) error {
+	return fmt.Errorf("key %v is disabled", resources[0].KMSKeyARN)
+}
+
+func TestService_MirrorEncryptionKeyCheck(t *testing.T) {
+	ctx := context.Background()
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/enc/valid/"}},
+				Dest:   &config.Resource{URL: "mem://localhost/enc/dest1"},
+			},
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/enc/invalid/"}},
+				Dest:   &config.Resource{URL: "mem://localhost/enc/dest2", KMSKeyARN: "arn:aws:kms:us-east-1:111111111111:key/disabled"},
+			},
+		}},
+	}
+	mirrorService, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	srv := mirrorService.(*service)
+	srv.secret = &keyCheckSecret{Service: srv.secret}
+	fs := afs.New()
+	for _, URL := range []string{"mem://localhost/enc/valid/data.csv", "mem://localhost/enc/invalid/data.csv"} {
+		_ = fs.Upload(ctx, URL, 0644, strings.NewReader("line1\n"))
+	}
+	response := mirrorService.Mirror(ctx, contract.NewRequest("mem://localhost/enc/invalid/data.csv"))
+	assert.Equal(t, base.StatusError, response.Status)
+	assert.Equal(t, base.ErrorCodeConfig, response.ErrorCode)
+	response = mirrorService.Mirror(ctx, contract.NewRequest("mem://localhost/enc/valid/data.csv"))
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+}
@@ -6,4 +6,8 @@ const (
 	VendorPubsub = "pubsub"
 	//VendorSQS sqs
 	VendorSQS = "sqs"
+	//VendorEventGrid Azure Event Grid topic
+	VendorEventGrid = "eventgrid"
+	//VendorServiceBus Azure Service Bus queue or topic
+	VendorServiceBus = "servicebus"
 )
@@ -16,6 +16,10 @@ const (
 	LoggingLevelInfo = "info"
 	//LoggingLevelDebug debug logging leve
 	LoggingLevelDebug = "debug"
+	//LoggingLevelError only failed responses are logged
+	LoggingLevelError = "error"
+	//LoggingLevelOff responses are not logged
+	LoggingLevelOff = "off"
 	//LoggingProgressChar progress char
 	LoggingProgressChar = "."
 	//LoggingProgressLineSize line size
@@ -35,6 +39,19 @@ func IsInfoLoggingLevel() bool {
 	return isLoggingLevel(LoggingEnvKey, LoggingLevelInfo)
 }
 
+//LoggingLevel returns LOGGING env variable level: debug (or true), info, error, off (or false), info by default
+func LoggingLevel() string {
+	switch strings.ToLower(os.Getenv(LoggingEnvKey)) {
+	case "true", LoggingLevelDebug:
+		return LoggingLevelDebug
+	case LoggingLevelError:
+		return LoggingLevelError
+	case "false", LoggingLevelOff:
+		return LoggingLevelOff
+	}
+	return LoggingLevelInfo
+}
+
 //isLoggingLevel returns true if logging is enabled
 func isLoggingLevel(key string, value string) bool {
 	return strings.ToLower(os.Getenv(key)) == value
@@ -57,7 +74,7 @@ func LogF(template string, params ...interface{}) {
 	if lastLogMessage == LoggingProgressChar {
 		fmt.Print("\n")
 	}
-	message := fmt.Sprintf(template, params...)
+	message := Redact(fmt.Sprintf(template, params...))
 	lastLogMessage = message
 	fmt.Print(message)
 }
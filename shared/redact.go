@@ -0,0 +1,124 @@
+package shared
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//RedactedMask replaces redacted secret values
+const RedactedMask = "***"
+
+//minSecretLength shorter resolved values are not registered, to avoid masking common words
+const minSecretLength = 8
+
+var secretPatterns = []struct {
+	expr        *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), RedactedMask},
+	{regexp.MustCompile(`(?i)\b(authorization|proxy-authorization|x-api-key|api-key|x-clickhouse-key|x-auth-token)(["']?\s*[:=]\s*["']?)((?:bearer|basic|digest|apikey|token)\s+)?[^\s"',;]+`), "${1}${2}${3}" + RedactedMask},
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]{8,}=*`), "${1} " + RedactedMask},
+	{regexp.MustCompile(`(?i)("(?:password|passwd|secret|token|access_?token|refresh_?token|client_?secret|api_?key|private_?key|secret_?access_?key|session_?token)"\s*:\s*")[^"]*(")`), "${1}" + RedactedMask + "${2}"},
+	{regexp.MustCompile(`(?i)\b(password|passwd|pwd|secret|token|access_token|refresh_token|client_secret|api_?key|sig|signature|x-amz-signature|x-amz-security-token|x-amz-credential|x-goog-signature|x-goog-credential)=[^\s&;,"']+`), "${1}=" + RedactedMask},
+	{regexp.MustCompile(`(://[^/\s:@]+):[^/\s@]+@`), "${1}:" + RedactedMask + "@"},
+	{regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), RedactedMask},
+}
+
+//nonSecretFields JSON secret fields describing rather than authenticating identity (i.e. service account key metadata)
+var nonSecretFields = map[string]bool{
+	"type":                        true,
+	"project_id":                  true,
+	"client_email":                true,
+	"client_id":                   true,
+	"auth_uri":                    true,
+	"token_uri":                   true,
+	"auth_provider_x509_cert_url": true,
+	"client_x509_cert_url":        true,
+	"universe_domain":             true,
+	"region":                      true,
+}
+
+var secrets = &secretRegistry{values: map[string]bool{}}
+
+type secretRegistry struct {
+	mux      sync.RWMutex
+	values   map[string]bool
+	replacer *strings.Replacer
+}
+
+func (r *secretRegistry) add(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minSecretLength {
+		return
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if r.values[value] {
+		return
+	}
+	r.values[value] = true
+	//longer values first, so that a secret containing other secret is fully masked
+	values := make([]string, 0, len(r.values))
+	for candidate := range r.values {
+		values = append(values, candidate)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	pairs := make([]string, 0, 2*len(values))
+	for _, candidate := range values {
+		pairs = append(pairs, candidate, RedactedMask)
+	}
+	r.replacer = strings.NewReplacer(pairs...)
+}
+
+func (r *secretRegistry) replace(text string) string {
+	r.mux.RLock()
+	replacer := r.replacer
+	r.mux.RUnlock()
+	if replacer == nil {
+		return text
+	}
+	return replacer.Replace(text)
+}
+
+//RegisterSecret registers value resolved through secret service, JSON secret string fields are registered individually
+func RegisterSecret(data []byte) {
+	secrets.add(string(data))
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err == nil {
+		registerSecretFields(decoded)
+	}
+}
+
+func registerSecretFields(value interface{}) {
+	switch actual := value.(type) {
+	case string:
+		if !strings.Contains(actual, "://") {
+			secrets.add(actual)
+		}
+	case map[string]interface{}:
+		for key, item := range actual {
+			if !nonSecretFields[strings.ToLower(key)] {
+				registerSecretFields(item)
+			}
+		}
+	case []interface{}:
+		for _, item := range actual {
+			registerSecretFields(item)
+		}
+	}
+}
+
+//Redact masks registered secret values and known secret patterns (auth headers, tokens, URL passwords, private keys)
+func Redact(text string) string {
+	if text == "" {
+		return text
+	}
+	text = secrets.replace(text)
+	for _, pattern := range secretPatterns {
+		text = pattern.expr.ReplaceAllString(text, pattern.replacement)
+	}
+	return text
+}
@@ -0,0 +1,94 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"google.golang.org/api/option"
+	"google.golang.org/api/sheets/v4"
+	"io"
+	"io/ioutil"
+)
+
+//sheetsOptions additional sheets client options
+var sheetsOptions []option.ClientOption
+
+func newSheetsService(ctx context.Context, resource *config.Resource) (*sheets.Service, error) {
+	options := append([]option.ClientOption{option.WithScopes(sheets.SpreadsheetsScope)}, sheetsOptions...)
+	if resource.Credentials != nil && len(resource.Credentials.Auth) > 0 {
+		options = append(options, option.WithCredentialsJSON(resource.Credentials.Auth))
+	}
+	return sheets.NewService(ctx, options...)
+}
+
+//writeSheets appends or replaces Google Sheet rows with small CSV
+func (s *service) writeSheets(ctx context.Context, transfer *Transfer, response *contract.Response) error {
+	settings := transfer.Resource.Sheets
+	reader, err := transfer.GetReader()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get reader for: %v", transfer.Resource.URL)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(reader, int64(settings.MaxBytes)+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > settings.MaxBytes {
+		return errors.Errorf("sheets destination supports files up to %v bytes", settings.MaxBytes)
+	}
+	rows, err := sheetRows(data, settings)
+	if err != nil {
+		return err
+	}
+	service, err := newSheetsService(ctx, transfer.Resource)
+	if err != nil {
+		return base.NewCodedError(base.ErrorCodeAuth, err)
+	}
+	waited, err := s.limiter.Wait(ctx, settings.SpreadsheetID)
+	response.AddThrottleTime(waited)
+	if err != nil {
+		return err
+	}
+	values := &sheets.ValueRange{Values: rows}
+	switch settings.Mode {
+	case config.SheetsModeReplace:
+		if _, err = service.Spreadsheets.Values.Clear(settings.SpreadsheetID, settings.Range(), &sheets.ClearValuesRequest{}).Context(ctx).Do(); err == nil && len(rows) > 0 {
+			_, err = service.Spreadsheets.Values.Update(settings.SpreadsheetID, settings.Range()+"!A1", values).ValueInputOption(settings.ValueInputOption()).Context(ctx).Do()
+		}
+	default:
+		if len(rows) > 0 {
+			_, err = service.Spreadsheets.Values.Append(settings.SpreadsheetID, settings.Range()+"!A1", values).ValueInputOption(settings.ValueInputOption()).InsertDataOption("INSERT_ROWS").Context(ctx).Do()
+		}
+	}
+	if err != nil {
+		s.limiter.Report(settings.SpreadsheetID, err)
+		return errors.Wrapf(err, "failed to %v sheet %v rows: %v", settings.Mode, settings.SpreadsheetID, settings.Sheet)
+	}
+	response.IncrementValue(contract.ValueLoadedRows, len(rows))
+	return nil
+}
+
+//sheetRows returns CSV rows, header is skipped in append mode
+func sheetRows(data []byte, settings *config.Sheets) ([][]interface{}, error) {
+	csvReader := csv.NewReader(bytes.NewReader(data))
+	csvReader.Comma = []rune(settings.Delimiter)[0]
+	csvReader.FieldsPerRecord = -1
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read CSV")
+	}
+	if settings.Header && settings.Mode == config.SheetsModeAppend && len(records) > 0 {
+		records = records[1:]
+	}
+	var rows = make([][]interface{}, len(records))
+	for i, record := range records {
+		rows[i] = make([]interface{}, len(record))
+		for j, value := range record {
+			rows[i][j] = value
+		}
+	}
+	return rows, nil
+}
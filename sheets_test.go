@@ -0,0 +1,95 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"google.golang.org/api/option"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestService_Sheets(t *testing.T) {
+	var calls []string
+	var values [][]string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		calls = append(calls, request.Method+" "+request.URL.Path)
+		body := struct{ Values [][]string }{}
+		_ = json.NewDecoder(request.Body).Decode(&body)
+		if body.Values != nil {
+			values = body.Values
+		}
+		_, _ = writer.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	sheetsOptions = []option.ClientOption{option.WithEndpoint(server.URL + "/"), option.WithoutAuthentication()}
+	defer func() { sheetsOptions = nil }()
+
+	var useCases = []struct {
+		description string
+		mode        string
+		data        string
+		expectCalls []string
+		expect      [][]string
+		expectError bool
+	}{
+		{
+			description: "append skips header",
+			mode:        config.SheetsModeAppend,
+			data:        "partner,total\nacme,10\nglobex,20\n",
+			expectCalls: []string{"POST /v4/spreadsheets/sheet-1/values/'Daily'!A1:append"},
+			expect:      [][]string{{"acme", "10"}, {"globex", "20"}},
+		},
+		{
+			description: "replace clears sheet",
+			mode:        config.SheetsModeReplace,
+			data:        "partner,total\nacme,10\n",
+			expectCalls: []string{"POST /v4/spreadsheets/sheet-1/values/'Daily':clear", "PUT /v4/spreadsheets/sheet-1/values/'Daily'!A1"},
+			expect:      [][]string{{"partner", "total"}, {"acme", "10"}},
+		},
+		{
+			description: "file over size cap",
+			mode:        config.SheetsModeAppend,
+			data:        strings.Repeat("x,1\n", 100),
+			expectError: true,
+		},
+	}
+
+	ctx := context.Background()
+	for _, useCase := range useCases {
+		calls, values = nil, nil
+		cfg := &Config{
+			Mirrors: config.Ruleset{Rules: []*config.Rule{
+				{
+					Source: &config.Resource{Basic: matcher.Basic{Prefix: "/sheets/data"}},
+					Dest: &config.Resource{
+						Sheets: &config.Sheets{SpreadsheetID: "sheet-1", Sheet: "Daily", Mode: useCase.mode, Header: true, MaxBytes: 128},
+					},
+				},
+			}},
+		}
+		service, err := New(ctx, cfg)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		sourceURL := "mem://localhost/sheets/data/summary.csv"
+		_ = afs.New().Upload(ctx, sourceURL, 0644, strings.NewReader(useCase.data))
+		response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+		if useCase.expectError {
+			assert.Equal(t, base.StatusError, response.Status, useCase.description)
+			continue
+		}
+		if !assert.Equal(t, base.StatusOK, response.Status, useCase.description+" "+response.Error) {
+			continue
+		}
+		assert.Equal(t, useCase.expectCalls, calls, useCase.description)
+		assert.Equal(t, useCase.expect, values, useCase.description)
+	}
+}
@@ -0,0 +1,149 @@
+package smirror
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/option"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/digest"
+	"hash"
+	"io"
+	"path"
+)
+
+//handleSidecar handles sidecar object arrival, it returns true if sidecar should not be mirrored
+func (s *service) handleSidecar(ctx context.Context, sidecar *config.Sidecar, request *contract.Request, response *contract.Response, options []storage.Option) (bool, error) {
+	dataURL, ok := sidecar.DataURL(request.URL)
+	if !ok {
+		return false, nil
+	}
+	if sidecar.Required {
+		if exists, _ := s.fs.Exists(ctx, dataURL, append(options, option.NewObjectKind(true))...); exists {
+			replayer := base.NewReplayer(s.fs)
+			replayer.Run(ctx, 1)
+			replayer.Schedule(dataURL)
+			if err := replayer.Wait(); err != nil {
+				return false, errors.Wrapf(err, "failed to re-trigger %v", dataURL)
+			}
+		}
+	}
+	if sidecar.SkipSidecar {
+		response.Status = base.StatusSidecar
+		return true, nil
+	}
+	return false, nil
+}
+
+//sidecarChecksum represents expected source checksum read from sidecar
+type sidecarChecksum struct {
+	algorithm string
+	expected  string
+}
+
+//verifySidecar reads expected source checksum from sidecar, it returns false if required sidecar is not yet present, nil checksum means no verification
+func (s *service) verifySidecar(ctx context.Context, sidecar *config.Sidecar, request *contract.Request, response *contract.Response, options []storage.Option) (*sidecarChecksum, bool, error) {
+	if _, ok := sidecar.DataURL(request.URL); ok {
+		return nil, true, nil
+	}
+	for _, sidecarURL := range sidecar.SidecarURLs(request.URL) {
+		if exists, _ := s.fs.Exists(ctx, sidecarURL, append(options, option.NewObjectKind(true))...); !exists {
+			continue
+		}
+		ext := path.Ext(sidecarURL)
+		content, err := s.fs.DownloadWithURL(ctx, sidecarURL, options...)
+		if err != nil {
+			return nil, false, errors.Wrapf(err, "failed to download sidecar %v", sidecarURL)
+		}
+		expected, err := config.ExpectedChecksum(ext, content, request.URL)
+		if err != nil {
+			return nil, false, base.NewCodedError(base.ErrorCodeChecksum, errors.Wrapf(err, "invalid sidecar %v", sidecarURL))
+		}
+		response.SidecarURL = sidecarURL
+		return &sidecarChecksum{algorithm: config.SidecarAlgorithm(ext), expected: expected}, true, nil
+	}
+	if sidecar.Required {
+		response.Status = base.StatusPartial
+		return nil, false, nil
+	}
+	return nil, true, nil
+}
+
+//verify checks source content digest against expected checksum
+func (c *sidecarChecksum) verify(URL, actual string, response *contract.Response) error {
+	if actual != c.expected {
+		return base.NewCodedError(base.ErrorCodeChecksum, fmt.Errorf("%v checksum mismatch, expected: %v, but had: %v", URL, c.expected, actual))
+	}
+	response.Checksum = actual
+	return nil
+}
+
+//checksumVerifier computes source content digest while it is transferred, checksum mismatch fails before EOF is returned
+type checksumVerifier struct {
+	io.ReadCloser
+	hash     hash.Hash
+	checksum *sidecarChecksum
+	URL      string
+	response *contract.Response
+}
+
+//Read reads source content, corrupted content fails before EOF is returned
+func (v *checksumVerifier) Read(p []byte) (int, error) {
+	n, err := v.ReadCloser.Read(p)
+	v.hash.Write(p[:n])
+	if err == io.EOF {
+		if verifyErr := v.checksum.verify(v.URL, hex.EncodeToString(v.hash.Sum(nil)), v.response); verifyErr != nil {
+			return n, verifyErr
+		}
+	}
+	return n, err
+}
+
+func newChecksumVerifier(reader io.ReadCloser, checksum *sidecarChecksum, URL string, response *contract.Response) io.ReadCloser {
+	return &checksumVerifier{
+		ReadCloser: reader,
+		hash:       digest.New(checksum.algorithm),
+		checksum:   checksum,
+		URL:        URL,
+		response:   response,
+	}
+}
+
+//removeCorrupted removes destination objects written from source failing checksum verification
+func (s *service) removeCorrupted(ctx context.Context, rule *config.Rule, response *contract.Response) {
+	for _, outcome := range response.DestOutcomes {
+		if url.Scheme(outcome.URL, "") == "" { //topic or queue
+			continue
+		}
+		options, err := s.secret.StorageOpts(ctx, rule.Dest.CloneWithURL(outcome.URL))
+		if err != nil {
+			response.LogError = err.Error()
+			continue
+		}
+		if exists, _ := s.fs.Exists(ctx, outcome.URL, options...); !exists {
+			continue
+		}
+		if err = s.fs.Delete(ctx, outcome.URL, options...); err != nil {
+			response.LogError = fmt.Sprintf("failed to remove corrupted %v: %v", outcome.URL, err)
+		}
+	}
+}
+
+//checksum returns hex encoded object content digest computed with supplied algorithm
+func (s *service) checksum(ctx context.Context, algorithm, URL string, options []storage.Option) (string, error) {
+	reader, err := s.fs.OpenURL(ctx, URL, options...)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to open %v", URL)
+	}
+	defer func() { _ = reader.Close() }()
+	checksum, err := digest.Compute(algorithm, reader)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to compute %v checksum", URL)
+	}
+	return checksum, nil
+}
@@ -0,0 +1,92 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/afs/storage"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestService_Sidecar(t *testing.T) {
+	ctx := context.Background()
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source:  &config.Resource{Basic: matcher.Basic{Prefix: "/sidecar/data"}},
+				Dest:    &config.Resource{URL: "mem://localhost/sidecar/dest"},
+				Sidecar: &config.Sidecar{SkipSidecar: true, Extensions: []string{config.SidecarMD5, config.SidecarXXH64}},
+			},
+		}},
+	}
+	mirrorService, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	fs := afs.New()
+	validURL := "mem://localhost/sidecar/data/valid.csv"
+	_ = fs.Upload(ctx, validURL, 0644, strings.NewReader("line1\n"))
+	_ = fs.Upload(ctx, validURL+".md5", 0644, strings.NewReader("1ddab9058a07abc0db2605ab02a61a00  valid.csv\n"))
+	corruptedURL := "mem://localhost/sidecar/data/corrupted.csv"
+	_ = fs.Upload(ctx, corruptedURL, 0644, strings.NewReader("line\n"))
+	_ = fs.Upload(ctx, corruptedURL+".md5", 0644, strings.NewReader("1ddab9058a07abc0db2605ab02a61a00  corrupted.csv\n"))
+
+	response := mirrorService.Mirror(ctx, contract.NewRequest(validURL))
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+	assert.Equal(t, validURL+".md5", response.SidecarURL)
+	assert.Equal(t, "1ddab9058a07abc0db2605ab02a61a00", response.Checksum)
+
+	response = mirrorService.Mirror(ctx, contract.NewRequest(corruptedURL))
+	assert.Equal(t, base.StatusError, response.Status)
+	assert.Equal(t, base.ErrorCodeChecksum, response.ErrorCode)
+	exists, _ := fs.Exists(ctx, "mem://localhost/sidecar/dest/sidecar/data/corrupted.csv")
+	assert.False(t, exists)
+
+	fastURL := "mem://localhost/sidecar/data/fast.csv"
+	_ = fs.Upload(ctx, fastURL, 0644, strings.NewReader("line1\n"))
+	_ = fs.Upload(ctx, fastURL+".xxh64", 0644, strings.NewReader("9d62ba983b20aa98  fast.csv\n"))
+	response = mirrorService.Mirror(ctx, contract.NewRequest(fastURL))
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+	assert.Equal(t, "9d62ba983b20aa98", response.Checksum)
+
+	response = mirrorService.Mirror(ctx, contract.NewRequest(validURL+".md5"))
+	assert.Equal(t, base.StatusSidecar, response.Status, response.Error)
+
+	srv := mirrorService.(*service)
+	counter := &openCounter{Service: srv.fs, URL: "mem://localhost/sidecar/data/once.csv"}
+	srv.fs = counter
+	_ = fs.Upload(ctx, counter.URL, 0644, strings.NewReader("line1\n"))
+	_ = fs.Upload(ctx, counter.URL+".md5", 0644, strings.NewReader("1ddab9058a07abc0db2605ab02a61a00  once.csv\n"))
+	response = mirrorService.Mirror(ctx, contract.NewRequest(counter.URL))
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+	assert.Equal(t, "1ddab9058a07abc0db2605ab02a61a00", response.Checksum)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&counter.count))
+}
+
+//openCounter counts URL content reads
+type openCounter struct {
+	afs.Service
+	URL   string
+	count int32
+}
+
+func (c *openCounter) OpenURL(ctx context.Context, URL string, options ...storage.Option) (io.ReadCloser, error) {
+	if URL == c.URL {
+		atomic.AddInt32(&c.count, 1)
+	}
+	return c.Service.OpenURL(ctx, URL, options...)
+}
+
+func (c *openCounter) DownloadWithURL(ctx context.Context, URL string, options ...storage.Option) ([]byte, error) {
+	if URL == c.URL {
+		atomic.AddInt32(&c.count, 1)
+	}
+	return c.Service.DownloadWithURL(ctx, URL, options...)
+}
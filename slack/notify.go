@@ -7,6 +7,7 @@ import (
 	"github.com/nlopes/slack"
 	"github.com/pkg/errors"
 	"github.com/viant/smirror/job"
+	"sort"
 )
 
 func (s *service) Notify(ctx context.Context, request *job.NotifyRequest) error {
@@ -98,6 +99,10 @@ func (s *service) sendMessage(context context.Context, client *slack.Client, req
 	attachment := slack.Attachment{
 		Text:       request.Message,
 		AuthorName: request.From,
+		Fields:     labelFields(request.Labels),
+	}
+	if request.TransferID != "" {
+		attachment.Footer = "transfer: " + request.TransferID
 	}
 	for _, channel := range request.Channels {
 		if _, _, e := client.PostMessage(channel, slack.MsgOptionText(request.Title, false), slack.MsgOptionAttachments(attachment)); e != nil {
@@ -106,3 +111,19 @@ func (s *service) sendMessage(context context.Context, client *slack.Client, req
 	}
 	return err
 }
+
+func labelFields(labels map[string]string) []slack.AttachmentField {
+	if len(labels) == 0 {
+		return nil
+	}
+	var keys = make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var result = make([]slack.AttachmentField, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, slack.AttachmentField{Title: k, Value: labels[k], Short: true})
+	}
+	return result
+}
@@ -99,7 +99,7 @@ func Split(reader io.Reader, writerProvider func(partition interface{}) io.Write
 		return splitWithPartition(scanner, split, writerProvider)
 	}
 	if split.MaxSize > 0 {
-		return splitBySize(scanner, split.MaxSize, writerProvider)
+		return splitBySize(scanner, int(split.MaxSize), writerProvider)
 	}
 	if split.MaxLines == 0 {
 		split.MaxLines = 1
@@ -145,7 +145,7 @@ func splitWithPartition(scanner *bufio.Scanner, split *config.Split, provider fu
 			partitions[key] = partition
 		}
 
-		if (split.MaxLines > 0 && partition.lines+1 > split.MaxLines) || (split.MaxSize > 0 && partition.size+len(data) > split.MaxSize) {
+		if (split.MaxLines > 0 && partition.lines+1 > split.MaxLines) || (split.MaxSize > 0 && partition.size+len(data) > int(split.MaxSize)) {
 			if err = partition.flush(provider); err != nil {
 				return nil
 			}
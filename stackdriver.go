@@ -0,0 +1,86 @@
+package smirror
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/stackdriver"
+	"google.golang.org/api/monitoring/v3"
+	"google.golang.org/api/option"
+	"time"
+)
+
+//monitoringNamespace generic_task namespace resource label
+const monitoringNamespace = "smirror"
+
+//monitoringOptions additional cloud monitoring client options
+var monitoringOptions []option.ClientOption
+
+//recordMonitoring records response with instance cumulative metrics, metrics are written with the first response after flush interval,
+//write error is reported as response log error
+func (s *service) recordMonitoring(ctx context.Context, response *contract.Response) {
+	settings := s.config.Monitoring
+	if settings == nil {
+		return
+	}
+	recorder := s.monitoringRecorder()
+	latency := time.Duration(response.TimeTakenMs) * time.Millisecond
+	switch response.Status {
+	case base.StatusOK:
+		recorder.RecordSuccess(responseRule(response), response.FileSize, latency)
+	case base.StatusError, base.StatusPartial:
+		errorCode := response.ErrorCode
+		if errorCode == "" {
+			errorCode = base.ErrorCodeUnknown
+		}
+		recorder.RecordFailure(responseRule(response), errorCode, latency)
+	default:
+		return
+	}
+	now := time.Now()
+	if !recorder.Due(now, settings.FlushInterval()) {
+		return
+	}
+	service, err := s.cloudMonitoring(ctx)
+	if err == nil {
+		err = recorder.Write(ctx, service, now)
+	}
+	if err != nil {
+		response.LogError = err.Error()
+	}
+}
+
+//monitoringRecorder returns instance metrics recorder, each instance writes its own task_id time series
+func (s *service) monitoringRecorder() *stackdriver.Recorder {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.monitoring == nil {
+		settings := s.config.Monitoring
+		s.monitoring = stackdriver.NewRecorder(settings.Prefix, &stackdriver.Resource{
+			ProjectID: settings.ProjectID,
+			Location:  settings.Region,
+			Namespace: monitoringNamespace,
+			Job:       settings.Function,
+			TaskID:    uuid.New().String(),
+		})
+	}
+	return s.monitoring
+}
+
+//cloudMonitoring returns Cloud Monitoring client, it is created once per instance
+func (s *service) cloudMonitoring(ctx context.Context) (*monitoring.Service, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.monitoringService != nil {
+		return s.monitoringService, nil
+	}
+	options := append([]option.ClientOption{option.WithScopes(monitoring.MonitoringWriteScope)}, monitoringOptions...)
+	service, err := monitoring.NewService(ctx, options...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cloud monitoring service")
+	}
+	s.monitoringService = service
+	return service, nil
+}
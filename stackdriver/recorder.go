@@ -0,0 +1,220 @@
+package stackdriver
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"google.golang.org/api/monitoring/v3"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	//MetricFiles mirrored files metric
+	MetricFiles = "mirrored_files"
+	//MetricBytes mirrored bytes metric
+	MetricBytes = "mirrored_bytes"
+	//MetricFailures failed transfers metric
+	MetricFailures = "failures"
+	//MetricLatency transfer latency (ms) distribution metric
+	MetricLatency = "latency"
+
+	//latency buckets: underflow below 1ms, then 1ms * 2^i up to ~17 minutes, then overflow
+	latencyScale   = 1.0
+	latencyGrowth  = 2.0
+	latencyBuckets = 20
+	//maxSeriesPerRequest Cloud Monitoring time series per create request limit
+	maxSeriesPerRequest  = 200
+	metricKindCumulative = "CUMULATIVE"
+)
+
+//Resource represents generic_task monitored resource labels
+type Resource struct {
+	ProjectID string
+	Location  string
+	Namespace string
+	Job       string
+	TaskID    string
+}
+
+//ruleStats represents cumulative rule metrics
+type ruleStats struct {
+	files    int64
+	bytes    int64
+	failures map[string]int64
+	latency  *distribution
+}
+
+//Recorder accumulates per rule cumulative metrics of function instance, metrics are written as Cloud Monitoring custom metrics,
+//each instance writes its own time series (task_id), so concurrent instances do not conflict
+type Recorder struct {
+	mux       sync.Mutex
+	prefix    string
+	resource  *monitoring.MonitoredResource
+	start     time.Time
+	lastWrite time.Time
+	rules     map[string]*ruleStats
+}
+
+//RecordSuccess records successful transfer
+func (r *Recorder) RecordSuccess(rule string, bytes int64, latency time.Duration) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	stats := r.stats(rule)
+	stats.files++
+	stats.bytes += bytes
+	stats.latency.add(float64(latency) / float64(time.Millisecond))
+}
+
+//RecordFailure records failed transfer with error code
+func (r *Recorder) RecordFailure(rule, errorCode string, latency time.Duration) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	stats := r.stats(rule)
+	stats.failures[errorCode]++
+	stats.latency.add(float64(latency) / float64(time.Millisecond))
+}
+
+//Due returns true and marks write time if metrics were not written within interval,
+//so that concurrent responses do not write the same points
+func (r *Recorder) Due(now time.Time, interval time.Duration) bool {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if now.Sub(r.lastWrite) < interval {
+		return false
+	}
+	r.lastWrite = now
+	return true
+}
+
+//Write writes cumulative time series, failed write is retried with the next write, since values are cumulative
+func (r *Recorder) Write(ctx context.Context, service *monitoring.Service, now time.Time) error {
+	series := r.TimeSeries(now)
+	name := "projects/" + r.resource.Labels["project_id"]
+	for i := 0; i < len(series); i += maxSeriesPerRequest {
+		end := i + maxSeriesPerRequest
+		if end > len(series) {
+			end = len(series)
+		}
+		request := &monitoring.CreateTimeSeriesRequest{TimeSeries: series[i:end]}
+		if _, err := service.Projects.TimeSeries.Create(name, request).Context(ctx).Do(); err != nil {
+			return errors.Wrap(err, "failed to write cloud monitoring metrics")
+		}
+	}
+	return nil
+}
+
+//TimeSeries returns cumulative time series since recorder start
+func (r *Recorder) TimeSeries(now time.Time) []*monitoring.TimeSeries {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	interval := &monitoring.TimeInterval{StartTime: r.start.UTC().Format(time.RFC3339Nano), EndTime: now.UTC().Format(time.RFC3339Nano)}
+	var rules = make([]string, 0, len(r.rules))
+	for rule := range r.rules {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	var result = make([]*monitoring.TimeSeries, 0)
+	for _, rule := range rules {
+		stats := r.rules[rule]
+		labels := map[string]string{"rule": rule}
+		result = append(result,
+			r.int64Series(MetricFiles, labels, "1", interval, stats.files),
+			r.int64Series(MetricBytes, labels, "By", interval, stats.bytes))
+		var codes = make([]string, 0, len(stats.failures))
+		for code := range stats.failures {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			result = append(result, r.int64Series(MetricFailures, map[string]string{"rule": rule, "error_code": code}, "1", interval, stats.failures[code]))
+		}
+		if stats.latency.count > 0 {
+			result = append(result, r.series(MetricLatency, labels, "ms", interval, &monitoring.TypedValue{DistributionValue: stats.latency.value()}))
+		}
+	}
+	return result
+}
+
+func (r *Recorder) int64Series(metric string, labels map[string]string, unit string, interval *monitoring.TimeInterval, value int64) *monitoring.TimeSeries {
+	return r.series(metric, labels, unit, interval, &monitoring.TypedValue{Int64Value: &value})
+}
+
+func (r *Recorder) series(metric string, labels map[string]string, unit string, interval *monitoring.TimeInterval, value *monitoring.TypedValue) *monitoring.TimeSeries {
+	return &monitoring.TimeSeries{
+		Metric:     &monitoring.Metric{Type: r.prefix + "/" + metric, Labels: labels},
+		Resource:   r.resource,
+		MetricKind: metricKindCumulative,
+		Unit:       unit,
+		Points:     []*monitoring.Point{{Interval: interval, Value: value}},
+	}
+}
+
+func (r *Recorder) stats(rule string) *ruleStats {
+	stats, ok := r.rules[rule]
+	if !ok {
+		stats = &ruleStats{failures: make(map[string]int64), latency: newDistribution()}
+		r.rules[rule] = stats
+	}
+	return stats
+}
+
+//distribution represents exponential buckets distribution
+type distribution struct {
+	count                 int64
+	mean                  float64
+	sumOfSquaredDeviation float64
+	buckets               []int64
+}
+
+//add adds value updating mean and sum of squared deviation (Welford)
+func (d *distribution) add(value float64) {
+	d.count++
+	delta := value - d.mean
+	d.mean += delta / float64(d.count)
+	d.sumOfSquaredDeviation += delta * (value - d.mean)
+	index := 0
+	if value >= latencyScale {
+		index = int(math.Floor(math.Log(value/latencyScale)/math.Log(latencyGrowth))) + 1
+		if index > latencyBuckets+1 {
+			index = latencyBuckets + 1
+		}
+	}
+	d.buckets[index]++
+}
+
+func (d *distribution) value() *monitoring.Distribution {
+	return &monitoring.Distribution{
+		Count:                 d.count,
+		Mean:                  d.mean,
+		SumOfSquaredDeviation: d.sumOfSquaredDeviation,
+		BucketCounts:          append([]int64{}, d.buckets...),
+		BucketOptions: &monitoring.BucketOptions{
+			ExponentialBuckets: &monitoring.Exponential{GrowthFactor: latencyGrowth, NumFiniteBuckets: latencyBuckets, Scale: latencyScale},
+		},
+	}
+}
+
+func newDistribution() *distribution {
+	return &distribution{buckets: make([]int64, latencyBuckets+2)}
+}
+
+//NewRecorder creates recorder, prefix is metric type prefix, i.e. custom.googleapis.com/smirror
+func NewRecorder(prefix string, resource *Resource) *Recorder {
+	return &Recorder{
+		prefix: prefix,
+		resource: &monitoring.MonitoredResource{
+			Type: "generic_task",
+			Labels: map[string]string{
+				"project_id": resource.ProjectID,
+				"location":   resource.Location,
+				"namespace":  resource.Namespace,
+				"job":        resource.Job,
+				"task_id":    resource.TaskID,
+			},
+		},
+		start: time.Now(),
+		rules: make(map[string]*ruleStats),
+	}
+}
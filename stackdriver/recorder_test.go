@@ -0,0 +1,47 @@
+package stackdriver
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRecorder_TimeSeries(t *testing.T) {
+	recorder := NewRecorder("custom.googleapis.com/smirror", &Resource{ProjectID: "p1", Location: "us-central1", Namespace: "smirror", Job: "mirror", TaskID: "t1"})
+	recorder.RecordSuccess("orders", 100, 500*time.Microsecond)
+	recorder.RecordSuccess("orders", 50, 3*time.Millisecond)
+	recorder.RecordFailure("orders", "auth", time.Second)
+	recorder.RecordSuccess("clicks", 10, 2*time.Hour)
+
+	series := recorder.TimeSeries(time.Now())
+	var actual = make(map[string]interface{})
+	for _, item := range series {
+		assert.Equal(t, "generic_task", item.Resource.Type)
+		assert.Equal(t, "us-central1", item.Resource.Labels["location"])
+		assert.Equal(t, "CUMULATIVE", item.MetricKind)
+		key := item.Metric.Type + "/" + item.Metric.Labels["rule"] + item.Metric.Labels["error_code"]
+		if value := item.Points[0].Value; value.Int64Value != nil {
+			actual[key] = *value.Int64Value
+		} else {
+			actual[key] = []int64(value.DistributionValue.BucketCounts)
+		}
+	}
+	latency := make([]int64, latencyBuckets+2)
+	latency[0], latency[2], latency[10] = 1, 1, 1
+	overflow := make([]int64, latencyBuckets+2)
+	overflow[latencyBuckets+1] = 1
+	assert.EqualValues(t, map[string]interface{}{
+		"custom.googleapis.com/smirror/mirrored_files/orders": int64(2),
+		"custom.googleapis.com/smirror/mirrored_bytes/orders": int64(150),
+		"custom.googleapis.com/smirror/failures/ordersauth":   int64(1),
+		"custom.googleapis.com/smirror/latency/orders":        latency,
+		"custom.googleapis.com/smirror/mirrored_files/clicks": int64(1),
+		"custom.googleapis.com/smirror/mirrored_bytes/clicks": int64(10),
+		"custom.googleapis.com/smirror/latency/clicks":        overflow,
+	}, actual)
+
+	now := time.Now()
+	assert.True(t, recorder.Due(now, time.Minute))
+	assert.False(t, recorder.Due(now.Add(time.Second), time.Minute))
+	assert.True(t, recorder.Due(now.Add(time.Minute), time.Minute))
+}
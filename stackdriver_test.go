@@ -0,0 +1,70 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"google.golang.org/api/monitoring/v3"
+	"google.golang.org/api/option"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestService_RecordMonitoring(t *testing.T) {
+	var paths []string
+	var written []*monitoring.TimeSeries
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		paths = append(paths, request.URL.Path)
+		body := &monitoring.CreateTimeSeriesRequest{}
+		_ = json.NewDecoder(request.Body).Decode(body)
+		written = append(written, body.TimeSeries...)
+		_, _ = writer.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	monitoringOptions = []option.ClientOption{option.WithEndpoint(server.URL + "/"), option.WithoutAuthentication()}
+	defer func() { monitoringOptions = nil }()
+
+	ctx := context.Background()
+	fs := afs.New()
+	_ = fs.Delete(ctx, "mem://localhost/monitoring")
+	cfg := &Config{
+		Monitoring: &config.Monitoring{ProjectID: "p1", Function: "mirror", Region: "us-central1"},
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Info:   base.Info{Workflow: "orders"},
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/monitoring/data"}},
+				Dest:   &config.Resource{URL: "mem://localhost/monitoring/dest"},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	for _, name := range []string{"f1.csv", "f2.csv"} {
+		sourceURL := "mem://localhost/monitoring/data/" + name
+		_ = fs.Upload(ctx, sourceURL, 0644, strings.NewReader("1,2,3"))
+		response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+		assert.Equal(t, base.StatusOK, response.Status, response.Error)
+		assert.Empty(t, response.LogError)
+	}
+	unmatched := service.Mirror(ctx, contract.NewRequest("mem://localhost/monitoring/other/f1.csv"))
+	assert.Equal(t, base.StatusNoMatch, unmatched.Status)
+
+	assert.EqualValues(t, []string{"/v3/projects/p1/timeSeries"}, paths, "second response is within flush interval")
+	var metrics = make([]string, 0)
+	for _, series := range written {
+		metrics = append(metrics, series.Metric.Type)
+		assert.Equal(t, "orders", series.Metric.Labels["rule"])
+		assert.EqualValues(t, map[string]string{"project_id": "p1", "location": "us-central1", "namespace": "smirror", "job": "mirror", "task_id": series.Resource.Labels["task_id"]}, series.Resource.Labels)
+	}
+	assert.EqualValues(t, []string{"custom.googleapis.com/smirror/mirrored_files", "custom.googleapis.com/smirror/mirrored_bytes", "custom.googleapis.com/smirror/latency"}, metrics)
+	assert.EqualValues(t, 1, *written[0].Points[0].Value.Int64Value)
+}
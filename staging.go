@@ -0,0 +1,169 @@
+package smirror
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/file"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/shared"
+	"log"
+	"net/http"
+	"time"
+)
+
+//StorageMirrorActivate cloud function entry point, activates pending rules
+func StorageMirrorActivate(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, auth.EndpointActivate) {
+		return
+	}
+	if r.ContentLength > 0 {
+		defer func() {
+			_ = r.Body.Close()
+		}()
+	}
+	err := activateRules(w, r)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func activateRules(writer http.ResponseWriter, httpRequest *http.Request) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	request := &contract.ActivationRequest{}
+	if err = json.NewDecoder(httpRequest.Body).Decode(&request); err != nil {
+		return errors.Wrapf(err, "failed to decode %T", request)
+	}
+	ctx := context.Background()
+	service, err := NewFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		return err
+	}
+	response := service.Activate(ctx, request)
+	return json.NewEncoder(writer).Encode(response)
+}
+
+//Activate validates pending rules and promotes them to active rules if request signature is valid
+func (s *service) Activate(ctx context.Context, request *contract.ActivationRequest) *contract.ActivationResponse {
+	response := contract.NewActivationResponse()
+	err := s.activate(ctx, request, response)
+	if err != nil {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+	}
+	s.mux.Lock()
+	changed := response.Hash != s.pendingHash
+	s.pendingHash = response.Hash
+	s.mux.Unlock()
+	if changed || !request.DryRun {
+		s.reportPending(ctx, response)
+	}
+	return response
+}
+
+func (s *service) activate(ctx context.Context, request *contract.ActivationRequest, response *contract.ActivationResponse) error {
+	staging := &s.config.Mirrors.Staging
+	if !staging.Enabled() {
+		return errors.New("staging pendingURL was empty")
+	}
+	pending, hash, err := staging.Pending(ctx, s.fs)
+	if err != nil {
+		return errors.Wrapf(err, "failed to load pending rules: %v", staging.PendingURL)
+	}
+	response.Hash = hash
+	response.Rules = pending
+	for _, rule := range pending {
+		if rule.Error != "" {
+			return errors.Errorf("invalid pending rule: %v, %v", rule.URL, rule.Error)
+		}
+	}
+	if request.DryRun {
+		return nil
+	}
+	if len(pending) == 0 {
+		return errors.Errorf("no pending rules in %v", staging.PendingURL)
+	}
+	if request.Hash != hash {
+		return errors.Errorf("pending rules have changed: expected hash %v, but had %v", hash, request.Hash)
+	}
+	if staging.SigningKey == nil {
+		return errors.New("staging signingKey was empty")
+	}
+	key, err := s.secret.Decrypt(ctx, staging.SigningKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to decrypt signing key")
+	}
+	if !config.VerifySignature(hash, request.Signature, key) {
+		return errors.New("invalid activation signature")
+	}
+	for _, rule := range pending {
+		activeURL := url.Join(s.config.Mirrors.BaseURL, staging.RelativePath(rule.URL))
+		if err = s.fs.Upload(ctx, activeURL, file.DefaultFileOsMode, bytes.NewReader(rule.Data())); err != nil {
+			return errors.Wrapf(err, "failed to activate %v", rule.URL)
+		}
+		if err = s.fs.Delete(ctx, rule.URL); err != nil {
+			return errors.Wrapf(err, "failed to remove pending %v", rule.URL)
+		}
+		response.Activated = append(response.Activated, activeURL)
+	}
+	if err = s.config.Mirrors.Reload(ctx, s.cfs); err != nil {
+		return err
+	}
+	s.retainRuleInits()
+	return nil
+}
+
+//checkStaging validates pending rules and activates them if activation marker is present
+func (s *service) checkStaging(ctx context.Context) {
+	staging := &s.config.Mirrors.Staging
+	if !staging.Enabled() {
+		return
+	}
+	now := time.Now()
+	s.mux.Lock()
+	if now.Before(s.nextStaging) {
+		s.mux.Unlock()
+		return
+	}
+	checkFrequency := time.Duration(s.config.Mirrors.CheckInMs) * time.Millisecond
+	if checkFrequency == 0 {
+		checkFrequency = time.Minute
+	}
+	s.nextStaging = now.Add(checkFrequency)
+	s.mux.Unlock()
+
+	request := &contract.ActivationRequest{DryRun: true}
+	if data, err := s.fs.DownloadWithURL(ctx, staging.MarkerURL()); err == nil {
+		_ = s.fs.Delete(ctx, staging.MarkerURL())
+		if err = json.Unmarshal(data, request); err != nil {
+			shared.LogF("invalid activation marker: %v, %v\n", staging.MarkerURL(), err)
+			return
+		}
+		request.DryRun = false
+	}
+	response := s.Activate(ctx, request)
+	if response.Error != "" {
+		shared.LogF("pending rules: %v\n", response.Error)
+	}
+}
+
+func (s *service) reportPending(ctx context.Context, response *contract.ActivationResponse) {
+	data, err := json.Marshal(response)
+	if err == nil {
+		err = s.fs.Upload(ctx, s.config.Mirrors.Staging.ReportURL(), file.DefaultFileOsMode, bytes.NewReader(data))
+	}
+	if err != nil {
+		shared.LogF("failed to report pending rules: %v\n", err)
+	}
+}
@@ -0,0 +1,116 @@
+package statsd
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/pkg/errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	//TypeCount counter metric type
+	TypeCount = "c"
+	//TypeGauge gauge metric type
+	TypeGauge = "g"
+	//TypeTiming timing (ms) metric type
+	TypeTiming = "ms"
+
+	//maxPacketSize keeps UDP packet within common network MTU
+	maxPacketSize = 1432
+)
+
+//Metric represents StatsD metric
+type Metric struct {
+	Name  string
+	Value float64
+	Type  string
+	//Tags DogStatsD tags, ignored by plain StatsD client
+	Tags map[string]string
+}
+
+//Client represents StatsD client, metrics are sent over UDP without waiting for agent
+type Client struct {
+	conn   net.Conn
+	prefix string
+	tagged bool
+}
+
+//Send sends metrics, metrics are batched into packets up to max packet size
+func (c *Client) Send(metrics []*Metric) error {
+	packet := new(bytes.Buffer)
+	for _, metric := range metrics {
+		line := c.encode(metric)
+		if packet.Len() > 0 && packet.Len()+len(line)+1 > maxPacketSize {
+			if err := c.write(packet); err != nil {
+				return err
+			}
+		}
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+	return c.write(packet)
+}
+
+//Close closes client connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Client) write(packet *bytes.Buffer) error {
+	if packet.Len() == 0 {
+		return nil
+	}
+	defer packet.Reset()
+	if _, err := c.conn.Write(packet.Bytes()); err != nil {
+		return errors.Wrap(err, "failed to send statsd metrics")
+	}
+	return nil
+}
+
+//encode returns metric line: <prefix>.<name>:<value>|<type>[|#tag:value,...]
+func (c *Client) encode(metric *Metric) string {
+	name := Sanitize(metric.Name)
+	if c.prefix != "" {
+		name = c.prefix + "." + name
+	}
+	line := fmt.Sprintf("%v:%v|%v", name, strconv.FormatFloat(metric.Value, 'f', -1, 64), metric.Type)
+	if !c.tagged || len(metric.Tags) == 0 {
+		return line
+	}
+	var tags = make([]string, 0, len(metric.Tags))
+	for key, value := range metric.Tags {
+		tags = append(tags, tag(key)+":"+tag(value))
+	}
+	sort.Strings(tags)
+	return line + "|#" + strings.Join(tags, ",")
+}
+
+//Sanitize replaces characters not allowed in metric name with underscore
+func Sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+//tag replaces DogStatsD tag separators with underscore
+func tag(value string) string {
+	return strings.NewReplacer(",", "_", "|", "_", "#", "_", ":", "_", "\n", "_").Replace(value)
+}
+
+//New creates StatsD client, tagged client sends DogStatsD tags
+func New(address, prefix string, tagged bool) (*Client, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect statsd agent: %v", address)
+	}
+	return &Client{conn: conn, prefix: strings.Trim(prefix, "."), tagged: tagged}, nil
+}
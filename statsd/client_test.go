@@ -0,0 +1,65 @@
+package statsd
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClient_Send(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer listener.Close()
+
+	var useCases = []struct {
+		description string
+		tagged      bool
+		metrics     []*Metric
+		expect      []string
+	}{
+		{
+			description: "dogstatsd tags",
+			tagged:      true,
+			metrics: []*Metric{
+				{Name: "transfers", Value: 1, Type: TypeCount, Tags: map[string]string{"rule": "orders", "team": "data,eng"}},
+				{Name: "duration", Value: 12.5, Type: TypeTiming},
+			},
+			expect: []string{"smirror.transfers:1|c|#rule:orders,team:data_eng\nsmirror.duration:12.5|ms"},
+		},
+		{
+			description: "plain statsd ignores tags",
+			metrics:     []*Metric{{Name: "orders/v1.bytes", Value: 2048, Type: TypeCount, Tags: map[string]string{"rule": "orders"}}},
+			expect:      []string{"smirror.orders_v1.bytes:2048|c"},
+		},
+		{
+			description: "packets are split at max size",
+			metrics: []*Metric{
+				{Name: strings.Repeat("a", 1000), Value: 1, Type: TypeCount},
+				{Name: strings.Repeat("b", 1000), Value: 1, Type: TypeGauge},
+			},
+			expect: []string{"smirror." + strings.Repeat("a", 1000) + ":1|c", "smirror." + strings.Repeat("b", 1000) + ":1|g"},
+		},
+	}
+
+	for _, useCase := range useCases {
+		client, err := New(listener.LocalAddr().String(), "smirror.", useCase.tagged)
+		if !assert.Nil(t, err, useCase.description) {
+			continue
+		}
+		assert.Nil(t, client.Send(useCase.metrics), useCase.description)
+		buffer := make([]byte, 4096)
+		for _, expect := range useCase.expect {
+			_ = listener.SetReadDeadline(time.Now().Add(2 * time.Second))
+			n, _, err := listener.ReadFrom(buffer)
+			if !assert.Nil(t, err, useCase.description) {
+				break
+			}
+			assert.Equal(t, expect, string(buffer[:n]), useCase.description)
+		}
+		_ = client.Close()
+	}
+}
@@ -0,0 +1,62 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/shared"
+	"log"
+	"net/http"
+	"time"
+)
+
+//StorageMirrorSubmit cloud function entry point, mirrors source object supplied with JSON mirror request and returns mirror response
+func StorageMirrorSubmit(w http.ResponseWriter, r *http.Request) {
+	if !authorized(w, r, auth.EndpointMirror) {
+		return
+	}
+	if !validRequest(w, r, pathSubmit) {
+		return
+	}
+	err := submitMirror(w, r)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func submitMirror(writer http.ResponseWriter, httpRequest *http.Request) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	defer func() {
+		_ = httpRequest.Body.Close()
+	}()
+	request := &contract.Request{}
+	if err = json.NewDecoder(httpRequest.Body).Decode(&request); err != nil {
+		return errors.Wrapf(err, "failed to decode %T", request)
+	}
+	if request.URL == "" {
+		return errors.New("request URL was empty")
+	}
+	if request.Timestamp.IsZero() {
+		request.Timestamp = time.Now()
+	}
+	ctx := context.Background()
+	service, err := NewFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		return err
+	}
+	response := service.Mirror(ctx, request)
+	if !response.IsLogSuppressed() {
+		shared.LogLn(response)
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(writer).Encode(response)
+}
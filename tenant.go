@@ -0,0 +1,376 @@
+package smirror
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/job"
+	"github.com/viant/smirror/shared"
+	"sort"
+	"strings"
+)
+
+//Tenant represents independent team config root
+type Tenant struct {
+	Name string
+	//Bucket optional event bucket routed to the tenant
+	Bucket string `json:",omitempty"`
+	//Prefix optional event path prefix routed to the tenant
+	Prefix string `json:",omitempty"`
+	//Mirrors tenant rules
+	Mirrors config.Ruleset
+	//SecretScopes secret URL or parameter prefixes tenant rules can use
+	SecretScopes []string `json:",omitempty"`
+	//ImpersonationScopes service accounts and federation AWS role ARNs tenant rules can impersonate
+	ImpersonationScopes []string `json:",omitempty"`
+	//Labels tenant metrics labels
+	Labels map[string]string `json:",omitempty"`
+}
+
+//Match returns true if event URL is routed to the tenant
+func (t *Tenant) Match(URL string) bool {
+	if t.Bucket != "" && url.Host(URL) != t.Bucket {
+		return false
+	}
+	if t.Prefix != "" && !strings.HasPrefix(strings.Trim(url.Path(URL), "/"), strings.Trim(t.Prefix, "/")) {
+		return false
+	}
+	return true
+}
+
+//Validate checks if tenant is valid
+func (t *Tenant) Validate() error {
+	if t.Name == "" {
+		return errors.New("tenant name was empty")
+	}
+	if t.Bucket == "" && t.Prefix == "" {
+		return errors.Errorf("tenant %v: both bucket and prefix were empty", t.Name)
+	}
+	if t.Mirrors.BaseURL == "" && len(t.Mirrors.Rules) == 0 {
+		return errors.Errorf("tenant %v: mirrors were empty", t.Name)
+	}
+	return nil
+}
+
+//Config returns tenant config inheriting global settings
+func (t *Tenant) Config(global *Config) *Config {
+	result := *global
+	result.Tenants = nil
+	result.Mirrors = t.Mirrors
+	if len(t.SecretScopes) > 0 {
+		result.SecretScopes = t.SecretScopes
+	}
+	if len(t.ImpersonationScopes) > 0 {
+		result.ImpersonationScopes = t.ImpersonationScopes
+	}
+	if len(t.Labels) > 0 {
+		result.Labels = t.Labels
+	}
+	return &result
+}
+
+//inSecretScope returns true if secret URL or parameter matches any scope prefix
+func inSecretScope(secret auth.Secret, scopes []string) bool {
+	for _, scope := range scopes {
+		if secret.URL != "" && strings.HasPrefix(secret.URL, scope) {
+			return true
+		}
+		if secret.Parameter != "" && strings.HasPrefix(secret.Parameter, scope) {
+			return true
+		}
+	}
+	return false
+}
+
+//ruleSecrets returns secrets referenced by rule resources and actions keyed by secret usage
+func ruleSecrets(rule *config.Rule) map[string]*auth.Secret {
+	result := make(map[string]*auth.Secret)
+	for name, resource := range map[string]*config.Resource{"source": rule.Source, "dest": rule.Dest} {
+		if resource == nil {
+			continue
+		}
+		if resource.Credentials != nil {
+			result[name+".Credentials"] = &resource.Credentials.Secret
+		}
+		if resource.CustomKey != nil {
+			result[name+".CustomKey"] = &resource.CustomKey.Secret
+		}
+		if resource.TLS != nil && resource.TLS.Certificate != nil {
+			result[name+".TLS.Certificate"] = resource.TLS.Certificate
+		}
+		if resource.Proxy != nil && resource.Proxy.Credentials != nil {
+			result[name+".Proxy.Credentials"] = resource.Proxy.Credentials
+		}
+	}
+	for name, actions := range map[string][]*job.Action{"OnSuccess": rule.OnSuccess, "OnFailure": rule.OnFailure} {
+		for i, action := range actions {
+			if action != nil && action.Credentials != nil {
+				result[fmt.Sprintf("%v[%v].Credentials", name, i)] = &action.Credentials.Secret
+			}
+		}
+	}
+	return result
+}
+
+//inImpersonationScope returns true if impersonation target (service account or AWS role ARN) is listed in scopes
+func inImpersonationScope(target string, scopes []string) bool {
+	for _, scope := range scopes {
+		if target == scope {
+			return true
+		}
+	}
+	return false
+}
+
+//checkSecretScope checks if all rule secrets are within configured secret scopes and impersonated service accounts or federation roles within impersonation scopes
+func checkSecretScope(rule *config.Rule, scopes, impersonationScopes []string) error {
+	if len(impersonationScopes) > 0 {
+		for _, resource := range []*config.Resource{rule.Source, rule.Dest} {
+			if resource == nil {
+				continue
+			}
+			targets := []string{resource.ImpersonateServiceAccount}
+			if resource.Federation != nil {
+				targets = append(targets, resource.Federation.ServiceAccount, resource.Federation.RoleARN)
+			}
+			for _, target := range targets {
+				if target != "" && !inImpersonationScope(target, impersonationScopes) {
+					return errors.Errorf("rule %v impersonation target %v is out of impersonation scopes %v", rule.Info.URL, target, impersonationScopes)
+				}
+			}
+		}
+	}
+	if len(scopes) == 0 {
+		return nil
+	}
+	secrets := ruleSecrets(rule)
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		secret := secrets[name]
+		if secret.URL == "" && secret.Parameter == "" {
+			continue
+		}
+		if !inSecretScope(*secret, scopes) {
+			return errors.Errorf("rule %v %v secret %v%v is out of secret scopes %v", rule.Info.URL, name, secret.URL, secret.Parameter, scopes)
+		}
+	}
+	return nil
+}
+
+type tenantService struct {
+	*Tenant
+	Service
+	err error
+}
+
+//tenantRouter routes events to independent tenant services
+type tenantRouter struct {
+	tenants []*tenantService
+}
+
+func (r *tenantRouter) match(URL string) *tenantService {
+	for _, tenant := range r.tenants {
+		if tenant.Match(URL) {
+			return tenant
+		}
+	}
+	return nil
+}
+
+//Mirror routes mirror request to matched tenant service
+func (r *tenantRouter) Mirror(ctx context.Context, request *contract.Request) *contract.Response {
+	tenant := r.match(request.URL)
+	if tenant == nil {
+		response := contract.NewResponse(request.URL)
+		response.Status = base.StatusNoMatch
+		return response
+	}
+	if tenant.err != nil {
+		response := contract.NewResponse(request.URL)
+		response.Status = base.StatusError
+		response.Error = shared.Redact(tenant.err.Error())
+		response.ClassifyError(tenant.err)
+		response.Tenant = tenant.Name
+		return response
+	}
+	response := tenant.Service.Mirror(ctx, request)
+	response.Tenant = tenant.Name
+	response.AddLabels(tenant.Labels)
+	return response
+}
+
+//MirrorBatch routes each batch source object to matched tenant service
+func (r *tenantRouter) MirrorBatch(ctx context.Context, request *contract.BatchRequest) *contract.BatchResponse {
+	return mirrorBatch(ctx, r.Mirror, request)
+}
+
+//Activate routes activation request to tenant service
+func (r *tenantRouter) Activate(ctx context.Context, request *contract.ActivationRequest) *contract.ActivationResponse {
+	for _, tenant := range r.tenants {
+		if tenant.Name != request.Tenant {
+			continue
+		}
+		if tenant.err != nil {
+			break
+		}
+		return tenant.Service.Activate(ctx, request)
+	}
+	response := contract.NewActivationResponse()
+	response.Status = base.StatusError
+	response.Error = fmt.Sprintf("tenant %v was not found or failed to initialise", request.Tenant)
+	return response
+}
+
+//RuleHistory routes rule history request to tenant service
+func (r *tenantRouter) RuleHistory(ctx context.Context, request *contract.RuleHistoryRequest) *contract.RuleHistoryResponse {
+	for _, tenant := range r.tenants {
+		if tenant.Name != request.Tenant {
+			continue
+		}
+		if tenant.err != nil {
+			break
+		}
+		return tenant.Service.RuleHistory(ctx, request)
+	}
+	response := contract.NewRuleHistoryResponse(request.URL, request.At)
+	response.Status = base.StatusError
+	response.Error = fmt.Sprintf("tenant %v was not found or failed to initialise", request.Tenant)
+	return response
+}
+
+//Pause routes pause request to tenant service
+func (r *tenantRouter) Pause(ctx context.Context, request *contract.PauseRequest) *contract.PauseResponse {
+	for _, tenant := range r.tenants {
+		if tenant.Name != request.Tenant {
+			continue
+		}
+		if tenant.err != nil {
+			break
+		}
+		return tenant.Service.Pause(ctx, request)
+	}
+	response := contract.NewPauseResponse()
+	response.Status = base.StatusError
+	response.Error = fmt.Sprintf("tenant %v was not found or failed to initialise", request.Tenant)
+	return response
+}
+
+//Webhook routes partner notification to tenant service
+func (r *tenantRouter) Webhook(ctx context.Context, request *contract.WebhookRequest) *contract.WebhookResponse {
+	for _, tenant := range r.tenants {
+		if tenant.Name != request.Tenant {
+			continue
+		}
+		if tenant.err != nil {
+			break
+		}
+		return tenant.Service.Webhook(ctx, request)
+	}
+	response := contract.NewWebhookResponse()
+	response.Status = base.StatusError
+	response.Error = fmt.Sprintf("tenant %v was not found or failed to initialise", request.Tenant)
+	return response
+}
+
+//ReceiveAS2 routes AS2 message to tenant service
+func (r *tenantRouter) ReceiveAS2(ctx context.Context, request *contract.AS2Request) *contract.AS2Response {
+	for _, tenant := range r.tenants {
+		if tenant.Name != request.Tenant {
+			continue
+		}
+		if tenant.err != nil {
+			break
+		}
+		return tenant.Service.ReceiveAS2(ctx, request)
+	}
+	response := contract.NewAS2Response()
+	response.Status = base.StatusError
+	response.Error = fmt.Sprintf("tenant %v was not found or failed to initialise", request.Tenant)
+	return response
+}
+
+//ReadEvents routes event log read request to tenant service
+func (r *tenantRouter) ReadEvents(ctx context.Context, request *contract.EventsRequest) *contract.EventsResponse {
+	for _, tenant := range r.tenants {
+		if tenant.Name != request.Tenant {
+			continue
+		}
+		if tenant.err != nil {
+			break
+		}
+		return tenant.Service.ReadEvents(ctx, request)
+	}
+	response := contract.NewEventsResponse()
+	response.Status = base.StatusError
+	response.Error = fmt.Sprintf("tenant %v was not found or failed to initialise", request.Tenant)
+	return response
+}
+
+//Dashboard routes dashboard request to tenant service
+func (r *tenantRouter) Dashboard(ctx context.Context, request *contract.DashboardRequest) *contract.DashboardResponse {
+	for _, tenant := range r.tenants {
+		if tenant.Name != request.Tenant {
+			continue
+		}
+		if tenant.err != nil {
+			break
+		}
+		return tenant.Service.Dashboard(ctx, request)
+	}
+	response := contract.NewDashboardResponse()
+	response.Status = base.StatusError
+	response.Error = fmt.Sprintf("tenant %v was not found or failed to initialise", request.Tenant)
+	return response
+}
+
+//RuleStatus routes rule status request to tenant service, empty tenant returns all tenants rule files status
+func (r *tenantRouter) RuleStatus(ctx context.Context, request *contract.RuleStatusRequest) *contract.RuleStatusResponse {
+	if request.Tenant == "" {
+		response := contract.NewRuleStatusResponse()
+		for _, tenant := range r.tenants {
+			if tenant.err != nil {
+				continue
+			}
+			tenantResponse := tenant.Service.RuleStatus(ctx, request)
+			response.Files = append(response.Files, tenantResponse.Files...)
+		}
+		response.Init()
+		return response
+	}
+	for _, tenant := range r.tenants {
+		if tenant.Name != request.Tenant {
+			continue
+		}
+		if tenant.err != nil {
+			break
+		}
+		return tenant.Service.RuleStatus(ctx, request)
+	}
+	response := contract.NewRuleStatusResponse()
+	response.Status = base.StatusError
+	response.Error = fmt.Sprintf("tenant %v was not found or failed to initialise", request.Tenant)
+	return response
+}
+
+//newTenantRouter creates tenant services, a tenant failing to initialise does not affect others
+func newTenantRouter(ctx context.Context, cfg *Config) (Service, error) {
+	result := &tenantRouter{}
+	for _, tenant := range cfg.Tenants {
+		srv := &tenantService{Tenant: tenant}
+		if srv.Service, srv.err = New(ctx, tenant.Config(cfg)); srv.err != nil {
+			srv.err = errors.Wrapf(srv.err, "failed to initialise tenant %v", tenant.Name)
+			shared.LogF("%v\n", srv.err)
+		}
+		result.tenants = append(result.tenants, srv)
+	}
+	return result, nil
+}
@@ -0,0 +1,141 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/auth"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/job"
+	"strings"
+	"testing"
+)
+
+func TestTenantRouter_Mirror(t *testing.T) {
+	ctx := context.Background()
+	cfg := &Config{
+		Tenants: []*Tenant{
+			{
+				Name:   "team1",
+				Prefix: "/tenant/team1",
+				Labels: map[string]string{"team": "team1"},
+				Mirrors: config.Ruleset{Rules: []*config.Rule{
+					{
+						Source: &config.Resource{Basic: matcher.Basic{Suffix: ".csv"}},
+						Dest:   &config.Resource{URL: "mem://localhost/tenant/dest1"},
+					},
+				}},
+			},
+			{
+				Name:   "team2",
+				Prefix: "/tenant/team2",
+				Mirrors: config.Ruleset{Rules: []*config.Rule{
+					{
+						Source: &config.Resource{Basic: matcher.Basic{Suffix: ".csv"}},
+						Dest:   &config.Resource{URL: "mem://localhost/tenant/dest2", KMSKeyARN: "invalid"},
+					},
+				}},
+			},
+		},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	fs := afs.New()
+	for _, URL := range []string{"mem://localhost/tenant/team1/data.csv", "mem://localhost/tenant/team2/data.csv"} {
+		_ = fs.Upload(ctx, URL, 0644, strings.NewReader("line1\n"))
+	}
+
+	response := service.Mirror(ctx, contract.NewRequest("mem://localhost/tenant/team1/data.csv"))
+	assert.Equal(t, base.StatusOK, response.Status, response.Error)
+	assert.Equal(t, "team1", response.Tenant)
+	assert.Equal(t, "team1", response.Labels["team"])
+
+	response = service.Mirror(ctx, contract.NewRequest("mem://localhost/tenant/team2/data.csv"))
+	assert.Equal(t, base.StatusError, response.Status)
+	assert.Equal(t, "team2", response.Tenant)
+
+	response = service.Mirror(ctx, contract.NewRequest("mem://localhost/tenant/team3/data.csv"))
+	assert.Equal(t, base.StatusNoMatch, response.Status)
+}
+
+func TestCheckSecretScope(t *testing.T) {
+	inScope := auth.Secret{URL: "gs://team1-secrets/secret.json.enc"}
+	outOfScope := auth.Secret{URL: "gs://team2-secrets/secret.json.enc"}
+	var useCases = []struct {
+		description string
+		rule        func(secret auth.Secret) *config.Rule
+	}{
+		{
+			description: "resource credentials",
+			rule: func(secret auth.Secret) *config.Rule {
+				return &config.Rule{Source: &config.Resource{}, Dest: &config.Resource{Credentials: &auth.Credentials{Secret: secret}}}
+			},
+		},
+		{
+			description: "custom key",
+			rule: func(secret auth.Secret) *config.Rule {
+				return &config.Rule{Source: &config.Resource{CustomKey: &config.CustomKey{Secret: secret}}}
+			},
+		},
+		{
+			description: "TLS client certificate",
+			rule: func(secret auth.Secret) *config.Rule {
+				return &config.Rule{Dest: &config.Resource{TLS: &config.TLS{Certificate: &secret}}}
+			},
+		},
+		{
+			description: "proxy credentials",
+			rule: func(secret auth.Secret) *config.Rule {
+				return &config.Rule{Source: &config.Resource{Proxy: &config.Proxy{Credentials: &secret}}}
+			},
+		},
+		{
+			description: "OAuth2 client credentials",
+			rule: func(secret auth.Secret) *config.Rule {
+				return &config.Rule{Dest: &config.Resource{URL: "https://api.partner.com/upload", OAuth2: &config.OAuth2{TokenURL: "https://idp.partner.com/token"}, Credentials: &auth.Credentials{Secret: secret}}}
+			},
+		},
+		{
+			description: "action credentials",
+			rule: func(secret auth.Secret) *config.Rule {
+				rule := &config.Rule{Dest: &config.Resource{}}
+				rule.OnFailure = []*job.Action{{Action: job.ActionNotify, Credentials: &auth.Credentials{Secret: secret}}}
+				return rule
+			},
+		},
+	}
+	scopes := []string{"gs://team1-secrets/"}
+	for _, useCase := range useCases {
+		assert.Nil(t, checkSecretScope(useCase.rule(outOfScope), nil, nil), useCase.description)
+		assert.Nil(t, checkSecretScope(useCase.rule(inScope), scopes, nil), useCase.description)
+		assert.NotNil(t, checkSecretScope(useCase.rule(outOfScope), scopes, nil), useCase.description)
+	}
+}
+
+func TestCheckSecretScope_Impersonation(t *testing.T) {
+	scopes := []string{"mirror@team1.iam.gserviceaccount.com", "arn:aws:iam::111111111111:role/team1-mirror"}
+	var useCases = []struct {
+		description         string
+		resource            *config.Resource
+		impersonationScopes []string
+		hasError            bool
+	}{
+		{description: "no impersonation scopes", resource: &config.Resource{ImpersonateServiceAccount: "admin@team2.iam.gserviceaccount.com"}},
+		{description: "allowed service account", resource: &config.Resource{ImpersonateServiceAccount: "mirror@team1.iam.gserviceaccount.com"}, impersonationScopes: scopes},
+		{description: "not allowed service account", resource: &config.Resource{ImpersonateServiceAccount: "admin@team2.iam.gserviceaccount.com"}, impersonationScopes: scopes, hasError: true},
+		{description: "allowed federation service account", resource: &config.Resource{Federation: &config.Federation{ServiceAccount: "mirror@team1.iam.gserviceaccount.com"}}, impersonationScopes: scopes},
+		{description: "not allowed federation service account", resource: &config.Resource{Federation: &config.Federation{ServiceAccount: "admin@team2.iam.gserviceaccount.com"}}, impersonationScopes: scopes, hasError: true},
+		{description: "allowed federation role", resource: &config.Resource{Federation: &config.Federation{RoleARN: "arn:aws:iam::111111111111:role/team1-mirror"}}, impersonationScopes: scopes},
+		{description: "not allowed federation role", resource: &config.Resource{Federation: &config.Federation{RoleARN: "arn:aws:iam::222222222222:role/admin"}}, impersonationScopes: scopes, hasError: true},
+	}
+	for _, useCase := range useCases {
+		rule := &config.Rule{Source: &config.Resource{}, Dest: useCase.resource}
+		err := checkSecretScope(rule, nil, useCase.impersonationScopes)
+		assert.Equal(t, useCase.hasError, err != nil, useCase.description)
+	}
+}
@@ -0,0 +1,62 @@
+package throttle
+
+import (
+	"github.com/viant/smirror/base"
+	"github.com/viant/afs/url"
+	"time"
+)
+
+const (
+	defaultMaxRetries   = 3
+	defaultMinBackoffMs = 100
+	defaultMaxBackoffMs = 10000
+)
+
+//Config represents provider API rate limit config
+type Config struct {
+	//RequestsPerSec max requests per second per bucket, 0 - no limit
+	RequestsPerSec float64 `json:",omitempty"`
+	//ProviderRequestsPerSec max requests per second per bucket keyed by storage scheme (gs, s3)
+	ProviderRequestsPerSec map[string]float64 `json:",omitempty"`
+	//MaxRetries max retries of throttled operation
+	MaxRetries int `json:",omitempty"`
+	//MinBackoffMs initial backoff applied after throttled response
+	MinBackoffMs base.Milliseconds `json:",omitempty"`
+	//MaxBackoffMs max backoff
+	MaxBackoffMs base.Milliseconds `json:",omitempty"`
+}
+
+//Init initialises config
+func (c *Config) Init() {
+	if c.MaxRetries == 0 {
+		c.MaxRetries = defaultMaxRetries
+	}
+	if c.MinBackoffMs == 0 {
+		c.MinBackoffMs = defaultMinBackoffMs
+	}
+	if c.MaxBackoffMs == 0 {
+		c.MaxBackoffMs = defaultMaxBackoffMs
+	}
+}
+
+//Interval returns min interval between requests for supplied URL bucket
+func (c *Config) Interval(URL string) time.Duration {
+	requestsPerSec := c.RequestsPerSec
+	if rate, ok := c.ProviderRequestsPerSec[url.Scheme(URL, "")]; ok {
+		requestsPerSec = rate
+	}
+	if requestsPerSec <= 0 {
+		return 0
+	}
+	return time.Duration(float64(time.Second) / requestsPerSec)
+}
+
+//MinBackoff returns min backoff
+func (c *Config) MinBackoff() time.Duration {
+	return time.Duration(c.MinBackoffMs) * time.Millisecond
+}
+
+//MaxBackoff returns max backoff
+func (c *Config) MaxBackoff() time.Duration {
+	return time.Duration(c.MaxBackoffMs) * time.Millisecond
+}
@@ -0,0 +1,30 @@
+package throttle
+
+import (
+	"regexp"
+	"strings"
+)
+
+var throttledFragments = []string{
+	"Too Many Requests",
+	"TooManyRequests",
+	"SlowDown",
+	"rateLimitExceeded",
+	"RequestLimitExceeded",
+}
+
+//throttledStatusExpr matches throttling status code in provider error format: googleapi "Error 429:", aws "status code: 503" and "StatusCode: 503"
+var throttledStatusExpr = regexp.MustCompile(`(?:Error |status code: |StatusCode: )(?:429|503)\b`)
+
+//IsThrottled returns true if error message indicates provider throttling, status codes are not matched in object URLs
+func IsThrottled(message string) bool {
+	if message == "" {
+		return false
+	}
+	for _, fragment := range throttledFragments {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return throttledStatusExpr.MatchString(message)
+}
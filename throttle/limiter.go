@@ -0,0 +1,120 @@
+package throttle
+
+import (
+	"context"
+	"github.com/viant/afs/url"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	next    time.Time
+	backoff time.Duration
+}
+
+//Limiter represents per bucket rate limiter with adaptive backoff, shared across worker routines
+type Limiter struct {
+	config    *Config
+	mux       *sync.Mutex
+	buckets   map[string]*bucket
+}
+
+func (l *Limiter) bucketKey(URL string) string {
+	return url.Scheme(URL, "") + "://" + url.Host(URL)
+}
+
+//reserve reserves next request slot and returns delay before it
+func (l *Limiter) reserve(URL string, now time.Time) time.Duration {
+	key := l.bucketKey(URL)
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	state, ok := l.buckets[key]
+	if !ok {
+		state = &bucket{}
+		l.buckets[key] = state
+	}
+	slot := state.next
+	if slot.Before(now) {
+		slot = now
+	}
+	state.next = slot.Add(l.config.Interval(URL) + state.backoff)
+	return slot.Sub(now)
+}
+
+//Wait waits for request slot for URL bucket, it returns time waited
+func (l *Limiter) Wait(ctx context.Context, URL string) (time.Duration, error) {
+	delay := l.reserve(URL, time.Now())
+	if delay <= 0 {
+		return 0, nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return delay, ctx.Err()
+	case <-timer.C:
+	}
+	return delay, nil
+}
+
+//Report adjusts URL bucket backoff with operation outcome
+func (l *Limiter) Report(URL string, err error) {
+	key := l.bucketKey(URL)
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	state, ok := l.buckets[key]
+	if !ok {
+		return
+	}
+	if err != nil && IsThrottled(err.Error()) {
+		state.backoff *= 2
+		if state.backoff < l.config.MinBackoff() {
+			state.backoff = l.config.MinBackoff()
+		}
+		if state.backoff > l.config.MaxBackoff() {
+			state.backoff = l.config.MaxBackoff()
+		}
+		if next := time.Now().Add(state.backoff); next.After(state.next) {
+			state.next = next
+		}
+		return
+	}
+	if err == nil && state.backoff > 0 {
+		state.backoff /= 2
+		if state.backoff < l.config.MinBackoff() {
+			state.backoff = 0
+		}
+	}
+}
+
+//Do runs operation within URL bucket rate limit, throttled operation is retried with backoff, it returns time waited
+func (l *Limiter) Do(ctx context.Context, URL string, operation func() error) (time.Duration, error) {
+	var waited time.Duration
+	var err error
+	for i := 0; i <= l.config.MaxRetries; i++ {
+		delay, e := l.Wait(ctx, URL)
+		waited += delay
+		if e != nil {
+			return waited, e
+		}
+		err = operation()
+		l.Report(URL, err)
+		if err == nil || !IsThrottled(err.Error()) {
+			return waited, err
+		}
+	}
+	return waited, err
+}
+
+//New creates a limiter
+func New(config *Config) *Limiter {
+	if config == nil {
+		config = &Config{}
+	}
+	config.Init()
+	return &Limiter{
+		config:  config,
+		mux:     &sync.Mutex{},
+		buckets: make(map[string]*bucket),
+	}
+}
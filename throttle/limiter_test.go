@@ -0,0 +1,79 @@
+package throttle
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Reserve(t *testing.T) {
+	var useCases = []struct {
+		description string
+		config      *Config
+		URLs        []string
+		errors      []error
+		expect      time.Duration
+	}{
+		{
+			description: "no limit",
+			config:      &Config{},
+			URLs:        []string{"gs://bucket/a", "gs://bucket/b"},
+			expect:      0,
+		},
+		{
+			description: "per bucket limit",
+			config:      &Config{RequestsPerSec: 10},
+			URLs:        []string{"gs://bucket/a", "gs://bucket/b"},
+			expect:      100 * time.Millisecond,
+		},
+		{
+			description: "provider limit",
+			config:      &Config{RequestsPerSec: 10, ProviderRequestsPerSec: map[string]float64{"s3": 2}},
+			URLs:        []string{"s3://bucket/a", "s3://bucket/b"},
+			expect:      500 * time.Millisecond,
+		},
+		{
+			description: "different buckets",
+			config:      &Config{RequestsPerSec: 10},
+			URLs:        []string{"gs://bucket1/a", "gs://bucket2/b"},
+			expect:      0,
+		},
+		{
+			description: "throttled backoff",
+			config:      &Config{MinBackoffMs: 200},
+			URLs:        []string{"gs://bucket/a", "gs://bucket/b"},
+			errors:      []error{errors.New("googleapi: Error 429: rateLimitExceeded")},
+			expect:      200 * time.Millisecond,
+		},
+		{
+			description: "throttling status digits in object URL",
+			config:      &Config{MinBackoffMs: 200},
+			URLs:        []string{"gs://bucket/part-00429.csv", "gs://bucket/part-00503.csv"},
+			errors:      []error{errors.New("failed to upload gs://bucket/part-00429.csv: i/o timeout")},
+			expect:      0,
+		},
+		{
+			description: "aws throttled status",
+			config:      &Config{MinBackoffMs: 200},
+			URLs:        []string{"s3://bucket/a", "s3://bucket/b"},
+			errors:      []error{errors.New("ServiceUnavailable: Please reduce your request rate.\n\tstatus code: 503, request id: 1")},
+			expect:      200 * time.Millisecond,
+		},
+	}
+
+	for _, useCase := range useCases {
+		limiter := New(useCase.config)
+		now := time.Now()
+		var delay time.Duration
+		for i, URL := range useCase.URLs {
+			if i < len(useCase.errors) {
+				limiter.reserve(URL, now)
+				limiter.Report(URL, useCase.errors[i])
+				continue
+			}
+			delay = limiter.reserve(URL, now)
+		}
+		assert.True(t, delay >= useCase.expect && delay < useCase.expect+50*time.Millisecond, useCase.description)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"io"
 	"github.com/viant/smirror/config"
 	"github.com/viant/smirror/transcoder"
+	"strings"
+	"sync/atomic"
 )
 
 //Transfer represents a data transfer
@@ -18,6 +20,47 @@ type Transfer struct {
 	Resource     *config.Resource
 	Reader       io.Reader
 	Dest         *Datafile
+	//bytes number of bytes read from transfer reader
+	bytes int64
+	//batcher coalesces split parts published to topic or queue
+	batcher *messageBatcher
+}
+
+//countingReader counts read bytes
+type countingReader struct {
+	io.Reader
+	count *int64
+}
+
+func (r *countingReader) Read(p []byte) (n int, err error) {
+	n, err = r.Reader.Read(p)
+	atomic.AddInt64(r.count, int64(n))
+	return n, err
+}
+
+//Bytes returns number of transferred bytes
+func (t *Transfer) Bytes() int64 {
+	return atomic.LoadInt64(&t.bytes)
+}
+
+//MessageDest returns topic or queue message destination
+func (t *Transfer) MessageDest() string {
+	dest := t.Resource.Topic
+	if dest == "" {
+		dest = t.Resource.Queue
+	}
+	return strings.Replace(dest, "$partition", t.partition, 1)
+}
+
+//DestURL returns destination URL or message destination
+func (t *Transfer) DestURL() string {
+	if t.Resource.Topic != "" || t.Resource.Queue != "" {
+		return t.MessageDest()
+	}
+	if t.Dest != nil {
+		return t.Dest.URL
+	}
+	return ""
 }
 
 //GetReader returns a reader
@@ -25,7 +68,10 @@ func (t *Transfer) GetReader() (reader io.Reader, err error) {
 	if t.Reader == nil {
 		return nil, fmt.Errorf("transfer reader was empty")
 	}
-	return t.getReader()
+	if reader, err = t.getReader(); err != nil {
+		return nil, err
+	}
+	return &countingReader{Reader: reader, count: &t.bytes}, nil
 }
 
 func (t *Transfer) getReader() (reader io.Reader, err error) {
@@ -0,0 +1,203 @@
+package udf
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/viant/toolbox"
+	"github.com/viant/toolbox/data"
+	"github.com/viant/toolbox/data/udf"
+	"hash"
+	"hash/crc32"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const defaultDateFormat = "yyyy-MM-dd"
+
+//Register registers toolbox and smirror template functions
+func Register(aMap data.Map) {
+	udf.Register(aMap)
+	aMap.Put("Upper", udf.ToUpper)
+	aMap.Put("Lower", udf.ToLower)
+	aMap.Put("Substr", Substr)
+	aMap.Put("RegexReplace", RegexReplace)
+	aMap.Put("Hash", Hash)
+	aMap.Put("UUID", UUID)
+	aMap.Put("DateAdd", DateAdd)
+	aMap.Put("LeftPad", LeftPad)
+	aMap.Put("RightPad", RightPad)
+}
+
+//NewMap returns a map with registered template functions
+func NewMap() data.Map {
+	result := data.NewMap()
+	Register(result)
+	return result
+}
+
+func asArgs(name string, source interface{}, min int) ([]interface{}, error) {
+	var args []interface{}
+	if toolbox.IsSlice(source) {
+		args = toolbox.AsSlice(source)
+	} else if source != nil {
+		args = []interface{}{source}
+	}
+	if len(args) < min {
+		return nil, fmt.Errorf("%v expected at least %v arguments, but had: %v", name, min, len(args))
+	}
+	return args, nil
+}
+
+//Substr returns text substring, i.e. $Substr($Name, 0, 4), negative index counts from the end
+func Substr(source interface{}, state data.Map) (interface{}, error) {
+	args, err := asArgs("Substr", source, 2)
+	if err != nil {
+		return nil, err
+	}
+	text := toolbox.AsString(args[0])
+	start, end := toolbox.AsInt(args[1]), len(text)
+	if len(args) > 2 {
+		end = toolbox.AsInt(args[2])
+	}
+	if start < 0 {
+		start += len(text)
+	}
+	if end < 0 {
+		end += len(text)
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end > len(text) {
+		end = len(text)
+	}
+	if start >= end {
+		return "", nil
+	}
+	return text[start:end], nil
+}
+
+//RegexReplace replaces text regular expression matches, i.e. $RegexReplace($Name, "[0-9]+", "N")
+func RegexReplace(source interface{}, state data.Map) (interface{}, error) {
+	args, err := asArgs("RegexReplace", source, 3)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := regexp.Compile(toolbox.AsString(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	return expr.ReplaceAllString(toolbox.AsString(args[0]), toolbox.AsString(args[2])), nil
+}
+
+//Hash returns hex text hash, i.e. $Hash($SourceURL, "sha256"), supported: md5 (default), sha1, sha256, crc32
+func Hash(source interface{}, state data.Map) (interface{}, error) {
+	args, err := asArgs("Hash", source, 1)
+	if err != nil {
+		return nil, err
+	}
+	algorithm := "md5"
+	if len(args) > 1 {
+		algorithm = strings.ToLower(toolbox.AsString(args[1]))
+	}
+	var hasher hash.Hash
+	switch algorithm {
+	case "md5":
+		hasher = md5.New()
+	case "sha1":
+		hasher = sha1.New()
+	case "sha256":
+		hasher = sha256.New()
+	case "crc32":
+		hasher = crc32.NewIEEE()
+	default:
+		return nil, fmt.Errorf("unsupported hash: %v", algorithm)
+	}
+	_, _ = hasher.Write([]byte(toolbox.AsString(args[0])))
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+//UUID returns a random UUID
+func UUID(source interface{}, state data.Map) (interface{}, error) {
+	return uuid.New().String(), nil
+}
+
+//DateAdd shifts date by offset and formats it, i.e. $DateAdd("now", "-1d", "yyyy/MM/dd"), offset supports d (day) unit and Go durations
+func DateAdd(source interface{}, state data.Map) (interface{}, error) {
+	args, err := asArgs("DateAdd", source, 2)
+	if err != nil {
+		return nil, err
+	}
+	var date time.Time
+	if value, ok := args[0].(time.Time); ok {
+		date = value
+	} else {
+		timeValue, err := toolbox.TimeAt(toolbox.AsString(args[0]))
+		if err != nil {
+			if timeValue, err = toolbox.ToTime(args[0], time.RFC3339); err != nil {
+				return nil, fmt.Errorf("invalid DateAdd date: %v, %w", args[0], err)
+			}
+		}
+		date = *timeValue
+	}
+	offset, err := parseOffset(toolbox.AsString(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	format := defaultDateFormat
+	if len(args) > 2 {
+		format = toolbox.AsString(args[2])
+	}
+	return date.Add(offset).Format(toolbox.DateFormatToLayout(format)), nil
+}
+
+func parseOffset(offset string) (time.Duration, error) {
+	if strings.HasSuffix(offset, "d") {
+		days, err := toolbox.ToInt(strings.TrimSuffix(offset, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid DateAdd offset: %v", offset)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(offset)
+}
+
+//LeftPad pads text on the left to width, i.e. $LeftPad($Values.partition, 4, "0")
+func LeftPad(source interface{}, state data.Map) (interface{}, error) {
+	text, padding, err := pad("LeftPad", source)
+	if err != nil {
+		return nil, err
+	}
+	return padding + text, nil
+}
+
+//RightPad pads text on the right to width
+func RightPad(source interface{}, state data.Map) (interface{}, error) {
+	text, padding, err := pad("RightPad", source)
+	if err != nil {
+		return nil, err
+	}
+	return text + padding, nil
+}
+
+func pad(name string, source interface{}) (string, string, error) {
+	args, err := asArgs(name, source, 2)
+	if err != nil {
+		return "", "", err
+	}
+	text := toolbox.AsString(args[0])
+	width := toolbox.AsInt(args[1])
+	char := " "
+	if len(args) > 2 && toolbox.AsString(args[2]) != "" {
+		char = toolbox.AsString(args[2])
+	}
+	if len(text) >= width {
+		return text, "", nil
+	}
+	return text, strings.Repeat(char, width-len(text))[:width-len(text)], nil
+}
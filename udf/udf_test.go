@@ -0,0 +1,33 @@
+package udf
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	state := NewMap()
+	state.Put("Name", "Events_2020.csv")
+	state.Put("Partition", 7)
+	state.Put("Date", "2020-03-01T10:00:00Z")
+	var useCases = []struct {
+		description string
+		template    string
+		expect      string
+	}{
+		{description: "upper", template: "$Upper($Name)", expect: "EVENTS_2020.CSV"},
+		{description: "lower", template: "$Lower($Name)", expect: "events_2020.csv"},
+		{description: "substr", template: "$Substr($Name, 0, 6)", expect: "Events"},
+		{description: "substr from end", template: "$Substr($Name, -3)", expect: "csv"},
+		{description: "regex replace", template: "$RegexReplace($Name, [0-9]+, N)", expect: "Events_N.csv"},
+		{description: "hash", template: "$Hash($Name)", expect: "55d94565491863b2a0f524ed9efdb3f7"},
+		{description: "left pad", template: "part-$LeftPad($Partition, 4, 0)", expect: "part-0007"},
+		{description: "right pad", template: "$RightPad($Partition, 3, x)", expect: "7xx"},
+		{description: "date add", template: "$DateAdd($Date, -1d, yyyy/MM/dd)", expect: "2020/02/29"},
+	}
+	for _, useCase := range useCases {
+		actual := state.ExpandAsText(useCase.template)
+		assert.Equal(t, useCase.expect, actual, useCase.description)
+	}
+	assert.Equal(t, 36, len(state.ExpandAsText("$UUID()")))
+}
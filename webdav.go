@@ -0,0 +1,82 @@
+package smirror
+
+import (
+	"compress/gzip"
+	"context"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"github.com/viant/smirror/secret"
+	"github.com/viant/smirror/webdav"
+	"io"
+	"net/http"
+	"sync"
+)
+
+//webdavClients clients keyed by host and credentials, to reuse digest challenge and created collections
+var webdavClients = sync.Map{}
+
+func webdavClient(resource *config.Resource, httpClient *http.Client) (*webdav.Client, error) {
+	host := url.Host(resource.URL)
+	var auth []byte
+	if resource.Credentials != nil {
+		auth = resource.Credentials.Auth
+	}
+	key := host + "/" + string(auth)
+	if httpClient != nil {
+		key += "/" + string(secret.NewIdentity(url.Scheme(resource.URL, ""), resource))
+	}
+	if client, ok := webdavClients.Load(key); ok {
+		return client.(*webdav.Client), nil
+	}
+	var credentials *webdav.Credentials
+	if resource.Credentials != nil {
+		var err error
+		if credentials, err = webdav.NewCredentials(auth); err != nil {
+			return nil, err
+		}
+	}
+	client, _ := webdavClients.LoadOrStore(key, webdav.New(credentials, httpClient))
+	return client.(*webdav.Client), nil
+}
+
+//uploadWebDAV streams transfer to WebDAV server
+func (s *service) uploadWebDAV(ctx context.Context, transfer *Transfer, response *contract.Response) error {
+	httpClient, err := s.httpClient(ctx, transfer.Resource)
+	if err != nil {
+		return err
+	}
+	client, err := webdavClient(transfer.Resource, httpClient)
+	if err != nil {
+		return base.NewCodedError(base.ErrorCodeAuth, err)
+	}
+	reader, err := transfer.GetReader()
+	if err != nil {
+		return errors.Wrapf(err, "failed to get reader for: %v", transfer.Resource.URL)
+	}
+	if transfer.Dest.CompressionCodec() == config.GZipCodec {
+		pipeReader, pipeWriter := io.Pipe()
+		go func(source io.Reader) {
+			gzipWriter := gzip.NewWriter(pipeWriter)
+			_, err := io.Copy(gzipWriter, source)
+			if err == nil {
+				err = gzipWriter.Close()
+			}
+			_ = pipeWriter.CloseWithError(err)
+		}(reader)
+		reader = pipeReader
+	}
+	waited, err := s.limiter.Wait(ctx, transfer.Dest.URL)
+	response.AddThrottleTime(waited)
+	if err != nil {
+		return err
+	}
+	if err = client.Upload(ctx, transfer.Dest.URL, reader); err != nil {
+		s.limiter.Report(transfer.Dest.URL, err)
+		return errors.Wrapf(err, "failed to upload to webdav: %v", transfer.Dest.URL)
+	}
+	response.AddURL(transfer.Dest.URL)
+	return nil
+}
@@ -0,0 +1,165 @@
+package webdav
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"hash"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	//AuthBasic basic authentication
+	AuthBasic = "basic"
+	//AuthDigest digest authentication
+	AuthDigest = "digest"
+)
+
+var challengeParam = regexp.MustCompile(`(\w+)=("([^"]*)"|[^,\s]*)`)
+
+//Credentials represents decrypted WebDAV credentials
+type Credentials struct {
+	Username string
+	Password string
+	//Auth basic (default) or digest
+	Auth string `json:",omitempty"`
+}
+
+//challenge represents digest authentication challenge
+type challenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+	count     int
+}
+
+func parseChallenge(header string) (*challenge, error) {
+	if !strings.HasPrefix(strings.ToLower(header), AuthDigest+" ") {
+		return nil, errors.Errorf("expected digest challenge, but had: %v", header)
+	}
+	params := map[string]string{}
+	for _, matched := range challengeParam.FindAllStringSubmatch(header[len(AuthDigest)+1:], -1) {
+		value := matched[2]
+		if strings.HasPrefix(value, `"`) {
+			value = matched[3]
+		}
+		params[strings.ToLower(matched[1])] = value
+	}
+	result := &challenge{realm: params["realm"], nonce: params["nonce"], opaque: params["opaque"], algorithm: params["algorithm"]}
+	if result.nonce == "" {
+		return nil, errors.Errorf("digest challenge nonce was empty: %v", header)
+	}
+	for _, qop := range strings.Split(params["qop"], ",") {
+		if strings.TrimSpace(qop) == "auth" {
+			result.qop = "auth"
+		}
+	}
+	return result, nil
+}
+
+//authorization returns digest Authorization header value, see RFC 7616
+func (c *challenge) authorization(credentials *Credentials, method, URI string) string {
+	var newHash func() hash.Hash = md5.New
+	algorithm := strings.ToUpper(c.algorithm)
+	if strings.HasPrefix(algorithm, "SHA-256") {
+		newHash = sha256.New
+	}
+	digest := func(values ...string) string {
+		h := newHash()
+		h.Write([]byte(strings.Join(values, ":")))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+	random := make([]byte, 8)
+	_, _ = rand.Read(random)
+	cnonce := hex.EncodeToString(random)
+	c.count++
+	nc := fmt.Sprintf("%08x", c.count)
+	ha1 := digest(credentials.Username, c.realm, credentials.Password)
+	if strings.HasSuffix(algorithm, "-SESS") {
+		ha1 = digest(ha1, c.nonce, cnonce)
+	}
+	ha2 := digest(method, URI)
+	header := fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s"`, credentials.Username, c.realm, c.nonce, URI)
+	if c.qop != "" {
+		header += fmt.Sprintf(`, qop=%s, nc=%s, cnonce="%s", response="%s"`, c.qop, nc, cnonce, digest(ha1, c.nonce, nc, cnonce, c.qop, ha2))
+	} else {
+		header += fmt.Sprintf(`, response="%s"`, digest(ha1, c.nonce, ha2))
+	}
+	if c.algorithm != "" {
+		header += ", algorithm=" + c.algorithm
+	}
+	if c.opaque != "" {
+		header += fmt.Sprintf(`, opaque="%s"`, c.opaque)
+	}
+	return header
+}
+
+//authorize sets request authorization, digest requires challenge
+func (c *Client) authorize(request *http.Request) {
+	if c.credentials == nil {
+		return
+	}
+	if c.credentials.Auth != AuthDigest {
+		request.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+		return
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	if c.challenge != nil {
+		request.Header.Set("Authorization", c.challenge.authorization(c.credentials, request.Method, request.URL.RequestURI()))
+	}
+}
+
+func (c *Client) hasChallenge() bool {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.challenge != nil
+}
+
+//updateChallenge stores digest challenge from unauthorized response, it returns true if request can be retried
+func (c *Client) updateChallenge(response *http.Response) bool {
+	if c.credentials == nil || c.credentials.Auth != AuthDigest || response.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	for _, header := range response.Header.Values("WWW-Authenticate") {
+		if parsed, err := parseChallenge(header); err == nil {
+			c.mux.Lock()
+			c.challenge = parsed
+			c.mux.Unlock()
+			return true
+		}
+	}
+	return false
+}
+
+//NewCredentials creates credentials from decrypted secret JSON
+func NewCredentials(secret []byte) (*Credentials, error) {
+	secret = bytes.TrimSpace(secret)
+	if len(secret) == 0 {
+		return nil, errors.New("webdav credentials were empty")
+	}
+	credentials := &Credentials{}
+	if err := json.Unmarshal(secret, credentials); err != nil {
+		return nil, errors.Wrap(err, "failed to decode webdav credentials")
+	}
+	credentials.Auth = strings.ToLower(credentials.Auth)
+	if credentials.Auth == "" {
+		credentials.Auth = AuthBasic
+	}
+	if credentials.Auth != AuthBasic && credentials.Auth != AuthDigest {
+		return nil, errors.Errorf("unsupported webdav Auth: %v, expected %v or %v", credentials.Auth, AuthBasic, AuthDigest)
+	}
+	if credentials.Username == "" {
+		return nil, errors.New("webdav credentials Username was empty")
+	}
+	return credentials, nil
+}
@@ -0,0 +1,168 @@
+package webdav
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	//Scheme WebDAV over HTTP scheme
+	Scheme = "webdav"
+	//SecureScheme WebDAV over HTTPS scheme
+	SecureScheme = "webdavs"
+
+	methodMkCol    = "MKCOL"
+	methodPropFind = "PROPFIND"
+)
+
+//Client represents WebDAV client
+type Client struct {
+	credentials *Credentials
+	http        *http.Client
+	mux         sync.Mutex
+	challenge   *challenge
+	//collections existing collection URLs
+	collections sync.Map
+}
+
+//Upload streams data with chunked PUT, missing parent collections are created with MKCOL
+func (c *Client) Upload(ctx context.Context, URL string, data io.Reader) error {
+	target, err := HTTPURL(URL)
+	if err != nil {
+		return err
+	}
+	if err = c.ensureCollection(ctx, parentURL(target)); err != nil {
+		return err
+	}
+	if c.credentials != nil && c.credentials.Auth == AuthDigest && !c.hasChallenge() {
+		//streamed body can not be replayed, obtain digest challenge upfront
+		if _, err = c.do(ctx, http.MethodOptions, target, nil); err != nil {
+			return err
+		}
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, target, ioutil.NopCloser(data))
+	if err != nil {
+		return err
+	}
+	request.ContentLength = -1
+	request.TransferEncoding = []string{"chunked"}
+	c.authorize(request)
+	response, err := c.http.Do(request)
+	if err != nil {
+		return errors.Wrapf(err, "failed to PUT %v", target)
+	}
+	defer response.Body.Close()
+	body, _ := ioutil.ReadAll(response.Body)
+	c.updateChallenge(response)
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	}
+	return errors.Errorf("failed to PUT %v: %v %s", target, response.Status, strings.TrimSpace(string(body)))
+}
+
+//ensureCollection checks if collection exists, otherwise creates it and missing ancestors
+func (c *Client) ensureCollection(ctx context.Context, collectionURL string) error {
+	if _, ok := c.collections.Load(collectionURL); ok {
+		return nil
+	}
+	status, err := c.do(ctx, methodPropFind, collectionURL, map[string]string{"Depth": "0"})
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusOK, http.StatusMultiStatus:
+	case http.StatusNotFound:
+		parent := parentURL(collectionURL)
+		if parent != collectionURL {
+			if err = c.ensureCollection(ctx, parent); err != nil {
+				return err
+			}
+		}
+		if status, err = c.do(ctx, methodMkCol, collectionURL, nil); err != nil {
+			return err
+		}
+		//405 Method Not Allowed: collection was created concurrently
+		if status != http.StatusCreated && status != http.StatusMethodNotAllowed {
+			return errors.Errorf("failed to MKCOL %v: %v", collectionURL, status)
+		}
+	default:
+		return errors.Errorf("failed to PROPFIND %v: %v", collectionURL, status)
+	}
+	c.collections.Store(collectionURL, true)
+	return nil
+}
+
+//do sends body-less request, it is retried once with new digest challenge, it returns response status
+func (c *Client) do(ctx context.Context, method, URL string, headers map[string]string) (int, error) {
+	for attempt := 0; ; attempt++ {
+		request, err := http.NewRequestWithContext(ctx, method, URL, nil)
+		if err != nil {
+			return 0, err
+		}
+		for k, v := range headers {
+			request.Header.Set(k, v)
+		}
+		c.authorize(request)
+		response, err := c.http.Do(request)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to %v %v", method, URL)
+		}
+		_, _ = io.Copy(ioutil.Discard, response.Body)
+		_ = response.Body.Close()
+		if c.updateChallenge(response) && attempt == 0 {
+			continue
+		}
+		if response.StatusCode == http.StatusUnauthorized || response.StatusCode == http.StatusForbidden {
+			return 0, errors.Errorf("failed to %v %v: %v", method, URL, response.Status)
+		}
+		return response.StatusCode, nil
+	}
+}
+
+func parentURL(URL string) string {
+	parsed, err := url.Parse(URL)
+	if err != nil {
+		return URL
+	}
+	parent := path.Dir(strings.TrimRight(parsed.Path, "/"))
+	if parent == "." || parent == "/" {
+		parsed.Path = "/"
+	} else {
+		parsed.Path = parent + "/"
+	}
+	return parsed.String()
+}
+
+//HTTPURL returns http(s) URL for webdav:// or webdavs:// URL
+func HTTPURL(URL string) (string, error) {
+	parsed, err := url.Parse(URL)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid webdav URL: %v", URL)
+	}
+	switch parsed.Scheme {
+	case Scheme:
+		parsed.Scheme = "http"
+	case SecureScheme:
+		parsed.Scheme = "https"
+	default:
+		return "", errors.Errorf("unsupported webdav URL scheme: %v", parsed.Scheme)
+	}
+	return parsed.String(), nil
+}
+
+//New creates a client
+func New(credentials *Credentials, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Minute}
+	}
+	return &Client{credentials: credentials, http: httpClient}
+}
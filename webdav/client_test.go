@@ -0,0 +1,141 @@
+package webdav
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type davServer struct {
+	mux         sync.Mutex
+	digest      bool
+	collections map[string]bool
+	files       map[string]string
+	requests    []string
+	chunked     bool
+}
+
+var digestParam = regexp.MustCompile(`(\w+)="?([^",]*)"?`)
+
+func (s *davServer) authorized(request *http.Request) bool {
+	if !s.digest {
+		user, password, ok := request.BasicAuth()
+		return ok && user == "user" && password == "secret"
+	}
+	header := request.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Digest ") {
+		return false
+	}
+	params := map[string]string{}
+	for _, matched := range digestParam.FindAllStringSubmatch(header[7:], -1) {
+		params[matched[1]] = matched[2]
+	}
+	hash := func(value string) string {
+		sum := md5.Sum([]byte(value))
+		return hex.EncodeToString(sum[:])
+	}
+	ha1 := hash("user:dav:secret")
+	ha2 := hash(request.Method + ":" + params["uri"])
+	expect := hash(fmt.Sprintf("%v:%v:%v:%v:%v:%v", ha1, params["nonce"], params["nc"], params["cnonce"], params["qop"], ha2))
+	return params["nonce"] == "abc" && params["response"] == expect
+}
+
+func (s *davServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if !s.authorized(request) {
+		writer.Header().Set("WWW-Authenticate", `Digest realm="dav", nonce="abc", qop="auth", algorithm=MD5`)
+		writer.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	s.requests = append(s.requests, request.Method+" "+request.URL.Path)
+	parent := request.URL.Path[:strings.LastIndex(strings.TrimRight(request.URL.Path, "/"), "/")+1]
+	switch request.Method {
+	case http.MethodOptions:
+	case "PROPFIND":
+		if !s.collections[request.URL.Path] {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writer.WriteHeader(http.StatusMultiStatus)
+	case "MKCOL":
+		if !s.collections[parent] {
+			writer.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.collections[request.URL.Path] = true
+		writer.WriteHeader(http.StatusCreated)
+	case http.MethodPut:
+		if !s.collections[parent] {
+			writer.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.chunked = len(request.TransferEncoding) > 0 && request.TransferEncoding[0] == "chunked"
+		data, _ := ioutil.ReadAll(request.Body)
+		s.files[request.URL.Path] = string(data)
+		writer.WriteHeader(http.StatusCreated)
+	}
+}
+
+func TestClient_Upload(t *testing.T) {
+	var useCases = []struct {
+		description string
+		digest      bool
+		auth        string
+	}{
+		{
+			description: "basic auth",
+			auth:        `{"Username":"user","Password":"secret"}`,
+		},
+		{
+			description: "digest auth",
+			digest:      true,
+			auth:        `{"Username":"user","Password":"secret","Auth":"Digest"}`,
+		},
+	}
+
+	for _, useCase := range useCases {
+		dav := &davServer{digest: useCase.digest, collections: map[string]bool{"/": true, "/dav/": true}, files: map[string]string{}}
+		server := httptest.NewServer(dav)
+		credentials, err := NewCredentials([]byte(useCase.auth))
+		if !assert.Nil(t, err, useCase.description) {
+			server.Close()
+			continue
+		}
+		client := New(credentials, nil)
+		baseURL := strings.Replace(server.URL, "http://", "webdav://", 1)
+		for _, name := range []string{"a.csv", "b.csv"} {
+			err = client.Upload(context.Background(), baseURL+"/dav/archive/2024/"+name, strings.NewReader("data:"+name))
+			assert.Nil(t, err, useCase.description)
+		}
+		server.Close()
+		assert.Equal(t, "data:a.csv", dav.files["/dav/archive/2024/a.csv"], useCase.description)
+		assert.Equal(t, "data:b.csv", dav.files["/dav/archive/2024/b.csv"], useCase.description)
+		assert.True(t, dav.collections["/dav/archive/2024/"], useCase.description)
+		assert.True(t, dav.chunked, useCase.description)
+		var mkcols int
+		for _, request := range dav.requests {
+			if strings.HasPrefix(request, "MKCOL") {
+				mkcols++
+			}
+		}
+		assert.Equal(t, 2, mkcols, useCase.description)
+	}
+}
+
+func TestHTTPURL(t *testing.T) {
+	URL, err := HTTPURL("webdavs://archive.vendor.com/remote.php/dav/files/a.csv")
+	assert.Nil(t, err)
+	assert.Equal(t, "https://archive.vendor.com/remote.php/dav/files/a.csv", URL)
+	_, err = HTTPURL("s3://bucket/a.csv")
+	assert.NotNil(t, err)
+}
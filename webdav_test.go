@@ -0,0 +1,54 @@
+package smirror
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/viant/afs"
+	"github.com/viant/afs/matcher"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestService_WebDAV(t *testing.T) {
+	var requests []string
+	var uploaded string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		requests = append(requests, request.Method+" "+request.URL.Path)
+		switch request.Method {
+		case "PROPFIND":
+			writer.WriteHeader(http.StatusMultiStatus)
+		case http.MethodPut:
+			data, _ := ioutil.ReadAll(request.Body)
+			uploaded = string(data)
+			writer.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+	cfg := &Config{
+		Mirrors: config.Ruleset{Rules: []*config.Rule{
+			{
+				Source: &config.Resource{Basic: matcher.Basic{Prefix: "/webdav/data"}},
+				Dest:   &config.Resource{URL: strings.Replace(server.URL, "http://", "webdav://", 1) + "/archive"},
+			},
+		}},
+	}
+	service, err := New(ctx, cfg)
+	if !assert.Nil(t, err) {
+		return
+	}
+	sourceURL := "mem://localhost/webdav/data/report.csv"
+	_ = afs.New().Upload(ctx, sourceURL, 0644, strings.NewReader("id,name\n1,a\n"))
+	response := service.Mirror(ctx, contract.NewRequest(sourceURL))
+	if !assert.Equal(t, base.StatusOK, response.Status, response.Error) {
+		return
+	}
+	assert.Equal(t, "id,name\n1,a\n", uploaded)
+	assert.Equal(t, []string{"PROPFIND /archive/webdav/data/", "PUT /archive/webdav/data/report.csv"}, requests)
+}
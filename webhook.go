@@ -0,0 +1,200 @@
+package smirror
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/viant/afs/storage"
+	"github.com/viant/afs/url"
+	"github.com/viant/smirror/base"
+	"github.com/viant/smirror/config"
+	"github.com/viant/smirror/contract"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	webhookPartnerHeader = "X-Partner"
+	//webhookParallelism max concurrently mirrored objects of notified prefix
+	webhookParallelism = 8
+	//maxWebhookPayload max notification payload size
+	maxWebhookPayload = 1024 * 1024
+)
+
+//StorageMirrorWebhook cloud function entry point, partner push notification is verified with HMAC signature and mirrored right away
+func StorageMirrorWebhook(w http.ResponseWriter, r *http.Request) {
+	response, err := handleWebhook(r)
+	if err != nil {
+		log.Print(err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if response.Denied {
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		log.Print(err)
+	}
+}
+
+func handleWebhook(httpRequest *http.Request) (response *contract.WebhookResponse, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	query := httpRequest.URL.Query()
+	request := &contract.WebhookRequest{Tenant: query.Get("tenant"), Partner: query.Get("partner"), Header: httpRequest.Header}
+	if request.Partner == "" {
+		request.Partner = httpRequest.Header.Get(webhookPartnerHeader)
+	}
+	defer func() {
+		_ = httpRequest.Body.Close()
+	}()
+	if request.Payload, err = ioutil.ReadAll(http.MaxBytesReader(nil, httpRequest.Body, maxWebhookPayload)); err != nil {
+		return nil, errors.Wrap(err, "failed to read webhook payload")
+	}
+	ctx := context.Background()
+	service, err := NewFromEnv(ctx, base.ConfigEnvKey)
+	if err != nil {
+		return nil, err
+	}
+	return service.Webhook(ctx, request), nil
+}
+
+//webhook returns partner webhook
+func (s *service) webhook(request *contract.WebhookRequest) *config.Webhook {
+	for _, candidate := range s.config.Webhooks {
+		if candidate.Partner == request.Partner {
+			return candidate
+		}
+	}
+	return nil
+}
+
+//Webhook verifies partner notification and mirrors notified source object or prefix objects
+func (s *service) Webhook(ctx context.Context, request *contract.WebhookRequest) *contract.WebhookResponse {
+	response := contract.NewWebhookResponse()
+	response.Partner = request.Partner
+	if err := s.handleWebhook(ctx, request, response); err != nil {
+		response.Status = base.StatusError
+		response.Error = err.Error()
+	}
+	return response
+}
+
+func (s *service) handleWebhook(ctx context.Context, request *contract.WebhookRequest, response *contract.WebhookResponse) error {
+	webhook := s.webhook(request)
+	if webhook == nil {
+		response.Denied = true
+		return errors.Errorf("unknown webhook partner: '%v'", request.Partner)
+	}
+	secret, err := s.webhookSecret(ctx, webhook)
+	if err != nil {
+		return err
+	}
+	signature, timestamp := request.Header.Get(webhook.SignatureHeader), ""
+	if webhook.TimestampHeader != "" {
+		timestamp = request.Header.Get(webhook.TimestampHeader)
+	}
+	if err = webhook.Verify(secret, signature, timestamp, request.Payload, time.Now()); err != nil {
+		response.Denied = true
+		return err
+	}
+	payload := map[string]interface{}{}
+	if err = json.Unmarshal(request.Payload, &payload); err != nil {
+		return errors.Wrap(err, "failed to decode webhook payload")
+	}
+	location, err := webhook.Path(payload)
+	if err != nil {
+		return err
+	}
+	response.SourceURL = url.Join(webhook.Source.URL, location)
+	URLs := []string{response.SourceURL}
+	if strings.HasSuffix(location, "/") {
+		options, err := s.secret.StorageOpts(ctx, &webhook.Source)
+		if err != nil {
+			return err
+		}
+		URLs = make([]string, 0)
+		if response.Truncated, err = s.listFiles(ctx, response.SourceURL, webhook.MaxFiles, &URLs, options); err != nil {
+			return errors.Wrapf(err, "failed to list %v", response.SourceURL)
+		}
+	}
+	s.triggerAll(ctx, URLs, response)
+	for _, triggered := range response.Triggered {
+		if triggered.Status == base.StatusError {
+			response.Status = base.StatusError
+			response.Error = triggered.Error
+		}
+	}
+	return nil
+}
+
+//triggerAll mirrors source objects with webhook parallelism
+func (s *service) triggerAll(ctx context.Context, URLs []string, response *contract.WebhookResponse) {
+	waitGroup := &sync.WaitGroup{}
+	limiter := make(chan bool, webhookParallelism)
+	for i := range URLs {
+		waitGroup.Add(1)
+		limiter <- true
+		go func(URL string) {
+			defer func() {
+				<-limiter
+				waitGroup.Done()
+			}()
+			response.AddTriggered(URL, s.Mirror(ctx, contract.NewRequest(URL)))
+		}(URLs[i])
+	}
+	waitGroup.Wait()
+}
+
+//listFiles recursively lists prefix files, it returns true if prefix has more than max files
+func (s *service) listFiles(ctx context.Context, URL string, max int, result *[]string, options []storage.Option) (bool, error) {
+	objects, err := s.fs.List(ctx, URL, options...)
+	if err != nil {
+		return false, err
+	}
+	for i, object := range objects {
+		if i == 0 && object.IsDir() {
+			continue
+		}
+		if object.IsDir() {
+			if truncated, err := s.listFiles(ctx, object.URL(), max, result, options); truncated || err != nil {
+				return truncated, err
+			}
+			continue
+		}
+		if len(*result) >= max {
+			return true, nil
+		}
+		*result = append(*result, object.URL())
+	}
+	return false, nil
+}
+
+//webhookSecret returns decrypted partner shared secret, it is decrypted once per instance
+func (s *service) webhookSecret(ctx context.Context, webhook *config.Webhook) ([]byte, error) {
+	s.mux.Lock()
+	secret, ok := s.webhookSecrets[webhook.Partner]
+	s.mux.Unlock()
+	if ok {
+		return secret, nil
+	}
+	secret, err := s.secret.Decrypt(ctx, webhook.Secret)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt webhook %v secret", webhook.Partner)
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.webhookSecrets == nil {
+		s.webhookSecrets = make(map[string][]byte)
+	}
+	s.webhookSecrets[webhook.Partner] = secret
+	return secret, nil
+}